@@ -0,0 +1,199 @@
+package auditexport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+func testEvent(success bool) Event {
+	uid := uint(7)
+	return FromOperationLog(&models.OperationLog{
+		ID:           42,
+		UserID:       &uid,
+		Username:     "alice",
+		Method:       "DELETE",
+		Path:         "/api/v1/clusters/1/pods/default/web-0",
+		Module:       "pod",
+		Action:       "delete",
+		ClusterName:  "prod",
+		Namespace:    "default",
+		ResourceName: "web|0=x",
+		Success:      success,
+		StatusCode:   200,
+		CreatedAt:    time.UnixMilli(1700000000000),
+	})
+}
+
+func TestFilterMatch(t *testing.T) {
+	ok, failed := testEvent(true), testEvent(false)
+	cases := []struct {
+		filter Filter
+		event  Event
+		want   bool
+	}{
+		{Filter{}, ok, true},
+		{Filter{Modules: []string{"pod"}}, ok, true},
+		{Filter{Modules: []string{"workload"}}, ok, false},
+		{Filter{Actions: []string{"create", "delete"}}, ok, true},
+		{Filter{Success: models.AuditSinkSuccessFailure}, ok, false},
+		{Filter{Success: models.AuditSinkSuccessFailure}, failed, true},
+		{Filter{Success: models.AuditSinkSuccessOnly}, failed, false},
+	}
+	for i, tc := range cases {
+		if got := tc.filter.Match(&tc.event); got != tc.want {
+			t.Errorf("case %d: got %v, want %v", i, got, tc.want)
+		}
+	}
+}
+
+func TestFormatCEFEscapes(t *testing.T) {
+	e := testEvent(true)
+	line := FormatCEFLine(&e)
+	if !strings.HasPrefix(line, "CEF:0|KubePolaris|KubePolaris|1.0|pod:delete|pod delete web\\|0=x|3|") {
+		t.Fatalf("unexpected header: %s", line)
+	}
+	for _, want := range []string{"suser=alice", "externalId=operation_logs:42", "fname=web|0\\=x", "outcome=success"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("missing %q in %s", want, line)
+		}
+	}
+}
+
+func TestWebhookSignsBatch(t *testing.T) {
+	var gotSig, gotTS string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig, gotTS = r.Header.Get(HeaderSignature), r.Header.Get(HeaderTimestamp)
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(models.AuditSinkWebhook, Config{URL: server.URL}, "s3cret")
+	if err != nil {
+		t.Fatalf("new sink: %v", err)
+	}
+	if err := sink.Send(context.Background(), []Event{testEvent(true), testEvent(false)}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if gotSig != Sign([]byte("s3cret"), gotTS, body) {
+		t.Fatalf("signature mismatch: %s", gotSig)
+	}
+	var batch struct {
+		Events []Event `json:"events"`
+	}
+	if err := json.Unmarshal(body, &batch); err != nil || len(batch.Events) != 2 {
+		t.Fatalf("unexpected body %s: %v", body, err)
+	}
+}
+
+func TestWebhookNon2xxFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sink, _ := NewSink(models.AuditSinkWebhook, Config{URL: server.URL}, "")
+	if err := sink.Send(context.Background(), []Event{testEvent(true)}); err == nil {
+		t.Fatal("expected error on 503")
+	}
+}
+
+func TestFileSinkRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "events.jsonl")
+	sink, err := NewSink(models.AuditSinkFile, Config{Path: path, MaxSizeMB: 1, MaxBackups: 2}, "")
+	if err != nil {
+		t.Fatalf("new sink: %v", err)
+	}
+	defer func() { _ = sink.Close() }()
+
+	// 每个事件约 600 字节，写入约 5MB 触发多次轮转
+	events := make([]Event, 3000)
+	for i := range events {
+		events[i] = testEvent(true)
+	}
+	for i := 0; i < 3; i++ {
+		if err := sink.Send(context.Background(), events); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("expected %s: %v", name, err)
+		}
+		if info.Size() > 1<<20 {
+			t.Errorf("%s exceeds max size: %d", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("backups beyond MaxBackups should be dropped")
+	}
+}
+
+func TestSyslogOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		r := bufio.NewReader(conn)
+		var msgs []string
+		for len(msgs) < 2 {
+			lenStr, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(lenStr))
+			buf := make([]byte, n)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return
+			}
+			msgs = append(msgs, string(buf))
+		}
+		received <- msgs
+	}()
+
+	sink, err := NewSink(models.AuditSinkSyslog, Config{Address: ln.Addr().String(), Format: FormatCEF}, "")
+	if err != nil {
+		t.Fatalf("new sink: %v", err)
+	}
+	defer func() { _ = sink.Close() }()
+	if err := sink.Send(context.Background(), []Event{testEvent(true), testEvent(false)}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	select {
+	case msgs := <-received:
+		// facility log audit(13): info=110, warning=108
+		if !strings.HasPrefix(msgs[0], "<110>1 2023-11-14T22:13:20.000Z ") || !strings.HasPrefix(msgs[1], "<108>1 ") {
+			t.Fatalf("unexpected syslog headers: %q", msgs)
+		}
+		if !strings.Contains(msgs[0], " pod - CEF:0|") {
+			t.Fatalf("unexpected syslog message: %q", msgs[0])
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for syslog messages")
+	}
+}
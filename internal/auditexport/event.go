@@ -0,0 +1,216 @@
+// Package auditexport 审计事件外送：将操作日志与终端命令转换为 OCSF 风格的稳定事件结构，
+// 并通过 syslog、Webhook、JSON Lines 文件等目标发送到外部 SIEM。
+package auditexport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/constants"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+// SchemaVersion 事件结构版本，字段只增不改
+const SchemaVersion = "1.0"
+
+// OCSF 事件分类
+const (
+	ClassAPIActivity     = 6003 // 操作日志（API 调用）
+	ClassProcessActivity = 1007 // 终端命令
+	CategoryApplication  = 6
+	CategorySystem       = 1
+)
+
+// 事件状态与严重级别（OCSF status_id / severity_id）
+const (
+	StatusSuccess = 1
+	StatusFailure = 2
+
+	SeverityInformational = 1
+	SeverityMedium        = 3
+)
+
+// Event 外送审计事件
+type Event struct {
+	SchemaVersion string       `json:"schema_version"`
+	Time          int64        `json:"time"` // 毫秒时间戳
+	ClassUID      int          `json:"class_uid"`
+	ClassName     string       `json:"class_name"`
+	CategoryUID   int          `json:"category_uid"`
+	ActivityName  string       `json:"activity_name"`
+	SeverityID    int          `json:"severity_id"`
+	StatusID      int          `json:"status_id"`
+	Status        string       `json:"status"`
+	StatusCode    int          `json:"status_code,omitempty"`
+	StatusDetail  string       `json:"status_detail,omitempty"`
+	Message       string       `json:"message"`
+	Actor         Actor        `json:"actor"`
+	SrcEndpoint   *Endpoint    `json:"src_endpoint,omitempty"`
+	HTTPRequest   *HTTPRequest `json:"http_request,omitempty"`
+	Process       *Process     `json:"process,omitempty"`
+	Resource      *Resource    `json:"resource,omitempty"`
+	Metadata      Metadata     `json:"metadata"`
+	Unmapped      Unmapped     `json:"unmapped"`
+}
+
+// Actor 操作者
+type Actor struct {
+	UserID   uint   `json:"user_uid,omitempty"`
+	UserName string `json:"user_name,omitempty"`
+	TenantID uint   `json:"tenant_uid,omitempty"`
+}
+
+// Endpoint 来源端点
+type Endpoint struct {
+	IP        string `json:"ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+// HTTPRequest 请求信息
+type HTTPRequest struct {
+	Method string `json:"http_method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Body   string `json:"body,omitempty"` // 已脱敏的请求体
+}
+
+// Process 终端命令
+type Process struct {
+	CmdLine    string `json:"cmd_line"`
+	ExitCode   *int   `json:"exit_code,omitempty"`
+	SessionUID uint   `json:"session_uid"`
+	Terminal   string `json:"terminal,omitempty"` // 终端类型
+	PolicyRule string `json:"policy_rule,omitempty"`
+}
+
+// Resource 操作对象
+type Resource struct {
+	ClusterID uint   `json:"cluster_uid,omitempty"`
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// Metadata 事件元数据
+type Metadata struct {
+	Product  string `json:"product"`
+	Vendor   string `json:"vendor"`
+	Version  string `json:"version"`
+	UID      string `json:"uid"` // 来源表与主键，如 operation_logs:42
+	ChainSeq int64  `json:"chain_seq,omitempty"`
+}
+
+// Unmapped 未映射到 OCSF 的 KubePolaris 字段（用于过滤）
+type Unmapped struct {
+	Module          string `json:"module"`
+	Action          string `json:"action"`
+	ChangeRequestID uint   `json:"change_request_id,omitempty"` // 双人审批执行的变更
+	Approver        *Actor `json:"approver,omitempty"`
+}
+
+// Success 事件是否成功
+func (e *Event) Success() bool {
+	return e.StatusID == StatusSuccess
+}
+
+func newEvent(classUID, categoryUID int, className string, t time.Time, success bool) Event {
+	e := Event{
+		SchemaVersion: SchemaVersion,
+		Time:          t.UnixMilli(),
+		ClassUID:      classUID,
+		ClassName:     className,
+		CategoryUID:   categoryUID,
+		SeverityID:    SeverityInformational,
+		StatusID:      StatusSuccess,
+		Status:        "Success",
+		Metadata:      Metadata{Product: "KubePolaris", Vendor: "KubePolaris", Version: SchemaVersion},
+	}
+	if !success {
+		e.SeverityID = SeverityMedium
+		e.StatusID = StatusFailure
+		e.Status = "Failure"
+	}
+	return e
+}
+
+func chainSeq(link *models.ChainLink) int64 {
+	if link.ChainSeq == nil {
+		return 0
+	}
+	return *link.ChainSeq
+}
+
+// FromOperationLog 由操作日志生成事件
+func FromOperationLog(l *models.OperationLog) Event {
+	e := newEvent(ClassAPIActivity, CategoryApplication, "API Activity", l.CreatedAt, l.Success)
+	e.ActivityName = l.Action
+	e.StatusCode = l.StatusCode
+	e.StatusDetail = l.ErrorMessage
+	e.Message = strings.TrimSpace(fmt.Sprintf("%s %s %s", l.Module, l.Action, l.ResourceName))
+	e.Actor = Actor{UserName: l.Username}
+	if l.UserID != nil {
+		e.Actor.UserID = *l.UserID
+	}
+	if l.TenantID != nil {
+		e.Actor.TenantID = *l.TenantID
+	}
+	e.SrcEndpoint = &Endpoint{IP: l.ClientIP, UserAgent: l.UserAgent}
+	e.HTTPRequest = &HTTPRequest{Method: l.Method, Path: l.Path, Query: l.Query, Body: l.RequestBody}
+	e.Resource = &Resource{Cluster: l.ClusterName, Namespace: l.Namespace, Type: l.ResourceType, Name: l.ResourceName}
+	if l.ClusterID != nil {
+		e.Resource.ClusterID = *l.ClusterID
+	}
+	e.Metadata.UID = l.TableName() + ":" + strconv.FormatUint(uint64(l.ID), 10)
+	e.Metadata.ChainSeq = chainSeq(&l.ChainLink)
+	e.Unmapped = Unmapped{Module: l.Module, Action: l.Action}
+	if l.ChangeRequestID != nil {
+		e.Unmapped.ChangeRequestID = *l.ChangeRequestID
+		e.Unmapped.Approver = &Actor{UserName: l.ApproverName}
+		if l.ApproverID != nil {
+			e.Unmapped.Approver.UserID = *l.ApproverID
+		}
+	}
+	return e
+}
+
+// FromTerminalCommand 由终端命令生成事件，session 需预加载 User 与 Cluster
+func FromTerminalCommand(c *models.TerminalCommand, session *models.TerminalSession) Event {
+	action := constants.ActionCommand
+	if c.Blocked {
+		action = constants.ActionCommandBlocked
+	}
+	e := newEvent(ClassProcessActivity, CategorySystem, "Process Activity", c.Timestamp, !c.Blocked)
+	e.ActivityName = action
+	e.Message = c.ParsedCmd
+	if c.Blocked {
+		e.StatusDetail = "blocked by terminal command rule " + c.PolicyRule
+	}
+	e.Process = &Process{
+		CmdLine:    c.ParsedCmd,
+		ExitCode:   c.ExitCode,
+		SessionUID: c.SessionID,
+		PolicyRule: c.PolicyRule,
+	}
+	if session != nil {
+		e.Actor = Actor{UserID: session.UserID, UserName: session.User.Username}
+		e.Process.Terminal = session.TargetType
+		name := session.Pod
+		if name == "" {
+			name = session.Node
+		}
+		e.Resource = &Resource{
+			ClusterID: session.ClusterID,
+			Cluster:   session.Cluster.Name,
+			Namespace: session.Namespace,
+			Type:      session.TargetType,
+			Name:      name,
+		}
+	}
+	e.Metadata.UID = c.TableName() + ":" + strconv.FormatUint(uint64(c.ID), 10)
+	e.Metadata.ChainSeq = chainSeq(&c.ChainLink)
+	e.Unmapped = Unmapped{Module: constants.ModuleTerminal, Action: action}
+	return e
+}
@@ -0,0 +1,101 @@
+package auditexport
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileSink 追加写入 JSON Lines（或 CEF）文件，超过大小上限时轮转为 path.1 … path.N
+type fileSink struct {
+	cfg Config
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func newFileSink(cfg Config) *fileSink {
+	return &fileSink{cfg: cfg}
+}
+
+func (s *fileSink) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.cfg.Path), 0o750); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640) // #nosec G304 -- 路径由管理员配置
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	s.file, s.size = f, info.Size()
+	return nil
+}
+
+// rotate 关闭当前文件并依次后移备份，超出 MaxBackups（至少 1 个）的最旧文件被覆盖
+func (s *fileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	s.file = nil
+	for i := max(s.cfg.MaxBackups, 1) - 1; i >= 1; i-- {
+		from := fmt.Sprintf("%s.%d", s.cfg.Path, i)
+		if _, err := os.Stat(from); err == nil {
+			if err := os.Rename(from, fmt.Sprintf("%s.%d", s.cfg.Path, i+1)); err != nil {
+				return err
+			}
+		}
+	}
+	return os.Rename(s.cfg.Path, s.cfg.Path+".1")
+}
+
+func (s *fileSink) Send(_ context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	maxSize := int64(s.cfg.MaxSizeMB) << 20
+	for i := range events {
+		line, err := Format(&events[i], s.cfg.Format)
+		if err != nil {
+			return err
+		}
+		line = append(line, '\n')
+
+		if s.file == nil {
+			if err := s.open(); err != nil {
+				return fmt.Errorf("打开审计文件失败: %w", err)
+			}
+		}
+		if maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > maxSize {
+			if err := s.rotate(); err != nil {
+				return fmt.Errorf("轮转审计文件失败: %w", err)
+			}
+			if err := s.open(); err != nil {
+				return fmt.Errorf("打开审计文件失败: %w", err)
+			}
+		}
+		n, err := s.file.Write(line)
+		s.size += int64(n)
+		if err != nil {
+			return fmt.Errorf("写入审计文件失败: %w", err)
+		}
+	}
+	return s.file.Sync()
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,127 @@
+package auditexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 事件行格式
+const (
+	FormatJSON = "json" // 每个事件一行 JSON（默认）
+	FormatCEF  = "cef"  // ArcSight Common Event Format
+)
+
+// Format 按格式序列化单个事件（不含换行）
+func Format(e *Event, format string) ([]byte, error) {
+	switch format {
+	case "", FormatJSON:
+		return json.Marshal(e)
+	case FormatCEF:
+		return []byte(FormatCEFLine(e)), nil
+	}
+	return nil, fmt.Errorf("不支持的事件格式: %s", format)
+}
+
+// cefSeverity 将 OCSF severity_id 映射为 CEF 0-10 级别
+func cefSeverity(e *Event) int {
+	if e.SeverityID >= SeverityMedium {
+		return 5
+	}
+	return 3
+}
+
+// cefField CEF 扩展字段
+type cefField struct{ key, value string }
+
+// FormatCEFLine 生成 CEF:0 格式的事件行
+func FormatCEFLine(e *Event) string {
+	var b strings.Builder
+	b.WriteString("CEF:0|")
+	b.WriteString(cefHeader(e.Metadata.Vendor))
+	b.WriteByte('|')
+	b.WriteString(cefHeader(e.Metadata.Product))
+	b.WriteByte('|')
+	b.WriteString(cefHeader(e.SchemaVersion))
+	b.WriteByte('|')
+	b.WriteString(cefHeader(e.Unmapped.Module + ":" + e.Unmapped.Action))
+	b.WriteByte('|')
+	b.WriteString(cefHeader(e.Message))
+	b.WriteByte('|')
+	b.WriteString(strconv.Itoa(cefSeverity(e)))
+	b.WriteByte('|')
+
+	ext := []cefField{
+		{"rt", strconv.FormatInt(e.Time, 10)},
+		{"externalId", e.Metadata.UID},
+		{"outcome", strings.ToLower(e.Status)},
+		{"suser", e.Actor.UserName},
+		{"cat", e.Unmapped.Module},
+		{"act", e.Unmapped.Action},
+	}
+	if e.Actor.UserID != 0 {
+		ext = append(ext, cefField{"suid", strconv.FormatUint(uint64(e.Actor.UserID), 10)})
+	}
+	if e.SrcEndpoint != nil {
+		ext = append(ext,
+			cefField{"src", e.SrcEndpoint.IP},
+			cefField{"requestClientApplication", e.SrcEndpoint.UserAgent})
+	}
+	if e.HTTPRequest != nil {
+		ext = append(ext,
+			cefField{"requestMethod", e.HTTPRequest.Method},
+			cefField{"request", e.HTTPRequest.Path})
+	}
+	if e.Process != nil {
+		ext = append(ext,
+			cefField{"cs3Label", "session"},
+			cefField{"cs3", strconv.FormatUint(uint64(e.Process.SessionUID), 10)},
+			cefField{"cs4Label", "command"},
+			cefField{"cs4", e.Process.CmdLine})
+	}
+	if e.Resource != nil {
+		ext = append(ext,
+			cefField{"cs1Label", "cluster"},
+			cefField{"cs1", e.Resource.Cluster},
+			cefField{"cs2Label", "namespace"},
+			cefField{"cs2", e.Resource.Namespace},
+			cefField{"fileType", e.Resource.Type},
+			cefField{"fname", e.Resource.Name})
+	}
+	if e.StatusCode != 0 {
+		ext = append(ext, cefField{"cn1Label", "statusCode"},
+			cefField{"cn1", strconv.Itoa(e.StatusCode)})
+	}
+	if e.StatusDetail != "" {
+		ext = append(ext, cefField{"reason", e.StatusDetail})
+	}
+
+	first := true
+	for _, kv := range ext {
+		if kv.value == "" {
+			continue
+		}
+		if !first {
+			b.WriteByte(' ')
+		}
+		first = false
+		b.WriteString(kv.key)
+		b.WriteByte('=')
+		b.WriteString(cefExtension(kv.value))
+	}
+	return b.String()
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+)
+
+func cefHeader(s string) string {
+	return cefHeaderEscaper.Replace(s)
+}
+
+func cefExtension(s string) string {
+	return cefExtensionEscaper.Replace(s)
+}
@@ -0,0 +1,132 @@
+package auditexport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+// Sink 审计事件外送目标。Send 返回错误时整批事件留在发件箱中稍后重试，
+// 因此目标端需按 metadata.uid 容忍重复事件（至少一次投递）。
+type Sink interface {
+	Send(ctx context.Context, events []Event) error
+	Close() error
+}
+
+// Config 外送目标配置（按类型使用其中的字段）
+type Config struct {
+	// syslog
+	Address       string `json:"address,omitempty"` // host:port
+	TLS           bool   `json:"tls,omitempty"`
+	TLSSkipVerify bool   `json:"tls_skip_verify,omitempty"`
+	CACert        string `json:"ca_cert,omitempty"` // PEM，为空时使用系统根证书
+	AppName       string `json:"app_name,omitempty"`
+
+	// webhook
+	URL            string            `json:"url,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	TimeoutSeconds int               `json:"timeout_seconds,omitempty"`
+
+	// file
+	Path       string `json:"path,omitempty"`
+	MaxSizeMB  int    `json:"max_size_mb,omitempty"` // 超过后轮转，0 表示不轮转
+	MaxBackups int    `json:"max_backups,omitempty"` // 保留的轮转文件数（至少 1 个）
+
+	// syslog / file 的事件格式：json（默认）或 cef
+	Format string `json:"format,omitempty"`
+	// BatchSize 每次发送的最大事件数
+	BatchSize int `json:"batch_size,omitempty"`
+}
+
+// DefaultBatchSize 未配置时每批发送的事件数
+const DefaultBatchSize = 100
+
+// Batch 每批发送的事件数
+func (c *Config) Batch() int {
+	if c.BatchSize <= 0 {
+		return DefaultBatchSize
+	}
+	return c.BatchSize
+}
+
+// Validate 按目标类型校验配置
+func (c *Config) Validate(sinkType string) error {
+	switch c.Format {
+	case "", FormatJSON, FormatCEF:
+	default:
+		return errors.New("事件格式只能为 json 或 cef")
+	}
+	switch sinkType {
+	case models.AuditSinkSyslog:
+		if c.Address == "" {
+			return errors.New("syslog 地址不能为空")
+		}
+	case models.AuditSinkWebhook:
+		if c.URL == "" {
+			return errors.New("Webhook URL 不能为空")
+		}
+	case models.AuditSinkFile:
+		if c.Path == "" {
+			return errors.New("文件路径不能为空")
+		}
+	default:
+		return errors.New("目标类型只能为 syslog、webhook 或 file")
+	}
+	return nil
+}
+
+// NewSink 按类型创建外送目标，secret 用于 Webhook 签名
+func NewSink(sinkType string, cfg Config, secret string) (Sink, error) {
+	if err := cfg.Validate(sinkType); err != nil {
+		return nil, err
+	}
+	switch sinkType {
+	case models.AuditSinkSyslog:
+		return newSyslogSink(cfg)
+	case models.AuditSinkWebhook:
+		return newWebhookSink(cfg, secret), nil
+	case models.AuditSinkFile:
+		return newFileSink(cfg), nil
+	}
+	return nil, fmt.Errorf("不支持的目标类型: %s", sinkType)
+}
+
+// Filter 目标的事件过滤条件
+type Filter struct {
+	Modules []string
+	Actions []string
+	Success string // 空表示全部，success / failure
+}
+
+// FilterOf 目标的事件过滤条件
+func FilterOf(sink *models.AuditSink) Filter {
+	return Filter{Modules: sink.GetModuleList(), Actions: sink.GetActionList(), Success: sink.SuccessFilter}
+}
+
+// Match 事件是否满足过滤条件
+func (f Filter) Match(e *Event) bool {
+	if len(f.Modules) > 0 && !contains(f.Modules, e.Unmapped.Module) {
+		return false
+	}
+	if len(f.Actions) > 0 && !contains(f.Actions, e.Unmapped.Action) {
+		return false
+	}
+	switch f.Success {
+	case models.AuditSinkSuccessOnly:
+		return e.Success()
+	case models.AuditSinkSuccessFailure:
+		return !e.Success()
+	}
+	return true
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
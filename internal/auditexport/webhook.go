@@ -0,0 +1,89 @@
+package auditexport
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Webhook 请求头
+const (
+	HeaderTimestamp = "X-KubePolaris-Timestamp"
+	HeaderSignature = "X-KubePolaris-Signature"
+)
+
+const defaultWebhookTimeout = 10 * time.Second
+
+// webhookSink 以 JSON 批量 POST 事件；配置了密钥时附带 HMAC-SHA256 签名
+type webhookSink struct {
+	cfg    Config
+	secret []byte
+	client *http.Client
+}
+
+func newWebhookSink(cfg Config, secret string) *webhookSink {
+	timeout := defaultWebhookTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	return &webhookSink{cfg: cfg, secret: []byte(secret), client: &http.Client{Timeout: timeout}}
+}
+
+// Sign 计算 Webhook 签名：hex(HMAC-SHA256(secret, timestamp + "." + body))
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBatch Webhook 请求体
+type webhookBatch struct {
+	SchemaVersion string  `json:"schema_version"`
+	Events        []Event `json:"events"`
+}
+
+func (s *webhookSink) Send(ctx context.Context, events []Event) error {
+	body, err := json.Marshal(webhookBatch{SchemaVersion: SchemaVersion, Events: events})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if len(s.secret) > 0 {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(HeaderTimestamp, ts)
+		req.Header.Set(HeaderSignature, Sign(s.secret, ts, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求 Webhook 失败: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("Webhook 返回 %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
+
+func (s *webhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
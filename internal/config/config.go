@@ -8,19 +8,112 @@ import (
 
 // Config 应用配置结构
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	Log      LogConfig      `mapstructure:"log"`
-	K8s      K8sConfig      `mapstructure:"k8s"`
-	Terminal TerminalConfig `mapstructure:"terminal"`
-	Arthas   ArthasConfig   `mapstructure:"arthas"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	JWT         JWTConfig         `mapstructure:"jwt"`
+	Log         LogConfig         `mapstructure:"log"`
+	K8s         K8sConfig         `mapstructure:"k8s"`
+	Terminal    TerminalConfig    `mapstructure:"terminal"`
+	Arthas      ArthasConfig      `mapstructure:"arthas"`
+	PortForward PortForwardConfig `mapstructure:"port_forward"`
+	SSHVault    SSHVaultConfig    `mapstructure:"ssh_vault"`
+	AuditChain  AuditChainConfig  `mapstructure:"audit_chain"`
+	AuditRetain AuditRetainConfig `mapstructure:"audit_retain"`
+	K8sAudit    K8sAuditConfig    `mapstructure:"k8s_audit"`
+	Notify      NotifyConfig      `mapstructure:"notify"`
+}
+
+// NotifyConfig 事件通知
+type NotifyConfig struct {
+	// ClusterProbeSeconds 集群连通性探测间隔（0 表示不探测，也不会产生集群不可达事件）
+	ClusterProbeSeconds int `mapstructure:"cluster_probe_seconds"`
+	// DeliveryRetainDays 投递记录保留天数（0 表示不清理）
+	DeliveryRetainDays int `mapstructure:"delivery_retain_days"`
+}
+
+// K8sAuditConfig Kubernetes API Server 审计事件接收
+type K8sAuditConfig struct {
+	// RetainDays 事件保留天数（0 表示不清理）
+	RetainDays int `mapstructure:"retain_days"`
+}
+
+// AuditRetainConfig 审计记录保留策略：过期记录先归档为压缩的 JSON Lines 文件再从数据库清理（天数为 0 表示不清理）
+type AuditRetainConfig struct {
+	OperationLogDays    int `mapstructure:"operation_log_days"`    // 操作日志（含对象快照）
+	TerminalSessionDays int `mapstructure:"terminal_session_days"` // 终端会话（含会话事件、录像索引与录像文件）
+	TerminalCommandDays int `mapstructure:"terminal_command_days"` // 终端命令
+	// ArchiveDir 归档文件目录
+	ArchiveDir string `mapstructure:"archive_dir"`
+	// IntervalHours 清理任务执行间隔（小时）
+	IntervalHours int `mapstructure:"interval_hours"`
+}
+
+// AuditChainConfig 审计哈希链（防篡改）
+type AuditChainConfig struct {
+	// Key 计算链上 HMAC 的密钥，为空时使用 JWT Secret；更换密钥后已有记录将无法通过校验
+	Key string `mapstructure:"key"`
+	// AnchorIntervalMinutes 链头锚定间隔（分钟，0 表示不定期锚定）
+	AnchorIntervalMinutes int `mapstructure:"anchor_interval_minutes"`
+}
+
+// SSHVaultConfig 节点 SSH 凭据库
+type SSHVaultConfig struct {
+	// CredentialKey 凭据加密密钥，为空时使用 JWT Secret
+	CredentialKey string `mapstructure:"credential_key"`
+	// CertTTLMinutes 每次会话签发的 SSH 证书默认有效期（分钟）
+	CertTTLMinutes int `mapstructure:"cert_ttl_minutes"`
+}
+
+// PortForwardConfig 端口转发网关
+type PortForwardConfig struct {
+	MaxPerUser        int `mapstructure:"max_per_user"`        // 每个用户同时活跃的转发数上限
+	DefaultTTLMinutes int `mapstructure:"default_ttl_minutes"` // 未指定时的有效期
+	MaxTTLMinutes     int `mapstructure:"max_ttl_minutes"`     // 可申请的最长有效期
+	// ProxyBaseURL HTTP 代理使用的独立域名（如 https://pf.example.com，需解析到本服务）。
+	// 未配置时代理与平台同源，页面以 CSP sandbox 运行，依赖自身 Cookie 或 localStorage 的应用可能无法正常使用
+	ProxyBaseURL string `mapstructure:"proxy_base_url"`
 }
 
 // TerminalConfig 终端与会话录像
 type TerminalConfig struct {
-	// ReplayDir 会话 asciicast 存储根目录（空表示禁用录像）
+	// ReplayDir 会话 asciicast 存储根目录（空表示禁用录像）；使用 S3 时仅作为录制中的本地缓存目录
 	ReplayDir string `mapstructure:"replay_dir"`
+	// ReplayStorage 录像存储后端：local（默认，存于 ReplayDir）或 s3（兼容 S3 的对象存储）
+	ReplayStorage string             `mapstructure:"replay_storage"`
+	ReplayS3      ReplayS3Config     `mapstructure:"replay_s3"`
+	ReplayRetain  ReplayRetainConfig `mapstructure:"replay_retain"`
+	// KubectlShellTools 集群 kubectl 终端（服务端受限 shell）可用的命令，PATH 中只包含这些命令。
+	// grep、jq、yq、head、tail、less 等可读文件的命令只能读取会话目录内的文件；helm 默认不提供，
+	// 开启后其插件/数据/配置目录固定为只读空目录。剩余风险：rbash 不限制输入重定向（如 $(< /etc/passwd)），
+	// shell 用户仍可读取服务器上对其可读的文件，kubectl -f 也可读取任意可读文件，
+	// 因此服务器上的敏感文件（配置、证书、数据库）必须对 kubectl_shell_user 不可读
+	KubectlShellTools []string `mapstructure:"kubectl_shell_tools"`
+	// KubectlShellUser 运行 kubectl 终端 shell 的系统用户（用户名或 UID），不能是 root 或服务进程自身的用户；
+	// 服务需以 root 运行或具备 CAP_SETUID/CAP_SETGID 才能切换用户
+	KubectlShellUser string `mapstructure:"kubectl_shell_user"`
+	// DebugImages 临时调试容器 / 节点调试 Pod 可选镜像，第一个为默认镜像
+	DebugImages []string `mapstructure:"debug_images"`
+	// FileTransferMaxMB Pod 文件上传/下载的单次大小上限（MB）
+	FileTransferMaxMB int64 `mapstructure:"file_transfer_max_mb"`
+	// NodeShellImage Pod 方式节点终端使用的镜像，需包含 nsenter
+	NodeShellImage string `mapstructure:"node_shell_image"`
+}
+
+// ReplayS3Config 录像 S3 存储配置
+type ReplayS3Config struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+	Prefix    string `mapstructure:"prefix"`
+	PathStyle bool   `mapstructure:"path_style"`
+}
+
+// ReplayRetainConfig 录像保留策略（0 表示不限制）
+type ReplayRetainConfig struct {
+	Days       int   `mapstructure:"days"`         // 按会话开始时间保留天数
+	MaxTotalMB int64 `mapstructure:"max_total_mb"` // 录像总大小上限，超出时从最早的会话开始清理
 }
 
 // ArthasConfig Arthas Agent 配置
@@ -106,6 +199,49 @@ func Load() *Config {
 
 	// 终端录像
 	_ = viper.BindEnv("terminal.replay_dir", "TERMINAL_REPLAY_DIR")
+	_ = viper.BindEnv("terminal.replay_storage", "TERMINAL_REPLAY_STORAGE")
+	_ = viper.BindEnv("terminal.replay_s3.endpoint", "TERMINAL_REPLAY_S3_ENDPOINT")
+	_ = viper.BindEnv("terminal.replay_s3.region", "TERMINAL_REPLAY_S3_REGION")
+	_ = viper.BindEnv("terminal.replay_s3.bucket", "TERMINAL_REPLAY_S3_BUCKET")
+	_ = viper.BindEnv("terminal.replay_s3.access_key", "TERMINAL_REPLAY_S3_ACCESS_KEY")
+	_ = viper.BindEnv("terminal.replay_s3.secret_key", "TERMINAL_REPLAY_S3_SECRET_KEY")
+	_ = viper.BindEnv("terminal.replay_s3.prefix", "TERMINAL_REPLAY_S3_PREFIX")
+	_ = viper.BindEnv("terminal.replay_s3.path_style", "TERMINAL_REPLAY_S3_PATH_STYLE")
+	_ = viper.BindEnv("terminal.replay_retain.days", "TERMINAL_REPLAY_RETAIN_DAYS")
+	_ = viper.BindEnv("terminal.replay_retain.max_total_mb", "TERMINAL_REPLAY_MAX_TOTAL_MB")
+	_ = viper.BindEnv("terminal.kubectl_shell_tools", "TERMINAL_KUBECTL_SHELL_TOOLS")
+	_ = viper.BindEnv("terminal.kubectl_shell_user", "TERMINAL_KUBECTL_SHELL_USER")
+	_ = viper.BindEnv("terminal.debug_images", "TERMINAL_DEBUG_IMAGES")
+	_ = viper.BindEnv("terminal.file_transfer_max_mb", "TERMINAL_FILE_TRANSFER_MAX_MB")
+	_ = viper.BindEnv("terminal.node_shell_image", "TERMINAL_NODE_SHELL_IMAGE")
+
+	// 端口转发
+	_ = viper.BindEnv("port_forward.max_per_user", "PORT_FORWARD_MAX_PER_USER")
+	_ = viper.BindEnv("port_forward.default_ttl_minutes", "PORT_FORWARD_DEFAULT_TTL_MINUTES")
+	_ = viper.BindEnv("port_forward.max_ttl_minutes", "PORT_FORWARD_MAX_TTL_MINUTES")
+	_ = viper.BindEnv("port_forward.proxy_base_url", "PORT_FORWARD_PROXY_BASE_URL")
+
+	// 节点 SSH 凭据库
+	_ = viper.BindEnv("ssh_vault.credential_key", "SSH_VAULT_CREDENTIAL_KEY")
+	_ = viper.BindEnv("ssh_vault.cert_ttl_minutes", "SSH_VAULT_CERT_TTL_MINUTES")
+
+	// 审计哈希链
+	_ = viper.BindEnv("audit_chain.key", "AUDIT_CHAIN_KEY")
+	_ = viper.BindEnv("audit_chain.anchor_interval_minutes", "AUDIT_CHAIN_ANCHOR_INTERVAL_MINUTES")
+
+	// 审计记录保留与归档
+	_ = viper.BindEnv("audit_retain.operation_log_days", "AUDIT_RETAIN_OPERATION_LOG_DAYS")
+	_ = viper.BindEnv("audit_retain.terminal_session_days", "AUDIT_RETAIN_TERMINAL_SESSION_DAYS")
+	_ = viper.BindEnv("audit_retain.terminal_command_days", "AUDIT_RETAIN_TERMINAL_COMMAND_DAYS")
+	_ = viper.BindEnv("audit_retain.archive_dir", "AUDIT_ARCHIVE_DIR")
+	_ = viper.BindEnv("audit_retain.interval_hours", "AUDIT_RETAIN_INTERVAL_HOURS")
+
+	// K8s API Server 审计事件
+	_ = viper.BindEnv("k8s_audit.retain_days", "K8S_AUDIT_RETAIN_DAYS")
+
+	// 事件通知
+	_ = viper.BindEnv("notify.cluster_probe_seconds", "NOTIFY_CLUSTER_PROBE_SECONDS")
+	_ = viper.BindEnv("notify.delivery_retain_days", "NOTIFY_DELIVERY_RETAIN_DAYS")
 
 	// Arthas Agent
 	_ = viper.BindEnv("arthas.enabled", "ARTHAS_ENABLED")
@@ -163,6 +299,41 @@ func setDefaults() {
 
 	// 终端录像（默认开启，目录可写即可）
 	viper.SetDefault("terminal.replay_dir", "./data/terminal_replays")
+	viper.SetDefault("terminal.replay_storage", "local")
+	viper.SetDefault("terminal.replay_s3.region", "us-east-1")
+	viper.SetDefault("terminal.replay_s3.path_style", true)
+	viper.SetDefault("terminal.replay_retain.days", 0)
+	viper.SetDefault("terminal.replay_retain.max_total_mb", 0)
+	viper.SetDefault("terminal.kubectl_shell_tools", []string{
+		"kubectl", "jq", "yq", "grep", "egrep", "head", "tail", "less", "sort", "uniq",
+		"wc", "cut", "tr", "column", "base64", "clear",
+	}) // vi/vim、helm 需显式开启：vi/vim 以受限模式（vim -Z）运行，helm 不加载服务器上的插件与仓库配置
+	viper.SetDefault("terminal.kubectl_shell_user", "nobody")
+	viper.SetDefault("terminal.debug_images", []string{"busybox:1.36", "nicolaka/netshoot:v0.13"})
+	viper.SetDefault("terminal.file_transfer_max_mb", 100)
+	viper.SetDefault("terminal.node_shell_image", "busybox:1.36")
+
+	// 端口转发默认配置
+	viper.SetDefault("port_forward.max_per_user", 5)
+	viper.SetDefault("port_forward.default_ttl_minutes", 60)
+	viper.SetDefault("port_forward.max_ttl_minutes", 480)
+
+	// 节点 SSH 凭据库默认配置
+	viper.SetDefault("ssh_vault.cert_ttl_minutes", 5)
+
+	// 审计哈希链默认配置
+	viper.SetDefault("audit_chain.anchor_interval_minutes", 10)
+
+	// 审计记录保留默认配置（默认不清理）
+	viper.SetDefault("audit_retain.archive_dir", "./data/audit_archive")
+	viper.SetDefault("audit_retain.interval_hours", 24)
+
+	// K8s API Server 审计事件默认配置
+	viper.SetDefault("k8s_audit.retain_days", 30)
+
+	// 事件通知默认配置
+	viper.SetDefault("notify.cluster_probe_seconds", 60)
+	viper.SetDefault("notify.delivery_retain_days", 30)
 
 	// Arthas Agent 默认配置
 	viper.SetDefault("arthas.enabled", true)
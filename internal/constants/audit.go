@@ -2,21 +2,24 @@ package constants
 
 // 操作模块定义
 const (
-	ModuleAuth       = "auth"       // 认证：登录、登出、密码修改
-	ModuleCluster    = "cluster"    // 集群：导入、删除、配置
-	ModuleNode       = "node"       // 节点：cordon、uncordon、drain
-	ModulePod        = "pod"        // Pod：删除
-	ModuleWorkload   = "workload"   // 工作负载：deployment/sts/ds/job/cronjob
-	ModuleConfig     = "config"     // 配置：configmap、secret
-	ModuleNetwork    = "network"    // 网络：service、ingress
-	ModuleStorage    = "storage"    // 存储：pvc、pv、storageclass
-	ModuleNamespace  = "namespace"  // 命名空间
-	ModulePermission = "permission" // 权限：用户组、集群权限
-	ModuleSystem     = "system"     // 系统：LDAP、SSH配置
-	ModuleMonitoring = "monitoring" // 监控：Prometheus、Grafana配置
-	ModuleAlert      = "alert"      // 告警：AlertManager、静默规则
-	ModuleArgoCD     = "argocd"     // GitOps：ArgoCD应用
-	ModuleUnknown    = "unknown"    // 未知模块
+	ModuleAuth        = "auth"         // 认证：登录、登出、密码修改
+	ModuleCluster     = "cluster"      // 集群：导入、删除、配置
+	ModuleNode        = "node"         // 节点：cordon、uncordon、drain
+	ModulePod         = "pod"          // Pod：删除
+	ModuleWorkload    = "workload"     // 工作负载：deployment/sts/ds/job/cronjob
+	ModuleConfig      = "config"       // 配置：configmap、secret
+	ModuleNetwork     = "network"      // 网络：service、ingress
+	ModuleStorage     = "storage"      // 存储：pvc、pv、storageclass
+	ModuleNamespace   = "namespace"    // 命名空间
+	ModulePermission  = "permission"   // 权限：用户组、集群权限
+	ModuleSystem      = "system"       // 系统：LDAP、SSH配置
+	ModuleMonitoring  = "monitoring"   // 监控：Prometheus、Grafana配置
+	ModuleAlert       = "alert"        // 告警：AlertManager、静默规则
+	ModuleArgoCD      = "argocd"       // GitOps：ArgoCD应用
+	ModuleTenant      = "tenant"       // 租户：租户、命名空间归属、成员
+	ModuleTerminal    = "terminal"     // 终端：会话强制终止
+	ModulePortForward = "port_forward" // 端口转发：建立、关闭
+	ModuleUnknown     = "unknown"      // 未知模块
 )
 
 // 操作动作定义
@@ -38,6 +41,9 @@ const (
 	ActionRollback = "rollback"
 	ActionRestart  = "restart"
 
+	// 变更回滚（按操作日志快照恢复对象）
+	ActionRevert = "revert"
+
 	// 节点操作
 	ActionCordon   = "cordon"
 	ActionUncordon = "uncordon"
@@ -51,25 +57,59 @@ const (
 
 	// 导入操作
 	ActionImport = "import"
+
+	// 审批流操作
+	ActionRequest = "request"
+	ActionApprove = "approve"
+	ActionReject  = "reject"
+	ActionCancel  = "cancel"
+	ActionRevoke  = "revoke"
+	ActionExpire  = "expire"
+
+	// 授权复核操作
+	ActionAttest = "attest"
+
+	// 终端会话操作
+	ActionKill           = "kill"
+	ActionCommand        = "command"         // 终端命令（仅外送事件使用）
+	ActionCommandBlocked = "command_blocked" // 被策略拦截的终端命令（仅外送事件使用）
+
+	// 文件传输操作
+	ActionUpload   = "upload"
+	ActionDownload = "download"
+
+	// 审计数据治理（保留清理、用户数据导出与匿名化）
+	ActionPurge        = "purge"
+	ActionExport       = "export"
+	ActionPseudonymize = "pseudonymize"
+
+	// 变更冻结期间紧急放行
+	ActionBreakGlass = "break_glass"
+
+	// 定时报表立即发送
+	ActionSend = "send"
 )
 
 // ModuleNames 模块中文名称映射
 var ModuleNames = map[string]string{
-	ModuleAuth:       "认证管理",
-	ModuleCluster:    "集群管理",
-	ModuleNode:       "节点管理",
-	ModulePod:        "Pod管理",
-	ModuleWorkload:   "工作负载",
-	ModuleConfig:     "配置管理",
-	ModuleNetwork:    "网络管理",
-	ModuleStorage:    "存储管理",
-	ModuleNamespace:  "命名空间",
-	ModulePermission: "权限管理",
-	ModuleSystem:     "系统设置",
-	ModuleMonitoring: "监控配置",
-	ModuleAlert:      "告警管理",
-	ModuleArgoCD:     "GitOps",
-	ModuleUnknown:    "未知",
+	ModuleAuth:        "认证管理",
+	ModuleCluster:     "集群管理",
+	ModuleNode:        "节点管理",
+	ModulePod:         "Pod管理",
+	ModuleWorkload:    "工作负载",
+	ModuleConfig:      "配置管理",
+	ModuleNetwork:     "网络管理",
+	ModuleStorage:     "存储管理",
+	ModuleNamespace:   "命名空间",
+	ModulePermission:  "权限管理",
+	ModuleSystem:      "系统设置",
+	ModuleMonitoring:  "监控配置",
+	ModuleAlert:       "告警管理",
+	ModuleArgoCD:      "GitOps",
+	ModuleTenant:      "租户管理",
+	ModuleTerminal:    "终端管理",
+	ModulePortForward: "端口转发",
+	ModuleUnknown:     "未知",
 }
 
 // ActionNames 操作中文名称映射
@@ -85,10 +125,28 @@ var ActionNames = map[string]string{ // #nosec G101 -- 操作名称映射，非
 	ActionScale:          "扩缩容",
 	ActionRollback:       "回滚",
 	ActionRestart:        "重启",
+	ActionRevert:         "撤销变更",
 	ActionCordon:         "禁止调度",
 	ActionUncordon:       "允许调度",
 	ActionDrain:          "驱逐节点",
 	ActionSync:           "同步",
 	ActionTest:           "测试",
 	ActionImport:         "导入",
+	ActionRequest:        "申请",
+	ActionApprove:        "审批通过",
+	ActionReject:         "审批拒绝",
+	ActionCancel:         "撤回",
+	ActionRevoke:         "撤销",
+	ActionExpire:         "到期回收",
+	ActionAttest:         "复核确认",
+	ActionKill:           "强制终止",
+	ActionCommand:        "执行命令",
+	ActionCommandBlocked: "拦截命令",
+	ActionUpload:         "上传文件",
+	ActionDownload:       "下载文件",
+	ActionPurge:          "清理归档",
+	ActionExport:         "导出数据",
+	ActionPseudonymize:   "匿名化",
+	ActionBreakGlass:     "紧急放行",
+	ActionSend:           "发送报表",
 }
@@ -171,14 +171,42 @@ func autoMigrate(db *gorm.DB) error {
 		&models.ClusterMetrics{},
 		&models.TerminalSession{},
 		&models.TerminalCommand{},
+		&models.TerminalSessionEvent{},
+		&models.TerminalReplayIndex{},
 		&models.AuditLog{},
-		&models.OperationLog{},      // 操作审计日志表（新增）
-		&models.SystemSetting{},     // 系统设置表
-		&models.ArgoCDConfig{},      // ArgoCD 配置表
-		&models.UserGroup{},         // 用户组表
-		&models.UserGroupMember{},   // 用户组成员关联表
-		&models.ClusterPermission{}, // 集群权限表
-		&models.AIConfig{},          // AI 配置表
+		&models.OperationLog{},         // 操作审计日志表（新增）
+		&models.SystemSetting{},        // 系统设置表
+		&models.ArgoCDConfig{},         // ArgoCD 配置表
+		&models.UserGroup{},            // 用户组表
+		&models.UserGroupMember{},      // 用户组成员关联表
+		&models.ClusterPermission{},    // 集群权限表
+		&models.AIConfig{},             // AI 配置表
+		&models.AccessRequest{},        // 临时提权申请表
+		&models.PermissionPolicy{},     // 细粒度权限策略表
+		&models.ClusterGroup{},         // 集群分组表
+		&models.Tenant{},               // 租户表
+		&models.TenantNamespace{},      // 租户命名空间表
+		&models.TenantMember{},         // 租户成员表
+		&models.AccessReview{},         // 授权复核记录表
+		&models.TerminalCommandRule{},  // 终端命令策略规则表
+		&models.PortForwardSession{},   // 端口转发会话表
+		&models.SSHCredentialProfile{}, // 节点 SSH 凭据配置表
+		&models.SSHHostKey{},           // 节点 SSH 主机密钥表
+		&models.AuditChainAnchor{},     // 审计哈希链锚点表
+		&models.AuditSink{},            // 审计外送目标表
+		&models.AuditOutbox{},          // 审计外送发件箱表
+		&models.OperationLogSnapshot{}, // 操作日志对象变更快照表
+		&models.AuditRetentionRun{},    // 审计记录清理任务表
+		&models.K8sAuditEvent{},        // K8s API Server 审计事件表
+		&models.K8sAuditWebhook{},      // K8s 审计 Webhook 接收配置表
+		&models.FreezeCalendar{},       // 变更冻结日历表
+		&models.FreezeWindow{},         // 变更冻结窗口表
+		&models.FreezeOverride{},       // 冻结期间紧急放行记录表
+		&models.ChangeApprovalPolicy{}, // 变更双人审批策略表
+		&models.ChangeRequest{},        // 待审批变更表
+		&models.NotificationDelivery{}, // 事件通知投递记录表
+		&models.ReportSchedule{},       // 定时报表表
+		&models.ReportDelivery{},       // 定时报表发送记录表
 	)
 
 	// 根据数据库驱动类型重新启用外键约束检查
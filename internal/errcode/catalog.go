@@ -0,0 +1,885 @@
+package errcode
+
+import "net/http"
+
+// 通用错误码（与历史响应中的 code 保持一致）
+const (
+	BadRequest         Code = "BAD_REQUEST"
+	Unauthorized       Code = "UNAUTHORIZED"
+	Forbidden          Code = "FORBIDDEN"
+	NotFound           Code = "NOT_FOUND"
+	Conflict           Code = "CONFLICT"
+	Internal           Code = "INTERNAL_ERROR"
+	ServiceUnavailable Code = "SERVICE_UNAVAILABLE"
+)
+
+// 请求参数
+const (
+	InvalidClusterID             Code = "INVALID_CLUSTER_ID"
+	InvalidUserID                Code = "INVALID_USER_ID"
+	InvalidUserGroupID           Code = "INVALID_USER_GROUP_ID"
+	InvalidSessionID             Code = "INVALID_SESSION_ID"
+	InvalidPermissionID          Code = "INVALID_PERMISSION_ID"
+	InvalidTenantID              Code = "INVALID_TENANT_ID"
+	InvalidLogID                 Code = "INVALID_LOG_ID"
+	InvalidYAML                  Code = "INVALID_YAML"
+	YAMLMissingKind              Code = "YAML_MISSING_KIND"
+	YAMLMissingMetadata          Code = "YAML_MISSING_METADATA"
+	YAMLOrFormRequired           Code = "YAML_OR_FORM_REQUIRED"
+	UnsupportedLanguage          Code = "UNSUPPORTED_LANGUAGE"
+	BodyReadFailed               Code = "BODY_READ_FAILED"
+	InvalidID                    Code = "INVALID_ID"
+	InvalidAccessRequestID       Code = "INVALID_ACCESS_REQUEST_ID"
+	InvalidAuditSinkID           Code = "INVALID_AUDIT_SINK_ID"
+	InvalidClusterGroupID        Code = "INVALID_CLUSTER_GROUP_ID"
+	InvalidDeliveryID            Code = "INVALID_DELIVERY_ID"
+	InvalidSnapshotID            Code = "INVALID_SNAPSHOT_ID"
+	InvalidPolicyID              Code = "INVALID_POLICY_ID"
+	InvalidNamespaceID           Code = "INVALID_NAMESPACE_ID"
+	InvalidRuleID                Code = "INVALID_RULE_ID"
+	InvalidLabelKey              Code = "INVALID_LABEL_KEY"
+	InvalidLabelSelector         Code = "INVALID_LABEL_SELECTOR"
+	UnsupportedExportFormat      Code = "UNSUPPORTED_EXPORT_FORMAT"
+	SearchKeywordRequired        Code = "SEARCH_KEYWORD_REQUIRED"
+	NamespaceAndPodRequired      Code = "NAMESPACE_AND_POD_REQUIRED"
+	NamespaceAndWorkloadRequired Code = "NAMESPACE_AND_WORKLOAD_REQUIRED"
+	NodeNameRequired             Code = "NODE_NAME_REQUIRED"
+	ActionsRequired              Code = "ACTIONS_REQUIRED"
+	CommandRequired              Code = "COMMAND_REQUIRED"
+	MessageRequired              Code = "MESSAGE_REQUIRED"
+	SilenceIDRequired            Code = "SILENCE_ID_REQUIRED"
+	SubjectRequired              Code = "SUBJECT_REQUIRED"
+)
+
+// 认证
+const (
+	NotLoggedIn          Code = "NOT_LOGGED_IN"
+	InvalidAuthContext   Code = "INVALID_AUTH_CONTEXT"
+	TokenMissing         Code = "TOKEN_MISSING"
+	TokenMalformed       Code = "TOKEN_MALFORMED"
+	TokenInvalid         Code = "TOKEN_INVALID"
+	TokenMissingUserID   Code = "TOKEN_MISSING_USER_ID"
+	TokenSignFailed      Code = "TOKEN_SIGN_FAILED"
+	InvalidCredentials   Code = "INVALID_CREDENTIALS"
+	AccountDisabled      Code = "ACCOUNT_DISABLED"
+	UnsupportedAuthType  Code = "UNSUPPORTED_AUTH_TYPE"
+	LDAPDisabled         Code = "LDAP_DISABLED"
+	LDAPConfigFailed     Code = "LDAP_CONFIG_FAILED"
+	LDAPAuthFailed       Code = "LDAP_AUTH_FAILED"
+	UserCreateFailed     Code = "USER_CREATE_FAILED"
+	UserQueryFailed      Code = "USER_QUERY_FAILED"
+	LDAPPasswordReadOnly Code = "LDAP_PASSWORD_READONLY"
+	WrongPassword        Code = "WRONG_PASSWORD"
+	PasswordUpdateFailed Code = "PASSWORD_UPDATE_FAILED"
+)
+
+// 权限与租户
+const (
+	PlatformAdminRequired           Code = "PLATFORM_ADMIN_REQUIRED"
+	AdminRequired                   Code = "ADMIN_REQUIRED"
+	ClusterAccessDenied             Code = "CLUSTER_ACCESS_DENIED"
+	NamespaceAccessDenied           Code = "NAMESPACE_ACCESS_DENIED"
+	ReadOnlyPermission              Code = "READ_ONLY_PERMISSION"
+	PolicyDenied                    Code = "POLICY_DENIED"
+	PolicyCheckFailed               Code = "POLICY_CHECK_FAILED"
+	TenantLoadFailed                Code = "TENANT_LOAD_FAILED"
+	TenantDisabled                  Code = "TENANT_DISABLED"
+	TenantNoNamespace               Code = "TENANT_NO_NAMESPACE"
+	TenantClusterTerminal           Code = "TENANT_CLUSTER_TERMINAL_DENIED"
+	TenantClusterResource           Code = "TENANT_CLUSTER_RESOURCE_DENIED"
+	TenantNamespaceMismatch         Code = "TENANT_NAMESPACE_MISMATCH"
+	TenantAdminRequired             Code = "TENANT_ADMIN_REQUIRED"
+	AccessRequestForbidden          Code = "ACCESS_REQUEST_FORBIDDEN"
+	AccessRequestConfigFailed       Code = "ACCESS_REQUEST_CONFIG_FAILED"
+	PermissionCreateFailed          Code = "PERMISSION_CREATE_FAILED"
+	CannotDeleteSelf                Code = "CANNOT_DELETE_SELF"
+	AccessApproverRequired          Code = "ACCESS_APPROVER_REQUIRED"
+	AccessApproverGroupRequired     Code = "ACCESS_APPROVER_GROUP_REQUIRED"
+	UnsupportedAccessPermissionType Code = "UNSUPPORTED_ACCESS_PERMISSION_TYPE"
+	AccessRequestDisabled           Code = "ACCESS_REQUEST_DISABLED"
+	AccessPermissionTypeNotAllowed  Code = "ACCESS_PERMISSION_TYPE_NOT_ALLOWED"
+	AccessDurationTooLong           Code = "ACCESS_DURATION_TOO_LONG"
+	AccessRequestPending            Code = "ACCESS_REQUEST_PENDING"
+	AccessRequestNotFound           Code = "ACCESS_REQUEST_NOT_FOUND"
+	AccessRequestNotPending         Code = "ACCESS_REQUEST_NOT_PENDING"
+	AccessSelfApproval              Code = "ACCESS_SELF_APPROVAL"
+	AccessRequestProcessed          Code = "ACCESS_REQUEST_PROCESSED"
+	AccessRequestNotActive          Code = "ACCESS_REQUEST_NOT_ACTIVE"
+	AccessRBACSyncFailed            Code = "ACCESS_RBAC_SYNC_FAILED"
+)
+
+// 变更管控（冻结与双人审批）
+const (
+	ChangeFrozen             Code = "CHANGE_FROZEN"
+	BreakGlassNotAllowed     Code = "BREAK_GLASS_NOT_ALLOWED"
+	BreakGlassReasonTooShort Code = "BREAK_GLASS_REASON_TOO_SHORT"
+	ApprovalRequired         Code = "APPROVAL_REQUIRED"
+	ApprovalPolicyFailed     Code = "APPROVAL_POLICY_FAILED"
+	ApprovalBodyTooLarge     Code = "APPROVAL_BODY_TOO_LARGE"
+	FreezeCheckFailed        Code = "FREEZE_CHECK_FAILED"
+	BreakGlassRecordFailed   Code = "BREAK_GLASS_RECORD_FAILED"
+	ChangeRequestForbidden   Code = "CHANGE_REQUEST_FORBIDDEN"
+	ChangeSubmitFailed       Code = "CHANGE_SUBMIT_FAILED"
+	InvalidFreezeDays        Code = "INVALID_FREEZE_DAYS"
+	InvalidFreezeCalendarID  Code = "INVALID_FREEZE_CALENDAR_ID"
+	InvalidFreezeWindowID    Code = "INVALID_FREEZE_WINDOW_ID"
+	ChangeApproverRequired   Code = "CHANGE_APPROVER_REQUIRED"
+	ApproverGroupNotFound    Code = "APPROVER_GROUP_NOT_FOUND"
+	ApprovalActionsRequired  Code = "APPROVAL_ACTIONS_REQUIRED"
+	ReadOnlyActionNoApproval Code = "READ_ONLY_ACTION_NO_APPROVAL"
+	InvalidApprovalPattern   Code = "INVALID_APPROVAL_PATTERN"
+	NegativeApprovalTTL      Code = "NEGATIVE_APPROVAL_TTL"
+	ApprovalPolicyNotFound   Code = "APPROVAL_POLICY_NOT_FOUND"
+	InvalidChangeRequestID   Code = "INVALID_CHANGE_REQUEST_ID"
+	ChangeRequestNotFound    Code = "CHANGE_REQUEST_NOT_FOUND"
+	ChangeRequestNotPending  Code = "CHANGE_REQUEST_NOT_PENDING"
+	ChangeSelfApproval       Code = "CHANGE_SELF_APPROVAL"
+	ChangeSelfReject         Code = "CHANGE_SELF_REJECT"
+	ChangeRequestExpired     Code = "CHANGE_REQUEST_EXPIRED"
+	ChangeRequestProcessed   Code = "CHANGE_REQUEST_PROCESSED"
+)
+
+// 集群与 K8s 资源
+const (
+	ClusterNotFound            Code = "CLUSTER_NOT_FOUND"
+	UserNotFound               Code = "USER_NOT_FOUND"
+	SessionNotFound            Code = "SESSION_NOT_FOUND"
+	ResourceNotFound           Code = "RESOURCE_NOT_FOUND"
+	K8sClientFailed            Code = "K8S_CLIENT_FAILED"
+	InformerManagerUnavailable Code = "INFORMER_MANAGER_UNAVAILABLE"
+	InformerNotReady           Code = "INFORMER_NOT_READY"
+	DeleteFailed               Code = "DELETE_FAILED"
+	ClusterInfoUnavailable     Code = "CLUSTER_INFO_UNAVAILABLE"
+	ClusterListFailed          Code = "CLUSTER_LIST_FAILED"
+	HPANotFound                Code = "HPA_NOT_FOUND"
+	RolloutClientFailed        Code = "ROLLOUT_CLIENT_FAILED"
+	ScaleFailed                Code = "SCALE_FAILED"
+	YAMLMarshalFailed          Code = "YAML_MARSHAL_FAILED"
+	YAMLApplyFailed            Code = "YAML_APPLY_FAILED"
+	YAMLKindMismatch           Code = "YAML_KIND_MISMATCH"
+	NodeCordonFailed           Code = "NODE_CORDON_FAILED"
+	NodeUncordonFailed         Code = "NODE_UNCORDON_FAILED"
+	NodeDrainFailed            Code = "NODE_DRAIN_FAILED"
+	NamespaceCreateDenied      Code = "NAMESPACE_CREATE_DENIED"
+	NamespaceDeleteDenied      Code = "NAMESPACE_DELETE_DENIED"
+	StreamLogsOverWebSocket    Code = "STREAM_LOGS_OVER_WEBSOCKET"
+	RBACSyncFailed             Code = "RBAC_SYNC_FAILED"
+	RBACSyncStatusFailed       Code = "RBAC_SYNC_STATUS_FAILED"
+)
+
+// 通知与定时报表
+const (
+	NotificationChannelNotFound Code = "NOTIFICATION_CHANNEL_NOT_FOUND"
+	NotEmailChannel             Code = "NOT_EMAIL_CHANNEL"
+	NotificationChannelDisabled Code = "NOTIFICATION_CHANNEL_DISABLED"
+	ReportScheduleNotFound      Code = "REPORT_SCHEDULE_NOT_FOUND"
+	ReportDeliveryNotFound      Code = "REPORT_DELIVERY_NOT_FOUND"
+	InvalidReportID             Code = "INVALID_REPORT_ID"
+	InvalidReportDeliveryID     Code = "INVALID_REPORT_DELIVERY_ID"
+	InvalidCron                 Code = "INVALID_CRON"
+	InvalidTimezone             Code = "INVALID_TIMEZONE"
+	UnsupportedReportFormat     Code = "UNSUPPORTED_REPORT_FORMAT"
+	UnknownReportSection        Code = "UNKNOWN_REPORT_SECTION"
+	InvalidReportPeriod         Code = "INVALID_REPORT_PERIOD"
+	InvalidEmailAddress         Code = "INVALID_EMAIL_ADDRESS"
+	ReportRecipientsRequired    Code = "REPORT_RECIPIENTS_REQUIRED"
+)
+
+// K8s 资源操作（%s 为资源类型）
+const (
+	ResourceListFailed   Code = "RESOURCE_LIST_FAILED"
+	ResourceGetFailed    Code = "RESOURCE_GET_FAILED"
+	ResourceCreateFailed Code = "RESOURCE_CREATE_FAILED"
+	ResourceUpdateFailed Code = "RESOURCE_UPDATE_FAILED"
+	ResourceDeleteFailed Code = "RESOURCE_DELETE_FAILED"
+	ResourceCacheFailed  Code = "RESOURCE_CACHE_FAILED"
+)
+
+// 集群管理
+const (
+	ClusterConnectionRequired     Code = "CLUSTER_CONNECTION_REQUIRED"
+	InvalidKubeconfig             Code = "INVALID_KUBECONFIG"
+	InvalidConnectionConfig       Code = "INVALID_CONNECTION_CONFIG"
+	ConnectionTestFailed          Code = "CONNECTION_TEST_FAILED"
+	ClusterConnectFailed          Code = "CLUSTER_CONNECT_FAILED"
+	ClusterSaveFailed             Code = "CLUSTER_SAVE_FAILED"
+	ClusterStatsFailed            Code = "CLUSTER_STATS_FAILED"
+	ClusterMetricsFailed          Code = "CLUSTER_METRICS_FAILED"
+	UnsupportedNodeTerminalMode   Code = "UNSUPPORTED_NODE_TERMINAL_MODE"
+	ControlPlaneStatusFailed      Code = "CONTROL_PLANE_STATUS_FAILED"
+	HealthDiagnosisFailed         Code = "HEALTH_DIAGNOSIS_FAILED"
+	ResourceTopFailed             Code = "RESOURCE_TOP_FAILED"
+	OverviewStatsFailed           Code = "OVERVIEW_STATS_FAILED"
+	ResourceUsageFailed           Code = "RESOURCE_USAGE_FAILED"
+	ResourceDistributionFailed    Code = "RESOURCE_DISTRIBUTION_FAILED"
+	TrendsFailed                  Code = "TRENDS_FAILED"
+	AbnormalWorkloadsFailed       Code = "ABNORMAL_WORKLOADS_FAILED"
+	GlobalAlertStatsFailed        Code = "GLOBAL_ALERT_STATS_FAILED"
+	SearchFailed                  Code = "SEARCH_FAILED"
+	LogsFailed                    Code = "LOGS_FAILED"
+	NamespaceAndPodParamsRequired Code = "NAMESPACE_AND_POD_PARAMS_REQUIRED"
+)
+
+// 终端、文件与调试
+const (
+	PodNotRunning             Code = "POD_NOT_RUNNING"
+	ContainerNotFound         Code = "CONTAINER_NOT_FOUND"
+	NoContainerAvailable      Code = "NO_CONTAINER_AVAILABLE"
+	NodeDebugImageMissing     Code = "NODE_DEBUG_IMAGE_MISSING"
+	DebugImageMissing         Code = "DEBUG_IMAGE_MISSING"
+	DebugImageNotAllowed      Code = "DEBUG_IMAGE_NOT_ALLOWED"
+	NodePodTerminalDisabled   Code = "NODE_POD_TERMINAL_DISABLED"
+	FileUploadFailed          Code = "FILE_UPLOAD_FAILED"
+	FileReadFailed            Code = "FILE_READ_FAILED"
+	UnsupportedFileType       Code = "UNSUPPORTED_FILE_TYPE"
+	InvalidContainerPath      Code = "INVALID_CONTAINER_PATH"
+	ContainerRootTransfer     Code = "CONTAINER_ROOT_TRANSFER"
+	PortForwardOpenFailed     Code = "PORT_FORWARD_OPEN_FAILED"
+	PortForwardRecordsFailed  Code = "PORT_FORWARD_RECORDS_FAILED"
+	PortForwardDialFailed     Code = "PORT_FORWARD_DIAL_FAILED"
+	PortForwardNotOwner       Code = "PORT_FORWARD_NOT_OWNER"
+	PortForwardCloseDenied    Code = "PORT_FORWARD_CLOSE_DENIED"
+	PortForwardKeyInvalid     Code = "PORT_FORWARD_KEY_INVALID"
+	ServiceNoSelector         Code = "SERVICE_NO_SELECTOR"
+	ServiceNoReadyPod         Code = "SERVICE_NO_READY_POD"
+	PodPortNotFound           Code = "POD_PORT_NOT_FOUND"
+	ServicePortNotFound       Code = "SERVICE_PORT_NOT_FOUND"
+	ArthasAgentDisabled       Code = "ARTHAS_AGENT_DISABLED"
+	ArthasParamsRequired      Code = "ARTHAS_PARAMS_REQUIRED"
+	ArthasSessionFailed       Code = "ARTHAS_SESSION_FAILED"
+	ArthasCommandFailed       Code = "ARTHAS_COMMAND_FAILED"
+	ArthasPlanFailed          Code = "ARTHAS_PLAN_FAILED"
+	ArthasStatusFailed        Code = "ARTHAS_STATUS_FAILED"
+	DiagnosisQuestionRequired Code = "DIAGNOSIS_QUESTION_REQUIRED"
+	SessionOffline            Code = "SESSION_OFFLINE"
+	SessionKilling            Code = "SESSION_KILLING"
+	SessionEventsFailed       Code = "SESSION_EVENTS_FAILED"
+	PortForwardNotFound       Code = "PORT_FORWARD_NOT_FOUND"
+	PortForwardLimitExceeded  Code = "PORT_FORWARD_LIMIT_EXCEEDED"
+	PortForwardTTLTooLong     Code = "PORT_FORWARD_TTL_TOO_LONG"
+	TransferTooLarge          Code = "TRANSFER_TOO_LARGE"
+	ContentLengthRequired     Code = "CONTENT_LENGTH_REQUIRED"
+)
+
+// 审计
+const (
+	RecordingDisabled         Code = "RECORDING_DISABLED"
+	RecordingNotFound         Code = "RECORDING_NOT_FOUND"
+	RecordingUnavailable      Code = "RECORDING_UNAVAILABLE"
+	RecordingTampered         Code = "RECORDING_TAMPERED"
+	RecordingReadFailed       Code = "RECORDING_READ_FAILED"
+	RecordingParseFailed      Code = "RECORDING_PARSE_FAILED"
+	RecordingIndexFailed      Code = "RECORDING_INDEX_FAILED"
+	RecordingSearchFailed     Code = "RECORDING_SEARCH_FAILED"
+	RecordingKeywordRequired  Code = "RECORDING_KEYWORD_REQUIRED"
+	InvalidRecordingScope     Code = "INVALID_RECORDING_SCOPE"
+	SessionListFailed         Code = "SESSION_LIST_FAILED"
+	CommandListFailed         Code = "COMMAND_LIST_FAILED"
+	AuditStatsFailed          Code = "AUDIT_STATS_FAILED"
+	AuditChainVerifyFailed    Code = "AUDIT_CHAIN_VERIFY_FAILED"
+	AuditChainAnchorFailed    Code = "AUDIT_CHAIN_ANCHOR_FAILED"
+	AuditRetentionUnset       Code = "AUDIT_RETENTION_UNSET"
+	AuditPurgeFailed          Code = "AUDIT_PURGE_FAILED"
+	AuditPurgeRunsFailed      Code = "AUDIT_PURGE_RUNS_FAILED"
+	AuditSinkTestFailed       Code = "AUDIT_SINK_TEST_FAILED"
+	UserDataExportFailed      Code = "USER_DATA_EXPORT_FAILED"
+	UserDataAnonymizeFailed   Code = "USER_DATA_ANONYMIZE_FAILED"
+	ExportFailed              Code = "EXPORT_FAILED"
+	InvalidProdSelector       Code = "INVALID_PROD_SELECTOR"
+	K8sAuditParamsRequired    Code = "K8S_AUDIT_PARAMS_REQUIRED"
+	K8sAuditWebhookSaveFailed Code = "K8S_AUDIT_WEBHOOK_SAVE_FAILED"
+	K8sAuditEventSaveFailed   Code = "K8S_AUDIT_EVENT_SAVE_FAILED"
+	K8sAuditEventsFailed      Code = "K8S_AUDIT_EVENTS_FAILED"
+	ChangeTimelineFailed      Code = "CHANGE_TIMELINE_FAILED"
+	OperationLogNotFound      Code = "OPERATION_LOG_NOT_FOUND"
+	OperationLogsFailed       Code = "OPERATION_LOGS_FAILED"
+	SnapshotNotFound          Code = "SNAPSHOT_NOT_FOUND"
+	SnapshotRedacted          Code = "SNAPSHOT_REDACTED"
+	SnapshotReverted          Code = "SNAPSHOT_REVERTED"
+	SnapshotDrifted           Code = "SNAPSHOT_DRIFTED"
+	SnapshotCaptureFailed     Code = "SNAPSHOT_CAPTURE_FAILED"
+	RevertFailed              Code = "REVERT_FAILED"
+)
+
+// 监控与告警
+const (
+	AlertmanagerDisabled     Code = "ALERTMANAGER_DISABLED"
+	AlertmanagerConfigFailed Code = "ALERTMANAGER_CONFIG_FAILED"
+	AlertmanagerUpdateFailed Code = "ALERTMANAGER_UPDATE_FAILED"
+	AlertmanagerStatusFailed Code = "ALERTMANAGER_STATUS_FAILED"
+	AlertListFailed          Code = "ALERT_LIST_FAILED"
+	AlertGroupsFailed        Code = "ALERT_GROUPS_FAILED"
+	AlertStatsFailed         Code = "ALERT_STATS_FAILED"
+	SilenceListFailed        Code = "SILENCE_LIST_FAILED"
+	SilenceCreateFailed      Code = "SILENCE_CREATE_FAILED"
+	SilenceDeleteFailed      Code = "SILENCE_DELETE_FAILED"
+	ReceiverListFailed       Code = "RECEIVER_LIST_FAILED"
+	MonitoringConfigFailed   Code = "MONITORING_CONFIG_FAILED"
+	MonitoringUpdateFailed   Code = "MONITORING_UPDATE_FAILED"
+	MetricsQueryFailed       Code = "METRICS_QUERY_FAILED"
+	NotificationConfigFailed Code = "NOTIFICATION_CONFIG_FAILED"
+	NotificationTestFailed   Code = "NOTIFICATION_TEST_FAILED"
+)
+
+// 系统设置与平台功能
+const (
+	AIDisabled                    Code = "AI_DISABLED"
+	AINotConfigured               Code = "AI_NOT_CONFIGURED"
+	StreamingUnsupported          Code = "STREAMING_UNSUPPORTED"
+	AIConfigFailed                Code = "AI_CONFIG_FAILED"
+	AIConfigSaveFailed            Code = "AI_CONFIG_SAVE_FAILED"
+	APIKeyRequired                Code = "API_KEY_REQUIRED"
+	ArgoCDSaveFailed              Code = "ARGOCD_SAVE_FAILED"
+	GrafanaNotConfigured          Code = "GRAFANA_NOT_CONFIGURED"
+	GrafanaConfigFailed           Code = "GRAFANA_CONFIG_FAILED"
+	GrafanaConfigUpdateFailed     Code = "GRAFANA_CONFIG_UPDATE_FAILED"
+	GrafanaConfigSaveFailed       Code = "GRAFANA_CONFIG_SAVE_FAILED"
+	GrafanaDashboardSyncFailed    Code = "GRAFANA_DASHBOARD_SYNC_FAILED"
+	GrafanaDashboardStatusFailed  Code = "GRAFANA_DASHBOARD_STATUS_FAILED"
+	GrafanaDatasourceSyncFailed   Code = "GRAFANA_DATASOURCE_SYNC_FAILED"
+	GrafanaDatasourceStatusFailed Code = "GRAFANA_DATASOURCE_STATUS_FAILED"
+	LDAPConfigUpdateFailed        Code = "LDAP_CONFIG_UPDATE_FAILED"
+	LDAPConfigSaveFailed          Code = "LDAP_CONFIG_SAVE_FAILED"
+	SSHConfigFailed               Code = "SSH_CONFIG_FAILED"
+	SSHConfigUpdateFailed         Code = "SSH_CONFIG_UPDATE_FAILED"
+	SSHConfigSaveFailed           Code = "SSH_CONFIG_SAVE_FAILED"
+	SSHCredentialFailed           Code = "SSH_CREDENTIAL_FAILED"
+	FrontendUnavailable           Code = "FRONTEND_UNAVAILABLE"
+)
+
+// 租户管理
+const (
+	TenantNotFound               Code = "TENANT_NOT_FOUND"
+	InvalidTenantStatus          Code = "INVALID_TENANT_STATUS"
+	TenantNamespaceRequired      Code = "TENANT_NAMESPACE_REQUIRED"
+	TenantNamespaceTaken         Code = "TENANT_NAMESPACE_TAKEN"
+	TenantClusterTaken           Code = "TENANT_CLUSTER_TAKEN"
+	TenantNamespaceNotFound      Code = "TENANT_NAMESPACE_NOT_FOUND"
+	InvalidTenantRole            Code = "INVALID_TENANT_ROLE"
+	TenantBuiltinAdmin           Code = "TENANT_BUILTIN_ADMIN"
+	TenantMemberTaken            Code = "TENANT_MEMBER_TAKEN"
+	TenantMemberNotFound         Code = "TENANT_MEMBER_NOT_FOUND"
+	TenantGrantTypeDenied        Code = "TENANT_GRANT_TYPE_DENIED"
+	TenantGrantNonMember         Code = "TENANT_GRANT_NON_MEMBER"
+	TenantGrantNamespaceRequired Code = "TENANT_GRANT_NAMESPACE_REQUIRED"
+	TenantGrantNamespaceDenied   Code = "TENANT_GRANT_NAMESPACE_DENIED"
+	TenantGrantNotFound          Code = "TENANT_GRANT_NOT_FOUND"
+)
+
+// 用户、用户组与集群授权
+const (
+	UsernameExists               Code = "USERNAME_EXISTS"
+	AdminUserDeleteDenied        Code = "ADMIN_USER_DELETE_DENIED"
+	AdminUserStatusDenied        Code = "ADMIN_USER_STATUS_DENIED"
+	InvalidUserStatus            Code = "INVALID_USER_STATUS"
+	LDAPPasswordResetDenied      Code = "LDAP_PASSWORD_RESET_DENIED"
+	LDAPMultipleUsers            Code = "LDAP_MULTIPLE_USERS"
+	UserGroupNotFound            Code = "USER_GROUP_NOT_FOUND"
+	UserGroupInUse               Code = "USER_GROUP_IN_USE"
+	ClusterGroupNotFound         Code = "CLUSTER_GROUP_NOT_FOUND"
+	ClusterGroupSelectorRequired Code = "CLUSTER_GROUP_SELECTOR_REQUIRED"
+	ClusterGroupFilterConflict   Code = "CLUSTER_GROUP_FILTER_CONFLICT"
+	PermissionGroupScopeConflict Code = "PERMISSION_GROUP_SCOPE_CONFLICT"
+	PermissionScopeRequired      Code = "PERMISSION_SCOPE_REQUIRED"
+	PermissionScopeConflict      Code = "PERMISSION_SCOPE_CONFLICT"
+	PermissionSubjectRequired    Code = "PERMISSION_SUBJECT_REQUIRED"
+	PermissionSubjectConflict    Code = "PERMISSION_SUBJECT_CONFLICT"
+	InvalidPermissionType        Code = "INVALID_PERMISSION_TYPE"
+	CustomRoleRequired           Code = "CUSTOM_ROLE_REQUIRED"
+	PermissionExists             Code = "PERMISSION_EXISTS"
+	PermissionNotFound           Code = "PERMISSION_NOT_FOUND"
+	PermissionSelectorOnCluster  Code = "PERMISSION_SELECTOR_ON_CLUSTER"
+	PermissionUserMismatch       Code = "PERMISSION_USER_MISMATCH"
+)
+
+// 权限策略与终端命令规则
+const (
+	InvalidPolicyEffect      Code = "INVALID_POLICY_EFFECT"
+	PolicyNotFound           Code = "POLICY_NOT_FOUND"
+	InvalidCommandRuleEffect Code = "INVALID_COMMAND_RULE_EFFECT"
+	CommandPatternRequired   Code = "COMMAND_PATTERN_REQUIRED"
+	InvalidCommandRegex      Code = "INVALID_COMMAND_REGEX"
+	InvalidCommandMatchType  Code = "INVALID_COMMAND_MATCH_TYPE"
+	UnsupportedTerminalType  Code = "UNSUPPORTED_TERMINAL_TYPE"
+	InvalidNamespacePattern  Code = "INVALID_NAMESPACE_PATTERN"
+	CommandRuleNotFound      Code = "COMMAND_RULE_NOT_FOUND"
+)
+
+// 发布冻结
+const (
+	InvalidFreezeNamespacePattern Code = "INVALID_FREEZE_NAMESPACE_PATTERN"
+	InvalidFreezeWebhook          Code = "INVALID_FREEZE_WEBHOOK"
+	FreezeCalendarNotFound        Code = "FREEZE_CALENDAR_NOT_FOUND"
+	InvalidFreezeWindow           Code = "INVALID_FREEZE_WINDOW"
+	FreezeWindowNotFound          Code = "FREEZE_WINDOW_NOT_FOUND"
+)
+
+// SSH 凭据
+const (
+	InvalidSSHPort         Code = "INVALID_SSH_PORT"
+	NegativeSSHCertTTL     Code = "NEGATIVE_SSH_CERT_TTL"
+	SSHPasswordRequired    Code = "SSH_PASSWORD_REQUIRED"
+	SSHPrivateKeyRequired  Code = "SSH_PRIVATE_KEY_REQUIRED"
+	InvalidSSHPrivateKey   Code = "INVALID_SSH_PRIVATE_KEY"
+	InvalidSSHCAKey        Code = "INVALID_SSH_CA_KEY"
+	InvalidSSHAuthType     Code = "INVALID_SSH_AUTH_TYPE"
+	UnsupportedSSHAuthType Code = "UNSUPPORTED_SSH_AUTH_TYPE"
+	SSHProfileNotFound     Code = "SSH_PROFILE_NOT_FOUND"
+	SSHProfileUnavailable  Code = "SSH_PROFILE_UNAVAILABLE"
+	HostKeyNotFound        Code = "HOST_KEY_NOT_FOUND"
+	HostKeyNotPending      Code = "HOST_KEY_NOT_PENDING"
+)
+
+// 审计外送、审计链与通知
+const (
+	InvalidAuditSuccessFilter      Code = "INVALID_AUDIT_SUCCESS_FILTER"
+	AuditSinkNotFound              Code = "AUDIT_SINK_NOT_FOUND"
+	K8sAuditUnauthorized           Code = "K8S_AUDIT_UNAUTHORIZED"
+	InvalidK8sAuditEvents          Code = "INVALID_K8S_AUDIT_EVENTS"
+	UserNotDeleted                 Code = "USER_NOT_DELETED"
+	AuditRetentionRunning          Code = "AUDIT_RETENTION_RUNNING"
+	UnknownAuditChain              Code = "UNKNOWN_AUDIT_CHAIN"
+	AuditChainBroken               Code = "AUDIT_CHAIN_BROKEN"
+	AuditAnchorRecordMissing       Code = "AUDIT_ANCHOR_RECORD_MISSING"
+	NotificationChannelDuplicate   Code = "NOTIFICATION_CHANNEL_DUPLICATE"
+	SubscriptionNameRequired       Code = "SUBSCRIPTION_NAME_REQUIRED"
+	SubscriptionIncomplete         Code = "SUBSCRIPTION_INCOMPLETE"
+	SubscriptionUnknownEvent       Code = "SUBSCRIPTION_UNKNOWN_EVENT"
+	SubscriptionChannelNotFound    Code = "SUBSCRIPTION_CHANNEL_NOT_FOUND"
+	NotificationChannelUnavailable Code = "NOTIFICATION_CHANNEL_UNAVAILABLE"
+	DeliveryNotRetryable           Code = "DELIVERY_NOT_RETRYABLE"
+)
+
+// ArgoCD
+const (
+	ArgoCDCredentialsRequired Code = "ARGOCD_CREDENTIALS_REQUIRED"
+	ArgoCDTokenInvalid        Code = "ARGOCD_TOKEN_INVALID"
+	ArgoCDResponseError       Code = "ARGOCD_RESPONSE_ERROR"
+	ArgoCDNotConfigured       Code = "ARGOCD_NOT_CONFIGURED"
+	ArgoCDAPIError            Code = "ARGOCD_API_ERROR"
+	ArgoCDAppNotFound         Code = "ARGOCD_APP_NOT_FOUND"
+	ArgoCDAppGetFailed        Code = "ARGOCD_APP_GET_FAILED"
+	ArgoCDAppCreateFailed     Code = "ARGOCD_APP_CREATE_FAILED"
+	ArgoCDAppUpdateFailed     Code = "ARGOCD_APP_UPDATE_FAILED"
+	ArgoCDSyncFailed          Code = "ARGOCD_SYNC_FAILED"
+	ArgoCDDeleteFailed        Code = "ARGOCD_DELETE_FAILED"
+	ArgoCDRollbackFailed      Code = "ARGOCD_ROLLBACK_FAILED"
+	ArgoCDResourceTreeFailed  Code = "ARGOCD_RESOURCE_TREE_FAILED"
+	ArgoCDLoginFailed         Code = "ARGOCD_LOGIN_FAILED"
+	ArgoCDTokenMissing        Code = "ARGOCD_TOKEN_MISSING"
+)
+
+// message 错误码的状态码与中英文文案（文案可含 fmt 占位符，两种语言的参数顺序一致）
+type message struct {
+	status int
+	zh, en string
+}
+
+var catalog = map[Code]message{
+	BadRequest:         {http.StatusBadRequest, "请求参数错误", "Invalid request parameters"},
+	Unauthorized:       {http.StatusUnauthorized, "未认证", "Authentication required"},
+	Forbidden:          {http.StatusForbidden, "权限不足，无法执行此操作", "Permission denied"},
+	NotFound:           {http.StatusNotFound, "资源不存在", "Resource not found"},
+	Conflict:           {http.StatusConflict, "资源冲突", "Resource conflict"},
+	Internal:           {http.StatusInternalServerError, "服务器内部错误", "Internal server error"},
+	ServiceUnavailable: {http.StatusServiceUnavailable, "服务暂不可用", "Service unavailable"},
+
+	InvalidClusterID:             {http.StatusBadRequest, "无效的集群ID", "Invalid cluster ID"},
+	InvalidUserID:                {http.StatusBadRequest, "无效的用户ID", "Invalid user ID"},
+	InvalidUserGroupID:           {http.StatusBadRequest, "无效的用户组ID", "Invalid user group ID"},
+	InvalidSessionID:             {http.StatusBadRequest, "无效的会话ID", "Invalid session ID"},
+	InvalidPermissionID:          {http.StatusBadRequest, "无效的权限ID", "Invalid permission ID"},
+	InvalidTenantID:              {http.StatusBadRequest, "无效的租户ID", "Invalid tenant ID"},
+	InvalidLogID:                 {http.StatusBadRequest, "无效的日志ID", "Invalid log ID"},
+	InvalidYAML:                  {http.StatusBadRequest, "YAML格式错误", "Invalid YAML"},
+	YAMLMissingKind:              {http.StatusBadRequest, "YAML缺少必要字段: apiVersion 或 kind", "YAML is missing required field: apiVersion or kind"},
+	YAMLMissingMetadata:          {http.StatusBadRequest, "YAML缺少 metadata 字段", "YAML is missing the metadata field"},
+	YAMLOrFormRequired:           {http.StatusBadRequest, "必须提供YAML或表单数据", "Either YAML or form data is required"},
+	UnsupportedLanguage:          {http.StatusBadRequest, "不支持的语言: %s", "Unsupported language: %s"},
+	BodyReadFailed:               {http.StatusBadRequest, "读取请求体失败", "Failed to read request body"},
+	InvalidID:                    {http.StatusBadRequest, "无效的ID", "Invalid ID"},
+	InvalidAccessRequestID:       {http.StatusBadRequest, "无效的申请ID", "Invalid access request ID"},
+	InvalidAuditSinkID:           {http.StatusBadRequest, "无效的目标ID", "Invalid audit sink ID"},
+	InvalidClusterGroupID:        {http.StatusBadRequest, "无效的集群分组ID", "Invalid cluster group ID"},
+	InvalidDeliveryID:            {http.StatusBadRequest, "无效的投递记录ID", "Invalid delivery ID"},
+	InvalidSnapshotID:            {http.StatusBadRequest, "无效的快照ID", "Invalid snapshot ID"},
+	InvalidPolicyID:              {http.StatusBadRequest, "无效的策略ID", "Invalid policy ID"},
+	InvalidNamespaceID:           {http.StatusBadRequest, "无效的命名空间ID", "Invalid namespace ID"},
+	InvalidRuleID:                {http.StatusBadRequest, "无效的规则ID", "Invalid rule ID"},
+	InvalidLabelKey:              {http.StatusBadRequest, "无效的标签键: %s", "Invalid label key: %s"},
+	InvalidLabelSelector:         {http.StatusBadRequest, "无效的标签选择器: %s", "Invalid label selector: %s"},
+	UnsupportedExportFormat:      {http.StatusBadRequest, "不支持的导出格式", "Unsupported export format"},
+	SearchKeywordRequired:        {http.StatusBadRequest, "搜索关键词不能为空", "Search keyword is required"},
+	NamespaceAndPodRequired:      {http.StatusBadRequest, "命名空间和Pod名称不能为空", "Namespace and Pod name are required"},
+	NamespaceAndWorkloadRequired: {http.StatusBadRequest, "命名空间和工作负载名称不能为空", "Namespace and workload name are required"},
+	NodeNameRequired:             {http.StatusBadRequest, "节点名称不能为空", "Node name is required"},
+	ActionsRequired:              {http.StatusBadRequest, "操作不能为空", "At least one action is required"},
+	CommandRequired:              {http.StatusBadRequest, "命令不能为空", "Command is required"},
+	MessageRequired:              {http.StatusBadRequest, "消息不能为空", "Message is required"},
+	SilenceIDRequired:            {http.StatusBadRequest, "静默规则ID不能为空", "Silence ID is required"},
+	SubjectRequired:              {http.StatusBadRequest, "至少需要指定一个用户或用户组", "At least one user or user group is required"},
+
+	NotLoggedIn:          {http.StatusUnauthorized, "未登录", "Not logged in"},
+	InvalidAuthContext:   {http.StatusUnauthorized, "无效的用户认证信息", "Invalid authentication context"},
+	TokenMissing:         {http.StatusUnauthorized, "缺少认证令牌", "Missing authentication token"},
+	TokenMalformed:       {http.StatusUnauthorized, "认证令牌格式错误", "Malformed authentication token"},
+	TokenInvalid:         {http.StatusUnauthorized, "认证令牌无效", "Invalid or expired authentication token"},
+	TokenMissingUserID:   {http.StatusUnauthorized, "认证令牌中缺少用户ID", "Authentication token has no user ID"},
+	TokenSignFailed:      {http.StatusInternalServerError, "JWT token生成失败", "Failed to issue authentication token"},
+	InvalidCredentials:   {http.StatusUnauthorized, "用户名或密码错误", "Incorrect username or password"},
+	AccountDisabled:      {http.StatusForbidden, "用户账号已被禁用", "User account is disabled"},
+	UnsupportedAuthType:  {http.StatusBadRequest, "不支持的认证类型", "Unsupported authentication type"},
+	LDAPDisabled:         {http.StatusUnauthorized, "LDAP认证未启用", "LDAP authentication is not enabled"},
+	LDAPConfigFailed:     {http.StatusInternalServerError, "获取LDAP配置失败", "Failed to load LDAP configuration"},
+	LDAPAuthFailed:       {http.StatusUnauthorized, "LDAP认证失败", "LDAP authentication failed"},
+	UserCreateFailed:     {http.StatusInternalServerError, "创建用户记录失败", "Failed to create user record"},
+	UserQueryFailed:      {http.StatusInternalServerError, "查询用户失败", "Failed to query user"},
+	LDAPPasswordReadOnly: {http.StatusForbidden, "LDAP用户不能在此修改密码", "LDAP users cannot change their password here"},
+	WrongPassword:        {http.StatusUnauthorized, "原密码错误", "Current password is incorrect"},
+	PasswordUpdateFailed: {http.StatusInternalServerError, "密码更新失败", "Failed to update password"},
+
+	PlatformAdminRequired:           {http.StatusForbidden, "需要平台管理员权限", "Platform administrator privileges required"},
+	AdminRequired:                   {http.StatusForbidden, "需要管理员权限", "Administrator privileges required"},
+	ClusterAccessDenied:             {http.StatusForbidden, "无集群访问权限", "No access to this cluster"},
+	NamespaceAccessDenied:           {http.StatusForbidden, "无权限访问该命名空间", "No access to this namespace"},
+	ReadOnlyPermission:              {http.StatusForbidden, "只读权限无法执行写操作", "Read-only permission cannot perform write operations"},
+	PolicyDenied:                    {http.StatusForbidden, "操作被权限策略拒绝: %s", "Operation denied by permission policy: %s"},
+	PolicyCheckFailed:               {http.StatusServiceUnavailable, "权限策略加载失败，暂时无法执行该操作", "Unable to load permission policies; the operation is temporarily unavailable"},
+	TenantLoadFailed:                {http.StatusInternalServerError, "加载租户信息失败", "Failed to load tenant"},
+	TenantDisabled:                  {http.StatusForbidden, "所属租户已被禁用", "Your tenant is disabled"},
+	TenantNoNamespace:               {http.StatusForbidden, "所属租户在该集群没有可用的命名空间", "Your tenant has no namespaces in this cluster"},
+	TenantClusterTerminal:           {http.StatusForbidden, "租户成员不能使用集群级 kubectl 终端", "Tenant members cannot use the cluster-wide kubectl terminal"},
+	TenantClusterResource:           {http.StatusForbidden, "租户成员不能修改集群级资源", "Tenant members cannot modify cluster-scoped resources"},
+	TenantNamespaceMismatch:         {http.StatusForbidden, "命名空间 %s 不属于所属租户", "Namespace %s does not belong to your tenant"},
+	TenantAdminRequired:             {http.StatusForbidden, "需要租户管理员权限", "Tenant administrator privileges required"},
+	AccessRequestForbidden:          {http.StatusForbidden, "无权查看该申请", "You cannot view this access request"},
+	AccessRequestConfigFailed:       {http.StatusInternalServerError, "获取临时提权配置失败", "Failed to get temporary access configuration"},
+	PermissionCreateFailed:          {http.StatusBadRequest, "创建失败", "Failed to create permissions"},
+	CannotDeleteSelf:                {http.StatusBadRequest, "不能删除自己", "You cannot delete yourself"},
+	AccessApproverRequired:          {http.StatusForbidden, "当前用户不在审批用户组中，无权审批", "You are not in the approver group for access requests"},
+	AccessApproverGroupRequired:     {http.StatusBadRequest, "启用临时提权时必须指定审批用户组", "An approver group is required when temporary access is enabled"},
+	UnsupportedAccessPermissionType: {http.StatusBadRequest, "不支持申请的权限类型: %s", "Permission type cannot be requested: %s"},
+	AccessRequestDisabled:           {http.StatusBadRequest, "临时提权功能未启用", "Temporary access requests are not enabled"},
+	AccessPermissionTypeNotAllowed:  {http.StatusBadRequest, "不允许申请 %s 权限", "Requesting %s permission is not allowed"},
+	AccessDurationTooLong:           {http.StatusBadRequest, "申请时长不能超过 %d 分钟", "Requested duration cannot exceed %d minutes"},
+	AccessRequestPending:            {http.StatusConflict, "该集群已有待审批的申请", "You already have a pending request for this cluster"},
+	AccessRequestNotFound:           {http.StatusNotFound, "申请不存在", "Access request not found"},
+	AccessRequestNotPending:         {http.StatusConflict, "申请当前状态为 %s，无法审批", "The access request is %s and cannot be reviewed"},
+	AccessSelfApproval:              {http.StatusBadRequest, "不能审批自己的申请", "You cannot review your own access request"},
+	AccessRequestProcessed:          {http.StatusConflict, "申请不存在或已处理", "The access request does not exist or has already been processed"},
+	AccessRequestNotActive:          {http.StatusConflict, "只能撤销生效中的临时权限", "Only active temporary access can be revoked"},
+	AccessRBACSyncFailed:            {http.StatusBadGateway, "同步临时权限的集群 RBAC 失败，申请保持待审批", "Failed to apply cluster RBAC for the temporary access; the request remains pending"},
+
+	ChangeFrozen:             {http.StatusLocked, "变更冻结中：%s / %s（至 %s），如需紧急变更请填写放行理由", "Changes are frozen: %s / %s (until %s); provide a break-glass reason for an emergency change"},
+	BreakGlassNotAllowed:     {http.StatusLocked, "变更冻结中：%s / %s（至 %s），该窗口不允许紧急放行", "Changes are frozen: %s / %s (until %s); break-glass is not allowed in this window"},
+	BreakGlassReasonTooShort: {http.StatusBadRequest, "紧急放行理由至少 %d 个字符", "Break-glass reason must be at least %d characters"},
+	ApprovalRequired:         {http.StatusAccepted, "该操作需经 %s 审批，已提交待审批变更", "This operation requires approval under %s; a change request has been submitted"},
+	ApprovalPolicyFailed:     {http.StatusInternalServerError, "变更审批策略评估失败", "Failed to evaluate change approval policies"},
+	ApprovalBodyTooLarge:     {http.StatusBadRequest, "请求体过大，无法提交审批", "Request body is too large to submit for approval"},
+	FreezeCheckFailed:        {http.StatusServiceUnavailable, "变更冻结日历加载失败，暂时无法执行写操作", "Unable to load change freeze calendars; writes are temporarily unavailable"},
+	BreakGlassRecordFailed:   {http.StatusInternalServerError, "记录紧急放行失败", "Failed to record the break-glass override"},
+	ChangeRequestForbidden:   {http.StatusForbidden, "无权查看该变更", "You cannot view this change request"},
+	ChangeSubmitFailed:       {http.StatusInternalServerError, "提交变更审批失败", "Failed to submit the change for approval"},
+	InvalidFreezeDays:        {http.StatusBadRequest, "days 取值范围为 1-366", "days must be between 1 and 366"},
+	InvalidFreezeCalendarID:  {http.StatusBadRequest, "无效的日历ID", "Invalid calendar ID"},
+	InvalidFreezeWindowID:    {http.StatusBadRequest, "无效的窗口ID", "Invalid window ID"},
+	ChangeApproverRequired:   {http.StatusForbidden, "当前用户不在该变更的审批用户组中，无权审批", "You are not in the approver group for this change"},
+	ApproverGroupNotFound:    {http.StatusBadRequest, "审批用户组不存在", "Approver group not found"},
+	ApprovalActionsRequired:  {http.StatusBadRequest, "至少需要指定一个操作", "At least one action must be specified"},
+	ReadOnlyActionNoApproval: {http.StatusBadRequest, "只读操作 %s 无需审批", "Read-only action %s does not need approval"},
+	InvalidApprovalPattern:   {http.StatusBadRequest, "无效的匹配模式: %s", "Invalid match pattern: %s"},
+	NegativeApprovalTTL:      {http.StatusBadRequest, "有效期不能为负数", "Expiry cannot be negative"},
+	ApprovalPolicyNotFound:   {http.StatusNotFound, "审批策略不存在", "Approval policy not found"},
+	InvalidChangeRequestID:   {http.StatusBadRequest, "无效的变更ID", "Invalid change request ID"},
+	ChangeRequestNotFound:    {http.StatusNotFound, "变更不存在", "Change request not found"},
+	ChangeRequestNotPending:  {http.StatusConflict, "变更当前状态为 %s，无法审批", "The change request is %s and cannot be reviewed"},
+	ChangeSelfApproval:       {http.StatusBadRequest, "不能审批自己提交的变更", "You cannot approve your own change"},
+	ChangeSelfReject:         {http.StatusBadRequest, "不能审批自己提交的变更，请使用撤回", "You cannot review your own change; cancel it instead"},
+	ChangeRequestExpired:     {http.StatusConflict, "变更已超过审批有效期", "The change request has expired"},
+	ChangeRequestProcessed:   {http.StatusConflict, "变更不存在或已处理", "The change request does not exist or has already been processed"},
+
+	ClusterNotFound:            {http.StatusNotFound, "集群不存在", "Cluster not found"},
+	UserNotFound:               {http.StatusNotFound, "用户不存在", "User not found"},
+	SessionNotFound:            {http.StatusNotFound, "会话不存在", "Session not found"},
+	ResourceNotFound:           {http.StatusNotFound, "%s不存在", "%s not found"},
+	K8sClientFailed:            {http.StatusInternalServerError, "获取K8s客户端失败", "Failed to get Kubernetes client"},
+	InformerManagerUnavailable: {http.StatusServiceUnavailable, "K8s informer 管理器未初始化", "Kubernetes informer manager is not initialized"},
+	InformerNotReady:           {http.StatusServiceUnavailable, "informer 未就绪", "Informer cache is not ready"},
+	DeleteFailed:               {http.StatusInternalServerError, "删除失败", "Delete failed"},
+	ClusterInfoUnavailable:     {http.StatusServiceUnavailable, "集群信息获取失败", "Failed to load cluster information"},
+	ClusterListFailed:          {http.StatusInternalServerError, "获取集群列表失败", "Failed to list clusters"},
+	HPANotFound:                {http.StatusNotFound, "未找到HPA", "HPA not found"},
+	RolloutClientFailed:        {http.StatusInternalServerError, "获取Rollout客户端失败", "Failed to get Argo Rollouts client"},
+	ScaleFailed:                {http.StatusInternalServerError, "扩缩容失败", "Scale failed"},
+	YAMLMarshalFailed:          {http.StatusInternalServerError, "转换YAML失败", "Failed to convert to YAML"},
+	YAMLApplyFailed:            {http.StatusInternalServerError, "YAML应用失败", "Failed to apply YAML"},
+	YAMLKindMismatch:           {http.StatusBadRequest, "YAML类型错误，期望%s，实际为: %s", "Unexpected YAML kind: expected %s, got %s"},
+	NodeCordonFailed:           {http.StatusInternalServerError, "封锁节点失败", "Failed to cordon node"},
+	NodeUncordonFailed:         {http.StatusInternalServerError, "解封节点失败", "Failed to uncordon node"},
+	NodeDrainFailed:            {http.StatusInternalServerError, "驱逐节点失败", "Failed to drain node"},
+	NamespaceCreateDenied:      {http.StatusForbidden, "只有管理员才能创建命名空间", "Only administrators can create namespaces"},
+	NamespaceDeleteDenied:      {http.StatusForbidden, "只有管理员才能删除命名空间", "Only administrators can delete namespaces"},
+	StreamLogsOverWebSocket:    {http.StatusBadRequest, "流式日志请使用WebSocket连接: /ws/clusters/:clusterID/pods/:namespace/:name/logs", "Use the WebSocket endpoint for streaming logs: /ws/clusters/:clusterID/pods/:namespace/:name/logs"},
+	RBACSyncFailed:             {http.StatusInternalServerError, "同步权限失败", "Failed to sync permissions"},
+	RBACSyncStatusFailed:       {http.StatusInternalServerError, "获取同步状态失败", "Failed to get sync status"},
+
+	NotificationChannelNotFound: {http.StatusNotFound, "通知渠道不存在: %s", "Notification channel not found: %s"},
+	NotEmailChannel:             {http.StatusBadRequest, "通知渠道 %s 不是邮件渠道", "Notification channel %s is not an email channel"},
+	NotificationChannelDisabled: {http.StatusBadRequest, "通知渠道 %s 未启用", "Notification channel %s is disabled"},
+	ReportScheduleNotFound:      {http.StatusNotFound, "定时报表不存在", "Report schedule not found"},
+	InvalidReportID:             {http.StatusBadRequest, "无效的报表ID", "Invalid report ID"},
+	InvalidReportDeliveryID:     {http.StatusBadRequest, "无效的发送记录ID", "Invalid report delivery ID"},
+	ReportDeliveryNotFound:      {http.StatusNotFound, "发送记录不存在", "Report delivery not found"},
+	InvalidCron:                 {http.StatusBadRequest, "无效的 Cron 表达式", "Invalid cron expression"},
+	InvalidTimezone:             {http.StatusBadRequest, "无效的时区: %s", "Invalid time zone: %s"},
+	UnsupportedReportFormat:     {http.StatusBadRequest, "不支持的报表格式: %s", "Unsupported report format: %s"},
+	UnknownReportSection:        {http.StatusBadRequest, "未知的报表章节: %s", "Unknown report section: %s"},
+	InvalidReportPeriod:         {http.StatusBadRequest, "统计区间需在 1-%d 天之间", "Report period must be between 1 and %d days"},
+	InvalidEmailAddress:         {http.StatusBadRequest, "收件人地址无效: %s", "Invalid recipient address: %s"},
+	ReportRecipientsRequired:    {http.StatusBadRequest, "未配置收件人，且邮件渠道没有默认收件人", "No recipients configured and the email channel has no default recipients"},
+
+	ResourceListFailed:   {http.StatusInternalServerError, "获取%s列表失败", "Failed to list %s"},
+	ResourceGetFailed:    {http.StatusInternalServerError, "获取%s失败", "Failed to get %s"},
+	ResourceCreateFailed: {http.StatusInternalServerError, "创建%s失败", "Failed to create %s"},
+	ResourceUpdateFailed: {http.StatusInternalServerError, "更新%s失败", "Failed to update %s"},
+	ResourceDeleteFailed: {http.StatusInternalServerError, "删除%s失败", "Failed to delete %s"},
+	ResourceCacheFailed:  {http.StatusInternalServerError, "读取%s缓存失败", "Failed to read %s from cache"},
+
+	ClusterConnectionRequired:     {http.StatusBadRequest, "请提供kubeconfig或者API Server地址和访问令牌", "Provide a kubeconfig, or an API server address and access token"},
+	InvalidKubeconfig:             {http.StatusBadRequest, "kubeconfig格式错误", "Invalid kubeconfig"},
+	InvalidConnectionConfig:       {http.StatusBadRequest, "连接配置错误", "Invalid connection configuration"},
+	ConnectionTestFailed:          {http.StatusBadRequest, "连接测试失败", "Connection test failed"},
+	ClusterConnectFailed:          {http.StatusInternalServerError, "连接集群失败", "Failed to connect to cluster"},
+	ClusterSaveFailed:             {http.StatusInternalServerError, "保存集群信息失败", "Failed to save cluster"},
+	ClusterStatsFailed:            {http.StatusInternalServerError, "获取集群统计失败", "Failed to get cluster statistics"},
+	ClusterMetricsFailed:          {http.StatusInternalServerError, "获取集群监控数据失败", "Failed to get cluster metrics"},
+	UnsupportedNodeTerminalMode:   {http.StatusBadRequest, "不支持的节点终端方式: %s", "Unsupported node terminal mode: %s"},
+	ControlPlaneStatusFailed:      {http.StatusInternalServerError, "获取控制面状态失败", "Failed to get control plane status"},
+	HealthDiagnosisFailed:         {http.StatusInternalServerError, "执行健康诊断失败", "Health diagnosis failed"},
+	ResourceTopFailed:             {http.StatusInternalServerError, "获取资源Top N失败", "Failed to get top resource consumers"},
+	OverviewStatsFailed:           {http.StatusInternalServerError, "获取统计数据失败", "Failed to get statistics"},
+	ResourceUsageFailed:           {http.StatusInternalServerError, "获取资源使用率失败", "Failed to get resource usage"},
+	ResourceDistributionFailed:    {http.StatusInternalServerError, "获取资源分布失败", "Failed to get resource distribution"},
+	TrendsFailed:                  {http.StatusInternalServerError, "获取趋势数据失败", "Failed to get trend data"},
+	AbnormalWorkloadsFailed:       {http.StatusInternalServerError, "获取异常工作负载失败", "Failed to get abnormal workloads"},
+	GlobalAlertStatsFailed:        {http.StatusInternalServerError, "获取全局告警统计失败", "Failed to get global alert statistics"},
+	SearchFailed:                  {http.StatusInternalServerError, "搜索失败", "Search failed"},
+	LogsFailed:                    {http.StatusInternalServerError, "获取日志失败", "Failed to get logs"},
+	NamespaceAndPodParamsRequired: {http.StatusBadRequest, "namespace 和 pod 参数必填", "The namespace and pod parameters are required"},
+
+	PodNotRunning:             {http.StatusBadRequest, "Pod 未处于运行状态: %s", "Pod is not running: %s"},
+	ContainerNotFound:         {http.StatusBadRequest, "目标容器不存在: %s", "Target container not found: %s"},
+	NoContainerAvailable:      {http.StatusBadRequest, "未找到可用容器", "No available container found"},
+	NodeDebugImageMissing:     {http.StatusBadRequest, "未配置节点终端镜像", "No node terminal image is configured"},
+	DebugImageMissing:         {http.StatusBadRequest, "未配置可用的调试镜像", "No debug image is configured"},
+	DebugImageNotAllowed:      {http.StatusBadRequest, "不允许使用的调试镜像: %s", "Debug image is not allowed: %s"},
+	NodePodTerminalDisabled:   {http.StatusBadRequest, "集群未启用 Pod 方式的节点终端", "Pod-based node terminals are not enabled for this cluster"},
+	FileUploadFailed:          {http.StatusInternalServerError, "上传文件失败: %s", "File upload failed: %s"},
+	FileReadFailed:            {http.StatusBadRequest, "读取容器文件失败: %s", "Failed to read container file: %s"},
+	UnsupportedFileType:       {http.StatusBadRequest, "仅支持下载普通文件或目录", "Only regular files and directories can be downloaded"},
+	InvalidContainerPath:      {http.StatusBadRequest, "路径必须为容器内绝对路径", "The path must be an absolute path inside the container"},
+	ContainerRootTransfer:     {http.StatusBadRequest, "不支持传输根目录", "Transferring the root directory is not supported"},
+	PortForwardOpenFailed:     {http.StatusInternalServerError, "建立端口转发失败", "Failed to open port forward"},
+	PortForwardRecordsFailed:  {http.StatusInternalServerError, "查询端口转发记录失败", "Failed to query port forward records"},
+	PortForwardDialFailed:     {http.StatusServiceUnavailable, "连接转发端口失败", "Failed to connect to the forwarded port"},
+	PortForwardNotOwner:       {http.StatusForbidden, "只能使用自己的端口转发", "You can only use your own port forwards"},
+	PortForwardCloseDenied:    {http.StatusForbidden, "只能关闭自己的端口转发", "You can only close your own port forwards"},
+	PortForwardKeyInvalid:     {http.StatusUnauthorized, "端口转发访问凭据无效", "Invalid port forward access key"},
+	ServiceNoSelector:         {http.StatusBadRequest, "Service %s 没有 selector，无法选择后端 Pod", "Service %s has no selector; cannot choose a backend pod"},
+	ServiceNoReadyPod:         {http.StatusBadRequest, "Service %s 没有就绪的后端 Pod", "Service %s has no ready backend pods"},
+	PodPortNotFound:           {http.StatusBadRequest, "Pod %s 中没有名为 %s 的端口", "Pod %s has no port named %s"},
+	ServicePortNotFound:       {http.StatusBadRequest, "Service %s 没有端口 %d", "Service %s has no port %d"},
+	ArthasAgentDisabled:       {http.StatusServiceUnavailable, "Arthas Agent 未启用", "Arthas agent is not enabled"},
+	ArthasParamsRequired:      {http.StatusBadRequest, "container、pid 和 command 不能为空", "container, pid and command are required"},
+	ArthasSessionFailed:       {http.StatusInternalServerError, "创建 Arthas 会话失败", "Failed to create Arthas session"},
+	ArthasCommandFailed:       {http.StatusInternalServerError, "执行 Arthas 命令失败", "Failed to run Arthas command"},
+	ArthasPlanFailed:          {http.StatusInternalServerError, "生成 Arthas 诊断计划失败", "Failed to generate Arthas diagnosis plan"},
+	ArthasStatusFailed:        {http.StatusInternalServerError, "获取 Arthas 状态失败", "Failed to get Arthas status"},
+	DiagnosisQuestionRequired: {http.StatusBadRequest, "诊断问题不能为空", "Diagnosis question is required"},
+	SessionOffline:            {http.StatusNotFound, "会话不在线", "Session is not online"},
+	SessionKilling:            {http.StatusConflict, "会话正在终止", "The session is already being terminated"},
+	SessionEventsFailed:       {http.StatusInternalServerError, "获取会话事件失败", "Failed to get session events"},
+	PortForwardNotFound:       {http.StatusNotFound, "端口转发不存在或已关闭", "Port forward not found or already closed"},
+	PortForwardLimitExceeded:  {http.StatusTooManyRequests, "活跃端口转发数已达上限", "Too many active port forwards"},
+	PortForwardTTLTooLong:     {http.StatusBadRequest, "端口转发有效期超过上限", "Port forward TTL exceeds the limit"},
+	TransferTooLarge:          {http.StatusRequestEntityTooLarge, "超过文件传输大小上限", "File transfer size limit exceeded"},
+	ContentLengthRequired:     {http.StatusLengthRequired, "上传文件需要 Content-Length", "File uploads require a Content-Length header"},
+
+	RecordingDisabled:         {http.StatusNotFound, "会话录像未启用", "Session recording is not enabled"},
+	RecordingNotFound:         {http.StatusNotFound, "录像文件不存在", "Recording file not found"},
+	RecordingUnavailable:      {http.StatusNotFound, "该会话无可用录像", "No recording is available for this session"},
+	RecordingTampered:         {http.StatusConflict, "录像完整性校验失败，文件可能已被篡改", "Recording integrity check failed; the file may have been tampered with"},
+	RecordingReadFailed:       {http.StatusInternalServerError, "读取录像失败", "Failed to read recording"},
+	RecordingParseFailed:      {http.StatusInternalServerError, "解析录像失败", "Failed to parse recording"},
+	RecordingIndexFailed:      {http.StatusInternalServerError, "保存录像索引失败", "Failed to save recording index"},
+	RecordingSearchFailed:     {http.StatusInternalServerError, "检索终端录像失败", "Failed to search terminal recordings"},
+	RecordingKeywordRequired:  {http.StatusBadRequest, "检索关键字不能为空", "Search keyword is required for recordings"},
+	InvalidRecordingScope:     {http.StatusBadRequest, "scope 仅支持 command、output、all", "scope must be one of command, output, all"},
+	SessionListFailed:         {http.StatusInternalServerError, "获取会话列表失败", "Failed to list sessions"},
+	CommandListFailed:         {http.StatusInternalServerError, "获取命令记录失败", "Failed to list commands"},
+	AuditStatsFailed:          {http.StatusInternalServerError, "获取统计信息失败", "Failed to get audit statistics"},
+	AuditChainVerifyFailed:    {http.StatusInternalServerError, "校验审计哈希链失败", "Failed to verify audit hash chain"},
+	AuditChainAnchorFailed:    {http.StatusInternalServerError, "锚定审计哈希链失败", "Failed to anchor audit hash chain"},
+	AuditRetentionUnset:       {http.StatusBadRequest, "未配置审计记录保留天数", "Audit retention days are not configured"},
+	AuditPurgeFailed:          {http.StatusInternalServerError, "审计记录清理失败", "Failed to purge audit records"},
+	AuditPurgeRunsFailed:      {http.StatusInternalServerError, "获取清理任务失败", "Failed to get purge runs"},
+	AuditSinkTestFailed:       {http.StatusBadRequest, "发送测试事件失败", "Failed to send test event"},
+	UserDataExportFailed:      {http.StatusInternalServerError, "导出用户审计数据失败", "Failed to export user audit data"},
+	UserDataAnonymizeFailed:   {http.StatusInternalServerError, "匿名化失败", "Failed to anonymize user data"},
+	ExportFailed:              {http.StatusInternalServerError, "导出失败", "Export failed"},
+	InvalidProdSelector:       {http.StatusBadRequest, "无效的生产集群标签选择器", "Invalid production cluster label selector"},
+	K8sAuditParamsRequired:    {http.StatusBadRequest, "clusterId、resource、name 不能为空", "clusterId, resource and name are required"},
+	K8sAuditWebhookSaveFailed: {http.StatusInternalServerError, "保存审计 Webhook 配置失败", "Failed to save audit webhook configuration"},
+	K8sAuditEventSaveFailed:   {http.StatusInternalServerError, "保存审计事件失败", "Failed to save audit events"},
+	K8sAuditEventsFailed:      {http.StatusInternalServerError, "获取审计事件失败", "Failed to get audit events"},
+	ChangeTimelineFailed:      {http.StatusInternalServerError, "获取变更时间线失败", "Failed to get change timeline"},
+	OperationLogNotFound:      {http.StatusNotFound, "日志不存在", "Operation log not found"},
+	OperationLogsFailed:       {http.StatusInternalServerError, "获取操作日志失败", "Failed to get operation logs"},
+	SnapshotNotFound:          {http.StatusNotFound, "快照不存在", "Snapshot not found"},
+	SnapshotRedacted:          {http.StatusBadRequest, "快照包含脱敏的 Secret 数据，无法撤销", "The snapshot contains redacted Secret data and cannot be reverted"},
+	SnapshotReverted:          {http.StatusConflict, "该变更已撤销", "This change has already been reverted"},
+	SnapshotDrifted:           {http.StatusConflict, "对象在该变更之后又被修改过（%s），确认覆盖请使用 force=true", "The object was modified after this change (%s); use force=true to overwrite"},
+	SnapshotCaptureFailed:     {http.StatusInternalServerError, "读取对象当前状态失败", "Failed to read the current object state"},
+	RevertFailed:              {http.StatusInternalServerError, "撤销变更失败", "Failed to revert the change"},
+
+	AlertmanagerDisabled:     {http.StatusBadRequest, "Alertmanager 未启用", "Alertmanager is not enabled"},
+	AlertmanagerConfigFailed: {http.StatusInternalServerError, "获取 Alertmanager 配置失败", "Failed to get Alertmanager configuration"},
+	AlertmanagerUpdateFailed: {http.StatusInternalServerError, "更新 Alertmanager 配置失败", "Failed to update Alertmanager configuration"},
+	AlertmanagerStatusFailed: {http.StatusInternalServerError, "获取 Alertmanager 状态失败", "Failed to get Alertmanager status"},
+	AlertListFailed:          {http.StatusInternalServerError, "获取告警列表失败", "Failed to list alerts"},
+	AlertGroupsFailed:        {http.StatusInternalServerError, "获取告警分组失败", "Failed to get alert groups"},
+	AlertStatsFailed:         {http.StatusInternalServerError, "获取告警统计失败", "Failed to get alert statistics"},
+	SilenceListFailed:        {http.StatusInternalServerError, "获取静默规则失败", "Failed to list silences"},
+	SilenceCreateFailed:      {http.StatusInternalServerError, "创建静默规则失败", "Failed to create silence"},
+	SilenceDeleteFailed:      {http.StatusInternalServerError, "删除静默规则失败", "Failed to delete silence"},
+	ReceiverListFailed:       {http.StatusInternalServerError, "获取接收器列表失败", "Failed to list receivers"},
+	MonitoringConfigFailed:   {http.StatusInternalServerError, "获取监控配置失败", "Failed to get monitoring configuration"},
+	MonitoringUpdateFailed:   {http.StatusInternalServerError, "更新监控配置失败", "Failed to update monitoring configuration"},
+	MetricsQueryFailed:       {http.StatusInternalServerError, "查询监控指标失败", "Failed to query metrics"},
+	NotificationConfigFailed: {http.StatusInternalServerError, "获取通知配置失败", "Failed to get notification configuration"},
+	NotificationTestFailed:   {http.StatusBadRequest, "发送测试通知失败", "Failed to send test notification"},
+
+	AIDisabled:                    {http.StatusBadRequest, "AI 功能未启用", "AI features are not enabled"},
+	AINotConfigured:               {http.StatusBadRequest, "AI 功能未配置，请在系统设置中配置 AI", "AI is not configured; configure it in system settings"},
+	StreamingUnsupported:          {http.StatusInternalServerError, "不支持流式响应", "Streaming responses are not supported"},
+	AIConfigFailed:                {http.StatusInternalServerError, "获取 AI 配置失败", "Failed to get AI configuration"},
+	AIConfigSaveFailed:            {http.StatusInternalServerError, "保存 AI 配置失败", "Failed to save AI configuration"},
+	APIKeyRequired:                {http.StatusBadRequest, "请提供 API Key", "API key is required"},
+	ArgoCDSaveFailed:              {http.StatusInternalServerError, "保存失败", "Save failed"},
+	GrafanaNotConfigured:          {http.StatusBadRequest, "请先配置 Grafana 连接信息", "Configure the Grafana connection first"},
+	GrafanaConfigFailed:           {http.StatusInternalServerError, "获取 Grafana 配置失败", "Failed to get Grafana configuration"},
+	GrafanaConfigUpdateFailed:     {http.StatusInternalServerError, "更新 Grafana 配置失败", "Failed to update Grafana configuration"},
+	GrafanaConfigSaveFailed:       {http.StatusInternalServerError, "保存 Grafana 配置失败", "Failed to save Grafana configuration"},
+	GrafanaDashboardSyncFailed:    {http.StatusInternalServerError, "同步 Dashboard 失败", "Failed to sync dashboards"},
+	GrafanaDashboardStatusFailed:  {http.StatusInternalServerError, "获取 Dashboard 同步状态失败", "Failed to get dashboard sync status"},
+	GrafanaDatasourceSyncFailed:   {http.StatusInternalServerError, "同步数据源失败", "Failed to sync data sources"},
+	GrafanaDatasourceStatusFailed: {http.StatusInternalServerError, "获取数据源同步状态失败", "Failed to get data source sync status"},
+	LDAPConfigUpdateFailed:        {http.StatusInternalServerError, "更新LDAP配置失败", "Failed to update LDAP configuration"},
+	LDAPConfigSaveFailed:          {http.StatusInternalServerError, "保存LDAP配置失败", "Failed to save LDAP configuration"},
+	SSHConfigFailed:               {http.StatusInternalServerError, "获取SSH配置失败", "Failed to get SSH configuration"},
+	SSHConfigUpdateFailed:         {http.StatusInternalServerError, "更新SSH配置失败", "Failed to update SSH configuration"},
+	SSHConfigSaveFailed:           {http.StatusInternalServerError, "保存SSH配置失败", "Failed to save SSH configuration"},
+	SSHCredentialFailed:           {http.StatusInternalServerError, "获取SSH凭据失败", "Failed to get SSH credentials"},
+	FrontendUnavailable:           {http.StatusInternalServerError, "前端资源不可用", "Frontend is not available"},
+
+	TenantNotFound:               {http.StatusNotFound, "租户不存在", "Tenant not found"},
+	InvalidTenantStatus:          {http.StatusBadRequest, "无效的租户状态", "Invalid tenant status"},
+	TenantNamespaceRequired:      {http.StatusBadRequest, "命名空间不能为空", "Namespace is required"},
+	TenantNamespaceTaken:         {http.StatusConflict, "该命名空间已归属其他租户", "The namespace already belongs to another tenant"},
+	TenantClusterTaken:           {http.StatusConflict, "该集群已有命名空间归属其他租户", "The cluster already has namespaces owned by another tenant"},
+	TenantNamespaceNotFound:      {http.StatusNotFound, "租户命名空间不存在", "Tenant namespace not found"},
+	InvalidTenantRole:            {http.StatusBadRequest, "无效的租户角色", "Invalid tenant role"},
+	TenantBuiltinAdmin:           {http.StatusBadRequest, "平台内置管理员不能加入租户", "The built-in platform admin cannot join a tenant"},
+	TenantMemberTaken:            {http.StatusConflict, "该用户已属于其他租户", "The user already belongs to another tenant"},
+	TenantMemberNotFound:         {http.StatusNotFound, "租户成员不存在", "Tenant member not found"},
+	TenantGrantTypeDenied:        {http.StatusBadRequest, "租户内只能授予开发或只读权限", "Only dev or readonly permissions can be granted within a tenant"},
+	TenantGrantNonMember:         {http.StatusBadRequest, "只能为本租户成员授权", "Permissions can only be granted to members of this tenant"},
+	TenantGrantNamespaceRequired: {http.StatusBadRequest, "必须指定命名空间", "Namespaces must be specified"},
+	TenantGrantNamespaceDenied:   {http.StatusBadRequest, "命名空间 %s 不属于本租户", "Namespace %s does not belong to this tenant"},
+	TenantGrantNotFound:          {http.StatusNotFound, "租户授权不存在", "Tenant permission not found"},
+
+	UsernameExists:               {http.StatusConflict, "用户名已存在", "Username already exists"},
+	AdminUserDeleteDenied:        {http.StatusBadRequest, "不能删除 admin 用户", "The admin user cannot be deleted"},
+	AdminUserStatusDenied:        {http.StatusBadRequest, "不能修改 admin 用户状态", "The admin user status cannot be changed"},
+	InvalidUserStatus:            {http.StatusBadRequest, "无效的状态值", "Invalid status value"},
+	LDAPPasswordResetDenied:      {http.StatusBadRequest, "LDAP 用户不能重置密码", "LDAP users cannot have their password reset"},
+	LDAPMultipleUsers:            {http.StatusUnauthorized, "找到多个匹配用户", "Multiple matching LDAP users found"},
+	UserGroupNotFound:            {http.StatusNotFound, "用户组不存在", "User group not found"},
+	UserGroupInUse:               {http.StatusConflict, "该用户组还有关联的权限配置，请先删除相关权限", "The user group still has permissions; delete them first"},
+	ClusterGroupNotFound:         {http.StatusNotFound, "集群分组不存在", "Cluster group not found"},
+	ClusterGroupSelectorRequired: {http.StatusBadRequest, "标签选择器不能为空", "Label selector is required"},
+	ClusterGroupFilterConflict:   {http.StatusBadRequest, "标签 %s 的过滤条件与集群分组冲突", "The filter on label %s conflicts with the cluster group"},
+	PermissionGroupScopeConflict: {http.StatusBadRequest, "集群分组不能与集群ID或集群标签选择器同时指定", "A cluster group cannot be combined with a cluster ID or cluster selector"},
+	PermissionScopeRequired:      {http.StatusBadRequest, "集群ID和集群标签选择器不能同时为空", "Either a cluster ID or a cluster selector is required"},
+	PermissionScopeConflict:      {http.StatusBadRequest, "不能同时指定集群ID和集群标签选择器", "A cluster ID and a cluster selector cannot both be specified"},
+	PermissionSubjectRequired:    {http.StatusBadRequest, "必须指定用户或用户组", "A user or user group must be specified"},
+	PermissionSubjectConflict:    {http.StatusBadRequest, "不能同时指定用户和用户组", "A user and a user group cannot both be specified"},
+	InvalidPermissionType:        {http.StatusBadRequest, "无效的权限类型", "Invalid permission type"},
+	CustomRoleRequired:           {http.StatusBadRequest, "自定义权限必须指定ClusterRole或Role", "Custom permissions require a ClusterRole or Role"},
+	PermissionExists:             {http.StatusConflict, "该用户/用户组在此集群（或相同集群选择器）已有权限配置", "The user or group already has a permission on this cluster (or the same cluster selector)"},
+	PermissionNotFound:           {http.StatusNotFound, "权限配置不存在", "Permission not found"},
+	PermissionSelectorOnCluster:  {http.StatusBadRequest, "该权限绑定的是单个集群，不能设置集群标签选择器", "This permission is bound to a single cluster and cannot use a cluster selector"},
+	PermissionUserMismatch:       {http.StatusBadRequest, "该授权不属于指定用户", "The permission does not belong to the specified user"},
+
+	InvalidPolicyEffect:      {http.StatusBadRequest, "策略效果只能为 allow 或 deny", "Policy effect must be allow or deny"},
+	PolicyNotFound:           {http.StatusNotFound, "策略不存在", "Policy not found"},
+	InvalidCommandRuleEffect: {http.StatusBadRequest, "规则效果只能为 allow、confirm 或 deny", "Rule effect must be allow, confirm or deny"},
+	CommandPatternRequired:   {http.StatusBadRequest, "匹配模式不能为空", "Match pattern is required"},
+	InvalidCommandRegex:      {http.StatusBadRequest, "无效的正则表达式: %v", "Invalid regular expression: %v"},
+	InvalidCommandMatchType:  {http.StatusBadRequest, "匹配方式只能为 prefix、contains 或 regex", "Match type must be prefix, contains or regex"},
+	UnsupportedTerminalType:  {http.StatusBadRequest, "不支持的终端类型: %s", "Unsupported terminal type: %s"},
+	InvalidNamespacePattern:  {http.StatusBadRequest, "无效的命名空间模式: %s", "Invalid namespace pattern: %s"},
+	CommandRuleNotFound:      {http.StatusNotFound, "终端命令规则不存在", "Terminal command rule not found"},
+
+	InvalidFreezeNamespacePattern: {http.StatusBadRequest, "无效的命名空间匹配模式: %s", "Invalid namespace match pattern: %s"},
+	InvalidFreezeWebhook:          {http.StatusBadRequest, "通知地址必须是 http/https URL", "The notification address must be an http/https URL"},
+	FreezeCalendarNotFound:        {http.StatusNotFound, "冻结日历不存在", "Freeze calendar not found"},
+	InvalidFreezeWindow:           {http.StatusBadRequest, "结束时间必须晚于开始时间", "The end time must be after the start time"},
+	FreezeWindowNotFound:          {http.StatusNotFound, "冻结窗口不存在", "Freeze window not found"},
+
+	InvalidSSHPort:         {http.StatusBadRequest, "无效的 SSH 端口", "Invalid SSH port"},
+	NegativeSSHCertTTL:     {http.StatusBadRequest, "证书有效期不能为负数", "Certificate TTL cannot be negative"},
+	SSHPasswordRequired:    {http.StatusBadRequest, "密码不能为空", "Password is required"},
+	SSHPrivateKeyRequired:  {http.StatusBadRequest, "私钥不能为空", "Private key is required"},
+	InvalidSSHPrivateKey:   {http.StatusBadRequest, "解析私钥失败", "Failed to parse the private key"},
+	InvalidSSHCAKey:        {http.StatusInternalServerError, "解析 CA 私钥失败", "Failed to parse the CA private key"},
+	InvalidSSHAuthType:     {http.StatusBadRequest, "认证方式只能为 password、key 或 certificate", "Auth type must be password, key or certificate"},
+	UnsupportedSSHAuthType: {http.StatusBadRequest, "不支持的认证类型: %s", "Unsupported auth type: %s"},
+	SSHProfileNotFound:     {http.StatusNotFound, "SSH 凭据配置不存在", "SSH credential profile not found"},
+	SSHProfileUnavailable:  {http.StatusNotFound, "没有适用于该节点的 SSH 凭据配置", "No SSH credential profile applies to this node"},
+	HostKeyNotFound:        {http.StatusNotFound, "主机密钥记录不存在", "Host key record not found"},
+	HostKeyNotPending:      {http.StatusBadRequest, "该主机没有待确认的新密钥", "The host has no pending key to accept"},
+
+	InvalidAuditSuccessFilter:      {http.StatusBadRequest, "成功过滤只能为空、success 或 failure", "Success filter must be empty, success or failure"},
+	AuditSinkNotFound:              {http.StatusNotFound, "审计外送目标不存在", "Audit sink not found"},
+	K8sAuditUnauthorized:           {http.StatusUnauthorized, "审计 Webhook 未启用或令牌无效", "Audit webhook is disabled or the token is invalid"},
+	InvalidK8sAuditEvents:          {http.StatusBadRequest, "审计事件格式错误", "Invalid audit event payload"},
+	UserNotDeleted:                 {http.StatusBadRequest, "只能匿名化已删除的用户", "Only deleted users can be anonymized"},
+	AuditRetentionRunning:          {http.StatusConflict, "审计记录清理任务正在执行", "An audit purge is already running"},
+	UnknownAuditChain:              {http.StatusBadRequest, "未知的审计链", "Unknown audit chain"},
+	AuditChainBroken:               {http.StatusConflict, "审计哈希链校验未通过", "The audit hash chain failed verification"},
+	AuditAnchorRecordMissing:       {http.StatusInternalServerError, "%s 第 %d 条记录不存在，无法记录清理锚点", "%s record %d does not exist; cannot record the purge anchor"},
+	NotificationChannelDuplicate:   {http.StatusBadRequest, "渠道名称重复: %s", "Duplicate channel name: %s"},
+	SubscriptionNameRequired:       {http.StatusBadRequest, "订阅名称不能为空", "Subscription name is required"},
+	SubscriptionIncomplete:         {http.StatusBadRequest, "订阅 %s 至少需要一个事件和一个渠道", "Subscription %s needs at least one event and one channel"},
+	SubscriptionUnknownEvent:       {http.StatusBadRequest, "订阅 %s: 未知事件 %s", "Subscription %s: unknown event %s"},
+	SubscriptionChannelNotFound:    {http.StatusBadRequest, "订阅 %s: 渠道 %s 不存在", "Subscription %s: channel %s does not exist"},
+	NotificationChannelUnavailable: {http.StatusBadRequest, "通知渠道不存在或已停用", "The notification channel does not exist or is disabled"},
+	DeliveryNotRetryable:           {http.StatusBadRequest, "投递记录不存在或未失败", "The delivery does not exist or has not failed"},
+
+	ArgoCDCredentialsRequired: {http.StatusBadRequest, "请提供 API Token 或用户名密码", "Provide an API token or a username and password"},
+	ArgoCDTokenInvalid:        {http.StatusBadRequest, "认证失败: Token 无效或已过期", "Authentication failed: the token is invalid or expired"},
+	ArgoCDResponseError:       {http.StatusBadGateway, "ArgoCD 响应错误 (状态码 %d): %s", "ArgoCD returned an error (status %d): %s"},
+	ArgoCDNotConfigured:       {http.StatusBadRequest, "ArgoCD 集成未启用，请先在插件中心配置", "ArgoCD integration is not enabled; configure it in the plugin center first"},
+	ArgoCDAPIError:            {http.StatusBadGateway, "ArgoCD API 错误 (状态码 %d): %s", "ArgoCD API error (status %d): %s"},
+	ArgoCDAppNotFound:         {http.StatusNotFound, "应用 %s 不存在", "Application %s not found"},
+	ArgoCDAppGetFailed:        {http.StatusBadGateway, "获取应用详情失败: %s", "Failed to get application details: %s"},
+	ArgoCDAppCreateFailed:     {http.StatusBadGateway, "ArgoCD 创建应用失败 (状态码 %d): %s", "ArgoCD failed to create the application (status %d): %s"},
+	ArgoCDAppUpdateFailed:     {http.StatusBadGateway, "ArgoCD 更新应用失败: %s", "ArgoCD failed to update the application: %s"},
+	ArgoCDSyncFailed:          {http.StatusBadGateway, "同步失败 (状态码 %d): %s", "Sync failed (status %d): %s"},
+	ArgoCDDeleteFailed:        {http.StatusBadGateway, "删除失败 (状态码 %d): %s", "Delete failed (status %d): %s"},
+	ArgoCDRollbackFailed:      {http.StatusBadGateway, "回滚失败 (状态码 %d): %s", "Rollback failed (status %d): %s"},
+	ArgoCDResourceTreeFailed:  {http.StatusBadGateway, "获取资源树失败: %s", "Failed to get the resource tree: %s"},
+	ArgoCDLoginFailed:         {http.StatusBadGateway, "登录失败 (状态码 %d): %s", "Login failed (status %d): %s"},
+	ArgoCDTokenMissing:        {http.StatusBadGateway, "登录成功但未返回 token", "Login succeeded but no token was returned"},
+}
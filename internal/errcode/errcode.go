@@ -0,0 +1,194 @@
+// Package errcode 稳定的机器可读错误码、带错误码的业务错误与中英文错误文案目录。
+//
+// 服务层返回 *Error，处理器通过 response.Fail 按请求语言输出本地化文案；
+// 客户端应根据 code 分支处理，不应解析 message。
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Code 错误码（对外稳定，新增可以，已发布的不应修改或复用）
+type Code string
+
+// 支持的语言
+const (
+	LangZH = "zh"
+	LangEN = "en"
+
+	// DefaultLanguage 未指定语言时使用中文（与历史行为一致）
+	DefaultLanguage = LangZH
+)
+
+// Error 带错误码的业务错误
+type Error struct {
+	Code Code
+	Args []interface{} // 文案参数
+	Err  error         // 底层原因，附加在文案之后
+}
+
+// New 按错误码创建错误
+func New(code Code, args ...interface{}) *Error {
+	return &Error{Code: code, Args: args}
+}
+
+// Wrap 以错误码包装底层错误，文案为 "<错误码文案>: <底层错误>"
+func Wrap(err error, code Code, args ...interface{}) *Error {
+	return &Error{Code: code, Args: args, Err: err}
+}
+
+// Ensure 错误链中已带错误码时返回该错误，否则以 code 包装，
+// 供处理器为服务层未分类的底层错误（如数据库错误）指定错误码
+func Ensure(err error, code Code, args ...interface{}) *Error {
+	if e, ok := As(err); ok {
+		return e
+	}
+	return Wrap(err, code, args...)
+}
+
+// Error 默认语言的文案（用于日志与尚未按语言输出的调用方）
+func (e *Error) Error() string {
+	return e.Message(DefaultLanguage)
+}
+
+// Unwrap 返回底层错误
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is 错误码相同即视为同一错误，便于 errors.Is(err, errcode.New(code)) 判断
+func (e *Error) Is(target error) bool {
+	var t *Error
+	return errors.As(target, &t) && t.Code == e.Code
+}
+
+// Status 错误码对应的 HTTP 状态码
+func (e *Error) Status() int {
+	if m, ok := catalog[e.Code]; ok {
+		return m.status
+	}
+	return http.StatusInternalServerError
+}
+
+// Message 指定语言的文案
+func (e *Error) Message(lang string) string {
+	msg := Message(lang, e.Code, e.Args...)
+	if inner, ok := As(e.Err); ok {
+		// 底层错误也带错误码时同样按语言输出
+		msg += ": " + inner.Message(lang)
+	} else if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Message 按语言渲染错误码文案，未登记的错误码返回错误码本身
+func Message(lang string, code Code, args ...interface{}) string {
+	m, ok := catalog[code]
+	if !ok {
+		return string(code)
+	}
+	tmpl := m.zh
+	if lang == LangEN {
+		tmpl = m.en
+	}
+	if len(args) == 0 {
+		return tmpl
+	}
+	return fmt.Sprintf(tmpl, args...)
+}
+
+// As 提取错误链中的 *Error
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// Is 判断错误链中是否包含指定错误码
+func Is(err error, code Code) bool {
+	e, ok := As(err)
+	return ok && e.Code == code
+}
+
+// StatusOf 错误对应的 HTTP 状态码，非 *Error 时返回 500
+func StatusOf(err error) int {
+	if e, ok := As(err); ok {
+		return e.Status()
+	}
+	return http.StatusInternalServerError
+}
+
+// NormalizeLanguage 将 zh-CN、en-US 等语言标签归一为支持的语言，不支持时返回空串
+func NormalizeLanguage(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	switch tag {
+	case LangZH, LangEN:
+		return tag
+	}
+	return ""
+}
+
+// ParseAcceptLanguage 按 Accept-Language 的权重选择支持的语言，均不支持时返回默认语言
+func ParseAcceptLanguage(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		lang := NormalizeLanguage(fields[0])
+		if lang == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if _, err := fmt.Sscanf(param[2:], "%g", &q); err != nil {
+					q = 0
+				}
+			}
+		}
+		if q > bestQ {
+			best, bestQ = lang, q
+		}
+	}
+	if best == "" {
+		return DefaultLanguage
+	}
+	return best
+}
+
+// Translate 将历史代码中的中文文案翻译为指定语言：
+// 完整匹配无参数的目录文案，或匹配 "<目录文案>: <详情>" 形式的前缀，未命中时原样返回
+func Translate(lang, message string) string {
+	if lang == DefaultLanguage || message == "" {
+		return message
+	}
+	if code, ok := legacyMessages[message]; ok {
+		return Message(lang, code)
+	}
+	if i := strings.Index(message, ": "); i > 0 {
+		if code, ok := legacyMessages[message[:i]]; ok {
+			return Message(lang, code) + message[i:]
+		}
+	}
+	return message
+}
+
+// legacyMessages 无参数的中文文案到错误码的反查表
+var legacyMessages = func() map[string]Code {
+	m := make(map[string]Code, len(catalog))
+	for code, msg := range catalog {
+		if !strings.Contains(msg.zh, "%") {
+			m[msg.zh] = code
+		}
+	}
+	return m
+}()
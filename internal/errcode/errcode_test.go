@@ -0,0 +1,106 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+var verbPattern = regexp.MustCompile(`%[dsvq]`)
+
+func TestCatalogComplete(t *testing.T) {
+	seen := make(map[string]Code)
+	for code, m := range catalog {
+		if m.status == 0 || m.zh == "" || m.en == "" {
+			t.Errorf("%s: status and both languages are required", code)
+		}
+		if got, want := verbPattern.FindAllString(m.en, -1), verbPattern.FindAllString(m.zh, -1); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s: placeholders differ between zh %v and en %v", code, want, got)
+		}
+		if other, ok := seen[m.zh]; ok {
+			t.Errorf("%s and %s share the zh message %q", code, other, m.zh)
+		}
+		seen[m.zh] = code
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	cases := map[string]string{
+		"":                              LangZH,
+		"en-US,en;q=0.9":                LangEN,
+		"zh-CN,zh;q=0.9,en;q=0.8":       LangZH,
+		"fr-FR,en;q=0.5,zh;q=0.3":       LangEN,
+		"zh;q=0.2, en_GB;q=0.7":         LangEN,
+		"de-DE,ja;q=0.9":                LangZH,
+		"en;q=0, zh-TW;q=0.1":           LangZH,
+		"EN":                            LangEN,
+		"en;q=abc":                      LangZH,
+		"zh-Hans-CN;q=0.8,en-US;q=0.81": LangEN,
+	}
+	for header, want := range cases {
+		if got := ParseAcceptLanguage(header); got != want {
+			t.Errorf("%q: got %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestErrorMessages(t *testing.T) {
+	err := Wrap(errors.New("dial tcp: timeout"), K8sClientFailed)
+	if got := err.Message(LangEN); got != "Failed to get Kubernetes client: dial tcp: timeout" {
+		t.Errorf("wrapped en message: %q", got)
+	}
+	if err.Error() != "获取K8s客户端失败: dial tcp: timeout" {
+		t.Errorf("default message should be zh: %q", err.Error())
+	}
+
+	frozen := New(ChangeFrozen, "发布冻结", "双十一", "2026-11-12 00:00")
+	if got := frozen.Message(LangEN); got != "Changes are frozen: 发布冻结 / 双十一 (until 2026-11-12 00:00); provide a break-glass reason for an emergency change" {
+		t.Errorf("formatted en message: %q", got)
+	}
+	if Message(LangEN, Code("NO_SUCH_CODE")) != "NO_SUCH_CODE" {
+		t.Error("unknown code should fall back to the code itself")
+	}
+}
+
+func TestErrorInspection(t *testing.T) {
+	cause := errors.New("record not found")
+	err := fmt.Errorf("load schedule: %w", Wrap(cause, ReportScheduleNotFound))
+
+	if !Is(err, ReportScheduleNotFound) || Is(err, ReportDeliveryNotFound) {
+		t.Error("Is should match the code in the error chain")
+	}
+	if !errors.Is(err, New(ReportScheduleNotFound)) || !errors.Is(err, cause) {
+		t.Error("errors.Is should match by code and reach the cause")
+	}
+	if StatusOf(err) != http.StatusNotFound || StatusOf(cause) != http.StatusInternalServerError {
+		t.Error("StatusOf should use the catalog status and default to 500")
+	}
+
+	if got := Ensure(err, Internal); got.Code != ReportScheduleNotFound {
+		t.Errorf("Ensure should keep the code already in the chain, got %s", got.Code)
+	}
+	if got := Ensure(cause, Internal); got.Code != Internal || !errors.Is(got, cause) {
+		t.Errorf("Ensure should wrap untyped errors with the default code, got %v", got)
+	}
+	nested := Wrap(New(InvalidCredentials), LDAPAuthFailed)
+	if got := nested.Message(LangEN); got != "LDAP authentication failed: Incorrect username or password" {
+		t.Errorf("wrapped typed error should be localized: %q", got)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	cases := []struct{ lang, in, want string }{
+		{LangEN, "无效的集群ID", "Invalid cluster ID"},
+		{LangEN, "请求参数错误: json: unexpected EOF", "Invalid request parameters: json: unexpected EOF"},
+		{LangEN, "一条未登记的提示", "一条未登记的提示"},
+		{LangEN, "不支持的语言: %s", "不支持的语言: %s"}, // 带参数的文案不参与反查
+		{LangZH, "无效的集群ID", "无效的集群ID"},
+	}
+	for _, c := range cases {
+		if got := Translate(c.lang, c.in); got != c.want {
+			t.Errorf("Translate(%s, %q) = %q, want %q", c.lang, c.in, got, c.want)
+		}
+	}
+}
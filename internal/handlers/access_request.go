@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+// AccessRequestHandler 临时提权申请处理器
+type AccessRequestHandler struct {
+	accessRequestService *services.AccessRequestService
+}
+
+// NewAccessRequestHandler 创建临时提权申请处理器
+func NewAccessRequestHandler(accessRequestService *services.AccessRequestService) *AccessRequestHandler {
+	return &AccessRequestHandler{
+		accessRequestService: accessRequestService,
+	}
+}
+
+// GetConfig 获取临时提权配置
+func (h *AccessRequestHandler) GetConfig(c *gin.Context) {
+	config, err := h.accessRequestService.GetConfig()
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.AccessRequestConfigFailed))
+		return
+	}
+	response.OK(c, config)
+}
+
+// UpdateConfig 更新临时提权配置
+func (h *AccessRequestHandler) UpdateConfig(c *gin.Context) {
+	var config models.JITAccessConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+	if err := h.accessRequestService.SaveConfig(&config); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, config)
+}
+
+// CreateAccessRequest 提交临时提权申请
+func (h *AccessRequestHandler) CreateAccessRequest(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		response.FailCode(c, errcode.NotLoggedIn)
+		return
+	}
+
+	var req services.CreateAccessRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	accessReq, err := h.accessRequestService.CreateRequest(userID, &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Created(c, accessReq)
+}
+
+// ListAccessRequests 获取申请列表
+// 审批组成员可查看全部申请，其他用户只能查看自己的申请
+func (h *AccessRequestHandler) ListAccessRequests(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	req := &services.AccessRequestListRequest{
+		Status:   c.Query("status"),
+		Page:     getIntParam(c, "page", 1),
+		PageSize: getIntParam(c, "pageSize", 20),
+	}
+	if clusterIDStr := c.Query("clusterId"); clusterIDStr != "" {
+		if cid, err := strconv.ParseUint(clusterIDStr, 10, 32); err == nil {
+			req.ClusterID = uint(cid)
+		}
+	}
+	if c.Query("mine") == "true" || !h.accessRequestService.IsApprover(userID) {
+		req.UserID = userID
+	}
+
+	items, total, err := h.accessRequestService.ListRequests(req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.PagedList(c, items, total, req.Page, req.PageSize)
+}
+
+// GetAccessRequest 获取申请详情
+func (h *AccessRequestHandler) GetAccessRequest(c *gin.Context) {
+	id, ok := parseAccessRequestID(c)
+	if !ok {
+		return
+	}
+
+	accessReq, err := h.accessRequestService.GetRequest(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if accessReq.UserID != userID && !h.accessRequestService.IsApprover(userID) {
+		response.FailCode(c, errcode.AccessRequestForbidden)
+		return
+	}
+	response.OK(c, accessReq)
+}
+
+// ReviewAccessRequestRequest 审批请求
+type ReviewAccessRequestRequest struct {
+	Comment string `json:"comment"`
+}
+
+// ApproveAccessRequest 批准申请
+func (h *AccessRequestHandler) ApproveAccessRequest(c *gin.Context) {
+	id, ok := parseAccessRequestID(c)
+	if !ok {
+		return
+	}
+	var req ReviewAccessRequestRequest
+	_ = c.ShouldBindJSON(&req)
+
+	accessReq, err := h.accessRequestService.Approve(id, c.GetUint("user_id"), req.Comment)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, accessReq)
+}
+
+// RejectAccessRequest 拒绝申请
+func (h *AccessRequestHandler) RejectAccessRequest(c *gin.Context) {
+	id, ok := parseAccessRequestID(c)
+	if !ok {
+		return
+	}
+	var req ReviewAccessRequestRequest
+	_ = c.ShouldBindJSON(&req)
+
+	accessReq, err := h.accessRequestService.Reject(id, c.GetUint("user_id"), req.Comment)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, accessReq)
+}
+
+// CancelAccessRequest 撤回申请
+func (h *AccessRequestHandler) CancelAccessRequest(c *gin.Context) {
+	id, ok := parseAccessRequestID(c)
+	if !ok {
+		return
+	}
+	if err := h.accessRequestService.Cancel(id, c.GetUint("user_id")); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// RevokeAccessRequest 提前撤销临时权限
+func (h *AccessRequestHandler) RevokeAccessRequest(c *gin.Context) {
+	id, ok := parseAccessRequestID(c)
+	if !ok {
+		return
+	}
+	var req ReviewAccessRequestRequest
+	_ = c.ShouldBindJSON(&req)
+
+	accessReq, err := h.accessRequestService.Revoke(id, c.GetUint("user_id"), req.Comment)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, accessReq)
+}
+
+// parseAccessRequestID 解析路径中的申请ID
+func parseAccessRequestID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidAccessRequestID)
+		return 0, false
+	}
+	return uint(id), true
+}
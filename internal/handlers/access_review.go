@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+// AccessReviewHandler 授权复核处理器
+type AccessReviewHandler struct {
+	accessReviewService *services.AccessReviewService
+}
+
+// NewAccessReviewHandler 创建授权复核处理器
+func NewAccessReviewHandler(accessReviewService *services.AccessReviewService) *AccessReviewHandler {
+	return &AccessReviewHandler{
+		accessReviewService: accessReviewService,
+	}
+}
+
+// GetAccessReviewReport 获取授权复核报表
+// 查询参数：inactiveDays（默认 90）、production（生产集群标签选择器，默认 env=prod）、userId、clusterId、flag
+func (h *AccessReviewHandler) GetAccessReviewReport(c *gin.Context) {
+	opts, ok := parseAccessReviewOptions(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.accessReviewService.GenerateReport(opts)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, report)
+}
+
+// ExportAccessReviewReport 导出授权复核报表（format=csv|json）
+func (h *AccessReviewHandler) ExportAccessReviewReport(c *gin.Context) {
+	opts, ok := parseAccessReviewOptions(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.accessReviewService.GenerateReport(opts)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("access-review-%s", time.Now().Format("20060102-150405"))
+	switch c.DefaultQuery("format", "csv") {
+	case "csv":
+		var buf bytes.Buffer
+		if err := services.WriteAccessReviewCSV(&buf, report); err != nil {
+			response.Fail(c, errcode.Wrap(err, errcode.ExportFailed))
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.csv", filename))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+	case "json":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			response.Fail(c, errcode.Wrap(err, errcode.ExportFailed))
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.json", filename))
+		c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+	default:
+		response.FailCode(c, errcode.UnsupportedExportFormat)
+	}
+}
+
+// ListAccessReviews 获取复核记录
+func (h *AccessReviewHandler) ListAccessReviews(c *gin.Context) {
+	page := getIntParam(c, "page", 1)
+	pageSize := getIntParam(c, "pageSize", 20)
+	userID, _ := strconv.ParseUint(c.Query("userId"), 10, 64)
+
+	reviews, total, err := h.accessReviewService.ListReviews(page, pageSize, uint(userID))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.PagedList(c, reviews, total, page, pageSize)
+}
+
+// AttestAccess 确认保留授权
+func (h *AccessReviewHandler) AttestAccess(c *gin.Context) {
+	var req services.AccessReviewDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	review, err := h.accessReviewService.Attest(c.GetUint("user_id"), &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, review)
+}
+
+// RevokeAccess 撤销授权
+func (h *AccessReviewHandler) RevokeAccess(c *gin.Context) {
+	var req services.AccessReviewDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	review, err := h.accessReviewService.Revoke(c.GetUint("user_id"), &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, review)
+}
+
+// parseAccessReviewOptions 解析复核报表查询参数
+func parseAccessReviewOptions(c *gin.Context) (services.AccessReviewOptions, bool) {
+	opts := services.AccessReviewOptions{
+		InactiveDays: getIntParam(c, "inactiveDays", services.DefaultInactiveDays),
+		Flag:         c.Query("flag"),
+	}
+
+	selector, err := models.ParseLabelSelector(c.DefaultQuery("production", services.DefaultProductionSelector))
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.InvalidProdSelector))
+		return opts, false
+	}
+	opts.ProductionSelector = selector
+
+	if userID, err := strconv.ParseUint(c.Query("userId"), 10, 64); err == nil {
+		opts.UserID = uint(userID)
+	}
+	if clusterID, err := strconv.ParseUint(c.Query("clusterId"), 10, 64); err == nil {
+		opts.ClusterID = uint(clusterID)
+	}
+	return opts, true
+}
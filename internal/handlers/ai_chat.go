@@ -12,6 +12,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
@@ -45,34 +46,34 @@ func (h *AIChatHandler) Chat(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	var req chatRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	if len(req.Messages) == 0 {
-		response.BadRequest(c, "消息不能为空")
+		response.FailCode(c, errcode.MessageRequired)
 		return
 	}
 
 	aiConfig, err := h.aiConfigService.GetConfigWithAPIKey()
 	if err != nil || aiConfig == nil {
-		response.BadRequest(c, "AI 功能未配置，请在系统设置中配置 AI")
+		response.FailCode(c, errcode.AINotConfigured)
 		return
 	}
 	if !aiConfig.Enabled || aiConfig.APIKey == "" {
-		response.BadRequest(c, "AI 功能未启用")
+		response.FailCode(c, errcode.AIDisabled)
 		return
 	}
 
 	cluster, err := h.clusterService.GetCluster(uint(clusterID))
 	if err != nil {
-		response.BadRequest(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -98,7 +99,7 @@ func (h *AIChatHandler) Chat(c *gin.Context) {
 
 	flusher, ok := c.Writer.(http.Flusher)
 	if !ok {
-		response.InternalError(c, "不支持流式响应")
+		response.FailCode(c, errcode.StreamingUnsupported)
 		return
 	}
 
@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
@@ -30,7 +31,7 @@ func (h *AIConfigHandler) GetConfig(c *gin.Context) {
 	config, err := h.configService.GetConfig()
 	if err != nil {
 		logger.Error("获取 AI 配置失败", "error", err)
-		response.InternalError(c, "获取 AI 配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AIConfigFailed))
 		return
 	}
 
@@ -75,7 +76,7 @@ func (h *AIConfigHandler) UpdateConfig(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -89,7 +90,7 @@ func (h *AIConfigHandler) UpdateConfig(c *gin.Context) {
 
 	if err := h.configService.SaveConfig(config); err != nil {
 		logger.Error("保存 AI 配置失败", "error", err)
-		response.InternalError(c, "保存 AI 配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AIConfigSaveFailed))
 		return
 	}
 
@@ -106,7 +107,7 @@ func (h *AIConfigHandler) TestConnection(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -114,7 +115,7 @@ func (h *AIConfigHandler) TestConnection(c *gin.Context) {
 	if apiKey == "" || apiKey == "******" {
 		fullConfig, err := h.configService.GetConfigWithAPIKey()
 		if err != nil || fullConfig == nil || fullConfig.APIKey == "" {
-			response.BadRequest(c, "请提供 API Key")
+			response.FailCode(c, errcode.APIKeyRequired)
 			return
 		}
 		apiKey = fullConfig.APIKey
@@ -3,6 +3,7 @@ package handlers
 import (
 	"strconv"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
@@ -30,14 +31,14 @@ func (h *AlertHandler) GetAlertManagerConfig(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	config, err := h.alertManagerConfigService.GetAlertManagerConfig(uint(clusterID))
 	if err != nil {
 		logger.Error("获取 Alertmanager 配置失败", "error", err)
-		response.InternalError(c, "获取 Alertmanager 配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AlertmanagerConfigFailed))
 		return
 	}
 
@@ -49,20 +50,20 @@ func (h *AlertHandler) UpdateAlertManagerConfig(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	var config models.AlertManagerConfig
 	if err := c.ShouldBindJSON(&config); err != nil {
-		response.BadRequest(c, "请求参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	// 更新配置
 	if err := h.alertManagerConfigService.UpdateAlertManagerConfig(uint(clusterID), &config); err != nil {
 		logger.Error("更新 Alertmanager 配置失败", "error", err)
-		response.InternalError(c, "更新 Alertmanager 配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AlertmanagerUpdateFailed))
 		return
 	}
 
@@ -74,20 +75,20 @@ func (h *AlertHandler) TestAlertManagerConnection(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	_, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	var config models.AlertManagerConfig
 	if err := c.ShouldBindJSON(&config); err != nil {
-		response.BadRequest(c, "请求参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	// 测试连接
 	if err := h.alertManagerService.TestConnection(c.Request.Context(), &config); err != nil {
 		logger.Error("测试 Alertmanager 连接失败", "error", err)
-		response.BadRequest(c, "连接测试失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ConnectionTestFailed))
 		return
 	}
 
@@ -99,7 +100,7 @@ func (h *AlertHandler) GetAlertManagerStatus(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -107,7 +108,7 @@ func (h *AlertHandler) GetAlertManagerStatus(c *gin.Context) {
 	config, err := h.alertManagerConfigService.GetAlertManagerConfig(uint(clusterID))
 	if err != nil {
 		logger.Error("获取 Alertmanager 配置失败", "error", err)
-		response.InternalError(c, "获取 Alertmanager 配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AlertmanagerConfigFailed))
 		return
 	}
 
@@ -120,7 +121,7 @@ func (h *AlertHandler) GetAlertManagerStatus(c *gin.Context) {
 	status, err := h.alertManagerService.GetStatus(c.Request.Context(), config)
 	if err != nil {
 		logger.Error("获取 Alertmanager 状态失败", "error", err)
-		response.InternalError(c, "获取 Alertmanager 状态失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AlertmanagerStatusFailed))
 		return
 	}
 
@@ -132,7 +133,7 @@ func (h *AlertHandler) GetAlerts(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -140,7 +141,7 @@ func (h *AlertHandler) GetAlerts(c *gin.Context) {
 	config, err := h.alertManagerConfigService.GetAlertManagerConfig(uint(clusterID))
 	if err != nil {
 		logger.Error("获取 Alertmanager 配置失败", "error", err)
-		response.InternalError(c, "获取 Alertmanager 配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AlertmanagerConfigFailed))
 		return
 	}
 
@@ -162,7 +163,7 @@ func (h *AlertHandler) GetAlerts(c *gin.Context) {
 	alerts, err := h.alertManagerService.GetAlerts(c.Request.Context(), config, filter)
 	if err != nil {
 		logger.Error("获取告警列表失败", "error", err)
-		response.InternalError(c, "获取告警列表失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AlertListFailed))
 		return
 	}
 
@@ -174,7 +175,7 @@ func (h *AlertHandler) GetAlertGroups(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -182,7 +183,7 @@ func (h *AlertHandler) GetAlertGroups(c *gin.Context) {
 	config, err := h.alertManagerConfigService.GetAlertManagerConfig(uint(clusterID))
 	if err != nil {
 		logger.Error("获取 Alertmanager 配置失败", "error", err)
-		response.InternalError(c, "获取 Alertmanager 配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AlertmanagerConfigFailed))
 		return
 	}
 
@@ -195,7 +196,7 @@ func (h *AlertHandler) GetAlertGroups(c *gin.Context) {
 	groups, err := h.alertManagerService.GetAlertGroups(c.Request.Context(), config)
 	if err != nil {
 		logger.Error("获取告警分组失败", "error", err)
-		response.InternalError(c, "获取告警分组失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AlertGroupsFailed))
 		return
 	}
 
@@ -207,7 +208,7 @@ func (h *AlertHandler) GetAlertStats(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -215,7 +216,7 @@ func (h *AlertHandler) GetAlertStats(c *gin.Context) {
 	config, err := h.alertManagerConfigService.GetAlertManagerConfig(uint(clusterID))
 	if err != nil {
 		logger.Error("获取 Alertmanager 配置失败", "error", err)
-		response.InternalError(c, "获取 Alertmanager 配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AlertmanagerConfigFailed))
 		return
 	}
 
@@ -235,7 +236,7 @@ func (h *AlertHandler) GetAlertStats(c *gin.Context) {
 	stats, err := h.alertManagerService.GetAlertStats(c.Request.Context(), config)
 	if err != nil {
 		logger.Error("获取告警统计失败", "error", err)
-		response.InternalError(c, "获取告警统计失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AlertStatsFailed))
 		return
 	}
 
@@ -247,7 +248,7 @@ func (h *AlertHandler) GetSilences(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -255,7 +256,7 @@ func (h *AlertHandler) GetSilences(c *gin.Context) {
 	config, err := h.alertManagerConfigService.GetAlertManagerConfig(uint(clusterID))
 	if err != nil {
 		logger.Error("获取 Alertmanager 配置失败", "error", err)
-		response.InternalError(c, "获取 Alertmanager 配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AlertmanagerConfigFailed))
 		return
 	}
 
@@ -268,7 +269,7 @@ func (h *AlertHandler) GetSilences(c *gin.Context) {
 	silences, err := h.alertManagerService.GetSilences(c.Request.Context(), config)
 	if err != nil {
 		logger.Error("获取静默规则失败", "error", err)
-		response.InternalError(c, "获取静默规则失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.SilenceListFailed))
 		return
 	}
 
@@ -280,7 +281,7 @@ func (h *AlertHandler) CreateSilence(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -288,18 +289,18 @@ func (h *AlertHandler) CreateSilence(c *gin.Context) {
 	config, err := h.alertManagerConfigService.GetAlertManagerConfig(uint(clusterID))
 	if err != nil {
 		logger.Error("获取 Alertmanager 配置失败", "error", err)
-		response.InternalError(c, "获取 Alertmanager 配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AlertmanagerConfigFailed))
 		return
 	}
 
 	if !config.Enabled {
-		response.BadRequest(c, "Alertmanager 未启用")
+		response.FailCode(c, errcode.AlertmanagerDisabled)
 		return
 	}
 
 	var req models.CreateSilenceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -307,7 +308,7 @@ func (h *AlertHandler) CreateSilence(c *gin.Context) {
 	silence, err := h.alertManagerService.CreateSilence(c.Request.Context(), config, &req)
 	if err != nil {
 		logger.Error("创建静默规则失败", "error", err)
-		response.InternalError(c, "创建静默规则失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.SilenceCreateFailed))
 		return
 	}
 
@@ -319,13 +320,13 @@ func (h *AlertHandler) DeleteSilence(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	silenceID := c.Param("silenceId")
 	if silenceID == "" {
-		response.BadRequest(c, "静默规则ID不能为空")
+		response.FailCode(c, errcode.SilenceIDRequired)
 		return
 	}
 
@@ -333,19 +334,19 @@ func (h *AlertHandler) DeleteSilence(c *gin.Context) {
 	config, err := h.alertManagerConfigService.GetAlertManagerConfig(uint(clusterID))
 	if err != nil {
 		logger.Error("获取 Alertmanager 配置失败", "error", err)
-		response.InternalError(c, "获取 Alertmanager 配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AlertmanagerConfigFailed))
 		return
 	}
 
 	if !config.Enabled {
-		response.BadRequest(c, "Alertmanager 未启用")
+		response.FailCode(c, errcode.AlertmanagerDisabled)
 		return
 	}
 
 	// 删除静默规则
 	if err := h.alertManagerService.DeleteSilence(c.Request.Context(), config, silenceID); err != nil {
 		logger.Error("删除静默规则失败", "error", err)
-		response.InternalError(c, "删除静默规则失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.SilenceDeleteFailed))
 		return
 	}
 
@@ -357,7 +358,7 @@ func (h *AlertHandler) GetReceivers(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -365,7 +366,7 @@ func (h *AlertHandler) GetReceivers(c *gin.Context) {
 	config, err := h.alertManagerConfigService.GetAlertManagerConfig(uint(clusterID))
 	if err != nil {
 		logger.Error("获取 Alertmanager 配置失败", "error", err)
-		response.InternalError(c, "获取 Alertmanager 配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AlertmanagerConfigFailed))
 		return
 	}
 
@@ -378,7 +379,7 @@ func (h *AlertHandler) GetReceivers(c *gin.Context) {
 	receivers, err := h.alertManagerService.GetReceivers(c.Request.Context(), config)
 	if err != nil {
 		logger.Error("获取接收器列表失败", "error", err)
-		response.InternalError(c, "获取接收器列表失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ReceiverListFailed))
 		return
 	}
 
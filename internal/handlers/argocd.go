@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
@@ -36,13 +37,13 @@ func NewArgoCDHandler(db *gorm.DB, argoCDSvc *services.ArgoCDService) *ArgoCDHan
 func (h *ArgoCDHandler) GetConfig(c *gin.Context) {
 	clusterID, err := strconv.ParseUint(c.Param("clusterID"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	config, err := h.argoCDSvc.GetConfig(c.Request.Context(), uint(clusterID))
 	if err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -68,14 +69,14 @@ func (h *ArgoCDHandler) GetConfig(c *gin.Context) {
 func (h *ArgoCDHandler) SaveConfig(c *gin.Context) {
 	clusterID, err := strconv.ParseUint(c.Param("clusterID"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	// 使用请求结构体接收前端数据（包含敏感字段）
 	var req models.ArgoCDConfigRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -101,7 +102,7 @@ func (h *ArgoCDHandler) SaveConfig(c *gin.Context) {
 	}
 
 	if err := h.argoCDSvc.SaveConfig(c.Request.Context(), config); err != nil {
-		response.InternalError(c, "保存失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ArgoCDSaveFailed))
 		return
 	}
 
@@ -120,14 +121,14 @@ func (h *ArgoCDHandler) SaveConfig(c *gin.Context) {
 func (h *ArgoCDHandler) TestConnection(c *gin.Context) {
 	clusterID, err := strconv.ParseUint(c.Param("clusterID"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	// 使用请求结构体接收前端数据（包含敏感字段）
 	var req models.ArgoCDConfigRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
@@ -167,13 +168,13 @@ func (h *ArgoCDHandler) TestConnection(c *gin.Context) {
 func (h *ArgoCDHandler) ListApplications(c *gin.Context) {
 	clusterID, err := strconv.ParseUint(c.Param("clusterID"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	apps, err := h.argoCDSvc.ListApplications(c.Request.Context(), uint(clusterID))
 	if err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -191,14 +192,14 @@ func (h *ArgoCDHandler) ListApplications(c *gin.Context) {
 func (h *ArgoCDHandler) GetApplication(c *gin.Context) {
 	clusterID, err := strconv.ParseUint(c.Param("clusterID"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	appName := c.Param("appName")
 
 	app, err := h.argoCDSvc.GetApplication(c.Request.Context(), uint(clusterID), appName)
 	if err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -217,19 +218,19 @@ func (h *ArgoCDHandler) GetApplication(c *gin.Context) {
 func (h *ArgoCDHandler) CreateApplication(c *gin.Context) {
 	clusterID, err := strconv.ParseUint(c.Param("clusterID"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	var req models.CreateApplicationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	app, err := h.argoCDSvc.CreateApplication(c.Request.Context(), uint(clusterID), &req)
 	if err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -249,20 +250,20 @@ func (h *ArgoCDHandler) CreateApplication(c *gin.Context) {
 func (h *ArgoCDHandler) UpdateApplication(c *gin.Context) {
 	clusterID, err := strconv.ParseUint(c.Param("clusterID"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	appName := c.Param("appName")
 
 	var req models.CreateApplicationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	app, err := h.argoCDSvc.UpdateApplication(c.Request.Context(), uint(clusterID), appName, &req)
 	if err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -282,19 +283,19 @@ func (h *ArgoCDHandler) UpdateApplication(c *gin.Context) {
 func (h *ArgoCDHandler) SyncApplication(c *gin.Context) {
 	clusterID, err := strconv.ParseUint(c.Param("clusterID"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	appName := c.Param("appName")
 
 	var req models.SyncApplicationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	if err := h.argoCDSvc.SyncApplication(c.Request.Context(), uint(clusterID), appName, req.Revision); err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -313,14 +314,14 @@ func (h *ArgoCDHandler) SyncApplication(c *gin.Context) {
 func (h *ArgoCDHandler) DeleteApplication(c *gin.Context) {
 	clusterID, err := strconv.ParseUint(c.Param("clusterID"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	appName := c.Param("appName")
 	cascade := c.Query("cascade") != "false"
 
 	if err := h.argoCDSvc.DeleteApplication(c.Request.Context(), uint(clusterID), appName, cascade); err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -340,19 +341,19 @@ func (h *ArgoCDHandler) DeleteApplication(c *gin.Context) {
 func (h *ArgoCDHandler) RollbackApplication(c *gin.Context) {
 	clusterID, err := strconv.ParseUint(c.Param("clusterID"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	appName := c.Param("appName")
 
 	var req models.RollbackApplicationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
 	if err := h.argoCDSvc.RollbackApplication(c.Request.Context(), uint(clusterID), appName, req.RevisionID); err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -370,14 +371,14 @@ func (h *ArgoCDHandler) RollbackApplication(c *gin.Context) {
 func (h *ArgoCDHandler) GetApplicationResources(c *gin.Context) {
 	clusterID, err := strconv.ParseUint(c.Param("clusterID"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	appName := c.Param("appName")
 
 	resources, err := h.argoCDSvc.GetApplicationResources(c.Request.Context(), uint(clusterID), appName)
 	if err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
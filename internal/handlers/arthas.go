@@ -14,6 +14,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
@@ -96,7 +97,7 @@ type arthasWSEvent struct {
 // GetStatus 探测目标 Pod/容器内 Java 进程和 Arthas 可用性。
 func (h *ArthasHandler) GetStatus(c *gin.Context) {
 	if !h.cfg.Arthas.Enabled {
-		response.ServiceUnavailable(c, "Arthas Agent 未启用")
+		response.FailCode(c, errcode.ArthasAgentDisabled)
 		return
 	}
 
@@ -108,7 +109,7 @@ func (h *ArthasHandler) GetStatus(c *gin.Context) {
 	status, err := service.GetStatus(c.Request.Context(), c.Param("namespace"), c.Param("name"), container)
 	if err != nil {
 		logger.Error("获取 Arthas 状态失败", "cluster", cluster.ID, "error", err)
-		response.InternalError(c, fmt.Sprintf("获取 Arthas 状态失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ArthasStatusFailed))
 		return
 	}
 	response.OK(c, status)
@@ -117,7 +118,7 @@ func (h *ArthasHandler) GetStatus(c *gin.Context) {
 // CreateSession 返回创建诊断会话所需的目标信息。真正的流式执行在 WebSocket 中完成。
 func (h *ArthasHandler) CreateSession(c *gin.Context) {
 	if !h.cfg.Arthas.Enabled {
-		response.ServiceUnavailable(c, "Arthas Agent 未启用")
+		response.FailCode(c, errcode.ArthasAgentDisabled)
 		return
 	}
 	var req arthasSessionRequest
@@ -129,7 +130,7 @@ func (h *ArthasHandler) CreateSession(c *gin.Context) {
 	}
 	status, err := service.GetStatus(c.Request.Context(), c.Param("namespace"), c.Param("name"), container)
 	if err != nil {
-		response.InternalError(c, fmt.Sprintf("创建 Arthas 会话失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ArthasSessionFailed))
 		return
 	}
 	if req.PID == "" && len(status.Processes) > 0 {
@@ -146,13 +147,13 @@ func (h *ArthasHandler) CreateSession(c *gin.Context) {
 func (h *ArthasHandler) BuildPlan(c *gin.Context) {
 	var req arthasPlanRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "诊断问题不能为空")
+		response.FailCode(c, errcode.DiagnosisQuestionRequired)
 		return
 	}
 	agent := services.NewArthasAgentServiceWithPolicy(h.aiConfigSvc, h.newPolicy())
 	plan, err := agent.BuildPlan(c.Request.Context(), req.Prompt, req.Evidence)
 	if err != nil {
-		response.InternalError(c, fmt.Sprintf("生成 Arthas 诊断计划失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ArthasPlanFailed))
 		return
 	}
 	response.OK(c, plan)
@@ -162,7 +163,7 @@ func (h *ArthasHandler) BuildPlan(c *gin.Context) {
 func (h *ArthasHandler) ConfirmCommand(c *gin.Context) {
 	var req arthasConfirmRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "container、pid 和 command 不能为空")
+		response.FailCode(c, errcode.ArthasParamsRequired)
 		return
 	}
 
@@ -180,7 +181,7 @@ func (h *ArthasHandler) ConfirmCommand(c *gin.Context) {
 		Command:   req.Command,
 	}, decision)
 	if err != nil {
-		response.InternalError(c, fmt.Sprintf("执行 Arthas 命令失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ArthasCommandFailed))
 		return
 	}
 	response.OK(c, gin.H{"result": result, "decision": decision})
@@ -189,7 +190,7 @@ func (h *ArthasHandler) ConfirmCommand(c *gin.Context) {
 // HandleWebSocket 处理 Arthas Agent 流式诊断。
 func (h *ArthasHandler) HandleWebSocket(c *gin.Context) {
 	if !h.cfg.Arthas.Enabled {
-		response.ServiceUnavailable(c, "Arthas Agent 未启用")
+		response.FailCode(c, errcode.ArthasAgentDisabled)
 		return
 	}
 
@@ -433,24 +434,24 @@ func (h *ArthasHandler) prepareSessionService(c *gin.Context) (*models.Cluster,
 func (h *ArthasHandler) prepareSessionServiceWithContainer(c *gin.Context, container string) (*models.Cluster, *services.ArthasSessionService, string, bool) {
 	clusterID, err := strconv.ParseUint(c.Param("clusterID"), 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return nil, nil, "", false
 	}
 	cluster, err := h.clusterService.GetCluster(uint(clusterID))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return nil, nil, "", false
 	}
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, fmt.Sprintf("连接集群失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ClusterConnectFailed))
 		return nil, nil, "", false
 	}
 	if container == "" {
 		container = h.defaultContainer(c.Request.Context(), k8sClient, c.Param("namespace"), c.Param("name"))
 	}
 	if container == "" {
-		response.BadRequest(c, "未找到可用容器")
+		response.FailCode(c, errcode.NoContainerAvailable)
 		return nil, nil, "", false
 	}
 	executor := services.NewK8sPodCommandExecutor(k8sClient.GetClientset(), k8sClient.GetRestConfig())
@@ -2,9 +2,8 @@ package handlers
 
 import (
 	"compress/gzip"
+	"errors"
 	"io"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -13,23 +12,29 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/internal/terminalreplay"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+	"github.com/clay-wangzhi/KubePolaris/pkg/objectstore"
 )
 
 // AuditHandler 审计处理器
 type AuditHandler struct {
-	db           *gorm.DB
-	cfg          *config.Config
-	auditService *services.AuditService
+	db            *gorm.DB
+	cfg           *config.Config
+	auditService  *services.AuditService
+	replayStorage *terminalreplay.Storage
 }
 
 // NewAuditHandler 创建审计处理器
-func NewAuditHandler(db *gorm.DB, cfg *config.Config) *AuditHandler {
+func NewAuditHandler(db *gorm.DB, cfg *config.Config, replayStorage *terminalreplay.Storage) *AuditHandler {
 	return &AuditHandler{
-		db:           db,
-		cfg:          cfg,
-		auditService: services.NewAuditService(db),
+		db:            db,
+		cfg:           cfg,
+		auditService:  services.NewAuditService(db, nil, nil),
+		replayStorage: replayStorage,
 	}
 }
 
@@ -86,7 +91,7 @@ func (h *AuditHandler) GetTerminalSessions(c *gin.Context) {
 
 	resp, err := h.auditService.GetSessions(req)
 	if err != nil {
-		response.InternalError(c, "获取会话列表失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.SessionListFailed))
 		return
 	}
 
@@ -98,63 +103,153 @@ func (h *AuditHandler) GetTerminalSessionReplay(c *gin.Context) {
 	sessionIDStr := c.Param("sessionId")
 	sessionID, err := strconv.ParseUint(sessionIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的会话ID")
+		response.FailCode(c, errcode.InvalidSessionID)
 		return
 	}
 
 	detail, err := h.auditService.GetSessionDetail(uint(sessionID))
 	if err != nil {
-		response.NotFound(c, "会话不存在")
+		response.FailCode(c, errcode.SessionNotFound)
 		return
 	}
 	if detail.ReplayPath == "" || detail.ReplaySize <= 0 {
-		response.NotFound(c, "该会话无可用录像")
+		response.FailCode(c, errcode.RecordingUnavailable)
 		return
 	}
 
-	root := filepath.Clean(h.cfg.Terminal.ReplayDir)
-	if strings.Contains(detail.ReplayPath, "..") || filepath.IsAbs(detail.ReplayPath) {
-		response.BadRequest(c, "无效的录像路径")
-		return
-	}
-	full := filepath.Clean(filepath.Join(root, filepath.FromSlash(detail.ReplayPath)))
-	if rel, err := filepath.Rel(root, full); err != nil || strings.HasPrefix(rel, "..") {
-		response.BadRequest(c, "无效的录像路径")
-		return
-	}
-
-	f, err := os.Open(full)
-	if err != nil {
-		response.NotFound(c, "录像文件不存在")
+	f, ok := h.openReplay(c, detail)
+	if !ok {
 		return
 	}
 	defer f.Close()
 
 	gzr, err := gzip.NewReader(f)
 	if err != nil {
-		response.InternalError(c, "读取录像失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.RecordingReadFailed))
 		return
 	}
 	defer gzr.Close()
 
 	c.Header("Content-Type", "application/x-asciicast")
+	if detail.ReplaySHA256 != "" {
+		c.Header("X-Replay-SHA256", detail.ReplaySHA256)
+	}
 	c.Header("Cache-Control", "private, max-age=3600")
 	c.Status(200)
 	_, _ = io.Copy(c.Writer, gzr)
 }
 
+// openReplay 从录像存储读取会话录像并校验大小与 SHA-256，失败时已写入响应
+func (h *AuditHandler) openReplay(c *gin.Context, detail *services.SessionDetailResponse) (io.ReadCloser, bool) {
+	if h.replayStorage == nil {
+		response.FailCode(c, errcode.RecordingDisabled)
+		return nil, false
+	}
+	f, err := h.replayStorage.Open(c.Request.Context(), detail.ReplayPath, detail.ReplaySize, detail.ReplaySHA256)
+	switch {
+	case err == nil:
+		return f, true
+	case errors.Is(err, objectstore.ErrNotFound):
+		response.FailCode(c, errcode.RecordingNotFound)
+	case errors.Is(err, terminalreplay.ErrIntegrity):
+		logger.Warn("终端录像完整性校验失败，可能已被篡改: session=%d, key=%s", detail.ID, detail.ReplayPath)
+		response.FailCode(c, errcode.RecordingTampered)
+	default:
+		response.Fail(c, errcode.Wrap(err, errcode.RecordingReadFailed))
+	}
+	return nil, false
+}
+
+// SearchTerminalReplays 按命令或输出检索终端录像
+// 查询参数 scope: command（默认）、output、all；结果中的 offset 为回放跳转位置（秒）
+func (h *AuditHandler) SearchTerminalReplays(c *gin.Context) {
+	keyword := strings.TrimSpace(c.Query("q"))
+	if keyword == "" {
+		response.FailCode(c, errcode.RecordingKeywordRequired)
+		return
+	}
+	scope := c.DefaultQuery("scope", "command")
+	if scope != "command" && scope != "output" && scope != "all" {
+		response.FailCode(c, errcode.InvalidRecordingScope)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	req := &services.ReplaySearchRequest{
+		Keyword:  keyword,
+		Scope:    scope,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if uid, err := strconv.ParseUint(c.Query("userId"), 10, 32); err == nil {
+		req.UserID = uint(uid)
+	}
+	if cid, err := strconv.ParseUint(c.Query("clusterId"), 10, 32); err == nil {
+		req.ClusterID = uint(cid)
+	}
+	if t, err := time.Parse(time.RFC3339, c.Query("startTime")); err == nil {
+		req.StartTime = &t
+	}
+	if t, err := time.Parse(time.RFC3339, c.Query("endTime")); err == nil {
+		req.EndTime = &t
+	}
+
+	items, total, err := h.auditService.SearchReplayIndex(req)
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.RecordingSearchFailed))
+		return
+	}
+	response.PagedList(c, items, total, req.Page, req.PageSize)
+}
+
+// ReindexTerminalSession 重建会话录像的命令索引
+func (h *AuditHandler) ReindexTerminalSession(c *gin.Context) {
+	sessionID, err := strconv.ParseUint(c.Param("sessionId"), 10, 32)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidSessionID)
+		return
+	}
+
+	detail, err := h.auditService.GetSessionDetail(uint(sessionID))
+	if err != nil {
+		response.FailCode(c, errcode.SessionNotFound)
+		return
+	}
+	if detail.ReplayPath == "" || detail.ReplaySize <= 0 {
+		response.FailCode(c, errcode.RecordingUnavailable)
+		return
+	}
+	f, ok := h.openReplay(c, detail)
+	if !ok {
+		return
+	}
+	defer f.Close()
+
+	entries, err := terminalreplay.IndexGzip(f)
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.RecordingParseFailed))
+		return
+	}
+	if err := h.auditService.SaveReplayIndex(uint(sessionID), entries); err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.RecordingIndexFailed))
+		return
+	}
+	response.OK(c, gin.H{"commands": len(entries)})
+}
+
 // GetTerminalSession 获取终端会话详情
 func (h *AuditHandler) GetTerminalSession(c *gin.Context) {
 	sessionIDStr := c.Param("sessionId")
 	sessionID, err := strconv.ParseUint(sessionIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的会话ID")
+		response.FailCode(c, errcode.InvalidSessionID)
 		return
 	}
 
 	session, err := h.auditService.GetSessionDetail(uint(sessionID))
 	if err != nil {
-		response.NotFound(c, "会话不存在")
+		response.FailCode(c, errcode.SessionNotFound)
 		return
 	}
 
@@ -166,7 +261,7 @@ func (h *AuditHandler) GetTerminalCommands(c *gin.Context) {
 	sessionIDStr := c.Param("sessionId")
 	sessionID, err := strconv.ParseUint(sessionIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的会话ID")
+		response.FailCode(c, errcode.InvalidSessionID)
 		return
 	}
 
@@ -175,7 +270,7 @@ func (h *AuditHandler) GetTerminalCommands(c *gin.Context) {
 
 	resp, err := h.auditService.GetSessionCommands(uint(sessionID), page, pageSize)
 	if err != nil {
-		response.InternalError(c, "获取命令记录失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.CommandListFailed))
 		return
 	}
 
@@ -186,7 +281,7 @@ func (h *AuditHandler) GetTerminalCommands(c *gin.Context) {
 func (h *AuditHandler) GetTerminalStats(c *gin.Context) {
 	stats, err := h.auditService.GetSessionStats()
 	if err != nil {
-		response.InternalError(c, "获取统计信息失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AuditStatsFailed))
 		return
 	}
 
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+// AuditChainHandler 审计哈希链处理器
+type AuditChainHandler struct {
+	chainSvc *services.AuditChainService
+}
+
+// NewAuditChainHandler 创建审计哈希链处理器
+func NewAuditChainHandler(chainSvc *services.AuditChainService) *AuditChainHandler {
+	return &AuditChainHandler{chainSvc: chainSvc}
+}
+
+// VerifyChain 逐条校验审计哈希链，报告每条链的第一处断裂
+func (h *AuditChainHandler) VerifyChain(c *gin.Context) {
+	reports, err := h.chainSvc.Verify(c.Query("stream"))
+	if err != nil {
+		response.Fail(c, errcode.Ensure(err, errcode.AuditChainVerifyFailed))
+		return
+	}
+
+	valid := true
+	for _, r := range reports {
+		valid = valid && r.Valid
+	}
+	response.OK(c, gin.H{"valid": valid, "streams": reports})
+}
+
+// AnchorChain 立即锚定各链链头
+func (h *AuditChainHandler) AnchorChain(c *gin.Context) {
+	if err := h.chainSvc.Anchor(); err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.AuditChainAnchorFailed))
+		return
+	}
+	response.OK(c, nil)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+// AuditRetentionHandler 审计记录保留策略处理器
+type AuditRetentionHandler struct {
+	retentionSvc *services.AuditRetentionService
+}
+
+// NewAuditRetentionHandler 创建审计记录保留策略处理器
+func NewAuditRetentionHandler(retentionSvc *services.AuditRetentionService) *AuditRetentionHandler {
+	return &AuditRetentionHandler{retentionSvc: retentionSvc}
+}
+
+// GetPolicy 获取当前保留策略
+func (h *AuditRetentionHandler) GetPolicy(c *gin.Context) {
+	response.OK(c, h.retentionSvc.Policy())
+}
+
+// ListRuns 获取清理任务列表
+func (h *AuditRetentionHandler) ListRuns(c *gin.Context) {
+	page, pageSize := getIntParam(c, "page", 1), getIntParam(c, "pageSize", 20)
+	runs, total, err := h.retentionSvc.ListRuns(c.Query("stream"), page, pageSize)
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.AuditPurgeRunsFailed))
+		return
+	}
+	response.PagedList(c, runs, total, page, pageSize)
+}
+
+// RunRetention 立即按保留策略执行一次清理
+func (h *AuditRetentionHandler) RunRetention(c *gin.Context) {
+	if !h.retentionSvc.Enabled() {
+		response.FailCode(c, errcode.AuditRetentionUnset)
+		return
+	}
+	runs, err := h.retentionSvc.RunOnce(services.AuditRetentionTriggerManual)
+	purged := int64(0)
+	for _, r := range runs {
+		purged += r.Purged
+	}
+	c.Set(middleware.AuditDetailKey, gin.H{"runs": len(runs), "purged": purged})
+	if err != nil {
+		response.Fail(c, errcode.Ensure(err, errcode.AuditPurgeFailed))
+		return
+	}
+	response.OK(c, runs)
+}
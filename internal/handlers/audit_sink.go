@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+// AuditSinkHandler 审计外送目标处理器
+type AuditSinkHandler struct {
+	exportSvc *services.AuditExportService
+}
+
+// NewAuditSinkHandler 创建审计外送目标处理器
+func NewAuditSinkHandler(exportSvc *services.AuditExportService) *AuditSinkHandler {
+	return &AuditSinkHandler{exportSvc: exportSvc}
+}
+
+// ListSinks 获取外送目标列表
+func (h *AuditSinkHandler) ListSinks(c *gin.Context) {
+	sinks, err := h.exportSvc.ListSinks()
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, sinks)
+}
+
+// CreateSink 创建外送目标
+func (h *AuditSinkHandler) CreateSink(c *gin.Context) {
+	var req services.AuditSinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	sink, err := h.exportSvc.CreateSink(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Created(c, sink)
+}
+
+// GetSink 获取外送目标详情
+func (h *AuditSinkHandler) GetSink(c *gin.Context) {
+	id, ok := parseAuditSinkID(c)
+	if !ok {
+		return
+	}
+
+	sink, err := h.exportSvc.GetSink(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, sink)
+}
+
+// UpdateSink 更新外送目标
+func (h *AuditSinkHandler) UpdateSink(c *gin.Context) {
+	id, ok := parseAuditSinkID(c)
+	if !ok {
+		return
+	}
+
+	var req services.AuditSinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	sink, err := h.exportSvc.UpdateSink(id, &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, sink)
+}
+
+// DeleteSink 删除外送目标
+func (h *AuditSinkHandler) DeleteSink(c *gin.Context) {
+	id, ok := parseAuditSinkID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.exportSvc.DeleteSink(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// TestSink 向外送目标发送测试事件
+func (h *AuditSinkHandler) TestSink(c *gin.Context) {
+	id, ok := parseAuditSinkID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.exportSvc.TestSink(id); err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.AuditSinkTestFailed))
+		return
+	}
+	response.OK(c, nil)
+}
+
+// parseAuditSinkID 解析路径中的目标ID
+func parseAuditSinkID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidAuditSinkID)
+		return 0, false
+	}
+	return uint(id), true
+}
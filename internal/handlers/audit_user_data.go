@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+)
+
+// AuditUserDataHandler 用户审计数据导出与匿名化处理器
+type AuditUserDataHandler struct {
+	userDataSvc *services.AuditUserDataService
+}
+
+// NewAuditUserDataHandler 创建用户审计数据处理器
+func NewAuditUserDataHandler(userDataSvc *services.AuditUserDataService) *AuditUserDataHandler {
+	return &AuditUserDataHandler{userDataSvc: userDataSvc}
+}
+
+func parseAuditUserID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidUserID)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// ExportUserData 以 zip 下载与用户相关的全部审计数据（登录、操作、终端会话与录像等）
+func (h *AuditUserDataHandler) ExportUserData(c *gin.Context) {
+	userID, ok := parseAuditUserID(c)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("user-%d-audit-%s.zip", userID, time.Now().Format("20060102150405"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	manifest, err := h.userDataSvc.Export(context.Background(), userID, c.GetString("username"), c.Writer)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.Header("Content-Type", "")
+		c.Header("Content-Disposition", "")
+		response.FailCode(c, errcode.UserNotFound)
+		return
+	}
+
+	detail := gin.H{"user_id": userID}
+	if manifest != nil {
+		detail["files"] = len(manifest.Files)
+		detail["errors"] = len(manifest.Errors)
+	}
+	if err != nil {
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "")
+			c.Header("Content-Disposition", "")
+			response.Fail(c, errcode.Wrap(err, errcode.UserDataExportFailed))
+		} else {
+			// 响应已开始写出，只能中断连接并在审计中记录未完成
+			logger.Error("导出用户审计数据中断: user=%d, err=%v", userID, err)
+			c.Set("error_message", "导出中断: "+err.Error())
+			c.Abort()
+		}
+		detail["complete"] = false
+	}
+	c.Set(middleware.AuditDetailKey, detail)
+}
+
+// PseudonymizeUser 将已删除用户在历史审计记录中的身份替换为假名
+func (h *AuditUserDataHandler) PseudonymizeUser(c *gin.Context) {
+	userID, ok := parseAuditUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.userDataSvc.Pseudonymize(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.FailCode(c, errcode.UserNotFound)
+		} else {
+			response.Fail(c, errcode.Ensure(err, errcode.UserDataAnonymizeFailed))
+		}
+		return
+	}
+	c.Set(middleware.AuditDetailKey, result)
+	response.OK(c, result)
+}
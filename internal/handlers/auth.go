@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/constants"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
@@ -13,13 +14,15 @@ import (
 type AuthHandler struct {
 	authService *services.AuthService
 	opLogSvc    *services.OperationLogService
+	prefSvc     *services.UserPreferenceService
 }
 
 // NewAuthHandler 创建认证处理器
-func NewAuthHandler(authService *services.AuthService, opLogSvc *services.OperationLogService) *AuthHandler {
+func NewAuthHandler(authService *services.AuthService, opLogSvc *services.OperationLogService, prefSvc *services.UserPreferenceService) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
 		opLogSvc:    opLogSvc,
+		prefSvc:     prefSvc,
 	}
 }
 
@@ -34,7 +37,7 @@ type LoginRequest struct {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
@@ -42,15 +45,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	if err != nil {
 		logger.Warn("用户登录失败: %s, 错误: %v", req.Username, err)
 
-		// 判断错误类型确定状态码
-		statusCode := 401
-		if err.Error() == "用户账号已被禁用" {
-			statusCode = 403
-		} else if err.Error() == "不支持的认证类型" {
-			statusCode = 400
-		} else if err.Error() == "JWT token生成失败" {
-			statusCode = 500
-		}
+		statusCode := errcode.StatusOf(err)
 
 		// 记录登录失败审计日志
 		if h.opLogSvc != nil {
@@ -70,16 +65,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 			})
 		}
 
-		switch statusCode {
-		case 400:
-			response.BadRequest(c, err.Error())
-		case 403:
-			response.Forbidden(c, err.Error())
-		case 500:
-			response.InternalError(c, err.Error())
-		default:
-			response.Unauthorized(c, err.Error())
-		}
+		response.Fail(c, err)
 		return
 	}
 
@@ -141,13 +127,13 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 func (h *AuthHandler) GetProfile(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	if userID == 0 {
-		response.Unauthorized(c, "无效的用户认证信息")
+		response.FailCode(c, errcode.InvalidAuthContext)
 		return
 	}
 
 	user, err := h.authService.GetProfile(userID)
 	if err != nil {
-		response.NotFound(c, "用户不存在")
+		response.FailCode(c, errcode.UserNotFound)
 		return
 	}
 
@@ -178,30 +164,49 @@ type ChangePasswordRequest struct {
 func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	if userID == 0 {
-		response.Unauthorized(c, "无效的用户认证信息")
+		response.FailCode(c, errcode.InvalidAuthContext)
 		return
 	}
 
 	var req ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
 	err := h.authService.ChangePassword(userID, req.OldPassword, req.NewPassword)
 	if err != nil {
-		switch err.Error() {
-		case "用户不存在":
-			response.NotFound(c, err.Error())
-		case "LDAP用户不能在此修改密码":
-			response.Forbidden(c, err.Error())
-		case "原密码错误":
-			response.Unauthorized(c, err.Error())
-		default:
-			response.InternalError(c, err.Error())
-		}
+		response.Fail(c, err)
 		return
 	}
 
 	response.OK(c, nil)
 }
+
+// UpdatePreferencesRequest 更新个人偏好请求
+type UpdatePreferencesRequest struct {
+	Language string `json:"language"` // zh, en，空表示跟随浏览器
+}
+
+// UpdatePreferences 更新个人偏好（语言）
+func (h *AuthHandler) UpdatePreferences(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		response.FailCode(c, errcode.InvalidAuthContext)
+		return
+	}
+
+	var req UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	lang, err := h.prefSvc.UpdateLanguage(userID, req.Language)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.OK(c, gin.H{"language": lang})
+}
@@ -47,8 +47,8 @@ func (s *AuthHandlerTestSuite) SetupTest() {
 	s.mock = mock
 
 	authSvc := services.NewAuthService(gormDB, "test-secret-key-for-unit-tests-only", 24)
-	opLogSvc := services.NewOperationLogService(gormDB)
-	s.handler = NewAuthHandler(authSvc, opLogSvc)
+	opLogSvc := services.NewOperationLogService(gormDB, nil, nil)
+	s.handler = NewAuthHandler(authSvc, opLogSvc, services.NewUserPreferenceService(gormDB))
 
 	s.router = gin.New()
 	s.router.POST("/api/auth/login", s.handler.Login)
@@ -109,6 +109,31 @@ func (s *AuthHandlerTestSuite) TestLogin_UserNotFound() {
 	assert.Equal(s.T(), http.StatusUnauthorized, w.Code)
 }
 
+// TestLogin_LocalizedError 测试错误码与按 Accept-Language 输出的文案
+func (s *AuthHandlerTestSuite) TestLogin_LocalizedError() {
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE username = ?")).
+		WithArgs("nonexistent").
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	body, _ := json.Marshal(map[string]string{"username": "nonexistent", "password": "password123"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/auth/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9,zh-CN;q=0.8")
+	s.router.ServeHTTP(w, req)
+
+	var resp struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(s.T(), http.StatusUnauthorized, w.Code)
+	assert.Equal(s.T(), "INVALID_CREDENTIALS", resp.Error.Code)
+	assert.Equal(s.T(), "Incorrect username or password", resp.Error.Message)
+}
+
 // TestLogin_InvalidJSON 测试无效的 JSON 请求
 func (s *AuthHandlerTestSuite) TestLogin_InvalidJSON() {
 	w := httptest.NewRecorder()
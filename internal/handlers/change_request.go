@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+// ChangeRequestHandler 变更双人审批处理器
+type ChangeRequestHandler struct {
+	changeRequestService *services.ChangeRequestService
+}
+
+// NewChangeRequestHandler 创建变更双人审批处理器
+func NewChangeRequestHandler(changeRequestService *services.ChangeRequestService) *ChangeRequestHandler {
+	return &ChangeRequestHandler{changeRequestService: changeRequestService}
+}
+
+// ListPolicies 获取变更审批策略列表
+func (h *ChangeRequestHandler) ListPolicies(c *gin.Context) {
+	policies, err := h.changeRequestService.ListPolicies()
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, policies)
+}
+
+// GetPolicy 获取变更审批策略详情
+func (h *ChangeRequestHandler) GetPolicy(c *gin.Context) {
+	id, ok := parseChangeRequestID(c, errcode.InvalidPolicyID)
+	if !ok {
+		return
+	}
+	policy, err := h.changeRequestService.GetPolicy(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, policy)
+}
+
+// CreatePolicy 创建变更审批策略
+func (h *ChangeRequestHandler) CreatePolicy(c *gin.Context) {
+	var req services.ChangeApprovalPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+	policy, err := h.changeRequestService.CreatePolicy(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Created(c, policy)
+}
+
+// UpdatePolicy 更新变更审批策略
+func (h *ChangeRequestHandler) UpdatePolicy(c *gin.Context) {
+	id, ok := parseChangeRequestID(c, errcode.InvalidPolicyID)
+	if !ok {
+		return
+	}
+	var req services.ChangeApprovalPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+	policy, err := h.changeRequestService.UpdatePolicy(id, &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, policy)
+}
+
+// DeletePolicy 删除变更审批策略
+func (h *ChangeRequestHandler) DeletePolicy(c *gin.Context) {
+	id, ok := parseChangeRequestID(c, errcode.InvalidPolicyID)
+	if !ok {
+		return
+	}
+	if err := h.changeRequestService.DeletePolicy(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// ListChangeRequests 获取待审批变更列表
+// 返回当前用户提交的以及由其所在用户组审批的变更；mine=true 时只返回自己提交的
+func (h *ChangeRequestHandler) ListChangeRequests(c *gin.Context) {
+	req := &services.ChangeRequestListRequest{
+		ViewerID: c.GetUint("user_id"),
+		Mine:     c.Query("mine") == "true",
+		Status:   c.Query("status"),
+		Page:     getIntParam(c, "page", 1),
+		PageSize: getIntParam(c, "pageSize", 20),
+	}
+	if clusterIDStr := c.Query("clusterId"); clusterIDStr != "" {
+		if cid, err := strconv.ParseUint(clusterIDStr, 10, 32); err == nil {
+			req.ClusterID = uint(cid)
+		}
+	}
+
+	items, total, err := h.changeRequestService.ListRequests(req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.PagedList(c, items, total, req.Page, req.PageSize)
+}
+
+// GetChangeRequest 获取变更详情（含原始请求体与执行结果）
+func (h *ChangeRequestHandler) GetChangeRequest(c *gin.Context) {
+	id, ok := parseChangeRequestID(c, errcode.InvalidChangeRequestID)
+	if !ok {
+		return
+	}
+	change, err := h.changeRequestService.GetRequest(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if !h.changeRequestService.CanView(c.GetUint("user_id"), change) {
+		response.FailCode(c, errcode.ChangeRequestForbidden)
+		return
+	}
+	response.OK(c, change)
+}
+
+// ReviewChangeRequestRequest 审批请求
+type ReviewChangeRequestRequest struct {
+	Comment string `json:"comment"`
+}
+
+// ApproveChangeRequest 批准变更，服务端随后执行原始请求
+func (h *ChangeRequestHandler) ApproveChangeRequest(c *gin.Context) {
+	id, ok := parseChangeRequestID(c, errcode.InvalidChangeRequestID)
+	if !ok {
+		return
+	}
+	var req ReviewChangeRequestRequest
+	_ = c.ShouldBindJSON(&req)
+
+	change, err := h.changeRequestService.Approve(id, c.GetUint("user_id"), c.GetString("username"), req.Comment)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, change)
+}
+
+// RejectChangeRequest 拒绝变更
+func (h *ChangeRequestHandler) RejectChangeRequest(c *gin.Context) {
+	id, ok := parseChangeRequestID(c, errcode.InvalidChangeRequestID)
+	if !ok {
+		return
+	}
+	var req ReviewChangeRequestRequest
+	_ = c.ShouldBindJSON(&req)
+
+	change, err := h.changeRequestService.Reject(id, c.GetUint("user_id"), c.GetString("username"), req.Comment)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, change)
+}
+
+// CancelChangeRequest 撤回待审批的变更
+func (h *ChangeRequestHandler) CancelChangeRequest(c *gin.Context) {
+	id, ok := parseChangeRequestID(c, errcode.InvalidChangeRequestID)
+	if !ok {
+		return
+	}
+	if err := h.changeRequestService.Cancel(id, c.GetUint("user_id")); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+func parseChangeRequestID(c *gin.Context, code errcode.Code) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.FailCode(c, code)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -13,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
@@ -29,6 +31,7 @@ type ClusterHandler struct {
 	promService      *services.PrometheusService
 	monitoringCfgSvc *services.MonitoringConfigService
 	permissionSvc    *services.PermissionService
+	rbacSvc          *services.RBACService
 }
 
 // NewClusterHandler 创建集群处理器
@@ -41,6 +44,7 @@ func NewClusterHandler(db *gorm.DB, cfg *config.Config, mgr *k8s.ClusterInformer
 		promService:      promService,
 		monitoringCfgSvc: monitoringCfgSvc,
 		permissionSvc:    permSvc,
+		rbacSvc:          services.NewRBACService(),
 	}
 }
 
@@ -48,13 +52,21 @@ func NewClusterHandler(db *gorm.DB, cfg *config.Config, mgr *k8s.ClusterInformer
 func (h *ClusterHandler) GetClusters(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
+	// 集群标签过滤条件（labelSelector=env=prod,region=eu 或 clusterGroupId）
+	selector, err := services.ResolveClusterLabelFilter(h.db, c.Query("labelSelector"), c.Query("clusterGroupId"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
 	// 获取用户可访问的集群
 	clusters, err := h.getAccessibleClusters(userID)
 	if err != nil {
 		logger.Error("获取集群列表失败", "error", err)
-		response.InternalError(c, "获取集群列表失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ClusterListFailed))
 		return
 	}
+	clusters = services.FilterClustersByLabels(clusters, selector)
 
 	// 转换为响应格式
 	clusterList := make([]gin.H, 0, len(clusters))
@@ -65,6 +77,7 @@ func (h *ClusterHandler) GetClusters(c *gin.Context) {
 			"apiServer": cluster.APIServer,
 			"version":   cluster.Version,
 			"status":    cluster.Status,
+			"labels":    cluster.GetLabels(),
 			"createdAt": cluster.CreatedAt.Format("2006-01-02T15:04:05Z"),
 			"updatedAt": cluster.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 		}
@@ -100,16 +113,17 @@ func (h *ClusterHandler) ImportCluster(c *gin.Context) {
 
 	// 获取请求参数
 	var req struct {
-		Name        string `json:"name" binding:"required"`
-		Description string `json:"description"`
-		ApiServer   string `json:"apiServer"`
-		Kubeconfig  string `json:"kubeconfig"`
-		Token       string `json:"token"`
-		CaCert      string `json:"caCert"`
+		Name        string            `json:"name" binding:"required"`
+		Description string            `json:"description"`
+		ApiServer   string            `json:"apiServer"`
+		Kubeconfig  string            `json:"kubeconfig"`
+		Token       string            `json:"token"`
+		CaCert      string            `json:"caCert"`
+		Labels      map[string]string `json:"labels"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -117,7 +131,7 @@ func (h *ClusterHandler) ImportCluster(c *gin.Context) {
 
 	// 验证参数
 	if req.Kubeconfig == "" && (req.ApiServer == "" || req.Token == "") {
-		response.BadRequest(c, "请提供kubeconfig或者API Server地址和访问令牌")
+		response.FailCode(c, errcode.ClusterConnectionRequired)
 		return
 	}
 
@@ -129,14 +143,14 @@ func (h *ClusterHandler) ImportCluster(c *gin.Context) {
 		k8sClient, err = services.NewK8sClientFromKubeconfig(req.Kubeconfig)
 		if err != nil {
 			logger.Error("从kubeconfig创建客户端失败", "error", err)
-			response.BadRequest(c, fmt.Sprintf("kubeconfig格式错误: %v", err))
+			response.Fail(c, errcode.Wrap(err, errcode.InvalidKubeconfig))
 			return
 		}
 	} else {
 		k8sClient, err = services.NewK8sClientFromToken(req.ApiServer, req.Token, req.CaCert)
 		if err != nil {
 			logger.Error("从Token创建客户端失败", "error", err)
-			response.BadRequest(c, fmt.Sprintf("连接配置错误: %v", err))
+			response.Fail(c, errcode.Wrap(err, errcode.InvalidConnectionConfig))
 			return
 		}
 	}
@@ -145,7 +159,7 @@ func (h *ClusterHandler) ImportCluster(c *gin.Context) {
 	clusterInfo, err := k8sClient.TestConnection()
 	if err != nil {
 		logger.Error("连接测试失败", "error", err)
-		response.BadRequest(c, fmt.Sprintf("连接测试失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ConnectionTestFailed))
 		return
 	}
 
@@ -160,6 +174,12 @@ func (h *ClusterHandler) ImportCluster(c *gin.Context) {
 		}
 	}
 
+	clusterLabels := "{}"
+	if len(req.Labels) > 0 {
+		labelsJSON, _ := json.Marshal(req.Labels)
+		clusterLabels = string(labelsJSON)
+	}
+
 	// 创建集群模型
 	cluster := &models.Cluster{
 		Name:               req.Name,
@@ -169,7 +189,7 @@ func (h *ClusterHandler) ImportCluster(c *gin.Context) {
 		CAEnc:              req.CaCert,     // TODO: 需要加密存储
 		Version:            clusterInfo.Version,
 		Status:             clusterInfo.Status,
-		Labels:             "{}",
+		Labels:             clusterLabels,
 		MonitoringConfig:   "{}", // 初始化为空 JSON 对象，避免 MySQL JSON 字段报错
 		AlertManagerConfig: "{}", // 初始化为空 JSON 对象，避免 MySQL JSON 字段报错
 		CreatedBy:          1,    // 临时设置为1，后续需要从JWT中获取用户ID
@@ -179,10 +199,13 @@ func (h *ClusterHandler) ImportCluster(c *gin.Context) {
 	err = h.clusterService.CreateCluster(cluster)
 	if err != nil {
 		logger.Error("保存集群信息失败", "error", err)
-		response.InternalError(c, "保存集群信息失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ClusterSaveFailed))
 		return
 	}
 
+	// 新集群自动继承匹配的标签选择器授权，同步对应的 RBAC
+	go h.permissionSvc.ReconcileSelectorRBAC(h.rbacSvc, cluster.ID, nil, req.Labels)
+
 	// 返回新创建的集群信息
 	newCluster := gin.H{
 		"id":        cluster.ID,
@@ -201,13 +224,13 @@ func (h *ClusterHandler) GetCluster(c *gin.Context) {
 	idStr := c.Param("clusterID")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterService.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -217,6 +240,7 @@ func (h *ClusterHandler) GetCluster(c *gin.Context) {
 		"apiServer": cluster.APIServer,
 		"version":   cluster.Version,
 		"status":    cluster.Status,
+		"labels":    cluster.GetLabels(),
 		"createdAt": cluster.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		"updatedAt": cluster.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 	}
@@ -228,12 +252,79 @@ func (h *ClusterHandler) GetCluster(c *gin.Context) {
 	response.OK(c, clusterData)
 }
 
+// UpdateClusterLabels 更新集群标签
+// 标签用于集群分组、按标签选择器授权以及列表过滤
+func (h *ClusterHandler) UpdateClusterLabels(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("clusterID"), 10, 32)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidClusterID)
+		return
+	}
+
+	var req struct {
+		Labels map[string]string `json:"labels"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
+		return
+	}
+	for k := range req.Labels {
+		if strings.TrimSpace(k) == "" || strings.ContainsAny(k, ",=") {
+			response.FailCode(c, errcode.InvalidLabelKey, k)
+			return
+		}
+	}
+
+	cluster, err := h.clusterService.GetCluster(uint(id))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := h.clusterService.UpdateClusterLabels(uint(id), req.Labels); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	// 标签变化后，标签选择器授权的匹配结果随之变化，同步对应的 RBAC
+	go h.permissionSvc.ReconcileSelectorRBAC(h.rbacSvc, cluster.ID, cluster.GetLabels(), req.Labels)
+
+	response.OK(c, gin.H{"labels": req.Labels})
+}
+
+// UpdateNodeTerminalMode 设置集群的节点终端方式（ssh / pod）
+func (h *ClusterHandler) UpdateNodeTerminalMode(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("clusterID"), 10, 32)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidClusterID)
+		return
+	}
+
+	var req struct {
+		Mode string `json:"mode" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
+		return
+	}
+	if req.Mode != models.NodeTerminalModeSSH && req.Mode != models.NodeTerminalModePod {
+		response.FailCode(c, errcode.UnsupportedNodeTerminalMode, req.Mode)
+		return
+	}
+
+	if err := h.clusterService.UpdateNodeTerminalMode(uint(id), req.Mode); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.OK(c, gin.H{"node_terminal_mode": req.Mode})
+}
+
 // DeleteCluster 删除集群
 func (h *ClusterHandler) DeleteCluster(c *gin.Context) {
 	idStr := c.Param("clusterID")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -246,12 +337,7 @@ func (h *ClusterHandler) DeleteCluster(c *gin.Context) {
 
 	err = h.clusterService.DeleteCluster(clusterID)
 	if err != nil {
-		// 检查是否是集群不存在的错误
-		if strings.Contains(err.Error(), "集群不存在") {
-			response.NotFound(c, err.Error())
-			return
-		}
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -265,7 +351,7 @@ func (h *ClusterHandler) GetClusterStats(c *gin.Context) {
 	stats, err := h.clusterService.GetClusterStats()
 	if err != nil {
 		logger.Error("获取集群统计失败", "error", err)
-		response.InternalError(c, "获取集群统计失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ClusterStatsFailed))
 		return
 	}
 
@@ -277,13 +363,13 @@ func (h *ClusterHandler) GetClusterStatus(c *gin.Context) {
 	idStr := c.Param("clusterID")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterService.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -307,14 +393,14 @@ func (h *ClusterHandler) GetClusterOverview(c *gin.Context) {
 	clusterID := c.Param("clusterID")
 	id, err := strconv.ParseUint(clusterID, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	// 获取集群信息
 	cluster, err := h.clusterService.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -342,7 +428,7 @@ func (h *ClusterHandler) GetClusterOverview(c *gin.Context) {
 	}
 
 	// 如果 informer 方式失败，返回错误
-	response.ServiceUnavailable(c, "集群信息获取失败")
+	response.FailCode(c, errcode.ClusterInfoUnavailable)
 }
 
 // getContainerSubnetIPs 获取容器子网IP信息
@@ -374,13 +460,13 @@ func (h *ClusterHandler) GetClusterEvents(c *gin.Context) {
 	idStr := c.Param("clusterID")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterService.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -388,7 +474,7 @@ func (h *ClusterHandler) GetClusterEvents(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err)
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -398,7 +484,7 @@ func (h *ClusterHandler) GetClusterEvents(c *gin.Context) {
 	evList, err := cs.CoreV1().Events("").List(c.Request.Context(), metav1.ListOptions{})
 	if err != nil {
 		logger.Error("获取K8s事件失败", "error", err)
-		response.InternalError(c, "获取K8s事件失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Event"))
 		return
 	}
 
@@ -491,14 +577,14 @@ func (h *ClusterHandler) GetClusterMetrics(c *gin.Context) {
 	// 从数据库获取集群
 	clusterID, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterService.GetCluster(uint(clusterID))
 	if err != nil {
 		logger.Error("获取集群失败", "error", err)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -506,7 +592,7 @@ func (h *ClusterHandler) GetClusterMetrics(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err)
-		response.InternalError(c, "获取集群监控数据失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ClusterMetricsFailed))
 		return
 	}
 
@@ -514,7 +600,7 @@ func (h *ClusterHandler) GetClusterMetrics(c *gin.Context) {
 	metrics, err := k8sClient.GetClusterMetrics(rangeParam, step)
 	if err != nil {
 		logger.Error("获取集群监控数据失败", "error", err)
-		response.InternalError(c, "获取集群监控数据失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ClusterMetricsFailed))
 		return
 	}
 
@@ -535,7 +621,7 @@ func (h *ClusterHandler) TestConnection(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.Error("参数绑定错误: %v", err)
-		response.BadRequest(c, "请求参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -543,7 +629,7 @@ func (h *ClusterHandler) TestConnection(c *gin.Context) {
 
 	// 验证参数
 	if req.Kubeconfig == "" && (req.ApiServer == "" || req.Token == "") {
-		response.BadRequest(c, "请提供kubeconfig或者API Server地址和访问令牌")
+		response.FailCode(c, errcode.ClusterConnectionRequired)
 		return
 	}
 
@@ -556,7 +642,7 @@ func (h *ClusterHandler) TestConnection(c *gin.Context) {
 		k8sClient, err = services.NewK8sClientFromKubeconfig(req.Kubeconfig)
 		if err != nil {
 			logger.Error("从kubeconfig创建客户端失败", "error", err)
-			response.BadRequest(c, fmt.Sprintf("kubeconfig格式错误: %v", err))
+			response.Fail(c, errcode.Wrap(err, errcode.InvalidKubeconfig))
 			return
 		}
 	} else {
@@ -564,7 +650,7 @@ func (h *ClusterHandler) TestConnection(c *gin.Context) {
 		k8sClient, err = services.NewK8sClientFromToken(req.ApiServer, req.Token, req.CaCert)
 		if err != nil {
 			logger.Error("从Token创建客户端失败", "error", err)
-			response.BadRequest(c, fmt.Sprintf("连接配置错误: %v", err))
+			response.Fail(c, errcode.Wrap(err, errcode.InvalidConnectionConfig))
 			return
 		}
 	}
@@ -573,7 +659,7 @@ func (h *ClusterHandler) TestConnection(c *gin.Context) {
 	clusterInfo, err := k8sClient.TestConnection()
 	if err != nil {
 		logger.Error("连接测试失败", "error", err)
-		response.BadRequest(c, fmt.Sprintf("连接测试失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ConnectionTestFailed))
 		return
 	}
 
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+// ClusterGroupHandler 集群分组处理器
+type ClusterGroupHandler struct {
+	clusterGroupService *services.ClusterGroupService
+}
+
+// NewClusterGroupHandler 创建集群分组处理器
+func NewClusterGroupHandler(clusterGroupService *services.ClusterGroupService) *ClusterGroupHandler {
+	return &ClusterGroupHandler{
+		clusterGroupService: clusterGroupService,
+	}
+}
+
+// ListClusterGroups 获取集群分组列表
+func (h *ClusterGroupHandler) ListClusterGroups(c *gin.Context) {
+	groups, err := h.clusterGroupService.ListGroups()
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, groups)
+}
+
+// GetClusterGroup 获取集群分组详情（包含当前匹配的集群）
+func (h *ClusterGroupHandler) GetClusterGroup(c *gin.Context) {
+	id, ok := parseClusterGroupID(c)
+	if !ok {
+		return
+	}
+
+	group, err := h.clusterGroupService.GetGroupDetail(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, group)
+}
+
+// CreateClusterGroup 创建集群分组
+func (h *ClusterGroupHandler) CreateClusterGroup(c *gin.Context) {
+	var req services.ClusterGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	group, err := h.clusterGroupService.CreateGroup(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Created(c, group)
+}
+
+// UpdateClusterGroup 更新集群分组
+func (h *ClusterGroupHandler) UpdateClusterGroup(c *gin.Context) {
+	id, ok := parseClusterGroupID(c)
+	if !ok {
+		return
+	}
+
+	var req services.ClusterGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	group, err := h.clusterGroupService.UpdateGroup(id, &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, group)
+}
+
+// DeleteClusterGroup 删除集群分组
+func (h *ClusterGroupHandler) DeleteClusterGroup(c *gin.Context) {
+	id, ok := parseClusterGroupID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.clusterGroupService.DeleteGroup(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// parseClusterGroupID 解析路径中的集群分组ID
+func parseClusterGroupID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidClusterGroupID)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -1,8 +1,9 @@
 package handlers
 
 import (
-	"fmt"
 	"strconv"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 )
 
 // ScaleRequest 扩缩容请求
@@ -20,7 +21,7 @@ type YAMLApplyRequest struct {
 func parseClusterID(clusterIDStr string) (uint, error) {
 	id, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		return 0, fmt.Errorf("无效的集群ID: %s", clusterIDStr)
+		return 0, errcode.New(errcode.InvalidClusterID)
 	}
 	return uint(id), nil
 }
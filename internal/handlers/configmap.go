@@ -14,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
@@ -80,13 +81,13 @@ func (h *ConfigMapHandler) GetConfigMaps(c *gin.Context) {
 	// 获取集群
 	id, err := strconv.ParseUint(clusterID, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterSvc.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -95,14 +96,14 @@ func (h *ConfigMapHandler) GetConfigMaps(c *gin.Context) {
 	defer cancel()
 
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 
 	// 检查命名空间权限
 	nsInfo, hasAccess := middleware.CheckNamespacePermission(c, namespace)
 	if !hasAccess {
-		response.Forbidden(c, fmt.Sprintf("无权访问命名空间: %s", namespace))
+		response.FailCode(c, errcode.NamespaceAccessDenied)
 		return
 	}
 
@@ -115,7 +116,7 @@ func (h *ConfigMapHandler) GetConfigMaps(c *gin.Context) {
 		cms, err := h.k8sMgr.ConfigMapsLister(cluster.ID).ConfigMaps(namespace).List(sel)
 		if err != nil {
 			logger.Error("读取ConfigMap缓存失败", "cluster", cluster.Name, "namespace", namespace, "error", err)
-			response.InternalError(c, fmt.Sprintf("获取ConfigMap列表失败: %v", err))
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "ConfigMap"))
 			return
 		}
 		// 转换为 []corev1.ConfigMap
@@ -127,7 +128,7 @@ func (h *ConfigMapHandler) GetConfigMaps(c *gin.Context) {
 		cms, err := h.k8sMgr.ConfigMapsLister(cluster.ID).List(sel)
 		if err != nil {
 			logger.Error("读取ConfigMap缓存失败", "cluster", cluster.Name, "error", err)
-			response.InternalError(c, fmt.Sprintf("获取ConfigMap列表失败: %v", err))
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "ConfigMap"))
 			return
 		}
 		// 转换为 []corev1.ConfigMap
@@ -187,20 +188,20 @@ func (h *ConfigMapHandler) GetConfigMap(c *gin.Context) {
 	// 获取集群
 	id, err := strconv.ParseUint(clusterID, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterSvc.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -210,7 +211,7 @@ func (h *ConfigMapHandler) GetConfigMap(c *gin.Context) {
 	cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		logger.Error("获取ConfigMap失败", "cluster", cluster.Name, "namespace", namespace, "name", name, "error", err)
-		response.NotFound(c, fmt.Sprintf("ConfigMap不存在: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "ConfigMap"))
 		return
 	}
 
@@ -236,20 +237,20 @@ func (h *ConfigMapHandler) GetConfigMapNamespaces(c *gin.Context) {
 	// 获取集群
 	id, err := strconv.ParseUint(clusterID, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterSvc.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -259,7 +260,7 @@ func (h *ConfigMapHandler) GetConfigMapNamespaces(c *gin.Context) {
 	configMaps, err := clientset.CoreV1().ConfigMaps("").List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		logger.Error("获取ConfigMap列表失败", "cluster", cluster.Name, "error", err)
-		response.InternalError(c, fmt.Sprintf("获取ConfigMap列表失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "ConfigMap"))
 		return
 	}
 
@@ -294,32 +295,34 @@ func (h *ConfigMapHandler) DeleteConfigMap(c *gin.Context) {
 	// 获取集群
 	id, err := strconv.ParseUint(clusterID, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterSvc.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
 	clientset := k8sClient.GetClientset()
 
+	snap := beginSnapshot(c, k8sClient, gvrConfigMaps, namespace, name)
 	// 删除ConfigMap
 	err = clientset.CoreV1().ConfigMaps(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
 	if err != nil {
 		logger.Error("删除ConfigMap失败", "cluster", cluster.Name, "namespace", namespace, "name", name, "error", err)
-		response.InternalError(c, fmt.Sprintf("删除ConfigMap失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceDeleteFailed, "ConfigMap"))
 		return
 	}
+	snap.Deleted()
 
 	response.NoContent(c)
 }
@@ -337,27 +340,27 @@ func (h *ConfigMapHandler) CreateConfigMap(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, fmt.Sprintf("请求参数错误: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	// 获取集群
 	id, err := strconv.ParseUint(clusterID, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterSvc.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -374,12 +377,14 @@ func (h *ConfigMapHandler) CreateConfigMap(c *gin.Context) {
 		Data: req.Data,
 	}
 
+	snap := beginSnapshot(c, k8sClient, gvrConfigMaps, req.Namespace, req.Name)
 	created, err := clientset.CoreV1().ConfigMaps(req.Namespace).Create(context.Background(), configMap, metav1.CreateOptions{})
 	if err != nil {
 		logger.Error("创建ConfigMap失败", "cluster", cluster.Name, "namespace", req.Namespace, "name", req.Name, "error", err)
-		response.InternalError(c, fmt.Sprintf("创建ConfigMap失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceCreateFailed, "ConfigMap"))
 		return
 	}
+	snap.Done()
 
 	response.OK(c, gin.H{
 		"name":      created.Name,
@@ -400,20 +405,20 @@ func (h *ConfigMapHandler) UpdateConfigMap(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, fmt.Sprintf("请求参数错误: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	// 获取集群
 	id, err := strconv.ParseUint(clusterID, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterSvc.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -421,7 +426,7 @@ func (h *ConfigMapHandler) UpdateConfigMap(c *gin.Context) {
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -431,7 +436,7 @@ func (h *ConfigMapHandler) UpdateConfigMap(c *gin.Context) {
 	configMap, err := clientset.CoreV1().ConfigMaps(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		logger.Error("获取ConfigMap失败", "cluster", cluster.Name, "namespace", namespace, "name", name, "error", err)
-		response.NotFound(c, fmt.Sprintf("ConfigMap不存在: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "ConfigMap"))
 		return
 	}
 
@@ -440,12 +445,14 @@ func (h *ConfigMapHandler) UpdateConfigMap(c *gin.Context) {
 	configMap.Annotations = req.Annotations
 	configMap.Data = req.Data
 
+	snap := beginSnapshot(c, k8sClient, gvrConfigMaps, namespace, name)
 	updated, err := clientset.CoreV1().ConfigMaps(namespace).Update(context.Background(), configMap, metav1.UpdateOptions{})
 	if err != nil {
 		logger.Error("更新ConfigMap失败", "cluster", cluster.Name, "namespace", namespace, "name", name, "error", err)
-		response.InternalError(c, fmt.Sprintf("更新ConfigMap失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceUpdateFailed, "ConfigMap"))
 		return
 	}
+	snap.Done()
 
 	response.OK(c, gin.H{
 		"name":            updated.Name,
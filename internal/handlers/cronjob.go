@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
@@ -62,26 +63,26 @@ func (h *CronJobHandler) ListCronJobs(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
 	// 检查命名空间权限
 	nsInfo, hasAccess := middleware.CheckNamespacePermission(c, namespace)
 	if !hasAccess {
-		response.Forbidden(c, fmt.Sprintf("无权访问命名空间: %s", namespace))
+		response.FailCode(c, errcode.NamespaceAccessDenied)
 		return
 	}
 
@@ -97,7 +98,7 @@ func (h *CronJobHandler) ListCronJobs(c *gin.Context) {
 	}
 
 	if err != nil {
-		response.InternalError(c, "获取CronJob列表失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "CronJob"))
 		return
 	}
 
@@ -151,18 +152,18 @@ func (h *CronJobHandler) GetCronJob(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -172,7 +173,7 @@ func (h *CronJobHandler) GetCronJob(c *gin.Context) {
 	clientset := k8sClient.GetClientset()
 	cronJob, err := clientset.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "CronJob不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "CronJob"))
 		return
 	}
 
@@ -210,18 +211,18 @@ func (h *CronJobHandler) GetCronJobNamespaces(c *gin.Context) {
 	clusterId := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -231,7 +232,7 @@ func (h *CronJobHandler) GetCronJobNamespaces(c *gin.Context) {
 	clientset := k8sClient.GetClientset()
 	cronJobList, err := clientset.BatchV1().CronJobs("").List(ctx, metav1.ListOptions{})
 	if err != nil {
-		response.InternalError(c, "获取CronJob列表失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "CronJob"))
 		return
 	}
 
@@ -261,7 +262,7 @@ func (h *CronJobHandler) ApplyYAML(c *gin.Context) {
 	clusterId := c.Param("clusterID")
 	var req YAMLApplyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -269,18 +270,18 @@ func (h *CronJobHandler) ApplyYAML(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -289,24 +290,24 @@ func (h *CronJobHandler) ApplyYAML(c *gin.Context) {
 
 	var objMap map[string]interface{}
 	if err := yaml.Unmarshal([]byte(req.YAML), &objMap); err != nil {
-		response.BadRequest(c, "YAML格式错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InvalidYAML))
 		return
 	}
 
 	if objMap["apiVersion"] == nil || objMap["kind"] == nil {
-		response.BadRequest(c, "YAML缺少必要字段: apiVersion 或 kind")
+		response.FailCode(c, errcode.YAMLMissingKind)
 		return
 	}
 
 	kind := objMap["kind"].(string)
 	if kind != "CronJob" {
-		response.BadRequest(c, "YAML类型错误，期望CronJob，实际为: "+kind)
+		response.FailCode(c, errcode.YAMLKindMismatch, "CronJob", kind)
 		return
 	}
 
 	metadata, ok := objMap["metadata"].(map[string]interface{})
 	if !ok {
-		response.BadRequest(c, "YAML缺少 metadata 字段")
+		response.FailCode(c, errcode.YAMLMissingMetadata)
 		return
 	}
 
@@ -315,11 +316,14 @@ func (h *CronJobHandler) ApplyYAML(c *gin.Context) {
 		namespace = "default"
 	}
 
+	name, _ := metadata["name"].(string)
+	snap := beginApplySnapshot(c, k8sClient, gvrCronJobs, namespace, name, req.DryRun)
 	result, err := h.applyYAML(ctx, k8sClient, req.YAML, namespace, req.DryRun)
 	if err != nil {
-		response.InternalError(c, "YAML应用失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.YAMLApplyFailed))
 		return
 	}
+	snap.Done()
 
 	response.OK(c, result)
 }
@@ -333,18 +337,18 @@ func (h *CronJobHandler) DeleteCronJob(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -352,11 +356,13 @@ func (h *CronJobHandler) DeleteCronJob(c *gin.Context) {
 	defer cancel()
 
 	clientset := k8sClient.GetClientset()
+	snap := beginSnapshot(c, k8sClient, gvrCronJobs, namespace, name)
 	err = clientset.BatchV1().CronJobs(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 	if err != nil {
-		response.InternalError(c, "删除失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.DeleteFailed))
 		return
 	}
+	snap.Deleted()
 
 	response.OK(c, gin.H{"message": "删除成功"})
 }
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
@@ -74,12 +75,12 @@ func (h *DaemonSetHandler) ListDaemonSets(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -87,14 +88,14 @@ func (h *DaemonSetHandler) ListDaemonSets(c *gin.Context) {
 	defer cancel()
 
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 
 	// 检查命名空间权限
 	nsInfo, hasAccess := middleware.CheckNamespacePermission(c, namespace)
 	if !hasAccess {
-		response.Forbidden(c, fmt.Sprintf("无权访问命名空间: %s", namespace))
+		response.FailCode(c, errcode.NamespaceAccessDenied)
 		return
 	}
 
@@ -167,18 +168,18 @@ func (h *DaemonSetHandler) GetDaemonSet(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -188,7 +189,7 @@ func (h *DaemonSetHandler) GetDaemonSet(c *gin.Context) {
 	clientset := k8sClient.GetClientset()
 	daemonSet, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "DaemonSet不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "DaemonSet"))
 		return
 	}
 
@@ -229,12 +230,12 @@ func (h *DaemonSetHandler) GetDaemonSetNamespaces(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -242,14 +243,14 @@ func (h *DaemonSetHandler) GetDaemonSetNamespaces(c *gin.Context) {
 	defer cancel()
 
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 
 	sel := labels.Everything()
 	dss, err := h.k8sMgr.DaemonSetsLister(cluster.ID).List(sel)
 	if err != nil {
-		response.InternalError(c, "读取DaemonSet缓存失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceCacheFailed, "DaemonSet"))
 		return
 	}
 
@@ -284,7 +285,7 @@ func (h *DaemonSetHandler) ApplyYAML(c *gin.Context) {
 
 	var req YAMLApplyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -292,18 +293,18 @@ func (h *DaemonSetHandler) ApplyYAML(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -312,24 +313,24 @@ func (h *DaemonSetHandler) ApplyYAML(c *gin.Context) {
 
 	var objMap map[string]interface{}
 	if err := yaml.Unmarshal([]byte(req.YAML), &objMap); err != nil {
-		response.BadRequest(c, "YAML格式错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InvalidYAML))
 		return
 	}
 
 	if objMap["apiVersion"] == nil || objMap["kind"] == nil {
-		response.BadRequest(c, "YAML缺少必要字段: apiVersion 或 kind")
+		response.FailCode(c, errcode.YAMLMissingKind)
 		return
 	}
 
 	kind := objMap["kind"].(string)
 	if kind != "DaemonSet" {
-		response.BadRequest(c, "YAML类型错误，期望DaemonSet，实际为: "+kind)
+		response.FailCode(c, errcode.YAMLKindMismatch, "DaemonSet", kind)
 		return
 	}
 
 	metadata, ok := objMap["metadata"].(map[string]interface{})
 	if !ok {
-		response.BadRequest(c, "YAML缺少 metadata 字段")
+		response.FailCode(c, errcode.YAMLMissingMetadata)
 		return
 	}
 
@@ -338,11 +339,14 @@ func (h *DaemonSetHandler) ApplyYAML(c *gin.Context) {
 		namespace = "default"
 	}
 
+	name, _ := metadata["name"].(string)
+	snap := beginApplySnapshot(c, k8sClient, gvrDaemonSets, namespace, name, req.DryRun)
 	result, err := h.applyYAML(ctx, k8sClient, req.YAML, namespace, req.DryRun)
 	if err != nil {
-		response.InternalError(c, "YAML应用失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.YAMLApplyFailed))
 		return
 	}
+	snap.Done()
 
 	response.OK(c, result)
 }
@@ -357,18 +361,18 @@ func (h *DaemonSetHandler) DeleteDaemonSet(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -376,11 +380,13 @@ func (h *DaemonSetHandler) DeleteDaemonSet(c *gin.Context) {
 	defer cancel()
 
 	clientset := k8sClient.GetClientset()
+	snap := beginSnapshot(c, k8sClient, gvrDaemonSets, namespace, name)
 	err = clientset.AppsV1().DaemonSets(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 	if err != nil {
-		response.InternalError(c, "删除失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.DeleteFailed))
 		return
 	}
+	snap.Deleted()
 
 	response.OK(c, gin.H{"message": "删除成功"})
 }
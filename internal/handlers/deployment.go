@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
@@ -80,12 +81,12 @@ func (h *DeploymentHandler) ListDeployments(c *gin.Context) {
 	// 获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -94,14 +95,14 @@ func (h *DeploymentHandler) ListDeployments(c *gin.Context) {
 
 	// 确保 informer 缓存就绪
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 
 	// 检查命名空间权限
 	nsInfo, hasAccess := middleware.CheckNamespacePermission(c, namespace)
 	if !hasAccess {
-		response.Forbidden(c, fmt.Sprintf("无权访问命名空间: %s", namespace))
+		response.FailCode(c, errcode.NamespaceAccessDenied)
 		return
 	}
 
@@ -178,19 +179,19 @@ func (h *DeploymentHandler) GetDeployment(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -200,7 +201,7 @@ func (h *DeploymentHandler) GetDeployment(c *gin.Context) {
 	clientset := k8sClient.GetClientset()
 	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "Deployment不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "Deployment"))
 		return
 	}
 
@@ -242,12 +243,12 @@ func (h *DeploymentHandler) GetDeploymentNamespaces(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -256,7 +257,7 @@ func (h *DeploymentHandler) GetDeploymentNamespaces(c *gin.Context) {
 
 	// 确保 informer 缓存就绪
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 
@@ -264,7 +265,7 @@ func (h *DeploymentHandler) GetDeploymentNamespaces(c *gin.Context) {
 	sel := labels.Everything()
 	deps, err := h.k8sMgr.DeploymentsLister(cluster.ID).List(sel)
 	if err != nil {
-		response.InternalError(c, "读取Deployment缓存失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceCacheFailed, "Deployment"))
 		return
 	}
 
@@ -304,7 +305,7 @@ func (h *DeploymentHandler) ScaleDeployment(c *gin.Context) {
 
 	var req ScaleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -312,19 +313,19 @@ func (h *DeploymentHandler) ScaleDeployment(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -334,16 +335,18 @@ func (h *DeploymentHandler) ScaleDeployment(c *gin.Context) {
 	clientset := k8sClient.GetClientset()
 	scale, err := clientset.AppsV1().Deployments(namespace).GetScale(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		response.InternalError(c, "获取Deployment Scale失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceGetFailed, "Deployment Scale"))
 		return
 	}
 
+	snap := beginSnapshot(c, k8sClient, gvrDeployments, namespace, name)
 	scale.Spec.Replicas = req.Replicas
 	_, err = clientset.AppsV1().Deployments(namespace).UpdateScale(ctx, name, scale, metav1.UpdateOptions{})
 	if err != nil {
-		response.InternalError(c, "扩缩容失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ScaleFailed))
 		return
 	}
+	snap.Done()
 
 	response.NoContent(c)
 }
@@ -354,7 +357,7 @@ func (h *DeploymentHandler) ApplyYAML(c *gin.Context) {
 
 	var req YAMLApplyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -362,19 +365,19 @@ func (h *DeploymentHandler) ApplyYAML(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -384,26 +387,26 @@ func (h *DeploymentHandler) ApplyYAML(c *gin.Context) {
 	// 解析YAML
 	var objMap map[string]interface{}
 	if err := yaml.Unmarshal([]byte(req.YAML), &objMap); err != nil {
-		response.BadRequest(c, "YAML格式错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InvalidYAML))
 		return
 	}
 
 	// 验证必要字段
 	if objMap["apiVersion"] == nil || objMap["kind"] == nil {
-		response.BadRequest(c, "YAML缺少必要字段: apiVersion 或 kind")
+		response.FailCode(c, errcode.YAMLMissingKind)
 		return
 	}
 
 	kind := objMap["kind"].(string)
 	if kind != "Deployment" {
-		response.BadRequest(c, "YAML类型错误，期望Deployment，实际为: "+kind)
+		response.FailCode(c, errcode.YAMLKindMismatch, "Deployment", kind)
 		return
 	}
 
 	// 获取metadata
 	metadata, ok := objMap["metadata"].(map[string]interface{})
 	if !ok {
-		response.BadRequest(c, "YAML缺少 metadata 字段")
+		response.FailCode(c, errcode.YAMLMissingMetadata)
 		return
 	}
 
@@ -412,12 +415,15 @@ func (h *DeploymentHandler) ApplyYAML(c *gin.Context) {
 		namespace = "default"
 	}
 
+	name, _ := metadata["name"].(string)
+	snap := beginApplySnapshot(c, k8sClient, gvrDeployments, namespace, name, req.DryRun)
 	// 应用YAML
 	result, err := h.applyYAML(ctx, k8sClient, req.YAML, namespace, req.DryRun)
 	if err != nil {
-		response.InternalError(c, "YAML应用失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.YAMLApplyFailed))
 		return
 	}
+	snap.Done()
 
 	response.OK(c, result)
 }
@@ -432,19 +438,19 @@ func (h *DeploymentHandler) DeleteDeployment(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -452,11 +458,13 @@ func (h *DeploymentHandler) DeleteDeployment(c *gin.Context) {
 	defer cancel()
 
 	clientset := k8sClient.GetClientset()
+	snap := beginSnapshot(c, k8sClient, gvrDeployments, namespace, name)
 	err = clientset.AppsV1().Deployments(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 	if err != nil {
-		response.InternalError(c, "删除失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.DeleteFailed))
 		return
 	}
+	snap.Deleted()
 
 	response.NoContent(c)
 }
@@ -597,19 +605,19 @@ func (h *DeploymentHandler) GetDeploymentPods(c *gin.Context) {
 	// 获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -620,7 +628,7 @@ func (h *DeploymentHandler) GetDeploymentPods(c *gin.Context) {
 	// 获取Deployment
 	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "Deployment不存在")
+		response.FailCode(c, errcode.ResourceNotFound, "Deployment")
 		return
 	}
 
@@ -630,7 +638,7 @@ func (h *DeploymentHandler) GetDeploymentPods(c *gin.Context) {
 		LabelSelector: selector.String(),
 	})
 	if err != nil {
-		response.InternalError(c, "获取Pod列表失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Pod"))
 		return
 	}
 
@@ -699,19 +707,19 @@ func (h *DeploymentHandler) GetDeploymentServices(c *gin.Context) {
 	// 获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -722,14 +730,14 @@ func (h *DeploymentHandler) GetDeploymentServices(c *gin.Context) {
 	// 获取Deployment
 	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "Deployment不存在")
+		response.FailCode(c, errcode.ResourceNotFound, "Deployment")
 		return
 	}
 
 	// 获取Services
 	serviceList, err := clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		response.InternalError(c, "获取Service列表失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Service"))
 		return
 	}
 
@@ -786,19 +794,19 @@ func (h *DeploymentHandler) GetDeploymentIngresses(c *gin.Context) {
 	// 获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -809,7 +817,7 @@ func (h *DeploymentHandler) GetDeploymentIngresses(c *gin.Context) {
 	// 获取Ingresses
 	ingressList, err := clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		response.InternalError(c, "获取Ingress列表失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Ingress"))
 		return
 	}
 
@@ -861,19 +869,19 @@ func (h *DeploymentHandler) GetDeploymentHPA(c *gin.Context) {
 	// 获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -884,7 +892,7 @@ func (h *DeploymentHandler) GetDeploymentHPA(c *gin.Context) {
 	// 获取HPA列表
 	hpaList, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		response.InternalError(c, "获取HPA列表失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "HPA"))
 		return
 	}
 
@@ -932,7 +940,7 @@ func (h *DeploymentHandler) GetDeploymentHPA(c *gin.Context) {
 	}
 
 	// 未找到HPA
-	response.NotFound(c, "未找到HPA")
+	response.FailCode(c, errcode.HPANotFound)
 }
 
 // GetDeploymentReplicaSets 获取Deployment的ReplicaSets
@@ -946,19 +954,19 @@ func (h *DeploymentHandler) GetDeploymentReplicaSets(c *gin.Context) {
 	// 获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -969,14 +977,14 @@ func (h *DeploymentHandler) GetDeploymentReplicaSets(c *gin.Context) {
 	// 检查Deployment是否存在
 	_, err = clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "Deployment不存在")
+		response.FailCode(c, errcode.ResourceNotFound, "Deployment")
 		return
 	}
 
 	// 获取ReplicaSets
 	rsList, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		response.InternalError(c, "获取ReplicaSet列表失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "ReplicaSet"))
 		return
 	}
 
@@ -1030,19 +1038,19 @@ func (h *DeploymentHandler) GetDeploymentEvents(c *gin.Context) {
 	// 获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -1055,7 +1063,7 @@ func (h *DeploymentHandler) GetDeploymentEvents(c *gin.Context) {
 		FieldSelector: fmt.Sprintf("involvedObject.name=%s,involvedObject.kind=Deployment", name),
 	})
 	if err != nil {
-		response.InternalError(c, "获取Events失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Event"))
 		return
 	}
 
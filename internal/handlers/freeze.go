@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+// freezeUpcomingMaxDays 即将到来的冻结窗口最多查询天数
+const freezeUpcomingMaxDays = 366
+
+// FreezeHandler 变更冻结日历处理器
+type FreezeHandler struct {
+	freezeService *services.FreezeService
+}
+
+// NewFreezeHandler 创建变更冻结日历处理器
+func NewFreezeHandler(freezeService *services.FreezeService) *FreezeHandler {
+	return &FreezeHandler{freezeService: freezeService}
+}
+
+// ListCalendars 获取冻结日历列表
+func (h *FreezeHandler) ListCalendars(c *gin.Context) {
+	calendars, err := h.freezeService.ListCalendars()
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, calendars)
+}
+
+// GetCalendar 获取冻结日历详情
+func (h *FreezeHandler) GetCalendar(c *gin.Context) {
+	id, ok := parseFreezeID(c, errcode.InvalidFreezeCalendarID)
+	if !ok {
+		return
+	}
+	calendar, err := h.freezeService.GetCalendar(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, calendar)
+}
+
+// CreateCalendar 创建冻结日历
+func (h *FreezeHandler) CreateCalendar(c *gin.Context) {
+	var req services.FreezeCalendarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+	calendar, err := h.freezeService.CreateCalendar(&req, c.GetString("username"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Created(c, calendar)
+}
+
+// UpdateCalendar 更新冻结日历
+func (h *FreezeHandler) UpdateCalendar(c *gin.Context) {
+	id, ok := parseFreezeID(c, errcode.InvalidFreezeCalendarID)
+	if !ok {
+		return
+	}
+	var req services.FreezeCalendarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+	calendar, err := h.freezeService.UpdateCalendar(id, &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, calendar)
+}
+
+// DeleteCalendar 删除冻结日历
+func (h *FreezeHandler) DeleteCalendar(c *gin.Context) {
+	id, ok := parseFreezeID(c, errcode.InvalidFreezeCalendarID)
+	if !ok {
+		return
+	}
+	if err := h.freezeService.DeleteCalendar(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// CreateWindow 在日历中添加冻结窗口
+func (h *FreezeHandler) CreateWindow(c *gin.Context) {
+	id, ok := parseFreezeID(c, errcode.InvalidFreezeCalendarID)
+	if !ok {
+		return
+	}
+	var req services.FreezeWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+	window, err := h.freezeService.CreateWindow(id, &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Created(c, window)
+}
+
+// UpdateWindow 更新冻结窗口
+func (h *FreezeHandler) UpdateWindow(c *gin.Context) {
+	id, ok := parseFreezeID(c, errcode.InvalidFreezeWindowID)
+	if !ok {
+		return
+	}
+	var req services.FreezeWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+	window, err := h.freezeService.UpdateWindow(id, &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, window)
+}
+
+// DeleteWindow 删除冻结窗口
+func (h *FreezeHandler) DeleteWindow(c *gin.Context) {
+	id, ok := parseFreezeID(c, errcode.InvalidFreezeWindowID)
+	if !ok {
+		return
+	}
+	if err := h.freezeService.DeleteWindow(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// ListUpcoming 即将到来（含正在生效）的冻结窗口，任意登录用户可查询
+// 查询参数: days（默认 30）, clusterId（可选，只返回覆盖该集群的窗口）
+func (h *FreezeHandler) ListUpcoming(c *gin.Context) {
+	days := getIntParam(c, "days", 30)
+	if days <= 0 || days > freezeUpcomingMaxDays {
+		response.FailCode(c, errcode.InvalidFreezeDays)
+		return
+	}
+	var clusterID uint
+	if value := c.Query("clusterId"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			response.FailCode(c, errcode.InvalidClusterID)
+			return
+		}
+		clusterID = uint(id)
+	}
+
+	windows, err := h.freezeService.Upcoming(clusterID, days)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, windows)
+}
+
+// ListOverrides 紧急放行记录
+func (h *FreezeHandler) ListOverrides(c *gin.Context) {
+	page := getIntParam(c, "page", 1)
+	pageSize := getIntParam(c, "pageSize", 20)
+	overrides, total, err := h.freezeService.ListOverrides(page, pageSize)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.PagedList(c, overrides, total, page, pageSize)
+}
+
+func parseFreezeID(c *gin.Context, code errcode.Code) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.FailCode(c, code)
+		return 0, false
+	}
+	return uint(id), true
+}
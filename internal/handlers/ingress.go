@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
@@ -85,7 +86,7 @@ func (h *IngressHandler) ListIngresses(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -100,7 +101,7 @@ func (h *IngressHandler) ListIngresses(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -108,7 +109,7 @@ func (h *IngressHandler) ListIngresses(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -117,7 +118,7 @@ func (h *IngressHandler) ListIngresses(c *gin.Context) {
 	// 检查命名空间权限
 	nsInfo, hasAccess := middleware.CheckNamespacePermission(c, namespace)
 	if !hasAccess {
-		response.Forbidden(c, fmt.Sprintf("无权访问命名空间: %s", namespace))
+		response.FailCode(c, errcode.NamespaceAccessDenied)
 		return
 	}
 
@@ -125,7 +126,7 @@ func (h *IngressHandler) ListIngresses(c *gin.Context) {
 	ingresses, err := h.getIngresses(clientset, namespace)
 	if err != nil {
 		logger.Error("获取Ingresses失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取Ingresses失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Ingress"))
 		return
 	}
 
@@ -164,7 +165,7 @@ func (h *IngressHandler) GetIngress(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -175,7 +176,7 @@ func (h *IngressHandler) GetIngress(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -183,7 +184,7 @@ func (h *IngressHandler) GetIngress(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -193,7 +194,7 @@ func (h *IngressHandler) GetIngress(c *gin.Context) {
 	ingress, err := clientset.NetworkingV1().Ingresses(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		logger.Error("获取Ingress失败", "error", err, "clusterId", clusterID, "namespace", namespace, "name", name)
-		response.InternalError(c, fmt.Sprintf("获取Ingress失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceGetFailed, "Ingress"))
 		return
 	}
 
@@ -207,7 +208,7 @@ func (h *IngressHandler) GetIngressYAML(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -218,7 +219,7 @@ func (h *IngressHandler) GetIngressYAML(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -226,7 +227,7 @@ func (h *IngressHandler) GetIngressYAML(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -236,7 +237,7 @@ func (h *IngressHandler) GetIngressYAML(c *gin.Context) {
 	ingress, err := clientset.NetworkingV1().Ingresses(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		logger.Error("获取Ingress失败", "error", err, "clusterId", clusterID, "namespace", namespace, "name", name)
-		response.InternalError(c, fmt.Sprintf("获取Ingress失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceGetFailed, "Ingress"))
 		return
 	}
 
@@ -250,7 +251,7 @@ func (h *IngressHandler) GetIngressYAML(c *gin.Context) {
 	yamlData, err := yaml.Marshal(cleanIng)
 	if err != nil {
 		logger.Error("转换YAML失败", "error", err)
-		response.InternalError(c, fmt.Sprintf("转换YAML失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.YAMLMarshalFailed))
 		return
 	}
 
@@ -262,7 +263,7 @@ func (h *IngressHandler) DeleteIngress(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -273,7 +274,7 @@ func (h *IngressHandler) DeleteIngress(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -281,19 +282,21 @@ func (h *IngressHandler) DeleteIngress(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
 	clientset := k8sClient.GetClientset()
 
+	snap := beginSnapshot(c, k8sClient, gvrIngresses, namespace, name)
 	// 删除Ingress
 	err = clientset.NetworkingV1().Ingresses(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
 	if err != nil {
 		logger.Error("删除Ingress失败", "error", err, "clusterId", clusterID, "namespace", namespace, "name", name)
-		response.InternalError(c, fmt.Sprintf("删除Ingress失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceDeleteFailed, "Ingress"))
 		return
 	}
+	snap.Deleted()
 
 	logger.Info("Ingress删除成功", "clusterId", clusterID, "namespace", namespace, "name", name)
 	response.NoContent(c)
@@ -489,13 +492,13 @@ func (h *IngressHandler) CreateIngress(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	var req CreateIngressRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -503,7 +506,7 @@ func (h *IngressHandler) CreateIngress(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -511,7 +514,7 @@ func (h *IngressHandler) CreateIngress(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -527,15 +530,16 @@ func (h *IngressHandler) CreateIngress(c *gin.Context) {
 		// 表单方式创建
 		ingress, err = h.createIngressFromForm(clientset, req.Namespace, req.FormData)
 	} else {
-		response.BadRequest(c, "必须提供YAML或表单数据")
+		response.FailCode(c, errcode.YAMLOrFormRequired)
 		return
 	}
 
 	if err != nil {
 		logger.Error("创建Ingress失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("创建Ingress失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceCreateFailed, "Ingress"))
 		return
 	}
+	recordCreated(c, k8sClient, gvrIngresses, ingress.Namespace, ingress.Name)
 
 	logger.Info("Ingress创建成功", "clusterId", clusterID, "namespace", ingress.Namespace, "name", ingress.Name)
 	response.OK(c, h.convertToIngressInfo(ingress))
@@ -546,7 +550,7 @@ func (h *IngressHandler) UpdateIngress(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	namespace := c.Param("namespace")
@@ -554,7 +558,7 @@ func (h *IngressHandler) UpdateIngress(c *gin.Context) {
 
 	var req CreateIngressRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -562,7 +566,7 @@ func (h *IngressHandler) UpdateIngress(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -570,12 +574,13 @@ func (h *IngressHandler) UpdateIngress(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
 	clientset := k8sClient.GetClientset()
 
+	snap := beginSnapshot(c, k8sClient, gvrIngresses, namespace, name)
 	var ingress *networkingv1.Ingress
 
 	// 根据更新方式选择处理逻辑
@@ -586,15 +591,16 @@ func (h *IngressHandler) UpdateIngress(c *gin.Context) {
 		// 表单方式更新
 		ingress, err = h.updateIngressFromForm(clientset, namespace, name, req.FormData)
 	} else {
-		response.BadRequest(c, "必须提供YAML或表单数据")
+		response.FailCode(c, errcode.YAMLOrFormRequired)
 		return
 	}
 
 	if err != nil {
 		logger.Error("更新Ingress失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("更新Ingress失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceUpdateFailed, "Ingress"))
 		return
 	}
+	snap.Done()
 
 	logger.Info("Ingress更新成功", "clusterId", clusterID, "namespace", ingress.Namespace, "name", ingress.Name)
 	response.OK(c, h.convertToIngressInfo(ingress))
@@ -790,20 +796,20 @@ func (h *IngressHandler) GetIngressNamespaces(c *gin.Context) {
 	// 获取集群
 	id, err := strconv.ParseUint(clusterID, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterService.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 	clientset := k8sClient.GetClientset()
@@ -812,7 +818,7 @@ func (h *IngressHandler) GetIngressNamespaces(c *gin.Context) {
 	ingressList, err := clientset.NetworkingV1().Ingresses("").List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		logger.Error("获取Ingress列表失败", "cluster", cluster.Name, "error", err)
-		response.InternalError(c, fmt.Sprintf("获取Ingress列表失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Ingress"))
 		return
 	}
 
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
@@ -67,12 +68,12 @@ func (h *JobHandler) ListJobs(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -80,14 +81,14 @@ func (h *JobHandler) ListJobs(c *gin.Context) {
 	defer cancel()
 
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 
 	// 检查命名空间权限
 	nsInfo, hasAccess := middleware.CheckNamespacePermission(c, namespace)
 	if !hasAccess {
-		response.Forbidden(c, fmt.Sprintf("无权访问命名空间: %s", namespace))
+		response.FailCode(c, errcode.NamespaceAccessDenied)
 		return
 	}
 
@@ -159,18 +160,18 @@ func (h *JobHandler) GetJob(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -180,7 +181,7 @@ func (h *JobHandler) GetJob(c *gin.Context) {
 	clientset := k8sClient.GetClientset()
 	job, err := clientset.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "Job不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "Job"))
 		return
 	}
 
@@ -219,12 +220,12 @@ func (h *JobHandler) GetJobNamespaces(c *gin.Context) {
 	clusterId := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -232,14 +233,14 @@ func (h *JobHandler) GetJobNamespaces(c *gin.Context) {
 	defer cancel()
 
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 
 	sel := labels.Everything()
 	js, err := h.k8sMgr.JobsLister(cluster.ID).List(sel)
 	if err != nil {
-		response.InternalError(c, "读取Job缓存失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceCacheFailed, "Job"))
 		return
 	}
 
@@ -269,7 +270,7 @@ func (h *JobHandler) ApplyYAML(c *gin.Context) {
 	clusterId := c.Param("clusterID")
 	var req YAMLApplyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -277,18 +278,18 @@ func (h *JobHandler) ApplyYAML(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -297,24 +298,24 @@ func (h *JobHandler) ApplyYAML(c *gin.Context) {
 
 	var objMap map[string]interface{}
 	if err := yaml.Unmarshal([]byte(req.YAML), &objMap); err != nil {
-		response.BadRequest(c, "YAML格式错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InvalidYAML))
 		return
 	}
 
 	if objMap["apiVersion"] == nil || objMap["kind"] == nil {
-		response.BadRequest(c, "YAML缺少必要字段: apiVersion 或 kind")
+		response.FailCode(c, errcode.YAMLMissingKind)
 		return
 	}
 
 	kind := objMap["kind"].(string)
 	if kind != "Job" {
-		response.BadRequest(c, "YAML类型错误，期望Job，实际为: "+kind)
+		response.FailCode(c, errcode.YAMLKindMismatch, "Job", kind)
 		return
 	}
 
 	metadata, ok := objMap["metadata"].(map[string]interface{})
 	if !ok {
-		response.BadRequest(c, "YAML缺少 metadata 字段")
+		response.FailCode(c, errcode.YAMLMissingMetadata)
 		return
 	}
 
@@ -323,11 +324,14 @@ func (h *JobHandler) ApplyYAML(c *gin.Context) {
 		namespace = "default"
 	}
 
+	name, _ := metadata["name"].(string)
+	snap := beginApplySnapshot(c, k8sClient, gvrJobs, namespace, name, req.DryRun)
 	result, err := h.applyYAML(ctx, k8sClient, req.YAML, namespace, req.DryRun)
 	if err != nil {
-		response.InternalError(c, "YAML应用失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.YAMLApplyFailed))
 		return
 	}
+	snap.Done()
 
 	response.OK(c, result)
 }
@@ -341,18 +345,18 @@ func (h *JobHandler) DeleteJob(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -360,11 +364,13 @@ func (h *JobHandler) DeleteJob(c *gin.Context) {
 	defer cancel()
 
 	clientset := k8sClient.GetClientset()
+	snap := beginSnapshot(c, k8sClient, gvrJobs, namespace, name)
 	err = clientset.BatchV1().Jobs(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 	if err != nil {
-		response.InternalError(c, "删除失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.DeleteFailed))
 		return
 	}
+	snap.Deleted()
 
 	response.OK(c, gin.H{"message": "删除成功"})
 }
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/k8saudit"
+	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+)
+
+// k8sAuditMaxBody 单次 Webhook 推送的请求体上限
+const k8sAuditMaxBody = 16 << 20
+
+// K8sAuditHandler Kubernetes API Server 审计事件处理器
+type K8sAuditHandler struct {
+	k8sAuditSvc    *services.K8sAuditService
+	clusterService *services.ClusterService
+}
+
+// NewK8sAuditHandler 创建 K8s 审计事件处理器
+func NewK8sAuditHandler(k8sAuditSvc *services.K8sAuditService, clusterService *services.ClusterService) *K8sAuditHandler {
+	return &K8sAuditHandler{k8sAuditSvc: k8sAuditSvc, clusterService: clusterService}
+}
+
+// ReceiveEvents 接收 API Server 审计 Webhook 推送的 EventList（以集群的接收令牌认证）
+func (h *K8sAuditHandler) ReceiveEvents(c *gin.Context) {
+	clusterID, err := parseClusterID(c.Param("clusterID"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	webhook, err := h.k8sAuditSvc.Authenticate(clusterID, token)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	list, err := k8saudit.Decode(http.MaxBytesReader(c.Writer, c.Request.Body, k8sAuditMaxBody))
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.InvalidK8sAuditEvents))
+		return
+	}
+	stored, err := h.k8sAuditSvc.Ingest(webhook, list)
+	if err != nil {
+		// 返回错误后 API Server 会重试推送
+		logger.Error("保存 K8s 审计事件失败: cluster=%d, err=%v", clusterID, err)
+		response.FailCode(c, errcode.K8sAuditEventSaveFailed)
+		return
+	}
+	response.OK(c, gin.H{"received": len(list.Items), "stored": stored})
+}
+
+// GetWebhook 获取集群的审计 Webhook 配置
+func (h *K8sAuditHandler) GetWebhook(c *gin.Context) {
+	clusterID, err := parseClusterID(c.Param("clusterID"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	webhook, err := h.k8sAuditSvc.GetWebhook(clusterID)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, gin.H{"webhook": webhook, "url": k8sAuditWebhookURL(c, clusterID)})
+}
+
+// ConfigureWebhook 启用/停用集群的审计 Webhook；生成新令牌时一并返回 API Server 使用的 Webhook kubeconfig
+func (h *K8sAuditHandler) ConfigureWebhook(c *gin.Context) {
+	clusterID, err := parseClusterID(c.Param("clusterID"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	cluster, err := h.clusterService.GetCluster(clusterID)
+	if err != nil {
+		response.FailCode(c, errcode.ClusterNotFound)
+		return
+	}
+	var req services.K8sAuditWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	webhook, token, err := h.k8sAuditSvc.ConfigureWebhook(clusterID, &req)
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.K8sAuditWebhookSaveFailed))
+		return
+	}
+	c.Set("cluster_name", cluster.Name)
+	c.Set(middleware.AuditDetailKey, gin.H{
+		"enabled":           webhook.Enabled,
+		"platform_username": webhook.PlatformUsername,
+		"token_rotated":     token != "",
+	})
+
+	url := k8sAuditWebhookURL(c, clusterID)
+	result := gin.H{"webhook": webhook, "url": url}
+	if token != "" {
+		result["token"] = token
+		result["kubeconfig"] = k8saudit.WebhookKubeconfig(url, token)
+	}
+	response.OK(c, result)
+}
+
+// k8sAuditWebhookURL 按当前请求的地址生成接收地址（反向代理需传递 X-Forwarded-Proto）
+func k8sAuditWebhookURL(c *gin.Context, clusterID uint) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/api/v1/k8s-audit/clusters/%d/events", scheme, c.Request.Host, clusterID)
+}
+
+// ListEvents 查询 API Server 审计事件
+func (h *K8sAuditHandler) ListEvents(c *gin.Context) {
+	req := &services.K8sAuditEventListRequest{
+		Namespace: c.Query("namespace"),
+		Resource:  c.Query("resource"),
+		Name:      c.Query("name"),
+		Actor:     c.Query("actor"),
+		Verb:      c.Query("verb"),
+		Source:    c.Query("source"),
+		Page:      getIntParam(c, "page", 1),
+		PageSize:  getIntParam(c, "pageSize", 20),
+	}
+	if id, err := strconv.ParseUint(c.Query("clusterId"), 10, 32); err == nil {
+		req.ClusterID = uint(id)
+	}
+	if t, err := time.Parse(time.RFC3339, c.Query("startTime")); err == nil {
+		req.StartTime = &t
+	}
+	if t, err := time.Parse(time.RFC3339, c.Query("endTime")); err == nil {
+		req.EndTime = &t
+	}
+
+	events, total, err := h.k8sAuditSvc.ListEvents(req)
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.K8sAuditEventsFailed))
+		return
+	}
+	response.PagedList(c, events, total, req.Page, req.PageSize)
+}
+
+// GetObjectTimeline 对象变更时间线：无论经平台、平台终端还是直接访问集群，统一展示谁在何时修改了对象
+func (h *K8sAuditHandler) GetObjectTimeline(c *gin.Context) {
+	clusterID, err := strconv.ParseUint(c.Query("clusterId"), 10, 32)
+	resource, name := c.Query("resource"), c.Query("name")
+	if err != nil || resource == "" || name == "" {
+		response.FailCode(c, errcode.K8sAuditParamsRequired)
+		return
+	}
+
+	items, err := h.k8sAuditSvc.ObjectTimeline(uint(clusterID), resource, c.Query("namespace"), name, getIntParam(c, "limit", 100))
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.ChangeTimelineFailed))
+		return
+	}
+	response.OK(c, items)
+}
@@ -9,11 +9,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/internal/terminalhub"
+	"github.com/clay-wangzhi/KubePolaris/internal/terminalreplay"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -39,7 +42,7 @@ type KubectlPodTerminalHandler struct {
 	clusterService *services.ClusterService
 	auditService   *services.AuditService
 	k8sMgr         *k8s.ClusterInformerManager
-	replayDir      string
+	replayStorage  *terminalreplay.Storage
 	podTerminal    *PodTerminalHandler
 	activeSessions map[string]int // podName -> activeConnections
 	sessionsMutex  sync.RWMutex
@@ -47,13 +50,13 @@ type KubectlPodTerminalHandler struct {
 }
 
 // NewKubectlPodTerminalHandler 创建 kubectl Pod 终端处理器
-func NewKubectlPodTerminalHandler(clusterService *services.ClusterService, auditService *services.AuditService, k8sMgr *k8s.ClusterInformerManager, replayDir string) *KubectlPodTerminalHandler {
+func NewKubectlPodTerminalHandler(clusterService *services.ClusterService, auditService *services.AuditService, commandPolicy *services.TerminalCommandPolicyService, liveHub *terminalhub.Hub, k8sMgr *k8s.ClusterInformerManager, replayStorage *terminalreplay.Storage) *KubectlPodTerminalHandler {
 	h := &KubectlPodTerminalHandler{
 		clusterService: clusterService,
 		auditService:   auditService,
 		k8sMgr:         k8sMgr,
-		replayDir:      replayDir,
-		podTerminal:    NewPodTerminalHandler(clusterService, auditService, k8sMgr, replayDir),
+		replayStorage:  replayStorage,
+		podTerminal:    NewPodTerminalHandler(clusterService, auditService, commandPolicy, liveHub, k8sMgr, replayStorage),
 		activeSessions: make(map[string]int),
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
@@ -77,7 +80,7 @@ func (h *KubectlPodTerminalHandler) HandleKubectlPodTerminal(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -111,14 +114,14 @@ func (h *KubectlPodTerminalHandler) HandleKubectlPodTerminal(c *gin.Context) {
 	// 获取集群信息
 	cluster, err := h.clusterService.GetCluster(uint(clusterID))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 	client := k8sClient.GetClientset()
@@ -179,6 +182,7 @@ func (h *KubectlPodTerminalHandler) HandleKubectlPodTerminal(c *gin.Context) {
 		podName,
 		"kubectl",
 		userID,
+		permissionType,
 		services.TerminalTypeKubectl,
 	)
 }
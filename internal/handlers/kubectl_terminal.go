@@ -2,31 +2,58 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"os"
 	"os/exec"
-	"runtime"
+	"os/user"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/internal/terminalhub"
+	"github.com/clay-wangzhi/KubePolaris/internal/terminalreplay"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+	"github.com/clay-wangzhi/KubePolaris/pkg/pty"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
+// kubectlTokenRefreshInterval ServiceAccount 令牌（1 小时有效）的刷新间隔
+const kubectlTokenRefreshInterval = 45 * time.Minute
+
+// promptUnsafeChars 提示符中需要过滤的字符（避免 PS1 转义与命令替换）
+var promptUnsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
 // KubectlTerminalHandler kubectl终端WebSocket处理器
+// 在服务端以 PTY 启动受限 shell（bash --restricted），PATH 中只包含允许的命令，
+// kubeconfig 使用用户有效 ServiceAccount 的令牌，HOME 为每个会话独立的临时目录。
 type KubectlTerminalHandler struct {
 	clusterService *services.ClusterService
 	auditService   *services.AuditService
+	commandPolicy  *services.TerminalCommandPolicyService
+	liveHub        *terminalhub.Hub
+	k8sMgr         *k8s.ClusterInformerManager
+	replayStorage  *terminalreplay.Storage // 为空表示不录像
+	tools          []string
+	shellUser      string // 运行受限 shell 的系统用户，与服务进程隔离
 	upgrader       websocket.Upgrader
 	sessions       map[string]*KubectlSession
 	sessionsMutex  sync.RWMutex
@@ -39,27 +66,35 @@ type KubectlSession struct {
 	ClusterID      string
 	Namespace      string
 	Conn           *websocket.Conn
-	Cmd            *exec.Cmd
-	StdinPipe      *os.File
-	StdoutPipe     *os.File
 	Context        context.Context
 	Cancel         context.CancelFunc
-	LastCommand    string
-	History        []string
 	Mutex          sync.Mutex
-}
 
-// TerminalMessage 终端消息
-type TerminalMessage struct {
-	Type string `json:"type"`
-	Data string `json:"data"`
+	writeMu sync.Mutex // WebSocket 写入串行化
+	cmd     *exec.Cmd
+	pty     *os.File
+	workDir string // 会话临时目录：home/ 为 HOME，bin/ 为受限 PATH
+
+	// 命令捕获（从终端回显中提取完整命令，包括Tab补全结果）
+	currentLine  strings.Builder
+	pendingEnter bool
+
+	guard  *terminalCommandGuard // 终端命令策略检查（未配置时为 nil）
+	replay *terminalreplay.Recorder
+	live   *terminalhub.Session // 在线旁观（未注册时为 nil）
 }
 
-// NewKubectlTerminalHandler 创建kubectl终端处理器
-func NewKubectlTerminalHandler(clusterService *services.ClusterService, auditService *services.AuditService) *KubectlTerminalHandler {
+// NewKubectlTerminalHandler 创建kubectl终端处理器。tools 为受限 shell 中可用的命令，shellUser 为运行 shell 的系统用户。
+func NewKubectlTerminalHandler(clusterService *services.ClusterService, auditService *services.AuditService, commandPolicy *services.TerminalCommandPolicyService, liveHub *terminalhub.Hub, k8sMgr *k8s.ClusterInformerManager, replayStorage *terminalreplay.Storage, tools []string, shellUser string) *KubectlTerminalHandler {
 	return &KubectlTerminalHandler{
 		clusterService: clusterService,
 		auditService:   auditService,
+		commandPolicy:  commandPolicy,
+		liveHub:        liveHub,
+		k8sMgr:         k8sMgr,
+		replayStorage:  replayStorage,
+		tools:          tools,
+		shellUser:      shellUser,
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				origin := r.Header.Get("Origin")
@@ -77,19 +112,44 @@ func NewKubectlTerminalHandler(clusterService *services.ClusterService, auditSer
 }
 
 // HandleKubectlTerminal 处理kubectl终端WebSocket连接
+// 消息协议与 Pod 终端一致：客户端发送 input / resize，服务端推送 connected / data / error / disconnected
 func (h *KubectlTerminalHandler) HandleKubectlTerminal(c *gin.Context) {
 	clusterID := c.Param("clusterID")
 	namespace := c.DefaultQuery("namespace", "default")
 	userID := c.GetUint("user_id") // 从JWT中获取用户ID
 
-	// 获取集群信息
-	cluster, err := h.clusterService.GetCluster(uint(mustParseUint(clusterID)))
+	clusterIDUint, err := strconv.ParseUint(clusterID, 10, 32)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidClusterID)
+		return
+	}
+	cluster, err := h.clusterService.GetCluster(uint(clusterIDUint))
+	if err != nil {
+		response.FailCode(c, errcode.ClusterNotFound)
+		return
+	}
+
+	// 用户有效 ServiceAccount（与 kubectl Pod 终端一致）
+	rbacConfig := &services.UserRBACConfig{UserID: userID, PermissionType: "readonly"}
+	if perm, exists := c.Get("cluster_permission"); exists {
+		if cp, ok := perm.(*models.ClusterPermission); ok && cp != nil {
+			rbacConfig.PermissionType = cp.PermissionType
+			rbacConfig.Namespaces = cp.GetNamespaceList()
+			rbacConfig.ClusterRoleRef = cp.CustomRoleRef
+		}
+	}
+	rbacSvc := services.NewRBACService()
+	serviceAccount := rbacSvc.GetEffectiveServiceAccount(rbacConfig)
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		logger.Error("升级WebSocket连接失败", "error", err)
 		return
 	}
+	defer func() {
+		_ = conn.Close()
+	}()
 
-	// 创建审计会话
 	var auditSessionID uint
 	if h.auditService != nil {
 		auditSession, err := h.auditService.CreateSession(&services.CreateSessionRequest{
@@ -105,24 +165,8 @@ func (h *KubectlTerminalHandler) HandleKubectlTerminal(c *gin.Context) {
 		}
 	}
 
-	// 升级到WebSocket连接
-	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
-	if err != nil {
-		logger.Error("升级WebSocket连接失败", "error", err)
-		// 关闭审计会话
-		if h.auditService != nil && auditSessionID > 0 {
-			_ = h.auditService.CloseSession(auditSessionID, "error")
-		}
-		return
-	}
-	defer func() {
-		_ = conn.Close()
-	}()
-
-	// 创建会话
-	sessionID := fmt.Sprintf("%s-%d", clusterID, time.Now().Unix())
+	sessionID := fmt.Sprintf("%s-%d", clusterID, time.Now().UnixNano())
 	ctx, cancel := context.WithCancel(context.Background())
-
 	session := &KubectlSession{
 		ID:             sessionID,
 		AuditSessionID: auditSessionID,
@@ -131,520 +175,566 @@ func (h *KubectlTerminalHandler) HandleKubectlTerminal(c *gin.Context) {
 		Conn:           conn,
 		Context:        ctx,
 		Cancel:         cancel,
-		History:        make([]string, 0),
 	}
+	session.guard = newTerminalCommandGuard(h.commandPolicy, h.auditService, auditSessionID, services.TerminalTypeKubectl, cluster.ID, "", rbacConfig.PermissionType)
+
+	if h.auditService != nil && auditSessionID > 0 {
+		rec, err := terminalreplay.NewRecorder(h.replayStorage, h.auditService, auditSessionID, 120, 30)
+		if err != nil {
+			logger.Error("创建会话录像失败", "error", err)
+		} else {
+			session.replay = rec
+		}
+	}
+
+	session.live = h.liveHub.Register(terminalhub.SessionInfo{
+		SessionID:  auditSessionID,
+		UserID:     userID,
+		ClusterID:  cluster.ID,
+		TargetType: string(services.TerminalTypeKubectl),
+		Target:     cluster.Name,
+	}, terminalhub.Owner{
+		Input:  func(data string) { h.handleInput(session, data) },
+		Notify: func(msgType, data string) { h.sendMessage(session, msgType, data) },
+		Kill: func(reason string) {
+			h.sendMessage(session, "error", "会话已被管理员强制终止: "+reason)
+			_ = conn.Close()
+		},
+	})
 
-	// 注册会话
 	h.sessionsMutex.Lock()
 	h.sessions[sessionID] = session
 	h.sessionsMutex.Unlock()
 
-	// 清理会话
 	defer func() {
 		h.sessionsMutex.Lock()
 		delete(h.sessions, sessionID)
 		h.sessionsMutex.Unlock()
 		cancel()
-		if session.Cmd != nil && session.Cmd.Process != nil {
-			_ = session.Cmd.Process.Kill()
+		h.closeSession(session)
+		killed := session.live.Close()
+		if session.replay != nil {
+			session.replay.End()
 		}
-		// 关闭审计会话
 		if h.auditService != nil && auditSessionID > 0 {
-			_ = h.auditService.CloseSession(auditSessionID, "closed")
+			status := "closed"
+			if killed {
+				status = "killed"
+			}
+			_ = h.auditService.CloseSession(auditSessionID, status)
 		}
 	}()
 
-	// 创建临时kubeconfig文件
-	kubeconfigPath, err := h.createTempKubeconfig(cluster)
+	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		h.sendMessage(conn, "error", fmt.Sprintf("创建kubeconfig失败: %v", err))
+		h.sendMessage(session, "error", fmt.Sprintf("获取K8s客户端失败: %v", err))
 		return
 	}
-	defer func() {
-		_ = os.Remove(kubeconfigPath)
-	}()
-
-	// 发送欢迎消息
-	h.sendMessage(conn, "output", fmt.Sprintf("Connected to cluster: %s\n", cluster.Name))
-	h.sendMessage(conn, "output", fmt.Sprintf("Default namespace: %s\n", namespace))
-	h.sendMessage(conn, "command_result", "")
+	if err := h.startShell(session, cluster, k8sClient.GetClientset(), k8sClient.GetRestConfig(), rbacSvc, serviceAccount); err != nil {
+		logger.Error("启动kubectl终端失败: cluster=%s, user=%d, err=%v", cluster.Name, userID, err)
+		h.sendMessage(session, "error", fmt.Sprintf("启动kubectl终端失败: %v", err))
+		return
+	}
+	h.sendMessage(session, "connected", fmt.Sprintf("Connected to cluster %s (namespace: %s, serviceaccount: %s)", cluster.Name, namespace, serviceAccount))
 
-	// 处理WebSocket消息
 	for {
-		var msg TerminalMessage
-		err := conn.ReadJSON(&msg)
+		_, data, err := conn.ReadMessage()
 		if err != nil {
-			logger.Error("读取WebSocket消息失败", "error", err)
 			break
 		}
-
+		var msg PodTerminalMessage
+		if err := json.Unmarshal(data, &msg); err != nil || msg.Type == "" {
+			h.handleInput(session, string(data))
+			continue
+		}
 		switch msg.Type {
 		case "input":
 			h.handleInput(session, msg.Data)
-		case "command":
-			h.handleCommand(session, kubeconfigPath, namespace)
-		case "interrupt":
-			h.handleInterrupt(session)
-		case "change_namespace":
-			namespace = msg.Data
-			session.Namespace = namespace
-			h.sendMessage(conn, "namespace_changed", namespace)
-		case "quick_command":
-			h.handleQuickCommand(session, kubeconfigPath, namespace, msg.Data)
+		case "resize":
+			h.handleResize(session, msg.Cols, msg.Rows)
+		case "share_response":
+			// 所有者答复协同操作申请：approve / deny
+			session.live.RespondDrive(msg.Data == "approve")
+		case "share_revoke":
+			session.live.RevokeDrive()
 		}
 	}
 }
 
-// handleInput 处理用户输入
-func (h *KubectlTerminalHandler) handleInput(session *KubectlSession, input string) {
-	session.Mutex.Lock()
-	defer session.Mutex.Unlock()
-
-	if input == "\u007f" { // 退格键
-		if len(session.LastCommand) > 0 {
-			session.LastCommand = session.LastCommand[:len(session.LastCommand)-1]
-			h.sendMessage(session.Conn, "output", "\b \b")
-		}
-	} else {
-		session.LastCommand += input
-		h.sendMessage(session.Conn, "output", input)
+// startShell 准备会话目录与 kubeconfig，并在 PTY 中以独立的系统用户启动受限 shell
+func (h *KubectlTerminalHandler) startShell(session *KubectlSession, cluster *models.Cluster, client *kubernetes.Clientset, restConfig *rest.Config, rbacSvc *services.RBACService, serviceAccount string) error {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		return errors.New("服务器未安装 bash")
 	}
-}
-
-// handleCommand 处理命令执行
-func (h *KubectlTerminalHandler) handleCommand(session *KubectlSession, kubeconfigPath, namespace string) {
-	session.Mutex.Lock()
-	command := strings.TrimSpace(session.LastCommand)
-	session.LastCommand = ""
-	// 使用会话中的命名空间，而不是传入的参数
-	currentNamespace := session.Namespace
-	session.Mutex.Unlock()
-
-	if command == "" {
-		h.sendMessage(session.Conn, "command_result", "")
-		return
+	uid, gid, err := lookupShellUser(h.shellUser)
+	if err != nil {
+		return err
 	}
 
-	// 添加到历史记录
-	session.History = append(session.History, command)
-	if len(session.History) > 100 {
-		session.History = session.History[1:]
+	workDir, err := os.MkdirTemp("", "kubepolaris-kubectl-*")
+	if err != nil {
+		return fmt.Errorf("创建会话目录失败: %v", err)
 	}
-
-	// 记录命令到审计数据库（异步）
-	if h.auditService != nil && session.AuditSessionID > 0 {
-		h.auditService.RecordCommandAsync(session.AuditSessionID, command, command, nil)
+	session.workDir = workDir
+	home := filepath.Join(workDir, "home")
+	binDir := filepath.Join(workDir, "bin")
+	if err := os.MkdirAll(filepath.Join(home, ".kube"), 0700); err != nil {
+		return fmt.Errorf("创建会话目录失败: %v", err)
 	}
-
-	// 执行kubectl命令，使用会话中的命名空间
-	h.executeKubectlCommand(session, kubeconfigPath, currentNamespace, command)
-}
-
-// handleQuickCommand 处理快捷命令
-func (h *KubectlTerminalHandler) handleQuickCommand(session *KubectlSession, kubeconfigPath, namespace, command string) {
-	h.sendMessage(session.Conn, "output", fmt.Sprintf("\n%s\n", command))
-
-	// 记录快捷命令到审计数据库（异步）
-	if h.auditService != nil && session.AuditSessionID > 0 {
-		h.auditService.RecordCommandAsync(session.AuditSessionID, command, command, nil)
-	}
-
-	// 使用会话中的命名空间，而不是传入的参数
-	h.executeKubectlCommand(session, kubeconfigPath, session.Namespace, command)
-}
-
-// executeKubectlCommand 执行kubectl命令
-func (h *KubectlTerminalHandler) executeKubectlCommand(session *KubectlSession, kubeconfigPath, namespace, command string) {
-	// 解析命令
-	parts := strings.Fields(command)
-	if len(parts) == 0 {
-		h.sendMessage(session.Conn, "command_result", "")
-		return
+	// 会话目录仅允许 shell 用户进入，HOME 归 shell 用户所有
+	if err := os.Chmod(workDir, 0711); err != nil {
+		return fmt.Errorf("创建会话目录失败: %v", err)
 	}
-
-	// 处理特殊命令
-	if h.handleSpecialCommands(session, command) {
-		return
+	for _, dir := range []string{home, filepath.Join(home, ".kube")} {
+		if err := os.Chown(dir, int(uid), int(gid)); err != nil {
+			return fmt.Errorf("设置会话目录属主失败: %v", err)
+		}
 	}
-
-	// 构建kubectl命令
-	var args []string
-	if parts[0] == "kubectl" {
-		args = parts[1:]
-	} else {
-		// 如果用户没有输入kubectl前缀，自动添加
-		args = parts
+	editor, err := linkShellTools(binDir, workDir, h.tools)
+	if err != nil {
+		return err
 	}
-
-	// 检查是否需要添加namespace参数
-	needsNamespace := h.commandNeedsNamespace(args)
-
-	// 添加kubeconfig参数
-	kubectlArgs := []string{"--kubeconfig", kubeconfigPath}
-
-	// 如果命令需要namespace且用户没有指定，则添加默认namespace
-	if needsNamespace && !h.hasNamespaceFlag(args) {
-		kubectlArgs = append(kubectlArgs, "--namespace", namespace)
+	shell, err := writeShellScript(filepath.Join(workDir, "libexec"), "rbash", bash, "--restricted", "--noprofile", "--norc")
+	if err != nil {
+		return err
 	}
 
-	kubectlArgs = append(kubectlArgs, args...)
-
-	// 检查是否是流式命令（如 logs -f）
-	isStreamingCommand := h.isStreamingCommand(args)
-
-	// 创建命令
-	var ctx context.Context
-	var cancel context.CancelFunc
-
-	if isStreamingCommand {
-		// 流式命令不设置超时
-		ctx, cancel = context.WithCancel(session.Context)
-	} else {
-		// 非流式命令设置超时
-		ctx, cancel = context.WithTimeout(session.Context, 60*time.Second)
+	kubeconfigPath := filepath.Join(home, ".kube", "config")
+	writeKubeconfig := func() error {
+		token, err := rbacSvc.GetServiceAccountToken(client, serviceAccount)
+		if err != nil {
+			return fmt.Errorf("获取 ServiceAccount %s 令牌失败: %v", serviceAccount, err)
+		}
+		if err := writeShellKubeconfig(kubeconfigPath, cluster.Name, restConfig, token, session.Namespace); err != nil {
+			return err
+		}
+		return os.Chown(kubeconfigPath, int(uid), int(gid))
+	}
+	if err := writeKubeconfig(); err != nil {
+		return err
+	}
+
+	prompt := promptUnsafeChars.ReplaceAllString(cluster.Name, "")
+	cmd := exec.Command(bash, "--restricted", "--noprofile", "--norc", "-i") // #nosec G204 -- 固定参数启动受限 shell
+	cmd.Dir = home
+	// 不继承服务进程环境变量，避免泄露数据库密码等配置
+	cmd.Env = []string{
+		"HOME=" + home,
+		"PATH=" + binDir,
+		"SHELL=" + shell,
+		"TERM=xterm-256color",
+		"LANG=C.UTF-8",
+		"KUBECONFIG=" + kubeconfigPath,
+		"LESSSECURE=1",
+		"HISTFILE=" + filepath.Join(home, ".bash_history"),
+		`PS1=\[\e[1;32m\]` + prompt + `\[\e[0m\]:\[\e[1;34m\]kubectl\[\e[0m\]$ `,
+	}
+	if editor != "" {
+		cmd.Env = append(cmd.Env, "KUBE_EDITOR="+editor, "EDITOR="+editor)
+	}
+	ptmx, err := pty.StartAs(cmd, 120, 30, uid, gid)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return fmt.Errorf("无法以用户 %s 启动终端，服务需以 root 运行或具备 CAP_SETUID/CAP_SETGID 权限: %v", h.shellUser, err)
+		}
+		return fmt.Errorf("创建伪终端失败: %v", err)
 	}
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "kubectl", kubectlArgs...) // #nosec G204 -- kubectl 参数经过白名单校验
-
-	// 设置环境变量
-	cmd.Env = append(os.Environ(),
-		fmt.Sprintf("KUBECONFIG=%s", kubeconfigPath),
-	)
-
-	// 保存命令到会话，以便可以被中断
 	session.Mutex.Lock()
-	session.Cmd = cmd
+	session.cmd = cmd
+	session.pty = ptmx
 	session.Mutex.Unlock()
 
-	// 如果是流式命令，使用管道处理输出
-	if isStreamingCommand {
-		// 创建管道
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			h.sendMessage(session.Conn, "error", fmt.Sprintf("创建输出管道失败: %v", err))
-			return
+	go h.readOutput(session)
+	go func() {
+		_ = cmd.Wait()
+		h.sendMessage(session, "disconnected", "kubectl终端已退出")
+		_ = session.Conn.Close()
+	}()
+	// 令牌有效期 1 小时，长会话定期刷新 kubeconfig
+	go func() {
+		ticker := time.NewTicker(kubectlTokenRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-session.Context.Done():
+				return
+			case <-ticker.C:
+				if err := writeKubeconfig(); err != nil {
+					logger.Warn("刷新kubectl终端令牌失败: session=%s, err=%v", session.ID, err)
+				}
+			}
 		}
+	}()
+	return nil
+}
 
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			h.sendMessage(session.Conn, "error", fmt.Sprintf("创建错误管道失败: %v", err))
-			return
-		}
+// shellEditors 编辑器命令。统一包装为受限模式的 vim（-Z，禁止 :!、:shell 等执行命令），
+// 服务器没有 vim 时不提供编辑器，因为其他 vi 实现无法禁用 shell 转义
+var shellEditors = map[string]bool{"vi": true, "vim": true, "view": true}
 
-		// 启动命令
-		if err := cmd.Start(); err != nil {
-			h.sendMessage(session.Conn, "error", fmt.Sprintf("启动命令失败: %v", err))
-			return
-		}
+// shellFileReaders 可以读取文件参数的命令，包装为只允许读取会话目录内文件的脚本（其余路径拒绝执行）
+var shellFileReaders = map[string]bool{
+	"grep": true, "egrep": true, "fgrep": true, "jq": true, "yq": true, "head": true, "tail": true,
+	"less": true, "more": true, "cat": true, "sort": true, "uniq": true, "wc": true, "cut": true,
+	"column": true, "base64": true, "helm": true,
+}
 
-		// 读取标准输出
-		go func() {
-			buffer := make([]byte, 1024)
-			for {
-				n, err := stdout.Read(buffer)
-				if n > 0 {
-					h.sendMessage(session.Conn, "output", string(buffer[:n]))
-				}
-				if err != nil {
-					break
-				}
+// shellDeniedArgs 命令参数中不允许出现的内容（yq 的 load 系列操作符可在表达式中读取任意文件）
+var shellDeniedArgs = map[string][]string{"yq": {"load"}}
+
+// linkShellTools 在受限 PATH 目录中为允许的命令创建符号链接（服务器上不存在的命令会被跳过），
+// 可读取文件的命令改为限制在 workDir 内的包装脚本，返回可用的编辑器命令（没有时为空）
+func linkShellTools(binDir, workDir string, tools []string) (string, error) {
+	if err := os.MkdirAll(binDir, 0750); err != nil {
+		return "", fmt.Errorf("创建会话目录失败: %v", err)
+	}
+	realpath, err := exec.LookPath("realpath")
+	if err != nil {
+		realpath = ""
+	}
+	hasKubectl := false
+	editor := ""
+	for _, tool := range tools {
+		if tool == "" || strings.ContainsAny(tool, `/\`) {
+			continue
+		}
+		if shellEditors[tool] {
+			vim, err := exec.LookPath("vim")
+			if err != nil {
+				continue
 			}
-		}()
-
-		// 读取标准错误
-		go func() {
-			buffer := make([]byte, 1024)
-			for {
-				n, err := stderr.Read(buffer)
-				if n > 0 {
-					h.sendMessage(session.Conn, "error", string(buffer[:n]))
-				}
-				if err != nil {
-					break
-				}
+			if _, err := writeShellScript(binDir, tool, vim, "-Z"); err != nil {
+				return "", err
 			}
-		}()
-
-		// 等待命令完成
-		go func() {
-			err := cmd.Wait()
-			if err != nil && ctx.Err() != context.Canceled {
-				h.sendMessage(session.Conn, "error", fmt.Sprintf("命令执行失败: %v", err))
+			if editor == "" || tool == "vim" {
+				editor = tool
 			}
-			h.sendMessage(session.Conn, "command_result", "")
-
-			// 清除会话中的命令引用
-			session.Mutex.Lock()
-			session.Cmd = nil
-			session.Mutex.Unlock()
-		}()
-	} else {
-		// 非流式命令，使用CombinedOutput
-		output, err := cmd.CombinedOutput()
-
-		// 清除会话中的命令引用
-		session.Mutex.Lock()
-		session.Cmd = nil
-		session.Mutex.Unlock()
-
+			continue
+		}
+		target, err := exec.LookPath(tool)
 		if err != nil {
-			if ctx.Err() == context.DeadlineExceeded {
-				h.sendMessage(session.Conn, "error", "命令执行超时 (60秒)")
-			} else {
-				h.sendMessage(session.Conn, "error", fmt.Sprintf("命令执行失败: %v\n%s", err, string(output)))
+			continue
+		}
+		if abs, err := filepath.Abs(target); err == nil {
+			target = abs
+		}
+		if shellFileReaders[tool] {
+			// 无法校验路径时不提供该命令
+			if realpath == "" {
+				logger.Warn("服务器未安装 realpath，kubectl 终端不提供命令 %s", tool)
+				continue
 			}
-		} else {
-			// 发送输出
-			if len(output) > 0 {
-				h.sendMessage(session.Conn, "output", string(output))
+			var env []string
+			if tool == "helm" {
+				if env, err = pinHelmDirs(workDir); err != nil {
+					return "", err
+				}
 			}
+			if err := writeGuardedTool(binDir, tool, target, realpath, workDir, env); err != nil {
+				return "", err
+			}
+			continue
 		}
-
-		h.sendMessage(session.Conn, "command_result", "")
+		if err := os.Symlink(target, filepath.Join(binDir, tool)); err != nil {
+			return "", fmt.Errorf("准备命令 %s 失败: %v", tool, err)
+		}
+		if tool == "kubectl" {
+			hasKubectl = true
+		}
+	}
+	if !hasKubectl {
+		return "", errors.New("服务器未安装 kubectl")
 	}
+	return editor, os.Chmod(binDir, 0555)
 }
 
-// isStreamingCommand 检查是否是流式命令
-func (h *KubectlTerminalHandler) isStreamingCommand(args []string) bool {
-	if len(args) == 0 {
-		return false
-	}
+// pinHelmDirs 创建只读的空目录，helm 的插件、数据、配置与缓存目录都指向它，
+// 避免加载服务器上已安装的插件（插件即任意命令）或写入下载的插件
+func pinHelmDirs(workDir string) ([]string, error) {
+	dir := filepath.Join(workDir, "helm")
+	if err := os.MkdirAll(dir, 0555); err != nil {
+		return nil, fmt.Errorf("创建会话目录失败: %v", err)
+	}
+	if err := os.Chmod(dir, 0555); err != nil {
+		return nil, fmt.Errorf("创建会话目录失败: %v", err)
+	}
+	return []string{
+		"HELM_PLUGINS=" + dir,
+		"HELM_DATA_HOME=" + dir,
+		"HELM_CONFIG_HOME=" + dir,
+		"HELM_CACHE_HOME=" + dir,
+		"HELM_REPOSITORY_CONFIG=" + filepath.Join(dir, "repositories.yaml"),
+		"HELM_REPOSITORY_CACHE=" + dir,
+	}, nil
+}
 
-	// 检查是否是 logs 命令 (无论是否有 -f 参数，都作为流式处理)
-	if args[0] == "logs" {
-		return true
-	}
+// writeGuardedTool 写入只允许读取 workDir 内文件的包装脚本：逐个检查参数（含 --opt=PATH 与 -oPATH 形式），
+// 指向已存在路径时解析符号链接后必须位于 workDir 下；env 为执行命令时固定的环境变量
+func writeGuardedTool(binDir, name, target, realpath, workDir string, env []string) error {
+	if resolved, err := filepath.EvalSymlinks(workDir); err == nil {
+		workDir = resolved
+	}
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("for arg in \"$@\"; do\n")
+	for _, denied := range shellDeniedArgs[name] {
+		fmt.Fprintf(&b, "\tcase \"$arg\" in *%s*) echo \"%s: 不允许使用 %s\" >&2; exit 1 ;; esac\n", denied, name, denied)
+	}
+	b.WriteString("\tfor p in \"$arg\" \"${arg#*=}\" \"${arg#-?}\"; do\n")
+	b.WriteString("\t\tcase \"$p\" in \"\"|-*) continue ;; esac\n")
+	b.WriteString("\t\t[ -e \"$p\" ] || continue\n")
+	fmt.Fprintf(&b, "\t\tcase \"$(%s -- \"$p\")/\" in\n", shellQuote(realpath))
+	fmt.Fprintf(&b, "\t\t%s/*) ;;\n", shellQuote(workDir))
+	fmt.Fprintf(&b, "\t\t*) echo \"%s: 只能读取会话目录内的文件: $p\" >&2; exit 1 ;;\n", name)
+	b.WriteString("\t\tesac\n\tdone\ndone\n")
+	for _, kv := range env {
+		k, v, _ := strings.Cut(kv, "=")
+		fmt.Fprintf(&b, "export %s=%s\n", k, shellQuote(v))
+	}
+	b.WriteString("exec " + shellQuote(target) + " \"$@\"\n")
+	if err := os.WriteFile(filepath.Join(binDir, name), []byte(b.String()), 0555); err != nil { // #nosec G306 -- 需对 shell 用户可执行
+		return fmt.Errorf("准备命令 %s 失败: %v", name, err)
+	}
+	return nil
+}
 
-	// 检查是否是 exec 命令
-	if args[0] == "exec" {
-		return true
+// writeShellScript 写入以固定参数执行 target 的包装脚本，返回脚本路径
+func writeShellScript(dir, name, target string, args ...string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建会话目录失败: %v", err)
 	}
-
-	// 检查是否是 port-forward 命令
-	if args[0] == "port-forward" {
-		return true
+	if abs, err := filepath.Abs(target); err == nil {
+		target = abs
 	}
-
-	// 检查是否是 watch 命令
-	if args[0] == "watch" {
-		return true
+	quoted := []string{shellQuote(target)}
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
 	}
-
-	// 检查是否是 top 命令
-	if args[0] == "top" {
-		return true
+	script := "#!/bin/sh\nexec " + strings.Join(quoted, " ") + " \"$@\"\n"
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(script), 0555); err != nil { // #nosec G306 -- 需对 shell 用户可执行
+		return "", fmt.Errorf("准备命令 %s 失败: %v", name, err)
 	}
+	return path, nil
+}
 
-	// 检查命令行中是否包含 --watch 参数
-	for _, arg := range args {
-		if arg == "--watch" || arg == "-w" {
-			return true
+// shellQuote 以单引号包裹参数
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// lookupShellUser 解析运行受限 shell 的系统用户（用户名或 UID）。
+// shell 不能与服务进程同一用户，否则可读取 /proc 中服务进程的环境变量与配置文件
+func lookupShellUser(name string) (uint32, uint32, error) {
+	if name == "" {
+		return 0, 0, errors.New("未配置 kubectl 终端的运行用户（terminal.kubectl_shell_user）")
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		if u, err = user.LookupId(name); err != nil {
+			return 0, 0, fmt.Errorf("kubectl 终端运行用户 %s 不存在", name)
 		}
 	}
-
-	return false
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("kubectl 终端运行用户 %s 无效", name)
+	}
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("kubectl 终端运行用户 %s 无效", name)
+	}
+	if uid == 0 || int(uid) == os.Geteuid() {
+		return 0, 0, fmt.Errorf("kubectl 终端运行用户 %s 不能是 root 或服务进程自身的用户", name)
+	}
+	return uint32(uid), uint32(gid), nil
 }
 
-// handleSpecialCommands 处理特殊命令
-func (h *KubectlTerminalHandler) handleSpecialCommands(session *KubectlSession, command string) bool {
-	command = strings.TrimSpace(command)
-
-	switch {
-	case command == "clear" || command == "cls":
-		h.sendMessage(session.Conn, "clear", "")
-		h.sendMessage(session.Conn, "command_result", "")
-		return true
-	case command == "help" || command == "?":
-		h.sendHelpMessage(session)
-		return true
-	case command == "history":
-		h.sendHistoryMessage(session)
-		return true
-	case strings.HasPrefix(command, "ns "):
-		// 切换namespace的快捷命令
-		namespace := strings.TrimSpace(command[3:])
-		if namespace != "" {
-			session.Namespace = namespace
-			h.sendMessage(session.Conn, "namespace_changed", namespace)
+// writeShellKubeconfig 写入仅包含 ServiceAccount 令牌的 kubeconfig（不包含平台自身的集群凭据）
+func writeShellKubeconfig(path, clusterName string, restConfig *rest.Config, token, namespace string) error {
+	cfg := clientcmdapi.NewConfig()
+	cfg.Clusters[clusterName] = &clientcmdapi.Cluster{
+		Server:                   restConfig.Host,
+		CertificateAuthorityData: restConfig.CAData,
+		InsecureSkipTLSVerify:    restConfig.Insecure || len(restConfig.CAData) == 0,
+		TLSServerName:            restConfig.ServerName,
+	}
+	cfg.AuthInfos[clusterName] = &clientcmdapi.AuthInfo{Token: token}
+	cfg.Contexts[clusterName] = &clientcmdapi.Context{
+		Cluster:   clusterName,
+		AuthInfo:  clusterName,
+		Namespace: namespace,
+	}
+	cfg.CurrentContext = clusterName
+
+	// 保留用户在会话中切换的命名空间
+	if existing, err := clientcmd.LoadFromFile(path); err == nil {
+		if ctx, ok := existing.Contexts[existing.CurrentContext]; ok && ctx.Namespace != "" {
+			cfg.Contexts[clusterName].Namespace = ctx.Namespace
 		}
-		h.sendMessage(session.Conn, "command_result", "")
-		return true
 	}
-
-	return false
+	data, err := clientcmd.Write(*cfg)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
 }
 
-// commandNeedsNamespace 检查命令是否需要namespace
-func (h *KubectlTerminalHandler) commandNeedsNamespace(args []string) bool {
-	if len(args) == 0 {
-		return false
-	}
+// handleInput 处理用户输入（所有者与协同操作者共用）
+func (h *KubectlTerminalHandler) handleInput(session *KubectlSession, input string) {
+	session.Mutex.Lock()
+	defer session.Mutex.Unlock()
 
-	// 不需要namespace的命令
-	clusterCommands := []string{
-		"cluster-info", "version", "api-versions", "api-resources",
-		"get nodes", "get namespaces", "get ns", "get pv", "get sc",
-		"get clusterroles", "get clusterrolebindings",
+	if session.pty == nil {
+		return
 	}
 
-	command := strings.Join(args, " ")
-	for _, cmd := range clusterCommands {
-		if strings.HasPrefix(command, cmd) {
-			return false
+	// 命令执行前策略检查：拒绝的命令不会把回车写入终端
+	if session.guard != nil {
+		result := session.guard.Filter(input, extractShellCommand(session.currentLine.String()))
+		if result.notice != "" {
+			h.sendMessage(session, "data", result.notice)
+		}
+		if result.blocked {
+			session.currentLine.Reset()
+			session.pendingEnter = false
+		}
+		input = result.forward
+		if input == "" {
+			return
 		}
 	}
 
-	return true
-}
+	// 录像记录实际写入终端的输入（密码提示后的输入会被掩码）
+	session.replay.RecordInput([]byte(input))
 
-// hasNamespaceFlag 检查命令是否已经包含namespace参数
-func (h *KubectlTerminalHandler) hasNamespaceFlag(args []string) bool {
-	for _, arg := range args {
-		if arg == "-n" || arg == "--namespace" {
-			return true
-		}
-		if strings.HasPrefix(arg, "--namespace=") {
-			return true
-		}
-		if arg == "--all-namespaces" || arg == "-A" {
-			return true
-		}
+	if _, err := session.pty.Write([]byte(input)); err != nil {
+		h.sendMessage(session, "error", "写入输入失败")
+		return
+	}
+
+	if strings.ContainsAny(input, "\r\n") {
+		session.pendingEnter = true
+	} else if input == "\x03" {
+		session.currentLine.Reset()
 	}
-	return false
 }
 
-// sendHelpMessage 发送帮助信息
-func (h *KubectlTerminalHandler) sendHelpMessage(session *KubectlSession) {
-	helpText := `
-kubectl终端帮助信息:
-
-基本命令:
-  kubectl get pods              - 查看Pod列表
-  kubectl get nodes             - 查看节点列表
-  kubectl get svc               - 查看服务列表
-  kubectl get deployments      - 查看部署列表
-  kubectl describe pod <name>   - 查看Pod详情
-  kubectl logs <pod-name>       - 查看Pod日志
-  kubectl exec -it <pod> bash   - 进入Pod容器
-
-快捷命令:
-  clear/cls                     - 清屏
-  help/?                        - 显示帮助
-  history                       - 显示命令历史
-  ns <namespace>                - 切换命名空间
-
-提示:
-  - 可以省略kubectl前缀，系统会自动添加
-  - 使用Tab键可以自动补全(部分支持)
-  - 使用上下箭头键浏览历史命令
-  - 当前命名空间会自动应用到相关命令
-
-`
-	h.sendMessage(session.Conn, "output", helpText)
-	h.sendMessage(session.Conn, "command_result", "")
+// handleResize 处理终端大小调整
+func (h *KubectlTerminalHandler) handleResize(session *KubectlSession, cols, rows int) {
+	if session.pty == nil || cols <= 0 || rows <= 0 || cols > math.MaxUint16 || rows > math.MaxUint16 {
+		return
+	}
+	if err := pty.Setsize(session.pty, uint16(cols), uint16(rows)); err != nil {
+		logger.Warn("调整kubectl终端大小失败: %v", err)
+		return
+	}
+	session.replay.Resize(cols, rows)
+	session.live.Resize(cols, rows)
 }
 
-// sendHistoryMessage 发送历史命令
-func (h *KubectlTerminalHandler) sendHistoryMessage(session *KubectlSession) {
-	if len(session.History) == 0 {
-		h.sendMessage(session.Conn, "output", "暂无命令历史\n")
-	} else {
-		historyText := "命令历史:\n"
-		for i, cmd := range session.History {
-			historyText += fmt.Sprintf("  %d: %s\n", i+1, cmd)
+// readOutput 读取 PTY 输出并转发、录像、旁观广播
+func (h *KubectlTerminalHandler) readOutput(session *KubectlSession) {
+	buffer := make([]byte, 4096)
+	for {
+		n, err := session.pty.Read(buffer)
+		if n > 0 {
+			output := string(buffer[:n])
+			h.sendMessage(session, "data", output)
+			session.replay.Record(buffer[:n])
+			session.live.Broadcast(buffer[:n])
+			h.trackOutputForCommand(session, output)
+		}
+		if err != nil {
+			return
 		}
-		h.sendMessage(session.Conn, "output", historyText)
 	}
-	h.sendMessage(session.Conn, "command_result", "")
 }
 
-// handleInterrupt 处理中断信号
-func (h *KubectlTerminalHandler) handleInterrupt(session *KubectlSession) {
+// trackOutputForCommand 追踪终端回显，在回车后记录完整命令
+func (h *KubectlTerminalHandler) trackOutputForCommand(session *KubectlSession, output string) {
 	session.Mutex.Lock()
-	cmd := session.Cmd
-	session.LastCommand = ""
-	session.Mutex.Unlock()
-
-	// 发送中断信号到终端
-	h.sendMessage(session.Conn, "output", "^C\n")
-
-	// 如果有正在运行的命令，尝试终止它
-	if cmd != nil && cmd.Process != nil {
-		logger.Info("正在终止命令", "pid", cmd.Process.Pid)
-
-		// 在Windows上，Kill()可能不会立即终止进程，尝试使用taskkill
-		if runtime.GOOS == "windows" {
-			_ = exec.Command("taskkill", "/F", "/T", "/PID", strconv.Itoa(cmd.Process.Pid)).Run() // #nosec G204 -- PID 来自已知进程
-		} else {
-			// 在Unix系统上，发送SIGINT信号（等同于Ctrl+C）
-			_ = cmd.Process.Signal(syscall.SIGINT)
-
-			// 给进程一点时间响应SIGINT
-			time.Sleep(100 * time.Millisecond)
+	defer session.Mutex.Unlock()
 
-			// 如果进程仍在运行，强制终止
-			if cmd.ProcessState == nil || !cmd.ProcessState.Exited() {
-				_ = cmd.Process.Kill()
+	for _, c := range output {
+		switch {
+		case c == '\n':
+			line := session.currentLine.String()
+			session.currentLine.Reset()
+			if session.pendingEnter {
+				session.pendingEnter = false
+				if cmd := extractShellCommand(line); cmd != "" && h.auditService != nil && session.AuditSessionID > 0 {
+					h.auditService.RecordCommandAsync(session.AuditSessionID, cmd, cmd, nil)
+				}
+			}
+		case c == '\b':
+			s := session.currentLine.String()
+			if len(s) > 0 {
+				session.currentLine.Reset()
+				session.currentLine.WriteString(s[:len(s)-1])
 			}
+		case c == '\x1b' || c >= 32:
+			// 保留 ESC 以便 extractShellCommand 去除控制序列
+			session.currentLine.WriteRune(c)
 		}
 	}
-
-	h.sendMessage(session.Conn, "command_result", "")
 }
 
-// createTempKubeconfig 创建临时kubeconfig文件
-func (h *KubectlTerminalHandler) createTempKubeconfig(cluster *models.Cluster) (string, error) {
-	// 创建临时文件
-	tmpFile, err := os.CreateTemp("", "kubeconfig-*.yaml")
-	if err != nil {
-		return "", fmt.Errorf("创建临时文件失败: %v", err)
+// extractShellCommand 从回显行中去除控制序列与提示符，返回命令
+func extractShellCommand(line string) string {
+	line = stripTerminalEscapes(line)
+	if idx := strings.LastIndex(line, "kubectl$ "); idx >= 0 {
+		line = line[idx+len("kubectl$ "):]
 	}
-	defer func() {
-		_ = tmpFile.Close()
-	}()
+	return strings.TrimSpace(line)
+}
 
-	// 写入kubeconfig内容
-	var kubeconfigContent string
-	if cluster.KubeconfigEnc != "" {
-		kubeconfigContent = cluster.KubeconfigEnc
-	} else if cluster.SATokenEnc != "" {
-		// 从Token创建kubeconfig
-		kubeconfigContent = services.CreateKubeconfigFromToken(
-			cluster.Name,
-			cluster.APIServer,
-			cluster.SATokenEnc,
-			cluster.CAEnc,
-		)
-	} else {
-		return "", fmt.Errorf("集群缺少认证信息")
-	}
-
-	_, err = tmpFile.WriteString(kubeconfigContent)
-	if err != nil {
-		return "", fmt.Errorf("写入kubeconfig失败: %v", err)
+// stripTerminalEscapes 去除 ANSI 控制序列
+func stripTerminalEscapes(s string) string {
+	var b strings.Builder
+	inEscape := false
+	for _, c := range s {
+		if c == '\x1b' {
+			inEscape = true
+			continue
+		}
+		if inEscape {
+			if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '~' {
+				inEscape = false
+			}
+			continue
+		}
+		b.WriteRune(c)
 	}
-
-	return tmpFile.Name(), nil
+	return b.String()
 }
 
-// sendMessage 发送WebSocket消息
-func (h *KubectlTerminalHandler) sendMessage(conn *websocket.Conn, msgType, data string) {
-	msg := TerminalMessage{
-		Type: msgType,
-		Data: data,
+// closeSession 终止 shell 并清理会话目录
+func (h *KubectlTerminalHandler) closeSession(session *KubectlSession) {
+	session.Mutex.Lock()
+	defer session.Mutex.Unlock()
+	if session.cmd != nil {
+		// shell 是会话首进程，结束整个进程组以同时终止 kubectl 等子进程
+		_ = pty.Kill(session.cmd)
 	}
-
-	if err := conn.WriteJSON(msg); err != nil {
-		logger.Error("发送WebSocket消息失败", "error", err)
+	if session.pty != nil {
+		_ = session.pty.Close()
+	}
+	if session.workDir != "" {
+		_ = os.Chmod(filepath.Join(session.workDir, "bin"), 0750)
+		_ = os.RemoveAll(session.workDir)
 	}
 }
 
-// mustParseUint 解析uint，失败时panic
-func mustParseUint(s string) uint64 {
-	val, err := strconv.ParseUint(s, 10, 32)
-	if err != nil {
-		panic(fmt.Sprintf("解析uint失败: %v", err))
+// sendMessage 发送WebSocket消息
+func (h *KubectlTerminalHandler) sendMessage(session *KubectlSession, msgType, data string) {
+	session.writeMu.Lock()
+	defer session.writeMu.Unlock()
+	if err := session.Conn.WriteJSON(PodTerminalMessage{Type: msgType, Data: data}); err != nil {
+		logger.Error("发送WebSocket消息失败", "error", err)
 	}
-	return val
 }
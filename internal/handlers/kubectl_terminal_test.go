@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestExtractShellCommand(t *testing.T) {
+	line := "\x1b[1;32mprod\x1b[0m:\x1b[1;34mkubectl\x1b[0m$ kubectl get pods -n kube-system | grep dns"
+	assert.Equal(t, "kubectl get pods -n kube-system | grep dns", extractShellCommand(line))
+	assert.Equal(t, "", extractShellCommand("prod:kubectl$ "))
+}
+
+func TestWriteShellKubeconfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	restConfig := &rest.Config{Host: "https://10.0.0.1:6443"}
+	restConfig.CAData = []byte("ca")
+
+	require.NoError(t, writeShellKubeconfig(path, "prod", restConfig, "token-1", "default"))
+	cfg, err := clientcmd.LoadFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", cfg.AuthInfos["prod"].Token)
+	assert.Empty(t, cfg.AuthInfos["prod"].Impersonate)
+	assert.Equal(t, "default", cfg.Contexts["prod"].Namespace)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// namespace switched inside the session survives a token refresh
+	cfg.Contexts["prod"].Namespace = "monitoring"
+	require.NoError(t, clientcmd.WriteToFile(*cfg, path))
+	require.NoError(t, writeShellKubeconfig(path, "prod", restConfig, "token-2", "default"))
+	cfg, err = clientcmd.LoadFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", cfg.AuthInfos["prod"].Token)
+	assert.Equal(t, "monitoring", cfg.Contexts["prod"].Namespace)
+}
+
+func TestLinkShellToolsWrapsEditors(t *testing.T) {
+	fakeBin := t.TempDir()
+	for _, name := range []string{"kubectl", "vim", "vi", "tr"} {
+		require.NoError(t, os.WriteFile(filepath.Join(fakeBin, name), []byte("#!/bin/sh\n"), 0755))
+	}
+	t.Setenv("PATH", fakeBin)
+
+	workDir := t.TempDir()
+	binDir := filepath.Join(workDir, "bin")
+	editor, err := linkShellTools(binDir, workDir, []string{"kubectl", "vi", "vim", "tr", "../sh"})
+	require.NoError(t, err)
+	assert.Equal(t, "vim", editor)
+
+	// vi 与 vim 都是以 -Z 启动 vim 的脚本，不链接到原始命令
+	for _, name := range []string{"vi", "vim"} {
+		info, err := os.Lstat(filepath.Join(binDir, name))
+		require.NoError(t, err)
+		assert.True(t, info.Mode().IsRegular(), name)
+		script, err := os.ReadFile(filepath.Join(binDir, name))
+		require.NoError(t, err)
+		assert.Equal(t, "#!/bin/sh\nexec '"+filepath.Join(fakeBin, "vim")+"' '-Z' \"$@\"\n", string(script))
+	}
+	target, err := os.Readlink(filepath.Join(binDir, "tr"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(fakeBin, "tr"), target)
+	_, err = os.Lstat(filepath.Join(binDir, "sh"))
+	assert.True(t, os.IsNotExist(err))
+
+	// 没有 vim 时不提供编辑器
+	require.NoError(t, os.Remove(filepath.Join(fakeBin, "vim")))
+	workDir = t.TempDir()
+	editor, err = linkShellTools(filepath.Join(workDir, "bin"), workDir, []string{"kubectl", "vi"})
+	require.NoError(t, err)
+	assert.Empty(t, editor)
+}
+
+func TestLinkShellToolsRestrictsFileReaders(t *testing.T) {
+	realpath, err := exec.LookPath("realpath")
+	if err != nil {
+		t.Skip("realpath not installed")
+	}
+	fakeBin := t.TempDir()
+	require.NoError(t, os.Symlink(realpath, filepath.Join(fakeBin, "realpath")))
+	require.NoError(t, os.WriteFile(filepath.Join(fakeBin, "kubectl"), []byte("#!/bin/sh\n"), 0755))
+	for _, name := range []string{"head", "yq"} {
+		require.NoError(t, os.WriteFile(filepath.Join(fakeBin, name), []byte("#!/bin/sh\necho ok\n"), 0755))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(fakeBin, "helm"), []byte("#!/bin/sh\necho \"$HELM_PLUGINS\"\n"), 0755))
+	t.Setenv("PATH", fakeBin)
+
+	workDir := t.TempDir()
+	binDir := filepath.Join(workDir, "bin")
+	_, err = linkShellTools(binDir, workDir, []string{"kubectl", "head", "yq", "helm"})
+	require.NoError(t, err)
+	inside := filepath.Join(workDir, "pods.yaml")
+	require.NoError(t, os.WriteFile(inside, []byte("kind: Pod\n"), 0600))
+	outside := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(outside, []byte("password\n"), 0600))
+
+	run := func(tool string, args ...string) (string, error) {
+		out, err := exec.Command(filepath.Join(binDir, tool), args...).CombinedOutput() // #nosec G204 -- 测试脚本
+		return string(out), err
+	}
+	out, err := run("head", "-n", "1", inside)
+	require.NoError(t, err, out)
+	assert.Equal(t, "ok\n", out)
+	for _, args := range [][]string{{outside}, {"-n1", "--", outside}, {"--file=" + outside}, {"-f" + outside}, {filepath.Join(workDir, "..")}} {
+		out, err := run("head", args...)
+		assert.Error(t, err, "%v should be rejected", args)
+		assert.Contains(t, out, "只能读取会话目录内的文件", args)
+	}
+	out, err = run("yq", `load("`+inside+`")`)
+	assert.Error(t, err, out)
+
+	// helm 的插件目录固定为会话内的只读空目录
+	out, err = run("helm", "list")
+	require.NoError(t, err, out)
+	assert.Equal(t, filepath.Join(workDir, "helm")+"\n", out)
+}
+
+func TestLookupShellUser(t *testing.T) {
+	_, _, err := lookupShellUser("")
+	assert.Error(t, err)
+	_, _, err = lookupShellUser("root")
+	assert.Error(t, err)
+	_, _, err = lookupShellUser(strconv.Itoa(os.Geteuid()))
+	assert.Error(t, err)
+	_, _, err = lookupShellUser("no-such-user-kubepolaris")
+	assert.Error(t, err)
+
+	if _, err := user.Lookup("nobody"); err == nil && os.Geteuid() != 65534 {
+		uid, _, err := lookupShellUser("nobody")
+		require.NoError(t, err)
+		assert.NotZero(t, uid)
+	}
+}
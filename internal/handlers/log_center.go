@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
@@ -57,7 +58,7 @@ func NewLogCenterHandler(clusterSvc *services.ClusterService, k8sMgr *k8s.Cluste
 func (h *LogCenterHandler) GetContainerLogs(c *gin.Context) {
 	clusterID, err := parseClusterID(c.Param("clusterID"))
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	namespace := c.Query("namespace")
@@ -68,13 +69,13 @@ func (h *LogCenterHandler) GetContainerLogs(c *gin.Context) {
 	previous := c.Query("previous") == "true"
 
 	if namespace == "" || podName == "" {
-		response.BadRequest(c, "namespace 和 pod 参数必填")
+		response.FailCode(c, errcode.NamespaceAndPodParamsRequired)
 		return
 	}
 
 	cluster, err := h.clusterSvc.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -83,7 +84,7 @@ func (h *LogCenterHandler) GetContainerLogs(c *gin.Context) {
 
 	logs, err := h.aggregator.GetContainerLogs(ctx, cluster, namespace, podName, container, tailLines, sinceSeconds, previous)
 	if err != nil {
-		response.InternalError(c, "获取日志失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.LogsFailed))
 		return
 	}
 
@@ -96,7 +97,7 @@ func (h *LogCenterHandler) GetContainerLogs(c *gin.Context) {
 func (h *LogCenterHandler) GetEventLogs(c *gin.Context) {
 	clusterID, err := parseClusterID(c.Param("clusterID"))
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	namespace := c.Query("namespace")
@@ -107,14 +108,14 @@ func (h *LogCenterHandler) GetEventLogs(c *gin.Context) {
 
 	cluster, err := h.clusterSvc.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -142,7 +143,7 @@ func (h *LogCenterHandler) GetEventLogs(c *gin.Context) {
 
 	events, err := k8sClient.GetClientset().CoreV1().Events(namespace).List(ctx, listOpts)
 	if err != nil {
-		response.InternalError(c, "获取事件失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Event"))
 		return
 	}
 
@@ -177,19 +178,19 @@ func (h *LogCenterHandler) GetEventLogs(c *gin.Context) {
 func (h *LogCenterHandler) SearchLogs(c *gin.Context) {
 	clusterID, err := parseClusterID(c.Param("clusterID"))
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	var query models.LogQuery
 	if err := c.ShouldBindJSON(&query); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	cluster, err := h.clusterSvc.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -198,7 +199,7 @@ func (h *LogCenterHandler) SearchLogs(c *gin.Context) {
 
 	results, total, err := h.aggregator.SearchLogs(ctx, cluster, &query)
 	if err != nil {
-		response.InternalError(c, "搜索失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.SearchFailed))
 		return
 	}
 
@@ -209,7 +210,7 @@ func (h *LogCenterHandler) SearchLogs(c *gin.Context) {
 func (h *LogCenterHandler) GetLogStats(c *gin.Context) {
 	clusterID, err := parseClusterID(c.Param("clusterID"))
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	namespace := c.Query("namespace")
@@ -217,7 +218,7 @@ func (h *LogCenterHandler) GetLogStats(c *gin.Context) {
 
 	cluster, err := h.clusterSvc.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -240,7 +241,7 @@ func (h *LogCenterHandler) GetLogStats(c *gin.Context) {
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -250,7 +251,7 @@ func (h *LogCenterHandler) GetLogStats(c *gin.Context) {
 	// 获取事件统计
 	events, err := k8sClient.GetClientset().CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		response.InternalError(c, "获取事件失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Event"))
 		return
 	}
 
@@ -306,13 +307,13 @@ func (h *LogCenterHandler) GetLogStats(c *gin.Context) {
 func (h *LogCenterHandler) HandleAggregateLogStream(c *gin.Context) {
 	clusterID, err := parseClusterID(c.Param("clusterID"))
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterSvc.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -405,7 +406,7 @@ func (h *LogCenterHandler) HandleAggregateLogStream(c *gin.Context) {
 func (h *LogCenterHandler) HandleSinglePodLogStream(c *gin.Context) {
 	clusterID, err := parseClusterID(c.Param("clusterID"))
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	namespace := c.Param("namespace")
@@ -417,7 +418,7 @@ func (h *LogCenterHandler) HandleSinglePodLogStream(c *gin.Context) {
 
 	cluster, err := h.clusterSvc.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -545,13 +546,13 @@ func (h *LogCenterHandler) HandleSinglePodLogStream(c *gin.Context) {
 func (h *LogCenterHandler) GetNamespacesForLogs(c *gin.Context) {
 	clusterID, err := parseClusterID(c.Param("clusterID"))
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterSvc.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -560,7 +561,7 @@ func (h *LogCenterHandler) GetNamespacesForLogs(c *gin.Context) {
 
 	// 确保 informer 缓存就绪
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 
@@ -568,7 +569,7 @@ func (h *LogCenterHandler) GetNamespacesForLogs(c *gin.Context) {
 	sel := labels.Everything()
 	pods, err := h.k8sMgr.PodsLister(cluster.ID).List(sel)
 	if err != nil {
-		response.InternalError(c, "获取命名空间失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Namespace"))
 		return
 	}
 
@@ -591,14 +592,14 @@ func (h *LogCenterHandler) GetNamespacesForLogs(c *gin.Context) {
 func (h *LogCenterHandler) GetPodsForLogs(c *gin.Context) {
 	clusterID, err := parseClusterID(c.Param("clusterID"))
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	namespace := c.Query("namespace")
 
 	cluster, err := h.clusterSvc.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -607,7 +608,7 @@ func (h *LogCenterHandler) GetPodsForLogs(c *gin.Context) {
 
 	// 确保 informer 缓存就绪
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 
@@ -622,7 +623,7 @@ func (h *LogCenterHandler) GetPodsForLogs(c *gin.Context) {
 	}
 
 	if err != nil {
-		response.InternalError(c, "获取Pod列表失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Pod"))
 		return
 	}
 
@@ -659,19 +660,19 @@ func (h *LogCenterHandler) GetPodsForLogs(c *gin.Context) {
 func (h *LogCenterHandler) ExportLogs(c *gin.Context) {
 	clusterID, err := parseClusterID(c.Param("clusterID"))
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	var query models.LogQuery
 	if err := c.ShouldBindJSON(&query); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	cluster, err := h.clusterSvc.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -685,7 +686,7 @@ func (h *LogCenterHandler) ExportLogs(c *gin.Context) {
 
 	results, _, err := h.aggregator.SearchLogs(ctx, cluster, &query)
 	if err != nil {
-		response.InternalError(c, "获取日志失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.LogsFailed))
 		return
 	}
 
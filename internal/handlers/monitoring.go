@@ -3,6 +3,7 @@ package handlers
 import (
 	"strconv"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
@@ -30,14 +31,14 @@ func (h *MonitoringHandler) GetMonitoringConfig(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	config, err := h.monitoringConfigService.GetMonitoringConfig(uint(clusterID))
 	if err != nil {
 		logger.Error("获取监控配置失败", "error", err)
-		response.InternalError(c, "获取监控配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.MonitoringConfigFailed))
 		return
 	}
 
@@ -49,20 +50,20 @@ func (h *MonitoringHandler) UpdateMonitoringConfig(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	var config models.MonitoringConfig
 	if err := c.ShouldBindJSON(&config); err != nil {
-		response.BadRequest(c, "请求参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	// 更新配置
 	if err := h.monitoringConfigService.UpdateMonitoringConfig(uint(clusterID), &config); err != nil {
 		logger.Error("更新监控配置失败", "error", err)
-		response.InternalError(c, "更新监控配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.MonitoringUpdateFailed))
 		return
 	}
 
@@ -74,20 +75,20 @@ func (h *MonitoringHandler) TestMonitoringConnection(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	_, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	var config models.MonitoringConfig
 	if err := c.ShouldBindJSON(&config); err != nil {
-		response.BadRequest(c, "请求参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	// 测试连接
 	if err := h.prometheusService.TestConnection(c.Request.Context(), &config); err != nil {
 		logger.Error("测试监控连接失败", "error", err)
-		response.BadRequest(c, "连接测试失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ConnectionTestFailed))
 		return
 	}
 
@@ -99,7 +100,7 @@ func (h *MonitoringHandler) GetClusterMetrics(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -107,7 +108,7 @@ func (h *MonitoringHandler) GetClusterMetrics(c *gin.Context) {
 	config, err := h.monitoringConfigService.GetMonitoringConfig(uint(clusterID))
 	if err != nil {
 		logger.Error("获取监控配置失败", "error", err)
-		response.InternalError(c, "获取监控配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.MonitoringConfigFailed))
 		return
 	}
 
@@ -125,7 +126,7 @@ func (h *MonitoringHandler) GetClusterMetrics(c *gin.Context) {
 	metrics, err := h.prometheusService.QueryClusterMetrics(c.Request.Context(), config, clusterName, timeRange, step)
 	if err != nil {
 		logger.Error("查询集群监控指标失败", "error", err)
-		response.InternalError(c, "查询监控指标失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.MetricsQueryFailed))
 		return
 	}
 
@@ -137,13 +138,13 @@ func (h *MonitoringHandler) GetNodeMetrics(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	nodeName := c.Param("nodeName")
 	if nodeName == "" {
-		response.BadRequest(c, "节点名称不能为空")
+		response.FailCode(c, errcode.NodeNameRequired)
 		return
 	}
 
@@ -151,7 +152,7 @@ func (h *MonitoringHandler) GetNodeMetrics(c *gin.Context) {
 	config, err := h.monitoringConfigService.GetMonitoringConfig(uint(clusterID))
 	if err != nil {
 		logger.Error("获取监控配置失败", "error", err)
-		response.InternalError(c, "获取监控配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.MonitoringConfigFailed))
 		return
 	}
 
@@ -169,7 +170,7 @@ func (h *MonitoringHandler) GetNodeMetrics(c *gin.Context) {
 	metrics, err := h.prometheusService.QueryNodeMetrics(c.Request.Context(), config, clusterName, nodeName, timeRange, step)
 	if err != nil {
 		logger.Error("查询节点监控指标失败", "error", err)
-		response.InternalError(c, "查询监控指标失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.MetricsQueryFailed))
 		return
 	}
 
@@ -181,14 +182,14 @@ func (h *MonitoringHandler) GetPodMetrics(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	namespace := c.Param("namespace")
 	podName := c.Param("name")
 	if namespace == "" || podName == "" {
-		response.BadRequest(c, "命名空间和Pod名称不能为空")
+		response.FailCode(c, errcode.NamespaceAndPodRequired)
 		return
 	}
 
@@ -196,7 +197,7 @@ func (h *MonitoringHandler) GetPodMetrics(c *gin.Context) {
 	config, err := h.monitoringConfigService.GetMonitoringConfig(uint(clusterID))
 	if err != nil {
 		logger.Error("获取监控配置失败", "error", err)
-		response.InternalError(c, "获取监控配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.MonitoringConfigFailed))
 		return
 	}
 
@@ -214,7 +215,7 @@ func (h *MonitoringHandler) GetPodMetrics(c *gin.Context) {
 	metrics, err := h.prometheusService.QueryPodMetrics(c.Request.Context(), config, clusterName, namespace, podName, timeRange, step)
 	if err != nil {
 		logger.Error("查询Pod监控指标失败", "error", err)
-		response.InternalError(c, "查询监控指标失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.MetricsQueryFailed))
 		return
 	}
 
@@ -226,14 +227,14 @@ func (h *MonitoringHandler) GetWorkloadMetrics(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	namespace := c.Param("namespace")
 	workloadName := c.Param("name")
 	if namespace == "" || workloadName == "" {
-		response.BadRequest(c, "命名空间和工作负载名称不能为空")
+		response.FailCode(c, errcode.NamespaceAndWorkloadRequired)
 		return
 	}
 
@@ -241,7 +242,7 @@ func (h *MonitoringHandler) GetWorkloadMetrics(c *gin.Context) {
 	config, err := h.monitoringConfigService.GetMonitoringConfig(uint(clusterID))
 	if err != nil {
 		logger.Error("获取监控配置失败", "error", err)
-		response.InternalError(c, "获取监控配置失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.MonitoringConfigFailed))
 		return
 	}
 
@@ -259,7 +260,7 @@ func (h *MonitoringHandler) GetWorkloadMetrics(c *gin.Context) {
 	metrics, err := h.prometheusService.QueryWorkloadMetrics(c.Request.Context(), config, clusterName, namespace, workloadName, timeRange, step)
 	if err != nil {
 		logger.Error("查询工作负载监控指标失败", "error", err)
-		response.InternalError(c, "查询监控指标失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.MetricsQueryFailed))
 		return
 	}
 
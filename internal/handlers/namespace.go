@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
@@ -50,12 +51,12 @@ func (h *NamespaceHandler) GetNamespaces(c *gin.Context) {
 	// 获取集群信息
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ClusterNotFound))
 		return
 	}
 
@@ -63,14 +64,14 @@ func (h *NamespaceHandler) GetNamespaces(c *gin.Context) {
 	defer cancel()
 
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 
 	// 获取命名空间列表
 	namespaces, err := h.k8sMgr.NamespacesLister(clusterID).List(labels.Everything())
 	if err != nil {
-		response.InternalError(c, "读取命名空间缓存失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceCacheFailed, "Namespace"))
 		return
 	}
 
@@ -112,26 +113,26 @@ func (h *NamespaceHandler) GetNamespaceDetail(c *gin.Context) {
 
 	// 检查命名空间访问权限
 	if !middleware.HasNamespaceAccess(c, namespaceName) {
-		response.Forbidden(c, "无权访问命名空间: "+namespaceName)
+		response.FailCode(c, errcode.NamespaceAccessDenied)
 		return
 	}
 
 	// 获取集群信息
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ClusterNotFound))
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -140,7 +141,7 @@ func (h *NamespaceHandler) GetNamespaceDetail(c *gin.Context) {
 	// 获取命名空间详情
 	namespace, err := clientset.CoreV1().Namespaces().Get(context.TODO(), namespaceName, metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "命名空间不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "Namespace"))
 		return
 	}
 
@@ -189,19 +190,19 @@ func (h *NamespaceHandler) CreateNamespace(c *gin.Context) {
 	// 检查是否有管理员权限（只有管理员才能创建命名空间）
 	permission := middleware.GetClusterPermission(c)
 	if permission == nil || permission.PermissionType != "admin" {
-		response.Forbidden(c, "只有管理员才能创建命名空间")
+		response.FailCode(c, errcode.NamespaceCreateDenied)
 		return
 	}
 
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ClusterNotFound))
 		return
 	}
 
@@ -212,14 +213,14 @@ func (h *NamespaceHandler) CreateNamespace(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -234,12 +235,14 @@ func (h *NamespaceHandler) CreateNamespace(c *gin.Context) {
 		},
 	}
 
+	snap := beginSnapshot(c, k8sClient, gvrNamespaces, "", req.Name)
 	// 创建命名空间
 	createdNs, err := clientset.CoreV1().Namespaces().Create(context.TODO(), namespace, metav1.CreateOptions{})
 	if err != nil {
-		response.InternalError(c, "创建命名空间失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceCreateFailed, "Namespace"))
 		return
 	}
+	snap.Done()
 
 	response.OK(c, NamespaceResponse{
 		Name:              createdNs.Name,
@@ -255,7 +258,7 @@ func (h *NamespaceHandler) DeleteNamespace(c *gin.Context) {
 	// 检查是否有管理员权限（只有管理员才能删除命名空间）
 	permission := middleware.GetClusterPermission(c)
 	if permission == nil || permission.PermissionType != "admin" {
-		response.Forbidden(c, "只有管理员才能删除命名空间")
+		response.FailCode(c, errcode.NamespaceDeleteDenied)
 		return
 	}
 
@@ -265,30 +268,32 @@ func (h *NamespaceHandler) DeleteNamespace(c *gin.Context) {
 	// 获取集群信息
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ClusterNotFound))
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
 	clientset := k8sClient.GetClientset()
 
+	snap := beginSnapshot(c, k8sClient, gvrNamespaces, "", namespaceName)
 	// 删除命名空间
 	err = clientset.CoreV1().Namespaces().Delete(context.TODO(), namespaceName, metav1.DeleteOptions{})
 	if err != nil {
-		response.InternalError(c, "删除命名空间失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceDeleteFailed, "Namespace"))
 		return
 	}
+	snap.Deleted()
 
 	response.NoContent(c)
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
@@ -47,31 +48,31 @@ func (h *NodeHandler) GetNodes(c *gin.Context) {
 	// 从集群服务获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	if clusterID == 0 {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 使用 informer+lister 获取节点列表
 	if h.k8sMgr == nil {
-		response.ServiceUnavailable(c, "K8s informer 管理器未初始化")
+		response.FailCode(c, errcode.InformerManagerUnavailable)
 		return
 	}
 	if _, err := h.k8sMgr.EnsureAndWait(context.Background(), cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 	nodeObjs, err := h.k8sMgr.NodesLister(cluster.ID).List(labels.Everything())
 	if err != nil {
-		response.InternalError(c, "读取节点缓存失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceCacheFailed, "Node"))
 		return
 	}
 	// 转为值类型以复用原有处理逻辑
@@ -196,25 +197,25 @@ func (h *NodeHandler) GetNodeOverview(c *gin.Context) {
 	// 从集群服务获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 使用 informer+lister 读取节点并统计
 	if _, err := h.k8sMgr.EnsureAndWait(context.Background(), cluster, 5*time.Second); err != nil {
 		logger.Error("informer 未就绪", "error", err)
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 	nodeObjs, err := h.k8sMgr.NodesLister(cluster.ID).List(labels.Everything())
 	if err != nil {
 		logger.Error("读取节点缓存失败", "error", err)
-		response.InternalError(c, "读取节点缓存失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceCacheFailed, "Node"))
 		return
 	}
 	totalNodes := len(nodeObjs)
@@ -277,27 +278,27 @@ func (h *NodeHandler) GetNode(c *gin.Context) {
 	// 从集群服务获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 使用 informer+lister 获取节点详情
 	if h.k8sMgr == nil {
-		response.ServiceUnavailable(c, "K8s informer 管理器未初始化")
+		response.FailCode(c, errcode.InformerManagerUnavailable)
 		return
 	}
 	if _, err := h.k8sMgr.EnsureAndWait(context.Background(), cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 	node, err := h.k8sMgr.NodesLister(cluster.ID).Get(name)
 	if err != nil {
-		response.InternalError(c, "读取节点缓存失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceCacheFailed, "Node"))
 		return
 	}
 
@@ -421,28 +422,30 @@ func (h *NodeHandler) CordonNode(c *gin.Context) {
 	// 从集群服务获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
+	snap := beginSnapshot(c, k8sClient, gvrNodes, "", name)
 	// 封锁节点
 	err = k8sClient.CordonNode(name)
 	if err != nil {
-		response.InternalError(c, "封锁节点失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.NodeCordonFailed))
 		return
 	}
+	snap.Done()
 
 	response.NoContent(c)
 }
@@ -456,28 +459,30 @@ func (h *NodeHandler) UncordonNode(c *gin.Context) {
 	// 从集群服务获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
+	snap := beginSnapshot(c, k8sClient, gvrNodes, "", name)
 	// 解封节点
 	err = k8sClient.UncordonNode(name)
 	if err != nil {
-		response.InternalError(c, "解封节点失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.NodeUncordonFailed))
 		return
 	}
+	snap.Done()
 
 	response.NoContent(c)
 }
@@ -491,33 +496,33 @@ func (h *NodeHandler) DrainNode(c *gin.Context) {
 	// 解析请求参数
 	var options map[string]interface{}
 	if err := c.ShouldBindJSON(&options); err != nil {
-		response.BadRequest(c, "参数解析失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	// 从集群服务获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
 	// 驱逐节点
 	err = k8sClient.DrainNode(name, options)
 	if err != nil {
-		response.InternalError(c, "驱逐节点失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.NodeDrainFailed))
 		return
 	}
 
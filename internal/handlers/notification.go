@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+// NotificationHandler 事件通知处理器
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+// NewNotificationHandler 创建事件通知处理器
+func NewNotificationHandler(notificationService *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// GetConfig 获取通知渠道与订阅配置（密钥、密码以 ****** 返回）
+func (h *NotificationHandler) GetConfig(c *gin.Context) {
+	config, err := h.notificationService.GetConfig()
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.NotificationConfigFailed))
+		return
+	}
+	response.OK(c, config)
+}
+
+// UpdateConfig 更新通知渠道与订阅配置
+func (h *NotificationHandler) UpdateConfig(c *gin.Context) {
+	var config models.NotificationConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+	if err := h.notificationService.SaveConfig(&config); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	h.GetConfig(c)
+}
+
+// ListEvents 可订阅的事件
+func (h *NotificationHandler) ListEvents(c *gin.Context) {
+	response.OK(c, models.NotifyEventNames)
+}
+
+// TestChannel 向渠道发送测试通知（使用已保存的配置）
+func (h *NotificationHandler) TestChannel(c *gin.Context) {
+	if err := h.notificationService.TestChannel(c.Param("name")); err != nil {
+		if errcode.Is(err, errcode.NotificationChannelNotFound) {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.Wrap(err, errcode.NotificationTestFailed))
+		return
+	}
+	response.OK(c, nil)
+}
+
+// ListDeliveries 通知投递记录
+// 查询参数: status, channel, eventType, page, pageSize
+func (h *NotificationHandler) ListDeliveries(c *gin.Context) {
+	req := &services.NotificationDeliveryListRequest{
+		Status:    c.Query("status"),
+		Channel:   c.Query("channel"),
+		EventType: c.Query("eventType"),
+		Page:      getIntParam(c, "page", 1),
+		PageSize:  getIntParam(c, "pageSize", 20),
+	}
+	items, total, err := h.notificationService.ListDeliveries(req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.PagedList(c, items, total, req.Page, req.PageSize)
+}
+
+// RetryDelivery 重新发送失败的投递
+func (h *NotificationHandler) RetryDelivery(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidDeliveryID)
+		return
+	}
+	if err := h.notificationService.RetryDelivery(uint(id)); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
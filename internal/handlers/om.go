@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
@@ -41,14 +42,14 @@ func (h *OMHandler) GetHealthDiagnosis(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	// 获取集群信息
 	cluster, err := h.clusterSvc.GetCluster(uint(clusterID))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -56,13 +57,13 @@ func (h *OMHandler) GetHealthDiagnosis(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err)
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
 	clientset := k8sClient.GetClientset()
 	if clientset == nil {
-		response.InternalError(c, "获取K8s客户端失败")
+		response.FailCode(c, errcode.K8sClientFailed)
 		return
 	}
 
@@ -70,7 +71,7 @@ func (h *OMHandler) GetHealthDiagnosis(c *gin.Context) {
 	result, err := h.omSvc.GetHealthDiagnosis(c.Request.Context(), clientset, uint(clusterID))
 	if err != nil {
 		logger.Error("执行健康诊断失败", "error", err)
-		response.InternalError(c, "执行健康诊断失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.HealthDiagnosisFailed))
 		return
 	}
 
@@ -93,14 +94,14 @@ func (h *OMHandler) GetResourceTop(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	// 解析请求参数
 	var req models.ResourceTopRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		response.BadRequest(c, "请求参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -112,7 +113,7 @@ func (h *OMHandler) GetResourceTop(c *gin.Context) {
 	// 获取集群信息
 	cluster, err := h.clusterSvc.GetCluster(uint(clusterID))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -120,13 +121,13 @@ func (h *OMHandler) GetResourceTop(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err)
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
 	clientset := k8sClient.GetClientset()
 	if clientset == nil {
-		response.InternalError(c, "获取K8s客户端失败")
+		response.FailCode(c, errcode.K8sClientFailed)
 		return
 	}
 
@@ -134,7 +135,7 @@ func (h *OMHandler) GetResourceTop(c *gin.Context) {
 	result, err := h.omSvc.GetResourceTop(c.Request.Context(), clientset, uint(clusterID), &req)
 	if err != nil {
 		logger.Error("获取资源Top N失败", "error", err)
-		response.InternalError(c, "获取资源Top N失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceTopFailed))
 		return
 	}
 
@@ -154,14 +155,14 @@ func (h *OMHandler) GetControlPlaneStatus(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	// 获取集群信息
 	cluster, err := h.clusterSvc.GetCluster(uint(clusterID))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -169,13 +170,13 @@ func (h *OMHandler) GetControlPlaneStatus(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err)
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
 	clientset := k8sClient.GetClientset()
 	if clientset == nil {
-		response.InternalError(c, "获取K8s客户端失败")
+		response.FailCode(c, errcode.K8sClientFailed)
 		return
 	}
 
@@ -183,7 +184,7 @@ func (h *OMHandler) GetControlPlaneStatus(c *gin.Context) {
 	result, err := h.omSvc.GetControlPlaneStatus(c.Request.Context(), clientset, uint(clusterID))
 	if err != nil {
 		logger.Error("获取控制面状态失败", "error", err)
-		response.InternalError(c, "获取控制面状态失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ControlPlaneStatusFailed))
 		return
 	}
 
@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/constants"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
 )
@@ -25,6 +26,17 @@ func NewOperationLogHandler(opLogSvc *services.OperationLogService) *OperationLo
 
 // GetOperationLogs 获取操作日志列表
 func (h *OperationLogHandler) GetOperationLogs(c *gin.Context) {
+	resp, err := h.opLogSvc.List(parseOperationLogListRequest(c))
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.OperationLogsFailed))
+		return
+	}
+
+	response.OK(c, resp)
+}
+
+// parseOperationLogListRequest 解析操作日志列表查询参数
+func parseOperationLogListRequest(c *gin.Context) *services.OperationLogListRequest {
 	req := &services.OperationLogListRequest{
 		Page:         getIntParam(c, "page", 1),
 		PageSize:     getIntParam(c, "pageSize", 20),
@@ -51,6 +63,14 @@ func (h *OperationLogHandler) GetOperationLogs(c *gin.Context) {
 		}
 	}
 
+	// 解析租户ID
+	if tenantIDStr := c.Query("tenantId"); tenantIDStr != "" {
+		if tid, err := strconv.ParseUint(tenantIDStr, 10, 32); err == nil {
+			tidVal := uint(tid)
+			req.TenantID = &tidVal
+		}
+	}
+
 	// 解析成功/失败
 	if successStr := c.Query("success"); successStr != "" {
 		successVal := successStr == "true"
@@ -69,13 +89,7 @@ func (h *OperationLogHandler) GetOperationLogs(c *gin.Context) {
 		}
 	}
 
-	resp, err := h.opLogSvc.List(req)
-	if err != nil {
-		response.InternalError(c, "获取操作日志失败: "+err.Error())
-		return
-	}
-
-	response.OK(c, resp)
+	return req
 }
 
 // GetOperationLog 获取操作日志详情
@@ -83,13 +97,13 @@ func (h *OperationLogHandler) GetOperationLog(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的日志ID")
+		response.FailCode(c, errcode.InvalidLogID)
 		return
 	}
 
 	log, err := h.opLogSvc.GetDetail(uint(id))
 	if err != nil {
-		response.NotFound(c, "日志不存在")
+		response.FailCode(c, errcode.OperationLogNotFound)
 		return
 	}
 
@@ -113,7 +127,7 @@ func (h *OperationLogHandler) GetOperationLogStats(c *gin.Context) {
 
 	stats, err := h.opLogSvc.GetStats(startTime, endTime)
 	if err != nil {
-		response.InternalError(c, "获取统计信息失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AuditStatsFailed))
 		return
 	}
 
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
+	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/resourcesnapshot"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+)
+
+// OperationSnapshotHandler 操作日志对象快照处理器（撤销变更）
+type OperationSnapshotHandler struct {
+	opLogSvc       *services.OperationLogService
+	clusterService *services.ClusterService
+	freezeService  *services.FreezeService
+	policyService  *services.PolicyService
+	changeService  *services.ChangeRequestService
+	k8sMgr         *k8s.ClusterInformerManager
+}
+
+// snapshotPolicyResources 快照资源名与路由资源名不一致时的映射，权限策略与审批策略按路由资源名配置
+var snapshotPolicyResources = map[string]string{
+	"persistentvolumeclaims": "pvcs",
+	"persistentvolumes":      "pvs",
+}
+
+// NewOperationSnapshotHandler 创建操作日志对象快照处理器
+func NewOperationSnapshotHandler(opLogSvc *services.OperationLogService, clusterService *services.ClusterService, freezeService *services.FreezeService,
+	policyService *services.PolicyService, changeService *services.ChangeRequestService, k8sMgr *k8s.ClusterInformerManager) *OperationSnapshotHandler {
+	return &OperationSnapshotHandler{
+		opLogSvc:       opLogSvc,
+		clusterService: clusterService,
+		freezeService:  freezeService,
+		policyService:  policyService,
+		changeService:  changeService,
+		k8sMgr:         k8sMgr,
+	}
+}
+
+// RevertSnapshot 将对象恢复到该操作之前的状态
+// 对象在该操作之后又被修改过时返回 409 及差异字段，确认覆盖需携带 force=true
+// 撤销与集群路由下的写操作一样经过权限策略、双人审批与变更冻结检查：撤销创建操作即删除对象
+func (h *OperationSnapshotHandler) RevertSnapshot(c *gin.Context) {
+	logID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidLogID)
+		return
+	}
+	snapshotID, err := strconv.ParseUint(c.Param("snapshotId"), 10, 32)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidSnapshotID)
+		return
+	}
+
+	snap, err := h.opLogSvc.GetSnapshot(uint(logID), uint(snapshotID))
+	if err != nil {
+		response.FailCode(c, errcode.SnapshotNotFound)
+		return
+	}
+	switch {
+	case snap.Redacted:
+		response.FailCode(c, errcode.SnapshotRedacted)
+		return
+	case snap.RevertedAt != nil:
+		response.FailCode(c, errcode.SnapshotReverted)
+		return
+	}
+
+	cluster, err := h.clusterService.GetCluster(snap.ClusterID)
+	if err != nil {
+		response.FailCode(c, errcode.ClusterNotFound)
+		return
+	}
+	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
+		return
+	}
+	dyn, err := k8sClient.GetDynamicClient()
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
+		return
+	}
+
+	c.Set("cluster_name", cluster.Name)
+	c.Set(middleware.AuditDetailKey, gin.H{
+		"operation_log_id": snap.OperationLogID,
+		"snapshot_id":      snap.ID,
+		"kind":             snap.Kind,
+		"namespace":        snap.Namespace,
+		"name":             snap.Name,
+		"force":            c.Query("force") == "true",
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+
+	gvr := resourcesnapshot.GVR(snap)
+	current, err := resourcesnapshot.Capture(ctx, dyn, gvr, snap.Namespace, snap.Name)
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.SnapshotCaptureFailed))
+		return
+	}
+	if c.Query("force") != "true" {
+		drift, err := resourcesnapshot.Drift(snap, current)
+		if err != nil {
+			response.Fail(c, errcode.Ensure(err, errcode.RevertFailed))
+			return
+		}
+		if len(drift) > 0 {
+			paths := make([]string, 0, len(drift))
+			for i, d := range drift {
+				if i == 5 {
+					paths = append(paths, "...")
+					break
+				}
+				paths = append(paths, d.Path)
+			}
+			response.FailCode(c, errcode.SnapshotDrifted, strings.Join(paths, ", "))
+			return
+		}
+	}
+
+	// 撤销路由不在 /clusters/:clusterID 下，需在此按撤销实际执行的操作单独检查
+	resource, action, namespace := revertTarget(snap, current)
+	if !middleware.EnforcePolicy(c, h.policyService, snap.ClusterID, namespace, resource, action) ||
+		!middleware.EnforceChangeApproval(c, h.changeService, snap.ClusterID, namespace, resource, action) ||
+		!middleware.EnforceFreeze(c, h.freezeService, snap.ClusterID, namespace) {
+		return
+	}
+
+	// 撤销本身也是一次变更，同样记录前后快照
+	revert := &objectSnapshot{c: c, dyn: dyn, clusterID: snap.ClusterID, gvr: gvr, namespace: snap.Namespace, name: snap.Name, before: current}
+	if err := resourcesnapshot.Revert(ctx, dyn, snap, current); err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.RevertFailed))
+		return
+	}
+	if snap.Before == "" {
+		revert.Deleted()
+	} else {
+		revert.Done()
+	}
+
+	if err := h.opLogSvc.MarkSnapshotReverted(snap.ID, c.GetUint("user_id")); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Error("标记快照已撤销失败", "snapshot", snap.ID, "error", err)
+	}
+
+	logger.Info("撤销变更成功", "snapshot", snap.ID, "resource", snap.Resource, "namespace", snap.Namespace, "name", snap.Name)
+	response.OK(c, gin.H{"snapshot_id": snap.ID})
+}
+
+// revertTarget 解析撤销实际执行的操作：变更前对象不存在时删除，当前对象不存在时重新创建，否则更新
+// 返回策略资源名、操作与命名空间（命名空间对象取其名称）
+func revertTarget(snap *models.OperationLogSnapshot, current map[string]interface{}) (string, string, string) {
+	resource := snap.Resource
+	if mapped, ok := snapshotPolicyResources[resource]; ok {
+		resource = mapped
+	}
+	namespace := snap.Namespace
+	if resource == "namespaces" {
+		namespace = snap.Name
+	}
+	action := models.PolicyActionUpdate
+	switch {
+	case snap.Before == "":
+		action = models.PolicyActionDelete
+	case current == nil:
+		action = models.PolicyActionCreate
+	}
+	return resource, action, namespace
+}
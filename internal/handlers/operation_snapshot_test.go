@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+func TestRevertTarget(t *testing.T) {
+	current := map[string]interface{}{"kind": "Namespace"}
+	tests := []struct {
+		name      string
+		snap      models.OperationLogSnapshot
+		current   map[string]interface{}
+		resource  string
+		action    string
+		namespace string
+	}{
+		{"revert create deletes", models.OperationLogSnapshot{Resource: "namespaces", Name: "prod-shop", After: "{}"}, current, "namespaces", models.PolicyActionDelete, "prod-shop"},
+		{"revert update", models.OperationLogSnapshot{Resource: "deployments", Namespace: "shop", Name: "web", Before: "{}", After: "{}"}, current, "deployments", models.PolicyActionUpdate, "shop"},
+		{"revert delete recreates", models.OperationLogSnapshot{Resource: "persistentvolumeclaims", Namespace: "shop", Name: "data", Before: "{}"}, nil, "pvcs", models.PolicyActionCreate, "shop"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, action, namespace := revertTarget(&tt.snap, tt.current)
+			if resource != tt.resource || action != tt.action || namespace != tt.namespace {
+				t.Fatalf("got %s/%s/%s, want %s/%s/%s", resource, action, namespace, tt.resource, tt.action, tt.namespace)
+			}
+		})
+	}
+}
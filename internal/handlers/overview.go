@@ -8,6 +8,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
@@ -15,6 +17,7 @@ import (
 
 // OverviewHandler 总览处理器
 type OverviewHandler struct {
+	db              *gorm.DB
 	overviewService *services.OverviewService
 	permissionSvc   *services.PermissionService
 }
@@ -40,19 +43,47 @@ func NewOverviewHandler(
 		alertManagerSvc,
 	)
 	return &OverviewHandler{
+		db:              db,
 		overviewService: overviewSvc,
 		permissionSvc:   permSvc,
 	}
 }
 
 // filteredContext 在 context 中注入用户可访问的集群过滤条件
+// 支持 labelSelector（如 env=prod,region=eu）与 clusterGroupId 按集群标签进一步过滤
 func (h *OverviewHandler) filteredContext(c *gin.Context) context.Context {
 	userID := c.GetUint("user_id")
 	clusterIDs, isAll, err := h.permissionSvc.GetUserAccessibleClusterIDs(userID)
-	if err != nil || isAll {
+	if err != nil {
 		return c.Request.Context()
 	}
-	return services.ContextWithClusterFilter(c.Request.Context(), clusterIDs)
+
+	ctx := c.Request.Context()
+	if scope, err := services.LoadTenantScope(h.db, userID); err == nil && scope != nil {
+		// 租户成员只统计本租户命名空间内的资源
+		ctx = services.ContextWithTenantScope(ctx, scope)
+	}
+
+	selector, err := services.ResolveClusterLabelFilter(h.db, c.Query("labelSelector"), c.Query("clusterGroupId"))
+	if err != nil || len(selector) == 0 {
+		if isAll {
+			return ctx
+		}
+		return services.ContextWithClusterFilter(ctx, clusterIDs)
+	}
+
+	var clusters []*models.Cluster
+	query := h.db.Select("id", "labels")
+	if !isAll {
+		query = query.Where("id IN ?", clusterIDs)
+	}
+	query.Find(&clusters)
+
+	filteredIDs := make([]uint, 0, len(clusters))
+	for _, cluster := range services.FilterClustersByLabels(clusters, selector) {
+		filteredIDs = append(filteredIDs, cluster.ID)
+	}
+	return services.ContextWithClusterFilter(ctx, filteredIDs)
 }
 
 // GetStats 获取总览统计数据
@@ -69,7 +100,7 @@ func (h *OverviewHandler) GetStats(c *gin.Context) {
 	stats, err := h.overviewService.GetOverviewStats(h.filteredContext(c))
 	if err != nil {
 		logger.Error("获取总览统计数据失败", "error", err)
-		response.InternalError(c, "获取统计数据失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.OverviewStatsFailed))
 		return
 	}
 
@@ -90,7 +121,7 @@ func (h *OverviewHandler) GetResourceUsage(c *gin.Context) {
 	usage, err := h.overviewService.GetResourceUsage(h.filteredContext(c))
 	if err != nil {
 		logger.Error("获取资源使用率失败", "error", err)
-		response.InternalError(c, "获取资源使用率失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceUsageFailed))
 		return
 	}
 
@@ -111,7 +142,7 @@ func (h *OverviewHandler) GetDistribution(c *gin.Context) {
 	distribution, err := h.overviewService.GetResourceDistribution(h.filteredContext(c))
 	if err != nil {
 		logger.Error("获取资源分布失败", "error", err)
-		response.InternalError(c, "获取资源分布失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceDistributionFailed))
 		return
 	}
 
@@ -142,7 +173,7 @@ func (h *OverviewHandler) GetTrends(c *gin.Context) {
 
 	if err != nil {
 		logger.Error("获取趋势数据失败", "error", err, "耗时", elapsed.String())
-		response.InternalError(c, "获取趋势数据失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.TrendsFailed))
 		return
 	}
 
@@ -167,7 +198,7 @@ func (h *OverviewHandler) GetAbnormalWorkloads(c *gin.Context) {
 	workloads, err := h.overviewService.GetAbnormalWorkloads(h.filteredContext(c), limit)
 	if err != nil {
 		logger.Error("获取异常工作负载失败", "error", err)
-		response.InternalError(c, "获取异常工作负载失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.AbnormalWorkloadsFailed))
 		return
 	}
 
@@ -188,7 +219,7 @@ func (h *OverviewHandler) GetAlertStats(c *gin.Context) {
 	stats, err := h.overviewService.GetGlobalAlertStats(h.filteredContext(c))
 	if err != nil {
 		logger.Error("获取全局告警统计失败", "error", err)
-		response.InternalError(c, "获取全局告警统计失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.GlobalAlertStatsFailed))
 		return
 	}
 
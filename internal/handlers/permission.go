@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
@@ -48,13 +49,13 @@ type CreateUserGroupRequest struct {
 func (h *PermissionHandler) CreateUserGroup(c *gin.Context) {
 	var req CreateUserGroupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
 	group, err := h.permissionService.CreateUserGroup(req.Name, req.Description)
 	if err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -71,19 +72,19 @@ type UpdateUserGroupRequest struct {
 func (h *PermissionHandler) UpdateUserGroup(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的用户组ID")
+		response.FailCode(c, errcode.InvalidUserGroupID)
 		return
 	}
 
 	var req UpdateUserGroupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
 	group, err := h.permissionService.UpdateUserGroup(uint(id), req.Name, req.Description)
 	if err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -94,12 +95,12 @@ func (h *PermissionHandler) UpdateUserGroup(c *gin.Context) {
 func (h *PermissionHandler) DeleteUserGroup(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的用户组ID")
+		response.FailCode(c, errcode.InvalidUserGroupID)
 		return
 	}
 
 	if err := h.permissionService.DeleteUserGroup(uint(id)); err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -110,13 +111,13 @@ func (h *PermissionHandler) DeleteUserGroup(c *gin.Context) {
 func (h *PermissionHandler) GetUserGroup(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的用户组ID")
+		response.FailCode(c, errcode.InvalidUserGroupID)
 		return
 	}
 
 	group, err := h.permissionService.GetUserGroup(uint(id))
 	if err != nil {
-		response.NotFound(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -127,7 +128,7 @@ func (h *PermissionHandler) GetUserGroup(c *gin.Context) {
 func (h *PermissionHandler) ListUserGroups(c *gin.Context) {
 	groups, err := h.permissionService.ListUserGroups()
 	if err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -143,18 +144,18 @@ type AddUserToGroupRequest struct {
 func (h *PermissionHandler) AddUserToGroup(c *gin.Context) {
 	groupID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的用户组ID")
+		response.FailCode(c, errcode.InvalidUserGroupID)
 		return
 	}
 
 	var req AddUserToGroupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
 	if err := h.permissionService.AddUserToGroup(req.UserID, uint(groupID)); err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -165,18 +166,18 @@ func (h *PermissionHandler) AddUserToGroup(c *gin.Context) {
 func (h *PermissionHandler) RemoveUserFromGroup(c *gin.Context) {
 	groupID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的用户组ID")
+		response.FailCode(c, errcode.InvalidUserGroupID)
 		return
 	}
 
 	userID, err := strconv.ParseUint(c.Param("userId"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的用户ID")
+		response.FailCode(c, errcode.InvalidUserID)
 		return
 	}
 
 	if err := h.permissionService.RemoveUserFromGroup(uint(userID), uint(groupID)); err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -187,12 +188,14 @@ func (h *PermissionHandler) RemoveUserFromGroup(c *gin.Context) {
 
 // CreateClusterPermissionRequest 创建集群权限请求
 type CreateClusterPermissionRequest struct {
-	ClusterID      uint     `json:"cluster_id" binding:"required"`
-	UserID         *uint    `json:"user_id"`
-	UserGroupID    *uint    `json:"user_group_id"`
-	PermissionType string   `json:"permission_type" binding:"required"`
-	Namespaces     []string `json:"namespaces"`
-	CustomRoleRef  string   `json:"custom_role_ref"`
+	ClusterID       uint              `json:"cluster_id"`
+	ClusterSelector map[string]string `json:"cluster_selector"` // 按集群标签授权，与 ClusterID 二选一
+	ClusterGroupID  *uint             `json:"cluster_group_id"` // 按集群分组授权（使用分组的标签选择器）
+	UserID          *uint             `json:"user_id"`
+	UserGroupID     *uint             `json:"user_group_id"`
+	PermissionType  string            `json:"permission_type" binding:"required"`
+	Namespaces      []string          `json:"namespaces"`
+	CustomRoleRef   string            `json:"custom_role_ref"`
 	// 批量字段：与 UserID/UserGroupID 互斥，支持同时为多个用户和用户组创建权限
 	UserIDs      []uint `json:"user_ids"`
 	UserGroupIDs []uint `json:"user_group_ids"`
@@ -202,7 +205,7 @@ type CreateClusterPermissionRequest struct {
 func (h *PermissionHandler) CreateClusterPermission(c *gin.Context) {
 	var req CreateClusterPermissionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
@@ -215,7 +218,7 @@ func (h *PermissionHandler) CreateClusterPermission(c *gin.Context) {
 	}
 
 	if len(req.UserIDs) == 0 && len(req.UserGroupIDs) == 0 {
-		response.BadRequest(c, "至少需要指定一个用户或用户组")
+		response.FailCode(c, errcode.SubjectRequired)
 		return
 	}
 
@@ -226,11 +229,13 @@ func (h *PermissionHandler) CreateClusterPermission(c *gin.Context) {
 	for _, uid := range req.UserIDs {
 		uidCopy := uid
 		serviceReq := &services.CreateClusterPermissionRequest{
-			ClusterID:      req.ClusterID,
-			UserID:         &uidCopy,
-			PermissionType: req.PermissionType,
-			Namespaces:     req.Namespaces,
-			CustomRoleRef:  req.CustomRoleRef,
+			ClusterID:       req.ClusterID,
+			ClusterSelector: req.ClusterSelector,
+			ClusterGroupID:  req.ClusterGroupID,
+			UserID:          &uidCopy,
+			PermissionType:  req.PermissionType,
+			Namespaces:      req.Namespaces,
+			CustomRoleRef:   req.CustomRoleRef,
 		}
 		permission, err := h.permissionService.CreateClusterPermission(serviceReq)
 		if err != nil {
@@ -245,11 +250,13 @@ func (h *PermissionHandler) CreateClusterPermission(c *gin.Context) {
 	for _, gid := range req.UserGroupIDs {
 		gidCopy := gid
 		serviceReq := &services.CreateClusterPermissionRequest{
-			ClusterID:      req.ClusterID,
-			UserGroupID:    &gidCopy,
-			PermissionType: req.PermissionType,
-			Namespaces:     req.Namespaces,
-			CustomRoleRef:  req.CustomRoleRef,
+			ClusterID:       req.ClusterID,
+			ClusterSelector: req.ClusterSelector,
+			ClusterGroupID:  req.ClusterGroupID,
+			UserGroupID:     &gidCopy,
+			PermissionType:  req.PermissionType,
+			Namespaces:      req.Namespaces,
+			CustomRoleRef:   req.CustomRoleRef,
 		}
 		permission, err := h.permissionService.CreateClusterPermission(serviceReq)
 		if err != nil {
@@ -261,7 +268,7 @@ func (h *PermissionHandler) CreateClusterPermission(c *gin.Context) {
 	}
 
 	if len(created) == 0 && len(errs) > 0 {
-		response.BadRequest(c, "创建失败")
+		response.FailCode(c, errcode.PermissionCreateFailed)
 		return
 	}
 
@@ -280,6 +287,14 @@ func (h *PermissionHandler) ensureUserRBACInCluster(permission *models.ClusterPe
 		return
 	}
 
+	// 标签选择器授权：在每个匹配的集群中分别创建
+	if permission.IsSelectorGrant() {
+		for _, bound := range h.permissionService.BindSelectorPermission(permission) {
+			h.ensureUserRBACInCluster(bound)
+		}
+		return
+	}
+
 	// 获取集群信息
 	cluster, err := h.clusterService.GetCluster(permission.ClusterID)
 	if err != nil {
@@ -315,29 +330,31 @@ func (h *PermissionHandler) ensureUserRBACInCluster(permission *models.ClusterPe
 
 // UpdateClusterPermissionRequest 更新集群权限请求
 type UpdateClusterPermissionRequest struct {
-	PermissionType string   `json:"permission_type"`
-	Namespaces     []string `json:"namespaces"`
-	CustomRoleRef  string   `json:"custom_role_ref"`
+	PermissionType  string            `json:"permission_type"`
+	Namespaces      []string          `json:"namespaces"`
+	CustomRoleRef   string            `json:"custom_role_ref"`
+	ClusterSelector map[string]string `json:"cluster_selector"`
 }
 
 // UpdateClusterPermission 更新集群权限
 func (h *PermissionHandler) UpdateClusterPermission(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的权限ID")
+		response.FailCode(c, errcode.InvalidPermissionID)
 		return
 	}
 
 	var req UpdateClusterPermissionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
 	serviceReq := &services.UpdateClusterPermissionRequest{
-		PermissionType: req.PermissionType,
-		Namespaces:     req.Namespaces,
-		CustomRoleRef:  req.CustomRoleRef,
+		PermissionType:  req.PermissionType,
+		Namespaces:      req.Namespaces,
+		CustomRoleRef:   req.CustomRoleRef,
+		ClusterSelector: req.ClusterSelector,
 	}
 
 	// 获取旧权限配置用于清理
@@ -345,7 +362,7 @@ func (h *PermissionHandler) UpdateClusterPermission(c *gin.Context) {
 
 	permission, err := h.permissionService.UpdateClusterPermission(uint(id), serviceReq)
 	if err != nil {
-		response.BadRequest(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -361,6 +378,15 @@ func (h *PermissionHandler) updateUserRBACInCluster(oldPermission, newPermission
 		return
 	}
 
+	// 涉及标签选择器授权时，匹配的集群可能变化：先在旧集群清理，再在新集群创建
+	if newPermission.IsSelectorGrant() || (oldPermission != nil && oldPermission.IsSelectorGrant()) {
+		if oldPermission != nil {
+			h.cleanupUserRBACInCluster(oldPermission)
+		}
+		h.ensureUserRBACInCluster(newPermission)
+		return
+	}
+
 	// 获取集群信息
 	cluster, err := h.clusterService.GetCluster(newPermission.ClusterID)
 	if err != nil {
@@ -403,7 +429,7 @@ func (h *PermissionHandler) updateUserRBACInCluster(oldPermission, newPermission
 func (h *PermissionHandler) DeleteClusterPermission(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的权限ID")
+		response.FailCode(c, errcode.InvalidPermissionID)
 		return
 	}
 
@@ -411,7 +437,7 @@ func (h *PermissionHandler) DeleteClusterPermission(c *gin.Context) {
 	permission, _ := h.permissionService.GetClusterPermission(uint(id))
 
 	if err := h.permissionService.DeleteClusterPermission(uint(id)); err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -429,6 +455,14 @@ func (h *PermissionHandler) cleanupUserRBACInCluster(permission *models.ClusterP
 		return
 	}
 
+	// 标签选择器授权：在每个匹配的集群中分别清理
+	if permission.IsSelectorGrant() {
+		for _, bound := range h.permissionService.BindSelectorPermission(permission) {
+			h.cleanupUserRBACInCluster(bound)
+		}
+		return
+	}
+
 	// 获取集群信息
 	cluster, err := h.clusterService.GetCluster(permission.ClusterID)
 	if err != nil {
@@ -443,8 +477,13 @@ func (h *PermissionHandler) cleanupUserRBACInCluster(permission *models.ClusterP
 		return
 	}
 
-	namespaces := permission.GetNamespaceList()
-	if err := h.rbacService.CleanupUserRBAC(k8sClient.GetClientset(), *permission.UserID, permission.PermissionType, namespaces); err != nil {
+	// 同类型其他有效权限（如临时提权）仍在使用的绑定需要保留
+	clusterBinding, namespaces, err := h.permissionService.StaleUserBindings(permission)
+	if err != nil {
+		logger.Error("计算待清理 RBAC 失败", "error", err)
+		return
+	}
+	if err := h.rbacService.CleanupUserBindings(k8sClient.GetClientset(), *permission.UserID, permission.PermissionType, clusterBinding, namespaces); err != nil {
 		logger.Error("清理用户 RBAC 失败", "error", err)
 	} else {
 		logger.Info("用户 RBAC 清理成功", "userID", *permission.UserID, "clusterID", permission.ClusterID)
@@ -460,12 +499,12 @@ type BatchDeleteClusterPermissionsRequest struct {
 func (h *PermissionHandler) BatchDeleteClusterPermissions(c *gin.Context) {
 	var req BatchDeleteClusterPermissionsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
 	if err := h.permissionService.BatchDeleteClusterPermissions(req.IDs); err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -476,13 +515,13 @@ func (h *PermissionHandler) BatchDeleteClusterPermissions(c *gin.Context) {
 func (h *PermissionHandler) GetClusterPermission(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的权限ID")
+		response.FailCode(c, errcode.InvalidPermissionID)
 		return
 	}
 
 	permission, err := h.permissionService.GetClusterPermission(uint(id))
 	if err != nil {
-		response.NotFound(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -496,7 +535,7 @@ func (h *PermissionHandler) ListClusterPermissions(c *gin.Context) {
 	if clusterIDStr != "" {
 		id, err := strconv.ParseUint(clusterIDStr, 10, 64)
 		if err != nil {
-			response.BadRequest(c, "无效的集群ID")
+			response.FailCode(c, errcode.InvalidClusterID)
 			return
 		}
 		clusterID = uint(id)
@@ -504,7 +543,7 @@ func (h *PermissionHandler) ListClusterPermissions(c *gin.Context) {
 
 	permissions, err := h.permissionService.ListClusterPermissions(clusterID)
 	if err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -521,7 +560,7 @@ func (h *PermissionHandler) ListClusterPermissions(c *gin.Context) {
 func (h *PermissionHandler) ListAllClusterPermissions(c *gin.Context) {
 	permissions, err := h.permissionService.ListAllClusterPermissions()
 	if err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -540,13 +579,13 @@ func (h *PermissionHandler) ListAllClusterPermissions(c *gin.Context) {
 func (h *PermissionHandler) GetMyPermissions(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	if userID == 0 {
-		response.Unauthorized(c, "未登录")
+		response.FailCode(c, errcode.NotLoggedIn)
 		return
 	}
 
 	permissions, err := h.permissionService.GetUserAllClusterPermissions(userID)
 	if err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -583,19 +622,19 @@ func (h *PermissionHandler) GetMyPermissions(c *gin.Context) {
 func (h *PermissionHandler) GetMyClusterPermission(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	if userID == 0 {
-		response.Unauthorized(c, "未登录")
+		response.FailCode(c, errcode.NotLoggedIn)
 		return
 	}
 
 	clusterID, err := strconv.ParseUint(c.Param("clusterID"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	permission, err := h.permissionService.GetUserClusterPermission(userID, uint(clusterID))
 	if err != nil {
-		response.Forbidden(c, "无权限访问该集群")
+		response.FailCode(c, errcode.ClusterAccessDenied)
 		return
 	}
 
@@ -626,7 +665,7 @@ func (h *PermissionHandler) GetMyClusterPermission(c *gin.Context) {
 func (h *PermissionHandler) ListUsers(c *gin.Context) {
 	users, err := h.permissionService.ListUsers()
 	if err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
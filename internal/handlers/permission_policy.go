@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+// PermissionPolicyHandler 细粒度权限策略处理器
+type PermissionPolicyHandler struct {
+	policyService     *services.PolicyService
+	permissionService *services.PermissionService
+}
+
+// NewPermissionPolicyHandler 创建细粒度权限策略处理器
+func NewPermissionPolicyHandler(policyService *services.PolicyService, permissionService *services.PermissionService) *PermissionPolicyHandler {
+	return &PermissionPolicyHandler{
+		policyService:     policyService,
+		permissionService: permissionService,
+	}
+}
+
+// ListPolicies 获取策略列表
+func (h *PermissionPolicyHandler) ListPolicies(c *gin.Context) {
+	policies, err := h.policyService.ListPolicies()
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, policies)
+}
+
+// GetPolicyActions 获取支持的策略操作
+func (h *PermissionPolicyHandler) GetPolicyActions(c *gin.Context) {
+	response.OK(c, services.SupportedPolicyActions())
+}
+
+// CreatePolicy 创建策略
+func (h *PermissionPolicyHandler) CreatePolicy(c *gin.Context) {
+	var req services.PolicyRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	policy, err := h.policyService.CreatePolicy(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Created(c, policy)
+}
+
+// GetPolicy 获取策略详情
+func (h *PermissionPolicyHandler) GetPolicy(c *gin.Context) {
+	id, ok := parsePolicyID(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.policyService.GetPolicy(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, policy)
+}
+
+// UpdatePolicy 更新策略
+func (h *PermissionPolicyHandler) UpdatePolicy(c *gin.Context) {
+	id, ok := parsePolicyID(c)
+	if !ok {
+		return
+	}
+
+	var req services.PolicyRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	policy, err := h.policyService.UpdatePolicy(id, &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, policy)
+}
+
+// DeletePolicy 删除策略
+func (h *PermissionPolicyHandler) DeletePolicy(c *gin.Context) {
+	id, ok := parsePolicyID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.policyService.DeletePolicy(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// ExplainPolicy 解释某用户对某资源操作的最终决策
+// 查询参数: user_id, cluster_id, namespace, resource, action
+func (h *PermissionPolicyHandler) ExplainPolicy(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Query("user_id"), 10, 32)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidUserID)
+		return
+	}
+	clusterID, err := strconv.ParseUint(c.Query("cluster_id"), 10, 32)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidClusterID)
+		return
+	}
+	action := c.Query("action")
+	if action == "" {
+		response.FailCode(c, errcode.ActionsRequired)
+		return
+	}
+
+	req := h.policyService.BuildRequest(uint(userID), uint(clusterID), c.Query("namespace"), c.Query("resource"), action)
+	explanation, err := h.policyService.Explain(h.permissionService, req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, explanation)
+}
+
+// parsePolicyID 解析路径中的策略ID
+func parsePolicyID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidPolicyID)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
@@ -138,16 +139,16 @@ func (h *PodHandler) GetPods(c *gin.Context) {
 	// 从集群服务获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	if clusterID == 0 {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -156,11 +157,11 @@ func (h *PodHandler) GetPods(c *gin.Context) {
 
 	// 确保 informer 缓存就绪
 	if h.k8sMgr == nil {
-		response.ServiceUnavailable(c, "K8s informer 管理器未初始化")
+		response.FailCode(c, errcode.InformerManagerUnavailable)
 		return
 	}
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 	// label 选择器
@@ -185,13 +186,13 @@ func (h *PodHandler) GetPods(c *gin.Context) {
 	if namespace != "" {
 		// 用户指定了命名空间，检查权限
 		if !hasAllAccess && !middleware.HasNamespaceAccess(c, namespace) {
-			response.Forbidden(c, fmt.Sprintf("无权访问命名空间: %s", namespace))
+			response.FailCode(c, errcode.NamespaceAccessDenied)
 			return
 		}
 
 		podObjs, err := h.k8sMgr.PodsLister(cluster.ID).Pods(namespace).List(sel)
 		if err != nil {
-			response.InternalError(c, "读取Pod缓存失败: "+err.Error())
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceCacheFailed, "Pod"))
 			return
 		}
 		filtered := make([]corev1.Pod, 0, len(podObjs))
@@ -205,7 +206,7 @@ func (h *PodHandler) GetPods(c *gin.Context) {
 		// 有全部命名空间权限，返回所有Pod
 		podObjs, err := h.k8sMgr.PodsLister(cluster.ID).List(sel)
 		if err != nil {
-			response.InternalError(c, "读取Pod缓存失败: "+err.Error())
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceCacheFailed, "Pod"))
 			return
 		}
 		filtered := make([]corev1.Pod, 0, len(podObjs))
@@ -313,16 +314,16 @@ func (h *PodHandler) GetPod(c *gin.Context) {
 	// 从集群服务获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	if clusterID == 0 {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -331,16 +332,16 @@ func (h *PodHandler) GetPod(c *gin.Context) {
 
 	// 使用 informer+lister 获取Pod详情
 	if h.k8sMgr == nil {
-		response.ServiceUnavailable(c, "K8s informer 管理器未初始化")
+		response.FailCode(c, errcode.InformerManagerUnavailable)
 		return
 	}
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 	pod, err := h.k8sMgr.PodsLister(cluster.ID).Pods(namespace).Get(name)
 	if err != nil {
-		response.NotFound(c, "Pod不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "Pod"))
 		return
 	}
 
@@ -363,19 +364,19 @@ func (h *PodHandler) DeletePod(c *gin.Context) {
 	// 从集群服务获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -388,11 +389,13 @@ func (h *PodHandler) DeletePod(c *gin.Context) {
 		PropagationPolicy: &deletePolicy,
 	}
 
+	snap := beginSnapshot(c, k8sClient, gvrPods, namespace, name)
 	err = k8sClient.GetClientset().CoreV1().Pods(namespace).Delete(ctx, name, deleteOptions)
 	if err != nil {
-		response.InternalError(c, "删除失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.DeleteFailed))
 		return
 	}
+	snap.Deleted()
 
 	response.NoContent(c)
 }
@@ -413,19 +416,19 @@ func (h *PodHandler) GetPodLogs(c *gin.Context) {
 	// 从集群服务获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -458,7 +461,7 @@ func (h *PodHandler) GetPodLogs(c *gin.Context) {
 	req := k8sClient.GetClientset().CoreV1().Pods(namespace).GetLogs(name, logOptions)
 	logs, err := req.Stream(ctx)
 	if err != nil {
-		response.InternalError(c, "获取日志失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.LogsFailed))
 		return
 	}
 	defer func() {
@@ -467,7 +470,7 @@ func (h *PodHandler) GetPodLogs(c *gin.Context) {
 
 	// 如果是follow模式，返回错误提示使用WebSocket
 	if follow {
-		response.BadRequest(c, "流式日志请使用WebSocket连接: /ws/clusters/:clusterID/pods/:namespace/:name/logs")
+		response.FailCode(c, errcode.StreamLogsOverWebSocket)
 		return
 	}
 
@@ -680,12 +683,12 @@ func (h *PodHandler) GetPodNamespaces(c *gin.Context) {
 	// 从集群服务获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -694,11 +697,11 @@ func (h *PodHandler) GetPodNamespaces(c *gin.Context) {
 
 	// 确保 informer 缓存就绪
 	if h.k8sMgr == nil {
-		response.ServiceUnavailable(c, "K8s informer 管理器未初始化")
+		response.FailCode(c, errcode.InformerManagerUnavailable)
 		return
 	}
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 
@@ -707,7 +710,7 @@ func (h *PodHandler) GetPodNamespaces(c *gin.Context) {
 	pods, err := h.k8sMgr.PodsLister(cluster.ID).List(sel)
 	if err != nil {
 		logger.Error("读取Pod缓存失败", "error", err)
-		response.InternalError(c, "获取命名空间列表失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Namespace"))
 		return
 	}
 
@@ -741,12 +744,12 @@ func (h *PodHandler) GetPodNodes(c *gin.Context) {
 	// 从集群服务获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -755,11 +758,11 @@ func (h *PodHandler) GetPodNodes(c *gin.Context) {
 
 	// 确保 informer 缓存就绪
 	if h.k8sMgr == nil {
-		response.ServiceUnavailable(c, "K8s informer 管理器未初始化")
+		response.FailCode(c, errcode.InformerManagerUnavailable)
 		return
 	}
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 
@@ -768,7 +771,7 @@ func (h *PodHandler) GetPodNodes(c *gin.Context) {
 	pods, err := h.k8sMgr.PodsLister(cluster.ID).List(sel)
 	if err != nil {
 		logger.Error("读取Pod缓存失败", "error", err)
-		response.InternalError(c, "获取节点列表失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Node"))
 		return
 	}
 
@@ -805,12 +808,12 @@ func (h *PodHandler) StreamPodLogs(c *gin.Context) {
 	// 从集群服务获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
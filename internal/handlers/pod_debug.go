@@ -0,0 +1,434 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
+	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/internal/templates/rbac"
+	"github.com/clay-wangzhi/KubePolaris/internal/terminalhub"
+	"github.com/clay-wangzhi/KubePolaris/internal/terminalreplay"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	debugContainerPrefix = "kp-debug-"
+	nodeDebugPodPrefix   = "kubepolaris-node-debug-"
+	nodeShellPodPrefix   = "kubepolaris-node-shell-"
+	nodeDebugContainer   = "debugger"
+	debugStartTimeout    = 120 * time.Second
+	// nodeDebugPodDeadline 节点调试 Pod 的最长存活时间，服务异常退出未能删除 Pod 时由 kubelet 终止
+	nodeDebugPodDeadline int64 = 8 * 3600
+)
+
+// DebugTerminalHandler 调试终端处理器（kubectl debug 等价能力）
+type DebugTerminalHandler struct {
+	clusterService *services.ClusterService
+	k8sMgr         *k8s.ClusterInformerManager
+	podTerminal    *PodTerminalHandler
+	images         []string
+	nodeShellImage string
+	upgrader       websocket.Upgrader
+}
+
+// NewDebugTerminalHandler 创建调试终端处理器，images 为可选调试镜像（第一个为默认），nodeShellImage 为 Pod 方式节点终端镜像
+func NewDebugTerminalHandler(clusterService *services.ClusterService, auditService *services.AuditService, commandPolicy *services.TerminalCommandPolicyService, liveHub *terminalhub.Hub, k8sMgr *k8s.ClusterInformerManager, replayStorage *terminalreplay.Storage, images []string, nodeShellImage string) *DebugTerminalHandler {
+	return &DebugTerminalHandler{
+		clusterService: clusterService,
+		k8sMgr:         k8sMgr,
+		podTerminal:    NewPodTerminalHandler(clusterService, auditService, commandPolicy, liveHub, k8sMgr, replayStorage),
+		images:         images,
+		nodeShellImage: nodeShellImage,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" {
+					return true
+				}
+				return middleware.IsRequestOriginAllowed(origin, r.Host)
+			},
+		},
+	}
+}
+
+// HandlePodDebug 向 Pod 注入临时调试容器（共享目标容器的进程命名空间）并 attach
+func (h *DebugTerminalHandler) HandlePodDebug(c *gin.Context) {
+	clusterIDStr := c.Param("clusterID")
+	namespace := c.Param("namespace")
+	podName := c.Param("name")
+	target := c.Query("container")
+	userID := c.GetUint("user_id")
+
+	image, err := resolveDebugImage(h.images, c.Query("image"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	cluster, client, ok := h.clusterClient(c, clusterIDStr)
+	if !ok {
+		return
+	}
+
+	pod, err := client.CoreV1().Pods(namespace).Get(c.Request.Context(), podName, metav1.GetOptions{})
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "Pod"))
+		return
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		response.FailCode(c, errcode.PodNotRunning, pod.Status.Phase)
+		return
+	}
+	if target == "" && len(pod.Spec.Containers) > 0 {
+		target = pod.Spec.Containers[0].Name
+	}
+	if !slices.ContainsFunc(pod.Spec.Containers, func(ct corev1.Container) bool { return ct.Name == target }) {
+		response.FailCode(c, errcode.ContainerNotFound, target)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logger.Error("调试终端升级WebSocket失败", "error", err)
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	debugName := debugContainerPrefix + uuid.NewString()[:8]
+	h.sendDebugPrep(conn, fmt.Sprintf("正在向 Pod %s/%s 注入调试容器 %s（镜像 %s，目标容器 %s）…", namespace, podName, debugName, image, target))
+
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, newEphemeralDebugContainer(debugName, image, target))
+	if _, err := client.CoreV1().Pods(namespace).UpdateEphemeralContainers(context.Background(), podName, pod, metav1.UpdateOptions{}); err != nil {
+		logger.Error("注入调试容器失败", "error", err, "pod", podName)
+		h.sendDebugJSON(conn, "error", fmt.Sprintf("注入调试容器失败: %v", err))
+		return
+	}
+
+	if err := h.waitForEphemeralRunning(client, namespace, podName, debugName, conn); err != nil {
+		h.sendDebugJSON(conn, "error", fmt.Sprintf("等待调试容器就绪失败: %v", err))
+		return
+	}
+
+	logger.Info("Pod调试终端连接", "cluster", cluster.Name, "pod", podName, "container", debugName, "user", userID)
+	h.podTerminal.runPodSession(conn, cluster, clusterIDStr, namespace, podName, debugName, userID,
+		debugPermissionType(c), services.TerminalTypePod, podSessionOptions{attach: true})
+}
+
+// HandleNodeDebug 在节点上创建特权调试 Pod（宿主机根目录挂载到 /host）并 attach，会话结束后删除 Pod
+func (h *DebugTerminalHandler) HandleNodeDebug(c *gin.Context) {
+	clusterIDStr := c.Param("clusterID")
+	nodeName := c.Param("name")
+	userID := c.GetUint("user_id")
+
+	image, err := resolveDebugImage(h.images, c.Query("image"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	cluster, client, ok := h.clusterClient(c, clusterIDStr)
+	if !ok {
+		return
+	}
+
+	if _, err := client.CoreV1().Nodes().Get(c.Request.Context(), nodeName, metav1.GetOptions{}); err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "Node"))
+		return
+	}
+
+	pod := buildNodeDebugPod(nodeDebugPodPrefix+uuid.NewString()[:8], nodeName, image, userID)
+	h.attachNodePod(c, cluster, client, pod, debugPermissionType(c),
+		fmt.Sprintf("正在节点 %s 上创建调试 Pod %s（镜像 %s）…", nodeName, pod.Name, image))
+}
+
+// HandleNodeShell Pod 方式的节点终端：在节点上创建特权 Pod，nsenter 进入宿主机 PID 1 的命名空间，
+// 无需 SSH 凭据与到节点的网络连通；仅对设置为 pod 方式的集群开放
+func (h *DebugTerminalHandler) HandleNodeShell(c *gin.Context) {
+	nodeName := c.Param("name")
+	userID := c.GetUint("user_id")
+
+	cluster, client, ok := h.clusterClient(c, c.Param("clusterID"))
+	if !ok {
+		return
+	}
+	if !cluster.UsesPodNodeTerminal() {
+		response.FailCode(c, errcode.NodePodTerminalDisabled)
+		return
+	}
+	if h.nodeShellImage == "" {
+		response.FailCode(c, errcode.NodeDebugImageMissing)
+		return
+	}
+
+	if _, err := client.CoreV1().Nodes().Get(c.Request.Context(), nodeName, metav1.GetOptions{}); err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "Node"))
+		return
+	}
+
+	pod := buildNodeShellPod(nodeShellPodPrefix+uuid.NewString()[:8], nodeName, h.nodeShellImage, userID)
+	// 节点终端仅平台管理员可用，与 SSH 方式一致按 admin 权限类型匹配命令规则
+	h.attachNodePod(c, cluster, client, pod, models.PermissionTypeAdmin,
+		fmt.Sprintf("正在节点 %s 上创建终端 Pod %s…", nodeName, pod.Name))
+}
+
+// attachNodePod 创建节点级 Pod 并在就绪后接入 Pod 终端与录像流程，会话结束后删除 Pod
+func (h *DebugTerminalHandler) attachNodePod(c *gin.Context, cluster *models.Cluster, client *kubernetes.Clientset, pod *corev1.Pod, permissionType, prep string) {
+	nodeName := pod.Spec.NodeName
+	userID := c.GetUint("user_id")
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logger.Error("调试终端升级WebSocket失败", "error", err)
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	h.sendDebugPrep(conn, prep)
+
+	if _, err := client.CoreV1().Pods(pod.Namespace).Create(context.Background(), pod, metav1.CreateOptions{}); err != nil {
+		logger.Error("创建节点Pod失败", "error", err, "node", nodeName)
+		h.sendDebugJSON(conn, "error", fmt.Sprintf("创建节点 Pod 失败: %v", err))
+		return
+	}
+	defer func() {
+		zero := int64(0)
+		if err := client.CoreV1().Pods(pod.Namespace).Delete(context.Background(), pod.Name, metav1.DeleteOptions{GracePeriodSeconds: &zero}); err != nil {
+			logger.Error("删除节点Pod失败", "error", err, "pod", pod.Name)
+		}
+	}()
+
+	if err := h.waitForPodRunning(client, pod.Namespace, pod.Name, conn); err != nil {
+		h.sendDebugJSON(conn, "error", fmt.Sprintf("等待节点 Pod 就绪失败: %v", err))
+		return
+	}
+
+	logger.Info("节点Pod终端连接", "cluster", cluster.Name, "node", nodeName, "pod", pod.Name, "user", userID)
+	h.podTerminal.runPodSession(conn, cluster, c.Param("clusterID"), pod.Namespace, pod.Name, nodeDebugContainer, userID,
+		permissionType, services.TerminalTypeNode, podSessionOptions{attach: true, node: nodeName})
+}
+
+// clusterClient 解析集群并获取 K8s 客户端，失败时已写入响应
+func (h *DebugTerminalHandler) clusterClient(c *gin.Context, clusterIDStr string) (*models.Cluster, *kubernetes.Clientset, bool) {
+	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 32)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidClusterID)
+		return nil, nil, false
+	}
+	cluster, err := h.clusterService.GetCluster(uint(clusterID))
+	if err != nil {
+		response.FailCode(c, errcode.ClusterNotFound)
+		return nil, nil, false
+	}
+	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
+		return nil, nil, false
+	}
+	return cluster, k8sClient.GetClientset(), true
+}
+
+// waitForEphemeralRunning 等待临时容器进入 Running，并推送 Waiting 原因（如镜像拉取）
+func (h *DebugTerminalHandler) waitForEphemeralRunning(client *kubernetes.Clientset, namespace, podName, name string, conn *websocket.Conn) error {
+	ctx, cancel := context.WithTimeout(context.Background(), debugStartTimeout)
+	defer cancel()
+
+	lastSent := ""
+	for {
+		pod, err := client.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		for _, cs := range pod.Status.EphemeralContainerStatuses {
+			if cs.Name != name {
+				continue
+			}
+			if cs.State.Running != nil {
+				return nil
+			}
+			if t := cs.State.Terminated; t != nil {
+				return fmt.Errorf("调试容器已退出: %s %s", t.Reason, strings.TrimSpace(t.Message))
+			}
+			if w := cs.State.Waiting; w != nil {
+				desc := fmt.Sprintf("调试容器 %s：%s", name, w.Reason)
+				if w.Message != "" {
+					desc += " — " + strings.TrimSpace(w.Message)
+				}
+				if desc != lastSent {
+					lastSent = desc
+					h.sendDebugPrep(conn, desc)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("等待调试容器运行超时")
+		case <-time.After(1 * time.Second):
+		}
+	}
+}
+
+// waitForPodRunning 等待调试 Pod 进入 Running，并推送进度摘要
+func (h *DebugTerminalHandler) waitForPodRunning(client *kubernetes.Clientset, namespace, podName string, conn *websocket.Conn) error {
+	ctx, cancel := context.WithTimeout(context.Background(), debugStartTimeout)
+	defer cancel()
+
+	lastSent := ""
+	for {
+		pod, err := client.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		switch pod.Status.Phase {
+		case corev1.PodRunning:
+			return nil
+		case corev1.PodFailed, corev1.PodSucceeded:
+			return fmt.Errorf("调试 Pod 已结束: %s %s", pod.Status.Phase, pod.Status.Message)
+		}
+
+		if desc := describeKubectlPodProgress(pod); desc != "" && desc != lastSent {
+			lastSent = desc
+			h.sendDebugPrep(conn, desc)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("等待调试 Pod 运行超时")
+		case <-time.After(1 * time.Second):
+		}
+	}
+}
+
+func (h *DebugTerminalHandler) sendDebugPrep(conn *websocket.Conn, text string) {
+	_ = conn.WriteJSON(PodTerminalMessage{Type: "debug_prep", Data: text})
+}
+
+func (h *DebugTerminalHandler) sendDebugJSON(conn *websocket.Conn, msgType, data string) {
+	_ = conn.WriteJSON(PodTerminalMessage{Type: msgType, Data: data})
+}
+
+// debugPermissionType 当前用户在集群上的权限类型
+func debugPermissionType(c *gin.Context) string {
+	if perm, exists := c.Get("cluster_permission"); exists {
+		if cp, ok := perm.(*models.ClusterPermission); ok && cp != nil {
+			return cp.PermissionType
+		}
+	}
+	return ""
+}
+
+// resolveDebugImage 校验请求的调试镜像，未指定时使用第一个可选镜像
+func resolveDebugImage(images []string, requested string) (string, error) {
+	if len(images) == 0 {
+		return "", errcode.New(errcode.DebugImageMissing)
+	}
+	if requested == "" {
+		return images[0], nil
+	}
+	if !slices.Contains(images, requested) {
+		return "", errcode.New(errcode.DebugImageNotAllowed, requested)
+	}
+	return requested, nil
+}
+
+// newEphemeralDebugContainer 构建临时调试容器。StdinOnce 使调试 shell 在会话断开（stdin 关闭）后退出，
+// 临时容器无法从 Pod 中删除，退出后仅保留状态记录。
+func newEphemeralDebugContainer(name, image, target string) corev1.EphemeralContainer {
+	return corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:                     name,
+			Image:                    image,
+			ImagePullPolicy:          corev1.PullIfNotPresent,
+			Stdin:                    true,
+			StdinOnce:                true,
+			TTY:                      true,
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		},
+		TargetContainerName: target,
+	}
+}
+
+// buildNodeDebugPod 构建节点调试 Pod：特权容器，共享宿主机 PID/网络/IPC 命名空间，宿主机根目录挂载到 /host
+func buildNodeDebugPod(name, nodeName, image string, userID uint) *corev1.Pod {
+	privileged := true
+	automount := false
+	deadline := nodeDebugPodDeadline
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: rbac.KubePolarisNamespace,
+			Labels: map[string]string{
+				"app":     "kubepolaris-node-debug",
+				"user-id": fmt.Sprintf("%d", userID),
+			},
+			Annotations: map[string]string{
+				"kubepolaris.io/debug-node": nodeName,
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:                     nodeName,
+			HostPID:                      true,
+			HostNetwork:                  true,
+			HostIPC:                      true,
+			RestartPolicy:                corev1.RestartPolicyNever,
+			AutomountServiceAccountToken: &automount,
+			ActiveDeadlineSeconds:        &deadline,
+			Tolerations:                  []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			Containers: []corev1.Container{{
+				Name:            nodeDebugContainer,
+				Image:           image,
+				ImagePullPolicy: corev1.PullIfNotPresent,
+				Stdin:           true,
+				StdinOnce:       true,
+				TTY:             true,
+				SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+				VolumeMounts:    []corev1.VolumeMount{{Name: "host-root", MountPath: "/host"}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "host-root",
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{Path: "/"},
+				},
+			}},
+		},
+	}
+}
+
+// nodeShellCommand 进入宿主机 PID 1 的全部命名空间，优先使用宿主机的 bash
+var nodeShellCommand = []string{
+	"nsenter", "-t", "1", "-m", "-u", "-i", "-n", "-p", "--",
+	"sh", "-c", "if [ -x /bin/bash ]; then exec /bin/bash -l; else exec /bin/sh -l; fi",
+}
+
+// buildNodeShellPod 构建 Pod 方式节点终端使用的 Pod：在节点调试 Pod 的基础上直接 nsenter 进入宿主机，不挂载宿主机目录
+func buildNodeShellPod(name, nodeName, image string, userID uint) *corev1.Pod {
+	pod := buildNodeDebugPod(name, nodeName, image, userID)
+	pod.Labels["app"] = "kubepolaris-node-shell"
+	pod.Annotations = map[string]string{"kubepolaris.io/shell-node": nodeName}
+	pod.Spec.Volumes = nil
+	ct := &pod.Spec.Containers[0]
+	ct.Command = nodeShellCommand
+	ct.VolumeMounts = nil
+	return pod
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestResolveDebugImage(t *testing.T) {
+	images := []string{"busybox:1.36", "nicolaka/netshoot:v0.13"}
+
+	image, err := resolveDebugImage(images, "")
+	require.NoError(t, err)
+	assert.Equal(t, "busybox:1.36", image)
+
+	image, err = resolveDebugImage(images, "nicolaka/netshoot:v0.13")
+	require.NoError(t, err)
+	assert.Equal(t, "nicolaka/netshoot:v0.13", image)
+
+	_, err = resolveDebugImage(images, "evil/image:latest")
+	assert.Error(t, err)
+	_, err = resolveDebugImage(nil, "")
+	assert.Error(t, err)
+}
+
+func TestNewEphemeralDebugContainer(t *testing.T) {
+	ec := newEphemeralDebugContainer("kp-debug-1", "busybox:1.36", "app")
+	assert.Equal(t, "app", ec.TargetContainerName)
+	assert.True(t, ec.Stdin)
+	assert.True(t, ec.StdinOnce)
+	assert.True(t, ec.TTY)
+}
+
+func TestBuildNodeDebugPod(t *testing.T) {
+	pod := buildNodeDebugPod("kubepolaris-node-debug-1", "node-1", "busybox:1.36", 7)
+	assert.Equal(t, "node-1", pod.Spec.NodeName)
+	assert.True(t, pod.Spec.HostPID)
+	assert.Equal(t, corev1.RestartPolicyNever, pod.Spec.RestartPolicy)
+	require.NotNil(t, pod.Spec.ActiveDeadlineSeconds)
+	require.Len(t, pod.Spec.Containers, 1)
+
+	ct := pod.Spec.Containers[0]
+	require.NotNil(t, ct.SecurityContext)
+	assert.True(t, *ct.SecurityContext.Privileged)
+	require.Len(t, ct.VolumeMounts, 1)
+	assert.Equal(t, "/host", ct.VolumeMounts[0].MountPath)
+	require.Len(t, pod.Spec.Volumes, 1)
+	assert.Equal(t, "/", pod.Spec.Volumes[0].HostPath.Path)
+	assert.Equal(t, corev1.TolerationOpExists, pod.Spec.Tolerations[0].Operator)
+}
+
+func TestBuildNodeShellPod(t *testing.T) {
+	pod := buildNodeShellPod("kubepolaris-node-shell-1", "node-1", "busybox:1.36", 7)
+	assert.Equal(t, "node-1", pod.Spec.NodeName)
+	assert.True(t, pod.Spec.HostPID)
+	assert.Equal(t, "kubepolaris-node-shell", pod.Labels["app"])
+	assert.Empty(t, pod.Spec.Volumes)
+	require.Len(t, pod.Spec.Containers, 1)
+
+	ct := pod.Spec.Containers[0]
+	assert.True(t, *ct.SecurityContext.Privileged)
+	assert.Empty(t, ct.VolumeMounts)
+	assert.Equal(t, []string{"nsenter", "-t", "1"}, ct.Command[:3])
+	assert.True(t, ct.Stdin && ct.StdinOnce && ct.TTY)
+}
@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// errTransferTooLarge 传输内容超过大小上限
+var errTransferTooLarge = errcode.New(errcode.TransferTooLarge)
+
+// DownloadPodFile 从容器下载文件（kubectl cp 方式：exec tar 流式读取）
+// 普通文件原样返回，目录打包为 tar.gz
+func (h *PodHandler) DownloadPodFile(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	container := c.Query("container")
+
+	dir, base, err := splitContainerPath(c.Query("path"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	client, restConfig, ok := h.execClient(c)
+	if !ok {
+		return
+	}
+	limit := h.cfg.Terminal.FileTransferMaxMB << 20
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	pr, pw := io.Pipe()
+	defer func() {
+		_ = pr.Close()
+	}()
+	var stderr bytes.Buffer
+	execDone := make(chan error, 1)
+	go func() {
+		err := streamPodExec(ctx, client, restConfig, namespace, name, container,
+			[]string{"tar", "cf", "-", "-C", dir, base}, nil, pw, &stderr)
+		_ = pw.CloseWithError(err)
+		execDone <- err
+	}()
+
+	tr := tar.NewReader(pr)
+	hdr, err := tr.Next()
+	if err != nil {
+		cancel()
+		_ = pr.Close()
+		execErr := <-execDone
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" && execErr != nil {
+			msg = execErr.Error()
+		}
+		response.FailCode(c, errcode.FileReadFailed, msg)
+		return
+	}
+
+	detail := gin.H{"container": container, "path": path.Join(dir, base)}
+	hash := sha256.New()
+	out := &countingWriter{w: io.MultiWriter(c.Writer, hash)}
+
+	switch {
+	case hdr.Typeflag == tar.TypeReg && path.Clean(hdr.Name) == base:
+		if limit > 0 && hdr.Size > limit {
+			response.Fail(c, errTransferTooLarge)
+			return
+		}
+		detail["type"] = "file"
+		c.Header("Content-Type", "application/octet-stream")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", base))
+		c.Header("Content-Length", fmt.Sprintf("%d", hdr.Size))
+		c.Status(http.StatusOK)
+		_, err = io.Copy(out, tr)
+	case hdr.Typeflag == tar.TypeDir:
+		detail["type"] = "directory"
+		c.Header("Content-Type", "application/gzip")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", base+".tar.gz"))
+		c.Status(http.StatusOK)
+		err = copyTarGz(out, tr, hdr, limit)
+	default:
+		response.FailCode(c, errcode.UnsupportedFileType)
+		return
+	}
+
+	if err == nil {
+		// 读完 tar 结尾的填充块，等待容器内 tar 正常退出
+		_, _ = io.Copy(io.Discard, pr)
+		if execErr := <-execDone; execErr != nil {
+			err = fmt.Errorf("%v: %s", execErr, strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	detail["size"] = out.n
+	detail["sha256"] = hex.EncodeToString(hash.Sum(nil))
+	if err != nil {
+		// 响应头已发送，只能中断连接并在审计中记录未完成
+		logger.Error("下载容器文件中断", "namespace", namespace, "pod", name, "path", detail["path"], "error", err)
+		detail["complete"] = false
+		c.Set("error_message", "下载中断: "+err.Error())
+		c.Abort()
+	}
+	c.Set(middleware.AuditDetailKey, detail)
+}
+
+// UploadPodFile 上传文件到容器（kubectl cp 方式：exec tar 流式写入）
+// 请求体为文件内容，必须携带 Content-Length；path 为容器内目标文件路径
+func (h *PodHandler) UploadPodFile(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	container := c.Query("container")
+
+	dir, base, err := splitContainerPath(c.Query("path"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	size := c.Request.ContentLength
+	if size < 0 {
+		response.FailCode(c, errcode.ContentLengthRequired)
+		return
+	}
+	if limit := h.cfg.Terminal.FileTransferMaxMB << 20; limit > 0 && size > limit {
+		response.Fail(c, errTransferTooLarge)
+		return
+	}
+
+	client, restConfig, ok := h.execClient(c)
+	if !ok {
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, size)
+	hash := sha256.New()
+	pr, pw := io.Pipe()
+	go func() {
+		_ = pw.CloseWithError(writeUploadArchive(pw, base, io.TeeReader(body, hash), size))
+	}()
+
+	var stderr bytes.Buffer
+	err = streamPodExec(c.Request.Context(), client, restConfig, namespace, name, container,
+		[]string{"tar", "xf", "-", "-C", dir}, pr, nil, &stderr)
+	_ = pr.Close()
+
+	detail := gin.H{"container": container, "path": path.Join(dir, base), "size": size}
+	if err != nil {
+		c.Set(middleware.AuditDetailKey, detail)
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		response.FailCode(c, errcode.FileUploadFailed, msg)
+		return
+	}
+
+	detail["sha256"] = hex.EncodeToString(hash.Sum(nil))
+	c.Set(middleware.AuditDetailKey, detail)
+	response.OK(c, detail)
+}
+
+// execClient 获取集群的 clientset 与 rest 配置，失败时已写入响应
+func (h *PodHandler) execClient(c *gin.Context) (*kubernetes.Clientset, *rest.Config, bool) {
+	clusterID, err := parseClusterID(c.Param("clusterID"))
+	if err != nil {
+		response.FailCode(c, errcode.InvalidClusterID)
+		return nil, nil, false
+	}
+	cluster, err := h.clusterService.GetCluster(clusterID)
+	if err != nil {
+		response.FailCode(c, errcode.ClusterNotFound)
+		return nil, nil, false
+	}
+	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
+		return nil, nil, false
+	}
+	return k8sClient.GetClientset(), k8sClient.GetRestConfig(), true
+}
+
+// streamPodExec 在容器内执行命令并流式连接 stdin/stdout/stderr（不分配 TTY）
+func streamPodExec(ctx context.Context, client *kubernetes.Clientset, restConfig *rest.Config, namespace, podName, container string, command []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	req := client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("exec")
+
+	req.VersionedParams(&corev1.PodExecOptions{
+		Container: container,
+		Command:   command,
+		Stdin:     stdin != nil,
+		Stdout:    stdout != nil,
+		Stderr:    stderr != nil,
+	}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(restConfig, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("创建 exec 连接失败: %w", err)
+	}
+	return exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+}
+
+// splitContainerPath 校验容器内绝对路径并拆分为目录与文件名
+func splitContainerPath(p string) (string, string, error) {
+	if !strings.HasPrefix(p, "/") {
+		return "", "", errcode.New(errcode.InvalidContainerPath)
+	}
+	p = path.Clean(p)
+	if p == "/" {
+		return "", "", errcode.New(errcode.ContainerRootTransfer)
+	}
+	return path.Dir(p), path.Base(p), nil
+}
+
+// writeUploadArchive 将单个文件写为 tar 流，内容不足 size 时返回错误
+func writeUploadArchive(w io.Writer, name string, r io.Reader, size int64) error {
+	tw := tar.NewWriter(w)
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     size,
+		ModTime:  time.Now(),
+	}); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(tw, r, size); err != nil {
+		return fmt.Errorf("读取上传内容失败: %w", err)
+	}
+	return tw.Close()
+}
+
+// copyTarGz 将 tar 流（first 为已读取的首个条目）重新打包为 tar.gz，文件内容累计超过 limit 时中止
+func copyTarGz(w io.Writer, tr *tar.Reader, first *tar.Header, limit int64) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	var total int64
+	for hdr := first; ; {
+		total += hdr.Size
+		if limit > 0 && total > limit {
+			return errTransferTooLarge
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+
+		var err error
+		hdr, err = tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// countingWriter 统计写入字节数
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitContainerPath(t *testing.T) {
+	dir, base, err := splitContainerPath("/var/log//app/../app.log")
+	require.NoError(t, err)
+	assert.Equal(t, "/var/log", dir)
+	assert.Equal(t, "app.log", base)
+
+	_, _, err = splitContainerPath("var/log/app.log")
+	assert.Error(t, err)
+	_, _, err = splitContainerPath("/")
+	assert.Error(t, err)
+}
+
+func TestWriteUploadArchive(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeUploadArchive(&buf, "a.txt", strings.NewReader("hello"), 5))
+
+	tr := tar.NewReader(&buf)
+	hdr, err := tr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "a.txt", hdr.Name)
+	data, err := io.ReadAll(tr)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	// 请求体比 Content-Length 短
+	assert.Error(t, writeUploadArchive(io.Discard, "a.txt", strings.NewReader("hi"), 5))
+}
+
+func buildTestTar(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "conf/", Mode: 0755}))
+	for _, name := range []string{"conf/a", "conf/b"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644, Size: 4}))
+		_, err := tw.Write([]byte("data"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return &buf
+}
+
+func TestCopyTarGz(t *testing.T) {
+	tr := tar.NewReader(buildTestTar(t))
+	first, err := tr.Next()
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, copyTarGz(&out, tr, first, 0))
+
+	gz, err := gzip.NewReader(&out)
+	require.NoError(t, err)
+	var names []string
+	rt := tar.NewReader(gz)
+	for {
+		hdr, err := rt.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+	}
+	assert.Equal(t, []string{"conf/", "conf/a", "conf/b"}, names)
+
+	tr = tar.NewReader(buildTestTar(t))
+	first, err = tr.Next()
+	require.NoError(t, err)
+	assert.ErrorIs(t, copyTarGz(io.Discard, tr, first, 6), errTransferTooLarge)
+}
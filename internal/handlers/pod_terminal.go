@@ -13,11 +13,13 @@ import (
 	"sync"
 	"time"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/internal/terminalhub"
 	"github.com/clay-wangzhi/KubePolaris/internal/terminalreplay"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
 
@@ -34,8 +36,10 @@ import (
 type PodTerminalHandler struct {
 	clusterService *services.ClusterService
 	auditService   *services.AuditService
+	commandPolicy  *services.TerminalCommandPolicyService
+	liveHub        *terminalhub.Hub
 	k8sMgr         *k8s.ClusterInformerManager
-	replayDir      string // 空表示不录像
+	replayStorage  *terminalreplay.Storage // 为空表示不录像
 	upgrader       websocket.Upgrader
 	sessions       map[string]*PodTerminalSession
 	sessionsMutex  sync.RWMutex
@@ -59,6 +63,9 @@ type PodTerminalSession struct {
 	lastCompleteLine string          // 上一个完整行（用于提取命令）
 	pendingEnter     bool            // 是否有待处理的回车键
 
+	// 终端命令策略检查（未配置时为 nil）
+	guard *terminalCommandGuard
+
 	// Kubernetes连接相关
 	stdinReader  io.ReadCloser
 	stdinWriter  io.WriteCloser
@@ -68,6 +75,7 @@ type PodTerminalSession struct {
 	done         chan struct{}
 
 	replay *terminalreplay.Recorder
+	live   *terminalhub.Session // 在线旁观（未注册时为 nil）
 }
 
 // PodTerminalMessage Pod终端消息
@@ -78,13 +86,15 @@ type PodTerminalMessage struct {
 	Rows int    `json:"rows,omitempty"`
 }
 
-// NewPodTerminalHandler 创建Pod终端处理器。replayDir 为空表示不写入会话录像。
-func NewPodTerminalHandler(clusterService *services.ClusterService, auditService *services.AuditService, k8sMgr *k8s.ClusterInformerManager, replayDir string) *PodTerminalHandler {
+// NewPodTerminalHandler 创建Pod终端处理器。replayStorage 为空表示不写入会话录像。
+func NewPodTerminalHandler(clusterService *services.ClusterService, auditService *services.AuditService, commandPolicy *services.TerminalCommandPolicyService, liveHub *terminalhub.Hub, k8sMgr *k8s.ClusterInformerManager, replayStorage *terminalreplay.Storage) *PodTerminalHandler {
 	return &PodTerminalHandler{
 		clusterService: clusterService,
 		auditService:   auditService,
+		commandPolicy:  commandPolicy,
+		liveHub:        liveHub,
 		k8sMgr:         k8sMgr,
-		replayDir:      replayDir,
+		replayStorage:  replayStorage,
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				origin := r.Header.Get("Origin")
@@ -112,13 +122,13 @@ func (h *PodTerminalHandler) HandlePodTerminal(c *gin.Context) {
 	// 获取集群信息
 	clusterIDUint, err := strconv.ParseUint(clusterID, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterService.GetCluster(uint(clusterIDUint))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -127,6 +137,13 @@ func (h *PodTerminalHandler) HandlePodTerminal(c *gin.Context) {
 		terminalType = services.TerminalTypeKubectl
 	}
 
+	permissionType := ""
+	if perm, exists := c.Get("cluster_permission"); exists {
+		if cp, ok := perm.(*models.ClusterPermission); ok && cp != nil {
+			permissionType = cp.PermissionType
+		}
+	}
+
 	// 升级到WebSocket连接
 	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
@@ -136,7 +153,7 @@ func (h *PodTerminalHandler) HandlePodTerminal(c *gin.Context) {
 		_ = conn.Close()
 	}()
 
-	h.RunPodTerminalWithConn(conn, cluster, clusterID, namespace, podName, container, userID, terminalType)
+	h.RunPodTerminalWithConn(conn, cluster, clusterID, namespace, podName, container, userID, permissionType, terminalType)
 }
 
 // RunPodTerminalWithConn 在已建立的 WebSocket 上运行 Pod 终端（kubectl Pod 终端等场景先推送进度再复用此逻辑）
@@ -145,7 +162,27 @@ func (h *PodTerminalHandler) RunPodTerminalWithConn(
 	cluster *models.Cluster,
 	clusterIDStr, namespace, podName, container string,
 	userID uint,
+	permissionType string,
 	terminalType services.TerminalType,
+) {
+	h.runPodSession(conn, cluster, clusterIDStr, namespace, podName, container, userID, permissionType, terminalType, podSessionOptions{})
+}
+
+// podSessionOptions Pod 终端会话的附加选项
+type podSessionOptions struct {
+	attach bool   // attach 到容器主进程（调试容器），不查找/启动 shell
+	node   string // 审计记录中的节点名（节点调试 Pod）
+}
+
+// runPodSession 运行 Pod 终端会话：审计、录像、在线旁观与命令策略对 exec 和 attach 两种方式一致
+func (h *PodTerminalHandler) runPodSession(
+	conn *websocket.Conn,
+	cluster *models.Cluster,
+	clusterIDStr, namespace, podName, container string,
+	userID uint,
+	permissionType string,
+	terminalType services.TerminalType,
+	opts podSessionOptions,
 ) {
 	var auditSessionID uint
 	if h.auditService != nil {
@@ -156,6 +193,7 @@ func (h *PodTerminalHandler) RunPodTerminalWithConn(
 			Namespace:  namespace,
 			Pod:        podName,
 			Container:  container,
+			Node:       opts.node,
 		})
 		if err != nil {
 			logger.Error("创建审计会话失败", "error", err)
@@ -179,8 +217,15 @@ func (h *PodTerminalHandler) RunPodTerminalWithConn(
 		Cancel:         cancel,
 	}
 
+	// kubectl 终端的命名空间是平台系统命名空间，不参与规则的命名空间匹配
+	guardNamespace := namespace
+	if terminalType == services.TerminalTypeKubectl {
+		guardNamespace = ""
+	}
+	session.guard = newTerminalCommandGuard(h.commandPolicy, h.auditService, auditSessionID, terminalType, cluster.ID, guardNamespace, permissionType)
+
 	if h.auditService != nil && auditSessionID > 0 {
-		rec, err := terminalreplay.NewRecorder(h.replayDir, h.auditService, auditSessionID, 120, 30)
+		rec, err := terminalreplay.NewRecorder(h.replayStorage, h.auditService, auditSessionID, 120, 30)
 		if err != nil {
 			logger.Error("创建会话录像失败", "error", err)
 		} else {
@@ -188,6 +233,25 @@ func (h *PodTerminalHandler) RunPodTerminalWithConn(
 		}
 	}
 
+	target := fmt.Sprintf("%s/%s", namespace, podName)
+	if container != "" {
+		target += "/" + container
+	}
+	session.live = h.liveHub.Register(terminalhub.SessionInfo{
+		SessionID:  auditSessionID,
+		UserID:     userID,
+		ClusterID:  cluster.ID,
+		TargetType: string(terminalType),
+		Target:     target,
+	}, terminalhub.Owner{
+		Input:  func(data string) { h.handleInput(session, data) },
+		Notify: func(msgType, data string) { h.sendMessage(conn, msgType, data) },
+		Kill: func(reason string) {
+			h.sendMessage(conn, "error", "会话已被管理员强制终止: "+reason)
+			_ = conn.Close()
+		},
+	})
+
 	h.sessionsMutex.Lock()
 	h.sessions[sessionID] = session
 	h.sessionsMutex.Unlock()
@@ -197,12 +261,17 @@ func (h *PodTerminalHandler) RunPodTerminalWithConn(
 		delete(h.sessions, sessionID)
 		h.sessionsMutex.Unlock()
 		cancel()
+		killed := session.live.Close()
 		if session.replay != nil {
 			session.replay.End()
 		}
 		h.closeSession(session)
 		if h.auditService != nil && auditSessionID > 0 {
-			_ = h.auditService.CloseSession(auditSessionID, "closed")
+			status := "closed"
+			if killed {
+				status = "killed"
+			}
+			_ = h.auditService.CloseSession(auditSessionID, status)
 		}
 	}()
 
@@ -214,22 +283,30 @@ func (h *PodTerminalHandler) RunPodTerminalWithConn(
 	client := k8sClient.GetClientset()
 	k8sConfig := k8sClient.GetRestConfig()
 
-	shell, err := h.findAvailableShell(client, k8sConfig, session)
-	if err != nil {
-		h.sendMessage(conn, "error", fmt.Sprintf("未找到可用的shell: %v", err))
-		return
-	}
+	if opts.attach {
+		if err := h.startPodTerminal(client, k8sConfig, session, ""); err != nil {
+			h.sendMessage(conn, "error", fmt.Sprintf("启动Pod终端失败: %v", err))
+			return
+		}
+		h.sendMessage(conn, "connected", fmt.Sprintf("Attached to debug container %s in pod %s/%s. If you don't see a command prompt, try pressing enter.", container, namespace, podName))
+	} else {
+		shell, err := h.findAvailableShell(client, k8sConfig, session)
+		if err != nil {
+			h.sendMessage(conn, "error", fmt.Sprintf("未找到可用的shell: %v", err))
+			return
+		}
 
-	if err := h.startPodTerminal(client, k8sConfig, session, shell); err != nil {
-		h.sendMessage(conn, "error", fmt.Sprintf("启动Pod终端失败: %v", err))
-		return
-	}
+		if err := h.startPodTerminal(client, k8sConfig, session, shell); err != nil {
+			h.sendMessage(conn, "error", fmt.Sprintf("启动Pod终端失败: %v", err))
+			return
+		}
 
-	containerInfo := ""
-	if container != "" {
-		containerInfo = fmt.Sprintf(" (container: %s)", container)
+		containerInfo := ""
+		if container != "" {
+			containerInfo = fmt.Sprintf(" (container: %s)", container)
+		}
+		h.sendMessage(conn, "connected", fmt.Sprintf("Connected to pod %s/%s%s using %s", namespace, podName, containerInfo, shell))
 	}
-	h.sendMessage(conn, "connected", fmt.Sprintf("Connected to pod %s/%s%s using %s", namespace, podName, containerInfo, shell))
 
 	for {
 		mt, data, err := conn.ReadMessage()
@@ -247,6 +324,11 @@ func (h *PodTerminalHandler) RunPodTerminalWithConn(
 				h.handleInput(session, msg.Data)
 			case "resize":
 				h.handleResize(session, msg.Cols, msg.Rows)
+			case "share_response":
+				// 所有者答复协同操作申请：approve / deny
+				session.live.RespondDrive(msg.Data == "approve")
+			case "share_revoke":
+				session.live.RevokeDrive()
 			}
 			continue
 		}
@@ -305,7 +387,7 @@ func (h *PodTerminalHandler) hasShellInContainer(client *kubernetes.Clientset, k
 	return strings.HasSuffix(result, shell)
 }
 
-// startPodTerminal 启动Pod终端连接
+// startPodTerminal 启动Pod终端连接；shell 为空时 attach 到容器主进程（调试容器）
 func (h *PodTerminalHandler) startPodTerminal(client *kubernetes.Clientset, k8sConfig *rest.Config, session *PodTerminalSession, shell string) error {
 	// 创建管道
 	stdinReader, stdinWriter := io.Pipe()
@@ -341,17 +423,26 @@ func (h *PodTerminalHandler) startPodTerminal(client *kubernetes.Clientset, k8sC
 		req := client.CoreV1().RESTClient().Post().
 			Resource("pods").
 			Name(session.PodName).
-			Namespace(session.Namespace).
-			SubResource("exec")
-
-		req.VersionedParams(&v1.PodExecOptions{
-			Container: session.Container,
-			Command:   []string{shell},
-			Stdin:     true,
-			Stdout:    true,
-			Stderr:    true,
-			TTY:       true,
-		}, scheme.ParameterCodec)
+			Namespace(session.Namespace)
+
+		if shell == "" {
+			req.SubResource("attach").VersionedParams(&v1.PodAttachOptions{
+				Container: session.Container,
+				Stdin:     true,
+				Stdout:    true,
+				Stderr:    true,
+				TTY:       true,
+			}, scheme.ParameterCodec)
+		} else {
+			req.SubResource("exec").VersionedParams(&v1.PodExecOptions{
+				Container: session.Container,
+				Command:   []string{shell},
+				Stdin:     true,
+				Stdout:    true,
+				Stderr:    true,
+				TTY:       true,
+			}, scheme.ParameterCodec)
+		}
 
 		exec, err := remotecommand.NewSPDYExecutor(k8sConfig, "POST", req.URL())
 		if err != nil {
@@ -380,6 +471,25 @@ func (h *PodTerminalHandler) handleInput(session *PodTerminalSession, input stri
 	session.Mutex.Lock()
 	defer session.Mutex.Unlock()
 
+	// 命令执行前策略检查：拒绝的命令不会把回车写入终端
+	if session.guard != nil {
+		result := session.guard.Filter(input, h.extractCommandFromLine(session.currentLine.String()))
+		if result.notice != "" {
+			h.sendMessage(session.Conn, "data", result.notice)
+		}
+		if result.blocked {
+			session.currentLine.Reset()
+			session.pendingEnter = false
+		}
+		input = result.forward
+		if input == "" {
+			return
+		}
+	}
+
+	// 录像记录实际写入终端的输入（密码提示后的输入会被掩码）
+	session.replay.RecordInput([]byte(input))
+
 	if session.stdinWriter != nil {
 		_, err := session.stdinWriter.Write([]byte(input))
 		if err != nil {
@@ -419,6 +529,7 @@ func (h *PodTerminalHandler) handleResize(session *PodTerminalSession, cols, row
 		if session.replay != nil {
 			session.replay.Resize(cols, rows)
 		}
+		session.live.Resize(cols, rows)
 	}
 }
 
@@ -437,6 +548,7 @@ func (h *PodTerminalHandler) readOutput(session *PodTerminalSession) {
 			if session.replay != nil {
 				session.replay.Record(buffer[:n])
 			}
+			session.live.Broadcast(buffer[:n])
 
 			// 追踪终端输出，用于提取完整命令（包括Tab补全结果）
 			if h.auditService != nil && session.AuditSessionID > 0 {
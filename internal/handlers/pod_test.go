@@ -85,7 +85,7 @@ func (s *PodHandlerTestSuite) TestGetPods_ClusterNotFound() {
 
 	errObj, ok := response["error"].(map[string]interface{})
 	s.Require().True(ok, "response should contain error object")
-	assert.Equal(s.T(), "NOT_FOUND", errObj["code"])
+	assert.Equal(s.T(), "CLUSTER_NOT_FOUND", errObj["code"])
 }
 
 // TestGetPods_InvalidClusterID 测试无效的集群 ID
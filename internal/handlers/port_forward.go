@@ -0,0 +1,409 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
+	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
+	"github.com/clay-wangzhi/KubePolaris/internal/portforward"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"gorm.io/gorm"
+)
+
+const (
+	// portForwardProxyPrefix HTTP 反向代理路径前缀，完整路径为 /proxy/port-forwards/:id/:key/
+	portForwardProxyPrefix = "/proxy/port-forwards/"
+	// portForwardSandboxCSP 与平台同源代理时附加的 CSP：页面运行在不透明源中，无法读取平台的 localStorage 与 Cookie
+	portForwardSandboxCSP = "sandbox allow-scripts allow-forms allow-popups"
+)
+
+// PortForwardHandler 端口转发处理器
+type PortForwardHandler struct {
+	db             *gorm.DB
+	clusterService *services.ClusterService
+	k8sMgr         *k8s.ClusterInformerManager
+	manager        *portforward.Manager
+	records        *services.PortForwardService
+	proxyOrigin    string // 反向代理使用的独立源（scheme://host），为空时与平台同源
+	upgrader       websocket.Upgrader
+}
+
+// NewPortForwardHandler 创建端口转发处理器。proxyBaseURL 为反向代理使用的独立域名（如 https://pf.example.com），可为空
+func NewPortForwardHandler(db *gorm.DB, clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager, manager *portforward.Manager, records *services.PortForwardService, proxyBaseURL string) *PortForwardHandler {
+	proxyOrigin := ""
+	if proxyBaseURL != "" {
+		u, err := url.Parse(proxyBaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			logger.Error("端口转发代理域名配置无效，代理将与平台同源并以 CSP sandbox 隔离", "proxy_base_url", proxyBaseURL)
+		} else {
+			proxyOrigin = u.Scheme + "://" + u.Host
+		}
+	}
+	return &PortForwardHandler{
+		db:             db,
+		clusterService: clusterService,
+		k8sMgr:         k8sMgr,
+		manager:        manager,
+		records:        records,
+		proxyOrigin:    proxyOrigin,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" {
+					return true
+				}
+				return middleware.IsRequestOriginAllowed(origin, r.Host)
+			},
+			ReadBufferSize:  32 * 1024,
+			WriteBufferSize: 32 * 1024,
+		},
+	}
+}
+
+// CreatePortForwardRequest 建立端口转发请求
+type CreatePortForwardRequest struct {
+	Port       int `json:"port" binding:"required,min=1,max=65535"`
+	TTLMinutes int `json:"ttl_minutes"`
+}
+
+// PortForwardView 端口转发及其访问方式
+type PortForwardView struct {
+	portforward.Info
+	TunnelPath string `json:"tunnel_path"`         // WebSocket TCP 隧道
+	ProxyURL   string `json:"proxy_url,omitempty"` // HTTP 反向代理入口（含访问凭据，仅返回给所有者）
+}
+
+// CreatePodPortForward 建立到 Pod 端口的转发
+func (h *PortForwardHandler) CreatePodPortForward(c *gin.Context) {
+	h.createPortForward(c, portforward.KindPod)
+}
+
+// CreateServicePortForward 建立到 Service 端口的转发（选择一个就绪的后端 Pod）
+func (h *PortForwardHandler) CreateServicePortForward(c *gin.Context) {
+	h.createPortForward(c, portforward.KindService)
+}
+
+func (h *PortForwardHandler) createPortForward(c *gin.Context, kind string) {
+	var req CreatePortForwardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
+		return
+	}
+
+	clusterID, err := strconv.ParseUint(c.Param("clusterID"), 10, 32)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidClusterID)
+		return
+	}
+	cluster, err := h.clusterService.GetCluster(uint(clusterID))
+	if err != nil {
+		response.FailCode(c, errcode.ClusterNotFound)
+		return
+	}
+	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
+		return
+	}
+	client := k8sClient.GetClientset()
+
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	target := portforward.Target{
+		ClusterID: cluster.ID,
+		Namespace: namespace,
+		Kind:      kind,
+		Name:      name,
+		Pod:       name,
+		Port:      req.Port,
+	}
+	if kind == portforward.KindService {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+		target.Pod, target.Port, err = portforward.ResolveServiceTarget(ctx, client, namespace, name, req.Port)
+		if err != nil {
+			response.Fail(c, errcode.Ensure(err, errcode.PortForwardOpenFailed))
+			return
+		}
+	}
+
+	f, err := h.manager.Open(portforward.OpenRequest{
+		UserID:   c.GetUint("user_id"),
+		Username: c.GetString("username"),
+		Target:   target,
+		TTL:      time.Duration(req.TTLMinutes) * time.Minute,
+	}, portforward.NewK8sOpener(client, k8sClient.GetRestConfig()))
+	switch {
+	case errors.Is(err, portforward.ErrLimitExceeded):
+		response.FailCode(c, errcode.PortForwardLimitExceeded)
+		return
+	case errors.Is(err, portforward.ErrTTLTooLong):
+		response.FailCode(c, errcode.PortForwardTTLTooLong)
+		return
+	case err != nil:
+		logger.Error("建立端口转发失败", "namespace", namespace, "pod", target.Pod, "port", target.Port, "error", err)
+		response.Fail(c, errcode.Wrap(err, errcode.PortForwardOpenFailed))
+		return
+	}
+
+	info := f.Info()
+	c.Set(middleware.AuditDetailKey, gin.H{
+		"forward_id":  info.ID,
+		"pod":         info.Pod,
+		"remote_port": info.RemotePort,
+		"expires_at":  info.ExpiresAt,
+	})
+	response.Created(c, PortForwardView{Info: info, TunnelPath: portForwardTunnelPath(info.ID), ProxyURL: h.proxyURL(f)})
+}
+
+// ListPortForwards 列出活跃的端口转发；平台管理员可通过 all=true 查看所有用户的转发
+func (h *PortForwardHandler) ListPortForwards(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if c.Query("all") == "true" {
+		if !middleware.IsPlatformAdmin(h.db, userID, c.GetString("username")) {
+			response.FailCode(c, errcode.PlatformAdminRequired)
+			return
+		}
+		userID = 0
+	}
+
+	infos := h.manager.List(userID)
+	items := make([]PortForwardView, 0, len(infos))
+	for _, info := range infos {
+		item := PortForwardView{Info: info, TunnelPath: portForwardTunnelPath(info.ID)}
+		if f, ok := h.manager.Get(info.ID); ok && info.UserID == c.GetUint("user_id") {
+			item.ProxyURL = h.proxyURL(f)
+		}
+		items = append(items, item)
+	}
+	response.List(c, items, int64(len(items)))
+}
+
+// ClosePortForward 关闭端口转发（所有者或平台管理员）
+func (h *PortForwardHandler) ClosePortForward(c *gin.Context) {
+	f, ok := h.manager.Get(c.Param("id"))
+	if !ok {
+		response.FailCode(c, errcode.PortForwardNotFound)
+		return
+	}
+	userID := c.GetUint("user_id")
+	reason := "用户关闭"
+	if f.Info().UserID != userID {
+		if !middleware.IsPlatformAdmin(h.db, userID, c.GetString("username")) {
+			response.FailCode(c, errcode.PortForwardCloseDenied)
+			return
+		}
+		reason = fmt.Sprintf("管理员 %s 关闭", c.GetString("username"))
+	}
+	if err := h.manager.Close(f.Info().ID, reason); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+// GetPortForwardHistory 端口转发审计记录
+func (h *PortForwardHandler) GetPortForwardHistory(c *gin.Context) {
+	req := &services.PortForwardListRequest{Status: c.Query("status")}
+	req.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	req.PageSize, _ = strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if v, err := strconv.ParseUint(c.Query("userId"), 10, 32); err == nil {
+		req.UserID = uint(v)
+	}
+	if v, err := strconv.ParseUint(c.Query("clusterId"), 10, 32); err == nil {
+		req.ClusterID = uint(v)
+	}
+
+	items, total, err := h.records.ListSessions(req)
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.PortForwardRecordsFailed))
+		return
+	}
+	response.PagedList(c, items, total, req.Page, req.PageSize)
+}
+
+// HandleTunnel WebSocket TCP 隧道：每个 WebSocket 连接对应一条到 Pod 端口的 TCP 连接，数据以二进制帧传输
+func (h *PortForwardHandler) HandleTunnel(c *gin.Context) {
+	f, ok := h.manager.Get(c.Param("id"))
+	if !ok {
+		response.FailCode(c, errcode.PortForwardNotFound)
+		return
+	}
+	if f.Info().UserID != c.GetUint("user_id") {
+		response.FailCode(c, errcode.PortForwardNotOwner)
+		return
+	}
+
+	upstream, err := f.DialContext(c.Request.Context(), "tcp", "")
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.PortForwardDialFailed))
+		return
+	}
+	defer func() {
+		_ = upstream.Close()
+	}()
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	// 转发关闭（手动关闭、到期）时断开隧道
+	go func() {
+		<-f.Closed()
+		_ = upstream.Close()
+	}()
+
+	go func() {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := upstream.Read(buf)
+			if n > 0 {
+				if werr := conn.WriteMessage(websocket.BinaryMessage, buf[:n]); werr != nil {
+					break
+				}
+			}
+			if err != nil {
+				break
+			}
+		}
+		_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		_ = conn.Close()
+	}()
+
+	for {
+		mt, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if mt != websocket.BinaryMessage && mt != websocket.TextMessage {
+			continue
+		}
+		if _, err := upstream.Write(data); err != nil {
+			return
+		}
+	}
+}
+
+// HandleProxy HTTP 反向代理，访问凭据位于路径首段：/proxy/port-forwards/:id/:key/...
+// 凭据放在路径中而不是 Cookie 中，同源时页面处于 CSP sandbox 的不透明源，Cookie 无法可靠携带
+func (h *PortForwardHandler) HandleProxy(c *gin.Context) {
+	// 配置了独立域名时，转发的应用只在该域名下提供，避免与平台同源
+	if h.proxyOrigin != "" && !strings.EqualFold(c.Request.Host, strings.SplitN(h.proxyOrigin, "://", 2)[1]) {
+		response.FailCode(c, errcode.PortForwardNotFound)
+		return
+	}
+	f, ok := h.manager.Get(c.Param("id"))
+	if !ok {
+		response.FailCode(c, errcode.PortForwardNotFound)
+		return
+	}
+
+	key, rest, hasSlash := strings.Cut(strings.TrimPrefix(c.Param("path"), "/"), "/")
+	if legacy := c.Query("pf_key"); legacy != "" {
+		// 兼容旧链接 /proxy/port-forwards/:id/?pf_key=...
+		key, rest, hasSlash = legacy, strings.TrimPrefix(c.Param("path"), "/"), true
+	}
+	if !f.CheckAccessKey(key) {
+		response.FailCode(c, errcode.PortForwardKeyInvalid)
+		return
+	}
+	prefix := portForwardProxyPrefix + c.Param("id") + "/" + key
+	if !hasSlash || c.Query("pf_key") != "" {
+		// 补全末尾斜杠，使应用中的相对路径落在代理前缀下
+		q := c.Request.URL.Query()
+		q.Del("pf_key")
+		location := prefix + "/" + rest
+		if encoded := q.Encode(); encoded != "" {
+			location += "?" + encoded
+		}
+		c.Redirect(http.StatusFound, location)
+		return
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.Out.URL.Scheme = "http"
+			pr.Out.URL.Host = "127.0.0.1"
+			pr.Out.URL.Path = "/" + rest
+			pr.Out.URL.RawPath = ""
+			pr.Out.Host = ""
+			pr.Out.Header.Del("Authorization")
+			pr.SetXForwarded()
+			pr.Out.Header.Set("X-Forwarded-Prefix", prefix)
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			sanitizeProxyResponse(resp.Header, prefix, h.proxyOrigin == "")
+			return nil
+		},
+		Transport: &http.Transport{DialContext: f.DialContext, DisableKeepAlives: true},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			logger.Error("端口转发代理失败", "forwardID", c.Param("id"), "error", err)
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+	proxy.ServeHTTP(c.Writer, c.Request)
+}
+
+// sanitizeProxyResponse 限制转发应用的响应对平台的影响：
+// Cookie 作用域与重定向限定在代理前缀下，移除可清空平台存储或注册全站 Service Worker 的响应头；
+// 与平台同源时附加 CSP sandbox，使页面脚本无法读取平台的登录令牌
+func sanitizeProxyResponse(header http.Header, prefix string, sandbox bool) {
+	if sandbox {
+		header.Add("Content-Security-Policy", portForwardSandboxCSP)
+	}
+	// 路径中含访问凭据，不向外部站点泄露
+	header.Set("Referrer-Policy", "no-referrer")
+	header.Del("Clear-Site-Data")
+	header.Del("Service-Worker-Allowed")
+
+	if location := header.Get("Location"); strings.HasPrefix(location, "/") && !strings.HasPrefix(location, "//") &&
+		!strings.HasPrefix(location, prefix+"/") {
+		header.Set("Location", prefix+location)
+	}
+
+	cookies := header.Values("Set-Cookie")
+	header.Del("Set-Cookie")
+	for _, line := range cookies {
+		cookie, err := http.ParseSetCookie(line)
+		if err != nil {
+			continue
+		}
+		cookie.Domain = ""
+		if !strings.HasPrefix(cookie.Path, "/") {
+			cookie.Path = "/"
+		}
+		if cookie.Path != prefix && !strings.HasPrefix(cookie.Path, prefix+"/") {
+			cookie.Path = prefix + cookie.Path
+		}
+		if v := cookie.String(); v != "" {
+			header.Add("Set-Cookie", v)
+		}
+	}
+}
+
+func portForwardTunnelPath(id string) string {
+	return "/ws/port-forwards/" + id + "/tunnel"
+}
+
+// proxyURL 转发的 HTTP 访问地址（含访问凭据）
+func (h *PortForwardHandler) proxyURL(f *portforward.Forward) string {
+	return h.proxyOrigin + portForwardProxyPrefix + f.Info().ID + "/" + f.AccessKey() + "/"
+}
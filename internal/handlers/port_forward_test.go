@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/portforward"
+)
+
+// newProxyTestRouter 建立一个指向本机 HTTP 服务的转发，返回路由与转发
+func newProxyTestRouter(t *testing.T, upstream http.HandlerFunc, proxyBaseURL string) (*gin.Engine, *portforward.Forward) {
+	gin.SetMode(gin.TestMode)
+	srv := httptest.NewServer(upstream)
+	t.Cleanup(srv.Close)
+	port := srv.Listener.Addr().(*net.TCPAddr).Port
+
+	mgr := portforward.NewManager(portforward.Limits{DefaultTTL: time.Hour}, nil)
+	f, err := mgr.Open(portforward.OpenRequest{UserID: 1, Target: portforward.Target{ClusterID: 1, Port: 80}},
+		func(portforward.Target) (*portforward.Tunnel, error) {
+			return &portforward.Tunnel{LocalPort: port, Stop: func() {}, Done: make(chan struct{})}, nil
+		})
+	require.NoError(t, err)
+
+	h := NewPortForwardHandler(nil, nil, nil, mgr, nil, proxyBaseURL)
+	r := gin.New()
+	r.Any("/proxy/port-forwards/:id/*path", h.HandleProxy)
+	return r, f
+}
+
+// serveProxy 经真实 HTTP 服务请求代理（ReverseProxy 依赖 CloseNotifier，ResponseRecorder 不支持）
+func serveProxy(t *testing.T, r *gin.Engine, host, target string) *httptest.ResponseRecorder {
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	req, err := http.NewRequest(http.MethodGet, srv.URL+target, nil)
+	require.NoError(t, err)
+	req.Host = host
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	w := httptest.NewRecorder()
+	w.Code = resp.StatusCode
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	_, _ = io.Copy(w.Body, resp.Body)
+	return w
+}
+
+func TestPortForwardProxySandboxesSameOrigin(t *testing.T) {
+	r, f := newProxyTestRouter(t, func(w http.ResponseWriter, req *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "1", Path: "/", Domain: "kubepolaris.local"})
+		http.SetCookie(w, &http.Cookie{Name: "token", Value: "x"})
+		w.Header().Set("Clear-Site-Data", `"storage"`)
+		w.Header().Set("Location", "/login")
+		_, _ = w.Write([]byte(req.URL.Path))
+	}, "")
+	prefix := "/proxy/port-forwards/" + f.Info().ID + "/" + f.AccessKey()
+
+	w := serveProxy(t, r, "kubepolaris.local", prefix+"/app/index.html")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "/app/index.html", w.Body.String())
+	assert.Equal(t, portForwardSandboxCSP, w.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+	assert.Empty(t, w.Header().Get("Clear-Site-Data"))
+	assert.Equal(t, prefix+"/login", w.Header().Get("Location"))
+	assert.Equal(t, []string{"sid=1; Path=" + prefix + "/", "token=x; Path=" + prefix + "/"}, w.Header().Values("Set-Cookie"))
+
+	// 凭据错误、旧链接与缺少末尾斜杠
+	assert.Equal(t, http.StatusUnauthorized, serveProxy(t, r, "kubepolaris.local", "/proxy/port-forwards/"+f.Info().ID+"/bad/").Code)
+	w = serveProxy(t, r, "kubepolaris.local", "/proxy/port-forwards/"+f.Info().ID+"/?pf_key="+f.AccessKey()+"&a=1")
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, prefix+"/?a=1", w.Header().Get("Location"))
+	w = serveProxy(t, r, "kubepolaris.local", prefix)
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, prefix+"/", w.Header().Get("Location"))
+}
+
+func TestPortForwardProxyDedicatedHost(t *testing.T) {
+	r, f := newProxyTestRouter(t, func(w http.ResponseWriter, _ *http.Request) {}, "https://pf.example.com")
+	path := "/proxy/port-forwards/" + f.Info().ID + "/" + f.AccessKey() + "/"
+
+	assert.Equal(t, http.StatusNotFound, serveProxy(t, r, "kubepolaris.example.com", path).Code)
+	w := serveProxy(t, r, "pf.example.com", path)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Security-Policy"))
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	rbacv1 "k8s.io/api/rbac/v1"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
@@ -34,21 +35,21 @@ func (h *RBACHandler) SyncPermissions(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	// Get cluster
 	cluster, err := h.clusterService.GetCluster(uint(clusterID))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -57,7 +58,7 @@ func (h *RBACHandler) SyncPermissions(c *gin.Context) {
 	// Sync permissions
 	result, err := h.rbacService.SyncPermissions(clientset)
 	if err != nil {
-		response.InternalError(c, "同步权限失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.RBACSyncFailed))
 		return
 	}
 
@@ -70,21 +71,21 @@ func (h *RBACHandler) GetSyncStatus(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	// Get cluster
 	cluster, err := h.clusterService.GetCluster(uint(clusterID))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -93,7 +94,7 @@ func (h *RBACHandler) GetSyncStatus(c *gin.Context) {
 	// Get sync status
 	result, err := h.rbacService.GetSyncStatus(clientset)
 	if err != nil {
-		response.InternalError(c, "获取同步状态失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.RBACSyncStatusFailed))
 		return
 	}
 
@@ -106,21 +107,21 @@ func (h *RBACHandler) ListClusterRoles(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	// Get cluster
 	cluster, err := h.clusterService.GetCluster(uint(clusterID))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -129,7 +130,7 @@ func (h *RBACHandler) ListClusterRoles(c *gin.Context) {
 	// List ClusterRoles
 	clusterRoles, err := h.rbacService.ListClusterRoles(clientset)
 	if err != nil {
-		response.InternalError(c, "获取ClusterRole列表失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "ClusterRole"))
 		return
 	}
 
@@ -172,27 +173,27 @@ func (h *RBACHandler) CreateCustomClusterRole(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	var req CreateCustomClusterRoleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	// Get cluster
 	cluster, err := h.clusterService.GetCluster(uint(clusterID))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -201,7 +202,7 @@ func (h *RBACHandler) CreateCustomClusterRole(c *gin.Context) {
 	// Create ClusterRole
 	err = h.rbacService.CreateCustomClusterRole(clientset, req.Name, req.Rules)
 	if err != nil {
-		response.InternalError(c, "创建ClusterRole失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceCreateFailed, "ClusterRole"))
 		return
 	}
 
@@ -216,21 +217,21 @@ func (h *RBACHandler) DeleteClusterRole(c *gin.Context) {
 
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	// Get cluster
 	cluster, err := h.clusterService.GetCluster(uint(clusterID))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -239,7 +240,7 @@ func (h *RBACHandler) DeleteClusterRole(c *gin.Context) {
 	// Delete ClusterRole
 	err = h.rbacService.DeleteClusterRole(clientset, name)
 	if err != nil {
-		response.InternalError(c, "删除ClusterRole失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceDeleteFailed, "ClusterRole"))
 		return
 	}
 
@@ -254,21 +255,21 @@ func (h *RBACHandler) ListRoles(c *gin.Context) {
 
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	// Get cluster
 	cluster, err := h.clusterService.GetCluster(uint(clusterID))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -277,7 +278,7 @@ func (h *RBACHandler) ListRoles(c *gin.Context) {
 	// List Roles
 	roles, err := h.rbacService.ListRoles(clientset, namespace)
 	if err != nil {
-		response.InternalError(c, "获取Role列表失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Role"))
 		return
 	}
 
@@ -324,27 +325,27 @@ func (h *RBACHandler) CreateCustomRole(c *gin.Context) {
 
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	var req CreateCustomRoleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	// Get cluster
 	cluster, err := h.clusterService.GetCluster(uint(clusterID))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -353,7 +354,7 @@ func (h *RBACHandler) CreateCustomRole(c *gin.Context) {
 	// Create Role
 	err = h.rbacService.CreateCustomRole(clientset, namespace, req.Name, req.Rules)
 	if err != nil {
-		response.InternalError(c, "创建Role失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceCreateFailed, "Role"))
 		return
 	}
 
@@ -369,21 +370,21 @@ func (h *RBACHandler) DeleteRole(c *gin.Context) {
 
 	clusterID, err := strconv.ParseUint(clusterIDStr, 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	// Get cluster
 	cluster, err := h.clusterService.GetCluster(uint(clusterID))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -392,7 +393,7 @@ func (h *RBACHandler) DeleteRole(c *gin.Context) {
 	// Delete Role
 	err = h.rbacService.DeleteRole(clientset, namespace, name)
 	if err != nil {
-		response.InternalError(c, "删除Role失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceDeleteFailed, "Role"))
 		return
 	}
 
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/report"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+// ReportHandler 定时报表处理器
+type ReportHandler struct {
+	reportService *services.ReportService
+}
+
+// NewReportHandler 创建定时报表处理器
+func NewReportHandler(reportService *services.ReportService) *ReportHandler {
+	return &ReportHandler{reportService: reportService}
+}
+
+// ListSections 可选的报表章节
+func (h *ReportHandler) ListSections(c *gin.Context) {
+	response.OK(c, report.Sections)
+}
+
+// ListSchedules 获取定时报表列表
+func (h *ReportHandler) ListSchedules(c *gin.Context) {
+	schedules, err := h.reportService.ListSchedules()
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, schedules)
+}
+
+// GetSchedule 获取定时报表详情
+func (h *ReportHandler) GetSchedule(c *gin.Context) {
+	id, ok := parseReportID(c, errcode.InvalidReportID)
+	if !ok {
+		return
+	}
+	schedule, err := h.reportService.GetSchedule(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, schedule)
+}
+
+// CreateSchedule 创建定时报表
+func (h *ReportHandler) CreateSchedule(c *gin.Context) {
+	var req services.ReportScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+	schedule, err := h.reportService.CreateSchedule(&req, c.GetString("username"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Created(c, schedule)
+}
+
+// UpdateSchedule 更新定时报表
+func (h *ReportHandler) UpdateSchedule(c *gin.Context) {
+	id, ok := parseReportID(c, errcode.InvalidReportID)
+	if !ok {
+		return
+	}
+	var req services.ReportScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+	schedule, err := h.reportService.UpdateSchedule(id, &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, schedule)
+}
+
+// DeleteSchedule 删除定时报表
+func (h *ReportHandler) DeleteSchedule(c *gin.Context) {
+	id, ok := parseReportID(c, errcode.InvalidReportID)
+	if !ok {
+		return
+	}
+	if err := h.reportService.DeleteSchedule(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// PreviewSchedule 生成报表预览（不发送）
+// 查询参数 raw=true 时直接返回渲染后的 HTML / Markdown 文本，便于在浏览器中查看
+func (h *ReportHandler) PreviewSchedule(c *gin.Context) {
+	id, ok := parseReportID(c, errcode.InvalidReportID)
+	if !ok {
+		return
+	}
+	preview, err := h.reportService.Preview(c.Request.Context(), id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if c.Query("raw") == "true" {
+		contentType := "text/html; charset=utf-8"
+		if preview.Format == report.FormatMarkdown {
+			contentType = "text/markdown; charset=utf-8"
+		}
+		c.Data(http.StatusOK, contentType, []byte(preview.Content))
+		return
+	}
+	response.OK(c, preview)
+}
+
+// RunSchedule 立即生成并发送报表（发送失败时返回的记录中包含错误原因）
+func (h *ReportHandler) RunSchedule(c *gin.Context) {
+	id, ok := parseReportID(c, errcode.InvalidReportID)
+	if !ok {
+		return
+	}
+	delivery, err := h.reportService.RunNow(c.Request.Context(), id, c.GetString("username"))
+	if delivery == nil {
+		response.Fail(c, err)
+		return
+	}
+	delivery.Content = ""
+	response.OK(c, delivery)
+}
+
+// ListDeliveries 报表发送记录
+// 查询参数: scheduleId, status, page, pageSize
+func (h *ReportHandler) ListDeliveries(c *gin.Context) {
+	req := &services.ReportDeliveryListRequest{
+		ScheduleID: uint(getIntParam(c, "scheduleId", 0)),
+		Status:     c.Query("status"),
+		Page:       getIntParam(c, "page", 1),
+		PageSize:   getIntParam(c, "pageSize", 20),
+	}
+	items, total, err := h.reportService.ListDeliveries(req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.PagedList(c, items, total, req.Page, req.PageSize)
+}
+
+// GetDelivery 报表发送记录详情（含报表内容）
+func (h *ReportHandler) GetDelivery(c *gin.Context) {
+	id, ok := parseReportID(c, errcode.InvalidReportDeliveryID)
+	if !ok {
+		return
+	}
+	delivery, err := h.reportService.GetDelivery(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, delivery)
+}
+
+func parseReportID(c *gin.Context, code errcode.Code) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.FailCode(c, code)
+		return 0, false
+	}
+	return uint(id), true
+}
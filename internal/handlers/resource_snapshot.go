@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/resourcesnapshot"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// 变更快照涉及的资源
+var (
+	gvrNamespaces   = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	gvrNodes        = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+	gvrPods         = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	gvrConfigMaps   = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	gvrSecrets      = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	gvrServices     = schema.GroupVersionResource{Version: "v1", Resource: "services"}
+	gvrPVCs         = schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}
+	gvrPVs          = schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumes"}
+	gvrDeployments  = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	gvrStatefulSets = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}
+	gvrDaemonSets   = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}
+	gvrJobs         = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+	gvrCronJobs     = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "cronjobs"}
+	gvrIngresses    = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+	gvrStorageClass = schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "storageclasses"}
+	gvrRollouts     = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}
+)
+
+// snapshotTimeout 读取快照对象的超时时间
+const snapshotTimeout = 10 * time.Second
+
+// objectSnapshot 变更类操作的对象快照：变更前读取对象，变更成功后读取变更后的对象，
+// 生成的快照交给操作审计中间件随操作日志保存。快照失败只记录日志，不影响变更本身。
+type objectSnapshot struct {
+	c         *gin.Context
+	dyn       dynamic.Interface
+	clusterID uint
+	gvr       schema.GroupVersionResource
+	namespace string
+	name      string
+	before    map[string]interface{}
+}
+
+func newObjectSnapshot(c *gin.Context, k8sClient *services.K8sClient, gvr schema.GroupVersionResource, namespace, name string) *objectSnapshot {
+	clusterID, _ := parseClusterID(c.Param("clusterID"))
+	dyn, err := k8sClient.GetDynamicClient()
+	if err != nil {
+		logger.Warn("创建快照客户端失败: %s %s/%s, err=%v", gvr.Resource, namespace, name, err)
+		return nil
+	}
+	return &objectSnapshot{c: c, dyn: dyn, clusterID: clusterID, gvr: gvr, namespace: namespace, name: name}
+}
+
+// beginSnapshot 在变更前读取路由所属集群中的对象（namespace 为空表示集群级资源），读取失败时返回 nil
+func beginSnapshot(c *gin.Context, k8sClient *services.K8sClient, gvr schema.GroupVersionResource, namespace, name string) *objectSnapshot {
+	s := newObjectSnapshot(c, k8sClient, gvr, namespace, name)
+	if s == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+	before, err := resourcesnapshot.Capture(ctx, s.dyn, gvr, namespace, name)
+	if err != nil {
+		logger.Warn("读取变更前快照失败: %s %s/%s, err=%v", gvr.Resource, namespace, name, err)
+		return nil
+	}
+	s.before = before
+	return s
+}
+
+// recordCreated 创建成功后记录快照（变更前对象为空），用于创建前无法确定对象名的场景
+func recordCreated(c *gin.Context, k8sClient *services.K8sClient, gvr schema.GroupVersionResource, namespace, name string) {
+	newObjectSnapshot(c, k8sClient, gvr, namespace, name).Done()
+}
+
+// Done 变更成功后调用，读取变更后的对象并记录快照
+func (s *objectSnapshot) Done() {
+	if s == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+	after, err := resourcesnapshot.Capture(ctx, s.dyn, s.gvr, s.namespace, s.name)
+	if err != nil {
+		logger.Warn("读取变更后快照失败: %s %s/%s, err=%v", s.gvr.Resource, s.namespace, s.name, err)
+		return
+	}
+	s.record(after)
+}
+
+// Deleted 删除成功后调用（对象可能仍在终止中，按已删除记录）
+func (s *objectSnapshot) Deleted() {
+	if s == nil {
+		return
+	}
+	s.record(nil)
+}
+
+func (s *objectSnapshot) record(after map[string]interface{}) {
+	snap, err := resourcesnapshot.Build(s.clusterID, s.gvr, s.namespace, s.name, s.before, after)
+	if err != nil {
+		logger.Warn("生成对象快照失败: %s %s/%s, err=%v", s.gvr.Resource, s.namespace, s.name, err)
+		return
+	}
+	var snaps []*models.OperationLogSnapshot
+	if v, exists := s.c.Get(middleware.AuditSnapshotsKey); exists {
+		snaps, _ = v.([]*models.OperationLogSnapshot)
+	}
+	s.c.Set(middleware.AuditSnapshotsKey, append(snaps, snap))
+}
+
+// beginApplySnapshot YAML 应用前读取对象，试运行不修改对象，不记录快照
+func beginApplySnapshot(c *gin.Context, k8sClient *services.K8sClient, gvr schema.GroupVersionResource, namespace, name string, dryRun bool) *objectSnapshot {
+	if dryRun {
+		return nil
+	}
+	return beginSnapshot(c, k8sClient, gvr, namespace, name)
+}
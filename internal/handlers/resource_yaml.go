@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
@@ -57,7 +58,7 @@ type ResourceYAMLResponse struct {
 func (h *ResourceYAMLHandler) ApplyConfigMapYAML(c *gin.Context) {
 	var req ResourceYAMLApplyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -69,13 +70,13 @@ func (h *ResourceYAMLHandler) ApplyConfigMapYAML(c *gin.Context) {
 	// 解析YAML
 	var cm corev1.ConfigMap
 	if err := yaml.Unmarshal([]byte(req.YAML), &cm); err != nil {
-		response.BadRequest(c, "YAML格式错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InvalidYAML))
 		return
 	}
 
 	// 验证kind
 	if cm.Kind != "" && cm.Kind != "ConfigMap" {
-		response.BadRequest(c, "YAML类型错误，期望ConfigMap，实际为: "+cm.Kind)
+		response.FailCode(c, errcode.YAMLKindMismatch, "ConfigMap", cm.Kind)
 		return
 	}
 
@@ -92,6 +93,7 @@ func (h *ResourceYAMLHandler) ApplyConfigMapYAML(c *gin.Context) {
 		dryRunOpt = []string{metav1.DryRunAll}
 	}
 
+	snap := beginApplySnapshot(c, k8sClient, gvrConfigMaps, cm.Namespace, cm.Name, req.DryRun)
 	// 尝试获取现有资源
 	existing, err := clientset.CoreV1().ConfigMaps(cm.Namespace).Get(ctx, cm.Name, metav1.GetOptions{})
 	var result *corev1.ConfigMap
@@ -102,7 +104,7 @@ func (h *ResourceYAMLHandler) ApplyConfigMapYAML(c *gin.Context) {
 		cm.ResourceVersion = existing.ResourceVersion
 		result, err = clientset.CoreV1().ConfigMaps(cm.Namespace).Update(ctx, &cm, metav1.UpdateOptions{DryRun: dryRunOpt})
 		if err != nil {
-			response.InternalError(c, "更新ConfigMap失败: "+err.Error())
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceUpdateFailed, "ConfigMap"))
 			return
 		}
 	} else {
@@ -110,11 +112,13 @@ func (h *ResourceYAMLHandler) ApplyConfigMapYAML(c *gin.Context) {
 		isCreated = true
 		result, err = clientset.CoreV1().ConfigMaps(cm.Namespace).Create(ctx, &cm, metav1.CreateOptions{DryRun: dryRunOpt})
 		if err != nil {
-			response.InternalError(c, "创建ConfigMap失败: "+err.Error())
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceCreateFailed, "ConfigMap"))
 			return
 		}
 	}
 
+	snap.Done()
+
 	response.OK(c, ResourceYAMLResponse{
 		Name:            result.Name,
 		Namespace:       result.Namespace,
@@ -135,7 +139,7 @@ func (h *ResourceYAMLHandler) GetConfigMapYAML(c *gin.Context) {
 
 	cm, err := k8sClient.GetClientset().CoreV1().ConfigMaps(c.Param("namespace")).Get(ctx, c.Param("name"), metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "ConfigMap不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "ConfigMap"))
 		return
 	}
 	clean := cm.DeepCopy()
@@ -149,7 +153,7 @@ func (h *ResourceYAMLHandler) GetConfigMapYAML(c *gin.Context) {
 func (h *ResourceYAMLHandler) ApplySecretYAML(c *gin.Context) {
 	var req ResourceYAMLApplyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -160,12 +164,12 @@ func (h *ResourceYAMLHandler) ApplySecretYAML(c *gin.Context) {
 
 	var secret corev1.Secret
 	if err := yaml.Unmarshal([]byte(req.YAML), &secret); err != nil {
-		response.BadRequest(c, "YAML格式错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InvalidYAML))
 		return
 	}
 
 	if secret.Kind != "" && secret.Kind != "Secret" {
-		response.BadRequest(c, "YAML类型错误，期望Secret，实际为: "+secret.Kind)
+		response.FailCode(c, errcode.YAMLKindMismatch, "Secret", secret.Kind)
 		return
 	}
 
@@ -182,6 +186,7 @@ func (h *ResourceYAMLHandler) ApplySecretYAML(c *gin.Context) {
 		dryRunOpt = []string{metav1.DryRunAll}
 	}
 
+	snap := beginApplySnapshot(c, k8sClient, gvrSecrets, secret.Namespace, secret.Name, req.DryRun)
 	existing, err := clientset.CoreV1().Secrets(secret.Namespace).Get(ctx, secret.Name, metav1.GetOptions{})
 	var result *corev1.Secret
 	isCreated := false
@@ -190,18 +195,20 @@ func (h *ResourceYAMLHandler) ApplySecretYAML(c *gin.Context) {
 		secret.ResourceVersion = existing.ResourceVersion
 		result, err = clientset.CoreV1().Secrets(secret.Namespace).Update(ctx, &secret, metav1.UpdateOptions{DryRun: dryRunOpt})
 		if err != nil {
-			response.InternalError(c, "更新Secret失败: "+err.Error())
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceUpdateFailed, "Secret"))
 			return
 		}
 	} else {
 		isCreated = true
 		result, err = clientset.CoreV1().Secrets(secret.Namespace).Create(ctx, &secret, metav1.CreateOptions{DryRun: dryRunOpt})
 		if err != nil {
-			response.InternalError(c, "创建Secret失败: "+err.Error())
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceCreateFailed, "Secret"))
 			return
 		}
 	}
 
+	snap.Done()
+
 	response.OK(c, ResourceYAMLResponse{
 		Name:            result.Name,
 		Namespace:       result.Namespace,
@@ -219,18 +226,18 @@ func (h *ResourceYAMLHandler) GetSecretYAML(c *gin.Context) {
 
 	id, err := parseClusterID(clusterID)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(id)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	k8sClient, err := h.createK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "创建K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -239,7 +246,7 @@ func (h *ResourceYAMLHandler) GetSecretYAML(c *gin.Context) {
 
 	secret, err := k8sClient.GetClientset().CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "Secret不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "Secret"))
 		return
 	}
 
@@ -250,7 +257,7 @@ func (h *ResourceYAMLHandler) GetSecretYAML(c *gin.Context) {
 
 	yamlBytes, err := sigsyaml.Marshal(cleanSecret)
 	if err != nil {
-		response.InternalError(c, "转换YAML失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.YAMLMarshalFailed))
 		return
 	}
 
@@ -263,7 +270,7 @@ func (h *ResourceYAMLHandler) GetSecretYAML(c *gin.Context) {
 func (h *ResourceYAMLHandler) ApplyServiceYAML(c *gin.Context) {
 	var req ResourceYAMLApplyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -274,12 +281,12 @@ func (h *ResourceYAMLHandler) ApplyServiceYAML(c *gin.Context) {
 
 	var svc corev1.Service
 	if err := yaml.Unmarshal([]byte(req.YAML), &svc); err != nil {
-		response.BadRequest(c, "YAML格式错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InvalidYAML))
 		return
 	}
 
 	if svc.Kind != "" && svc.Kind != "Service" {
-		response.BadRequest(c, "YAML类型错误，期望Service，实际为: "+svc.Kind)
+		response.FailCode(c, errcode.YAMLKindMismatch, "Service", svc.Kind)
 		return
 	}
 
@@ -296,6 +303,7 @@ func (h *ResourceYAMLHandler) ApplyServiceYAML(c *gin.Context) {
 		dryRunOpt = []string{metav1.DryRunAll}
 	}
 
+	snap := beginApplySnapshot(c, k8sClient, gvrServices, svc.Namespace, svc.Name, req.DryRun)
 	existing, err := clientset.CoreV1().Services(svc.Namespace).Get(ctx, svc.Name, metav1.GetOptions{})
 	var result *corev1.Service
 	isCreated := false
@@ -307,18 +315,20 @@ func (h *ResourceYAMLHandler) ApplyServiceYAML(c *gin.Context) {
 		svc.Spec.ClusterIPs = existing.Spec.ClusterIPs
 		result, err = clientset.CoreV1().Services(svc.Namespace).Update(ctx, &svc, metav1.UpdateOptions{DryRun: dryRunOpt})
 		if err != nil {
-			response.InternalError(c, "更新Service失败: "+err.Error())
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceUpdateFailed, "Service"))
 			return
 		}
 	} else {
 		isCreated = true
 		result, err = clientset.CoreV1().Services(svc.Namespace).Create(ctx, &svc, metav1.CreateOptions{DryRun: dryRunOpt})
 		if err != nil {
-			response.InternalError(c, "创建Service失败: "+err.Error())
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceCreateFailed, "Service"))
 			return
 		}
 	}
 
+	snap.Done()
+
 	response.OK(c, ResourceYAMLResponse{
 		Name:            result.Name,
 		Namespace:       result.Namespace,
@@ -332,7 +342,7 @@ func (h *ResourceYAMLHandler) ApplyServiceYAML(c *gin.Context) {
 func (h *ResourceYAMLHandler) ApplyIngressYAML(c *gin.Context) {
 	var req ResourceYAMLApplyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -343,12 +353,12 @@ func (h *ResourceYAMLHandler) ApplyIngressYAML(c *gin.Context) {
 
 	var ing networkingv1.Ingress
 	if err := yaml.Unmarshal([]byte(req.YAML), &ing); err != nil {
-		response.BadRequest(c, "YAML格式错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InvalidYAML))
 		return
 	}
 
 	if ing.Kind != "" && ing.Kind != "Ingress" {
-		response.BadRequest(c, "YAML类型错误，期望Ingress，实际为: "+ing.Kind)
+		response.FailCode(c, errcode.YAMLKindMismatch, "Ingress", ing.Kind)
 		return
 	}
 
@@ -365,6 +375,7 @@ func (h *ResourceYAMLHandler) ApplyIngressYAML(c *gin.Context) {
 		dryRunOpt = []string{metav1.DryRunAll}
 	}
 
+	snap := beginApplySnapshot(c, k8sClient, gvrIngresses, ing.Namespace, ing.Name, req.DryRun)
 	existing, err := clientset.NetworkingV1().Ingresses(ing.Namespace).Get(ctx, ing.Name, metav1.GetOptions{})
 	var result *networkingv1.Ingress
 	isCreated := false
@@ -373,18 +384,20 @@ func (h *ResourceYAMLHandler) ApplyIngressYAML(c *gin.Context) {
 		ing.ResourceVersion = existing.ResourceVersion
 		result, err = clientset.NetworkingV1().Ingresses(ing.Namespace).Update(ctx, &ing, metav1.UpdateOptions{DryRun: dryRunOpt})
 		if err != nil {
-			response.InternalError(c, "更新Ingress失败: "+err.Error())
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceUpdateFailed, "Ingress"))
 			return
 		}
 	} else {
 		isCreated = true
 		result, err = clientset.NetworkingV1().Ingresses(ing.Namespace).Create(ctx, &ing, metav1.CreateOptions{DryRun: dryRunOpt})
 		if err != nil {
-			response.InternalError(c, "创建Ingress失败: "+err.Error())
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceCreateFailed, "Ingress"))
 			return
 		}
 	}
 
+	snap.Done()
+
 	response.OK(c, ResourceYAMLResponse{
 		Name:            result.Name,
 		Namespace:       result.Namespace,
@@ -398,7 +411,7 @@ func (h *ResourceYAMLHandler) ApplyIngressYAML(c *gin.Context) {
 func (h *ResourceYAMLHandler) ApplyPVCYAML(c *gin.Context) {
 	var req ResourceYAMLApplyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -409,12 +422,12 @@ func (h *ResourceYAMLHandler) ApplyPVCYAML(c *gin.Context) {
 
 	var pvc corev1.PersistentVolumeClaim
 	if err := yaml.Unmarshal([]byte(req.YAML), &pvc); err != nil {
-		response.BadRequest(c, "YAML格式错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InvalidYAML))
 		return
 	}
 
 	if pvc.Kind != "" && pvc.Kind != "PersistentVolumeClaim" {
-		response.BadRequest(c, "YAML类型错误，期望PersistentVolumeClaim，实际为: "+pvc.Kind)
+		response.FailCode(c, errcode.YAMLKindMismatch, "PersistentVolumeClaim", pvc.Kind)
 		return
 	}
 
@@ -431,6 +444,7 @@ func (h *ResourceYAMLHandler) ApplyPVCYAML(c *gin.Context) {
 		dryRunOpt = []string{metav1.DryRunAll}
 	}
 
+	snap := beginApplySnapshot(c, k8sClient, gvrPVCs, pvc.Namespace, pvc.Name, req.DryRun)
 	existing, err := clientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Get(ctx, pvc.Name, metav1.GetOptions{})
 	var result *corev1.PersistentVolumeClaim
 	isCreated := false
@@ -441,18 +455,20 @@ func (h *ResourceYAMLHandler) ApplyPVCYAML(c *gin.Context) {
 		pvc.Spec.VolumeName = existing.Spec.VolumeName
 		result, err = clientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Update(ctx, &pvc, metav1.UpdateOptions{DryRun: dryRunOpt})
 		if err != nil {
-			response.InternalError(c, "更新PVC失败: "+err.Error())
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceUpdateFailed, "PVC"))
 			return
 		}
 	} else {
 		isCreated = true
 		result, err = clientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(ctx, &pvc, metav1.CreateOptions{DryRun: dryRunOpt})
 		if err != nil {
-			response.InternalError(c, "创建PVC失败: "+err.Error())
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceCreateFailed, "PVC"))
 			return
 		}
 	}
 
+	snap.Done()
+
 	response.OK(c, ResourceYAMLResponse{
 		Name:            result.Name,
 		Namespace:       result.Namespace,
@@ -466,7 +482,7 @@ func (h *ResourceYAMLHandler) ApplyPVCYAML(c *gin.Context) {
 func (h *ResourceYAMLHandler) ApplyPVYAML(c *gin.Context) {
 	var req ResourceYAMLApplyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -477,12 +493,12 @@ func (h *ResourceYAMLHandler) ApplyPVYAML(c *gin.Context) {
 
 	var pv corev1.PersistentVolume
 	if err := yaml.Unmarshal([]byte(req.YAML), &pv); err != nil {
-		response.BadRequest(c, "YAML格式错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InvalidYAML))
 		return
 	}
 
 	if pv.Kind != "" && pv.Kind != "PersistentVolume" {
-		response.BadRequest(c, "YAML类型错误，期望PersistentVolume，实际为: "+pv.Kind)
+		response.FailCode(c, errcode.YAMLKindMismatch, "PersistentVolume", pv.Kind)
 		return
 	}
 
@@ -495,6 +511,7 @@ func (h *ResourceYAMLHandler) ApplyPVYAML(c *gin.Context) {
 		dryRunOpt = []string{metav1.DryRunAll}
 	}
 
+	snap := beginApplySnapshot(c, k8sClient, gvrPVs, "", pv.Name, req.DryRun)
 	existing, err := clientset.CoreV1().PersistentVolumes().Get(ctx, pv.Name, metav1.GetOptions{})
 	var result *corev1.PersistentVolume
 	isCreated := false
@@ -503,18 +520,20 @@ func (h *ResourceYAMLHandler) ApplyPVYAML(c *gin.Context) {
 		pv.ResourceVersion = existing.ResourceVersion
 		result, err = clientset.CoreV1().PersistentVolumes().Update(ctx, &pv, metav1.UpdateOptions{DryRun: dryRunOpt})
 		if err != nil {
-			response.InternalError(c, "更新PV失败: "+err.Error())
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceUpdateFailed, "PV"))
 			return
 		}
 	} else {
 		isCreated = true
 		result, err = clientset.CoreV1().PersistentVolumes().Create(ctx, &pv, metav1.CreateOptions{DryRun: dryRunOpt})
 		if err != nil {
-			response.InternalError(c, "创建PV失败: "+err.Error())
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceCreateFailed, "PV"))
 			return
 		}
 	}
 
+	snap.Done()
+
 	response.OK(c, ResourceYAMLResponse{
 		Name:            result.Name,
 		Kind:            "PersistentVolume",
@@ -527,7 +546,7 @@ func (h *ResourceYAMLHandler) ApplyPVYAML(c *gin.Context) {
 func (h *ResourceYAMLHandler) ApplyStorageClassYAML(c *gin.Context) {
 	var req ResourceYAMLApplyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -538,12 +557,12 @@ func (h *ResourceYAMLHandler) ApplyStorageClassYAML(c *gin.Context) {
 
 	var sc storagev1.StorageClass
 	if err := yaml.Unmarshal([]byte(req.YAML), &sc); err != nil {
-		response.BadRequest(c, "YAML格式错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InvalidYAML))
 		return
 	}
 
 	if sc.Kind != "" && sc.Kind != "StorageClass" {
-		response.BadRequest(c, "YAML类型错误，期望StorageClass，实际为: "+sc.Kind)
+		response.FailCode(c, errcode.YAMLKindMismatch, "StorageClass", sc.Kind)
 		return
 	}
 
@@ -556,6 +575,7 @@ func (h *ResourceYAMLHandler) ApplyStorageClassYAML(c *gin.Context) {
 		dryRunOpt = []string{metav1.DryRunAll}
 	}
 
+	snap := beginApplySnapshot(c, k8sClient, gvrStorageClass, "", sc.Name, req.DryRun)
 	existing, err := clientset.StorageV1().StorageClasses().Get(ctx, sc.Name, metav1.GetOptions{})
 	var result *storagev1.StorageClass
 	isCreated := false
@@ -564,18 +584,20 @@ func (h *ResourceYAMLHandler) ApplyStorageClassYAML(c *gin.Context) {
 		sc.ResourceVersion = existing.ResourceVersion
 		result, err = clientset.StorageV1().StorageClasses().Update(ctx, &sc, metav1.UpdateOptions{DryRun: dryRunOpt})
 		if err != nil {
-			response.InternalError(c, "更新StorageClass失败: "+err.Error())
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceUpdateFailed, "StorageClass"))
 			return
 		}
 	} else {
 		isCreated = true
 		result, err = clientset.StorageV1().StorageClasses().Create(ctx, &sc, metav1.CreateOptions{DryRun: dryRunOpt})
 		if err != nil {
-			response.InternalError(c, "创建StorageClass失败: "+err.Error())
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceCreateFailed, "StorageClass"))
 			return
 		}
 	}
 
+	snap.Done()
+
 	response.OK(c, ResourceYAMLResponse{
 		Name:            result.Name,
 		Kind:            "StorageClass",
@@ -594,17 +616,17 @@ func (h *ResourceYAMLHandler) prepareK8sClient(c *gin.Context) (*services.K8sCli
 	clusterID := c.Param("clusterID")
 	id, err := parseClusterID(clusterID)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return nil, false
 	}
 	cluster, err := h.clusterService.GetCluster(id)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return nil, false
 	}
 	k8sClient, err := h.createK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "创建K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return nil, false
 	}
 	return k8sClient, true
@@ -614,7 +636,7 @@ func (h *ResourceYAMLHandler) prepareK8sClient(c *gin.Context) (*services.K8sCli
 func respondWithYAML(c *gin.Context, obj interface{}) {
 	yamlBytes, err := sigsyaml.Marshal(obj)
 	if err != nil {
-		response.InternalError(c, "转换YAML失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.YAMLMarshalFailed))
 		return
 	}
 	response.OK(c, gin.H{"yaml": string(yamlBytes)})
@@ -631,7 +653,7 @@ func (h *ResourceYAMLHandler) GetServiceYAMLClean(c *gin.Context) {
 
 	svc, err := k8sClient.GetClientset().CoreV1().Services(c.Param("namespace")).Get(ctx, c.Param("name"), metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "Service不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "Service"))
 		return
 	}
 	clean := svc.DeepCopy()
@@ -652,7 +674,7 @@ func (h *ResourceYAMLHandler) GetIngressYAMLClean(c *gin.Context) {
 
 	ing, err := k8sClient.GetClientset().NetworkingV1().Ingresses(c.Param("namespace")).Get(ctx, c.Param("name"), metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "Ingress不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "Ingress"))
 		return
 	}
 	clean := ing.DeepCopy()
@@ -673,7 +695,7 @@ func (h *ResourceYAMLHandler) GetPVCYAMLClean(c *gin.Context) {
 
 	pvc, err := k8sClient.GetClientset().CoreV1().PersistentVolumeClaims(c.Param("namespace")).Get(ctx, c.Param("name"), metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "PVC不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "PVC"))
 		return
 	}
 	clean := pvc.DeepCopy()
@@ -694,7 +716,7 @@ func (h *ResourceYAMLHandler) GetPVYAMLClean(c *gin.Context) {
 
 	pv, err := k8sClient.GetClientset().CoreV1().PersistentVolumes().Get(ctx, c.Param("name"), metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "PV不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "PV"))
 		return
 	}
 	clean := pv.DeepCopy()
@@ -715,7 +737,7 @@ func (h *ResourceYAMLHandler) GetStorageClassYAMLClean(c *gin.Context) {
 
 	sc, err := k8sClient.GetClientset().StorageV1().StorageClasses().Get(ctx, c.Param("name"), metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "StorageClass不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "StorageClass"))
 		return
 	}
 	clean := sc.DeepCopy()
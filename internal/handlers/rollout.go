@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
@@ -74,12 +75,12 @@ func (h *RolloutHandler) CheckRolloutCRD(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -88,7 +89,7 @@ func (h *RolloutHandler) CheckRolloutCRD(c *gin.Context) {
 
 	// 确保 informer 缓存就绪
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 
@@ -112,12 +113,12 @@ func (h *RolloutHandler) ListRollouts(c *gin.Context) {
 	// 获取集群信息
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -126,7 +127,7 @@ func (h *RolloutHandler) ListRollouts(c *gin.Context) {
 
 	// 确保 informer 缓存就绪
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 
@@ -151,7 +152,7 @@ func (h *RolloutHandler) ListRollouts(c *gin.Context) {
 	// 检查命名空间权限
 	nsInfo, hasAccess := middleware.CheckNamespacePermission(c, namespace)
 	if !hasAccess {
-		response.Forbidden(c, fmt.Sprintf("无权访问命名空间: %s", namespace))
+		response.FailCode(c, errcode.NamespaceAccessDenied)
 		return
 	}
 
@@ -225,19 +226,19 @@ func (h *RolloutHandler) GetRollout(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -246,13 +247,13 @@ func (h *RolloutHandler) GetRollout(c *gin.Context) {
 
 	rolloutClient, err := k8sClient.GetRolloutClient()
 	if err != nil {
-		response.InternalError(c, "获取Rollout客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.RolloutClientFailed))
 		return
 	}
 
 	rollout, err := rolloutClient.ArgoprojV1alpha1().Rollouts(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "Rollout不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "Rollout"))
 		return
 	}
 
@@ -295,12 +296,12 @@ func (h *RolloutHandler) GetRolloutNamespaces(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -309,7 +310,7 @@ func (h *RolloutHandler) GetRolloutNamespaces(c *gin.Context) {
 
 	// 确保 informer 缓存就绪
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 
@@ -323,7 +324,7 @@ func (h *RolloutHandler) GetRolloutNamespaces(c *gin.Context) {
 	}
 	rs, err := lister.List(sel)
 	if err != nil {
-		response.InternalError(c, "读取Rollout缓存失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceCacheFailed, "Rollout"))
 		return
 	}
 
@@ -363,7 +364,7 @@ func (h *RolloutHandler) ScaleRollout(c *gin.Context) {
 
 	var req ScaleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -371,19 +372,19 @@ func (h *RolloutHandler) ScaleRollout(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -392,24 +393,26 @@ func (h *RolloutHandler) ScaleRollout(c *gin.Context) {
 
 	rolloutClient, err := k8sClient.GetRolloutClient()
 	if err != nil {
-		response.InternalError(c, "获取Rollout客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.RolloutClientFailed))
 		return
 	}
 
 	// 获取Rollout
 	rollout, err := rolloutClient.ArgoprojV1alpha1().Rollouts(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		response.InternalError(c, "获取Rollout失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceGetFailed, "Rollout"))
 		return
 	}
 
 	// 更新副本数
 	rollout.Spec.Replicas = &req.Replicas
+	snap := beginSnapshot(c, k8sClient, gvrRollouts, namespace, name)
 	_, err = rolloutClient.ArgoprojV1alpha1().Rollouts(namespace).Update(ctx, rollout, metav1.UpdateOptions{})
 	if err != nil {
-		response.InternalError(c, "扩缩容失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ScaleFailed))
 		return
 	}
+	snap.Done()
 
 	response.OK(c, gin.H{"message": "扩缩容成功"})
 }
@@ -420,7 +423,7 @@ func (h *RolloutHandler) ApplyYAML(c *gin.Context) {
 
 	var req YAMLApplyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -428,19 +431,19 @@ func (h *RolloutHandler) ApplyYAML(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -450,26 +453,26 @@ func (h *RolloutHandler) ApplyYAML(c *gin.Context) {
 	// 解析YAML
 	var objMap map[string]interface{}
 	if err := yaml.Unmarshal([]byte(req.YAML), &objMap); err != nil {
-		response.BadRequest(c, "YAML格式错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InvalidYAML))
 		return
 	}
 
 	// 验证必要字段
 	if objMap["apiVersion"] == nil || objMap["kind"] == nil {
-		response.BadRequest(c, "YAML缺少必要字段: apiVersion 或 kind")
+		response.FailCode(c, errcode.YAMLMissingKind)
 		return
 	}
 
 	kind := objMap["kind"].(string)
 	if kind != "Rollout" {
-		response.BadRequest(c, "YAML类型错误，期望Rollout，实际为: "+kind)
+		response.FailCode(c, errcode.YAMLKindMismatch, "Rollout", kind)
 		return
 	}
 
 	// 获取metadata
 	metadata, ok := objMap["metadata"].(map[string]interface{})
 	if !ok {
-		response.BadRequest(c, "YAML缺少 metadata 字段")
+		response.FailCode(c, errcode.YAMLMissingMetadata)
 		return
 	}
 
@@ -478,12 +481,15 @@ func (h *RolloutHandler) ApplyYAML(c *gin.Context) {
 		namespace = "default"
 	}
 
+	name, _ := metadata["name"].(string)
+	snap := beginApplySnapshot(c, k8sClient, gvrRollouts, namespace, name, req.DryRun)
 	// 应用YAML
 	result, err := h.applyYAML(ctx, k8sClient, req.YAML, namespace, req.DryRun)
 	if err != nil {
-		response.InternalError(c, "YAML应用失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.YAMLApplyFailed))
 		return
 	}
+	snap.Done()
 
 	response.OK(c, result)
 }
@@ -498,19 +504,19 @@ func (h *RolloutHandler) DeleteRollout(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -519,15 +525,17 @@ func (h *RolloutHandler) DeleteRollout(c *gin.Context) {
 
 	rolloutClient, err := k8sClient.GetRolloutClient()
 	if err != nil {
-		response.InternalError(c, "获取Rollout客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.RolloutClientFailed))
 		return
 	}
 
+	snap := beginSnapshot(c, k8sClient, gvrRollouts, namespace, name)
 	err = rolloutClient.ArgoprojV1alpha1().Rollouts(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 	if err != nil {
-		response.InternalError(c, "删除失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.DeleteFailed))
 		return
 	}
+	snap.Deleted()
 
 	response.OK(c, gin.H{"message": "删除成功"})
 }
@@ -631,19 +639,19 @@ func (h *RolloutHandler) GetRolloutPods(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -653,13 +661,13 @@ func (h *RolloutHandler) GetRolloutPods(c *gin.Context) {
 	// 获取Rollout
 	rolloutClient, err := k8sClient.GetRolloutClient()
 	if err != nil {
-		response.InternalError(c, "获取Rollout客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.RolloutClientFailed))
 		return
 	}
 
 	rollout, err := rolloutClient.ArgoprojV1alpha1().Rollouts(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "Rollout不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "Rollout"))
 		return
 	}
 
@@ -669,7 +677,7 @@ func (h *RolloutHandler) GetRolloutPods(c *gin.Context) {
 		LabelSelector: metav1.FormatLabelSelector(rollout.Spec.Selector),
 	})
 	if err != nil {
-		response.InternalError(c, "获取Pods失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Pod"))
 		return
 	}
 
@@ -737,19 +745,19 @@ func (h *RolloutHandler) GetRolloutServices(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -759,13 +767,13 @@ func (h *RolloutHandler) GetRolloutServices(c *gin.Context) {
 	// 获取Rollout
 	rolloutClient, err := k8sClient.GetRolloutClient()
 	if err != nil {
-		response.InternalError(c, "获取Rollout客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.RolloutClientFailed))
 		return
 	}
 
 	rollout, err := rolloutClient.ArgoprojV1alpha1().Rollouts(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "Rollout不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "Rollout"))
 		return
 	}
 
@@ -773,7 +781,7 @@ func (h *RolloutHandler) GetRolloutServices(c *gin.Context) {
 	clientset := k8sClient.GetClientset()
 	serviceList, err := clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		response.InternalError(c, "获取Services失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Service"))
 		return
 	}
 
@@ -829,19 +837,19 @@ func (h *RolloutHandler) GetRolloutIngresses(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -851,13 +859,13 @@ func (h *RolloutHandler) GetRolloutIngresses(c *gin.Context) {
 	// 获取Rollout对象
 	rolloutClient, err := k8sClient.GetRolloutClient()
 	if err != nil {
-		response.InternalError(c, "获取Rollout客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.RolloutClientFailed))
 		return
 	}
 
 	rollout, err := rolloutClient.ArgoprojV1alpha1().Rollouts(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "Rollout不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "Rollout"))
 		return
 	}
 
@@ -920,7 +928,7 @@ func (h *RolloutHandler) GetRolloutIngresses(c *gin.Context) {
 	// 获取Ingresses
 	ingressList, err := clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		response.InternalError(c, "获取Ingresses失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Ingress"))
 		return
 	}
 
@@ -1004,19 +1012,19 @@ func (h *RolloutHandler) GetRolloutHPA(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -1026,7 +1034,7 @@ func (h *RolloutHandler) GetRolloutHPA(c *gin.Context) {
 	clientset := k8sClient.GetClientset()
 	hpaList, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		response.InternalError(c, "获取HPA失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceGetFailed, "HPA"))
 		return
 	}
 
@@ -1052,19 +1060,19 @@ func (h *RolloutHandler) GetRolloutReplicaSets(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -1074,20 +1082,20 @@ func (h *RolloutHandler) GetRolloutReplicaSets(c *gin.Context) {
 	// 获取Rollout
 	rolloutClient, err := k8sClient.GetRolloutClient()
 	if err != nil {
-		response.InternalError(c, "获取Rollout客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.RolloutClientFailed))
 		return
 	}
 
 	_, err = rolloutClient.ArgoprojV1alpha1().Rollouts(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		response.NotFound(c, "Rollout不存在: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "Rollout"))
 		return
 	}
 
 	clientset := k8sClient.GetClientset()
 	replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		response.InternalError(c, "获取ReplicaSets失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "ReplicaSet"))
 		return
 	}
 
@@ -1115,19 +1123,19 @@ func (h *RolloutHandler) GetRolloutEvents(c *gin.Context) {
 
 	clusterID, err := parseClusterID(clusterId)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, "获取K8s客户端失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -1139,7 +1147,7 @@ func (h *RolloutHandler) GetRolloutEvents(c *gin.Context) {
 		FieldSelector: fmt.Sprintf("involvedObject.name=%s,involvedObject.kind=Rollout", name),
 	})
 	if err != nil {
-		response.InternalError(c, "获取Events失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Event"))
 		return
 	}
 
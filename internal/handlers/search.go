@@ -10,6 +10,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
@@ -55,17 +56,17 @@ type SearchResult struct {
 func (h *SearchHandler) GlobalSearch(c *gin.Context) {
 	query := c.Query("q")
 	if query == "" {
-		response.BadRequest(c, "搜索关键词不能为空")
+		response.FailCode(c, errcode.SearchKeywordRequired)
 		return
 	}
 
 	logger.Info("全局搜索: %s", query)
 
 	// 获取用户可访问的集群
-	clusters, err := h.getAccessibleClusters(c.GetUint("user_id"))
+	clusters, err := h.getAccessibleClusters(c)
 	if err != nil {
 		logger.Error("获取集群列表失败", "error", err)
-		response.InternalError(c, "获取集群列表失败")
+		response.FailCode(c, errcode.ClusterListFailed)
 		return
 	}
 
@@ -279,6 +280,8 @@ func (h *SearchHandler) GlobalSearch(c *gin.Context) {
 		}
 	}
 
+	results = h.filterTenantResults(c, results)
+
 	// 计算统计信息
 	stats := struct {
 		Cluster  int `json:"cluster"`
@@ -326,10 +329,10 @@ func (h *SearchHandler) QuickSearch(c *gin.Context) {
 	logger.Info("快速搜索: %s", query)
 
 	// 获取用户可访问的集群
-	clusters, err := h.getAccessibleClusters(c.GetUint("user_id"))
+	clusters, err := h.getAccessibleClusters(c)
 	if err != nil {
 		logger.Error("获取集群列表失败", "error", err)
-		response.InternalError(c, "获取集群列表失败")
+		response.FailCode(c, errcode.ClusterListFailed)
 		return
 	}
 
@@ -550,20 +553,30 @@ func (h *SearchHandler) QuickSearch(c *gin.Context) {
 	for _, typeResult := range typeResults {
 		results = append(results, typeResult...)
 	}
+	results = h.filterTenantResults(c, results)
 
 	response.OK(c, gin.H{
 		"results": results,
 	})
 }
 
-// getAccessibleClusters 获取用户可访问的集群列表
-func (h *SearchHandler) getAccessibleClusters(userID uint) ([]*models.Cluster, error) {
-	clusterIDs, isAll, err := h.permissionSvc.GetUserAccessibleClusterIDs(userID)
+// getAccessibleClusters 获取用户可访问的集群列表（支持 labelSelector / clusterGroupId 按标签过滤）
+func (h *SearchHandler) getAccessibleClusters(c *gin.Context) ([]*models.Cluster, error) {
+	selector, err := services.ResolveClusterLabelFilter(h.db, c.Query("labelSelector"), c.Query("clusterGroupId"))
+	if err != nil {
+		return nil, err
+	}
+
+	clusterIDs, isAll, err := h.permissionSvc.GetUserAccessibleClusterIDs(c.GetUint("user_id"))
 	if err != nil {
 		return nil, err
 	}
 	if isAll {
-		return h.clusterSvc.GetAllClusters()
+		clusters, err := h.clusterSvc.GetAllClusters()
+		if err != nil {
+			return nil, err
+		}
+		return services.FilterClustersByLabels(clusters, selector), nil
 	}
 	if len(clusterIDs) == 0 {
 		return []*models.Cluster{}, nil
@@ -572,7 +585,30 @@ func (h *SearchHandler) getAccessibleClusters(userID uint) ([]*models.Cluster, e
 	if err := h.db.Where("id IN ?", clusterIDs).Find(&clusters).Error; err != nil {
 		return nil, fmt.Errorf("获取集群列表失败: %w", err)
 	}
-	return clusters, nil
+	return services.FilterClustersByLabels(clusters, selector), nil
+}
+
+// filterTenantResults 租户成员只保留本租户命名空间内的结果（不返回节点）
+func (h *SearchHandler) filterTenantResults(c *gin.Context, results []SearchResult) []SearchResult {
+	scope, err := services.LoadTenantScope(h.db, c.GetUint("user_id"))
+	if err != nil || scope == nil {
+		return results
+	}
+
+	filtered := make([]SearchResult, 0, len(results))
+	for _, result := range results {
+		if result.Type == "node" {
+			continue
+		}
+		if result.Namespace != "" {
+			clusterID, _ := strconv.ParseUint(result.ClusterID, 10, 64)
+			if !scope.Allows(uint(clusterID), result.Namespace) {
+				continue
+			}
+		}
+		filtered = append(filtered, result)
+	}
+	return filtered
 }
 
 // getNodeStatus 获取节点状态
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"context"
-	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -14,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
@@ -82,13 +82,13 @@ func (h *SecretHandler) GetSecrets(c *gin.Context) {
 	// 获取集群
 	id, err := strconv.ParseUint(clusterID, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterSvc.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -97,14 +97,14 @@ func (h *SecretHandler) GetSecrets(c *gin.Context) {
 	defer cancel()
 
 	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
-		response.ServiceUnavailable(c, "informer 未就绪: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.InformerNotReady))
 		return
 	}
 
 	// 检查命名空间权限
 	nsInfo, hasAccess := middleware.CheckNamespacePermission(c, namespace)
 	if !hasAccess {
-		response.Forbidden(c, fmt.Sprintf("无权访问命名空间: %s", namespace))
+		response.FailCode(c, errcode.NamespaceAccessDenied)
 		return
 	}
 
@@ -117,7 +117,7 @@ func (h *SecretHandler) GetSecrets(c *gin.Context) {
 		secs, err := h.k8sMgr.SecretsLister(cluster.ID).Secrets(namespace).List(sel)
 		if err != nil {
 			logger.Error("读取Secret缓存失败", "cluster", cluster.Name, "namespace", namespace, "error", err)
-			response.InternalError(c, fmt.Sprintf("获取Secret列表失败: %v", err))
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Secret"))
 			return
 		}
 		// 转换为 []corev1.Secret
@@ -129,7 +129,7 @@ func (h *SecretHandler) GetSecrets(c *gin.Context) {
 		secs, err := h.k8sMgr.SecretsLister(cluster.ID).List(sel)
 		if err != nil {
 			logger.Error("读取Secret缓存失败", "cluster", cluster.Name, "error", err)
-			response.InternalError(c, fmt.Sprintf("获取Secret列表失败: %v", err))
+			response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Secret"))
 			return
 		}
 		// 转换为 []corev1.Secret
@@ -195,20 +195,20 @@ func (h *SecretHandler) GetSecret(c *gin.Context) {
 	// 获取集群
 	id, err := strconv.ParseUint(clusterID, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterSvc.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -218,7 +218,7 @@ func (h *SecretHandler) GetSecret(c *gin.Context) {
 	secret, err := clientset.CoreV1().Secrets(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		logger.Error("获取Secret失败", "cluster", cluster.Name, "namespace", namespace, "name", name, "error", err)
-		response.NotFound(c, fmt.Sprintf("Secret不存在: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "Secret"))
 		return
 	}
 
@@ -250,13 +250,13 @@ func (h *SecretHandler) GetSecretNamespaces(c *gin.Context) {
 	// 获取集群
 	id, err := strconv.ParseUint(clusterID, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterSvc.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -264,7 +264,7 @@ func (h *SecretHandler) GetSecretNamespaces(c *gin.Context) {
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -274,7 +274,7 @@ func (h *SecretHandler) GetSecretNamespaces(c *gin.Context) {
 	secrets, err := clientset.CoreV1().Secrets("").List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		logger.Error("获取Secret列表失败", "cluster", cluster.Name, "error", err)
-		response.InternalError(c, fmt.Sprintf("获取Secret列表失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Secret"))
 		return
 	}
 
@@ -309,32 +309,34 @@ func (h *SecretHandler) DeleteSecret(c *gin.Context) {
 	// 获取集群
 	id, err := strconv.ParseUint(clusterID, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterSvc.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
 	clientset := k8sClient.GetClientset()
 
+	snap := beginSnapshot(c, k8sClient, gvrSecrets, namespace, name)
 	// 删除Secret
 	err = clientset.CoreV1().Secrets(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
 	if err != nil {
 		logger.Error("删除Secret失败", "cluster", cluster.Name, "namespace", namespace, "name", name, "error", err)
-		response.InternalError(c, fmt.Sprintf("删除Secret失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceDeleteFailed, "Secret"))
 		return
 	}
+	snap.Deleted()
 
 	response.NoContent(c)
 }
@@ -353,27 +355,27 @@ func (h *SecretHandler) CreateSecret(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, fmt.Sprintf("请求参数错误: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	// 获取集群
 	id, err := strconv.ParseUint(clusterID, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterSvc.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -403,12 +405,14 @@ func (h *SecretHandler) CreateSecret(c *gin.Context) {
 		Data: dataBytes,
 	}
 
+	snap := beginSnapshot(c, k8sClient, gvrSecrets, req.Namespace, req.Name)
 	created, err := clientset.CoreV1().Secrets(req.Namespace).Create(context.Background(), secret, metav1.CreateOptions{})
 	if err != nil {
 		logger.Error("创建Secret失败", "cluster", cluster.Name, "namespace", req.Namespace, "name", req.Name, "error", err)
-		response.InternalError(c, fmt.Sprintf("创建Secret失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceCreateFailed, "Secret"))
 		return
 	}
+	snap.Done()
 
 	response.OK(c, gin.H{
 		"name":      created.Name,
@@ -429,27 +433,27 @@ func (h *SecretHandler) UpdateSecret(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, fmt.Sprintf("请求参数错误: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
 	// 获取集群
 	id, err := strconv.ParseUint(clusterID, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterSvc.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -459,7 +463,7 @@ func (h *SecretHandler) UpdateSecret(c *gin.Context) {
 	secret, err := clientset.CoreV1().Secrets(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		logger.Error("获取Secret失败", "cluster", cluster.Name, "namespace", namespace, "name", name, "error", err)
-		response.NotFound(c, fmt.Sprintf("Secret不存在: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceNotFound, "Secret"))
 		return
 	}
 
@@ -474,12 +478,14 @@ func (h *SecretHandler) UpdateSecret(c *gin.Context) {
 	secret.Annotations = req.Annotations
 	secret.Data = dataBytes
 
+	snap := beginSnapshot(c, k8sClient, gvrSecrets, namespace, name)
 	updated, err := clientset.CoreV1().Secrets(namespace).Update(context.Background(), secret, metav1.UpdateOptions{})
 	if err != nil {
 		logger.Error("更新Secret失败", "cluster", cluster.Name, "namespace", namespace, "name", name, "error", err)
-		response.InternalError(c, fmt.Sprintf("更新Secret失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceUpdateFailed, "Secret"))
 		return
 	}
+	snap.Done()
 
 	response.OK(c, gin.H{
 		"name":            updated.Name,
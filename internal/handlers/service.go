@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
@@ -80,7 +81,7 @@ func (h *ServiceHandler) ListServices(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -95,7 +96,7 @@ func (h *ServiceHandler) ListServices(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -103,7 +104,7 @@ func (h *ServiceHandler) ListServices(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -112,7 +113,7 @@ func (h *ServiceHandler) ListServices(c *gin.Context) {
 	// 检查命名空间权限
 	nsInfo, hasAccess := middleware.CheckNamespacePermission(c, namespace)
 	if !hasAccess {
-		response.Forbidden(c, fmt.Sprintf("无权访问命名空间: %s", namespace))
+		response.FailCode(c, errcode.NamespaceAccessDenied)
 		return
 	}
 
@@ -120,7 +121,7 @@ func (h *ServiceHandler) ListServices(c *gin.Context) {
 	services, err := h.getServices(clientset, namespace)
 	if err != nil {
 		logger.Error("获取Services失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取Services失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Service"))
 		return
 	}
 
@@ -159,7 +160,7 @@ func (h *ServiceHandler) GetService(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -170,7 +171,7 @@ func (h *ServiceHandler) GetService(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -178,7 +179,7 @@ func (h *ServiceHandler) GetService(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -188,7 +189,7 @@ func (h *ServiceHandler) GetService(c *gin.Context) {
 	service, err := clientset.CoreV1().Services(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		logger.Error("获取Service失败", "error", err, "clusterId", clusterID, "namespace", namespace, "name", name)
-		response.InternalError(c, fmt.Sprintf("获取Service失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceGetFailed, "Service"))
 		return
 	}
 
@@ -202,7 +203,7 @@ func (h *ServiceHandler) GetServiceYAML(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -213,7 +214,7 @@ func (h *ServiceHandler) GetServiceYAML(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -221,7 +222,7 @@ func (h *ServiceHandler) GetServiceYAML(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -231,7 +232,7 @@ func (h *ServiceHandler) GetServiceYAML(c *gin.Context) {
 	service, err := clientset.CoreV1().Services(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		logger.Error("获取Service失败", "error", err, "clusterId", clusterID, "namespace", namespace, "name", name)
-		response.InternalError(c, fmt.Sprintf("获取Service失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceGetFailed, "Service"))
 		return
 	}
 
@@ -245,7 +246,7 @@ func (h *ServiceHandler) GetServiceYAML(c *gin.Context) {
 	yamlData, err := yaml.Marshal(cleanSvc)
 	if err != nil {
 		logger.Error("转换YAML失败", "error", err)
-		response.InternalError(c, fmt.Sprintf("转换YAML失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.YAMLMarshalFailed))
 		return
 	}
 
@@ -257,7 +258,7 @@ func (h *ServiceHandler) DeleteService(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -268,7 +269,7 @@ func (h *ServiceHandler) DeleteService(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -276,19 +277,21 @@ func (h *ServiceHandler) DeleteService(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
 	clientset := k8sClient.GetClientset()
 
+	snap := beginSnapshot(c, k8sClient, gvrServices, namespace, name)
 	// 删除Service
 	err = clientset.CoreV1().Services(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
 	if err != nil {
 		logger.Error("删除Service失败", "error", err, "clusterId", clusterID, "namespace", namespace, "name", name)
-		response.InternalError(c, fmt.Sprintf("删除Service失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceDeleteFailed, "Service"))
 		return
 	}
+	snap.Deleted()
 
 	logger.Info("Service删除成功", "clusterId", clusterID, "namespace", namespace, "name", name)
 	response.NoContent(c)
@@ -299,7 +302,7 @@ func (h *ServiceHandler) GetServiceEndpoints(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -310,7 +313,7 @@ func (h *ServiceHandler) GetServiceEndpoints(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -318,7 +321,7 @@ func (h *ServiceHandler) GetServiceEndpoints(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -328,7 +331,7 @@ func (h *ServiceHandler) GetServiceEndpoints(c *gin.Context) {
 	endpoints, err := clientset.CoreV1().Endpoints(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		logger.Error("获取Endpoints失败", "error", err, "clusterId", clusterID, "namespace", namespace, "name", name)
-		response.InternalError(c, fmt.Sprintf("获取Endpoints失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceGetFailed, "Endpoints"))
 		return
 	}
 
@@ -513,13 +516,13 @@ func (h *ServiceHandler) CreateService(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	var req CreateServiceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -527,7 +530,7 @@ func (h *ServiceHandler) CreateService(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -535,7 +538,7 @@ func (h *ServiceHandler) CreateService(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -551,15 +554,16 @@ func (h *ServiceHandler) CreateService(c *gin.Context) {
 		// 表单方式创建
 		service, err = h.createServiceFromForm(clientset, req.Namespace, req.FormData)
 	} else {
-		response.BadRequest(c, "必须提供YAML或表单数据")
+		response.FailCode(c, errcode.YAMLOrFormRequired)
 		return
 	}
 
 	if err != nil {
 		logger.Error("创建Service失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("创建Service失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceCreateFailed, "Service"))
 		return
 	}
+	recordCreated(c, k8sClient, gvrServices, service.Namespace, service.Name)
 
 	logger.Info("Service创建成功", "clusterId", clusterID, "namespace", service.Namespace, "name", service.Name)
 	response.OK(c, h.convertToServiceInfo(service))
@@ -570,7 +574,7 @@ func (h *ServiceHandler) UpdateService(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 	namespace := c.Param("namespace")
@@ -578,7 +582,7 @@ func (h *ServiceHandler) UpdateService(c *gin.Context) {
 
 	var req CreateServiceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "参数错误: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.BadRequest))
 		return
 	}
 
@@ -586,7 +590,7 @@ func (h *ServiceHandler) UpdateService(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -594,12 +598,13 @@ func (h *ServiceHandler) UpdateService(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
 	clientset := k8sClient.GetClientset()
 
+	snap := beginSnapshot(c, k8sClient, gvrServices, namespace, name)
 	var service *corev1.Service
 
 	// 根据更新方式选择处理逻辑
@@ -610,15 +615,16 @@ func (h *ServiceHandler) UpdateService(c *gin.Context) {
 		// 表单方式更新
 		service, err = h.updateServiceFromForm(clientset, namespace, name, req.FormData)
 	} else {
-		response.BadRequest(c, "必须提供YAML或表单数据")
+		response.FailCode(c, errcode.YAMLOrFormRequired)
 		return
 	}
 
 	if err != nil {
 		logger.Error("更新Service失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("更新Service失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceUpdateFailed, "Service"))
 		return
 	}
+	snap.Done()
 
 	logger.Info("Service更新成功", "clusterId", clusterID, "namespace", service.Namespace, "name", service.Name)
 	response.OK(c, h.convertToServiceInfo(service))
@@ -796,20 +802,20 @@ func (h *ServiceHandler) GetServiceNamespaces(c *gin.Context) {
 	// 获取集群
 	id, err := strconv.ParseUint(clusterID, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterService.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 	clientset := k8sClient.GetClientset()
@@ -818,7 +824,7 @@ func (h *ServiceHandler) GetServiceNamespaces(c *gin.Context) {
 	serviceList, err := clientset.CoreV1().Services("").List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		logger.Error("获取Service列表失败", "cluster", cluster.Name, "error", err)
-		response.InternalError(c, fmt.Sprintf("获取Service列表失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "Service"))
 		return
 	}
 
@@ -1,35 +1,54 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/internal/sshvault"
+	"github.com/clay-wangzhi/KubePolaris/internal/terminalhub"
 	"github.com/clay-wangzhi/KubePolaris/internal/terminalreplay"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // SSHHandler SSH终端处理器
 type SSHHandler struct {
-	auditService *services.AuditService
-	replayDir    string
+	clusterService *services.ClusterService
+	auditService   *services.AuditService
+	commandPolicy  *services.TerminalCommandPolicyService
+	liveHub        *terminalhub.Hub
+	k8sMgr         *k8s.ClusterInformerManager
+	replayStorage  *terminalreplay.Storage
+	vault          *services.SSHVaultService
 }
 
 // NewSSHHandler 创建SSH处理器
-func NewSSHHandler(auditService *services.AuditService, replayDir string) *SSHHandler {
+func NewSSHHandler(clusterService *services.ClusterService, auditService *services.AuditService, commandPolicy *services.TerminalCommandPolicyService, liveHub *terminalhub.Hub, k8sMgr *k8s.ClusterInformerManager, replayStorage *terminalreplay.Storage, vault *services.SSHVaultService) *SSHHandler {
 	return &SSHHandler{
-		auditService: auditService,
-		replayDir:    replayDir,
+		clusterService: clusterService,
+		auditService:   auditService,
+		commandPolicy:  commandPolicy,
+		liveHub:        liveHub,
+		k8sMgr:         k8sMgr,
+		replayStorage:  replayStorage,
+		vault:          vault,
 	}
 }
 
@@ -42,6 +61,7 @@ type SSHConfig struct {
 	PrivateKey string `json:"privateKey,omitempty"`
 	AuthType   string `json:"authType"` // "password" or "key"
 	ClusterID  uint   `json:"clusterId,omitempty"`
+	Node       string `json:"node,omitempty"` // 节点名称，未提供 host 时按节点 InternalIP 连接
 }
 
 // SSHMessage WebSocket消息
@@ -58,9 +78,12 @@ type SSHMessage struct {
 type SSHSession struct {
 	auditSessionID   uint
 	replay           *terminalreplay.Recorder
-	currentLine      strings.Builder // 当前行的输出内容
-	lastCompleteLine string          // 上一个完整行
-	pendingEnter     bool            // 是否有待处理的回车键
+	guard            *terminalCommandGuard // 终端命令策略检查
+	live             *terminalhub.Session  // 在线旁观（未注册时为 nil）
+	mu               sync.Mutex            // 保护回显行缓冲（输出协程与输入处理并发访问）
+	currentLine      strings.Builder       // 当前行的输出内容
+	lastCompleteLine string                // 上一个完整行
+	pendingEnter     bool                  // 是否有待处理的回车键
 }
 
 // WebSocket升级器
@@ -105,12 +128,20 @@ func (h *SSHHandler) SSHConnect(c *gin.Context) {
 		if sshClient != nil {
 			_ = sshClient.Close()
 		}
+		killed := false
+		if sessionInfo != nil {
+			killed = sessionInfo.live.Close()
+		}
 		if sessionInfo != nil && sessionInfo.replay != nil {
 			sessionInfo.replay.End()
 		}
 		// 关闭审计会话
 		if sessionInfo != nil && sessionInfo.auditSessionID > 0 && h.auditService != nil {
-			_ = h.auditService.CloseSession(sessionInfo.auditSessionID, "closed")
+			status := "closed"
+			if killed {
+				status = "killed"
+			}
+			_ = h.auditService.CloseSession(sessionInfo.auditSessionID, status)
 		}
 	}()
 
@@ -128,6 +159,17 @@ func (h *SSHHandler) SSHConnect(c *gin.Context) {
 				h.sendError(conn, "缺少SSH配置")
 				continue
 			}
+			if h.podNodeTerminalOnly(msg.Config.ClusterID) {
+				h.sendError(conn, "该集群的节点终端已设置为 Pod 方式，不允许使用 SSH 连接")
+				continue
+			}
+
+			// 解析目标节点与会话凭据（未提供密码/私钥时由服务端凭据库提供，凭据不经过浏览器）
+			nodeName, cred, err := h.resolveConnection(msg.Config, userID)
+			if err != nil {
+				h.sendError(conn, fmt.Sprintf("SSH连接失败: %v", err))
+				continue
+			}
 
 			// 创建审计会话
 			sessionInfo = &SSHSession{}
@@ -142,7 +184,7 @@ func (h *SSHHandler) SSHConnect(c *gin.Context) {
 					logger.Error("创建审计会话失败", "error", err)
 				} else {
 					sessionInfo.auditSessionID = auditSession.ID
-					if rec, err := terminalreplay.NewRecorder(h.replayDir, h.auditService, auditSession.ID, 80, 24); err != nil {
+					if rec, err := terminalreplay.NewRecorder(h.replayStorage, h.auditService, auditSession.ID, 80, 24); err != nil {
 						logger.Error("SSH 会话录像创建失败", "error", err)
 					} else {
 						sessionInfo.replay = rec
@@ -150,9 +192,18 @@ func (h *SSHHandler) SSHConnect(c *gin.Context) {
 				}
 			}
 
+			// 节点终端仅平台管理员可用，按 admin 权限类型匹配规则
+			sessionInfo.guard = newTerminalCommandGuard(h.commandPolicy, h.auditService, sessionInfo.auditSessionID,
+				services.TerminalTypeNode, msg.Config.ClusterID, "", models.PermissionTypeAdmin)
+
 			// 创建SSH连接
-			sshClient, sshSession, stdin, stdout, stderr, err = h.createSSHConnection(msg.Config)
+			sshClient, sshSession, stdin, stdout, stderr, err = h.createSSHConnection(msg.Config, cred,
+				sshvault.HostKeyCallback(h.vault, msg.Config.ClusterID, nodeName))
 			if err != nil {
+				var mismatch *sshvault.MismatchError
+				if errors.As(err, &mismatch) {
+					logger.Error("SSH 主机密钥校验失败", "user", userID, "host", mismatch.Host, "expected", mismatch.Expected, "presented", mismatch.Presented)
+				}
 				h.sendError(conn, fmt.Sprintf("SSH连接失败: %v", err))
 				if sessionInfo != nil && sessionInfo.replay != nil {
 					sessionInfo.replay.End()
@@ -163,6 +214,23 @@ func (h *SSHHandler) SSHConnect(c *gin.Context) {
 				continue
 			}
 
+			// 注册在线会话，供管理员旁观、协同操作与强制终止
+			input, session := stdin, sessionInfo
+			sessionInfo.live = h.liveHub.Register(terminalhub.SessionInfo{
+				SessionID:  sessionInfo.auditSessionID,
+				UserID:     userID,
+				ClusterID:  msg.Config.ClusterID,
+				TargetType: string(services.TerminalTypeNode),
+				Target:     fmt.Sprintf("%s@%s:%d", msg.Config.Username, msg.Config.Host, msg.Config.Port),
+			}, terminalhub.Owner{
+				Input:  func(data string) { h.handleInput(conn, session, input, data) },
+				Notify: func(msgType, data string) { _ = conn.WriteJSON(SSHMessage{Type: msgType, Data: data}) },
+				Kill: func(reason string) {
+					h.sendError(conn, "会话已被管理员强制终止: "+reason)
+					_ = conn.Close()
+				},
+			})
+
 			// 发送连接成功消息
 			_ = conn.WriteJSON(SSHMessage{
 				Type: "connected",
@@ -174,21 +242,7 @@ func (h *SSHHandler) SSHConnect(c *gin.Context) {
 		case "input":
 			if stdin != nil && msg.Data != nil {
 				if input, ok := msg.Data.(string); ok {
-					_, err := stdin.Write([]byte(input))
-					if err != nil {
-						logger.Error("写入SSH输入失败", "error", err)
-						h.sendError(conn, "写入输入失败")
-					}
-
-					// 检测回车键，标记待处理
-					if sessionInfo != nil && h.auditService != nil && sessionInfo.auditSessionID > 0 {
-						if strings.Contains(input, "\r") || strings.Contains(input, "\n") {
-							sessionInfo.pendingEnter = true
-						} else if input == "\x03" {
-							// Ctrl+C 清空当前行
-							sessionInfo.currentLine.Reset()
-						}
-					}
+					h.handleInput(conn, sessionInfo, stdin, input)
 				}
 			}
 
@@ -201,6 +255,21 @@ func (h *SSHHandler) SSHConnect(c *gin.Context) {
 				if sessionInfo != nil && sessionInfo.replay != nil {
 					sessionInfo.replay.Resize(msg.Cols, msg.Rows)
 				}
+				if sessionInfo != nil {
+					sessionInfo.live.Resize(msg.Cols, msg.Rows)
+				}
+			}
+
+		case "share_response":
+			// 所有者答复协同操作申请：approve / deny
+			if sessionInfo != nil {
+				approve, _ := msg.Data.(string)
+				sessionInfo.live.RespondDrive(approve == "approve")
+			}
+
+		case "share_revoke":
+			if sessionInfo != nil {
+				sessionInfo.live.RevokeDrive()
 			}
 		}
 	}
@@ -208,8 +277,59 @@ func (h *SSHHandler) SSHConnect(c *gin.Context) {
 	logger.Info("SSH WebSocket连接关闭")
 }
 
+// handleInput 处理终端输入（所有者与协同操作者共用）
+func (h *SSHHandler) handleInput(conn *websocket.Conn, session *SSHSession, stdin io.Writer, input string) {
+	// 命令执行前策略检查：拒绝的命令不会把回车写入终端
+	if session.guard != nil {
+		input = h.filterInput(conn, session, input)
+		if input == "" {
+			return
+		}
+	}
+
+	// 录像记录实际写入终端的输入（密码提示后的输入会被掩码）
+	session.replay.RecordInput([]byte(input))
+
+	_, err := stdin.Write([]byte(input))
+	if err != nil {
+		logger.Error("写入SSH输入失败", "error", err)
+		h.sendError(conn, "写入输入失败")
+	}
+
+	// 检测回车键，标记待处理
+	if h.auditService != nil && session.auditSessionID > 0 {
+		session.mu.Lock()
+		if strings.Contains(input, "\r") || strings.Contains(input, "\n") {
+			session.pendingEnter = true
+		} else if input == "\x03" {
+			// Ctrl+C 清空当前行
+			session.currentLine.Reset()
+		}
+		session.mu.Unlock()
+	}
+}
+
+// filterInput 按终端命令策略过滤输入，返回需要写入 SSH 的数据
+func (h *SSHHandler) filterInput(conn *websocket.Conn, session *SSHSession, input string) string {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	result := session.guard.Filter(input, h.extractCommandFromLine(session.currentLine.String()))
+	if result.notice != "" {
+		_ = conn.WriteJSON(SSHMessage{Type: "data", Data: result.notice})
+	}
+	if result.blocked {
+		session.currentLine.Reset()
+		session.pendingEnter = false
+	}
+	return result.forward
+}
+
 // trackOutputForCommand 追踪输出以提取命令
 func (h *SSHHandler) trackOutputForCommand(session *SSHSession, output string) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
 	for _, c := range output {
 		switch c {
 		case '\n':
@@ -308,44 +428,16 @@ func (h *SSHHandler) stripANSI(s string) string {
 	return result.String()
 }
 
-// createSSHConnection 创建SSH连接
-func (h *SSHHandler) createSSHConnection(config *SSHConfig) (*ssh.Client, *ssh.Session, io.WriteCloser, io.Reader, io.Reader, error) {
+// createSSHConnection 创建SSH连接，主机密钥按 hostKeyCallback 校验
+func (h *SSHHandler) createSSHConnection(config *SSHConfig, cred *services.SSHCredential, hostKeyCallback ssh.HostKeyCallback) (*ssh.Client, *ssh.Session, io.WriteCloser, io.Reader, io.Reader, error) {
 	// 创建SSH客户端配置
 	sshConfig := &ssh.ClientConfig{
-		User:            config.Username,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // #nosec G106 -- 平台管理场景，目标节点已在集群管理范围内
+		User:            cred.Username,
+		Auth:            cred.Auth,
+		HostKeyCallback: hostKeyCallback,
 		Timeout:         30 * time.Second,
 	}
 
-	// 根据认证类型设置认证方法
-	switch config.AuthType {
-	case "password":
-		if config.Password == "" {
-			return nil, nil, nil, nil, nil, fmt.Errorf("密码不能为空")
-		}
-		sshConfig.Auth = []ssh.AuthMethod{
-			ssh.Password(config.Password),
-		}
-
-	case "key":
-		if config.PrivateKey == "" {
-			return nil, nil, nil, nil, nil, fmt.Errorf("私钥不能为空")
-		}
-
-		// 解析私钥
-		signer, err := ssh.ParsePrivateKey([]byte(config.PrivateKey))
-		if err != nil {
-			return nil, nil, nil, nil, nil, fmt.Errorf("解析私钥失败: %v", err)
-		}
-
-		sshConfig.Auth = []ssh.AuthMethod{
-			ssh.PublicKeys(signer),
-		}
-
-	default:
-		return nil, nil, nil, nil, nil, fmt.Errorf("不支持的认证类型: %s", config.AuthType)
-	}
-
 	// 连接SSH服务器
 	address := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
 	client, err := ssh.Dial("tcp", address, sshConfig)
@@ -435,6 +527,9 @@ func (h *SSHHandler) readSSHOutput(conn *websocket.Conn, stdout, stderr io.Reade
 				if session != nil && session.replay != nil {
 					session.replay.Record(buffer[:n])
 				}
+				if session != nil {
+					session.live.Broadcast(buffer[:n])
+				}
 
 				// 追踪输出以提取命令
 				if session != nil && h.auditService != nil && session.auditSessionID > 0 {
@@ -468,11 +563,109 @@ func (h *SSHHandler) readSSHOutput(conn *websocket.Conn, stdout, stderr io.Reade
 				if session != nil && session.replay != nil {
 					session.replay.Record(buffer[:n])
 				}
+				if session != nil {
+					session.live.Broadcast(buffer[:n])
+				}
 			}
 		}
 	}()
 }
 
+// resolveConnection 解析目标节点并准备会话凭据。
+// 浏览器提供了密码或私钥时按手工连接处理；否则由凭据库按集群与节点标签匹配凭据，证书方式为本次会话签发短期证书。
+func (h *SSHHandler) resolveConnection(config *SSHConfig, userID uint) (string, *services.SSHCredential, error) {
+	nodeName := ""
+	var nodeLabels map[string]string
+	if node := h.lookupNode(config); node != nil {
+		nodeName = node.Name
+		nodeLabels = node.Labels
+		if config.Host == "" {
+			config.Host = getNodeInternalIP(*node)
+		}
+	}
+	if config.Host == "" {
+		return "", nil, errors.New("无法确定节点地址")
+	}
+
+	var cred *services.SSHCredential
+	if config.Password != "" || config.PrivateKey != "" {
+		auth, err := services.SSHAuthMethods(config.AuthType, config.Password, config.PrivateKey)
+		if err != nil {
+			return "", nil, err
+		}
+		cred = &services.SSHCredential{Username: config.Username, Port: config.Port, Auth: auth, Source: "manual"}
+	} else {
+		keyID := fmt.Sprintf("kubepolaris:user-%d:%s", userID, config.Host)
+		resolved, err := h.vault.ResolveCredential(config.ClusterID, nodeLabels, keyID)
+		if err != nil {
+			return "", nil, err
+		}
+		cred = resolved
+	}
+	if cred.Username == "" {
+		cred.Username = "root"
+	}
+	if cred.Port == 0 {
+		cred.Port = 22
+	}
+	config.Username, config.Port = cred.Username, cred.Port
+	logger.Info("SSH 会话凭据", "user", userID, "host", config.Host, "node", nodeName, "source", cred.Source)
+	return nodeName, cred, nil
+}
+
+// lookupNode 按节点名称或地址查找集群节点（用于匹配凭据配置），找不到时返回 nil
+func (h *SSHHandler) lookupNode(config *SSHConfig) *corev1.Node {
+	if config.ClusterID == 0 || h.clusterService == nil || h.k8sMgr == nil {
+		return nil
+	}
+	cluster, err := h.clusterService.GetCluster(config.ClusterID)
+	if err != nil {
+		return nil
+	}
+	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
+	if err != nil {
+		logger.Warn("获取K8s客户端失败，按未知节点匹配 SSH 凭据", "cluster", cluster.Name, "error", err)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client := k8sClient.GetClientset()
+	if config.Node != "" {
+		node, err := client.CoreV1().Nodes().Get(ctx, config.Node, metav1.GetOptions{})
+		if err != nil {
+			logger.Warn("获取节点失败", "node", config.Node, "error", err)
+			return nil
+		}
+		return node
+	}
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logger.Warn("获取节点列表失败", "cluster", cluster.Name, "error", err)
+		return nil
+	}
+	for i := range nodes.Items {
+		for _, addr := range nodes.Items[i].Status.Addresses {
+			if addr.Address == config.Host {
+				return &nodes.Items[i]
+			}
+		}
+	}
+	return nil
+}
+
+// podNodeTerminalOnly 集群是否设置为 Pod 方式节点终端（此时禁止 SSH 连接）
+func (h *SSHHandler) podNodeTerminalOnly(clusterID uint) bool {
+	if clusterID == 0 || h.clusterService == nil {
+		return false
+	}
+	cluster, err := h.clusterService.GetCluster(clusterID)
+	if err != nil {
+		return false
+	}
+	return cluster.UsesPodNodeTerminal()
+}
+
 // sendError 发送错误消息
 func (h *SSHHandler) sendError(conn *websocket.Conn, errorMsg string) {
 	_ = conn.WriteJSON(SSHMessage{
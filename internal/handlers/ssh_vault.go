@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+// SSHVaultHandler 节点 SSH 凭据库处理器（凭据配置与主机密钥管理）
+type SSHVaultHandler struct {
+	vault *services.SSHVaultService
+}
+
+// NewSSHVaultHandler 创建节点 SSH 凭据库处理器
+func NewSSHVaultHandler(vault *services.SSHVaultService) *SSHVaultHandler {
+	return &SSHVaultHandler{vault: vault}
+}
+
+// ListProfiles 获取 SSH 凭据配置列表
+func (h *SSHVaultHandler) ListProfiles(c *gin.Context) {
+	profiles, err := h.vault.ListProfiles()
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, profiles)
+}
+
+// CreateProfile 创建 SSH 凭据配置
+func (h *SSHVaultHandler) CreateProfile(c *gin.Context) {
+	var req services.SSHProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	profile, err := h.vault.CreateProfile(&req, c.GetUint("user_id"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Created(c, profile)
+}
+
+// UpdateProfile 更新 SSH 凭据配置（密码与私钥留空表示保持不变）
+func (h *SSHVaultHandler) UpdateProfile(c *gin.Context) {
+	id, ok := parseSSHVaultID(c)
+	if !ok {
+		return
+	}
+
+	var req services.SSHProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	profile, err := h.vault.UpdateProfile(id, &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, profile)
+}
+
+// DeleteProfile 删除 SSH 凭据配置
+func (h *SSHVaultHandler) DeleteProfile(c *gin.Context) {
+	id, ok := parseSSHVaultID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.vault.DeleteProfile(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// ListHostKeys 获取节点主机密钥列表
+// 查询参数: cluster_id, mismatch=true 只看存在不一致告警的主机
+func (h *SSHVaultHandler) ListHostKeys(c *gin.Context) {
+	var clusterID uint
+	if id, err := strconv.ParseUint(c.Query("cluster_id"), 10, 32); err == nil {
+		clusterID = uint(id)
+	}
+
+	keys, err := h.vault.ListHostKeys(clusterID, c.Query("mismatch") == "true")
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, keys)
+}
+
+// AcceptHostKey 确认主机的新密钥（节点重装等合法变更）
+func (h *SSHVaultHandler) AcceptHostKey(c *gin.Context) {
+	id, ok := parseSSHVaultID(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.vault.AcceptHostKey(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, key)
+}
+
+// DeleteHostKey 删除主机密钥记录
+func (h *SSHVaultHandler) DeleteHostKey(c *gin.Context) {
+	id, ok := parseSSHVaultID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.vault.DeleteHostKey(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// parseSSHVaultID 解析路径中的ID
+func parseSSHVaultID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidID)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -2,13 +2,13 @@ package handlers
 
 import (
 	"context"
-	"fmt"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
@@ -64,7 +64,7 @@ func (h *StorageHandler) ListPVCs(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -79,7 +79,7 @@ func (h *StorageHandler) ListPVCs(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -87,7 +87,7 @@ func (h *StorageHandler) ListPVCs(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -96,7 +96,7 @@ func (h *StorageHandler) ListPVCs(c *gin.Context) {
 	// 检查命名空间权限
 	nsInfo, hasAccess := middleware.CheckNamespacePermission(c, namespace)
 	if !hasAccess {
-		response.Forbidden(c, fmt.Sprintf("无权访问命名空间: %s", namespace))
+		response.FailCode(c, errcode.NamespaceAccessDenied)
 		return
 	}
 
@@ -104,7 +104,7 @@ func (h *StorageHandler) ListPVCs(c *gin.Context) {
 	pvcs, err := h.getPVCs(clientset, namespace)
 	if err != nil {
 		logger.Error("获取PVCs失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取PVCs失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "PVC"))
 		return
 	}
 
@@ -143,7 +143,7 @@ func (h *StorageHandler) GetPVC(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -154,7 +154,7 @@ func (h *StorageHandler) GetPVC(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -162,7 +162,7 @@ func (h *StorageHandler) GetPVC(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -172,7 +172,7 @@ func (h *StorageHandler) GetPVC(c *gin.Context) {
 	pvc, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		logger.Error("获取PVC失败", "error", err, "clusterId", clusterID, "namespace", namespace, "name", name)
-		response.InternalError(c, fmt.Sprintf("获取PVC失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceGetFailed, "PVC"))
 		return
 	}
 
@@ -186,7 +186,7 @@ func (h *StorageHandler) GetPVCYAML(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -197,7 +197,7 @@ func (h *StorageHandler) GetPVCYAML(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -205,7 +205,7 @@ func (h *StorageHandler) GetPVCYAML(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -215,7 +215,7 @@ func (h *StorageHandler) GetPVCYAML(c *gin.Context) {
 	pvc, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		logger.Error("获取PVC失败", "error", err, "clusterId", clusterID, "namespace", namespace, "name", name)
-		response.InternalError(c, fmt.Sprintf("获取PVC失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceGetFailed, "PVC"))
 		return
 	}
 
@@ -223,7 +223,7 @@ func (h *StorageHandler) GetPVCYAML(c *gin.Context) {
 	yamlData, err := yaml.Marshal(pvc)
 	if err != nil {
 		logger.Error("转换YAML失败", "error", err)
-		response.InternalError(c, fmt.Sprintf("转换YAML失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.YAMLMarshalFailed))
 		return
 	}
 
@@ -235,7 +235,7 @@ func (h *StorageHandler) DeletePVC(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -246,7 +246,7 @@ func (h *StorageHandler) DeletePVC(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -254,19 +254,21 @@ func (h *StorageHandler) DeletePVC(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
 	clientset := k8sClient.GetClientset()
 
+	snap := beginSnapshot(c, k8sClient, gvrPVCs, namespace, name)
 	// 删除PVC
 	err = clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
 	if err != nil {
 		logger.Error("删除PVC失败", "error", err, "clusterId", clusterID, "namespace", namespace, "name", name)
-		response.InternalError(c, fmt.Sprintf("删除PVC失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceDeleteFailed, "PVC"))
 		return
 	}
+	snap.Deleted()
 
 	logger.Info("PVC删除成功", "clusterId", clusterID, "namespace", namespace, "name", name)
 	response.NoContent(c)
@@ -279,20 +281,20 @@ func (h *StorageHandler) GetPVCNamespaces(c *gin.Context) {
 	// 获取集群
 	id, err := strconv.ParseUint(clusterID, 10, 32)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
 	cluster, err := h.clusterService.GetCluster(uint(id))
 	if err != nil {
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
 	// 获取缓存的 K8s 客户端
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 	clientset := k8sClient.GetClientset()
@@ -301,7 +303,7 @@ func (h *StorageHandler) GetPVCNamespaces(c *gin.Context) {
 	pvcList, err := clientset.CoreV1().PersistentVolumeClaims("").List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		logger.Error("获取PVC列表失败", "cluster", cluster.Name, "error", err)
-		response.InternalError(c, fmt.Sprintf("获取PVC列表失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "PVC"))
 		return
 	}
 
@@ -449,7 +451,7 @@ func (h *StorageHandler) ListPVs(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -463,7 +465,7 @@ func (h *StorageHandler) ListPVs(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -471,7 +473,7 @@ func (h *StorageHandler) ListPVs(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -481,7 +483,7 @@ func (h *StorageHandler) ListPVs(c *gin.Context) {
 	pvList, err := clientset.CoreV1().PersistentVolumes().List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		logger.Error("获取PVs失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取PVs失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "PV"))
 		return
 	}
 
@@ -518,7 +520,7 @@ func (h *StorageHandler) GetPV(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -528,7 +530,7 @@ func (h *StorageHandler) GetPV(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -536,7 +538,7 @@ func (h *StorageHandler) GetPV(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -546,7 +548,7 @@ func (h *StorageHandler) GetPV(c *gin.Context) {
 	pv, err := clientset.CoreV1().PersistentVolumes().Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		logger.Error("获取PV失败", "error", err, "clusterId", clusterID, "name", name)
-		response.InternalError(c, fmt.Sprintf("获取PV失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceGetFailed, "PV"))
 		return
 	}
 
@@ -560,7 +562,7 @@ func (h *StorageHandler) GetPVYAML(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -570,7 +572,7 @@ func (h *StorageHandler) GetPVYAML(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -578,7 +580,7 @@ func (h *StorageHandler) GetPVYAML(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -588,7 +590,7 @@ func (h *StorageHandler) GetPVYAML(c *gin.Context) {
 	pv, err := clientset.CoreV1().PersistentVolumes().Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		logger.Error("获取PV失败", "error", err, "clusterId", clusterID, "name", name)
-		response.InternalError(c, fmt.Sprintf("获取PV失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceGetFailed, "PV"))
 		return
 	}
 
@@ -596,7 +598,7 @@ func (h *StorageHandler) GetPVYAML(c *gin.Context) {
 	yamlData, err := yaml.Marshal(pv)
 	if err != nil {
 		logger.Error("转换YAML失败", "error", err)
-		response.InternalError(c, fmt.Sprintf("转换YAML失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.YAMLMarshalFailed))
 		return
 	}
 
@@ -608,7 +610,7 @@ func (h *StorageHandler) DeletePV(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -618,7 +620,7 @@ func (h *StorageHandler) DeletePV(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -626,19 +628,21 @@ func (h *StorageHandler) DeletePV(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
 	clientset := k8sClient.GetClientset()
 
+	snap := beginSnapshot(c, k8sClient, gvrPVs, "", name)
 	// 删除PV
 	err = clientset.CoreV1().PersistentVolumes().Delete(context.Background(), name, metav1.DeleteOptions{})
 	if err != nil {
 		logger.Error("删除PV失败", "error", err, "clusterId", clusterID, "name", name)
-		response.InternalError(c, fmt.Sprintf("删除PV失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceDeleteFailed, "PV"))
 		return
 	}
+	snap.Deleted()
 
 	logger.Info("PV删除成功", "clusterId", clusterID, "name", name)
 	response.NoContent(c)
@@ -790,7 +794,7 @@ func (h *StorageHandler) ListStorageClasses(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -803,7 +807,7 @@ func (h *StorageHandler) ListStorageClasses(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -811,7 +815,7 @@ func (h *StorageHandler) ListStorageClasses(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -821,7 +825,7 @@ func (h *StorageHandler) ListStorageClasses(c *gin.Context) {
 	scList, err := clientset.StorageV1().StorageClasses().List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		logger.Error("获取StorageClasses失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取StorageClasses失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceListFailed, "StorageClass"))
 		return
 	}
 
@@ -858,7 +862,7 @@ func (h *StorageHandler) GetStorageClass(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -868,7 +872,7 @@ func (h *StorageHandler) GetStorageClass(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -876,7 +880,7 @@ func (h *StorageHandler) GetStorageClass(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -886,7 +890,7 @@ func (h *StorageHandler) GetStorageClass(c *gin.Context) {
 	sc, err := clientset.StorageV1().StorageClasses().Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		logger.Error("获取StorageClass失败", "error", err, "clusterId", clusterID, "name", name)
-		response.InternalError(c, fmt.Sprintf("获取StorageClass失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceGetFailed, "StorageClass"))
 		return
 	}
 
@@ -900,7 +904,7 @@ func (h *StorageHandler) GetStorageClassYAML(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -910,7 +914,7 @@ func (h *StorageHandler) GetStorageClassYAML(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -918,7 +922,7 @@ func (h *StorageHandler) GetStorageClassYAML(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
@@ -928,7 +932,7 @@ func (h *StorageHandler) GetStorageClassYAML(c *gin.Context) {
 	sc, err := clientset.StorageV1().StorageClasses().Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		logger.Error("获取StorageClass失败", "error", err, "clusterId", clusterID, "name", name)
-		response.InternalError(c, fmt.Sprintf("获取StorageClass失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceGetFailed, "StorageClass"))
 		return
 	}
 
@@ -936,7 +940,7 @@ func (h *StorageHandler) GetStorageClassYAML(c *gin.Context) {
 	yamlData, err := yaml.Marshal(sc)
 	if err != nil {
 		logger.Error("转换YAML失败", "error", err)
-		response.InternalError(c, fmt.Sprintf("转换YAML失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.YAMLMarshalFailed))
 		return
 	}
 
@@ -948,7 +952,7 @@ func (h *StorageHandler) DeleteStorageClass(c *gin.Context) {
 	clusterIDStr := c.Param("clusterID")
 	clusterID, err := parseClusterID(clusterIDStr)
 	if err != nil {
-		response.BadRequest(c, "无效的集群ID")
+		response.FailCode(c, errcode.InvalidClusterID)
 		return
 	}
 
@@ -958,7 +962,7 @@ func (h *StorageHandler) DeleteStorageClass(c *gin.Context) {
 	cluster, err := h.clusterService.GetCluster(clusterID)
 	if err != nil {
 		logger.Error("获取集群失败", "error", err, "clusterId", clusterID)
-		response.NotFound(c, "集群不存在")
+		response.FailCode(c, errcode.ClusterNotFound)
 		return
 	}
 
@@ -966,19 +970,21 @@ func (h *StorageHandler) DeleteStorageClass(c *gin.Context) {
 	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
 	if err != nil {
 		logger.Error("获取K8s客户端失败", "error", err, "clusterId", clusterID)
-		response.InternalError(c, fmt.Sprintf("获取K8s客户端失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.K8sClientFailed))
 		return
 	}
 
 	clientset := k8sClient.GetClientset()
 
+	snap := beginSnapshot(c, k8sClient, gvrStorageClass, "", name)
 	// 删除StorageClass
 	err = clientset.StorageV1().StorageClasses().Delete(context.Background(), name, metav1.DeleteOptions{})
 	if err != nil {
 		logger.Error("删除StorageClass失败", "error", err, "clusterId", clusterID, "name", name)
-		response.InternalError(c, fmt.Sprintf("删除StorageClass失败: %v", err))
+		response.Fail(c, errcode.Wrap(err, errcode.ResourceDeleteFailed, "StorageClass"))
 		return
 	}
+	snap.Deleted()
 
 	logger.Info("StorageClass删除成功", "clusterId", clusterID, "name", name)
 	response.NoContent(c)
@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
@@ -37,7 +38,7 @@ func (h *SystemSettingHandler) GetLDAPConfig(c *gin.Context) {
 	config, err := h.ldapService.GetLDAPConfig()
 	if err != nil {
 		logger.Error("获取LDAP配置失败: %v", err)
-		response.InternalError(c, "获取LDAP配置失败")
+		response.FailCode(c, errcode.LDAPConfigFailed)
 		return
 	}
 
@@ -72,7 +73,7 @@ type UpdateLDAPConfigRequest struct {
 func (h *SystemSettingHandler) UpdateLDAPConfig(c *gin.Context) {
 	var req UpdateLDAPConfigRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
@@ -80,7 +81,7 @@ func (h *SystemSettingHandler) UpdateLDAPConfig(c *gin.Context) {
 	existingConfig, err := h.ldapService.GetLDAPConfig()
 	if err != nil {
 		logger.Error("获取现有LDAP配置失败: %v", err)
-		response.InternalError(c, "更新LDAP配置失败")
+		response.FailCode(c, errcode.LDAPConfigUpdateFailed)
 		return
 	}
 
@@ -111,7 +112,7 @@ func (h *SystemSettingHandler) UpdateLDAPConfig(c *gin.Context) {
 	// 保存配置
 	if err := h.ldapService.SaveLDAPConfig(config); err != nil {
 		logger.Error("保存LDAP配置失败: %v", err)
-		response.InternalError(c, "保存LDAP配置失败")
+		response.FailCode(c, errcode.LDAPConfigSaveFailed)
 		return
 	}
 
@@ -124,7 +125,7 @@ func (h *SystemSettingHandler) UpdateLDAPConfig(c *gin.Context) {
 func (h *SystemSettingHandler) TestLDAPConnection(c *gin.Context) {
 	var req UpdateLDAPConfigRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
@@ -195,7 +196,7 @@ type TestLDAPAuthRequest struct {
 func (h *SystemSettingHandler) TestLDAPAuth(c *gin.Context) {
 	var req TestLDAPAuthRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
@@ -255,7 +256,7 @@ func (h *SystemSettingHandler) GetSSHConfig(c *gin.Context) {
 	config, err := h.sshSettingService.GetSSHConfig()
 	if err != nil {
 		logger.Error("获取SSH配置失败: %v", err)
-		response.InternalError(c, "获取SSH配置失败")
+		response.FailCode(c, errcode.SSHConfigFailed)
 		return
 	}
 
@@ -285,7 +286,7 @@ type UpdateSSHConfigRequest struct {
 func (h *SystemSettingHandler) UpdateSSHConfig(c *gin.Context) {
 	var req UpdateSSHConfigRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
@@ -293,7 +294,7 @@ func (h *SystemSettingHandler) UpdateSSHConfig(c *gin.Context) {
 	existingConfig, err := h.sshSettingService.GetSSHConfig()
 	if err != nil {
 		logger.Error("获取现有SSH配置失败: %v", err)
-		response.InternalError(c, "更新SSH配置失败")
+		response.FailCode(c, errcode.SSHConfigUpdateFailed)
 		return
 	}
 
@@ -333,7 +334,7 @@ func (h *SystemSettingHandler) UpdateSSHConfig(c *gin.Context) {
 	// 保存配置
 	if err := h.sshSettingService.SaveSSHConfig(config); err != nil {
 		logger.Error("保存SSH配置失败: %v", err)
-		response.InternalError(c, "保存SSH配置失败")
+		response.FailCode(c, errcode.SSHConfigSaveFailed)
 		return
 	}
 
@@ -342,12 +343,13 @@ func (h *SystemSettingHandler) UpdateSSHConfig(c *gin.Context) {
 	response.OK(c, gin.H{"message": "SSH配置更新成功"})
 }
 
-// GetSSHCredentials 获取SSH凭据（用于自动连接，返回完整凭据）
+// GetSSHCredentials 获取SSH自动连接信息
+// 密码与私钥不再下发给浏览器：前端不携带凭据发起连接时，由服务端凭据库（凭据配置或全局配置）提供
 func (h *SystemSettingHandler) GetSSHCredentials(c *gin.Context) {
 	config, err := h.sshSettingService.GetSSHConfig()
 	if err != nil {
 		logger.Error("获取SSH凭据失败: %v", err)
-		response.InternalError(c, "获取SSH凭据失败")
+		response.FailCode(c, errcode.SSHCredentialFailed)
 		return
 	}
 
@@ -359,8 +361,13 @@ func (h *SystemSettingHandler) GetSSHCredentials(c *gin.Context) {
 		return
 	}
 
-	// 返回完整凭据（用于自动连接）
-	response.OK(c, config)
+	response.OK(c, gin.H{
+		"enabled":     true,
+		"username":    config.Username,
+		"port":        config.Port,
+		"auth_type":   config.AuthType,
+		"server_side": true,
+	})
 }
 
 // ==================== Grafana 配置相关接口 ====================
@@ -370,7 +377,7 @@ func (h *SystemSettingHandler) GetGrafanaConfig(c *gin.Context) {
 	config, err := h.grafanaSettingService.GetGrafanaConfig()
 	if err != nil {
 		logger.Error("获取 Grafana 配置失败: %v", err)
-		response.InternalError(c, "获取 Grafana 配置失败")
+		response.FailCode(c, errcode.GrafanaConfigFailed)
 		return
 	}
 
@@ -392,14 +399,14 @@ type UpdateGrafanaConfigRequest struct {
 func (h *SystemSettingHandler) UpdateGrafanaConfig(c *gin.Context) {
 	var req UpdateGrafanaConfigRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
 	existingConfig, err := h.grafanaSettingService.GetGrafanaConfig()
 	if err != nil {
 		logger.Error("获取现有 Grafana 配置失败: %v", err)
-		response.InternalError(c, "更新 Grafana 配置失败")
+		response.FailCode(c, errcode.GrafanaConfigUpdateFailed)
 		return
 	}
 
@@ -415,7 +422,7 @@ func (h *SystemSettingHandler) UpdateGrafanaConfig(c *gin.Context) {
 
 	if err := h.grafanaSettingService.SaveGrafanaConfig(config); err != nil {
 		logger.Error("保存 Grafana 配置失败: %v", err)
-		response.InternalError(c, "保存 Grafana 配置失败")
+		response.FailCode(c, errcode.GrafanaConfigSaveFailed)
 		return
 	}
 
@@ -434,7 +441,7 @@ func (h *SystemSettingHandler) UpdateGrafanaConfig(c *gin.Context) {
 func (h *SystemSettingHandler) TestGrafanaConnection(c *gin.Context) {
 	var req UpdateGrafanaConfigRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
@@ -477,7 +484,7 @@ func (h *SystemSettingHandler) GetGrafanaDashboardStatus(c *gin.Context) {
 	status, err := h.grafanaService.GetDashboardSyncStatus()
 	if err != nil {
 		logger.Error("获取 Dashboard 同步状态失败: %v", err)
-		response.InternalError(c, "获取 Dashboard 同步状态失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.GrafanaDashboardStatusFailed))
 		return
 	}
 
@@ -497,7 +504,7 @@ func (h *SystemSettingHandler) GetGrafanaDataSourceStatus(c *gin.Context) {
 	clusters := h.getMonitoringClusters()
 	status, err := h.grafanaService.GetDataSourceSyncStatus(clusters)
 	if err != nil {
-		response.InternalError(c, "获取数据源同步状态失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.GrafanaDatasourceStatusFailed))
 		return
 	}
 
@@ -507,7 +514,7 @@ func (h *SystemSettingHandler) GetGrafanaDataSourceStatus(c *gin.Context) {
 // SyncGrafanaDataSources 同步所有数据源到 Grafana
 func (h *SystemSettingHandler) SyncGrafanaDataSources(c *gin.Context) {
 	if h.grafanaService == nil || !h.grafanaService.IsEnabled() {
-		response.BadRequest(c, "请先配置 Grafana 连接信息")
+		response.FailCode(c, errcode.GrafanaNotConfigured)
 		return
 	}
 
@@ -522,7 +529,7 @@ func (h *SystemSettingHandler) SyncGrafanaDataSources(c *gin.Context) {
 
 	status, err := h.grafanaService.SyncAllDataSources(clusters)
 	if err != nil {
-		response.InternalError(c, "同步数据源失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.GrafanaDatasourceSyncFailed))
 		return
 	}
 
@@ -557,14 +564,14 @@ func (h *SystemSettingHandler) getMonitoringClusters() []services.DataSourceClus
 // SyncGrafanaDashboards 同步 Dashboard 到 Grafana
 func (h *SystemSettingHandler) SyncGrafanaDashboards(c *gin.Context) {
 	if h.grafanaService == nil || !h.grafanaService.IsEnabled() {
-		response.BadRequest(c, "请先配置 Grafana 连接信息")
+		response.FailCode(c, errcode.GrafanaNotConfigured)
 		return
 	}
 
 	status, err := h.grafanaService.EnsureDashboards()
 	if err != nil {
 		logger.Error("同步 Dashboard 失败: %v", err)
-		response.InternalError(c, "同步 Dashboard 失败: "+err.Error())
+		response.Fail(c, errcode.Wrap(err, errcode.GrafanaDashboardSyncFailed))
 		return
 	}
 
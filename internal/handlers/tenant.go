@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+// TenantHandler 租户处理器
+type TenantHandler struct {
+	db            *gorm.DB
+	tenantService *services.TenantService
+	opLogSvc      *services.OperationLogService
+}
+
+// NewTenantHandler 创建租户处理器
+func NewTenantHandler(db *gorm.DB, tenantService *services.TenantService, opLogSvc *services.OperationLogService) *TenantHandler {
+	return &TenantHandler{
+		db:            db,
+		tenantService: tenantService,
+		opLogSvc:      opLogSvc,
+	}
+}
+
+// ListTenants 获取租户列表
+func (h *TenantHandler) ListTenants(c *gin.Context) {
+	tenants, err := h.tenantService.ListTenants()
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, tenants)
+}
+
+// GetTenant 获取租户详情
+func (h *TenantHandler) GetTenant(c *gin.Context) {
+	id, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+
+	tenant, err := h.tenantService.GetTenant(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, tenant)
+}
+
+// GetMyTenant 获取当前用户所属租户及可见范围
+func (h *TenantHandler) GetMyTenant(c *gin.Context) {
+	scope, err := services.LoadTenantScope(h.db, c.GetUint("user_id"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if scope == nil {
+		response.OK(c, nil)
+		return
+	}
+
+	namespaces := make(map[uint][]string)
+	for _, clusterID := range scope.ClusterIDs() {
+		namespaces[clusterID] = scope.Namespaces(clusterID)
+	}
+	response.OK(c, gin.H{
+		"tenant_id":   scope.TenantID,
+		"tenant_name": scope.TenantName,
+		"role":        scope.Role,
+		"disabled":    scope.Disabled,
+		"namespaces":  namespaces,
+	})
+}
+
+// CreateTenant 创建租户
+func (h *TenantHandler) CreateTenant(c *gin.Context) {
+	var req services.TenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	tenant, err := h.tenantService.CreateTenant(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Created(c, tenant)
+}
+
+// UpdateTenant 更新租户
+func (h *TenantHandler) UpdateTenant(c *gin.Context) {
+	id, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+
+	var req services.TenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	tenant, err := h.tenantService.UpdateTenant(id, &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, tenant)
+}
+
+// DeleteTenant 删除租户
+func (h *TenantHandler) DeleteTenant(c *gin.Context) {
+	id, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.tenantService.DeleteTenant(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// AddTenantNamespace 为租户分配命名空间
+func (h *TenantHandler) AddTenantNamespace(c *gin.Context) {
+	id, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		ClusterID uint   `json:"cluster_id" binding:"required"`
+		Namespace string `json:"namespace" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	tenantNs, err := h.tenantService.AddNamespace(id, req.ClusterID, req.Namespace)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Created(c, tenantNs)
+}
+
+// RemoveTenantNamespace 移除租户命名空间
+func (h *TenantHandler) RemoveTenantNamespace(c *gin.Context) {
+	id, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+	nsID, err := strconv.ParseUint(c.Param("nsId"), 10, 64)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidNamespaceID)
+		return
+	}
+
+	if err := h.tenantService.RemoveNamespace(id, uint(nsID)); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// ListTenantMembers 获取租户成员
+func (h *TenantHandler) ListTenantMembers(c *gin.Context) {
+	id, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.tenantService.ListMembers(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, members)
+}
+
+// AddTenantMember 添加租户成员
+func (h *TenantHandler) AddTenantMember(c *gin.Context) {
+	id, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		UserID uint   `json:"user_id" binding:"required"`
+		Role   string `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	member, err := h.tenantService.AddMember(id, req.UserID, req.Role)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, member)
+}
+
+// RemoveTenantMember 移除租户成员
+func (h *TenantHandler) RemoveTenantMember(c *gin.Context) {
+	id, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidUserID)
+		return
+	}
+
+	if err := h.tenantService.RemoveMember(id, uint(userID)); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// ListTenantPermissions 获取租户内授权
+func (h *TenantHandler) ListTenantPermissions(c *gin.Context) {
+	id, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+
+	grants, err := h.tenantService.ListGrants(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	items := make([]interface{}, 0, len(grants))
+	for _, grant := range grants {
+		items = append(items, grant.ToResponse())
+	}
+	response.OK(c, items)
+}
+
+// CreateTenantPermission 租户管理员为成员授权
+func (h *TenantHandler) CreateTenantPermission(c *gin.Context) {
+	id, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+
+	var req services.TenantGrantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	permission, err := h.tenantService.CreateGrant(id, &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Created(c, permission.ToResponse())
+}
+
+// DeleteTenantPermission 删除租户内授权
+func (h *TenantHandler) DeleteTenantPermission(c *gin.Context) {
+	id, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+	permissionID, err := strconv.ParseUint(c.Param("permissionId"), 10, 64)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidPermissionID)
+		return
+	}
+
+	if err := h.tenantService.DeleteGrant(id, uint(permissionID)); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// GetTenantQuotas 获取租户配额汇总
+func (h *TenantHandler) GetTenantQuotas(c *gin.Context) {
+	id, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.tenantService.GetQuotas(c.Request.Context(), id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, summary)
+}
+
+// GetTenantOperationLogs 获取租户成员的操作日志
+func (h *TenantHandler) GetTenantOperationLogs(c *gin.Context) {
+	id, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+
+	req := parseOperationLogListRequest(c)
+	req.TenantID = &id
+	resp, err := h.opLogSvc.List(req)
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.OperationLogsFailed))
+		return
+	}
+	response.OK(c, resp)
+}
+
+// parseTenantID 解析路径中的租户ID
+func parseTenantID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidTenantID)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+)
+
+// terminalCommandGuard 交互式终端的命令执行前检查
+// 在输入侧维护当前行缓冲，回车时结合终端回显重建的命令（包含 Tab 补全、历史命令）
+// 进行策略评估，拒绝的命令不会把回车写入终端。调用方需自行保证并发安全。
+type terminalCommandGuard struct {
+	policyService  *services.TerminalCommandPolicyService
+	auditService   *services.AuditService
+	auditSessionID uint
+	base           services.CommandCheckRequest
+
+	input          []rune // 输入侧当前行
+	pendingConfirm string // 等待再次回车确认的命令
+}
+
+// guardResult 输入过滤结果
+type guardResult struct {
+	forward string // 需要写入终端的数据
+	notice  string // 需要在终端内联显示的提示
+	blocked bool   // 是否拦截了命令（调用方需清理回显行缓冲）
+}
+
+// newTerminalCommandGuard 创建命令检查器，未配置策略服务时返回 nil
+func newTerminalCommandGuard(
+	policyService *services.TerminalCommandPolicyService,
+	auditService *services.AuditService,
+	auditSessionID uint,
+	terminalType services.TerminalType,
+	clusterID uint,
+	namespace, permissionType string,
+) *terminalCommandGuard {
+	if policyService == nil {
+		return nil
+	}
+	return &terminalCommandGuard{
+		policyService:  policyService,
+		auditService:   auditService,
+		auditSessionID: auditSessionID,
+		base: services.CommandCheckRequest{
+			TerminalType:   terminalType,
+			ClusterLabels:  policyService.ClusterLabels(clusterID),
+			Namespace:      namespace,
+			PermissionType: permissionType,
+		},
+	}
+}
+
+// Filter 过滤一段用户输入。echoCmd 为从终端回显中提取的当前命令（可为空），
+// 仅用于本段输入中的第一个回车，之后的行回显尚未到达，只能依赖输入侧缓冲。
+func (g *terminalCommandGuard) Filter(input, echoCmd string) guardResult {
+	var result guardResult
+	var forward strings.Builder
+
+	for i := 0; i < len(input); i++ {
+		ch := input[i]
+		if ch != '\r' && ch != '\n' {
+			// ESC 序列（方向键等）原样转发，不计入输入缓冲
+			if ch == '\x1b' {
+				end := escapeSequenceEnd(input, i)
+				forward.WriteString(input[i:end])
+				i = end - 1
+				continue
+			}
+			start := i
+			g.trackInputByte(input, &i)
+			forward.WriteString(input[start : i+1])
+			continue
+		}
+
+		decision, command := g.check(echoCmd)
+		echoCmd = ""
+		switch {
+		case decision.Denied():
+			forward.WriteString("\x03") // 放弃当前行
+			result.notice = fmt.Sprintf("\r\n\x1b[31m[KubePolaris] 命令已被策略拒绝（规则：%s）%s\x1b[0m\r\n", decision.RuleName, formatRuleMessage(decision.Message))
+			result.blocked = true
+			g.reset()
+			logger.Warn("终端命令被策略拒绝: session=%d, rule=%s, command=%s", g.auditSessionID, decision.RuleName, command)
+			if g.auditService != nil && g.auditSessionID > 0 {
+				g.auditService.RecordBlockedCommandAsync(g.auditSessionID, command, decision.RuleName)
+			}
+			result.forward = forward.String()
+			return result // 拒绝后丢弃本段剩余输入
+		case decision.NeedsConfirm() && g.pendingConfirm != services.NormalizeCommand(command):
+			g.pendingConfirm = services.NormalizeCommand(command)
+			result.notice = fmt.Sprintf("\r\n\x1b[33m[KubePolaris] 命令需要确认（规则：%s）%s，再次按回车执行，按 Ctrl+C 取消\x1b[0m\r\n", decision.RuleName, formatRuleMessage(decision.Message))
+			result.forward = forward.String()
+			return result
+		default:
+			forward.WriteByte(ch)
+			g.reset()
+		}
+	}
+
+	result.forward = forward.String()
+	return result
+}
+
+// CheckLine 检查一条完整命令（非交互式终端使用），返回拒绝或需确认的提示
+// 需确认的命令在再次提交同一命令时放行。
+func (g *terminalCommandGuard) CheckLine(command string) (allowed bool, notice string) {
+	g.input = []rune(command)
+	decision, _ := g.check("")
+	normalized := services.NormalizeCommand(command)
+	g.input = g.input[:0]
+
+	switch {
+	case decision.Denied():
+		g.pendingConfirm = ""
+		logger.Warn("终端命令被策略拒绝: session=%d, rule=%s, command=%s", g.auditSessionID, decision.RuleName, command)
+		if g.auditService != nil && g.auditSessionID > 0 {
+			g.auditService.RecordBlockedCommandAsync(g.auditSessionID, command, decision.RuleName)
+		}
+		return false, fmt.Sprintf("命令已被策略拒绝（规则：%s）%s\n", decision.RuleName, formatRuleMessage(decision.Message))
+	case decision.NeedsConfirm() && g.pendingConfirm != normalized:
+		g.pendingConfirm = normalized
+		return false, fmt.Sprintf("命令需要确认（规则：%s）%s，再次执行同一命令以确认\n", decision.RuleName, formatRuleMessage(decision.Message))
+	default:
+		g.pendingConfirm = ""
+		return true, ""
+	}
+}
+
+// check 评估当前行，回显命令与输入缓冲分别评估后取更严格的结果
+func (g *terminalCommandGuard) check(echoCmd string) (*services.CommandDecision, string) {
+	typed := strings.TrimSpace(string(g.input))
+	command := strings.TrimSpace(echoCmd)
+	if command == "" {
+		command = typed
+	}
+
+	decision := &services.CommandDecision{}
+	for _, candidate := range []string{command, typed} {
+		if candidate == "" {
+			continue
+		}
+		req := g.base
+		req.Command = candidate
+		if d := g.policyService.Check(&req); commandDecisionStricter(d, decision) {
+			decision = d
+		}
+	}
+	return decision, command
+}
+
+// trackInputByte 将一个输入字符计入输入缓冲（处理退格、Ctrl+U、Ctrl+C 及多字节字符）
+func (g *terminalCommandGuard) trackInputByte(input string, i *int) {
+	ch := input[*i]
+	switch {
+	case ch == '\x7f' || ch == '\b':
+		if len(g.input) > 0 {
+			g.input = g.input[:len(g.input)-1]
+		}
+	case ch == '\x15' || ch == '\x03':
+		g.reset()
+	case ch >= utf8.RuneSelf:
+		r, size := utf8.DecodeRuneInString(input[*i:])
+		g.input = append(g.input, r)
+		*i += size - 1
+	case ch >= 32:
+		g.input = append(g.input, rune(ch))
+	}
+}
+
+// reset 清空输入缓冲与待确认命令
+func (g *terminalCommandGuard) reset() {
+	g.input = g.input[:0]
+	g.pendingConfirm = ""
+}
+
+// commandDecisionStricter 判断 a 是否比 b 更严格
+func commandDecisionStricter(a, b *services.CommandDecision) bool {
+	rank := func(d *services.CommandDecision) int {
+		switch {
+		case d.Denied():
+			return 2
+		case d.NeedsConfirm():
+			return 1
+		default:
+			return 0
+		}
+	}
+	return rank(a) > rank(b)
+}
+
+// escapeSequenceEnd 返回从 start 开始的 ESC 序列结束位置（不含）
+func escapeSequenceEnd(input string, start int) int {
+	i := start + 1
+	if i < len(input) && (input[i] == '[' || input[i] == 'O') {
+		i++
+	}
+	for i < len(input) {
+		c := input[i]
+		i++
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '~' {
+			break
+		}
+	}
+	return i
+}
+
+// formatRuleMessage 格式化规则附带的提示
+func formatRuleMessage(message string) string {
+	if message == "" {
+		return ""
+	}
+	return "：" + message
+}
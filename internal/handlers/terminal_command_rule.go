@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+// TerminalCommandRuleHandler 终端命令策略处理器
+type TerminalCommandRuleHandler struct {
+	commandPolicy *services.TerminalCommandPolicyService
+}
+
+// NewTerminalCommandRuleHandler 创建终端命令策略处理器
+func NewTerminalCommandRuleHandler(commandPolicy *services.TerminalCommandPolicyService) *TerminalCommandRuleHandler {
+	return &TerminalCommandRuleHandler{
+		commandPolicy: commandPolicy,
+	}
+}
+
+// ListRules 获取终端命令规则列表
+func (h *TerminalCommandRuleHandler) ListRules(c *gin.Context) {
+	rules, err := h.commandPolicy.ListRules()
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, rules)
+}
+
+// CreateRule 创建终端命令规则
+func (h *TerminalCommandRuleHandler) CreateRule(c *gin.Context) {
+	var req services.TerminalCommandRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	rule, err := h.commandPolicy.CreateRule(&req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.Created(c, rule)
+}
+
+// GetRule 获取终端命令规则详情
+func (h *TerminalCommandRuleHandler) GetRule(c *gin.Context) {
+	id, ok := parseTerminalCommandRuleID(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.commandPolicy.GetRule(id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, rule)
+}
+
+// UpdateRule 更新终端命令规则
+func (h *TerminalCommandRuleHandler) UpdateRule(c *gin.Context) {
+	id, ok := parseTerminalCommandRuleID(c)
+	if !ok {
+		return
+	}
+
+	var req services.TerminalCommandRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailCode(c, errcode.BadRequest)
+		return
+	}
+
+	rule, err := h.commandPolicy.UpdateRule(id, &req)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, rule)
+}
+
+// DeleteRule 删除终端命令规则
+func (h *TerminalCommandRuleHandler) DeleteRule(c *gin.Context) {
+	id, ok := parseTerminalCommandRuleID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.commandPolicy.DeleteRule(id); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// CheckCommand 试算命令在指定上下文下的决策
+// 查询参数: command, terminal_type, cluster_id, namespace, permission_type
+func (h *TerminalCommandRuleHandler) CheckCommand(c *gin.Context) {
+	command := c.Query("command")
+	if command == "" {
+		response.FailCode(c, errcode.CommandRequired)
+		return
+	}
+
+	req := &services.CommandCheckRequest{
+		TerminalType:   services.TerminalType(c.DefaultQuery("terminal_type", string(services.TerminalTypePod))),
+		Namespace:      c.Query("namespace"),
+		PermissionType: c.Query("permission_type"),
+		Command:        command,
+	}
+	if clusterID, err := strconv.ParseUint(c.Query("cluster_id"), 10, 32); err == nil {
+		req.ClusterLabels = h.commandPolicy.ClusterLabels(uint(clusterID))
+	}
+
+	response.OK(c, gin.H{
+		"request":  req,
+		"decision": h.commandPolicy.Check(req),
+		"segments": services.SplitCommandSegments(command),
+	})
+}
+
+// parseTerminalCommandRuleID 解析路径中的规则ID
+func parseTerminalCommandRuleID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidRuleID)
+		return 0, false
+	}
+	return uint(id), true
+}
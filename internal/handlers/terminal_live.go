@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"gorm.io/gorm"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/internal/terminalhub"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+)
+
+// TerminalLiveHandler 在线终端会话处理器（旁观、协同操作、强制终止）
+type TerminalLiveHandler struct {
+	db           *gorm.DB
+	liveHub      *terminalhub.Hub
+	auditService *services.AuditService
+	upgrader     websocket.Upgrader
+}
+
+// NewTerminalLiveHandler 创建在线终端会话处理器
+func NewTerminalLiveHandler(db *gorm.DB, liveHub *terminalhub.Hub, auditService *services.AuditService) *TerminalLiveHandler {
+	return &TerminalLiveHandler{
+		db:           db,
+		liveHub:      liveHub,
+		auditService: auditService,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" {
+					return true
+				}
+				return middleware.IsRequestOriginAllowed(origin, r.Host)
+			},
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+		},
+	}
+}
+
+// KillSessionRequest 强制终止会话请求
+type KillSessionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ListLiveSessions 获取在线终端会话
+func (h *TerminalLiveHandler) ListLiveSessions(c *gin.Context) {
+	sessions := h.liveHub.List()
+
+	userIDs := make([]uint, 0, len(sessions))
+	for _, s := range sessions {
+		userIDs = append(userIDs, s.UserID)
+	}
+	usernames := make(map[uint]string)
+	if len(userIDs) > 0 {
+		var users []models.User
+		h.db.Select("id", "username").Where("id IN ?", userIDs).Find(&users)
+		for _, u := range users {
+			usernames[u.ID] = u.Username
+		}
+	}
+	for i := range sessions {
+		sessions[i].Username = usernames[sessions[i].UserID]
+	}
+
+	response.OK(c, sessions)
+}
+
+// KillSession 强制终止在线终端会话
+func (h *TerminalLiveHandler) KillSession(c *gin.Context) {
+	sessionID, ok := parseTerminalSessionID(c)
+	if !ok {
+		return
+	}
+
+	var req KillSessionRequest
+	_ = c.ShouldBindJSON(&req)
+	if req.Reason == "" {
+		req.Reason = "管理员操作"
+	}
+
+	if err := h.liveHub.Kill(sessionID, c.GetUint("user_id"), c.GetString("username"), req.Reason); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	logger.Info("终端会话被强制终止: session=%d, operator=%s, reason=%s", sessionID, c.GetString("username"), req.Reason)
+	response.OK(c, nil)
+}
+
+// GetSessionEvents 获取终端会话事件（旁观、协同操作、强制终止）
+func (h *TerminalLiveHandler) GetSessionEvents(c *gin.Context) {
+	sessionID, ok := parseTerminalSessionID(c)
+	if !ok {
+		return
+	}
+
+	events, err := h.auditService.GetSessionEvents(sessionID)
+	if err != nil {
+		response.Fail(c, errcode.Wrap(err, errcode.SessionEventsFailed))
+		return
+	}
+	response.OK(c, events)
+}
+
+// WatchSession 旁观在线终端会话（WebSocket）
+// 查询参数 mode: watch（只读，默认）或 drive（申请协同操作，需会话所有者同意）
+// 旁观者可发送 {"type":"input","data":"..."}（获得授权后）与 {"type":"request_drive"}
+func (h *TerminalLiveHandler) WatchSession(c *gin.Context) {
+	sessionID, ok := parseTerminalSessionID(c)
+	if !ok {
+		return
+	}
+	session := h.liveHub.Get(sessionID)
+	if session == nil {
+		response.FailCode(c, errcode.SessionOffline)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	watcher, err := session.Attach(c.GetUint("user_id"), c.GetString("username"), c.DefaultQuery("mode", terminalhub.ModeWatch))
+	if err != nil {
+		_ = conn.WriteJSON(terminalhub.Message{Type: "error", Data: err.Error()})
+		return
+	}
+	defer session.Detach(watcher)
+
+	// 输出协程：会话结束或旁观者离开时消息队列关闭
+	go func() {
+		for msg := range watcher.Messages() {
+			if err := conn.WriteJSON(msg); err != nil {
+				break
+			}
+		}
+		_ = conn.Close()
+	}()
+
+	// 读循环只处理旁观者指令，所有写入都经由消息队列，避免并发写 WebSocket
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		var msg terminalhub.Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		switch msg.Type {
+		case "input":
+			if err := session.Input(watcher, msg.Data); err != nil {
+				session.NotifyWatcher(watcher, err.Error())
+			}
+		case "request_drive":
+			if err := session.RequestDrive(watcher); err != nil {
+				session.NotifyWatcher(watcher, err.Error())
+			}
+		}
+	}
+}
+
+// parseTerminalSessionID 解析路径中的终端会话ID
+func parseTerminalSessionID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("sessionId"), 10, 32)
+	if err != nil {
+		response.FailCode(c, errcode.InvalidSessionID)
+		return 0, false
+	}
+	return uint(id), true
+}
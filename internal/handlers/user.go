@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
@@ -41,7 +42,7 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 
 	users, total, err := h.userService.ListUsers(params)
 	if err != nil {
-		response.InternalError(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -52,13 +53,13 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 func (h *UserHandler) GetUser(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的用户ID")
+		response.FailCode(c, errcode.InvalidUserID)
 		return
 	}
 
 	user, err := h.userService.GetUser(uint(id))
 	if err != nil {
-		response.NotFound(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -69,13 +70,13 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req services.CreateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
 	user, err := h.userService.CreateUser(&req)
 	if err != nil {
-		response.BadRequest(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -87,19 +88,19 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的用户ID")
+		response.FailCode(c, errcode.InvalidUserID)
 		return
 	}
 
 	var req services.UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
 	user, err := h.userService.UpdateUser(uint(id), &req)
 	if err != nil {
-		response.BadRequest(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -110,19 +111,19 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的用户ID")
+		response.FailCode(c, errcode.InvalidUserID)
 		return
 	}
 
 	// 不能删除自己
 	currentUserID := c.GetUint("user_id")
 	if currentUserID == uint(id) {
-		response.BadRequest(c, "不能删除自己")
+		response.FailCode(c, errcode.CannotDeleteSelf)
 		return
 	}
 
 	if err := h.userService.DeleteUser(uint(id)); err != nil {
-		response.BadRequest(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -138,18 +139,18 @@ type UpdateStatusRequest struct {
 func (h *UserHandler) UpdateUserStatus(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的用户ID")
+		response.FailCode(c, errcode.InvalidUserID)
 		return
 	}
 
 	var req UpdateStatusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
 	if err := h.userService.UpdateUserStatus(uint(id), req.Status); err != nil {
-		response.BadRequest(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -165,18 +166,18 @@ type ResetPasswordRequest struct {
 func (h *UserHandler) ResetPassword(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		response.BadRequest(c, "无效的用户ID")
+		response.FailCode(c, errcode.InvalidUserID)
 		return
 	}
 
 	var req ResetPasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "请求参数错误")
+		response.FailCode(c, errcode.BadRequest)
 		return
 	}
 
 	if err := h.userService.ResetPassword(uint(id), req.NewPassword); err != nil {
-		response.BadRequest(c, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
@@ -404,6 +404,15 @@ func (m *ClusterInformerManager) GetK8sClient(cluster *models.Cluster) (*service
 	return rt.k8sClient, nil
 }
 
+// EnsureClient 确保指定集群的 informer 缓存已同步，并返回其 K8sClient（供后台任务在读取 Lister 前调用）
+func (m *ClusterInformerManager) EnsureClient(ctx context.Context, cluster *models.Cluster, timeout time.Duration) (*services.K8sClient, error) {
+	rt, err := m.EnsureAndWait(ctx, cluster, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return rt.k8sClient, nil
+}
+
 // GetK8sClientByID 根据集群 ID 获取已缓存的 K8sClient（集群必须已通过 EnsureForCluster 初始化）
 func (m *ClusterInformerManager) GetK8sClientByID(clusterID uint) *services.K8sClient {
 	m.mu.RLock()
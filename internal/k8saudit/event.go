@@ -0,0 +1,193 @@
+// Package k8saudit 解析 Kubernetes API Server 审计 Webhook 推送的 audit.k8s.io/v1 EventList，
+// 将变更类请求归一化为可查询的审计事件，并识别发起请求的身份。
+package k8saudit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+// APIVersion 支持的审计事件版本
+const APIVersion = "audit.k8s.io/v1"
+
+// 审计阶段
+const (
+	StageResponseComplete = "ResponseComplete"
+	StagePanic            = "Panic"
+)
+
+// serviceAccountPrefix ServiceAccount 身份的用户名前缀
+const serviceAccountPrefix = "system:serviceaccount:"
+
+// mutatingVerbs 保存的变更类请求
+var mutatingVerbs = map[string]bool{
+	"create": true, "update": true, "patch": true, "delete": true, "deletecollection": true,
+}
+
+// EventList audit.k8s.io/v1 EventList（只解析需要的字段）
+type EventList struct {
+	Kind       string  `json:"kind"`
+	APIVersion string  `json:"apiVersion"`
+	Items      []Event `json:"items"`
+}
+
+// Event audit.k8s.io/v1 Event
+type Event struct {
+	Level                    string           `json:"level"`
+	AuditID                  string           `json:"auditID"`
+	Stage                    string           `json:"stage"`
+	RequestURI               string           `json:"requestURI"`
+	Verb                     string           `json:"verb"`
+	User                     UserInfo         `json:"user"`
+	ImpersonatedUser         *UserInfo        `json:"impersonatedUser,omitempty"`
+	SourceIPs                []string         `json:"sourceIPs,omitempty"`
+	UserAgent                string           `json:"userAgent,omitempty"`
+	ObjectRef                *ObjectReference `json:"objectRef,omitempty"`
+	ResponseStatus           *Status          `json:"responseStatus,omitempty"`
+	RequestReceivedTimestamp time.Time        `json:"requestReceivedTimestamp"`
+	StageTimestamp           time.Time        `json:"stageTimestamp"`
+}
+
+// UserInfo 请求身份
+type UserInfo struct {
+	Username string   `json:"username"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
+// ObjectReference 请求的对象
+type ObjectReference struct {
+	Resource    string `json:"resource,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name,omitempty"`
+	APIGroup    string `json:"apiGroup,omitempty"`
+	APIVersion  string `json:"apiVersion,omitempty"`
+	Subresource string `json:"subresource,omitempty"`
+}
+
+// Status 响应状态
+type Status struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Decode 解析 Webhook 请求体
+func Decode(r io.Reader) (*EventList, error) {
+	var list EventList
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return nil, fmt.Errorf("解析审计事件失败: %w", err)
+	}
+	if list.Kind != "EventList" || list.APIVersion != APIVersion {
+		return nil, fmt.Errorf("不支持的审计事件类型: %s %s", list.APIVersion, list.Kind)
+	}
+	return &list, nil
+}
+
+// Recordable 是否保存该事件：只保存变更类资源请求的最终阶段
+func (e *Event) Recordable() bool {
+	if e.Stage != StageResponseComplete && e.Stage != StagePanic {
+		return false
+	}
+	return mutatingVerbs[e.Verb] && e.ObjectRef != nil && e.ObjectRef.Resource != "" && e.AuditID != ""
+}
+
+// Actor 实际生效的身份：模拟请求取被模拟的身份
+func (e *Event) Actor() string {
+	if e.ImpersonatedUser != nil && e.ImpersonatedUser.Username != "" {
+		return e.ImpersonatedUser.Username
+	}
+	return e.User.Username
+}
+
+// Normalize 转换为审计事件记录（来源与关联字段由调用方识别后填写）
+func Normalize(clusterID uint, e *Event) *models.K8sAuditEvent {
+	ev := &models.K8sAuditEvent{
+		ClusterID:         clusterID,
+		AuditID:           e.AuditID,
+		Level:             e.Level,
+		Stage:             e.Stage,
+		Verb:              e.Verb,
+		RequestURI:        truncate(e.RequestURI, 1000),
+		UserAgent:         truncate(e.UserAgent, 500),
+		SourceIPs:         truncate(strings.Join(e.SourceIPs, ","), 200),
+		Username:          truncate(e.User.Username, 255),
+		Groups:            truncate(strings.Join(e.User.Groups, ","), 1000),
+		Actor:             truncate(e.Actor(), 255),
+		RequestReceivedAt: e.RequestReceivedTimestamp,
+		StageAt:           e.StageTimestamp,
+	}
+	if e.ImpersonatedUser != nil {
+		ev.ImpersonatedUser = truncate(e.ImpersonatedUser.Username, 255)
+	}
+	if ref := e.ObjectRef; ref != nil {
+		ev.APIGroup = ref.APIGroup
+		ev.APIVersion = ref.APIVersion
+		ev.Resource = ref.Resource
+		ev.Subresource = ref.Subresource
+		ev.Namespace = ref.Namespace
+		ev.Name = ref.Name
+	}
+	if s := e.ResponseStatus; s != nil {
+		ev.ResponseCode = s.Code
+		ev.ResponseMessage = truncate(s.Message, 1000)
+	}
+	return ev
+}
+
+// ServiceAccount 解析 ServiceAccount 身份（system:serviceaccount:<namespace>:<name>）
+func ServiceAccount(username string) (namespace, name string, ok bool) {
+	rest, found := strings.CutPrefix(username, serviceAccountPrefix)
+	if !found {
+		return "", "", false
+	}
+	namespace, name, ok = strings.Cut(rest, ":")
+	return namespace, name, ok && namespace != "" && name != ""
+}
+
+// operationResourceTypes API 资源名与平台操作日志资源类型不一致的部分
+var operationResourceTypes = map[string]string{
+	"persistentvolumeclaims": "pvc",
+	"persistentvolumes":      "pv",
+	"ingresses":              "ingress",
+	"storageclasses":         "storageclass",
+}
+
+// OperationResourceType 将 API 资源名（复数）转换为平台操作日志中的资源类型
+func OperationResourceType(resource string) string {
+	if t, ok := operationResourceTypes[resource]; ok {
+		return t
+	}
+	return strings.TrimSuffix(resource, "s")
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// WebhookKubeconfig 生成 API Server --audit-webhook-config-file 使用的 kubeconfig
+func WebhookKubeconfig(server, token string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: kubepolaris
+  cluster:
+    server: %s
+contexts:
+- name: kubepolaris
+  context:
+    cluster: kubepolaris
+    user: kubepolaris
+current-context: kubepolaris
+users:
+- name: kubepolaris
+  user:
+    token: %s
+`, server, token)
+}
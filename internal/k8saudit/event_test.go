@@ -0,0 +1,72 @@
+package k8saudit
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleEventList = `{
+  "kind": "EventList",
+  "apiVersion": "audit.k8s.io/v1",
+  "items": [
+    {"auditID": "a1", "stage": "RequestReceived", "verb": "patch", "user": {"username": "alice"},
+     "objectRef": {"resource": "deployments", "namespace": "default", "name": "web"}},
+    {"auditID": "a1", "stage": "ResponseComplete", "verb": "patch", "user": {"username": "alice"},
+     "objectRef": {"resource": "deployments", "namespace": "default", "name": "web"}, "responseStatus": {"code": 200}},
+    {"auditID": "a2", "stage": "ResponseComplete", "verb": "get", "user": {"username": "alice"},
+     "objectRef": {"resource": "pods", "namespace": "default", "name": "web-1"}},
+    {"auditID": "a3", "stage": "ResponseComplete", "verb": "create", "user": {"username": "admin"},
+     "impersonatedUser": {"username": "bob"}, "objectRef": {"resource": "configmaps", "namespace": "default", "name": "cfg"}}
+  ]
+}`
+
+func TestDecodeAndRecordable(t *testing.T) {
+	list, err := Decode(strings.NewReader(sampleEventList))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	var recordable []string
+	for i := range list.Items {
+		if list.Items[i].Recordable() {
+			recordable = append(recordable, list.Items[i].AuditID)
+		}
+	}
+	if strings.Join(recordable, ",") != "a1,a3" {
+		t.Fatalf("recordable = %v, want [a1 a3]", recordable)
+	}
+
+	ev := Normalize(7, &list.Items[3])
+	if ev.ClusterID != 7 || ev.Username != "admin" || ev.ImpersonatedUser != "bob" || ev.Actor != "bob" || ev.Name != "cfg" {
+		t.Fatalf("unexpected normalized event: %+v", ev)
+	}
+
+	if _, err := Decode(strings.NewReader(`{"kind":"EventList","apiVersion":"audit.k8s.io/v1beta1"}`)); err == nil {
+		t.Fatal("expected error for unsupported apiVersion")
+	}
+}
+
+func TestServiceAccount(t *testing.T) {
+	ns, name, ok := ServiceAccount("system:serviceaccount:kubepolaris-system:kubepolaris-user-3-sa")
+	if !ok || ns != "kubepolaris-system" || name != "kubepolaris-user-3-sa" {
+		t.Fatalf("got %q %q %v", ns, name, ok)
+	}
+	for _, username := range []string{"alice", "system:serviceaccount:", "system:serviceaccount:ns"} {
+		if _, _, ok := ServiceAccount(username); ok {
+			t.Fatalf("%q should not parse as a service account", username)
+		}
+	}
+}
+
+func TestOperationResourceType(t *testing.T) {
+	cases := map[string]string{
+		"deployments":            "deployment",
+		"persistentvolumeclaims": "pvc",
+		"ingresses":              "ingress",
+		"configmaps":             "configmap",
+	}
+	for resource, want := range cases {
+		if got := OperationResourceType(resource); got != want {
+			t.Errorf("OperationResourceType(%q) = %q, want %q", resource, got, want)
+		}
+	}
+}
@@ -6,12 +6,23 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
 )
 
 // AuthRequired JWT认证中间件
 func AuthRequired(secret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// 服务端执行已批准的变更：以申请人身份处理（上下文由服务端设置，外部请求无法携带）
+		if exec := services.ChangeExecutionFrom(c.Request.Context()); exec != nil {
+			c.Set("user_id", exec.RequesterID)
+			c.Set("username", exec.RequesterName)
+			c.Set("auth_type", "change_request")
+			c.Next()
+			return
+		}
+
 		var tokenString string
 
 		// 优先从请求头获取token
@@ -20,7 +31,7 @@ func AuthRequired(secret string) gin.HandlerFunc {
 			// 检查Bearer前缀
 			tokenString = strings.TrimPrefix(authHeader, "Bearer ")
 			if tokenString == authHeader {
-				response.Unauthorized(c, "认证令牌格式错误")
+				response.FailCode(c, errcode.TokenMalformed)
 				return
 			}
 		} else {
@@ -29,7 +40,7 @@ func AuthRequired(secret string) gin.HandlerFunc {
 		}
 
 		if tokenString == "" {
-			response.Unauthorized(c, "缺少认证令牌")
+			response.FailCode(c, errcode.TokenMissing)
 			return
 		}
 
@@ -39,7 +50,7 @@ func AuthRequired(secret string) gin.HandlerFunc {
 		})
 
 		if err != nil || !token.Valid {
-			response.Unauthorized(c, "认证令牌无效")
+			response.FailCode(c, errcode.TokenInvalid)
 			return
 		}
 
@@ -49,13 +60,13 @@ func AuthRequired(secret string) gin.HandlerFunc {
 			if userIDFloat, ok := claims["user_id"].(float64); ok {
 				c.Set("user_id", uint(userIDFloat))
 			} else {
-				response.Unauthorized(c, "认证令牌中缺少用户ID")
+				response.FailCode(c, errcode.TokenMissingUserID)
 				return
 			}
 			c.Set("username", claims["username"])
 			c.Set("auth_type", claims["auth_type"])
 		} else {
-			response.Unauthorized(c, "认证令牌格式无效")
+			response.FailCode(c, errcode.TokenMalformed)
 			return
 		}
 
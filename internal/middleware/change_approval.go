@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/constants"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+)
+
+// ChangeReasonHeader 提交待审批变更时的变更说明（非 ASCII 字符需 URL 编码）
+const ChangeReasonHeader = "X-Change-Reason"
+
+// ChangeApproval 变更双人审批检查
+// 需要在 AutoWriteCheck 之后、FreezeEnforcement 之前使用：命中审批策略的写请求不执行，
+// 而是保存为待审批变更并返回 202；批准后由服务端以申请人身份重放，此时直接放行
+func ChangeApproval(changeService *services.ChangeRequestService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if method == "GET" || method == "HEAD" || method == "OPTIONS" {
+			c.Next()
+			return
+		}
+		if services.ChangeExecutionFrom(c.Request.Context()) != nil {
+			c.Next()
+			return
+		}
+
+		resource, action := ResolvePolicyTarget(method, c.FullPath())
+		if resource == "" {
+			c.Next()
+			return
+		}
+		namespace, _, dryRun := writeRequestTarget(c, resource)
+		if dryRun {
+			c.Next()
+			return
+		}
+
+		clusterID := c.GetUint("cluster_id")
+		policy, err := changeService.Match(clusterID, resource, action)
+		if err != nil {
+			// 无法判定是否需要审批时拒绝执行，避免绕过双人审批
+			logger.Error("变更审批策略评估失败: %v", err)
+			response.FailCode(c, errcode.ApprovalPolicyFailed)
+			return
+		}
+		if policy == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > writeBodyPeekLimit {
+			response.FailCode(c, errcode.ApprovalBodyTooLarge)
+			return
+		}
+		var body []byte
+		if c.Request.Body != nil {
+			body, err = io.ReadAll(io.LimitReader(c.Request.Body, writeBodyPeekLimit+1))
+			if err != nil || len(body) > writeBodyPeekLimit {
+				response.FailCode(c, errcode.BodyReadFailed)
+				return
+			}
+		}
+
+		submitChange(c, changeService, policy, clusterID, namespace, resource, action, body)
+	}
+}
+
+// EnforceChangeApproval 供不在集群路由下的写操作（如撤销变更）直接调用，请求体不随变更保存
+// 可以直接执行时返回 true；命中审批策略时已保存为待审批变更并返回 202，评估失败时已写入错误响应，均返回 false
+func EnforceChangeApproval(c *gin.Context, changeService *services.ChangeRequestService, clusterID uint, namespace, resource, action string) bool {
+	if services.ChangeExecutionFrom(c.Request.Context()) != nil {
+		return true
+	}
+	policy, err := changeService.Match(clusterID, resource, action)
+	if err != nil {
+		logger.Error("变更审批策略评估失败: %v", err)
+		response.FailCode(c, errcode.ApprovalPolicyFailed)
+		return false
+	}
+	if policy == nil {
+		return true
+	}
+	submitChange(c, changeService, policy, clusterID, namespace, resource, action, nil)
+	return false
+}
+
+// submitChange 将写请求保存为待审批变更并返回 202
+func submitChange(c *gin.Context, changeService *services.ChangeRequestService, policy *models.ChangeApprovalPolicy, clusterID uint, namespace, resource, action string, body []byte) {
+	change, err := changeService.Submit(policy, &models.ChangeRequest{
+		RequesterID:          c.GetUint("user_id"),
+		RequesterName:        c.GetString("username"),
+		Reason:               decodeReasonHeader(c, ChangeReasonHeader),
+		ClusterID:            clusterID,
+		Namespace:            namespace,
+		Resource:             resource,
+		Action:               action,
+		Method:               c.Request.Method,
+		Path:                 c.Request.URL.Path,
+		Query:                c.Request.URL.RawQuery,
+		ContentType:          c.ContentType(),
+		Body:                 string(body),
+		FreezeOverrideReason: decodeReasonHeader(c, services.FreezeOverrideHeader),
+	})
+	if err != nil {
+		response.Fail(c, errcode.Ensure(err, errcode.ChangeSubmitFailed))
+		return
+	}
+
+	// 操作日志记录为“申请”，实际执行时另有一条同时带有审批人的日志
+	c.Set(AuditActionKey, constants.ActionRequest)
+	c.JSON(http.StatusAccepted, gin.H{
+		"code":           errcode.ApprovalRequired,
+		"message":        errcode.Message(response.Language(c), errcode.ApprovalRequired, policy.Name),
+		"change_request": change,
+	})
+	c.Abort()
+}
+
+// decodeReasonHeader 读取 URL 编码的说明类请求头
+func decodeReasonHeader(c *gin.Context, header string) string {
+	value := c.GetHeader(header)
+	if decoded, err := url.QueryUnescape(value); err == nil {
+		value = decoded
+	}
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+func TestChangeApprovalIgnoresDryRunOnRoutesWithoutDryRun(t *testing.T) {
+	db, cluster := newFreezeTestDB(t)
+	group := models.UserGroup{Name: "sre"}
+	if err := db.Create(&group).Error; err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	svc := services.NewChangeRequestService(db)
+	if _, err := svc.CreatePolicy(&services.ChangeApprovalPolicyRequest{
+		Name: "dangerous", Resources: []string{"nodes", "deployments"}, Actions: []string{"drain", "apply"}, ApproverGroupID: group.ID,
+	}); err != nil {
+		t.Fatalf("create policy: %v", err)
+	}
+
+	var executed []string
+	r := newWriteTestRouter(cluster.ID, ChangeApproval(svc), &executed)
+
+	// drain 会忽略 dryRun 直接执行，因此仍需审批
+	if w := postJSON(r, "/api/v1/clusters/1/nodes/node-1/drain", `{"dryRun":true,"ignoreDaemonSets":true}`); w.Code != http.StatusAccepted {
+		t.Fatalf("drain with dryRun: status %d, body %s", w.Code, w.Body.String())
+	}
+	if w := postJSON(r, "/api/v1/clusters/1/deployments/yaml/apply", `{"yaml":"kind: Deployment","dryRun":true}`); w.Code != http.StatusOK {
+		t.Fatalf("dry-run apply should not need approval: status %d", w.Code)
+	}
+	if len(executed) != 1 || executed[0] != "/api/v1/clusters/1/deployments/yaml/apply" {
+		t.Fatalf("drain must not execute before approval, got %v", executed)
+	}
+
+	var pending int64
+	db.Model(&models.ChangeRequest{}).Where("resource = ? AND action = ?", "nodes", "drain").Count(&pending)
+	if pending != 1 {
+		t.Fatalf("expected one pending drain change request, got %d", pending)
+	}
+}
+
+func TestEnforceChangeApprovalOutsideClusterRoutes(t *testing.T) {
+	db, cluster := newFreezeTestDB(t)
+	group := models.UserGroup{Name: "sre"}
+	if err := db.Create(&group).Error; err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	svc := services.NewChangeRequestService(db)
+	if _, err := svc.CreatePolicy(&services.ChangeApprovalPolicyRequest{
+		Name: "namespaces", Resources: []string{"namespaces"}, Actions: []string{"delete"}, ApproverGroupID: group.ID,
+	}); err != nil {
+		t.Fatalf("create policy: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	executed := 0
+	r.POST("/api/v1/audit/operations/:id/snapshots/:snapshotId/revert", func(c *gin.Context) {
+		c.Set("user_id", uint(1))
+		c.Set("username", "alice")
+		if !EnforceChangeApproval(c, svc, cluster.ID, "prod-shop", "namespaces", "delete") {
+			return
+		}
+		executed++
+		c.Status(http.StatusOK)
+	})
+
+	path := "/api/v1/audit/operations/7/snapshots/9/revert"
+	if w := postJSON(r, path, ""); w.Code != http.StatusAccepted {
+		t.Fatalf("revert deleting a namespace should need approval: status %d, body %s", w.Code, w.Body.String())
+	}
+	if executed != 0 {
+		t.Fatal("revert must not execute before approval")
+	}
+	var change models.ChangeRequest
+	if err := db.Where("path = ?", path).First(&change).Error; err != nil || change.Namespace != "prod-shop" || change.Status != models.ChangeRequestStatusPending {
+		t.Fatalf("expected pending change request, got %+v, %v", change, err)
+	}
+
+	// 批准后的重放直接执行
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	req = req.WithContext(services.WithChangeExecution(req.Context(), &services.ChangeExecution{RequestID: change.ID}))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || executed != 1 {
+		t.Fatalf("approved replay should execute: status %d, executed %d", w.Code, executed)
+	}
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"sigs.k8s.io/yaml"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+)
+
+// freezeMinReasonLength 紧急放行理由的最小字符数
+const freezeMinReasonLength = 5
+
+// writeBodyPeekLimit 解析写请求体的大小上限
+const writeBodyPeekLimit = 4 << 20
+
+// freezeExemptResources 冻结期间不拦截的写操作：平台自身配置、告警静默、权限同步等不改变集群工作负载的操作
+var freezeExemptResources = map[string]bool{
+	"clusters":     true,
+	"monitoring":   true,
+	"alertmanager": true,
+	"silences":     true,
+	"receivers":    true,
+	"argocd":       true, // ArgoCD 连接配置；应用的创建与同步归属 applications
+	"rbac":         true,
+}
+
+// dryRunApplyResources 应用 YAML 时以 DryRunAll 提交到 API Server 的资源
+// 其余写操作（删除、驱逐、扩缩容等）会忽略请求体中的 dryRun，不能据此跳过冻结与审批
+var dryRunApplyResources = map[string]bool{
+	"deployments":    true,
+	"rollouts":       true,
+	"statefulsets":   true,
+	"daemonsets":     true,
+	"jobs":           true,
+	"cronjobs":       true,
+	"configmaps":     true,
+	"secrets":        true,
+	"services":       true,
+	"ingresses":      true,
+	"pvcs":           true,
+	"pvs":            true,
+	"storageclasses": true,
+}
+
+// FreezeEnforcement 变更冻结检查
+// 需要在 AutoWriteCheck 之后使用：冻结窗口内的写操作被拒绝，除非请求携带紧急放行理由且日历允许放行
+func FreezeEnforcement(freezeService *services.FreezeService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if method == "GET" || method == "HEAD" || method == "OPTIONS" {
+			c.Next()
+			return
+		}
+		resource, action := ResolvePolicyTarget(method, c.FullPath())
+		if resource == "" || freezeExemptResources[resource] || action == models.PolicyActionPortForward {
+			c.Next()
+			return
+		}
+
+		namespace, known, dryRun := writeRequestTarget(c, resource)
+		if dryRun {
+			c.Next()
+			return
+		}
+		if !known {
+			// 无法确定目标命名空间时按命中所有限定命名空间的日历处理，避免冻结被绕过
+			namespace = services.FreezeAnyNamespace
+		}
+		if EnforceFreeze(c, freezeService, c.GetUint("cluster_id"), namespace) {
+			c.Next()
+		}
+	}
+}
+
+// EnforceFreeze 检查集群与命名空间是否处于冻结窗口，供不在集群路由下的写操作（如撤销变更）直接调用
+// 允许执行时返回 true（含紧急放行）；拒绝时已写入错误响应并返回 false
+func EnforceFreeze(c *gin.Context, freezeService *services.FreezeService, clusterID uint, namespace string) bool {
+	match, err := freezeService.Check(clusterID, namespace)
+	if err != nil {
+		// 无法判定是否处于冻结期时拒绝执行，避免冻结期间的变更漏过
+		logger.Error("变更冻结检查失败: %v", err)
+		response.Fail(c, errcode.Wrap(err, errcode.FreezeCheckFailed))
+		return false
+	}
+	if match == nil {
+		return true
+	}
+
+	reason := decodeReasonHeader(c, services.FreezeOverrideHeader)
+	// 允许紧急放行时提示填写理由，否则无论是否填写理由均拒绝
+	if reason == "" || !match.Calendar.AllowBreakGlass {
+		code := errcode.BreakGlassNotAllowed
+		if match.Calendar.AllowBreakGlass {
+			code = errcode.ChangeFrozen
+		}
+		frozen := errcode.New(code, match.Calendar.Name, match.Window.Name,
+			match.Window.EndAt.Local().Format("2006-01-02 15:04"))
+		c.Set("error_message", frozen.Error())
+		response.Fail(c, frozen)
+		return false
+	}
+	if utf8.RuneCountInString(reason) < freezeMinReasonLength {
+		response.FailCode(c, errcode.BreakGlassReasonTooShort, freezeMinReasonLength)
+		return false
+	}
+
+	override := &models.FreezeOverride{
+		UserID:    c.GetUint("user_id"),
+		Username:  c.GetString("username"),
+		ClusterID: clusterID,
+		Namespace: namespace,
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Reason:    reason,
+		ClientIP:  c.ClientIP(),
+	}
+	if err := freezeService.BreakGlass(match, override); err != nil {
+		// 放行记录是放行的前提
+		response.Fail(c, errcode.Ensure(err, errcode.BreakGlassRecordFailed))
+		return false
+	}
+	return true
+}
+
+// writeRequestBody 写请求体中与冻结、审批判定有关的字段
+type writeRequestBody struct {
+	Namespace string `json:"namespace"`
+	YAML      string `json:"yaml"`
+	DryRun    bool   `json:"dryRun"`
+}
+
+// clusterScopedResources 集群级资源，请求不涉及命名空间
+var clusterScopedResources = map[string]bool{
+	"namespaces":     true,
+	"nodes":          true,
+	"pvs":            true,
+	"storageclasses": true,
+}
+
+// writeRequestTarget 解析写请求涉及的命名空间；路径与查询参数中没有时从请求体（含 YAML）中读取
+// 请求体按 writeBodyPeekLimit 读取，与 Content-Length 无关（分块传输同样解析）。
+// known 为 false 表示无法确定目标命名空间：请求体不是 JSON、超过解析上限、无法解析，或 YAML 未指定命名空间，
+// 调用方需按命中所有命名空间处理。dryRun 仅在路由确实以 DryRunAll 执行时为 true，见 dryRunApplyResources
+func writeRequestTarget(c *gin.Context, resource string) (namespace string, known, dryRun bool) {
+	namespace = c.Param("namespace")
+	if namespace == "" {
+		namespace = c.Query("namespace")
+	}
+	known = namespace != "" || clusterScopedResources[resource]
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return namespace, true, false
+	}
+
+	body := c.Request.Body
+	data, err := io.ReadAll(io.LimitReader(body, writeBodyPeekLimit+1))
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), body), body}
+	switch {
+	case err != nil || len(data) > writeBodyPeekLimit:
+		return namespace, known, false
+	case len(data) == 0:
+		return namespace, true, false
+	case c.ContentType() != "application/json":
+		return namespace, known, false
+	}
+
+	var parsed writeRequestBody
+	if json.Unmarshal(data, &parsed) != nil {
+		return namespace, known, false
+	}
+	if namespace == "" {
+		namespace = parsed.Namespace
+	}
+	if namespace == "" && parsed.YAML != "" {
+		var object struct {
+			Metadata struct {
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		}
+		if yaml.Unmarshal([]byte(parsed.YAML), &object) == nil {
+			namespace = object.Metadata.Namespace
+		}
+	}
+	// YAML 未指定命名空间时由后端决定目标命名空间，无法据此判定
+	known = namespace != "" || parsed.YAML == "" || clusterScopedResources[resource]
+	return namespace, known, parsed.DryRun && honorsDryRun(c.Request.Method, c.FullPath(), resource)
+}
+
+// honorsDryRun 路由是否会把 dryRun 透传给 API Server
+func honorsDryRun(method, fullPath, resource string) bool {
+	return method == "POST" && dryRunApplyResources[resource] && strings.HasSuffix(fullPath, "/"+resource+"/yaml/apply")
+}
@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+// newFreezeTestDB 内存数据库，含一个 prod 集群
+func newFreezeTestDB(t *testing.T) (*gorm.DB, models.Cluster) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Cluster{}, &models.FreezeCalendar{}, &models.FreezeWindow{}, &models.FreezeOverride{},
+		&models.OperationLog{}, &models.UserGroup{}, &models.UserGroupMember{},
+		&models.ChangeApprovalPolicy{}, &models.ChangeRequest{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	cluster := models.Cluster{Name: "prod-1", APIServer: "https://prod-1:6443", Labels: `{"env":"prod"}`}
+	if err := db.Create(&cluster).Error; err != nil {
+		t.Fatalf("create cluster: %v", err)
+	}
+	return db, cluster
+}
+
+// newWriteTestRouter 注册 drain 与 yaml/apply 两个写路由，handler 执行时记录路径
+func newWriteTestRouter(clusterID uint, guard gin.HandlerFunc, executed *[]string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	cluster := r.Group("/api/v1/clusters/:clusterID")
+	cluster.Use(func(c *gin.Context) {
+		c.Set("cluster_id", clusterID)
+		c.Set("user_id", uint(1))
+		c.Set("username", "alice")
+	}, guard)
+	handler := func(c *gin.Context) {
+		*executed = append(*executed, c.Request.URL.Path)
+		c.Status(http.StatusOK)
+	}
+	cluster.POST("/nodes/:name/drain", handler)
+	cluster.POST("/deployments/yaml/apply", handler)
+	return r
+}
+
+func postJSON(r http.Handler, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestFreezeRequestTargetReadsNamespaceFromYAML(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := `{"yaml":"apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n  namespace: shop-web\n","dryRun":true}`
+	r := gin.New()
+	r.POST("/api/v1/clusters/:clusterID/deployments/yaml/apply", func(c *gin.Context) {
+		namespace, known, dryRun := writeRequestTarget(c, "deployments")
+		if namespace != "shop-web" || !known || !dryRun {
+			t.Errorf("got namespace=%q known=%v dryRun=%v", namespace, known, dryRun)
+		}
+		// 请求体需要保留给后续 handler
+		rest, err := io.ReadAll(c.Request.Body)
+		if err != nil || string(rest) != body {
+			t.Errorf("body not restored: %q, err=%v", rest, err)
+		}
+	})
+	postJSON(r, "/api/v1/clusters/1/deployments/yaml/apply", body)
+}
+
+func TestFreezeBlocksDryRunOnRoutesWithoutDryRun(t *testing.T) {
+	db, cluster := newFreezeTestDB(t)
+	svc := services.NewFreezeService(db, services.NewOperationLogService(db, nil, nil))
+	calendar, err := svc.CreateCalendar(&services.FreezeCalendarRequest{Name: "launch", ClusterSelector: map[string]string{"env": "prod"}}, "admin")
+	if err != nil {
+		t.Fatalf("create calendar: %v", err)
+	}
+	now := time.Now()
+	if _, err := svc.CreateWindow(calendar.ID, &services.FreezeWindowRequest{Name: "big-sale", StartAt: now.Add(-time.Minute), EndAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("create window: %v", err)
+	}
+
+	var executed []string
+	r := newWriteTestRouter(cluster.ID, FreezeEnforcement(svc), &executed)
+
+	// drain 不会把 dryRun 传给 API Server，必须照常拦截
+	if w := postJSON(r, "/api/v1/clusters/1/nodes/node-1/drain", `{"dryRun":true}`); w.Code != http.StatusLocked {
+		t.Fatalf("drain with dryRun during freeze: status %d, body %s", w.Code, w.Body.String())
+	}
+	// yaml/apply 以 DryRunAll 执行，预检不受冻结限制
+	if w := postJSON(r, "/api/v1/clusters/1/deployments/yaml/apply", `{"yaml":"kind: Deployment","dryRun":true}`); w.Code != http.StatusOK {
+		t.Fatalf("dry-run apply during freeze: status %d, body %s", w.Code, w.Body.String())
+	}
+	if w := postJSON(r, "/api/v1/clusters/1/deployments/yaml/apply", `{"yaml":"kind: Deployment"}`); w.Code != http.StatusLocked {
+		t.Fatalf("apply during freeze: status %d", w.Code)
+	}
+	if len(executed) != 1 || !strings.HasSuffix(executed[0], "/yaml/apply") {
+		t.Fatalf("only the dry-run apply should execute, got %v", executed)
+	}
+}
+
+func TestFreezeFailsClosedWhenNamespaceUnknown(t *testing.T) {
+	db, cluster := newFreezeTestDB(t)
+	svc := services.NewFreezeService(db, services.NewOperationLogService(db, nil, nil))
+	calendar, err := svc.CreateCalendar(&services.FreezeCalendarRequest{Name: "prod", Namespaces: []string{"prod-*"}}, "admin")
+	if err != nil {
+		t.Fatalf("create calendar: %v", err)
+	}
+	now := time.Now()
+	if _, err := svc.CreateWindow(calendar.ID, &services.FreezeWindowRequest{Name: "release", StartAt: now.Add(-time.Minute), EndAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("create window: %v", err)
+	}
+
+	var executed []string
+	r := newWriteTestRouter(cluster.ID, FreezeEnforcement(svc), &executed)
+	apply := "/api/v1/clusters/1/deployments/yaml/apply"
+	send := func(body, contentType string, contentLength int64) int {
+		req := httptest.NewRequest(http.MethodPost, apply, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.ContentLength = contentLength
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	prodYAML := `{"yaml":"kind: Deployment\nmetadata:\n  name: web\n  namespace: prod-shop\n"}`
+	if code := send(prodYAML, "application/json", -1); code != http.StatusLocked {
+		t.Fatalf("chunked apply into frozen namespace: status %d", code)
+	}
+	if code := send(`{"yaml":"kind: Deployment\nmetadata:\n  name: web\n"}`, "application/json", -1); code != http.StatusLocked {
+		t.Fatalf("apply without namespace must fail closed: status %d", code)
+	}
+	if code := send("kind: Deployment\n", "application/yaml", 17); code != http.StatusLocked {
+		t.Fatalf("non-JSON apply must fail closed: status %d", code)
+	}
+	devYAML := `{"yaml":"kind: Deployment\nmetadata:\n  name: web\n  namespace: dev-shop\n"}`
+	if code := send(devYAML, "application/json", int64(len(devYAML))); code != http.StatusOK {
+		t.Fatalf("apply into unfrozen namespace: status %d", code)
+	}
+	// 节点为集群级资源，不受限定命名空间的日历影响
+	if w := postJSON(r, "/api/v1/clusters/1/nodes/node-1/drain", `{}`); w.Code != http.StatusOK {
+		t.Fatalf("drain: status %d", w.Code)
+	}
+	if len(executed) != 2 {
+		t.Fatalf("only the dev apply and drain should execute, got %v", executed)
+	}
+}
+
+func TestFreezeFailsClosedWhenCalendarsUnavailable(t *testing.T) {
+	db, cluster := newFreezeTestDB(t)
+	svc := services.NewFreezeService(db, services.NewOperationLogService(db, nil, nil))
+	if err := db.Migrator().DropTable(&models.FreezeCalendar{}); err != nil {
+		t.Fatalf("drop table: %v", err)
+	}
+
+	var executed []string
+	r := newWriteTestRouter(cluster.ID, FreezeEnforcement(svc), &executed)
+	if w := postJSON(r, "/api/v1/clusters/1/nodes/node-1/drain", `{}`); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 when freeze calendars cannot be loaded, got %d", w.Code)
+	}
+	if len(executed) != 0 {
+		t.Fatalf("write must not execute, got %v", executed)
+	}
+}
+
+func TestDecodeReasonHeader(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	c.Request.Header.Set(services.FreezeOverrideHeader, "%E5%9B%9E%E6%BB%9A%E6%95%85%E9%9A%9C%E7%89%88%E6%9C%AC")
+	if reason := decodeReasonHeader(c, services.FreezeOverrideHeader); reason != "回滚故障版本" {
+		t.Fatalf("reason = %q", reason)
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+// UserLanguage 将登录用户的语言偏好写入上下文，错误文案优先使用该语言，未设置时按 Accept-Language
+// 需要在 AuthRequired 之后使用
+func UserLanguage(prefSvc *services.UserPreferenceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userID := c.GetUint("user_id"); userID != 0 {
+			if lang := prefSvc.Language(userID); lang != "" {
+				c.Set(response.LanguageKey, lang)
+			}
+		}
+		c.Next()
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/constants"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
 
@@ -47,6 +48,10 @@ func init() {
 		{`^/api/v1/clusters/import$`, constants.ModuleCluster, constants.ActionImport, "cluster", -1},
 		{`^/api/v1/clusters/test-connection$`, constants.ModuleCluster, constants.ActionTest, "cluster", -1},
 		{`^/api/v1/clusters/(\d+)$`, constants.ModuleCluster, "", "cluster", 1},
+		{`^/api/v1/clusters/(\d+)/labels$`, constants.ModuleCluster, constants.ActionUpdate, "cluster", 1},
+		{`^/api/v1/clusters/(\d+)/node-terminal$`, constants.ModuleCluster, constants.ActionUpdate, "cluster", 1},
+		{`^/api/v1/cluster-groups$`, constants.ModuleCluster, constants.ActionCreate, "cluster_group", -1},
+		{`^/api/v1/cluster-groups/(\d+)$`, constants.ModuleCluster, "", "cluster_group", 1},
 
 		// 节点模块
 		{`^/api/v1/clusters/\d+/nodes/([^/]+)/cordon$`, constants.ModuleNode, constants.ActionCordon, "node", 1},
@@ -55,6 +60,13 @@ func init() {
 
 		// Pod 模块
 		{`^/api/v1/clusters/\d+/pods/([^/]+)/([^/]+)$`, constants.ModulePod, "", "pod", 2},
+		{`^/api/v1/clusters/\d+/pods/([^/]+)/([^/]+)/files/upload$`, constants.ModulePod, constants.ActionUpload, "pod", 2},
+		{`^/api/v1/clusters/\d+/pods/([^/]+)/([^/]+)/files/download$`, constants.ModulePod, constants.ActionDownload, "pod", 2},
+
+		// 端口转发模块
+		{`^/api/v1/clusters/\d+/pods/([^/]+)/([^/]+)/portforward$`, constants.ModulePortForward, constants.ActionCreate, "pod", 2},
+		{`^/api/v1/clusters/\d+/services/([^/]+)/([^/]+)/portforward$`, constants.ModulePortForward, constants.ActionCreate, "service", 2},
+		{`^/api/v1/port-forwards/([^/]+)$`, constants.ModulePortForward, constants.ActionDelete, "port_forward", 1},
 
 		// Deployment 模块
 		{`^/api/v1/clusters/\d+/deployments/yaml/apply$`, constants.ModuleWorkload, constants.ActionApply, "deployment", -1},
@@ -134,12 +146,74 @@ func init() {
 		{`^/api/v1/permissions/cluster-permissions$`, constants.ModulePermission, constants.ActionCreate, "cluster_permission", -1},
 		{`^/api/v1/permissions/cluster-permissions/(\d+)$`, constants.ModulePermission, "", "cluster_permission", 1},
 		{`^/api/v1/permissions/cluster-permissions/batch-delete$`, constants.ModulePermission, constants.ActionDelete, "cluster_permission", -1},
+		{`^/api/v1/permissions/access-requests$`, constants.ModulePermission, constants.ActionRequest, "access_request", -1},
+		{`^/api/v1/permissions/access-requests/(\d+)/approve$`, constants.ModulePermission, constants.ActionApprove, "access_request", 1},
+		{`^/api/v1/permissions/access-requests/(\d+)/reject$`, constants.ModulePermission, constants.ActionReject, "access_request", 1},
+		{`^/api/v1/permissions/access-requests/(\d+)/cancel$`, constants.ModulePermission, constants.ActionCancel, "access_request", 1},
+		{`^/api/v1/permissions/access-requests/(\d+)/revoke$`, constants.ModulePermission, constants.ActionRevoke, "access_request", 1},
+		{`^/api/v1/permissions/jit-config$`, constants.ModulePermission, constants.ActionUpdate, "jit_config", -1},
+		{`^/api/v1/permissions/policies$`, constants.ModulePermission, constants.ActionCreate, "permission_policy", -1},
+		{`^/api/v1/permissions/policies/(\d+)$`, constants.ModulePermission, "", "permission_policy", 1},
+		{`^/api/v1/permissions/access-review/attest$`, constants.ModulePermission, constants.ActionAttest, "access_review", -1},
+		{`^/api/v1/permissions/access-review/revoke$`, constants.ModulePermission, constants.ActionRevoke, "access_review", -1},
+		{`^/api/v1/permissions/terminal-command-rules$`, constants.ModulePermission, constants.ActionCreate, "terminal_command_rule", -1},
+		{`^/api/v1/permissions/terminal-command-rules/(\d+)$`, constants.ModulePermission, "", "terminal_command_rule", 1},
+
+		// 变更冻结日历
+		{`^/api/v1/change-requests/(\d+)/approve$`, constants.ModuleSystem, constants.ActionApprove, "change_request", 1},
+		{`^/api/v1/change-requests/(\d+)/reject$`, constants.ModuleSystem, constants.ActionReject, "change_request", 1},
+		{`^/api/v1/change-requests/(\d+)/cancel$`, constants.ModuleSystem, constants.ActionCancel, "change_request", 1},
+		{`^/api/v1/change-approval-policies$`, constants.ModuleSystem, constants.ActionCreate, "change_approval_policy", -1},
+		{`^/api/v1/change-approval-policies/(\d+)$`, constants.ModuleSystem, "", "change_approval_policy", 1},
+		{`^/api/v1/freeze/calendars$`, constants.ModuleSystem, constants.ActionCreate, "freeze_calendar", -1},
+		{`^/api/v1/freeze/calendars/(\d+)$`, constants.ModuleSystem, "", "freeze_calendar", 1},
+		{`^/api/v1/freeze/calendars/(\d+)/windows$`, constants.ModuleSystem, constants.ActionCreate, "freeze_window", 1},
+		{`^/api/v1/freeze/windows/(\d+)$`, constants.ModuleSystem, "", "freeze_window", 1},
+
+		// 终端模块
+		{`^/api/v1/audit/terminal/sessions/(\d+)/kill$`, constants.ModuleTerminal, constants.ActionKill, "terminal_session", 1},
+		{`^/api/v1/audit/terminal/sessions/(\d+)/reindex$`, constants.ModuleTerminal, constants.ActionUpdate, "terminal_session", 1},
+
+		// 变更回滚
+		{`^/api/v1/audit/operations/(\d+)/snapshots/(\d+)/revert$`, constants.ModuleCluster, constants.ActionRevert, "operation_snapshot", 2},
+
+		// 审计配置
+		{`^/api/v1/audit/chain/anchor$`, constants.ModuleSystem, constants.ActionCreate, "audit_chain_anchor", -1},
+		{`^/api/v1/audit/sinks$`, constants.ModuleSystem, constants.ActionCreate, "audit_sink", -1},
+		{`^/api/v1/audit/sinks/(\d+)/test$`, constants.ModuleSystem, constants.ActionTest, "audit_sink", 1},
+		{`^/api/v1/audit/sinks/(\d+)$`, constants.ModuleSystem, "", "audit_sink", 1},
+
+		// 审计数据治理
+		{`^/api/v1/audit/retention/run$`, constants.ModuleSystem, constants.ActionPurge, "audit_retention", -1},
+		{`^/api/v1/audit/users/(\d+)/export$`, constants.ModuleSystem, constants.ActionExport, "user", 1},
+		{`^/api/v1/audit/users/(\d+)/pseudonymize$`, constants.ModuleSystem, constants.ActionPseudonymize, "user", 1},
+		{`^/api/v1/audit/k8s/clusters/(\d+)/webhook$`, constants.ModuleCluster, constants.ActionUpdate, "k8s_audit_webhook", 1},
+
+		// 租户模块
+		{`^/api/v1/tenants$`, constants.ModuleTenant, constants.ActionCreate, "tenant", -1},
+		{`^/api/v1/tenants/(\d+)$`, constants.ModuleTenant, "", "tenant", 1},
+		{`^/api/v1/tenants/(\d+)/namespaces$`, constants.ModuleTenant, constants.ActionCreate, "tenant_namespace", 1},
+		{`^/api/v1/tenants/(\d+)/namespaces/(\d+)$`, constants.ModuleTenant, constants.ActionDelete, "tenant_namespace", 2},
+		{`^/api/v1/tenants/(\d+)/members$`, constants.ModuleTenant, constants.ActionCreate, "tenant_member", 1},
+		{`^/api/v1/tenants/(\d+)/members/(\d+)$`, constants.ModuleTenant, constants.ActionDelete, "tenant_member", 2},
+		{`^/api/v1/tenants/(\d+)/permissions$`, constants.ModuleTenant, constants.ActionCreate, "tenant_permission", 1},
+		{`^/api/v1/tenants/(\d+)/permissions/(\d+)$`, constants.ModuleTenant, constants.ActionDelete, "tenant_permission", 2},
 
 		// 系统设置模块
 		{`^/api/v1/system/ldap/config$`, constants.ModuleSystem, "", "ldap_config", -1},
 		{`^/api/v1/system/ldap/test-connection$`, constants.ModuleSystem, constants.ActionTest, "ldap_config", -1},
 		{`^/api/v1/system/ldap/test-auth$`, constants.ModuleSystem, constants.ActionTest, "ldap_auth", -1},
+		{`^/api/v1/system/notifications/config$`, constants.ModuleSystem, "", "notification_config", -1},
+		{`^/api/v1/system/notifications/channels/([^/]+)/test$`, constants.ModuleSystem, constants.ActionTest, "notification_channel", 1},
+		{`^/api/v1/system/notifications/deliveries/(\d+)/retry$`, constants.ModuleSystem, constants.ActionUpdate, "notification_delivery", 1},
+		{`^/api/v1/reports/schedules$`, constants.ModuleSystem, constants.ActionCreate, "report_schedule", -1},
+		{`^/api/v1/reports/schedules/(\d+)/run$`, constants.ModuleSystem, constants.ActionSend, "report_schedule", 1},
+		{`^/api/v1/reports/schedules/(\d+)$`, constants.ModuleSystem, "", "report_schedule", 1},
 		{`^/api/v1/system/ssh/config$`, constants.ModuleSystem, "", "ssh_config", -1},
+		{`^/api/v1/system/ssh/profiles$`, constants.ModuleSystem, constants.ActionCreate, "ssh_profile", -1},
+		{`^/api/v1/system/ssh/profiles/(\d+)$`, constants.ModuleSystem, "", "ssh_profile", 1},
+		{`^/api/v1/system/ssh/host-keys/(\d+)/accept$`, constants.ModuleSystem, constants.ActionApprove, "ssh_host_key", 1},
+		{`^/api/v1/system/ssh/host-keys/(\d+)$`, constants.ModuleSystem, constants.ActionDelete, "ssh_host_key", 1},
 	}
 
 	for _, r := range rules {
@@ -153,33 +227,43 @@ func init() {
 	}
 }
 
+// AuditDetailKey handler 写入的审计详情（如文件传输的路径、大小、摘要），记录为操作日志的请求体。
+// GET 请求默认不记录，设置了该值的 GET 请求（如文件下载）也会记录。
+const AuditDetailKey = "audit_detail"
+
+// AuditSnapshotsKey handler 写入的对象变更快照（[]*models.OperationLogSnapshot），仅在请求成功时随操作日志保存。
+const AuditSnapshotsKey = "audit_snapshots"
+
+// AuditActionKey handler/中间件覆盖的操作类型（如提交待审批变更时记为申请）
+const AuditActionKey = "audit_action"
+
 // OperationAudit 操作审计中间件
 func OperationAudit(logSvc *services.OperationLogService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 只记录非 GET 请求
-		if c.Request.Method == "GET" {
+		// 跳过健康检查等
+		path := c.Request.URL.Path
+		if strings.HasPrefix(path, "/healthz") || strings.HasPrefix(path, "/readyz") {
 			c.Next()
 			return
 		}
 
-		// 跳过健康检查等
-		path := c.Request.URL.Path
-		if strings.HasPrefix(path, "/healthz") || strings.HasPrefix(path, "/readyz") {
+		// 跳过 WebSocket 请求（由终端审计单独处理）和端口转发反向代理（由端口转发记录审计）
+		if strings.HasPrefix(path, "/ws/") || strings.HasPrefix(path, "/proxy/") {
 			c.Next()
 			return
 		}
 
-		// 跳过 WebSocket 请求（由终端审计单独处理）
-		if strings.HasPrefix(path, "/ws/") {
+		// 跳过 API Server 审计 Webhook 推送（事件本身即审计记录）
+		if strings.HasPrefix(path, "/api/v1/k8s-audit/") {
 			c.Next()
 			return
 		}
 
 		startTime := time.Now()
 
-		// 读取并缓存请求体
+		// 读取并缓存请求体（文件上传等二进制请求体直接流式转发，不读入内存）
 		var requestBody interface{}
-		if c.Request.Body != nil && c.Request.ContentLength > 0 {
+		if c.Request.Method != "GET" && c.Request.Body != nil && c.Request.ContentLength > 0 && !isBinaryContentType(c.ContentType()) {
 			bodyBytes, err := io.ReadAll(c.Request.Body)
 			if err == nil && len(bodyBytes) > 0 {
 				c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
@@ -190,6 +274,13 @@ func OperationAudit(logSvc *services.OperationLogService) gin.HandlerFunc {
 		// 执行请求
 		c.Next()
 
+		// 只记录非 GET 请求，以及 handler 显式提供审计详情的请求
+		if detail, exists := c.Get(AuditDetailKey); exists {
+			requestBody = detail
+		} else if c.Request.Method == "GET" {
+			return
+		}
+
 		// 解析路由信息
 		module, action, resourceType, resourceName := parseRoute(c, path)
 
@@ -197,6 +288,9 @@ func OperationAudit(logSvc *services.OperationLogService) gin.HandlerFunc {
 		if action == "" {
 			action = methodToAction(c.Request.Method)
 		}
+		if override := c.GetString(AuditActionKey); override != "" {
+			action = override
+		}
 
 		// 获取集群信息
 		var clusterID *uint
@@ -268,6 +362,18 @@ func OperationAudit(logSvc *services.OperationLogService) gin.HandlerFunc {
 			Duration:     time.Since(startTime).Milliseconds(),
 		}
 
+		// 服务端执行已批准的变更：操作者为申请人，同时记录审批人
+		if exec := services.ChangeExecutionFrom(c.Request.Context()); exec != nil {
+			requestID, approverID := exec.RequestID, exec.ApproverID
+			entry.ChangeRequestID = &requestID
+			entry.ApproverID = &approverID
+			entry.ApproverName = exec.ApproverName
+		}
+
+		if snaps, exists := c.Get(AuditSnapshotsKey); exists && entry.Success {
+			entry.Snapshots, _ = snaps.([]*models.OperationLogSnapshot)
+		}
+
 		// 异步记录
 		logSvc.RecordAsync(entry)
 
@@ -307,6 +413,11 @@ func parseRoute(c *gin.Context, path string) (module, action, resourceType, reso
 	return
 }
 
+// isBinaryContentType 是否为不应读入内存的二进制请求体
+func isBinaryContentType(contentType string) bool {
+	return contentType == "application/octet-stream" || strings.HasPrefix(contentType, "multipart/")
+}
+
 // methodToAction 根据 HTTP 方法返回操作
 func methodToAction(method string) string {
 	switch method {
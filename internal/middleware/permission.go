@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
@@ -29,7 +30,7 @@ func (m *PermissionMiddleware) ClusterAccessRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID := c.GetUint("user_id")
 		if userID == 0 {
-			response.Unauthorized(c, "未登录")
+			response.FailCode(c, errcode.NotLoggedIn)
 			return
 		}
 
@@ -46,14 +47,14 @@ func (m *PermissionMiddleware) ClusterAccessRequired() gin.HandlerFunc {
 
 		clusterID, err := strconv.ParseUint(clusterIDStr, 10, 64)
 		if err != nil {
-			response.BadRequest(c, "无效的集群ID")
+			response.FailCode(c, errcode.InvalidClusterID)
 			return
 		}
 
 		// 检查权限
 		permission, err := m.permissionService.GetUserClusterPermission(userID, uint(clusterID))
 		if err != nil {
-			response.Forbidden(c, "无权限访问该集群")
+			response.FailCode(c, errcode.ClusterAccessDenied)
 			return
 		}
 
@@ -71,7 +72,7 @@ func (m *PermissionMiddleware) NamespaceAccessRequired() gin.HandlerFunc {
 		// 获取权限信息
 		permissionInterface, exists := c.Get("cluster_permission")
 		if !exists {
-			response.Forbidden(c, "无集群访问权限")
+			response.FailCode(c, errcode.ClusterAccessDenied)
 			return
 		}
 
@@ -85,7 +86,7 @@ func (m *PermissionMiddleware) NamespaceAccessRequired() gin.HandlerFunc {
 
 		// 如果有命名空间参数，检查权限
 		if namespace != "" && !services.HasNamespaceAccess(permission, namespace) {
-			response.Forbidden(c, "无权限访问该命名空间")
+			response.FailCode(c, errcode.NamespaceAccessDenied)
 			return
 		}
 
@@ -100,16 +101,20 @@ func (m *PermissionMiddleware) ActionRequired(actions ...string) gin.HandlerFunc
 		// 获取权限信息
 		permissionInterface, exists := c.Get("cluster_permission")
 		if !exists {
-			response.Forbidden(c, "无集群访问权限")
+			response.FailCode(c, errcode.ClusterAccessDenied)
 			return
 		}
 
 		permission := permissionInterface.(*models.ClusterPermission)
 
-		// 检查所有要求的操作权限
+		// 检查所有要求的操作权限；调试、上传等高危操作可由细粒度策略显式允许
+		policyAllowed := GetPolicyDecision(c).Allowed()
 		for _, action := range actions {
-			if !services.CanPerformAction(permission, action) {
-				response.Forbidden(c, "权限不足，无法执行此操作")
+			if services.CanPerformAction(permission, action) {
+				continue
+			}
+			if !policyAllowed || !services.RequiresExplicitGrant(permission, action) {
+				response.FailCode(c, errcode.Forbidden)
 				return
 			}
 		}
@@ -119,20 +124,20 @@ func (m *PermissionMiddleware) ActionRequired(actions ...string) gin.HandlerFunc
 }
 
 // AdminRequired 管理员权限检查
-// 只有管理员权限才能访问
+// 只有全部命名空间的管理员权限才能访问，限定命名空间的管理员（如临时提权）不具备集群级管理能力
 func (m *PermissionMiddleware) AdminRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 获取权限信息
 		permissionInterface, exists := c.Get("cluster_permission")
 		if !exists {
-			response.Forbidden(c, "无集群访问权限")
+			response.FailCode(c, errcode.ClusterAccessDenied)
 			return
 		}
 
 		permission := permissionInterface.(*models.ClusterPermission)
 
-		if permission.PermissionType != models.PermissionTypeAdmin {
-			response.Forbidden(c, "需要管理员权限")
+		if permission.PermissionType != models.PermissionTypeAdmin || !services.HasAllNamespaceAccess(permission) {
+			response.FailCode(c, errcode.AdminRequired)
 			return
 		}
 
@@ -147,14 +152,14 @@ func (m *PermissionMiddleware) WriteRequired() gin.HandlerFunc {
 		// 获取权限信息
 		permissionInterface, exists := c.Get("cluster_permission")
 		if !exists {
-			response.Forbidden(c, "无集群访问权限")
+			response.FailCode(c, errcode.ClusterAccessDenied)
 			return
 		}
 
 		permission := permissionInterface.(*models.ClusterPermission)
 
 		if permission.PermissionType == models.PermissionTypeReadonly {
-			response.Forbidden(c, "只读权限无法执行写操作")
+			response.FailCode(c, errcode.ReadOnlyPermission)
 			return
 		}
 
@@ -177,15 +182,15 @@ func (m *PermissionMiddleware) AutoWriteCheck() gin.HandlerFunc {
 		permissionInterface, exists := c.Get("cluster_permission")
 		if !exists {
 			// 如果没有权限信息，说明 ClusterAccessRequired 没有运行或失败
-			response.Forbidden(c, "无集群访问权限")
+			response.FailCode(c, errcode.ClusterAccessDenied)
 			return
 		}
 
 		permission := permissionInterface.(*models.ClusterPermission)
 
-		// 只读权限无法执行写操作
-		if permission.PermissionType == models.PermissionTypeReadonly {
-			response.Forbidden(c, "只读权限无法执行写操作，请联系管理员获取更高权限")
+		// 只读权限无法执行写操作（细粒度策略显式允许的除外）
+		if permission.PermissionType == models.PermissionTypeReadonly && !GetPolicyDecision(c).Allowed() {
+			response.FailCode(c, errcode.ReadOnlyPermission)
 			return
 		}
 
@@ -198,42 +203,54 @@ func (m *PermissionMiddleware) AutoWriteCheck() gin.HandlerFunc {
 func PlatformAdminRequired(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID := c.GetUint("user_id")
-		username := c.GetString("username")
 		if userID == 0 {
-			response.Unauthorized(c, "未登录")
+			response.FailCode(c, errcode.NotLoggedIn)
 			return
 		}
 
-		if username == "admin" {
+		if IsPlatformAdmin(db, userID, c.GetString("username")) {
 			c.Next()
 			return
 		}
 
-		// 检查用户是否直接拥有 admin 权限
-		var count int64
+		response.FailCode(c, errcode.PlatformAdminRequired)
+	}
+}
+
+// IsPlatformAdmin 判断用户是否为平台管理员
+// 租户成员的权限被限制在租户命名空间内，不会被视为平台管理员
+func IsPlatformAdmin(db *gorm.DB, userID uint, username string) bool {
+	if username == "admin" {
+		return true
+	}
+
+	var tenantCount int64
+	db.Model(&models.TenantMember{}).Where("user_id = ?", userID).Count(&tenantCount)
+	if tenantCount > 0 {
+		return false
+	}
+
+	// 检查用户是否直接拥有 admin 权限（临时提权获得的 admin 不视为平台管理员）
+	var count int64
+	db.Model(&models.ClusterPermission{}).
+		Where("user_id = ? AND permission_type = ? AND expires_at IS NULL", userID, models.PermissionTypeAdmin).
+		Count(&count)
+	if count > 0 {
+		return true
+	}
+
+	// 检查用户所在用户组是否拥有 admin 权限
+	var groupIDs []uint
+	db.Model(&models.UserGroupMember{}).Where("user_id = ?", userID).Pluck("user_group_id", &groupIDs)
+	if len(groupIDs) > 0 {
 		db.Model(&models.ClusterPermission{}).
-			Where("user_id = ? AND permission_type = ?", userID, models.PermissionTypeAdmin).
+			Where("user_group_id IN ? AND permission_type = ? AND expires_at IS NULL", groupIDs, models.PermissionTypeAdmin).
 			Count(&count)
 		if count > 0 {
-			c.Next()
-			return
-		}
-
-		// 检查用户所在用户组是否拥有 admin 权限
-		var groupIDs []uint
-		db.Model(&models.UserGroupMember{}).Where("user_id = ?", userID).Pluck("user_group_id", &groupIDs)
-		if len(groupIDs) > 0 {
-			db.Model(&models.ClusterPermission{}).
-				Where("user_group_id IN ? AND permission_type = ?", groupIDs, models.PermissionTypeAdmin).
-				Count(&count)
-			if count > 0 {
-				c.Next()
-				return
-			}
+			return true
 		}
-
-		response.Forbidden(c, "需要平台管理员权限")
 	}
+	return false
 }
 
 // GetClusterPermission 从上下文获取集群权限
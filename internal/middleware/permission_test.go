@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+func TestActionRequiredDebugNeedsExplicitPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	runAction := func(action, permissionType string, decision *services.PolicyDecision) int {
+		r := gin.New()
+		r.GET("/pods/:namespace/:name/action", func(c *gin.Context) {
+			c.Set("cluster_permission", &models.ClusterPermission{PermissionType: permissionType})
+			if decision != nil {
+				c.Set("policy_decision", decision)
+			}
+		}, (&PermissionMiddleware{}).ActionRequired(action), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pods/default/web/action", nil))
+		return w.Code
+	}
+	run := func(permissionType string, decision *services.PolicyDecision) int {
+		return runAction("pod:debug", permissionType, decision)
+	}
+
+	allow := &services.PolicyDecision{Effect: models.PolicyEffectAllow}
+	if code := run(models.PermissionTypeCustom, nil); code != http.StatusForbidden {
+		t.Errorf("custom without policy: got %d, want 403", code)
+	}
+	if code := run(models.PermissionTypeCustom, allow); code != http.StatusOK {
+		t.Errorf("custom with allow policy: got %d, want 200", code)
+	}
+	if code := run(models.PermissionTypeReadonly, allow); code != http.StatusForbidden {
+		t.Errorf("readonly is not widened by allow policy: got %d, want 403", code)
+	}
+	for _, action := range []string{"pod:debug", "pod:upload"} {
+		for _, permissionType := range []string{models.PermissionTypeDev, models.PermissionTypeOps} {
+			if code := runAction(action, permissionType, nil); code != http.StatusForbidden {
+				t.Errorf("%s %s without policy: got %d, want 403", permissionType, action, code)
+			}
+			if code := runAction(action, permissionType, allow); code != http.StatusOK {
+				t.Errorf("%s %s with allow policy: got %d, want 200", permissionType, action, code)
+			}
+		}
+	}
+	if code := runAction("pod:exec", models.PermissionTypeDev, nil); code != http.StatusOK {
+		t.Errorf("dev exec: got %d, want 200", code)
+	}
+	if code := runAction("node:drain", models.PermissionTypeDev, allow); code != http.StatusForbidden {
+		t.Errorf("dev drain is not widened by allow policy: got %d, want 403", code)
+	}
+}
@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+)
+
+// policyVerbActions 路由中的动作段与策略操作的映射
+var policyVerbActions = map[string]string{
+	"scale":       models.PolicyActionScale,
+	"logs":        models.PolicyActionLogs,
+	"terminal":    models.PolicyActionExec,
+	"kubectl":     models.PolicyActionExec,
+	"arthas":      models.PolicyActionExec,
+	"cordon":      models.PolicyActionCordon,
+	"uncordon":    models.PolicyActionCordon,
+	"drain":       models.PolicyActionDrain,
+	"sync":        models.PolicyActionSync,
+	"rollback":    models.PolicyActionRollback,
+	"apply":       models.PolicyActionApply,
+	"debug":       models.PolicyActionDebug,
+	"files":       models.PolicyActionExec,
+	"portforward": models.PolicyActionPortForward,
+}
+
+// PolicyEnforcement 细粒度权限策略检查
+// 需要在 ClusterAccessRequired 之后、AutoWriteCheck 之前使用
+func PolicyEnforcement(policyService *services.PolicyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		permission := GetClusterPermission(c)
+		if permission == nil {
+			c.Next()
+			return
+		}
+
+		resource, action := ResolvePolicyTarget(c.Request.Method, c.FullPath())
+		if resource == "" {
+			c.Next()
+			return
+		}
+
+		namespace := c.Param("namespace")
+		if namespace == "" {
+			namespace = c.Query("namespace")
+		}
+		if EnforcePolicy(c, policyService, c.GetUint("cluster_id"), namespace, resource, action) {
+			c.Next()
+		}
+	}
+}
+
+// EnforcePolicy 执行细粒度策略检查，也供不在集群路由下的操作（如撤销变更）直接调用
+// 允许执行时返回 true；被拒绝或无法评估时已写入错误响应并返回 false
+func EnforcePolicy(c *gin.Context, policyService *services.PolicyService, clusterID uint, namespace, resource, action string) bool {
+	decision, err := policyService.Decide(c.GetUint("user_id"), clusterID, namespace, resource, action)
+	if err != nil {
+		// 策略加载失败时拒绝执行，避免拒绝规则失效
+		logger.Error("权限策略评估失败: %v", err)
+		response.FailCode(c, errcode.PolicyCheckFailed)
+		return false
+	}
+	if decision.Denied() {
+		response.FailCode(c, errcode.PolicyDenied, decision.DecidingRule.Name)
+		return false
+	}
+	if decision != nil {
+		c.Set("policy_decision", decision)
+	}
+	return true
+}
+
+// GetPolicyDecision 从上下文获取策略评估结果
+func GetPolicyDecision(c *gin.Context) *services.PolicyDecision {
+	value, exists := c.Get("policy_decision")
+	if !exists {
+		return nil
+	}
+	decision, ok := value.(*services.PolicyDecision)
+	if !ok {
+		return nil
+	}
+	return decision
+}
+
+// ResolvePolicyTarget 根据路由模板解析资源类型与操作
+// 例如 POST /api/v1/clusters/:clusterID/deployments/:namespace/:name/scale => deployments, scale
+func ResolvePolicyTarget(method, fullPath string) (string, string) {
+	segments := strings.Split(strings.Trim(fullPath, "/"), "/")
+	start := -1
+	for i, seg := range segments {
+		if seg == ":clusterID" || seg == ":clusterId" {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return "", ""
+	}
+	segments = segments[start:]
+	if len(segments) == 0 {
+		return "clusters", methodPolicyAction(method, "clusters", true)
+	}
+
+	resource := segments[0]
+	rest := segments[1:]
+	switch resource {
+	case "terminal", "kubectl":
+		// 集群级 kubectl 终端
+		return "kubectl", models.PolicyActionExec
+	case "logs":
+		// 日志中心读取的是 Pod 日志
+		return "pods", models.PolicyActionLogs
+	case "argocd":
+		if len(rest) > 0 && rest[0] == "applications" {
+			resource = "applications"
+			rest = rest[1:]
+		}
+	}
+
+	// 从后往前查找动作段，如 scale、drain、yaml/apply
+	for i := len(rest) - 1; i >= 0; i-- {
+		seg := rest[i]
+		if strings.HasPrefix(seg, ":") {
+			continue
+		}
+		if action, ok := policyVerbActions[seg]; ok {
+			return resource, action
+		}
+		if seg == "yaml" && method != "GET" {
+			return resource, models.PolicyActionApply
+		}
+	}
+
+	hasName := false
+	for _, seg := range rest {
+		if strings.HasPrefix(seg, ":") && (seg != ":namespace" || resource == "namespaces") {
+			hasName = true
+			break
+		}
+	}
+	return resource, methodPolicyAction(method, resource, hasName)
+}
+
+// methodPolicyAction 根据 HTTP 方法推导策略操作
+func methodPolicyAction(method, resource string, hasName bool) string {
+	switch method {
+	case "POST":
+		return models.PolicyActionCreate
+	case "PUT", "PATCH":
+		return models.PolicyActionUpdate
+	case "DELETE":
+		return models.PolicyActionDelete
+	}
+	if !hasName {
+		return models.PolicyActionList
+	}
+	if resource == "secrets" {
+		return models.PolicyActionSecretRead
+	}
+	return models.PolicyActionGet
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+func TestPolicyEnforcementFailsClosedWhenPoliciesUnavailable(t *testing.T) {
+	// 测试库中没有策略表，加载策略必然失败
+	db, cluster := newFreezeTestDB(t)
+	svc := services.NewPolicyService(db)
+
+	var executed []string
+	guard := func(c *gin.Context) {
+		c.Set("cluster_permission", &models.ClusterPermission{PermissionType: models.PermissionTypeAdmin})
+		PolicyEnforcement(svc)(c)
+	}
+	r := newWriteTestRouter(cluster.ID, guard, &executed)
+	if w := postJSON(r, "/api/v1/clusters/1/nodes/node-1/drain", `{}`); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 when policies cannot be loaded, got %d", w.Code)
+	}
+	if len(executed) != 0 {
+		t.Fatalf("request must not execute, got %v", executed)
+	}
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/response"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+)
+
+// tenantClusterScopedResources 租户成员只读的集群级资源
+var tenantClusterScopedResources = map[string]bool{
+	"clusters":       true,
+	"nodes":          true,
+	"namespaces":     true,
+	"pvs":            true,
+	"storageclasses": true,
+	"rbac":           true,
+	"monitoring":     true,
+	"alertmanager":   true,
+	"alerts":         true,
+	"silences":       true,
+	"receivers":      true,
+	"om":             true,
+}
+
+// TenantScope 租户可见范围限制
+// 需要在 ClusterAccessRequired 之后、PolicyEnforcement 之前使用
+// 租户成员的集群权限被收敛到本租户命名空间内，且不能操作集群级资源
+func TenantScope(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		permission := GetClusterPermission(c)
+		if permission == nil {
+			c.Next()
+			return
+		}
+
+		scope, err := services.LoadTenantScope(db, c.GetUint("user_id"))
+		if err != nil {
+			logger.Error("加载租户范围失败: %v", err)
+			response.FailCode(c, errcode.TenantLoadFailed)
+			return
+		}
+		if scope == nil {
+			c.Next()
+			return
+		}
+		if scope.Disabled {
+			response.FailCode(c, errcode.TenantDisabled)
+			return
+		}
+
+		clusterID := c.GetUint("cluster_id")
+		scoped, ok := services.ScopePermissionToTenant(permission, scope, clusterID)
+		if !ok {
+			response.FailCode(c, errcode.TenantNoNamespace)
+			return
+		}
+
+		resource, _ := ResolvePolicyTarget(c.Request.Method, c.FullPath())
+		if scope.OwnsCluster(clusterID) {
+			// 租户独占的集群不限制集群级资源
+			resource = ""
+		}
+		if resource == "kubectl" {
+			response.FailCode(c, errcode.TenantClusterTerminal)
+			return
+		}
+		if tenantClusterScopedResources[resource] && c.Request.Method != "GET" {
+			response.FailCode(c, errcode.TenantClusterResource)
+			return
+		}
+
+		namespace := c.Param("namespace")
+		if namespace == "" {
+			namespace = c.Query("namespace")
+		}
+		if namespace != "" && namespace != "_all" && !scope.Allows(clusterID, namespace) {
+			response.FailCode(c, errcode.TenantNamespaceMismatch, namespace)
+			return
+		}
+
+		c.Set("cluster_permission", scoped)
+		c.Set("tenant_id", scope.TenantID)
+		c.Set("tenant_scope", scope)
+		c.Next()
+	}
+}
+
+// GetTenantScope 从上下文获取租户可见范围，非租户用户返回 nil
+func GetTenantScope(c *gin.Context) *services.TenantScope {
+	value, exists := c.Get("tenant_scope")
+	if !exists {
+		return nil
+	}
+	scope, ok := value.(*services.TenantScope)
+	if !ok {
+		return nil
+	}
+	return scope
+}
+
+// TenantAdminRequired 租户管理员权限检查（平台管理员同样允许）
+// 路由需包含 :id 参数表示租户ID
+func TenantAdminRequired(db *gorm.DB, tenantService *services.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetUint("user_id")
+		if userID == 0 {
+			response.FailCode(c, errcode.NotLoggedIn)
+			return
+		}
+
+		tenantID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			response.FailCode(c, errcode.InvalidTenantID)
+			return
+		}
+
+		if IsPlatformAdmin(db, userID, c.GetString("username")) || tenantService.IsTenantAdmin(userID, uint(tenantID)) {
+			c.Next()
+			return
+		}
+		response.FailCode(c, errcode.TenantAdminRequired)
+	}
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AccessRequest 状态常量
+const (
+	AccessRequestStatusPending  = "pending"  // 待审批
+	AccessRequestStatusApproved = "approved" // 已批准，临时权限生效中
+	AccessRequestStatusRejected = "rejected" // 已拒绝
+	AccessRequestStatusExpired  = "expired"  // 已到期，临时权限已回收
+	AccessRequestStatusRevoked  = "revoked"  // 被提前撤销
+	AccessRequestStatusCanceled = "canceled" // 申请人撤回
+)
+
+// AccessRequest 临时提权申请（Just-In-Time Access）
+// 用户申请在指定集群/命名空间上获得限时的 ops/admin 权限，
+// 经指定用户组成员审批后生成带过期时间的 ClusterPermission。
+type AccessRequest struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	UserID          uint           `json:"user_id" gorm:"index;not null"`               // 申请人
+	ClusterID       uint           `json:"cluster_id" gorm:"index;not null"`            // 目标集群
+	PermissionType  string         `json:"permission_type" gorm:"not null;size:50"`     // 申请的权限类型：ops, admin
+	Namespaces      string         `json:"namespaces" gorm:"type:text"`                 // 命名空间范围，JSON格式
+	Reason          string         `json:"reason" gorm:"type:text;not null"`            // 申请理由
+	DurationMinutes int            `json:"duration_minutes" gorm:"not null"`            // 申请时长（分钟）
+	Status          string         `json:"status" gorm:"size:20;index;default:pending"` // pending, approved, rejected, expired, revoked, canceled
+	ReviewerID      *uint          `json:"reviewer_id"`                                 // 审批人
+	ReviewComment   string         `json:"review_comment" gorm:"size:500"`              // 审批意见
+	ReviewedAt      *time.Time     `json:"reviewed_at"`
+	ExpiresAt       *time.Time     `json:"expires_at" gorm:"index"`    // 临时权限到期时间（批准后设置）
+	PermissionID    *uint          `json:"permission_id" gorm:"index"` // 批准后生成的 ClusterPermission
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 关联（预加载用）
+	User     *User    `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Reviewer *User    `json:"reviewer,omitempty" gorm:"foreignKey:ReviewerID"`
+	Cluster  *Cluster `json:"cluster,omitempty" gorm:"foreignKey:ClusterID"`
+}
+
+// TableName 指定表名
+func (AccessRequest) TableName() string {
+	return "access_requests"
+}
+
+// GetNamespaceList 获取命名空间列表
+func (r *AccessRequest) GetNamespaceList() []string {
+	if r.Namespaces == "" {
+		return []string{"*"}
+	}
+	var namespaces []string
+	if err := json.Unmarshal([]byte(r.Namespaces), &namespaces); err != nil {
+		return []string{}
+	}
+	return namespaces
+}
+
+// JITAccessConfig 临时提权配置（存储在 system_settings 表中）
+type JITAccessConfig struct {
+	Enabled            bool     `json:"enabled"`              // 是否启用临时提权
+	ApproverGroupID    uint     `json:"approver_group_id"`    // 审批用户组
+	MaxDurationMinutes int      `json:"max_duration_minutes"` // 单次申请最长时长
+	AllowedTypes       []string `json:"allowed_types"`        // 允许申请的权限类型
+}
+
+// GetDefaultJITAccessConfig 获取默认临时提权配置
+func GetDefaultJITAccessConfig() JITAccessConfig {
+	return JITAccessConfig{
+		Enabled:            false,
+		ApproverGroupID:    0,
+		MaxDurationMinutes: 480,
+		AllowedTypes:       []string{PermissionTypeOps, PermissionTypeAdmin},
+	}
+}
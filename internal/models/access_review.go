@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// AccessReview 决定常量
+const (
+	AccessReviewDecisionAttested = "attested" // 确认保留
+	AccessReviewDecisionRevoked  = "revoked"  // 已撤销
+)
+
+// AccessReview 授权复核记录
+// 平台管理员定期复核每个用户的有效授权：确认保留（attest）或撤销（revoke）。
+// 通过用户组继承的授权以 UserGroupID 区分，撤销时将用户移出该用户组。
+type AccessReview struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	PermissionID   uint      `json:"permission_id" gorm:"index;not null"` // 被复核的 ClusterPermission
+	UserID         uint      `json:"user_id" gorm:"index;not null"`       // 被复核的用户
+	UserGroupID    *uint     `json:"user_group_id"`                       // 授权来自用户组时记录
+	ClusterID      uint      `json:"cluster_id"`                          // 复核时的集群（选择器授权为 0）
+	PermissionType string    `json:"permission_type" gorm:"size:50"`      // 复核时的权限类型
+	Decision       string    `json:"decision" gorm:"size:20;index;not null"`
+	ReviewerID     uint      `json:"reviewer_id" gorm:"not null"`
+	Comment        string    `json:"comment" gorm:"size:500"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	// 关联（预加载用）
+	User     *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Reviewer *User `json:"reviewer,omitempty" gorm:"foreignKey:ReviewerID"`
+}
+
+// TableName 指定表名
+func (AccessReview) TableName() string {
+	return "access_reviews"
+}
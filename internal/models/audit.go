@@ -8,24 +8,28 @@ import (
 
 // TerminalSession 终端会话模型
 type TerminalSession struct {
-	ID         uint           `json:"id" gorm:"primaryKey"`
-	UserID     uint           `json:"user_id" gorm:"not null"`
-	ClusterID  uint           `json:"cluster_id" gorm:"not null"`
-	TargetType string         `json:"target_type" gorm:"not null;size:20"` // pod, node, cluster
-	TargetRef  string         `json:"target_ref" gorm:"type:json"`         // JSON格式存储目标引用信息
-	Namespace  string         `json:"namespace" gorm:"size:100"`
-	Pod        string         `json:"pod" gorm:"size:100"`
-	Container  string         `json:"container" gorm:"size:100"`
-	Node       string         `json:"node" gorm:"size:100"`
-	StartAt    time.Time      `json:"start_at"`
-	EndAt      *time.Time     `json:"end_at"`
-	InputSize  int64          `json:"input_size" gorm:"default:0"`          // 输入流大小（字节）
-	ReplayPath string         `json:"replay_path" gorm:"size:512"`          // 相对 ReplayDir，如 2026-04-17/42.cast.gz
-	ReplaySize int64          `json:"replay_size" gorm:"default:0"`         // gzip 文件字节数
-	Status     string         `json:"status" gorm:"default:active;size:20"` // active, closed, error
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	UserID       uint           `json:"user_id" gorm:"not null"`
+	ClusterID    uint           `json:"cluster_id" gorm:"not null"`
+	TargetType   string         `json:"target_type" gorm:"not null;size:20"` // pod, node, cluster
+	TargetRef    string         `json:"target_ref" gorm:"type:json"`         // JSON格式存储目标引用信息
+	Namespace    string         `json:"namespace" gorm:"size:100"`
+	Pod          string         `json:"pod" gorm:"size:100"`
+	Container    string         `json:"container" gorm:"size:100"`
+	Node         string         `json:"node" gorm:"size:100"`
+	StartAt      time.Time      `json:"start_at"`
+	EndAt        *time.Time     `json:"end_at"`
+	InputSize    int64          `json:"input_size" gorm:"default:0"`          // 输入流大小（字节）
+	ReplayPath   string         `json:"replay_path" gorm:"size:512"`          // 录像存储中的对象键，如 2026-04-17/42.cast.gz
+	ReplaySize   int64          `json:"replay_size" gorm:"default:0"`         // gzip 文件字节数
+	ReplaySHA256 string         `json:"replay_sha256" gorm:"size:64"`         // gzip 文件 SHA-256，回放时校验以发现篡改
+	Status       string         `json:"status" gorm:"default:active;size:20"` // active, closed, error, killed
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 哈希链（防篡改）
+	ChainLink
 
 	// 关联关系
 	User     User              `json:"user" gorm:"foreignKey:UserID"`
@@ -35,16 +39,49 @@ type TerminalSession struct {
 
 // TerminalCommand 终端命令记录模型
 type TerminalCommand struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	SessionID  uint      `json:"session_id" gorm:"not null;index"`
+	Timestamp  time.Time `json:"timestamp"`
+	RawInput   string    `json:"raw_input" gorm:"type:text"`  // 原始输入
+	ParsedCmd  string    `json:"parsed_cmd" gorm:"size:1024"` // 解析后的命令
+	ExitCode   *int      `json:"exit_code"`                   // 命令退出码
+	Blocked    bool      `json:"blocked" gorm:"index"`        // 是否被终端命令策略拦截
+	PolicyRule string    `json:"policy_rule" gorm:"size:100"` // 拦截命中的策略规则名称
+	CreatedAt  time.Time `json:"created_at"`
+
+	// 哈希链（防篡改）
+	ChainLink
+
+	// 关联关系
+	Session TerminalSession `json:"session" gorm:"foreignKey:SessionID"`
+}
+
+// TerminalReplayIndex 终端录像命令索引（会话结束后解析 .cast.gz 生成）
+// 每条记录对应一条输入命令及其随后的输出，Offset 为相对会话开始的秒数，用于回放跳转。
+type TerminalReplayIndex struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	SessionID uint      `json:"session_id" gorm:"not null;index"`
+	Seq       int       `json:"seq"`                             // 会话内序号，0 为首条命令前的输出（登录提示等）
+	Offset    float64   `json:"offset" gorm:"column:offset_sec"` // 相对会话开始的秒数
+	Command   string    `json:"command" gorm:"size:1024"`        // 输入重建的命令
+	Output    string    `json:"output" gorm:"type:text"`         // 命令输出（已去除控制序列，超长截断）
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TerminalSessionEvent 终端会话事件（旁观加入/离开、协同操作申请与授权、强制终止）
+type TerminalSessionEvent struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
 	SessionID uint      `json:"session_id" gorm:"not null;index"`
-	Timestamp time.Time `json:"timestamp"`
-	RawInput  string    `json:"raw_input" gorm:"type:text"`  // 原始输入
-	ParsedCmd string    `json:"parsed_cmd" gorm:"size:1024"` // 解析后的命令
-	ExitCode  *int      `json:"exit_code"`                   // 命令退出码
+	Event     string    `json:"event" gorm:"not null;size:30"` // join, leave, drive_request, drive_granted, drive_denied, drive_revoked, kill
+	ActorID   *uint     `json:"actor_id"`                      // 触发事件的用户（为空表示会话所有者或系统）
+	Detail    string    `json:"detail" gorm:"size:500"`
 	CreatedAt time.Time `json:"created_at"`
 
+	// 哈希链（防篡改）
+	ChainLink
+
 	// 关联关系
-	Session TerminalSession `json:"session" gorm:"foreignKey:SessionID"`
+	Actor *User `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
 }
 
 // AuditLog 审计日志模型
@@ -74,6 +111,16 @@ func (TerminalCommand) TableName() string {
 	return "terminal_commands"
 }
 
+// TableName 指定终端录像索引表名
+func (TerminalReplayIndex) TableName() string {
+	return "terminal_replay_index"
+}
+
+// TableName 指定终端会话事件表名
+func (TerminalSessionEvent) TableName() string {
+	return "terminal_session_events"
+}
+
 // TableName 指定审计日志表名
 func (AuditLog) TableName() string {
 	return "audit_logs"
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ChainLink 审计记录在哈希链上的位置（嵌入各审计表）
+// ChainSeq 为空表示启用哈希链之前写入的历史记录，不参与校验。
+type ChainLink struct {
+	ChainSeq  *int64 `json:"chain_seq" gorm:"uniqueIndex"`
+	ChainPrev string `json:"chain_prev" gorm:"size:64"`
+	ChainHash string `json:"chain_hash" gorm:"size:64"`
+}
+
+// GetChainLink 返回可写的链字段
+func (l *ChainLink) GetChainLink() *ChainLink {
+	return l
+}
+
+// Chained 纳入哈希链的审计记录
+type Chained interface {
+	TableName() string
+	GetChainLink() *ChainLink
+	// ChainRecordID 记录主键，仅用于在校验报告中定位记录
+	ChainRecordID() uint
+	// ChainContent 参与哈希的记录内容（不含主键与链字段）
+	ChainContent() []byte
+}
+
+// 锚点类型
+const (
+	AnchorKindHead   = ""       // 定期锚定的链头
+	AnchorKindPrune  = "prune"  // 保留策略清理了该序号及之前的记录，校验从此处继续
+	AnchorKindReseal = "reseal" // 匿名化改写记录后重新计算了哈希，之前的锚点作废
+)
+
+// AuditChainAnchor 哈希链链头锚点：定期记录各链的链头，用于发现尾部记录被截断
+type AuditChainAnchor struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Stream    string    `json:"stream" gorm:"size:50;index"`
+	Kind      string    `json:"kind" gorm:"size:20"`
+	Seq       int64     `json:"seq"`
+	Hash      string    `json:"hash" gorm:"size:64"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (AuditChainAnchor) TableName() string {
+	return "audit_chain_anchors"
+}
+
+// chainTime 时间在哈希中统一按毫秒记录，与数据库存储精度一致
+func chainTime(t time.Time) int64 {
+	return t.UnixMilli()
+}
+
+func chainJSON(v interface{}) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+// ChainRecordID 操作日志主键
+func (l *OperationLog) ChainRecordID() uint { return l.ID }
+
+// ChainRecordID 终端会话主键
+func (s *TerminalSession) ChainRecordID() uint { return s.ID }
+
+// ChainRecordID 终端命令主键
+func (c *TerminalCommand) ChainRecordID() uint { return c.ID }
+
+// ChainRecordID 终端会话事件主键
+func (e *TerminalSessionEvent) ChainRecordID() uint { return e.ID }
+
+// ChainContent 参与哈希的操作日志内容（删除集群时会清空 ClusterID，以 ClusterName 为准）。
+// 审批字段仅在有值时追加，不影响此前记录的哈希
+func (l *OperationLog) ChainContent() []byte {
+	content := []interface{}{
+		l.UserID, l.Username, l.TenantID, l.Method, l.Path, l.Query, l.Module, l.Action,
+		l.ClusterName, l.Namespace, l.ResourceType, l.ResourceName,
+		l.RequestBody, l.StatusCode, l.Success, l.ErrorMessage, l.ClientIP, l.UserAgent,
+		l.Duration, chainTime(l.CreatedAt),
+	}
+	if l.ChangeRequestID != nil {
+		content = append(content, l.ChangeRequestID, l.ApproverID, l.ApproverName)
+	}
+	return chainJSON(content)
+}
+
+// ChainContent 参与哈希的终端会话内容（仅创建时确定的字段，结束与录像信息以会话事件入链）
+func (s *TerminalSession) ChainContent() []byte {
+	return chainJSON([]interface{}{
+		s.UserID, s.ClusterID, s.TargetType, s.TargetRef, s.Namespace, s.Pod, s.Container, s.Node,
+		chainTime(s.StartAt),
+	})
+}
+
+// ChainContent 参与哈希的终端命令内容
+func (c *TerminalCommand) ChainContent() []byte {
+	return chainJSON([]interface{}{
+		c.SessionID, chainTime(c.Timestamp), c.RawInput, c.ParsedCmd, c.ExitCode, c.Blocked, c.PolicyRule,
+	})
+}
+
+// ChainContent 参与哈希的终端会话事件内容
+func (e *TerminalSessionEvent) ChainContent() []byte {
+	return chainJSON([]interface{}{
+		e.SessionID, e.Event, e.ActorID, e.Detail, chainTime(e.CreatedAt),
+	})
+}
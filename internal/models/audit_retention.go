@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// 审计清理任务状态
+const (
+	AuditRetentionRunning = "running"
+	AuditRetentionSuccess = "success"
+	AuditRetentionFailed  = "failed"
+)
+
+// AuditRetentionRun 审计记录保留策略的一次清理：过期记录归档后删除，记录进度与结果
+type AuditRetentionRun struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Stream        string     `json:"stream" gorm:"size:50;index"` // 清理的表
+	Cutoff        time.Time  `json:"cutoff"`                      // 早于该时间的记录过期
+	Status        string     `json:"status" gorm:"size:20;index"`
+	Archived      int64      `json:"archived"`  // 已归档的记录数（含关联的子记录）
+	Purged        int64      `json:"purged"`    // 已删除的主表记录数
+	ChainSeq      int64      `json:"chain_seq"` // 清理至哈希链的该序号（含）
+	ArchivePath   string     `json:"archive_path" gorm:"size:500"`
+	ArchiveSize   int64      `json:"archive_size"`
+	ArchiveSHA256 string     `json:"archive_sha256" gorm:"size:64"`
+	Error         string     `json:"error" gorm:"size:1000"`
+	Trigger       string     `json:"trigger" gorm:"size:20"` // schedule / manual
+	StartedAt     time.Time  `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at"`
+}
+
+// TableName 指定表名
+func (AuditRetentionRun) TableName() string {
+	return "audit_retention_runs"
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditSinkType 审计外送目标类型常量
+const (
+	AuditSinkSyslog  = "syslog"  // RFC5424 syslog（TCP / TLS）
+	AuditSinkWebhook = "webhook" // HTTP Webhook（批量、HMAC 签名）
+	AuditSinkFile    = "file"    // JSON Lines 文件（按大小轮转）
+)
+
+// AuditSinkSuccess 审计外送成功/失败过滤常量
+const (
+	AuditSinkSuccessAll     = ""        // 全部
+	AuditSinkSuccessOnly    = "success" // 仅成功
+	AuditSinkSuccessFailure = "failure" // 仅失败
+)
+
+// AuditSink 审计外送目标（SIEM 等）
+// 操作日志与终端命令写入后按过滤条件投递到各目标的发件箱，由后台任务批量发送。
+type AuditSink struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	Name          string         `json:"name" gorm:"uniqueIndex;not null;size:100"`
+	Type          string         `json:"type" gorm:"not null;size:20"`  // syslog, webhook, file
+	Config        string         `json:"config" gorm:"type:text"`       // 目标配置，JSON 格式，字段见 auditexport.Config
+	Secret        string         `json:"-" gorm:"type:text"`            // Webhook 签名密钥，不对外暴露
+	Modules       string         `json:"modules" gorm:"type:text"`      // 模块过滤，JSON 格式 ["workload","terminal"]，空表示全部
+	Actions       string         `json:"actions" gorm:"type:text"`      // 操作过滤，JSON 格式 ["delete","command"]，空表示全部
+	SuccessFilter string         `json:"success_filter" gorm:"size:10"` // 空表示全部，success / failure
+	Enabled       bool           `json:"enabled"`
+	LastSentAt    *time.Time     `json:"last_sent_at"`
+	LastError     string         `json:"last_error" gorm:"size:500"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 非持久化字段
+	HasSecret bool  `json:"has_secret" gorm:"-"`
+	Pending   int64 `json:"pending" gorm:"-"` // 发件箱中待发送的事件数
+}
+
+// TableName 指定表名
+func (AuditSink) TableName() string {
+	return "audit_sinks"
+}
+
+// GetModuleList 获取模块过滤
+func (s *AuditSink) GetModuleList() []string {
+	return decodeStringList(s.Modules)
+}
+
+// GetActionList 获取操作过滤
+func (s *AuditSink) GetActionList() []string {
+	return decodeStringList(s.Actions)
+}
+
+// AuditOutbox 审计外送发件箱：每个目标一行，发送成功后删除，进程重启或目标不可用时保留重试
+type AuditOutbox struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	SinkID        uint      `json:"sink_id" gorm:"not null;index:idx_audit_outbox_sink_next,priority:1"`
+	Payload       string    `json:"payload" gorm:"type:text;not null"` // 事件 JSON（auditexport.Event）
+	Attempts      int       `json:"attempts" gorm:"default:0"`
+	NextAttemptAt time.Time `json:"next_attempt_at" gorm:"index:idx_audit_outbox_sink_next,priority:2"`
+	LastError     string    `json:"last_error" gorm:"size:500"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (AuditOutbox) TableName() string {
+	return "audit_outbox"
+}
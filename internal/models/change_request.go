@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ChangeRequest 状态常量
+const (
+	ChangeRequestStatusPending  = "pending"  // 待审批
+	ChangeRequestStatusApproved = "approved" // 已批准，服务端执行中
+	ChangeRequestStatusExecuted = "executed" // 已执行成功
+	ChangeRequestStatusFailed   = "failed"   // 执行失败
+	ChangeRequestStatusRejected = "rejected" // 已拒绝
+	ChangeRequestStatusCanceled = "canceled" // 申请人撤回
+	ChangeRequestStatusExpired  = "expired"  // 超时未审批
+)
+
+// ChangeApprovalPolicy 变更双人审批策略
+// 命中（集群标签、资源类型、操作）的写请求不会立即执行，而是保存为待审批变更，
+// 由审批用户组中申请人以外的成员批准后由服务端执行。
+type ChangeApprovalPolicy struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	Name            string         `json:"name" gorm:"uniqueIndex;not null;size:100"`
+	Description     string         `json:"description" gorm:"size:255"`
+	ClusterSelector string         `json:"cluster_selector" gorm:"type:text"` // 集群标签选择器，JSON 格式 {"env":"prod"}，空表示全部集群
+	Resources       string         `json:"resources" gorm:"type:text"`        // 资源类型，JSON 格式 ["namespaces","pvs"]，空表示全部
+	Actions         string         `json:"actions" gorm:"type:text;not null"` // 操作，JSON 格式 ["delete","drain","apply"]
+	ApproverGroupID uint           `json:"approver_group_id" gorm:"index;not null"`
+	ExpireHours     int            `json:"expire_hours" gorm:"default:24"` // 待审批变更的有效期
+	Enabled         bool           `json:"enabled"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 关联（预加载用）
+	ApproverGroup *UserGroup `json:"approver_group,omitempty" gorm:"foreignKey:ApproverGroupID"`
+}
+
+// TableName 指定表名
+func (ChangeApprovalPolicy) TableName() string {
+	return "change_approval_policies"
+}
+
+// GetClusterSelector 获取集群标签选择器
+func (p *ChangeApprovalPolicy) GetClusterSelector() map[string]string {
+	return DecodeLabelSelector(p.ClusterSelector)
+}
+
+// GetResourceList 获取资源类型列表
+func (p *ChangeApprovalPolicy) GetResourceList() []string {
+	return decodeStringList(p.Resources)
+}
+
+// GetActionList 获取操作列表
+func (p *ChangeApprovalPolicy) GetActionList() []string {
+	return decodeStringList(p.Actions)
+}
+
+// ChangeRequest 待审批变更：保存原始写请求，批准后由服务端以申请人身份重放执行
+type ChangeRequest struct {
+	ID              uint   `json:"id" gorm:"primaryKey"`
+	PolicyID        uint   `json:"policy_id" gorm:"index"`
+	PolicyName      string `json:"policy_name" gorm:"size:100"`
+	ApproverGroupID uint   `json:"approver_group_id" gorm:"index"`
+
+	// 申请人
+	RequesterID   uint   `json:"requester_id" gorm:"index;not null"`
+	RequesterName string `json:"requester_name" gorm:"size:100"`
+	Reason        string `json:"reason" gorm:"size:500"`
+
+	// 变更目标
+	ClusterID uint   `json:"cluster_id" gorm:"index"`
+	Namespace string `json:"namespace" gorm:"size:100"`
+	Resource  string `json:"resource" gorm:"size:50"`
+	Action    string `json:"action" gorm:"size:30"`
+
+	// 原始请求
+	Method               string `json:"method" gorm:"size:10"`
+	Path                 string `json:"path" gorm:"size:500"`
+	Query                string `json:"query" gorm:"size:1000"`
+	ContentType          string `json:"content_type" gorm:"size:100"`
+	Body                 string `json:"body" gorm:"type:mediumtext"`
+	FreezeOverrideReason string `json:"freeze_override_reason" gorm:"size:500"` // 提交时携带的冻结期紧急放行理由，执行时一并带上
+
+	// 审批
+	Status        string     `json:"status" gorm:"size:20;index;default:pending"`
+	ApproverID    *uint      `json:"approver_id"`
+	ApproverName  string     `json:"approver_name" gorm:"size:100"`
+	ReviewComment string     `json:"review_comment" gorm:"size:500"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	ExpiresAt     time.Time  `json:"expires_at" gorm:"index"`
+
+	// 执行结果
+	ExecutedAt   *time.Time `json:"executed_at"`
+	ResultStatus int        `json:"result_status"`
+	ResultBody   string     `json:"result_body" gorm:"type:text"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// 关联（预加载用）
+	Cluster *Cluster `json:"cluster,omitempty" gorm:"foreignKey:ClusterID"`
+}
+
+// TableName 指定表名
+func (ChangeRequest) TableName() string {
+	return "change_requests"
+}
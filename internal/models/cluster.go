@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
@@ -30,11 +31,37 @@ type Cluster struct {
 	// Alertmanager 配置
 	AlertManagerConfig string `json:"alertmanager_config" gorm:"type:json"` // JSON 格式存储 Alertmanager 配置
 
+	// 节点终端方式：ssh（默认，使用全局 SSH 凭据）或 pod（在节点上创建特权 Pod 并 nsenter 进入宿主机）
+	NodeTerminalMode string `json:"node_terminal_mode" gorm:"size:20;default:ssh"`
+
 	// 关联关系
 	Creator         User              `json:"creator" gorm:"foreignKey:CreatedBy"`
 	TerminalSession []TerminalSession `json:"terminal_sessions" gorm:"foreignKey:ClusterID"`
 }
 
+// 节点终端方式
+const (
+	NodeTerminalModeSSH = "ssh"
+	NodeTerminalModePod = "pod"
+)
+
+// UsesPodNodeTerminal 集群是否使用特权 Pod 方式的节点终端
+func (c *Cluster) UsesPodNodeTerminal() bool {
+	return c.NodeTerminalMode == NodeTerminalModePod
+}
+
+// GetLabels 解析集群标签，解析失败或为空时返回空 map
+func (c *Cluster) GetLabels() map[string]string {
+	labels := make(map[string]string)
+	if c.Labels == "" {
+		return labels
+	}
+	if err := json.Unmarshal([]byte(c.Labels), &labels); err != nil {
+		return make(map[string]string)
+	}
+	return labels
+}
+
 // ClusterStats 集群统计信息
 type ClusterStats struct {
 	TotalClusters     int `json:"total_clusters"`
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+)
+
+// ClusterGroup 集群分组
+// 通过标签选择器动态圈定集群（如 env=prod,region=eu），新导入的集群只要标签匹配即自动归入分组
+type ClusterGroup struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"uniqueIndex;not null;size:100"`
+	Description string         `json:"description" gorm:"size:255"`
+	Selector    string         `json:"selector" gorm:"type:text;not null"` // 标签选择器，JSON 格式 {"env":"prod"}
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName 指定表名
+func (ClusterGroup) TableName() string {
+	return "cluster_groups"
+}
+
+// GetSelector 获取标签选择器
+func (g *ClusterGroup) GetSelector() map[string]string {
+	return DecodeLabelSelector(g.Selector)
+}
+
+// DecodeLabelSelector 解析 JSON 格式的标签选择器，为空或解析失败时返回空 map
+func DecodeLabelSelector(value string) map[string]string {
+	selector := make(map[string]string)
+	if value == "" {
+		return selector
+	}
+	if err := json.Unmarshal([]byte(value), &selector); err != nil {
+		return make(map[string]string)
+	}
+	return selector
+}
+
+// MatchLabels 判断标签是否满足选择器（需匹配全部键值，空选择器匹配所有）
+func MatchLabels(selector, labels map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseLabelSelector 解析 "env=prod,region=eu" 格式的标签选择器
+func ParseLabelSelector(value string) (map[string]string, error) {
+	selector := make(map[string]string)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		key := strings.TrimSpace(kv[0])
+		if len(kv) != 2 || key == "" {
+			return nil, errcode.New(errcode.InvalidLabelSelector, part)
+		}
+		selector[key] = strings.TrimSpace(kv[1])
+	}
+	return selector, nil
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FreezeCalendar 变更冻结日历
+// 按（集群标签、命名空间）划定范围，日历内的冻结窗口期间禁止写操作（节假日、大促等）。
+type FreezeCalendar struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	Name            string         `json:"name" gorm:"uniqueIndex;not null;size:100"`
+	Description     string         `json:"description" gorm:"size:255"`
+	ClusterSelector string         `json:"cluster_selector" gorm:"type:text"` // 集群标签选择器，JSON 格式 {"env":"prod"}，空表示全部集群
+	Namespaces      string         `json:"namespaces" gorm:"type:text"`       // 命名空间匹配模式，JSON 格式 ["prod-*"]，空表示全部
+	AllowBreakGlass bool           `json:"allow_break_glass"`                 // 是否允许填写理由后紧急放行
+	NotifyWebhook   string         `json:"notify_webhook" gorm:"size:500"`    // 紧急放行时的通知地址（POST JSON）
+	Enabled         bool           `json:"enabled"`
+	CreatedBy       string         `json:"created_by" gorm:"size:100"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+
+	Windows []FreezeWindow `json:"windows,omitempty" gorm:"foreignKey:CalendarID"`
+}
+
+// TableName 指定表名
+func (FreezeCalendar) TableName() string {
+	return "freeze_calendars"
+}
+
+// GetClusterSelector 获取集群标签选择器
+func (f *FreezeCalendar) GetClusterSelector() map[string]string {
+	return DecodeLabelSelector(f.ClusterSelector)
+}
+
+// GetNamespaceList 获取命名空间匹配模式
+func (f *FreezeCalendar) GetNamespaceList() []string {
+	return decodeStringList(f.Namespaces)
+}
+
+// FreezeWindow 冻结窗口（[StartAt, EndAt) 期间生效）
+type FreezeWindow struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CalendarID uint      `json:"calendar_id" gorm:"index;not null"`
+	Name       string    `json:"name" gorm:"size:100;not null"`
+	Reason     string    `json:"reason" gorm:"size:500"`
+	StartAt    time.Time `json:"start_at" gorm:"index"`
+	EndAt      time.Time `json:"end_at" gorm:"index"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (FreezeWindow) TableName() string {
+	return "freeze_windows"
+}
+
+// Active 窗口在指定时间是否生效
+func (w *FreezeWindow) Active(at time.Time) bool {
+	return !at.Before(w.StartAt) && at.Before(w.EndAt)
+}
+
+// FreezeOverride 冻结期间的紧急放行记录
+type FreezeOverride struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CalendarID uint      `json:"calendar_id" gorm:"index"`
+	WindowID   uint      `json:"window_id" gorm:"index"`
+	UserID     uint      `json:"user_id" gorm:"index"`
+	Username   string    `json:"username" gorm:"size:100"`
+	ClusterID  uint      `json:"cluster_id" gorm:"index"`
+	Namespace  string    `json:"namespace" gorm:"size:100"`
+	Method     string    `json:"method" gorm:"size:10"`
+	Path       string    `json:"path" gorm:"size:500"`
+	Reason     string    `json:"reason" gorm:"size:500;not null"`
+	ClientIP   string    `json:"client_ip" gorm:"size:45"`
+	Notified   bool      `json:"notified"` // 通知是否发送成功
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+
+	// 关联（预加载用）
+	Window *FreezeWindow `json:"window,omitempty" gorm:"foreignKey:WindowID"`
+}
+
+// TableName 指定表名
+func (FreezeOverride) TableName() string {
+	return "freeze_overrides"
+}
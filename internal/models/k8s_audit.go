@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// K8s 审计事件来源
+const (
+	K8sAuditSourcePlatform = "platform" // 平台自身的集群凭据（经 KubePolaris API 发起）
+	K8sAuditSourceTerminal = "terminal" // 平台终端使用的 ServiceAccount（kubectl 终端等）
+	K8sAuditSourceDirect   = "direct"   // 其他身份（直接使用 kubectl、CI 等）
+)
+
+// K8sAuditEvent Kubernetes API Server 审计事件（仅保存变更类请求的最终阶段）
+type K8sAuditEvent struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	ClusterID uint   `json:"cluster_id" gorm:"uniqueIndex:idx_k8s_audit_event;index:idx_k8s_audit_object"`
+	AuditID   string `json:"audit_id" gorm:"size:64;uniqueIndex:idx_k8s_audit_event"`
+	Level     string `json:"level" gorm:"size:30"`
+	Stage     string `json:"stage" gorm:"size:30"`
+	Verb      string `json:"verb" gorm:"size:30;index"`
+
+	RequestURI string `json:"request_uri" gorm:"size:1000"`
+	UserAgent  string `json:"user_agent" gorm:"size:500"`
+	SourceIPs  string `json:"source_ips" gorm:"size:200"` // 逗号分隔
+
+	// 身份：Username 为认证身份，ImpersonatedUser 为被模拟的身份，Actor 为实际生效的身份
+	Username         string `json:"username" gorm:"size:255"`
+	Groups           string `json:"groups" gorm:"size:1000"` // 逗号分隔
+	ImpersonatedUser string `json:"impersonated_user" gorm:"size:255"`
+	Actor            string `json:"actor" gorm:"size:255;index"`
+
+	// 对象
+	APIGroup    string `json:"api_group" gorm:"size:100"`
+	APIVersion  string `json:"api_version" gorm:"size:50"`
+	Resource    string `json:"resource" gorm:"size:100;index:idx_k8s_audit_object"`
+	Subresource string `json:"subresource" gorm:"size:100"`
+	Namespace   string `json:"namespace" gorm:"size:100;index:idx_k8s_audit_object"`
+	Name        string `json:"name" gorm:"size:253;index:idx_k8s_audit_object"`
+
+	ResponseCode    int    `json:"response_code"`
+	ResponseMessage string `json:"response_message" gorm:"size:1000"`
+
+	RequestReceivedAt time.Time `json:"request_received_at" gorm:"index"`
+	StageAt           time.Time `json:"stage_at"`
+
+	// 关联：Source 为来源分类，UserID 为对应的平台用户，OperationLogID 为对应的平台操作日志
+	Source         string `json:"source" gorm:"size:20;index"`
+	UserID         *uint  `json:"user_id" gorm:"index"`
+	OperationLogID *uint  `json:"operation_log_id" gorm:"index"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (K8sAuditEvent) TableName() string {
+	return "k8s_audit_events"
+}
+
+// K8sAuditWebhook 集群 API Server 审计 Webhook 接收配置
+type K8sAuditWebhook struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	ClusterID uint   `json:"cluster_id" gorm:"uniqueIndex"`
+	Enabled   bool   `json:"enabled"`
+	TokenHash string `json:"-" gorm:"size:64"` // 接收令牌的 SHA-256，令牌仅在生成时返回一次
+	// PlatformUsername 平台访问该集群使用的身份（审计事件中的 user.username），用于关联平台操作日志
+	PlatformUsername string     `json:"platform_username" gorm:"size:255"`
+	LastEventAt      *time.Time `json:"last_event_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (K8sAuditWebhook) TableName() string {
+	return "k8s_audit_webhooks"
+}
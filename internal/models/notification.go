@@ -0,0 +1,124 @@
+package models
+
+import "time"
+
+// NotificationSettingKey 通知配置在 system_settings 表中的键
+const NotificationSettingKey = "notification_config"
+
+// NotifyChannelType 通知渠道类型常量
+const (
+	NotifyChannelWebhook  = "webhook"  // 通用 Webhook（请求体可使用模板）
+	NotifyChannelSlack    = "slack"    // Slack 兼容的 Incoming Webhook
+	NotifyChannelDingTalk = "dingtalk" // 钉钉自定义机器人
+	NotifyChannelWeCom    = "wecom"    // 企业微信群机器人
+	NotifyChannelFeishu   = "feishu"   // 飞书自定义机器人
+	NotifyChannelEmail    = "email"    // SMTP 邮件
+)
+
+// NotifyEvent 可订阅的平台事件常量
+const (
+	NotifyEventClusterUnreachable = "cluster_unreachable"  // 集群不可达
+	NotifyEventHealthScoreDropped = "health_score_dropped" // 集群健康评分下降
+	NotifyEventOperationFailed    = "operation_failed"     // 平台操作失败
+	NotifyEventNodeDrained        = "node_drained"         // 节点被驱逐
+	NotifyEventTerminalOpened     = "terminal_opened"      // 打开终端会话
+	NotifyEventArgoCDSyncFailed   = "argocd_sync_failed"   // ArgoCD 应用同步失败
+)
+
+// NotifyEventNames 事件中文名称
+var NotifyEventNames = map[string]string{
+	NotifyEventClusterUnreachable: "集群不可达",
+	NotifyEventHealthScoreDropped: "健康评分下降",
+	NotifyEventOperationFailed:    "操作失败",
+	NotifyEventNodeDrained:        "节点驱逐",
+	NotifyEventTerminalOpened:     "打开终端",
+	NotifyEventArgoCDSyncFailed:   "ArgoCD 同步失败",
+}
+
+// NotificationConfig 通知配置（存储在 system_settings 表中）
+type NotificationConfig struct {
+	Channels      []NotificationChannel      `json:"channels"`
+	Subscriptions []NotificationSubscription `json:"subscriptions"`
+	// HealthScoreDropThreshold 健康评分较上次诊断下降达到该分值时触发 health_score_dropped
+	HealthScoreDropThreshold int `json:"health_score_drop_threshold"`
+}
+
+// GetDefaultNotificationConfig 获取默认通知配置
+func GetDefaultNotificationConfig() NotificationConfig {
+	return NotificationConfig{
+		Channels:                 []NotificationChannel{},
+		Subscriptions:            []NotificationSubscription{},
+		HealthScoreDropThreshold: 10,
+	}
+}
+
+// NotificationChannel 通知渠道，以名称唯一标识
+type NotificationChannel struct {
+	Name    string                    `json:"name"`
+	Type    string                    `json:"type"`
+	Enabled bool                      `json:"enabled"`
+	Config  NotificationChannelConfig `json:"config"`
+}
+
+// NotificationChannelConfig 渠道配置（按类型使用其中的字段）
+type NotificationChannelConfig struct {
+	// webhook / slack / dingtalk / wecom / feishu
+	URL string `json:"url,omitempty"`
+	// Secret 钉钉、飞书的加签密钥；通用 Webhook 的 HMAC 签名密钥
+	Secret string `json:"secret,omitempty"`
+
+	// webhook
+	Method       string            `json:"method,omitempty"` // 默认 POST
+	Headers      map[string]string `json:"headers,omitempty"`
+	BodyTemplate string            `json:"body_template,omitempty"` // Go text/template，为空时发送事件 JSON
+
+	// email
+	SMTPHost string   `json:"smtp_host,omitempty"`
+	SMTPPort int      `json:"smtp_port,omitempty"` // 465 使用 TLS 直连，其他端口在服务端支持时使用 STARTTLS
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       []string `json:"to,omitempty"`
+
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+// NotificationSubscription 事件订阅：匹配的事件发送到指定渠道
+type NotificationSubscription struct {
+	Name            string            `json:"name"`
+	Events          []string          `json:"events"`
+	ClusterSelector map[string]string `json:"cluster_selector,omitempty"` // 按集群标签过滤，如 {"env":"prod"}
+	Channels        []string          `json:"channels"`                   // 渠道名称
+	Enabled         bool              `json:"enabled"`
+}
+
+// NotificationDeliveryStatus 投递状态常量
+const (
+	NotificationDeliveryPending = "pending" // 待发送或等待重试
+	NotificationDeliverySent    = "sent"    // 已发送
+	NotificationDeliveryFailed  = "failed"  // 重试次数用尽
+)
+
+// NotificationDelivery 通知投递记录：既是待发送队列，也是投递日志
+type NotificationDelivery struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	EventType     string     `json:"event_type" gorm:"size:50;index"`
+	Subscription  string     `json:"subscription" gorm:"size:100"`
+	Channel       string     `json:"channel" gorm:"size:100;index"`
+	ChannelType   string     `json:"channel_type" gorm:"size:20"`
+	ClusterID     *uint      `json:"cluster_id"`
+	Title         string     `json:"title" gorm:"size:255"`
+	Payload       string     `json:"payload" gorm:"type:text"` // 事件 JSON（notify.Event）
+	Status        string     `json:"status" gorm:"size:20;index:idx_notification_delivery_status_next,priority:1"`
+	Attempts      int        `json:"attempts" gorm:"default:0"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index:idx_notification_delivery_status_next,priority:2"`
+	LastError     string     `json:"last_error" gorm:"size:500"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"index"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (NotificationDelivery) TableName() string {
+	return "notification_deliveries"
+}
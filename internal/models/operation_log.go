@@ -10,6 +10,7 @@ type OperationLog struct {
 	// 操作者信息
 	UserID   *uint  `json:"user_id" gorm:"index"`           // 可为空（如登录失败场景）
 	Username string `json:"username" gorm:"size:100;index"` // 冗余存储，便于查询
+	TenantID *uint  `json:"tenant_id" gorm:"index"`         // 操作者所属租户（非租户用户为空）
 
 	// 请求信息
 	Method string `json:"method" gorm:"size:10;index"` // POST/PUT/DELETE/PATCH
@@ -39,9 +40,20 @@ type OperationLog struct {
 	ClientIP  string `json:"client_ip" gorm:"size:45"`
 	UserAgent string `json:"user_agent" gorm:"size:500"`
 
+	// 双人审批：服务端执行已批准的变更时记录审批人（操作者为申请人）
+	ChangeRequestID *uint  `json:"change_request_id,omitempty" gorm:"index"`
+	ApproverID      *uint  `json:"approver_id,omitempty"`
+	ApproverName    string `json:"approver_name,omitempty" gorm:"size:100"`
+
 	// 其他
 	Duration  int64     `json:"duration"` // 请求耗时(ms)
 	CreatedAt time.Time `json:"created_at" gorm:"index"`
+
+	// 哈希链（防篡改）
+	ChainLink
+
+	// 关联的对象变更快照（详情接口预加载）
+	Snapshots []OperationLogSnapshot `json:"snapshots,omitempty" gorm:"foreignKey:OperationLogID"`
 }
 
 // TableName 指定表名
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// OperationLogSnapshot 操作日志关联的 Kubernetes 对象变更快照
+// Before 为空表示该操作创建了对象，After 为空表示该操作删除了对象；Secret 的数据已脱敏，不可回滚。
+type OperationLogSnapshot struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	OperationLogID uint       `json:"operation_log_id" gorm:"not null;index"`
+	ClusterID      uint       `json:"cluster_id" gorm:"index"`
+	Group          string     `json:"group" gorm:"column:api_group;size:100"`
+	Version        string     `json:"version" gorm:"size:50"`
+	Resource       string     `json:"resource" gorm:"size:100"` // 资源复数名，如 deployments
+	Kind           string     `json:"kind" gorm:"size:100"`
+	Namespace      string     `json:"namespace" gorm:"size:100"`
+	Name           string     `json:"name" gorm:"size:253"`
+	Before         string     `json:"before,omitempty" gorm:"type:mediumtext"` // 变更前对象 JSON（已去除状态与托管字段）
+	After          string     `json:"after,omitempty" gorm:"type:mediumtext"`  // 变更后对象 JSON
+	Diff           string     `json:"diff" gorm:"type:mediumtext"`             // 字段级差异，JSON 格式 [{"op","path","old","new"}]
+	Redacted       bool       `json:"redacted"`                                // 是否包含脱敏数据（不可回滚）
+	RevertedAt     *time.Time `json:"reverted_at"`
+	RevertedBy     *uint      `json:"reverted_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// TableName 指定表名
+func (OperationLogSnapshot) TableName() string {
+	return "operation_log_snapshots"
+}
+
+// Revertible 是否可以回滚到变更前状态
+func (s *OperationLogSnapshot) Revertible() bool {
+	return !s.Redacted && s.RevertedAt == nil && (s.Before != "" || s.After != "")
+}
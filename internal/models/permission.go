@@ -42,16 +42,19 @@ func (UserGroupMember) TableName() string {
 
 // ClusterPermission 集群级别权限配置
 type ClusterPermission struct {
-	ID             uint           `json:"id" gorm:"primaryKey"`
-	ClusterID      uint           `json:"cluster_id" gorm:"index;not null"`        // 关联集群
-	UserID         *uint          `json:"user_id" gorm:"index"`                    // 用户ID（与用户组二选一）
-	UserGroupID    *uint          `json:"user_group_id" gorm:"index"`              // 用户组ID
-	PermissionType string         `json:"permission_type" gorm:"not null;size:50"` // admin, ops, dev, readonly, custom
-	Namespaces     string         `json:"namespaces" gorm:"type:text"`             // 命名空间范围，JSON格式，["*"] 表示全部
-	CustomRoleRef  string         `json:"custom_role_ref" gorm:"size:200"`         // 自定义权限时引用的 ClusterRole/Role 名称
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	ClusterID       uint           `json:"cluster_id" gorm:"index;not null"`        // 关联集群（按标签选择器授权时为 0）
+	ClusterSelector string         `json:"cluster_selector" gorm:"type:text"`       // 集群标签选择器，JSON 格式 {"env":"prod"}，匹配的集群（含新导入集群）自动继承权限
+	UserID          *uint          `json:"user_id" gorm:"index"`                    // 用户ID（与用户组二选一）
+	UserGroupID     *uint          `json:"user_group_id" gorm:"index"`              // 用户组ID
+	PermissionType  string         `json:"permission_type" gorm:"not null;size:50"` // admin, ops, dev, readonly, custom
+	Namespaces      string         `json:"namespaces" gorm:"type:text"`             // 命名空间范围，JSON格式，["*"] 表示全部
+	CustomRoleRef   string         `json:"custom_role_ref" gorm:"size:200"`         // 自定义权限时引用的 ClusterRole/Role 名称
+	ExpiresAt       *time.Time     `json:"expires_at" gorm:"index"`                 // 过期时间，为空表示永久权限（临时提权时设置）
+	TenantID        *uint          `json:"tenant_id" gorm:"index"`                  // 由租户管理员授予时记录所属租户
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// 关联（预加载用）
 	Cluster   *Cluster   `json:"cluster,omitempty" gorm:"foreignKey:ClusterID"`
@@ -71,6 +74,29 @@ func (cp *ClusterPermission) GetNamespaceList() []string {
 	return namespaces
 }
 
+// GetClusterSelector 获取集群标签选择器
+func (cp *ClusterPermission) GetClusterSelector() map[string]string {
+	return DecodeLabelSelector(cp.ClusterSelector)
+}
+
+// IsSelectorGrant 是否为按集群标签选择器授权
+func (cp *ClusterPermission) IsSelectorGrant() bool {
+	return cp.ClusterID == 0 && cp.ClusterSelector != ""
+}
+
+// AppliesToCluster 判断权限是否作用于指定集群
+func (cp *ClusterPermission) AppliesToCluster(clusterID uint, labels map[string]string) bool {
+	if cp.IsSelectorGrant() {
+		return MatchLabels(cp.GetClusterSelector(), labels)
+	}
+	return cp.ClusterID == clusterID
+}
+
+// IsTemporary 是否为临时权限（由临时提权审批生成）
+func (cp *ClusterPermission) IsTemporary() bool {
+	return cp.ExpiresAt != nil
+}
+
 // SetNamespaceList 设置命名空间列表
 func (cp *ClusterPermission) SetNamespaceList(namespaces []string) error {
 	data, err := json.Marshal(namespaces)
@@ -207,19 +233,21 @@ func GetServiceAccountByPermissionType(permissionType string) string {
 
 // ClusterPermissionResponse 集群权限响应结构
 type ClusterPermissionResponse struct {
-	ID             uint      `json:"id"`
-	ClusterID      uint      `json:"cluster_id"`
-	ClusterName    string    `json:"cluster_name,omitempty"`
-	UserID         *uint     `json:"user_id,omitempty"`
-	Username       string    `json:"username,omitempty"`
-	UserGroupID    *uint     `json:"user_group_id,omitempty"`
-	UserGroupName  string    `json:"user_group_name,omitempty"`
-	PermissionType string    `json:"permission_type"`
-	PermissionName string    `json:"permission_name"`
-	Namespaces     []string  `json:"namespaces"`
-	CustomRoleRef  string    `json:"custom_role_ref,omitempty"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID              uint              `json:"id"`
+	ClusterID       uint              `json:"cluster_id"`
+	ClusterName     string            `json:"cluster_name,omitempty"`
+	ClusterSelector map[string]string `json:"cluster_selector,omitempty"`
+	UserID          *uint             `json:"user_id,omitempty"`
+	Username        string            `json:"username,omitempty"`
+	UserGroupID     *uint             `json:"user_group_id,omitempty"`
+	UserGroupName   string            `json:"user_group_name,omitempty"`
+	PermissionType  string            `json:"permission_type"`
+	PermissionName  string            `json:"permission_name"`
+	Namespaces      []string          `json:"namespaces"`
+	CustomRoleRef   string            `json:"custom_role_ref,omitempty"`
+	ExpiresAt       *time.Time        `json:"expires_at,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
 }
 
 // ToResponse 转换为响应结构
@@ -232,6 +260,7 @@ func (cp *ClusterPermission) ToResponse() ClusterPermissionResponse {
 		PermissionType: cp.PermissionType,
 		Namespaces:     cp.GetNamespaceList(),
 		CustomRoleRef:  cp.CustomRoleRef,
+		ExpiresAt:      cp.ExpiresAt,
 		CreatedAt:      cp.CreatedAt,
 		UpdatedAt:      cp.UpdatedAt,
 	}
@@ -244,6 +273,10 @@ func (cp *ClusterPermission) ToResponse() ClusterPermissionResponse {
 		}
 	}
 
+	if cp.IsSelectorGrant() {
+		resp.ClusterSelector = cp.GetClusterSelector()
+	}
+
 	// 填充关联信息
 	if cp.Cluster != nil {
 		resp.ClusterName = cp.Cluster.Name
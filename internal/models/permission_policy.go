@@ -0,0 +1,96 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PolicyEffect 策略效果常量
+const (
+	PolicyEffectAllow = "allow" // 允许
+	PolicyEffectDeny  = "deny"  // 拒绝（优先于允许）
+)
+
+// PolicyAction 细粒度操作常量
+const (
+	PolicyActionGet         = "get"
+	PolicyActionList        = "list"
+	PolicyActionCreate      = "create"
+	PolicyActionUpdate      = "update"
+	PolicyActionDelete      = "delete"
+	PolicyActionApply       = "apply"
+	PolicyActionScale       = "scale"
+	PolicyActionExec        = "exec"
+	PolicyActionLogs        = "logs"
+	PolicyActionSecretRead  = "secret-read"
+	PolicyActionCordon      = "cordon"
+	PolicyActionDrain       = "drain"
+	PolicyActionSync        = "sync"
+	PolicyActionRollback    = "rollback"
+	PolicyActionDebug       = "debug"
+	PolicyActionPortForward = "portforward"
+)
+
+// PermissionPolicy 细粒度权限策略
+// 在五种固定权限类型之上，按（集群标签、命名空间、资源类型、操作）定义允许/拒绝规则。
+// 拒绝规则优先于允许规则；未命中任何规则时回退到 ClusterPermission 的权限类型判断。
+type PermissionPolicy struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	Name            string         `json:"name" gorm:"uniqueIndex;not null;size:100"`
+	Description     string         `json:"description" gorm:"size:255"`
+	Effect          string         `json:"effect" gorm:"not null;size:10"`    // allow, deny
+	Priority        int            `json:"priority" gorm:"default:0"`         // 同效果规则的展示/匹配顺序，数值越大越优先
+	UserID          *uint          `json:"user_id" gorm:"index"`              // 适用用户（与用户组均为空表示所有用户）
+	UserGroupID     *uint          `json:"user_group_id" gorm:"index"`        // 适用用户组
+	ClusterSelector string         `json:"cluster_selector" gorm:"type:text"` // 集群标签选择器，JSON 格式 {"env":"prod"}，空表示全部集群
+	Namespaces      string         `json:"namespaces" gorm:"type:text"`       // 命名空间匹配模式，JSON 格式 ["prod-*"]，空表示全部
+	Resources       string         `json:"resources" gorm:"type:text"`        // 资源类型，JSON 格式 ["pods","secrets"]，空表示全部
+	Actions         string         `json:"actions" gorm:"type:text;not null"` // 操作，JSON 格式 ["exec","delete"]
+	Enabled         bool           `json:"enabled"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 关联（预加载用）
+	User      *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	UserGroup *UserGroup `json:"user_group,omitempty" gorm:"foreignKey:UserGroupID"`
+}
+
+// TableName 指定表名
+func (PermissionPolicy) TableName() string {
+	return "permission_policies"
+}
+
+// GetClusterSelector 获取集群标签选择器
+func (p *PermissionPolicy) GetClusterSelector() map[string]string {
+	return DecodeLabelSelector(p.ClusterSelector)
+}
+
+// GetNamespaceList 获取命名空间匹配模式
+func (p *PermissionPolicy) GetNamespaceList() []string {
+	return decodeStringList(p.Namespaces)
+}
+
+// GetResourceList 获取资源类型列表
+func (p *PermissionPolicy) GetResourceList() []string {
+	return decodeStringList(p.Resources)
+}
+
+// GetActionList 获取操作列表
+func (p *PermissionPolicy) GetActionList() []string {
+	return decodeStringList(p.Actions)
+}
+
+// decodeStringList 解析 JSON 字符串数组，空值返回 nil
+func decodeStringList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal([]byte(value), &list); err != nil {
+		return nil
+	}
+	return list
+}
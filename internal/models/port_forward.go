@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// 端口转发会话状态
+const (
+	PortForwardStatusActive  = "active"
+	PortForwardStatusClosed  = "closed"
+	PortForwardStatusExpired = "expired"
+	PortForwardStatusFailed  = "failed"
+)
+
+// PortForwardSession 端口转发会话（审计记录）
+type PortForwardSession struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	ForwardID   string     `json:"forward_id" gorm:"size:64;uniqueIndex"`
+	UserID      uint       `json:"user_id" gorm:"index"`
+	Username    string     `json:"username" gorm:"size:100"`
+	ClusterID   uint       `json:"cluster_id" gorm:"index"`
+	Namespace   string     `json:"namespace" gorm:"size:100"`
+	TargetKind  string     `json:"target_kind" gorm:"size:20"` // pod / service
+	TargetName  string     `json:"target_name" gorm:"size:200"`
+	Pod         string     `json:"pod" gorm:"size:200"` // 实际转发的 Pod（Service 解析后的后端 Pod）
+	RemotePort  int        `json:"remote_port"`
+	Connections int64      `json:"connections"`
+	BytesIn     int64      `json:"bytes_in"`  // 客户端发往 Pod 的字节数
+	BytesOut    int64      `json:"bytes_out"` // Pod 返回客户端的字节数
+	Status      string     `json:"status" gorm:"size:20;index"`
+	CloseReason string     `json:"close_reason" gorm:"size:255"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	ClosedAt    *time.Time `json:"closed_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"index"`
+}
+
+// TableName 指定表名
+func (PortForwardSession) TableName() string {
+	return "port_forward_sessions"
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReportSchedule 定时报表：按 Cron 表达式生成报表并通过 SMTP 邮件渠道发送
+type ReportSchedule struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	Name            string         `json:"name" gorm:"uniqueIndex;not null;size:100"`
+	Description     string         `json:"description" gorm:"size:255"`
+	Cron            string         `json:"cron" gorm:"not null;size:100"`     // 5 段 Cron 表达式，如 "0 9 * * 1"（每周一 9 点）
+	Timezone        string         `json:"timezone" gorm:"size:64"`           // IANA 时区，空表示服务器时区
+	Format          string         `json:"format" gorm:"size:20"`             // html, markdown
+	Sections        string         `json:"sections" gorm:"type:text"`         // 报表章节，JSON 格式 ["overview","health"]，空表示全部
+	ClusterSelector string         `json:"cluster_selector" gorm:"type:text"` // 集群标签选择器，JSON 格式 {"env":"prod"}，空表示全部集群
+	PeriodDays      int            `json:"period_days" gorm:"default:7"`      // 操作统计区间（天）
+	Channel         string         `json:"channel" gorm:"size:100"`           // 通知配置中的邮件渠道名称
+	Recipients      string         `json:"recipients" gorm:"type:text"`       // 收件人，JSON 格式，空表示使用渠道的收件人
+	Enabled         bool           `json:"enabled"`
+	NextRunAt       *time.Time     `json:"next_run_at" gorm:"index"`
+	LastRunAt       *time.Time     `json:"last_run_at"`
+	LastStatus      string         `json:"last_status" gorm:"size:20"`
+	CreatedBy       string         `json:"created_by" gorm:"size:100"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName 指定表名
+func (ReportSchedule) TableName() string {
+	return "report_schedules"
+}
+
+// GetSectionList 获取报表章节
+func (r *ReportSchedule) GetSectionList() []string {
+	return decodeStringList(r.Sections)
+}
+
+// GetRecipientList 获取收件人
+func (r *ReportSchedule) GetRecipientList() []string {
+	return decodeStringList(r.Recipients)
+}
+
+// GetClusterSelector 获取集群标签选择器
+func (r *ReportSchedule) GetClusterSelector() map[string]string {
+	return DecodeLabelSelector(r.ClusterSelector)
+}
+
+// ReportDeliveryStatus 报表发送状态常量
+const (
+	ReportDeliverySent   = "sent"
+	ReportDeliveryFailed = "failed"
+)
+
+// ReportDeliveryTrigger 报表发送触发方式常量
+const (
+	ReportTriggerSchedule = "schedule" // 按 Cron 定时发送
+	ReportTriggerManual   = "manual"   // 手动立即发送
+)
+
+// ReportDelivery 报表发送记录
+type ReportDelivery struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	ScheduleID   uint       `json:"schedule_id" gorm:"index"`
+	ScheduleName string     `json:"schedule_name" gorm:"size:100"`
+	Trigger      string     `json:"trigger" gorm:"size:20"`
+	TriggeredBy  string     `json:"triggered_by" gorm:"size:100"` // 手动发送的用户
+	Status       string     `json:"status" gorm:"size:20;index"`
+	Recipients   string     `json:"recipients" gorm:"type:text"` // JSON 格式
+	Subject      string     `json:"subject" gorm:"size:255"`
+	Format       string     `json:"format" gorm:"size:20"`
+	Content      string     `json:"content,omitempty" gorm:"type:mediumtext"` // 渲染后的报表，列表接口不返回
+	Error        string     `json:"error" gorm:"size:1000"`
+	Duration     int64      `json:"duration"` // 生成与发送耗时（毫秒）
+	SentAt       *time.Time `json:"sent_at"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"index"`
+}
+
+// TableName 指定表名
+func (ReportDelivery) TableName() string {
+	return "report_deliveries"
+}
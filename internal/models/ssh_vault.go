@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// SSH 凭据认证方式
+const (
+	SSHAuthTypePassword    = "password"
+	SSHAuthTypeKey         = "key"
+	SSHAuthTypeCertificate = "certificate" // 由 CA 为每次会话签发短期证书
+)
+
+// SSHCredentialProfile 节点 SSH 凭据配置，按集群与节点标签匹配
+type SSHCredentialProfile struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Name         string    `json:"name" gorm:"uniqueIndex;size:100;not null"`
+	Description  string    `json:"description" gorm:"size:255"`
+	ClusterID    uint      `json:"cluster_id" gorm:"index"`           // 0 表示适用于所有集群
+	NodeSelector string    `json:"node_selector" gorm:"type:text"`    // 节点标签选择器，JSON 格式 {"pool":"gpu"}，空表示匹配全部节点
+	Priority     int       `json:"priority" gorm:"default:0"`         // 多个配置匹配时优先级高者生效
+	Username     string    `json:"username" gorm:"size:100;not null"` // 登录用户（证书方式下为 principal）
+	Port         int       `json:"port" gorm:"default:22"`            // SSH 端口
+	AuthType     string    `json:"auth_type" gorm:"size:20;not null"` // password / key / certificate
+	PasswordEnc  string    `json:"-" gorm:"type:text"`                // 加密存储的密码
+	SecretKeyEnc string    `json:"-" gorm:"type:text"`                // 加密存储的私钥（certificate 方式为 CA 私钥）
+	PublicKey    string    `json:"public_key" gorm:"type:text"`       // 私钥对应的公钥（certificate 方式为 CA 公钥，需配置到节点 TrustedUserCAKeys）
+	CertTTL      int       `json:"cert_ttl_minutes" gorm:"default:0"` // 证书有效期（分钟），0 使用全局默认
+	CreatedBy    uint      `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	HasPassword  bool `json:"has_password" gorm:"-"`
+	HasSecretKey bool `json:"has_secret_key" gorm:"-"`
+}
+
+// GetNodeSelector 获取节点标签选择器
+func (p *SSHCredentialProfile) GetNodeSelector() map[string]string {
+	return DecodeLabelSelector(p.NodeSelector)
+}
+
+// TableName 指定表名
+func (SSHCredentialProfile) TableName() string {
+	return "ssh_credential_profiles"
+}
+
+// SSHHostKey 节点 SSH 主机密钥（首次连接时记录，之后变化即告警）
+type SSHHostKey struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	ClusterID   uint      `json:"cluster_id" gorm:"uniqueIndex:idx_ssh_host_key"`
+	Host        string    `json:"host" gorm:"uniqueIndex:idx_ssh_host_key;size:255"` // host:port
+	Node        string    `json:"node" gorm:"size:255"`
+	KeyType     string    `json:"key_type" gorm:"size:50"`
+	Fingerprint string    `json:"fingerprint" gorm:"size:100"`
+	PublicKey   string    `json:"public_key" gorm:"type:text"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+
+	// 最近一次不一致的密钥（待管理员确认）
+	MismatchKeyType     string     `json:"mismatch_key_type" gorm:"size:50"`
+	MismatchFingerprint string     `json:"mismatch_fingerprint" gorm:"size:100"`
+	MismatchPublicKey   string     `json:"mismatch_public_key" gorm:"type:text"`
+	MismatchCount       int        `json:"mismatch_count"`
+	LastMismatchAt      *time.Time `json:"last_mismatch_at"`
+}
+
+// TableName 指定表名
+func (SSHHostKey) TableName() string {
+	return "ssh_host_keys"
+}
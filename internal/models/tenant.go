@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TenantRole 租户成员角色常量
+const (
+	TenantRoleAdmin  = "admin"  // 租户管理员：管理本租户成员及命名空间内授权
+	TenantRoleMember = "member" // 租户成员
+)
+
+// TenantStatus 租户状态常量
+const (
+	TenantStatusActive   = "active"
+	TenantStatusDisabled = "disabled"
+)
+
+// TenantNamespaceAll 租户独占整个集群时使用的命名空间标记
+const TenantNamespaceAll = "*"
+
+// Tenant 租户
+// 租户跨集群拥有一组命名空间（或独占整个集群），租户成员只能看到本租户命名空间内的数据
+type Tenant struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"uniqueIndex;not null;size:100"`
+	DisplayName string         `json:"display_name" gorm:"size:100"`
+	Description string         `json:"description" gorm:"size:255"`
+	Status      string         `json:"status" gorm:"default:active;size:20"` // active, disabled
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 关联（预加载用）
+	Namespaces []TenantNamespace `json:"namespaces,omitempty" gorm:"foreignKey:TenantID"`
+	Members    []TenantMember    `json:"members,omitempty" gorm:"foreignKey:TenantID"`
+}
+
+// TableName 指定表名
+func (Tenant) TableName() string {
+	return "tenants"
+}
+
+// TenantNamespace 租户拥有的命名空间（同一集群的命名空间只能归属一个租户）
+type TenantNamespace struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TenantID  uint      `json:"tenant_id" gorm:"index;not null"`
+	ClusterID uint      `json:"cluster_id" gorm:"uniqueIndex:idx_tenant_ns_cluster_ns;not null"`
+	Namespace string    `json:"namespace" gorm:"uniqueIndex:idx_tenant_ns_cluster_ns;not null;size:100"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Cluster *Cluster `json:"cluster,omitempty" gorm:"foreignKey:ClusterID"`
+}
+
+// TableName 指定表名
+func (TenantNamespace) TableName() string {
+	return "tenant_namespaces"
+}
+
+// TenantMember 租户成员（一个用户最多属于一个租户）
+type TenantMember struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TenantID  uint      `json:"tenant_id" gorm:"index;not null"`
+	UserID    uint      `json:"user_id" gorm:"uniqueIndex;not null"`
+	Role      string    `json:"role" gorm:"not null;size:20;default:member"` // admin, member
+	CreatedAt time.Time `json:"created_at"`
+
+	User   *User   `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Tenant *Tenant `json:"tenant,omitempty" gorm:"foreignKey:TenantID"`
+}
+
+// TableName 指定表名
+func (TenantMember) TableName() string {
+	return "tenant_members"
+}
+
+// IsAdmin 是否为租户管理员
+func (m *TenantMember) IsAdmin() bool {
+	return m.Role == TenantRoleAdmin
+}
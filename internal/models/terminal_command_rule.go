@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CommandRuleEffect 终端命令规则效果常量
+const (
+	CommandRuleEffectAllow   = "allow"   // 允许（用于在拒绝规则中开白名单）
+	CommandRuleEffectConfirm = "confirm" // 需要再次回车确认后执行
+	CommandRuleEffectDeny    = "deny"    // 拒绝执行
+)
+
+// CommandRuleMatch 终端命令规则匹配方式常量
+const (
+	CommandRuleMatchPrefix   = "prefix"   // 命令以模式开头（按单词边界）
+	CommandRuleMatchContains = "contains" // 命令包含模式
+	CommandRuleMatchRegex    = "regex"    // 正则表达式
+)
+
+// TerminalCommandRule 终端命令执行前策略规则
+// 在用户按下回车时匹配重建的命令行，按优先级决定允许、确认或拒绝；
+// 同优先级下拒绝优先于确认，确认优先于允许。
+type TerminalCommandRule struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	Name            string         `json:"name" gorm:"uniqueIndex;not null;size:100"`
+	Description     string         `json:"description" gorm:"size:255"`
+	Effect          string         `json:"effect" gorm:"not null;size:10"`     // allow, confirm, deny
+	MatchType       string         `json:"match_type" gorm:"not null;size:20"` // prefix, contains, regex
+	Pattern         string         `json:"pattern" gorm:"not null;size:500"`   // 匹配模式
+	Priority        int            `json:"priority" gorm:"default:0"`          // 数值越大越优先
+	TerminalTypes   string         `json:"terminal_types" gorm:"type:text"`    // 适用终端类型，JSON 格式 ["pod","node"]，空表示全部
+	ClusterSelector string         `json:"cluster_selector" gorm:"type:text"`  // 集群标签选择器，JSON 格式，空表示全部集群
+	Namespaces      string         `json:"namespaces" gorm:"type:text"`        // 命名空间匹配模式，JSON 格式，空表示全部
+	PermissionTypes string         `json:"permission_types" gorm:"type:text"`  // 适用权限类型，JSON 格式 ["dev","ops"]，空表示全部
+	Message         string         `json:"message" gorm:"size:255"`            // 拦截时在终端显示的提示
+	Enabled         bool           `json:"enabled"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName 指定表名
+func (TerminalCommandRule) TableName() string {
+	return "terminal_command_rules"
+}
+
+// GetTerminalTypeList 获取适用终端类型
+func (r *TerminalCommandRule) GetTerminalTypeList() []string {
+	return decodeStringList(r.TerminalTypes)
+}
+
+// GetClusterSelector 获取集群标签选择器
+func (r *TerminalCommandRule) GetClusterSelector() map[string]string {
+	return DecodeLabelSelector(r.ClusterSelector)
+}
+
+// GetNamespaceList 获取命名空间匹配模式
+func (r *TerminalCommandRule) GetNamespaceList() []string {
+	return decodeStringList(r.Namespaces)
+}
+
+// GetPermissionTypeList 获取适用权限类型
+func (r *TerminalCommandRule) GetPermissionTypeList() []string {
+	return decodeStringList(r.PermissionTypes)
+}
@@ -19,6 +19,7 @@ type User struct {
 	Status       string         `json:"status" gorm:"default:active;size:20"`   // active, inactive, locked
 	LastLoginAt  *time.Time     `json:"last_login_at"`
 	LastLoginIP  string         `json:"last_login_ip" gorm:"size:50"`
+	Language     string         `json:"language" gorm:"size:10"` // 界面与错误提示语言：zh, en，空表示跟随浏览器
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"text/template"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Sender 通知渠道
+type Sender interface {
+	Send(ctx context.Context, e *Event) error
+}
+
+// Validate 校验渠道配置
+func Validate(ch *models.NotificationChannel) error {
+	if ch.Name == "" {
+		return errors.New("渠道名称不能为空")
+	}
+	cfg := &ch.Config
+	switch ch.Type {
+	case models.NotifyChannelWebhook:
+		if cfg.URL == "" {
+			return errors.New("Webhook URL 不能为空")
+		}
+		if cfg.BodyTemplate != "" {
+			if _, err := parseBodyTemplate(cfg.BodyTemplate); err != nil {
+				return fmt.Errorf("请求体模板错误: %w", err)
+			}
+		}
+	case models.NotifyChannelSlack, models.NotifyChannelDingTalk, models.NotifyChannelWeCom, models.NotifyChannelFeishu:
+		if cfg.URL == "" {
+			return errors.New("机器人 Webhook 地址不能为空")
+		}
+	case models.NotifyChannelEmail:
+		if cfg.SMTPHost == "" || cfg.SMTPPort <= 0 {
+			return errors.New("SMTP 地址和端口不能为空")
+		}
+		if _, err := mail.ParseAddress(cfg.From); err != nil {
+			return errors.New("发件人地址无效")
+		}
+		if len(cfg.To) == 0 {
+			return errors.New("至少需要一个收件人")
+		}
+		for _, to := range cfg.To {
+			if _, err := mail.ParseAddress(to); err != nil {
+				return fmt.Errorf("收件人地址无效: %s", to)
+			}
+		}
+	default:
+		return fmt.Errorf("不支持的渠道类型: %s", ch.Type)
+	}
+	return nil
+}
+
+// NewSender 按渠道类型创建发送器
+func NewSender(ch *models.NotificationChannel) (Sender, error) {
+	if err := Validate(ch); err != nil {
+		return nil, err
+	}
+	timeout := defaultTimeout
+	if ch.Config.TimeoutSeconds > 0 {
+		timeout = time.Duration(ch.Config.TimeoutSeconds) * time.Second
+	}
+	switch ch.Type {
+	case models.NotifyChannelWebhook:
+		tmpl, err := parseBodyTemplate(ch.Config.BodyTemplate)
+		if err != nil {
+			return nil, err
+		}
+		return &webhookSender{cfg: ch.Config, tmpl: tmpl, client: newHTTPClient(timeout)}, nil
+	case models.NotifyChannelEmail:
+		return &emailSender{cfg: ch.Config, timeout: timeout}, nil
+	default:
+		return &botSender{kind: ch.Type, cfg: ch.Config, client: newHTTPClient(timeout)}, nil
+	}
+}
+
+// parseBodyTemplate 解析通用 Webhook 请求体模板，模板为空时返回 nil
+func parseBodyTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	return template.New("body").Funcs(template.FuncMap{"json": jsonString}).Parse(text)
+}
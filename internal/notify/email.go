@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+// smtpsPort 使用 TLS 直连（SMTPS）的端口
+const smtpsPort = 465
+
+// emailSender SMTP 邮件
+type emailSender struct {
+	cfg     models.NotificationChannelConfig
+	timeout time.Duration
+}
+
+func (s *emailSender) Send(ctx context.Context, e *Event) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return SendMail(ctx, s.cfg, &Mail{To: s.cfg.To, Subject: e.Subject(), Body: e.Text()})
+}
+
+// Mail 邮件内容
+type Mail struct {
+	To      []string
+	Subject string
+	Body    string
+	HTML    bool // 正文为 HTML
+}
+
+// SendMail 使用渠道的 SMTP 配置发送邮件
+func SendMail(ctx context.Context, cfg models.NotificationChannelConfig, m *Mail) error {
+	if len(m.To) == 0 {
+		return errors.New("收件人不能为空")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return errors.New("发件人地址无效")
+	}
+	recipients := make([]string, 0, len(m.To))
+	for _, to := range m.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return fmt.Errorf("收件人地址无效: %s", to)
+		}
+		recipients = append(recipients, addr.Address)
+	}
+
+	client, err := dialSMTP(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = client.Close() }()
+
+	if cfg.Username != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.SMTPHost)); err != nil {
+				return fmt.Errorf("SMTP 认证失败: %w", err)
+			}
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM 失败: %w", err)
+	}
+	for _, rcpt := range recipients {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("SMTP RCPT TO %s 失败: %w", rcpt, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA 失败: %w", err)
+	}
+	if _, err := w.Write(buildMessage(from.String(), m)); err != nil {
+		_ = w.Close()
+		return fmt.Errorf("写入邮件失败: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+	return client.Quit()
+}
+
+// dialSMTP 连接 SMTP 服务器：465 端口 TLS 直连，其他端口在服务端支持时升级 STARTTLS
+func dialSMTP(ctx context.Context, cfg models.NotificationChannelConfig) (*smtp.Client, error) {
+	addr := net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort))
+	tlsConfig := &tls.Config{ServerName: cfg.SMTPHost, MinVersion: tls.VersionTLS12}
+
+	var conn net.Conn
+	var err error
+	if cfg.SMTPPort == smtpsPort {
+		dialer := &tls.Dialer{Config: tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("连接 SMTP 服务器失败: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, cfg.SMTPHost)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("SMTP 握手失败: %w", err)
+	}
+	if cfg.SMTPPort != smtpsPort {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				_ = client.Close()
+				return nil, fmt.Errorf("SMTP STARTTLS 失败: %w", err)
+			}
+		}
+	}
+	return client, nil
+}
+
+// buildMessage 构造 UTF-8 邮件（主题按 RFC 2047 编码，正文 base64）
+func buildMessage(from string, m *Mail) []byte {
+	contentType := "text/plain"
+	if m.HTML {
+		contentType = "text/html"
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(m.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: %s; charset=UTF-8\r\n", contentType)
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	writeBase64Lines(&b, []byte(m.Body))
+	return b.Bytes()
+}
+
+// writeBase64Lines 按 76 字符折行写入 base64 内容
+func writeBase64Lines(b *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76])
+		b.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,101 @@
+// Package notify 平台事件通知：将集群不可达、操作失败等事件渲染后发送到
+// 通用 Webhook、Slack、钉钉、企业微信、飞书与 SMTP 邮件等渠道。
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+// 事件严重级别
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Event 通知事件
+type Event struct {
+	ID          string            `json:"id"`
+	Type        string            `json:"type"`
+	Severity    string            `json:"severity"`
+	Title       string            `json:"title"`
+	Message     string            `json:"message"`
+	ClusterID   uint              `json:"cluster_id,omitempty"`
+	ClusterName string            `json:"cluster_name,omitempty"`
+	Fields      map[string]string `json:"fields,omitempty"` // 事件附加信息，如用户名、节点名
+	Time        time.Time         `json:"time"`
+
+	// ClusterLabels 用于匹配订阅的集群选择器，不对外发送
+	ClusterLabels map[string]string `json:"-"`
+}
+
+// NewEvent 创建事件，标题为空时使用事件类型的中文名称
+func NewEvent(eventType, severity, title, message string) *Event {
+	if title == "" {
+		title = models.NotifyEventNames[eventType]
+	}
+	return &Event{
+		ID:       uuid.NewString(),
+		Type:     eventType,
+		Severity: severity,
+		Title:    title,
+		Message:  message,
+		Fields:   map[string]string{},
+		Time:     time.Now(),
+	}
+}
+
+// sortedFields 按键排序的附加信息，保证渲染结果稳定
+func (e *Event) sortedFields() []string {
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Subject 邮件主题等单行标题
+func (e *Event) Subject() string {
+	if e.ClusterName != "" {
+		return fmt.Sprintf("[KubePolaris][%s] %s - %s", e.Severity, e.Title, e.ClusterName)
+	}
+	return fmt.Sprintf("[KubePolaris][%s] %s", e.Severity, e.Title)
+}
+
+// Text 纯文本正文
+func (e *Event) Text() string {
+	var b strings.Builder
+	b.WriteString(e.Subject())
+	b.WriteString("\n")
+	if e.Message != "" {
+		b.WriteString(e.Message)
+		b.WriteString("\n")
+	}
+	for _, k := range e.sortedFields() {
+		fmt.Fprintf(&b, "%s: %s\n", k, e.Fields[k])
+	}
+	fmt.Fprintf(&b, "时间: %s", e.Time.Local().Format("2006-01-02 15:04:05"))
+	return b.String()
+}
+
+// Markdown 钉钉、企业微信使用的 Markdown 正文
+func (e *Event) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s\n", e.Subject())
+	if e.Message != "" {
+		fmt.Fprintf(&b, "%s\n\n", e.Message)
+	}
+	for _, k := range e.sortedFields() {
+		fmt.Fprintf(&b, "- **%s**: %s\n", k, e.Fields[k])
+	}
+	fmt.Fprintf(&b, "- **时间**: %s", e.Time.Local().Format("2006-01-02 15:04:05"))
+	return b.String()
+}
@@ -0,0 +1,228 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+func testEvent() *Event {
+	e := NewEvent(models.NotifyEventNodeDrained, SeverityWarning, "", "alice 驱逐了节点 node-1")
+	e.ClusterName = "prod-1"
+	e.Fields["用户"] = "alice"
+	return e
+}
+
+func TestWebhookBodyTemplate(t *testing.T) {
+	bodies := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if r.Header.Get("X-Token") != "abc" || r.Header.Get(HeaderSignature) == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		bodies <- string(data)
+	}))
+	defer srv.Close()
+
+	sender, err := NewSender(&models.NotificationChannel{
+		Name: "ops", Type: models.NotifyChannelWebhook,
+		Config: models.NotificationChannelConfig{
+			URL:          srv.URL,
+			Secret:       "s3cret",
+			Headers:      map[string]string{"X-Token": "abc"},
+			BodyTemplate: `{"summary":{{ json .Title }},"cluster":{{ json .ClusterName }},"user":{{ json (index .Fields "用户") }}}`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("new sender: %v", err)
+	}
+	if err := sender.Send(context.Background(), testEvent()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	var got map[string]string
+	if err := json.Unmarshal([]byte(<-bodies), &got); err != nil {
+		t.Fatalf("rendered body is not JSON: %v", err)
+	}
+	if got["summary"] != "节点驱逐" || got["cluster"] != "prod-1" || got["user"] != "alice" {
+		t.Fatalf("unexpected body: %+v", got)
+	}
+}
+
+func TestValidateRejectsBadTemplate(t *testing.T) {
+	err := Validate(&models.NotificationChannel{
+		Name: "bad", Type: models.NotifyChannelWebhook,
+		Config: models.NotificationChannelConfig{URL: "http://example.invalid", BodyTemplate: "{{ .Title "},
+	})
+	if err == nil {
+		t.Fatal("expected template parse error")
+	}
+}
+
+func TestBotSenders(t *testing.T) {
+	type request struct {
+		query string
+		body  map[string]interface{}
+	}
+	requests := make(chan request, 1)
+	reply := `{"errcode":0,"errmsg":"ok"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		requests <- request{query: r.URL.RawQuery, body: body}
+		_, _ = io.WriteString(w, reply)
+	}))
+	defer srv.Close()
+
+	send := func(kind, secret string) (request, error) {
+		sender, err := NewSender(&models.NotificationChannel{
+			Name: kind, Type: kind, Config: models.NotificationChannelConfig{URL: srv.URL + "/robot?access_token=t", Secret: secret},
+		})
+		if err != nil {
+			t.Fatalf("new sender: %v", err)
+		}
+		err = sender.Send(context.Background(), testEvent())
+		return <-requests, err
+	}
+
+	req, err := send(models.NotifyChannelDingTalk, "SEC123")
+	if err != nil {
+		t.Fatalf("dingtalk: %v", err)
+	}
+	if req.body["msgtype"] != "markdown" || !strings.Contains(req.query, "sign=") ||
+		!strings.Contains(req.query, "access_token=t") {
+		t.Fatalf("unexpected dingtalk request: %+v", req)
+	}
+
+	req, err = send(models.NotifyChannelWeCom, "")
+	if err != nil || req.body["msgtype"] != "markdown" {
+		t.Fatalf("unexpected wecom request: %+v, %v", req, err)
+	}
+
+	req, err = send(models.NotifyChannelSlack, "")
+	if err != nil || !strings.Contains(req.body["text"].(string), "node-1") {
+		t.Fatalf("unexpected slack request: %+v, %v", req, err)
+	}
+
+	reply = `{"code":19021,"msg":"sign match fail"}`
+	req, err = send(models.NotifyChannelFeishu, "SEC456")
+	if err == nil || !strings.Contains(err.Error(), "19021") {
+		t.Fatalf("expected feishu business error, got %v", err)
+	}
+	if req.body["msg_type"] != "text" || req.body["sign"] == "" {
+		t.Fatalf("unexpected feishu request: %+v", req)
+	}
+}
+
+// smtpMessage SMTP 桩服务收到的邮件
+type smtpMessage struct {
+	from, to []string
+	data     string
+}
+
+func TestSendMail(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	got := make(chan smtpMessage, 1)
+	go serveSMTPStub(ln, got)
+
+	addr := ln.Addr().(*net.TCPAddr)
+	sender, err := NewSender(&models.NotificationChannel{
+		Name: "mail", Type: models.NotifyChannelEmail,
+		Config: models.NotificationChannelConfig{
+			SMTPHost: "127.0.0.1", SMTPPort: addr.Port,
+			From: "KubePolaris <noreply@example.com>", To: []string{"ops@example.com", "sre@example.com"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("new sender: %v", err)
+	}
+	if err := sender.Send(context.Background(), testEvent()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	select {
+	case r := <-got:
+		if len(r.from) != 1 || r.from[0] != "<noreply@example.com>" || len(r.to) != 2 {
+			t.Fatalf("unexpected envelope: %+v", r)
+		}
+		if !strings.Contains(r.data, "Subject: =?UTF-8?b?") {
+			t.Fatalf("subject should be RFC 2047 encoded: %s", r.data)
+		}
+		parts := strings.SplitN(r.data, "\r\n\r\n", 2)
+		body, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(parts[1], "\r\n", ""))
+		if err != nil || !strings.Contains(string(body), "alice 驱逐了节点 node-1") {
+			t.Fatalf("unexpected body: %q, %v", body, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("mail not received")
+	}
+}
+
+// serveSMTPStub 最小 SMTP 服务（不支持 STARTTLS 与认证），收到一封邮件后退出
+func serveSMTPStub(ln net.Listener, got chan<- smtpMessage) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(s string) { _, _ = io.WriteString(conn, s+"\r\n") }
+
+	var msg smtpMessage
+	reply("220 stub ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 stub")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			msg.from = append(msg.from, strings.TrimSpace(line[len("MAIL FROM:"):]))
+			reply("250 ok")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			msg.to = append(msg.to, strings.TrimSpace(line[len("RCPT TO:"):]))
+			reply("250 ok")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(l)
+			}
+			msg.data = strings.TrimSuffix(b.String(), "\r\n")
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			got <- msg
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
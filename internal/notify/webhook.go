@@ -0,0 +1,203 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+// 通用 Webhook 签名请求头，与审计外送 Webhook 一致
+const (
+	HeaderTimestamp = "X-KubePolaris-Timestamp"
+	HeaderSignature = "X-KubePolaris-Signature"
+)
+
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout}
+}
+
+// jsonString 模板函数：将值编码为 JSON（字符串会带引号并转义）
+func jsonString(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// webhookSender 通用 Webhook：默认发送事件 JSON，配置了模板时以事件为数据渲染请求体
+type webhookSender struct {
+	cfg    models.NotificationChannelConfig
+	tmpl   *template.Template
+	client *http.Client
+}
+
+func (s *webhookSender) Send(ctx context.Context, e *Event) error {
+	var body []byte
+	if s.tmpl != nil {
+		var buf bytes.Buffer
+		if err := s.tmpl.Execute(&buf, e); err != nil {
+			return fmt.Errorf("渲染请求体模板失败: %w", err)
+		}
+		body = buf.Bytes()
+	} else {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		body = data
+	}
+
+	method := s.cfg.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if s.cfg.Secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(s.cfg.Secret))
+		mac.Write([]byte(ts + "."))
+		mac.Write(body)
+		req.Header.Set(HeaderTimestamp, ts)
+		req.Header.Set(HeaderSignature, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	_, err = doRequest(s.client, req)
+	return err
+}
+
+// botSender Slack、钉钉、企业微信、飞书群机器人
+type botSender struct {
+	kind   string
+	cfg    models.NotificationChannelConfig
+	client *http.Client
+}
+
+// botResponse 机器人接口的错误码（钉钉、企业微信使用 errcode，飞书使用 code）
+type botResponse struct {
+	ErrCode *int   `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+	Code    *int   `json:"code"`
+	Msg     string `json:"msg"`
+}
+
+func (s *botSender) Send(ctx context.Context, e *Event) error {
+	target := s.cfg.URL
+	var payload interface{}
+	switch s.kind {
+	case models.NotifyChannelSlack:
+		payload = map[string]interface{}{"text": e.Text()}
+	case models.NotifyChannelDingTalk:
+		if s.cfg.Secret != "" {
+			signed, err := signDingTalkURL(target, s.cfg.Secret, time.Now())
+			if err != nil {
+				return err
+			}
+			target = signed
+		}
+		payload = map[string]interface{}{
+			"msgtype":  "markdown",
+			"markdown": map[string]string{"title": e.Title, "text": e.Markdown()},
+		}
+	case models.NotifyChannelWeCom:
+		payload = map[string]interface{}{
+			"msgtype":  "markdown",
+			"markdown": map[string]string{"content": e.Markdown()},
+		}
+	case models.NotifyChannelFeishu:
+		body := map[string]interface{}{
+			"msg_type": "text",
+			"content":  map[string]string{"text": e.Text()},
+		}
+		if s.cfg.Secret != "" {
+			ts := time.Now().Unix()
+			body["timestamp"] = strconv.FormatInt(ts, 10)
+			body["sign"] = FeishuSign(s.cfg.Secret, ts)
+		}
+		payload = body
+	default:
+		return fmt.Errorf("不支持的渠道类型: %s", s.kind)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	respBody, err := doRequest(s.client, req)
+	if err != nil {
+		return err
+	}
+
+	// 机器人接口在 HTTP 200 中返回业务错误
+	var result botResponse
+	if json.Unmarshal(respBody, &result) != nil {
+		return nil
+	}
+	if result.ErrCode != nil && *result.ErrCode != 0 {
+		return fmt.Errorf("机器人返回错误 %d: %s", *result.ErrCode, result.ErrMsg)
+	}
+	if result.Code != nil && *result.Code != 0 {
+		return fmt.Errorf("机器人返回错误 %d: %s", *result.Code, result.Msg)
+	}
+	return nil
+}
+
+// signDingTalkURL 钉钉加签：sign = base64(HMAC-SHA256(secret, timestamp + "\n" + secret))
+func signDingTalkURL(rawURL, secret string, now time.Time) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("无效的钉钉地址: %w", err)
+	}
+	ts := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "\n" + secret))
+	q := u.Query()
+	q.Set("timestamp", ts)
+	q.Set("sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// FeishuSign 飞书加签：以 timestamp + "\n" + secret 为密钥对空串做 HMAC-SHA256
+func FeishuSign(secret string, timestamp int64) string {
+	mac := hmac.New(sha256.New, []byte(strconv.FormatInt(timestamp, 10)+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// doRequest 发送请求，非 2xx 视为失败，返回（截断后的）响应体
+func doRequest(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if len(body) > 512 {
+			body = body[:512]
+		}
+		return nil, fmt.Errorf("返回 %d: %s", resp.StatusCode, body)
+	}
+	return body, nil
+}
@@ -0,0 +1,150 @@
+package portforward
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+)
+
+const readyTimeout = 30 * time.Second
+
+// NewK8sOpener 返回通过 pods/portforward 子资源建立转发的 Opener，转发只监听 127.0.0.1
+func NewK8sOpener(client kubernetes.Interface, restConfig *rest.Config) Opener {
+	return func(target Target) (*Tunnel, error) {
+		transport, upgrader, err := spdy.RoundTripperFor(restConfig)
+		if err != nil {
+			return nil, err
+		}
+		url := client.CoreV1().RESTClient().Post().
+			Resource("pods").
+			Namespace(target.Namespace).
+			Name(target.Pod).
+			SubResource("portforward").
+			URL()
+		dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+		stopCh := make(chan struct{})
+		readyCh := make(chan struct{})
+		var errOut bytes.Buffer
+		fw, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{fmt.Sprintf("0:%d", target.Port)}, stopCh, readyCh, io.Discard, &errOut)
+		if err != nil {
+			return nil, err
+		}
+
+		done := make(chan struct{})
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- fw.ForwardPorts()
+			close(done)
+		}()
+
+		stop := func() {
+			select {
+			case <-stopCh:
+			default:
+				close(stopCh)
+			}
+		}
+
+		select {
+		case <-readyCh:
+		case err := <-errCh:
+			if err == nil {
+				err = fmt.Errorf("转发意外结束: %s", strings.TrimSpace(errOut.String()))
+			}
+			return nil, err
+		case <-time.After(readyTimeout):
+			stop()
+			return nil, fmt.Errorf("建立端口转发超时")
+		}
+
+		ports, err := fw.GetPorts()
+		if err != nil || len(ports) == 0 {
+			stop()
+			return nil, fmt.Errorf("获取转发端口失败: %v", err)
+		}
+		return &Tunnel{LocalPort: int(ports[0].Local), Stop: stop, Done: done}, nil
+	}
+}
+
+// ResolveServiceTarget 将 Service 端口解析为一个就绪后端 Pod 及其容器端口（与 kubectl port-forward svc/xxx 一致）
+func ResolveServiceTarget(ctx context.Context, client kubernetes.Interface, namespace, name string, port int) (string, int, error) {
+	svc, err := client.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", 0, err
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return "", 0, errcode.New(errcode.ServiceNoSelector, name)
+	}
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	pod := SelectReadyPod(pods.Items)
+	if pod == nil {
+		return "", 0, errcode.New(errcode.ServiceNoReadyPod, name)
+	}
+	podPort, err := ServiceTargetPort(svc, pod, port)
+	if err != nil {
+		return "", 0, err
+	}
+	return pod.Name, podPort, nil
+}
+
+// SelectReadyPod 选择第一个运行中且就绪的 Pod
+func SelectReadyPod(pods []corev1.Pod) *corev1.Pod {
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+				return pod
+			}
+		}
+	}
+	return nil
+}
+
+// ServiceTargetPort 将 Service 端口映射为 Pod 上的容器端口（支持命名 targetPort）
+func ServiceTargetPort(svc *corev1.Service, pod *corev1.Pod, port int) (int, error) {
+	for _, sp := range svc.Spec.Ports {
+		if int(sp.Port) != port {
+			continue
+		}
+		switch {
+		case sp.TargetPort.Type == intstr.String && sp.TargetPort.StrVal != "":
+			for _, c := range pod.Spec.Containers {
+				for _, cp := range c.Ports {
+					if cp.Name == sp.TargetPort.StrVal {
+						return int(cp.ContainerPort), nil
+					}
+				}
+			}
+			return 0, errcode.New(errcode.PodPortNotFound, pod.Name, sp.TargetPort.StrVal)
+		case sp.TargetPort.IntValue() > 0:
+			return sp.TargetPort.IntValue(), nil
+		default:
+			return port, nil
+		}
+	}
+	return 0, errcode.New(errcode.ServicePortNotFound, svc.Name, port)
+}
@@ -0,0 +1,354 @@
+package portforward
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+)
+
+// 转发类型
+const (
+	KindPod     = "pod"
+	KindService = "service"
+)
+
+// 转发结束状态
+const (
+	StatusClosed  = "closed"
+	StatusExpired = "expired"
+	StatusFailed  = "failed"
+)
+
+var (
+	// ErrNotFound 转发不存在或已关闭
+	ErrNotFound = errcode.New(errcode.PortForwardNotFound)
+	// ErrLimitExceeded 用户活跃转发数达到上限
+	ErrLimitExceeded = errcode.New(errcode.PortForwardLimitExceeded)
+	// ErrTTLTooLong 申请的有效期超过上限
+	ErrTTLTooLong = errcode.New(errcode.PortForwardTTLTooLong)
+)
+
+// Target 转发目标
+type Target struct {
+	ClusterID uint
+	Namespace string
+	Kind      string // pod / service
+	Name      string // Pod 或 Service 名称
+	Pod       string // 实际转发的 Pod
+	Port      int    // Pod 端口
+}
+
+// Tunnel 已建立的到 Pod 端口的转发
+type Tunnel struct {
+	LocalPort int             // 本机（127.0.0.1）监听端口
+	Stop      func()          // 关闭转发
+	Done      <-chan struct{} // 转发异常结束时关闭
+}
+
+// Opener 建立到目标 Pod 端口的转发
+type Opener func(target Target) (*Tunnel, error)
+
+// Recorder 转发审计记录
+type Recorder interface {
+	RecordOpen(info Info) error
+	RecordClose(info Info, status, reason string)
+}
+
+// Info 转发信息
+type Info struct {
+	ID          string    `json:"id"`
+	UserID      uint      `json:"user_id"`
+	Username    string    `json:"username"`
+	ClusterID   uint      `json:"cluster_id"`
+	Namespace   string    `json:"namespace"`
+	TargetKind  string    `json:"target_kind"`
+	TargetName  string    `json:"target_name"`
+	Pod         string    `json:"pod"`
+	RemotePort  int       `json:"remote_port"`
+	Connections int64     `json:"connections"`
+	BytesIn     int64     `json:"bytes_in"`
+	BytesOut    int64     `json:"bytes_out"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Limits 转发限制
+type Limits struct {
+	MaxPerUser int           // 每个用户同时活跃的转发数，0 表示不限制
+	DefaultTTL time.Duration // 未指定时的有效期
+	MaxTTL     time.Duration // 最长有效期，0 表示不限制
+}
+
+// OpenRequest 申请转发
+type OpenRequest struct {
+	UserID   uint
+	Username string
+	Target   Target
+	TTL      time.Duration
+}
+
+// Forward 活跃的端口转发
+type Forward struct {
+	info      Info
+	accessKey string
+	tunnel    *Tunnel
+	conns     atomic.Int64
+	bytesIn   atomic.Int64
+	bytesOut  atomic.Int64
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// Manager 端口转发管理器
+type Manager struct {
+	mu       sync.Mutex
+	forwards map[string]*Forward
+	limits   Limits
+	record   Recorder
+	now      func() time.Time
+}
+
+// NewManager 创建端口转发管理器，record 可为空
+func NewManager(limits Limits, record Recorder) *Manager {
+	return &Manager{
+		forwards: make(map[string]*Forward),
+		limits:   limits,
+		record:   record,
+		now:      time.Now,
+	}
+}
+
+// Open 建立端口转发
+func (m *Manager) Open(req OpenRequest, opener Opener) (*Forward, error) {
+	ttl := req.TTL
+	if ttl <= 0 {
+		ttl = m.limits.DefaultTTL
+	}
+	if m.limits.MaxTTL > 0 && ttl > m.limits.MaxTTL {
+		return nil, ErrTTLTooLong
+	}
+	if !m.underLimit(req.UserID) {
+		return nil, ErrLimitExceeded
+	}
+
+	tunnel, err := opener(req.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		tunnel.Stop()
+		return nil, err
+	}
+	now := m.now()
+	f := &Forward{
+		info: Info{
+			ID:         uuid.NewString(),
+			UserID:     req.UserID,
+			Username:   req.Username,
+			ClusterID:  req.Target.ClusterID,
+			Namespace:  req.Target.Namespace,
+			TargetKind: req.Target.Kind,
+			TargetName: req.Target.Name,
+			Pod:        req.Target.Pod,
+			RemotePort: req.Target.Port,
+			CreatedAt:  now,
+			ExpiresAt:  now.Add(ttl),
+		},
+		accessKey: hex.EncodeToString(key),
+		tunnel:    tunnel,
+		closed:    make(chan struct{}),
+	}
+
+	// 建立转发期间可能有并发申请，加入前再次检查上限
+	m.mu.Lock()
+	if m.limits.MaxPerUser > 0 && m.countLocked(req.UserID) >= m.limits.MaxPerUser {
+		m.mu.Unlock()
+		tunnel.Stop()
+		return nil, ErrLimitExceeded
+	}
+	m.forwards[f.info.ID] = f
+	m.mu.Unlock()
+
+	if m.record != nil {
+		if err := m.record.RecordOpen(f.info); err != nil {
+			m.remove(f.info.ID)
+			tunnel.Stop()
+			return nil, fmt.Errorf("记录端口转发失败: %w", err)
+		}
+	}
+
+	go func() {
+		select {
+		case <-tunnel.Done:
+			m.finish(f, StatusFailed, "与 Pod 的转发连接已断开")
+		case <-f.closed:
+		}
+	}()
+	return f, nil
+}
+
+// Get 获取活跃转发
+func (m *Manager) Get(id string) (*Forward, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, ok := m.forwards[id]
+	return f, ok
+}
+
+// List 列出活跃转发，userID 为 0 时列出全部
+func (m *Manager) List(userID uint) []Info {
+	m.mu.Lock()
+	items := make([]Info, 0, len(m.forwards))
+	for _, f := range m.forwards {
+		if userID == 0 || f.info.UserID == userID {
+			items = append(items, f.Info())
+		}
+	}
+	m.mu.Unlock()
+	sort.Slice(items, func(i, j int) bool { return items[i].CreatedAt.After(items[j].CreatedAt) })
+	return items
+}
+
+// Close 主动关闭转发
+func (m *Manager) Close(id, reason string) error {
+	f, ok := m.Get(id)
+	if !ok {
+		return ErrNotFound
+	}
+	m.finish(f, StatusClosed, reason)
+	return nil
+}
+
+// ExpireDue 关闭已到期的转发，返回关闭数量
+func (m *Manager) ExpireDue() int {
+	now := m.now()
+	var due []*Forward
+	m.mu.Lock()
+	for _, f := range m.forwards {
+		if !now.Before(f.info.ExpiresAt) {
+			due = append(due, f)
+		}
+	}
+	m.mu.Unlock()
+	for _, f := range due {
+		m.finish(f, StatusExpired, "有效期已到")
+	}
+	return len(due)
+}
+
+// StartReaper 定期关闭到期的转发
+func (m *Manager) StartReaper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.ExpireDue()
+	}
+}
+
+func (m *Manager) underLimit(userID uint) bool {
+	if m.limits.MaxPerUser <= 0 {
+		return true
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.countLocked(userID) < m.limits.MaxPerUser
+}
+
+func (m *Manager) countLocked(userID uint) int {
+	n := 0
+	for _, f := range m.forwards {
+		if f.info.UserID == userID {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *Manager) remove(id string) {
+	m.mu.Lock()
+	delete(m.forwards, id)
+	m.mu.Unlock()
+}
+
+// finish 关闭转发并写入审计记录，重复调用只生效一次
+func (m *Manager) finish(f *Forward, status, reason string) {
+	f.closeOnce.Do(func() {
+		m.remove(f.info.ID)
+		close(f.closed)
+		f.tunnel.Stop()
+		if m.record != nil {
+			m.record.RecordClose(f.Info(), status, reason)
+		}
+	})
+}
+
+// Info 返回转发信息（含流量统计）
+func (f *Forward) Info() Info {
+	info := f.info
+	info.Connections = f.conns.Load()
+	info.BytesIn = f.bytesIn.Load()
+	info.BytesOut = f.bytesOut.Load()
+	return info
+}
+
+// CheckAccessKey 校验 HTTP 反向代理的访问凭据
+func (f *Forward) CheckAccessKey(key string) bool {
+	return subtle.ConstantTimeCompare([]byte(key), []byte(f.accessKey)) == 1
+}
+
+// AccessKey HTTP 反向代理的访问凭据（仅发给转发所有者）
+func (f *Forward) AccessKey() string {
+	return f.accessKey
+}
+
+// Closed 转发关闭时关闭
+func (f *Forward) Closed() <-chan struct{} {
+	return f.closed
+}
+
+// DialContext 连接到转发的本机端口，返回的连接会计入流量统计；可用作 http.Transport.DialContext
+func (f *Forward) DialContext(ctx context.Context, _, _ string) (net.Conn, error) {
+	select {
+	case <-f.closed:
+		return nil, ErrNotFound
+	default:
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", fmt.Sprintf("127.0.0.1:%d", f.tunnel.LocalPort))
+	if err != nil {
+		return nil, err
+	}
+	f.conns.Add(1)
+	return &countingConn{Conn: conn, in: &f.bytesIn, out: &f.bytesOut}, nil
+}
+
+// countingConn 统计经过转发的字节数
+type countingConn struct {
+	net.Conn
+	in, out *atomic.Int64
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.out.Add(int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.in.Add(int64(n))
+	return n, err
+}
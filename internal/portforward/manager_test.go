@@ -0,0 +1,192 @@
+package portforward
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+type memRecorder struct {
+	mu     sync.Mutex
+	opened []string
+	closed map[string]string
+}
+
+func (r *memRecorder) RecordOpen(info Info) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.opened = append(r.opened, info.ID)
+	return nil
+}
+
+func (r *memRecorder) RecordClose(info Info, status, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed[info.ID] = status
+}
+
+func (r *memRecorder) status(id string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closed[id]
+}
+
+// echoOpener 用本机回显服务代替 Pod 端口
+func echoOpener(t *testing.T) (Opener, chan struct{}) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	done := make(chan struct{})
+	return func(target Target) (*Tunnel, error) {
+		return &Tunnel{LocalPort: ln.Addr().(*net.TCPAddr).Port, Stop: func() {}, Done: done}, nil
+	}, done
+}
+
+func TestManagerOpenDialAndClose(t *testing.T) {
+	rec := &memRecorder{closed: map[string]string{}}
+	m := NewManager(Limits{MaxPerUser: 1, DefaultTTL: time.Hour, MaxTTL: 2 * time.Hour}, rec)
+	opener, _ := echoOpener(t)
+
+	f, err := m.Open(OpenRequest{UserID: 1, Target: Target{Namespace: "default", Kind: KindPod, Name: "web", Pod: "web", Port: 80}}, opener)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if got := f.Info().ExpiresAt.Sub(f.Info().CreatedAt); got != time.Hour {
+		t.Fatalf("expected default ttl, got %v", got)
+	}
+	if _, err := m.Open(OpenRequest{UserID: 1}, opener); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected limit error, got %v", err)
+	}
+	if _, err := m.Open(OpenRequest{UserID: 2, TTL: 3 * time.Hour}, opener); !errors.Is(err, ErrTTLTooLong) {
+		t.Fatalf("expected ttl error, got %v", err)
+	}
+
+	conn, err := f.DialContext(context.Background(), "tcp", "")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "ping" {
+		t.Fatalf("unexpected echo %q: %v", buf, err)
+	}
+	conn.Close()
+	info := f.Info()
+	if info.Connections != 1 || info.BytesIn != 4 || info.BytesOut != 4 {
+		t.Fatalf("unexpected counters: %+v", info)
+	}
+
+	if len(m.List(1)) != 1 || len(m.List(2)) != 0 || len(m.List(0)) != 1 {
+		t.Fatal("unexpected list result")
+	}
+	if err := m.Close(info.ID, "test"); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if rec.status(info.ID) != StatusClosed {
+		t.Fatalf("expected closed record, got %q", rec.status(info.ID))
+	}
+	if _, err := f.DialContext(context.Background(), "tcp", ""); err == nil {
+		t.Fatal("closed forward should refuse new connections")
+	}
+	if err := m.Close(info.ID, "again"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestManagerExpireAndTunnelLoss(t *testing.T) {
+	rec := &memRecorder{closed: map[string]string{}}
+	m := NewManager(Limits{DefaultTTL: time.Minute}, rec)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	opener, done := echoOpener(t)
+
+	expiring, _ := m.Open(OpenRequest{UserID: 1}, opener)
+	now = now.Add(30 * time.Second)
+	lost, _ := m.Open(OpenRequest{UserID: 1}, opener)
+
+	now = now.Add(45 * time.Second)
+	if n := m.ExpireDue(); n != 1 {
+		t.Fatalf("expected 1 expired forward, got %d", n)
+	}
+	if rec.status(expiring.Info().ID) != StatusExpired {
+		t.Fatal("forward past its ttl should be expired")
+	}
+
+	close(done)
+	select {
+	case <-lost.Closed():
+	case <-time.After(time.Second):
+		t.Fatal("forward should close when the tunnel is lost")
+	}
+	if rec.status(lost.Info().ID) != StatusFailed {
+		t.Fatalf("expected failed record, got %q", rec.status(lost.Info().ID))
+	}
+}
+
+func readyPod(name string, ready bool) corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:  "app",
+			Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+		}}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestServiceTargetResolution(t *testing.T) {
+	pods := []corev1.Pod{readyPod("web-0", false), readyPod("web-1", true)}
+	pod := SelectReadyPod(pods)
+	if pod == nil || pod.Name != "web-1" {
+		t.Fatalf("expected ready pod web-1, got %v", pod)
+	}
+
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+			{Port: 80, TargetPort: intstr.FromString("http")},
+			{Port: 9090, TargetPort: intstr.FromInt32(9091)},
+			{Port: 7000},
+		}},
+	}
+	for port, want := range map[int]int{80: 8080, 9090: 9091, 7000: 7000} {
+		got, err := ServiceTargetPort(svc, pod, port)
+		if err != nil || got != want {
+			t.Fatalf("port %d: expected %d, got %d (%v)", port, want, got, err)
+		}
+	}
+	if _, err := ServiceTargetPort(svc, pod, 443); err == nil {
+		t.Fatal("unknown service port should fail")
+	}
+}
@@ -0,0 +1,300 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 报表格式
+const (
+	FormatHTML     = "html"
+	FormatMarkdown = "markdown"
+)
+
+// 报表章节
+const (
+	SectionOverview          = "overview"           // 集群、节点、Pod 概况
+	SectionAbnormalWorkloads = "abnormal_workloads" // 异常工作负载
+	SectionHealth            = "health"             // 集群健康诊断
+	SectionAlerts            = "alerts"             // 告警统计
+	SectionOperations        = "operations"         // 操作日志统计
+)
+
+// Sections 全部章节（按报表中的顺序）
+var Sections = []string{SectionOverview, SectionAbnormalWorkloads, SectionHealth, SectionAlerts, SectionOperations}
+
+// Data 报表数据，nil 章节不渲染
+type Data struct {
+	Title       string
+	GeneratedAt time.Time
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Clusters    []string
+
+	Overview          *Overview
+	AbnormalWorkloads []Workload
+	Health            []ClusterHealth
+	Alerts            *Alerts
+	Operations        *Operations
+
+	// 章节是否启用（启用但无数据时显示“无”）
+	HasAbnormalWorkloads bool
+	HasHealth            bool
+
+	// Errors 采集失败的章节说明，报表仍会发送
+	Errors []string
+}
+
+// Overview 资源概况
+type Overview struct {
+	ClustersTotal, ClustersHealthy, ClustersUnhealthy, ClustersUnknown int
+	NodesTotal, NodesReady, NodesNotReady                              int
+	PodsTotal, PodsRunning, PodsPending, PodsFailed                    int
+}
+
+// Workload 异常工作负载
+type Workload struct {
+	Cluster, Namespace, Name, Type, Reason, Duration, Severity string
+}
+
+// ClusterHealth 集群健康诊断摘要
+type ClusterHealth struct {
+	Cluster  string
+	Score    int
+	Status   string
+	Critical int
+	Warning  int
+	TopRisks []string
+	Error    string
+}
+
+// Alerts 告警统计
+type Alerts struct {
+	Total, Firing, Suppressed int
+	BySeverity                map[string]int
+	ByCluster                 []ClusterCount
+}
+
+// ClusterCount 按集群计数
+type ClusterCount struct {
+	Cluster       string
+	Total, Firing int
+}
+
+// Operations 操作日志统计
+type Operations struct {
+	Total, Success, Failed int64
+	TopModules             []NameCount
+	TopUsers               []NameCount
+	RecentFailures         []Failure
+}
+
+// NameCount 名称与计数
+type NameCount struct {
+	Name  string
+	Count int64
+}
+
+// Failure 失败操作
+type Failure struct {
+	Time     time.Time
+	Username string
+	Action   string
+	Path     string
+	Error    string
+}
+
+// SortedSeverities 按名称排序的严重级别计数，保证渲染结果稳定
+func (a *Alerts) SortedSeverities() []NameCount {
+	items := make([]NameCount, 0, len(a.BySeverity))
+	for k, v := range a.BySeverity {
+		items = append(items, NameCount{Name: k, Count: int64(v)})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items
+}
+
+// Render 按格式渲染报表
+func Render(format string, data *Data) (string, error) {
+	switch format {
+	case FormatHTML, "":
+		return RenderHTML(data)
+	case FormatMarkdown:
+		return RenderMarkdown(data), nil
+	}
+	return "", fmt.Errorf("不支持的报表格式: %s", format)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("report").Funcs(htmltemplate.FuncMap{
+	"time": formatTime,
+	"join": strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title>
+<style>
+body{font-family:-apple-system,"Segoe UI","PingFang SC","Microsoft YaHei",sans-serif;color:#1f2937;font-size:14px}
+h1{font-size:20px}h2{font-size:16px;border-left:4px solid #2563eb;padding-left:8px;margin-top:24px}
+table{border-collapse:collapse;margin:8px 0}th,td{border:1px solid #e5e7eb;padding:4px 10px;text-align:left}
+th{background:#f3f4f6}.muted{color:#6b7280}.bad{color:#dc2626}
+</style></head><body>
+<h1>{{.Title}}</h1>
+<p class="muted">统计区间 {{time .PeriodStart}} ~ {{time .PeriodEnd}}，生成于 {{time .GeneratedAt}}<br>
+集群：{{if .Clusters}}{{join .Clusters "、"}}{{else}}无匹配集群{{end}}</p>
+{{with .Overview}}
+<h2>资源概况</h2>
+<table><tr><th></th><th>总数</th><th>正常</th><th>异常</th><th>其他</th></tr>
+<tr><td>集群</td><td>{{.ClustersTotal}}</td><td>{{.ClustersHealthy}}</td><td class="bad">{{.ClustersUnhealthy}}</td><td>{{.ClustersUnknown}}</td></tr>
+<tr><td>节点</td><td>{{.NodesTotal}}</td><td>{{.NodesReady}}</td><td class="bad">{{.NodesNotReady}}</td><td>-</td></tr>
+<tr><td>Pod</td><td>{{.PodsTotal}}</td><td>{{.PodsRunning}}</td><td class="bad">{{.PodsFailed}}</td><td>Pending {{.PodsPending}}</td></tr>
+</table>
+{{end}}
+{{if .HasAbnormalWorkloads}}
+<h2>异常工作负载</h2>
+{{if .AbnormalWorkloads}}<table><tr><th>集群</th><th>命名空间</th><th>名称</th><th>类型</th><th>原因</th><th>持续</th></tr>
+{{range .AbnormalWorkloads}}<tr><td>{{.Cluster}}</td><td>{{.Namespace}}</td><td>{{.Name}}</td><td>{{.Type}}</td><td>{{.Reason}}</td><td>{{.Duration}}</td></tr>
+{{end}}</table>{{else}}<p>无</p>{{end}}
+{{end}}
+{{if .HasHealth}}
+<h2>集群健康诊断</h2>
+{{if .Health}}<table><tr><th>集群</th><th>评分</th><th>状态</th><th>严重</th><th>警告</th><th>主要风险</th></tr>
+{{range .Health}}<tr><td>{{.Cluster}}</td>{{if .Error}}<td colspan="5" class="bad">{{.Error}}</td>{{else}}<td>{{.Score}}</td><td>{{.Status}}</td><td>{{.Critical}}</td><td>{{.Warning}}</td><td>{{join .TopRisks "；"}}</td>{{end}}</tr>
+{{end}}</table>{{else}}<p>无</p>{{end}}
+{{end}}
+{{with .Alerts}}
+<h2>告警统计</h2>
+<p>告警 {{.Total}} 条，触发中 <span class="bad">{{.Firing}}</span>，已抑制 {{.Suppressed}}</p>
+{{with .SortedSeverities}}<table><tr><th>级别</th><th>数量</th></tr>{{range .}}<tr><td>{{.Name}}</td><td>{{.Count}}</td></tr>{{end}}</table>{{end}}
+{{if .ByCluster}}<table><tr><th>集群</th><th>告警</th><th>触发中</th></tr>{{range .ByCluster}}<tr><td>{{.Cluster}}</td><td>{{.Total}}</td><td>{{.Firing}}</td></tr>{{end}}</table>{{end}}
+{{end}}
+{{with .Operations}}
+<h2>平台操作</h2>
+<p>操作 {{.Total}} 次，成功 {{.Success}}，失败 <span class="bad">{{.Failed}}</span></p>
+{{if .TopModules}}<table><tr><th>模块</th><th>次数</th></tr>{{range .TopModules}}<tr><td>{{.Name}}</td><td>{{.Count}}</td></tr>{{end}}</table>{{end}}
+{{if .TopUsers}}<table><tr><th>用户</th><th>次数</th></tr>{{range .TopUsers}}<tr><td>{{.Name}}</td><td>{{.Count}}</td></tr>{{end}}</table>{{end}}
+{{if .RecentFailures}}<table><tr><th>时间</th><th>用户</th><th>操作</th><th>错误</th></tr>
+{{range .RecentFailures}}<tr><td>{{time .Time}}</td><td>{{.Username}}</td><td>{{.Action}} {{.Path}}</td><td>{{.Error}}</td></tr>
+{{end}}</table>{{end}}
+{{end}}
+{{if .Errors}}<h2>采集异常</h2><ul>{{range .Errors}}<li class="bad">{{.}}</li>{{end}}</ul>{{end}}
+</body></html>
+`))
+
+// RenderHTML 渲染 HTML 邮件正文
+func RenderHTML(data *Data) (string, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("渲染报表失败: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// mdEscape 转义 Markdown 表格中的竖线与换行
+func mdEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// RenderMarkdown 渲染 Markdown 报表
+func RenderMarkdown(data *Data) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", data.Title)
+	fmt.Fprintf(&b, "统计区间 %s ~ %s，生成于 %s\n\n", formatTime(data.PeriodStart), formatTime(data.PeriodEnd), formatTime(data.GeneratedAt))
+	if len(data.Clusters) > 0 {
+		fmt.Fprintf(&b, "集群：%s\n", strings.Join(data.Clusters, "、"))
+	} else {
+		b.WriteString("集群：无匹配集群\n")
+	}
+
+	if o := data.Overview; o != nil {
+		b.WriteString("\n## 资源概况\n\n| | 总数 | 正常 | 异常 | 其他 |\n|---|---|---|---|---|\n")
+		fmt.Fprintf(&b, "| 集群 | %d | %d | %d | %d |\n", o.ClustersTotal, o.ClustersHealthy, o.ClustersUnhealthy, o.ClustersUnknown)
+		fmt.Fprintf(&b, "| 节点 | %d | %d | %d | - |\n", o.NodesTotal, o.NodesReady, o.NodesNotReady)
+		fmt.Fprintf(&b, "| Pod | %d | %d | %d | Pending %d |\n", o.PodsTotal, o.PodsRunning, o.PodsFailed, o.PodsPending)
+	}
+
+	if data.HasAbnormalWorkloads {
+		b.WriteString("\n## 异常工作负载\n\n")
+		if len(data.AbnormalWorkloads) == 0 {
+			b.WriteString("无\n")
+		} else {
+			b.WriteString("| 集群 | 命名空间 | 名称 | 类型 | 原因 | 持续 |\n|---|---|---|---|---|---|\n")
+			for _, w := range data.AbnormalWorkloads {
+				fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n", mdEscape(w.Cluster), mdEscape(w.Namespace),
+					mdEscape(w.Name), w.Type, mdEscape(w.Reason), w.Duration)
+			}
+		}
+	}
+
+	if data.HasHealth {
+		b.WriteString("\n## 集群健康诊断\n\n")
+		if len(data.Health) == 0 {
+			b.WriteString("无\n")
+		} else {
+			b.WriteString("| 集群 | 评分 | 状态 | 严重 | 警告 | 主要风险 |\n|---|---|---|---|---|---|\n")
+			for _, h := range data.Health {
+				if h.Error != "" {
+					fmt.Fprintf(&b, "| %s | - | %s | - | - | - |\n", mdEscape(h.Cluster), mdEscape(h.Error))
+					continue
+				}
+				fmt.Fprintf(&b, "| %s | %d | %s | %d | %d | %s |\n", mdEscape(h.Cluster), h.Score, h.Status,
+					h.Critical, h.Warning, mdEscape(strings.Join(h.TopRisks, "；")))
+			}
+		}
+	}
+
+	if a := data.Alerts; a != nil {
+		b.WriteString("\n## 告警统计\n\n")
+		fmt.Fprintf(&b, "告警 %d 条，触发中 %d，已抑制 %d\n", a.Total, a.Firing, a.Suppressed)
+		for _, s := range a.SortedSeverities() {
+			fmt.Fprintf(&b, "- %s: %d\n", s.Name, s.Count)
+		}
+		if len(a.ByCluster) > 0 {
+			b.WriteString("\n| 集群 | 告警 | 触发中 |\n|---|---|---|\n")
+			for _, c := range a.ByCluster {
+				fmt.Fprintf(&b, "| %s | %d | %d |\n", mdEscape(c.Cluster), c.Total, c.Firing)
+			}
+		}
+	}
+
+	if o := data.Operations; o != nil {
+		b.WriteString("\n## 平台操作\n\n")
+		fmt.Fprintf(&b, "操作 %d 次，成功 %d，失败 %d\n", o.Total, o.Success, o.Failed)
+		if len(o.TopModules) > 0 {
+			b.WriteString("\n| 模块 | 次数 |\n|---|---|\n")
+			for _, m := range o.TopModules {
+				fmt.Fprintf(&b, "| %s | %d |\n", m.Name, m.Count)
+			}
+		}
+		if len(o.TopUsers) > 0 {
+			b.WriteString("\n| 用户 | 次数 |\n|---|---|\n")
+			for _, u := range o.TopUsers {
+				fmt.Fprintf(&b, "| %s | %d |\n", mdEscape(u.Name), u.Count)
+			}
+		}
+		if len(o.RecentFailures) > 0 {
+			b.WriteString("\n| 时间 | 用户 | 操作 | 错误 |\n|---|---|---|---|\n")
+			for _, f := range o.RecentFailures {
+				fmt.Fprintf(&b, "| %s | %s | %s %s | %s |\n", formatTime(f.Time), mdEscape(f.Username),
+					f.Action, mdEscape(f.Path), mdEscape(f.Error))
+			}
+		}
+	}
+
+	if len(data.Errors) > 0 {
+		b.WriteString("\n## 采集异常\n\n")
+		for _, e := range data.Errors {
+			fmt.Fprintf(&b, "- %s\n", e)
+		}
+	}
+	return b.String()
+}
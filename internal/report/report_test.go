@@ -0,0 +1,111 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	base := time.Date(2026, 3, 4, 10, 30, 15, 0, loc) // 周三
+
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 3, 4, 10, 45, 0, 0, loc)},
+		{"30 10 * * *", time.Date(2026, 3, 5, 10, 30, 0, 0, loc)},
+		{"0 9 * * 1", time.Date(2026, 3, 9, 9, 0, 0, 0, loc)},
+		{"0 9 * * 7", time.Date(2026, 3, 8, 9, 0, 0, 0, loc)},
+		{"0 8 1-3 * *", time.Date(2026, 4, 1, 8, 0, 0, 0, loc)},
+		{"0 0 13 * 5", time.Date(2026, 3, 6, 0, 0, 0, 0, loc)}, // 日与周满足其一
+		{"0 12 29 2 *", time.Date(2028, 2, 29, 12, 0, 0, 0, loc)},
+		{"@monthly", time.Date(2026, 4, 1, 0, 0, 0, 0, loc)},
+		{"5/20 * * * *", time.Date(2026, 3, 4, 10, 45, 0, 0, loc)},
+	}
+	for _, c := range cases {
+		s, err := ParseCron(c.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", c.expr, err)
+		}
+		if got := s.Next(base); !got.Equal(c.want) {
+			t.Errorf("%s: next = %s, want %s", c.expr, got, c.want)
+		}
+	}
+
+	never, _ := ParseCron("0 0 30 2 *")
+	if got := never.Next(base); !got.IsZero() {
+		t.Errorf("Feb 30 should never fire, got %s", got)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("%q should be rejected", expr)
+		}
+	}
+}
+
+func testData() *Data {
+	now := time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)
+	return &Data{
+		Title:       "生产周报",
+		GeneratedAt: now,
+		PeriodStart: now.AddDate(0, 0, -7),
+		PeriodEnd:   now,
+		Clusters:    []string{"prod-1"},
+		Overview:    &Overview{ClustersTotal: 1, ClustersHealthy: 1, NodesTotal: 3, NodesReady: 3, PodsTotal: 40, PodsRunning: 38, PodsFailed: 2},
+		AbnormalWorkloads: []Workload{
+			{Cluster: "prod-1", Namespace: "shop", Name: "<script>alert(1)</script>", Type: "Deployment", Reason: "副本不足"},
+		},
+		HasAbnormalWorkloads: true,
+		HasHealth:            true,
+		Health:               []ClusterHealth{{Cluster: "prod-1", Score: 82, Status: "warning", Warning: 2, TopRisks: []string{"节点内存压力"}}},
+		Alerts:               &Alerts{Total: 3, Firing: 2, BySeverity: map[string]int{"warning": 2, "critical": 1}},
+		Operations: &Operations{Total: 12, Success: 11, Failed: 1,
+			RecentFailures: []Failure{{Time: now, Username: "alice", Action: "删除", Path: "/api/v1/clusters/1/a|b", Error: "forbidden"}}},
+		Errors: []string{"告警统计: 超时"},
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	out, err := Render(FormatHTML, testData())
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	for _, want := range []string{"<h1>生产周报</h1>", "集群健康诊断", "节点内存压力", "触发中 <span class=\"bad\">2</span>", "采集异常"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html missing %q", want)
+		}
+	}
+	if strings.Contains(out, "<script>") {
+		t.Error("workload name must be escaped")
+	}
+	if !strings.Contains(out, "<tr><td>critical</td><td>1</td></tr><tr><td>warning</td><td>2</td></tr>") {
+		t.Error("severities should be sorted")
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	data := testData()
+	data.Overview = nil
+	data.Health = nil
+	out, err := Render(FormatMarkdown, data)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if strings.Contains(out, "资源概况") {
+		t.Error("nil overview should not be rendered")
+	}
+	if !strings.Contains(out, "## 集群健康诊断\n\n无\n") {
+		t.Errorf("enabled section without data should say 无:\n%s", out)
+	}
+	if !strings.Contains(out, `a\|b`) {
+		t.Error("table cells should escape pipes")
+	}
+	if _, err := Render("pdf", data); err == nil {
+		t.Error("unknown format should fail")
+	}
+}
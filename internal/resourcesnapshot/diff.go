@@ -0,0 +1,157 @@
+package resourcesnapshot
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 差异操作类型（与 JSON Patch 一致）
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+// Change 一条字段级差异，Path 为 JSON Pointer
+type Change struct {
+	Op   string      `json:"op"`
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// Diff 计算两个对象的字段级差异。对象逐键比较；等长数组逐元素比较，长度变化时整体替换
+func Diff(before, after map[string]interface{}) []Change {
+	changes := []Change{}
+	switch {
+	case before == nil && after == nil:
+	case before == nil:
+		changes = append(changes, Change{Op: OpAdd, Path: "", New: after})
+	case after == nil:
+		changes = append(changes, Change{Op: OpRemove, Path: "", Old: before})
+	default:
+		diffMap("", before, after, &changes)
+	}
+	return changes
+}
+
+func diffMap(path string, before, after map[string]interface{}, changes *[]Change) {
+	keys := make([]string, 0, len(before)+len(after))
+	for k := range before {
+		keys = append(keys, k)
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		p := path + "/" + escapePointer(k)
+		oldVal, inOld := before[k]
+		newVal, inNew := after[k]
+		switch {
+		case !inOld:
+			*changes = append(*changes, Change{Op: OpAdd, Path: p, New: newVal})
+		case !inNew:
+			*changes = append(*changes, Change{Op: OpRemove, Path: p, Old: oldVal})
+		default:
+			diffValue(p, oldVal, newVal, changes)
+		}
+	}
+}
+
+func diffValue(path string, before, after interface{}, changes *[]Change) {
+	if oldMap, ok := before.(map[string]interface{}); ok {
+		if newMap, ok := after.(map[string]interface{}); ok {
+			diffMap(path, oldMap, newMap, changes)
+			return
+		}
+	}
+	if oldList, ok := before.([]interface{}); ok {
+		if newList, ok := after.([]interface{}); ok && len(oldList) == len(newList) {
+			for i := range oldList {
+				diffValue(path+"/"+strconv.Itoa(i), oldList[i], newList[i], changes)
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, Change{Op: OpReplace, Path: path, Old: before, New: after})
+	}
+}
+
+// lastAppliedPath kubectl apply 写入的完整对象注解，Secret 中包含明文数据
+var lastAppliedPath = "/metadata/annotations/" + escapePointer(lastAppliedAnnotation)
+
+// redactChanges 将 Secret data/stringData 下的差异值替换为占位值
+func redactChanges(changes []Change) []Change {
+	for i := range changes {
+		c := &changes[i]
+		switch {
+		case c.Path == "":
+			c.Old, c.New = redactWhole(c.Old), redactWhole(c.New)
+		case c.Path == "/data" || c.Path == "/stringData":
+			c.Old, c.New = redactMap(c.Old), redactMap(c.New)
+		case strings.HasPrefix(c.Path, "/data/") || strings.HasPrefix(c.Path, "/stringData/"),
+			c.Path == lastAppliedPath:
+			c.Old, c.New = redactScalar(c.Old), redactScalar(c.New)
+		case c.Path == "/metadata" || c.Path == "/metadata/annotations":
+			c.Old, c.New = redactAnnotations(c.Path, c.Old), redactAnnotations(c.Path, c.New)
+		}
+	}
+	return changes
+}
+
+func redactWhole(v interface{}) interface{} {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	obj = runtimeCopy(obj)
+	redactObject(obj)
+	return obj
+}
+
+// redactAnnotations 从 metadata 或 annotations 差异值中移除 last-applied 注解
+func redactAnnotations(path string, v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	if path == "/metadata/annotations" {
+		m = runtimeCopy(m)
+		delete(m, lastAppliedAnnotation)
+		return m
+	}
+	obj := runtimeCopy(map[string]interface{}{"metadata": m})
+	redactObject(obj)
+	return obj["metadata"]
+}
+
+func redactMap(v interface{}) interface{} {
+	data, ok := v.(map[string]interface{})
+	if !ok {
+		return redactScalar(v)
+	}
+	out := make(map[string]interface{}, len(data))
+	for k := range data {
+		out[k] = RedactedValue
+	}
+	return out
+}
+
+func redactScalar(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return RedactedValue
+}
+
+// escapePointer 按 RFC 6901 转义 JSON Pointer 片段
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
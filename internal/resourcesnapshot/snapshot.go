@@ -0,0 +1,222 @@
+// Package resourcesnapshot 记录变更类操作前后的 Kubernetes 对象快照：
+// 去除服务端字段后计算字段级差异，对 Secret 数据脱敏，并支持将对象恢复到变更前状态。
+package resourcesnapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/client-go/dynamic"
+)
+
+// RedactedValue 脱敏后的占位值
+const RedactedValue = "***"
+
+// lastAppliedAnnotation kubectl apply 记录的上次应用配置
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// serverFields 由服务端维护、不参与快照与差异的 metadata 字段
+var serverFields = []string{
+	"managedFields", "resourceVersion", "uid", "generation", "creationTimestamp",
+	"selfLink", "deletionTimestamp", "deletionGracePeriodSeconds",
+}
+
+// controllerAnnotations 控制器在变更后自动写入的注解，判断对象是否被再次修改时忽略
+var controllerAnnotations = []string{
+	"deployment.kubernetes.io/revision",
+	"rollout.argoproj.io/revision",
+}
+
+// Clean 返回去除 status 与服务端 metadata 字段后的对象副本，obj 为 nil 时返回 nil
+func Clean(obj map[string]interface{}) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+	out := runtimeCopy(obj)
+	delete(out, "status")
+	if meta, ok := out["metadata"].(map[string]interface{}); ok {
+		for _, f := range serverFields {
+			delete(meta, f)
+		}
+	}
+	return out
+}
+
+func runtimeCopy(obj map[string]interface{}) map[string]interface{} {
+	return (&unstructured.Unstructured{Object: obj}).DeepCopy().Object
+}
+
+// isSecret 对象是否为 Secret（核心组）
+func isSecret(obj map[string]interface{}) bool {
+	return obj != nil && obj["kind"] == "Secret" && obj["apiVersion"] == "v1"
+}
+
+// redactObject 将 Secret 的 data/stringData 值替换为占位值，并移除可能含明文的 last-applied 注解
+func redactObject(obj map[string]interface{}) {
+	for _, field := range []string{"data", "stringData"} {
+		if data, ok := obj[field].(map[string]interface{}); ok {
+			for k := range data {
+				data[k] = RedactedValue
+			}
+		}
+	}
+	if meta, ok := obj["metadata"].(map[string]interface{}); ok {
+		if ann, ok := meta["annotations"].(map[string]interface{}); ok {
+			delete(ann, lastAppliedAnnotation)
+		}
+	}
+}
+
+// Build 由变更前后的原始对象生成快照记录（before/after 为 nil 分别表示创建与删除）。
+// 差异在脱敏前计算，因此 Secret 的值变化仍会体现为一条差异，但不会记录具体内容。
+func Build(clusterID uint, gvr schema.GroupVersionResource, namespace, name string, before, after map[string]interface{}) (*models.OperationLogSnapshot, error) {
+	before, after = Clean(before), Clean(after)
+	changes := Diff(before, after)
+
+	snap := &models.OperationLogSnapshot{
+		ClusterID: clusterID,
+		Group:     gvr.Group,
+		Version:   gvr.Version,
+		Resource:  gvr.Resource,
+		Namespace: namespace,
+		Name:      name,
+	}
+	for _, obj := range []map[string]interface{}{after, before} {
+		if kind, ok := obj["kind"].(string); ok && snap.Kind == "" {
+			snap.Kind = kind
+		}
+	}
+	if isSecret(before) || isSecret(after) {
+		snap.Redacted = true
+		for _, obj := range []map[string]interface{}{before, after} {
+			if obj != nil {
+				redactObject(obj)
+			}
+		}
+		changes = redactChanges(changes)
+	}
+
+	var err error
+	if snap.Before, err = marshalObject(before); err != nil {
+		return nil, err
+	}
+	if snap.After, err = marshalObject(after); err != nil {
+		return nil, err
+	}
+	diff, err := json.Marshal(changes)
+	if err != nil {
+		return nil, fmt.Errorf("序列化差异失败: %w", err)
+	}
+	snap.Diff = string(diff)
+	return snap, nil
+}
+
+func marshalObject(obj map[string]interface{}) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return "", fmt.Errorf("序列化对象快照失败: %w", err)
+	}
+	return string(data), nil
+}
+
+func unmarshalObject(data string) (map[string]interface{}, error) {
+	if data == "" {
+		return nil, nil
+	}
+	// 与 API 返回的对象一致，整数解码为 int64 而非 float64
+	var obj map[string]interface{}
+	if err := utiljson.Unmarshal([]byte(data), &obj); err != nil {
+		return nil, fmt.Errorf("解析对象快照失败: %w", err)
+	}
+	return obj, nil
+}
+
+// GVR 快照对应的资源
+func GVR(snap *models.OperationLogSnapshot) schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: snap.Group, Version: snap.Version, Resource: snap.Resource}
+}
+
+func resourceClient(dyn dynamic.Interface, gvr schema.GroupVersionResource, namespace string) dynamic.ResourceInterface {
+	if namespace == "" {
+		return dyn.Resource(gvr)
+	}
+	return dyn.Resource(gvr).Namespace(namespace)
+}
+
+// Capture 读取对象当前状态，对象不存在时返回 nil
+func Capture(ctx context.Context, dyn dynamic.Interface, gvr schema.GroupVersionResource, namespace, name string) (map[string]interface{}, error) {
+	obj, err := resourceClient(dyn, gvr, namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return obj.Object, nil
+}
+
+// Drift 返回对象当前状态相对快照变更后状态的差异（忽略控制器自动写入的注解），
+// 非空表示该变更之后对象又被修改过，直接回滚会覆盖后续修改
+func Drift(snap *models.OperationLogSnapshot, current map[string]interface{}) ([]Change, error) {
+	after, err := unmarshalObject(snap.After)
+	if err != nil {
+		return nil, err
+	}
+	current = Clean(current)
+	for _, obj := range []map[string]interface{}{after, current} {
+		if meta, ok := obj["metadata"].(map[string]interface{}); ok {
+			if ann, ok := meta["annotations"].(map[string]interface{}); ok {
+				for _, key := range controllerAnnotations {
+					delete(ann, key)
+				}
+				if len(ann) == 0 {
+					delete(meta, "annotations")
+				}
+			}
+		}
+	}
+	return Diff(after, current), nil
+}
+
+// Revert 将对象恢复到快照的变更前状态：
+// 变更前不存在则删除对象；对象已被删除则重新创建；否则以当前 resourceVersion 覆盖更新
+func Revert(ctx context.Context, dyn dynamic.Interface, snap *models.OperationLogSnapshot, current map[string]interface{}) error {
+	if snap.Redacted {
+		return fmt.Errorf("快照包含脱敏数据，无法撤销")
+	}
+	client := resourceClient(dyn, GVR(snap), snap.Namespace)
+
+	before, err := unmarshalObject(snap.Before)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		if current == nil {
+			return nil
+		}
+		err := client.Delete(ctx, snap.Name, metav1.DeleteOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	obj := &unstructured.Unstructured{Object: before}
+	if current == nil {
+		_, err = client.Create(ctx, obj, metav1.CreateOptions{})
+		return err
+	}
+	obj.SetResourceVersion((&unstructured.Unstructured{Object: current}).GetResourceVersion())
+	_, err = client.Update(ctx, obj, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,129 @@
+package resourcesnapshot
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var gvrDeployments = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+func deployment(replicas int64, image, rv string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "web",
+			"namespace":       "default",
+			"resourceVersion": rv,
+			"uid":             "u-1",
+			"managedFields":   []interface{}{map[string]interface{}{"manager": "kubectl"}},
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{map[string]interface{}{"name": "web", "image": image}},
+				},
+			},
+		},
+		"status": map[string]interface{}{"readyReplicas": replicas},
+	}
+}
+
+func TestBuildDiffIgnoresServerFields(t *testing.T) {
+	snap, err := Build(1, gvrDeployments, "default", "web", deployment(1, "nginx:1.25", "10"), deployment(3, "nginx:1.27", "11"))
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	var changes []Change
+	if err := json.Unmarshal([]byte(snap.Diff), &changes); err != nil {
+		t.Fatalf("decode diff: %v", err)
+	}
+	want := map[string]bool{"/spec/replicas": true, "/spec/template/spec/containers/0/image": true}
+	if len(changes) != len(want) {
+		t.Fatalf("unexpected changes: %s", snap.Diff)
+	}
+	for _, c := range changes {
+		if !want[c.Path] || c.Op != OpReplace {
+			t.Errorf("unexpected change %+v", c)
+		}
+	}
+	if snap.Kind != "Deployment" || snap.Redacted || strings.Contains(snap.Before, "managedFields") || strings.Contains(snap.After, "status") {
+		t.Fatalf("unexpected snapshot: %+v", snap)
+	}
+}
+
+func TestBuildRedactsSecret(t *testing.T) {
+	secret := func(value string) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]interface{}{
+				"name":        "db",
+				"namespace":   "default",
+				"annotations": map[string]interface{}{lastAppliedAnnotation: `{"data":{"password":"` + value + `"}}`},
+			},
+			"data": map[string]interface{}{"password": value, "user": "YWRtaW4="},
+		}
+	}
+	snap, err := Build(1, schema.GroupVersionResource{Version: "v1", Resource: "secrets"}, "default", "db", secret("b2xk"), secret("bmV3"))
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	for _, field := range []string{snap.Before, snap.After, snap.Diff} {
+		if strings.Contains(field, "b2xk") || strings.Contains(field, "bmV3") || strings.Contains(field, "YWRtaW4=") {
+			t.Fatalf("secret value leaked: %s", field)
+		}
+	}
+	if !snap.Redacted || !strings.Contains(snap.Diff, "/data/password") || snap.Revertible() {
+		t.Fatalf("expected redacted, non-revertible snapshot with password change: %+v", snap)
+	}
+}
+
+func TestRevertRestoresBeforeState(t *testing.T) {
+	before, after := deployment(1, "nginx:1.25", "10"), deployment(3, "nginx:1.25", "11")
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvrDeployments: "DeploymentList"},
+		&unstructured.Unstructured{Object: after})
+	ctx := context.Background()
+
+	snap, err := Build(1, gvrDeployments, "default", "web", before, after)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	current, err := Capture(ctx, dyn, gvrDeployments, "default", "web")
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if drift, _ := Drift(snap, current); len(drift) != 0 {
+		t.Fatalf("unexpected drift: %+v", drift)
+	}
+	if err := Revert(ctx, dyn, snap, current); err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+	current, _ = Capture(ctx, dyn, gvrDeployments, "default", "web")
+	if replicas, _, _ := unstructured.NestedInt64(current, "spec", "replicas"); replicas != 1 {
+		t.Fatalf("expected replicas restored to 1, got %d", replicas)
+	}
+
+	// 撤销删除：对象不存在时按变更前状态重新创建
+	deleted, _ := Build(1, gvrDeployments, "default", "web", after, nil)
+	_ = dyn.Resource(gvrDeployments).Namespace("default").Delete(ctx, "web", metav1.DeleteOptions{})
+	if err := Revert(ctx, dyn, deleted, nil); err != nil {
+		t.Fatalf("revert delete: %v", err)
+	}
+	if current, _ = Capture(ctx, dyn, gvrDeployments, "default", "web"); current == nil {
+		t.Fatal("expected deployment recreated")
+	}
+	if drift, _ := Drift(deleted, current); len(drift) == 0 {
+		t.Fatal("expected drift once the deleted object exists again")
+	}
+}
@@ -4,8 +4,21 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 )
 
+// LanguageKey 上下文中的用户语言偏好（由认证后的中间件设置）
+const LanguageKey = "language"
+
+// Language 当前请求的响应语言：优先使用用户偏好，其次按 Accept-Language 协商
+func Language(c *gin.Context) string {
+	if lang := errcode.NormalizeLanguage(c.GetString(LanguageKey)); lang != "" {
+		return lang
+	}
+	return errcode.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+}
+
 // ErrorBody 统一错误响应体
 type ErrorBody struct {
 	Error ErrorDetail `json:"error"`
@@ -66,11 +79,28 @@ func NoContent(c *gin.Context) {
 // ---- 错误响应 ----
 
 // Error 返回自定义状态码 + 结构化错误
+// 文案命中错误码目录时按请求语言翻译（尚未迁移到 Fail 的调用方）
 func Error(c *gin.Context, status int, code, message string) {
+	message = errcode.Translate(Language(c), message)
 	c.JSON(status, ErrorBody{Error: ErrorDetail{Code: code, Message: message}})
 	c.Abort()
 }
 
+// Fail 返回业务错误：*errcode.Error 按其错误码、状态码与请求语言的文案返回，其他错误按 500 返回
+func Fail(c *gin.Context, err error) {
+	e, ok := errcode.As(err)
+	if !ok {
+		e = errcode.Wrap(err, errcode.Internal)
+	}
+	c.JSON(e.Status(), ErrorBody{Error: ErrorDetail{Code: string(e.Code), Message: e.Message(Language(c))}})
+	c.Abort()
+}
+
+// FailCode 按错误码返回错误
+func FailCode(c *gin.Context, code errcode.Code, args ...interface{}) {
+	Fail(c, errcode.New(code, args...))
+}
+
 // BadRequest 400
 func BadRequest(c *gin.Context, msg string) {
 	Error(c, http.StatusBadRequest, "BAD_REQUEST", msg)
@@ -0,0 +1,83 @@
+package response
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// legacyHelpers 以文案为参数的错误响应函数及文案参数的位置
+var legacyHelpers = map[string]int{
+	"BadRequest":         1,
+	"Unauthorized":       1,
+	"Forbidden":          1,
+	"NotFound":           1,
+	"Conflict":           1,
+	"InternalError":      1,
+	"ServiceUnavailable": 1,
+	"Error":              3,
+}
+
+// TestNoLiteralErrorMessages 错误文案需登记到 errcode 目录并通过 Fail/FailCode 返回，
+// 禁止直接向旧的错误响应函数传入字面量文案（含拼接与 fmt.Sprintf）或 err.Error()，否则无法按语言输出
+func TestNoLiteralErrorMessages(t *testing.T) {
+	fset := token.NewFileSet()
+	err := filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "response" {
+				return true
+			}
+			if i, ok := legacyHelpers[sel.Sel.Name]; ok && i < len(call.Args) && literalMessage(call.Args[i]) {
+				t.Errorf("%s: response.%s 使用了字面量文案或 err.Error()，请返回 errcode 错误后使用 response.Fail/FailCode",
+					fset.Position(call.Pos()), sel.Sel.Name)
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// literalMessage 表达式是否为未登记的文案：字符串字面量、含字面量的拼接、fmt.Sprintf 或 err.Error()
+func literalMessage(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return e.Kind == token.STRING
+	case *ast.BinaryExpr:
+		return literalMessage(e.X) || literalMessage(e.Y)
+	case *ast.ParenExpr:
+		return literalMessage(e.X)
+	case *ast.CallExpr:
+		if sel, ok := e.Fun.(*ast.SelectorExpr); ok {
+			if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "fmt" && sel.Sel.Name == "Sprintf" {
+				return true
+			}
+			// 错误原文未经错误码翻译
+			if sel.Sel.Name == "Error" && len(e.Args) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -5,17 +5,23 @@ import (
 	"io/fs"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/handlers"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
+	"github.com/clay-wangzhi/KubePolaris/internal/portforward"
 	"github.com/clay-wangzhi/KubePolaris/internal/response"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/internal/sshvault"
+	"github.com/clay-wangzhi/KubePolaris/internal/terminalhub"
+	"github.com/clay-wangzhi/KubePolaris/internal/terminalreplay"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
 )
 
@@ -31,8 +37,29 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 	// 	gin.SetMode(gin.ReleaseMode)
 	// }
 
+	// 审计哈希链：操作日志与终端审计记录逐条入链，未单独配置密钥时使用 JWT Secret
+	chainKey := cfg.AuditChain.Key
+	if chainKey == "" {
+		logger.Warn("未设置 AUDIT_CHAIN_KEY，审计哈希链使用 JWT Secret 计算")
+		chainKey = cfg.JWT.Secret
+	}
+	auditChainSvc := services.NewAuditChainService(db, chainKey)
+	go auditChainSvc.StartAnchorWorker(time.Duration(cfg.AuditChain.AnchorIntervalMinutes) * time.Minute)
+
+	// 审计外送：操作日志与终端命令经发件箱批量发送到 SIEM 等外部目标
+	auditExportSvc := services.NewAuditExportService(db)
+	go auditExportSvc.StartDispatcher(2 * time.Second)
+
+	// 事件通知：订阅的平台事件写入投递记录，后台发送到 Webhook、IM 机器人与邮件并失败重试
+	notificationSvc := services.NewNotificationService(db)
+	go notificationSvc.StartWorker(5*time.Second, cfg.Notify.DeliveryRetainDays)
+	if cfg.Notify.ClusterProbeSeconds > 0 {
+		go services.NewClusterHealthMonitor(db, notificationSvc).Start(time.Duration(cfg.Notify.ClusterProbeSeconds) * time.Second)
+	}
+
 	// 创建操作审计日志服务
-	opLogSvc := services.NewOperationLogService(db)
+	opLogSvc := services.NewOperationLogService(db, auditChainSvc, auditExportSvc)
+	opLogSvc.SetNotifier(notificationSvc)
 
 	// 全局中间件：建议引入 RequestID + 结构化日志 + 统一恢复
 	r.Use(
@@ -41,7 +68,7 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 		gin.Logger(),                        // 可替换为 zap/logrus 结构化日志中间件
 		middleware.CORS(),                   // TODO: 从 cfg 读取允许的 Origin/Methods/Headers
 		middleware.OperationAudit(opLogSvc), // 操作审计中间件（记录所有非GET请求）
-		gzip.Gzip(gzip.DefaultCompression, gzip.WithExcludedPaths([]string{"/ws/"})), // Gzip 压缩（排除 WebSocket）
+		gzip.Gzip(gzip.DefaultCompression, gzip.WithExcludedPaths([]string{"/ws/", "/proxy/"})), // Gzip 压缩（排除 WebSocket 与端口转发代理）
 		// middleware.RateLimit() // TODO: 关键接口限流
 	)
 
@@ -57,9 +84,10 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 	// 统一的 Service 实例，避免重复创建
 	clusterSvc := services.NewClusterService(db)
 	prometheusSvc := services.NewPrometheusService()
-	auditSvc := services.NewAuditService(db)           // 审计服务
-	argoCDSvc := services.NewArgoCDService(db)         // ArgoCD 服务
-	permissionSvc := services.NewPermissionService(db) // 权限服务
+	auditSvc := services.NewAuditService(db, auditChainSvc, auditExportSvc) // 审计服务
+	argoCDSvc := services.NewArgoCDService(db)                              // ArgoCD 服务
+	permissionSvc := services.NewPermissionService(db)                      // 权限服务
+	auditSvc.SetNotifier(notificationSvc)                                   // 打开终端时产生通知事件
 
 	// 初始化 Grafana 服务（始终创建实例，从数据库读取配置，env 仅控制代理和自动同步）
 	grafanaSettingSvc := services.NewGrafanaSettingService(db)
@@ -98,25 +126,89 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 	// /api/v1
 	api := r.Group("/api/v1")
 
+	// 用户偏好（语言），认证后的请求按偏好输出错误文案
+	userPrefSvc := services.NewUserPreferenceService(db)
+
 	// Auth 仅开放登录与登出，其余走受保护分组
 	auth := api.Group("/auth")
 	{
 		authSvc := services.NewAuthService(db, cfg.JWT.Secret, cfg.JWT.ExpireTime)
-		authHandler := handlers.NewAuthHandler(authSvc, opLogSvc)
+		authHandler := handlers.NewAuthHandler(authSvc, opLogSvc, userPrefSvc)
 		auth.POST("/login", authHandler.Login)
 		auth.POST("/logout", authHandler.Logout)
 		auth.GET("/status", authHandler.GetAuthStatus) // 获取认证状态（无需登录）
 		// /me 必须带 Auth
 		auth.GET("/me", middleware.AuthRequired(cfg.JWT.Secret), authHandler.GetProfile)
 		auth.POST("/change-password", middleware.AuthRequired(cfg.JWT.Secret), authHandler.ChangePassword)
+		auth.PUT("/preferences", middleware.AuthRequired(cfg.JWT.Secret), authHandler.UpdatePreferences)
 	}
 
+	// K8s API Server 审计 Webhook 接收（API Server 以集群的接收令牌认证，不走用户登录）
+	k8sAuditSvc := services.NewK8sAuditService(db)
+	go k8sAuditSvc.StartWorker(30*time.Second, cfg.K8sAudit.RetainDays)
+	k8sAuditHandler := handlers.NewK8sAuditHandler(k8sAuditSvc, clusterSvc)
+	api.POST("/k8s-audit/clusters/:clusterID/events", k8sAuditHandler.ReceiveEvents)
+
 	// 创建权限中间件（在受保护路由和 WebSocket 路由中共用）
 	permMiddleware := middleware.NewPermissionMiddleware(permissionSvc)
+	policySvc := services.NewPolicyService(db)                       // 细粒度权限策略服务
+	commandPolicySvc := services.NewTerminalCommandPolicyService(db) // 终端命令策略服务
+	freezeSvc := services.NewFreezeService(db, opLogSvc)             // 变更冻结日历服务
+	changeRequestSvc := services.NewChangeRequestService(db)         // 变更双人审批服务
+	liveHub := terminalhub.NewHub(auditSvc.RecordSessionEventAsync)  // 在线终端会话（旁观、协同、强制终止）
+
+	// 端口转发网关：活跃转发只存在于本进程，启动时结束上次运行遗留的记录
+	portForwardSvc := services.NewPortForwardService(db)
+	if err := portForwardSvc.CloseStaleSessions(); err != nil {
+		logger.Error("清理遗留端口转发记录失败: %v", err)
+	}
+	portForwardMgr := portforward.NewManager(portforward.Limits{
+		MaxPerUser: cfg.PortForward.MaxPerUser,
+		DefaultTTL: time.Duration(cfg.PortForward.DefaultTTLMinutes) * time.Minute,
+		MaxTTL:     time.Duration(cfg.PortForward.MaxTTLMinutes) * time.Minute,
+	}, portForwardSvc)
+	go portForwardMgr.StartReaper(time.Minute)
+	portForwardHandler := handlers.NewPortForwardHandler(db, clusterSvc, k8sMgr, portForwardMgr, portForwardSvc, cfg.PortForward.ProxyBaseURL)
+
+	// 节点 SSH 凭据库：凭据加密存储，未单独配置密钥时使用 JWT Secret
+	credentialKey := cfg.SSHVault.CredentialKey
+	if credentialKey == "" {
+		logger.Warn("未设置 SSH_VAULT_CREDENTIAL_KEY，SSH 凭据使用 JWT Secret 派生的密钥加密")
+		credentialKey = cfg.JWT.Secret
+	}
+	credentialCipher, err := sshvault.NewCipher(credentialKey)
+	if err != nil {
+		logger.Fatal("SSH 凭据加密初始化失败: %v", err)
+	}
+	sshVaultSvc := services.NewSSHVaultService(db, credentialCipher, time.Duration(cfg.SSHVault.CertTTLMinutes)*time.Minute)
+
+	// 终端录像存储（本地目录或 S3）及保留策略
+	replayStorage, err := terminalreplay.NewStorageFromConfig(cfg.Terminal)
+	if err != nil {
+		logger.Error("终端录像存储初始化失败，已禁用录像: %v", err)
+	}
+	if replayStorage != nil {
+		go replayStorage.StartRetentionWorker(auditSvc, terminalreplay.RetentionPolicy{
+			MaxAge:        time.Duration(cfg.Terminal.ReplayRetain.Days) * 24 * time.Hour,
+			MaxTotalBytes: cfg.Terminal.ReplayRetain.MaxTotalMB << 20,
+		}, time.Hour)
+	}
+
+	// 审计记录保留策略：过期记录归档后清理；录像存储未启用时保持接口为 nil
+	var replayStore services.ReplayStore
+	if replayStorage != nil {
+		replayStore = replayStorage
+	}
+	auditRetentionSvc := services.NewAuditRetentionService(db, auditChainSvc, replayStore, services.AuditRetentionPolicy{
+		OperationLogDays:    cfg.AuditRetain.OperationLogDays,
+		TerminalSessionDays: cfg.AuditRetain.TerminalSessionDays,
+		TerminalCommandDays: cfg.AuditRetain.TerminalCommandDays,
+	}, cfg.AuditRetain.ArchiveDir)
+	go auditRetentionSvc.StartWorker(time.Duration(cfg.AuditRetain.IntervalHours) * time.Hour)
 
 	// 受保护的业务路由
 	protected := api.Group("")
-	protected.Use(middleware.AuthRequired(cfg.JWT.Secret))
+	protected.Use(middleware.AuthRequired(cfg.JWT.Secret), middleware.UserLanguage(userPrefSvc))
 	{
 		// users - 用户管理（仅平台管理员）
 		userSvc := services.NewUserService(db)
@@ -148,8 +240,12 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 
 			// 动态 cluster 子分组（需要集群权限检查）
 			cluster := clusters.Group("/:clusterID")
-			cluster.Use(permMiddleware.ClusterAccessRequired()) // 启用集群权限检查
-			cluster.Use(permMiddleware.AutoWriteCheck())        // 自动检查写权限（POST/PUT/DELETE需要非只读权限）
+			cluster.Use(permMiddleware.ClusterAccessRequired())      // 启用集群权限检查
+			cluster.Use(middleware.TenantScope(db))                  // 租户成员收敛到本租户命名空间
+			cluster.Use(middleware.PolicyEnforcement(policySvc))     // 细粒度权限策略检查（拒绝优先）
+			cluster.Use(permMiddleware.AutoWriteCheck())             // 自动检查写权限（POST/PUT/DELETE需要非只读权限）
+			cluster.Use(middleware.ChangeApproval(changeRequestSvc)) // 命中审批策略的危险操作转为待审批变更
+			cluster.Use(middleware.FreezeEnforcement(freezeSvc))     // 变更冻结窗口内拒绝写操作（可填写理由紧急放行）
 			{
 				cluster.GET("", clusterHandler.GetCluster)
 				cluster.GET("/status", clusterHandler.GetClusterStatus)
@@ -157,6 +253,8 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 				cluster.GET("/metrics", clusterHandler.GetClusterMetrics)
 				cluster.GET("/events", clusterHandler.GetClusterEvents)
 				cluster.DELETE("", clusterHandler.DeleteCluster)
+				cluster.PUT("/labels", middleware.PlatformAdminRequired(db), clusterHandler.UpdateClusterLabels)           // 集群标签（仅平台管理员）
+				cluster.PUT("/node-terminal", middleware.PlatformAdminRequired(db), clusterHandler.UpdateNodeTerminalMode) // 节点终端方式（仅平台管理员）
 
 				// namespaces 子分组
 				namespaceHandler := handlers.NewNamespaceHandler(clusterSvc, k8sMgr)
@@ -237,6 +335,21 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 					pods.DELETE("/:namespace/:name", podHandler.DeletePod)
 					pods.GET("/:namespace/:name/logs", podHandler.GetPodLogs)
 					pods.GET("/:namespace/:name/metrics", monitoringHandler.GetPodMetrics)
+					pods.GET("/:namespace/:name/files/download",
+						permMiddleware.NamespaceAccessRequired(),
+						permMiddleware.ActionRequired("pod:download"),
+						podHandler.DownloadPodFile,
+					)
+					pods.POST("/:namespace/:name/files/upload",
+						permMiddleware.NamespaceAccessRequired(),
+						permMiddleware.ActionRequired("pod:upload"),
+						podHandler.UploadPodFile,
+					)
+					pods.POST("/:namespace/:name/portforward",
+						permMiddleware.NamespaceAccessRequired(),
+						permMiddleware.ActionRequired("pod:portforward"),
+						portForwardHandler.CreatePodPortForward,
+					)
 				}
 
 				// Deployment 子分组
@@ -370,6 +483,11 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 					svcGroup.GET("/:namespace/:name/yaml", serviceHandler.GetServiceYAML)
 					svcGroup.GET("/:namespace/:name/endpoints", serviceHandler.GetServiceEndpoints)
 					svcGroup.DELETE("/:namespace/:name", serviceHandler.DeleteService)
+					svcGroup.POST("/:namespace/:name/portforward",
+						permMiddleware.NamespaceAccessRequired(),
+						permMiddleware.ActionRequired("pod:portforward"),
+						portForwardHandler.CreateServicePortForward,
+					)
 					svcGroup.POST("/yaml/apply", resourceYAMLHandler.ApplyServiceYAML)
 				}
 
@@ -480,6 +598,7 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 
 				// O&M - 监控中心（运维）
 				omSvc := services.NewOMService(prometheusSvc, monitoringConfigSvc)
+				omSvc.SetNotifier(notificationSvc)
 				omHandler := handlers.NewOMHandler(clusterSvc, omSvc, k8sMgr)
 				om := cluster.Group("/om")
 				{
@@ -490,6 +609,17 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 			}
 		}
 
+		// cluster-groups - 按标签选择器定义的集群分组（查看对所有登录用户开放，维护仅平台管理员）
+		clusterGroupHandler := handlers.NewClusterGroupHandler(services.NewClusterGroupService(db))
+		clusterGroups := protected.Group("/cluster-groups")
+		{
+			clusterGroups.GET("", clusterGroupHandler.ListClusterGroups)
+			clusterGroups.GET("/:id", clusterGroupHandler.GetClusterGroup)
+			clusterGroups.POST("", middleware.PlatformAdminRequired(db), clusterGroupHandler.CreateClusterGroup)
+			clusterGroups.PUT("/:id", middleware.PlatformAdminRequired(db), clusterGroupHandler.UpdateClusterGroup)
+			clusterGroups.DELETE("/:id", middleware.PlatformAdminRequired(db), clusterGroupHandler.DeleteClusterGroup)
+		}
+
 		// overview - 总览大盘
 		overview := protected.Group("/overview")
 		{
@@ -517,12 +647,23 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 		audit.Use(middleware.PlatformAdminRequired(db))
 		{
 			// 终端会话审计（保持不变）
-			terminalAuditHandler := handlers.NewAuditHandler(db, cfg)
+			terminalAuditHandler := handlers.NewAuditHandler(db, cfg, replayStorage)
 			audit.GET("/terminal/sessions", terminalAuditHandler.GetTerminalSessions)
 			audit.GET("/terminal/sessions/:sessionId", terminalAuditHandler.GetTerminalSession)
 			audit.GET("/terminal/sessions/:sessionId/replay", terminalAuditHandler.GetTerminalSessionReplay)
 			audit.GET("/terminal/sessions/:sessionId/commands", terminalAuditHandler.GetTerminalCommands)
 			audit.GET("/terminal/stats", terminalAuditHandler.GetTerminalStats)
+			audit.GET("/terminal/search", terminalAuditHandler.SearchTerminalReplays)
+			audit.POST("/terminal/sessions/:sessionId/reindex", terminalAuditHandler.ReindexTerminalSession)
+
+			// 在线终端会话：旁观者列表、事件记录、强制终止
+			terminalLiveHandler := handlers.NewTerminalLiveHandler(db, liveHub, auditSvc)
+			audit.GET("/terminal/live", terminalLiveHandler.ListLiveSessions)
+			audit.GET("/terminal/sessions/:sessionId/events", terminalLiveHandler.GetSessionEvents)
+			audit.POST("/terminal/sessions/:sessionId/kill", terminalLiveHandler.KillSession)
+
+			// 端口转发记录
+			audit.GET("/port-forwards", portForwardHandler.GetPortForwardHistory)
 
 			// 操作日志审计（新增）
 			opLogHandler := handlers.NewOperationLogHandler(opLogSvc)
@@ -531,8 +672,45 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 			audit.GET("/operations/stats", opLogHandler.GetOperationLogStats)
 			audit.GET("/modules", opLogHandler.GetModules)
 			audit.GET("/actions", opLogHandler.GetActions)
+
+			// 撤销变更（按操作日志快照恢复对象）
+			opSnapshotHandler := handlers.NewOperationSnapshotHandler(opLogSvc, clusterSvc, freezeSvc, policySvc, changeRequestSvc, k8sMgr)
+			audit.POST("/operations/:id/snapshots/:snapshotId/revert", opSnapshotHandler.RevertSnapshot)
+
+			// 审计哈希链校验与锚定
+			auditChainHandler := handlers.NewAuditChainHandler(auditChainSvc)
+			audit.GET("/chain/verify", auditChainHandler.VerifyChain)
+			audit.POST("/chain/anchor", auditChainHandler.AnchorChain)
+
+			// 审计外送目标（syslog / Webhook / 文件）
+			auditSinkHandler := handlers.NewAuditSinkHandler(auditExportSvc)
+			audit.GET("/sinks", auditSinkHandler.ListSinks)
+			audit.POST("/sinks", auditSinkHandler.CreateSink)
+			audit.GET("/sinks/:id", auditSinkHandler.GetSink)
+			audit.PUT("/sinks/:id", auditSinkHandler.UpdateSink)
+			audit.DELETE("/sinks/:id", auditSinkHandler.DeleteSink)
+			audit.POST("/sinks/:id/test", auditSinkHandler.TestSink)
+
+			// 审计记录保留清理与用户数据导出、匿名化
+			auditRetentionHandler := handlers.NewAuditRetentionHandler(auditRetentionSvc)
+			audit.GET("/retention/policy", auditRetentionHandler.GetPolicy)
+			audit.GET("/retention/runs", auditRetentionHandler.ListRuns)
+			audit.POST("/retention/run", auditRetentionHandler.RunRetention)
+			auditUserDataHandler := handlers.NewAuditUserDataHandler(services.NewAuditUserDataService(db, auditChainSvc, replayStore))
+			audit.GET("/users/:id/export", auditUserDataHandler.ExportUserData)
+			audit.POST("/users/:id/pseudonymize", auditUserDataHandler.PseudonymizeUser)
+
+			// K8s API Server 审计事件与对象变更时间线
+			audit.GET("/k8s/clusters/:clusterID/webhook", k8sAuditHandler.GetWebhook)
+			audit.PUT("/k8s/clusters/:clusterID/webhook", k8sAuditHandler.ConfigureWebhook)
+			audit.GET("/k8s/events", k8sAuditHandler.ListEvents)
+			audit.GET("/k8s/timeline", k8sAuditHandler.GetObjectTimeline)
 		}
 
+		// 当前用户的活跃端口转发
+		protected.GET("/port-forwards", portForwardHandler.ListPortForwards)
+		protected.DELETE("/port-forwards/:id", portForwardHandler.ClosePortForward)
+
 		// monitoring templates
 		monitoringHandler := handlers.NewMonitoringHandler(monitoringConfigSvc, prometheusSvc)
 		protected.GET("/monitoring/templates", monitoringHandler.GetMonitoringTemplates)
@@ -551,6 +729,15 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 			systemSettings.GET("/ssh/config", systemSettingHandler.GetSSHConfig)
 			systemSettings.PUT("/ssh/config", systemSettingHandler.UpdateSSHConfig)
 			systemSettings.GET("/ssh/credentials", systemSettingHandler.GetSSHCredentials)
+			// 节点 SSH 凭据配置与主机密钥
+			sshVaultHandler := handlers.NewSSHVaultHandler(sshVaultSvc)
+			systemSettings.GET("/ssh/profiles", sshVaultHandler.ListProfiles)
+			systemSettings.POST("/ssh/profiles", sshVaultHandler.CreateProfile)
+			systemSettings.PUT("/ssh/profiles/:id", sshVaultHandler.UpdateProfile)
+			systemSettings.DELETE("/ssh/profiles/:id", sshVaultHandler.DeleteProfile)
+			systemSettings.GET("/ssh/host-keys", sshVaultHandler.ListHostKeys)
+			systemSettings.POST("/ssh/host-keys/:id/accept", sshVaultHandler.AcceptHostKey)
+			systemSettings.DELETE("/ssh/host-keys/:id", sshVaultHandler.DeleteHostKey)
 			// Grafana 配置
 			systemSettings.GET("/grafana/config", systemSettingHandler.GetGrafanaConfig)
 			systemSettings.PUT("/grafana/config", systemSettingHandler.UpdateGrafanaConfig)
@@ -559,18 +746,45 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 			systemSettings.POST("/grafana/sync-dashboards", systemSettingHandler.SyncGrafanaDashboards)
 			systemSettings.GET("/grafana/datasource-status", systemSettingHandler.GetGrafanaDataSourceStatus)
 			systemSettings.POST("/grafana/sync-datasources", systemSettingHandler.SyncGrafanaDataSources)
+
+			// 事件通知渠道、订阅与投递记录
+			notificationHandler := handlers.NewNotificationHandler(notificationSvc)
+			systemSettings.GET("/notifications/config", notificationHandler.GetConfig)
+			systemSettings.PUT("/notifications/config", notificationHandler.UpdateConfig)
+			systemSettings.GET("/notifications/events", notificationHandler.ListEvents)
+			systemSettings.POST("/notifications/channels/:name/test", notificationHandler.TestChannel)
+			systemSettings.GET("/notifications/deliveries", notificationHandler.ListDeliveries)
+			systemSettings.POST("/notifications/deliveries/:id/retry", notificationHandler.RetryDelivery)
 		}
 
 		// permissions - 权限管理
 		globalRbacSvc := services.NewRBACService()
 		permissionHandler := handlers.NewPermissionHandler(permissionSvc, clusterSvc, globalRbacSvc)
 		globalRbacHandler := handlers.NewRBACHandler(clusterSvc, globalRbacSvc, k8sMgr)
+		accessRequestSvc := services.NewAccessRequestService(db, globalRbacSvc, opLogSvc)
+		accessRequestHandler := handlers.NewAccessRequestHandler(accessRequestSvc)
+		policyHandler := handlers.NewPermissionPolicyHandler(policySvc, permissionSvc)
+		accessReviewHandler := handlers.NewAccessReviewHandler(services.NewAccessReviewService(db, permissionSvc, globalRbacSvc))
+		terminalCommandRuleHandler := handlers.NewTerminalCommandRuleHandler(commandPolicySvc)
+		go accessRequestSvc.StartExpiryWorker(time.Minute) // 临时提权到期回收
 		permissions := protected.Group("/permissions")
 		{
 			// 当前用户权限查询（任意登录用户可访问）
 			permissions.GET("/my-permissions", permissionHandler.GetMyPermissions)
 			permissions.GET("/types", permissionHandler.GetPermissionTypes)
 
+			// 临时提权申请（任意登录用户可申请，审批组成员可审批）
+			accessRequests := permissions.Group("/access-requests")
+			{
+				accessRequests.GET("", accessRequestHandler.ListAccessRequests)
+				accessRequests.POST("", accessRequestHandler.CreateAccessRequest)
+				accessRequests.GET("/:id", accessRequestHandler.GetAccessRequest)
+				accessRequests.POST("/:id/approve", accessRequestHandler.ApproveAccessRequest)
+				accessRequests.POST("/:id/reject", accessRequestHandler.RejectAccessRequest)
+				accessRequests.POST("/:id/cancel", accessRequestHandler.CancelAccessRequest)
+				accessRequests.POST("/:id/revoke", accessRequestHandler.RevokeAccessRequest)
+			}
+
 			// 以下接口需要平台管理员权限
 			permAdmin := permissions.Group("")
 			permAdmin.Use(middleware.PlatformAdminRequired(db))
@@ -581,6 +795,43 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 				// 用户列表（用于权限分配）
 				permAdmin.GET("/users", permissionHandler.ListUsers)
 
+				// 临时提权配置
+				permAdmin.GET("/jit-config", accessRequestHandler.GetConfig)
+				permAdmin.PUT("/jit-config", accessRequestHandler.UpdateConfig)
+
+				// 细粒度权限策略
+				policies := permAdmin.Group("/policies")
+				{
+					policies.GET("", policyHandler.ListPolicies)
+					policies.POST("", policyHandler.CreatePolicy)
+					policies.GET("/actions", policyHandler.GetPolicyActions)
+					policies.GET("/explain", policyHandler.ExplainPolicy)
+					policies.GET("/:id", policyHandler.GetPolicy)
+					policies.PUT("/:id", policyHandler.UpdatePolicy)
+					policies.DELETE("/:id", policyHandler.DeletePolicy)
+				}
+
+				// 授权复核（访问审计报表、确认/撤销、导出）
+				accessReview := permAdmin.Group("/access-review")
+				{
+					accessReview.GET("", accessReviewHandler.GetAccessReviewReport)
+					accessReview.GET("/export", accessReviewHandler.ExportAccessReviewReport)
+					accessReview.GET("/decisions", accessReviewHandler.ListAccessReviews)
+					accessReview.POST("/attest", accessReviewHandler.AttestAccess)
+					accessReview.POST("/revoke", accessReviewHandler.RevokeAccess)
+				}
+
+				// 终端命令策略（命令执行前拦截）
+				terminalRules := permAdmin.Group("/terminal-command-rules")
+				{
+					terminalRules.GET("", terminalCommandRuleHandler.ListRules)
+					terminalRules.POST("", terminalCommandRuleHandler.CreateRule)
+					terminalRules.GET("/check", terminalCommandRuleHandler.CheckCommand)
+					terminalRules.GET("/:id", terminalCommandRuleHandler.GetRule)
+					terminalRules.PUT("/:id", terminalCommandRuleHandler.UpdateRule)
+					terminalRules.DELETE("/:id", terminalCommandRuleHandler.DeleteRule)
+				}
+
 				// 用户组管理
 				userGroups := permAdmin.Group("/user-groups")
 				{
@@ -606,6 +857,105 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 			}
 		}
 
+		// freeze - 变更冻结日历
+		freezeHandler := handlers.NewFreezeHandler(freezeSvc)
+		freeze := protected.Group("/freeze")
+		{
+			// 即将到来的冻结窗口（任意登录用户可查询）
+			freeze.GET("/upcoming", freezeHandler.ListUpcoming)
+
+			// 以下接口需要平台管理员权限
+			freezeAdmin := freeze.Group("")
+			freezeAdmin.Use(middleware.PlatformAdminRequired(db))
+			{
+				freezeAdmin.GET("/calendars", freezeHandler.ListCalendars)
+				freezeAdmin.POST("/calendars", freezeHandler.CreateCalendar)
+				freezeAdmin.GET("/calendars/:id", freezeHandler.GetCalendar)
+				freezeAdmin.PUT("/calendars/:id", freezeHandler.UpdateCalendar)
+				freezeAdmin.DELETE("/calendars/:id", freezeHandler.DeleteCalendar)
+				freezeAdmin.POST("/calendars/:id/windows", freezeHandler.CreateWindow)
+				freezeAdmin.PUT("/windows/:id", freezeHandler.UpdateWindow)
+				freezeAdmin.DELETE("/windows/:id", freezeHandler.DeleteWindow)
+				freezeAdmin.GET("/overrides", freezeHandler.ListOverrides)
+			}
+		}
+
+		// change-requests - 变更双人审批（申请人查看/撤回，审批用户组成员批准/拒绝）
+		changeRequestHandler := handlers.NewChangeRequestHandler(changeRequestSvc)
+		go changeRequestSvc.StartExpiryWorker(time.Minute) // 超过审批有效期的变更置为过期
+		changeRequests := protected.Group("/change-requests")
+		{
+			changeRequests.GET("", changeRequestHandler.ListChangeRequests)
+			changeRequests.GET("/:id", changeRequestHandler.GetChangeRequest)
+			changeRequests.POST("/:id/approve", changeRequestHandler.ApproveChangeRequest)
+			changeRequests.POST("/:id/reject", changeRequestHandler.RejectChangeRequest)
+			changeRequests.POST("/:id/cancel", changeRequestHandler.CancelChangeRequest)
+		}
+		changePolicies := protected.Group("/change-approval-policies")
+		changePolicies.Use(middleware.PlatformAdminRequired(db))
+		{
+			changePolicies.GET("", changeRequestHandler.ListPolicies)
+			changePolicies.POST("", changeRequestHandler.CreatePolicy)
+			changePolicies.GET("/:id", changeRequestHandler.GetPolicy)
+			changePolicies.PUT("/:id", changeRequestHandler.UpdatePolicy)
+			changePolicies.DELETE("/:id", changeRequestHandler.DeletePolicy)
+		}
+
+		// reports - 定时报表（按 Cron 汇总总览、健康诊断、告警与操作统计，通过邮件渠道发送）
+		reportOverviewSvc := services.NewOverviewService(db, clusterSvc, k8sMgr, prometheusSvc, monitoringConfigSvc,
+			services.NewAlertManagerConfigService(db), services.NewAlertManagerService())
+		reportSvc := services.NewReportService(db, reportOverviewSvc, services.NewOMService(prometheusSvc, monitoringConfigSvc),
+			opLogSvc, notificationSvc, k8sMgr)
+		reportHandler := handlers.NewReportHandler(reportSvc)
+		go reportSvc.StartScheduler(time.Minute) // 每分钟检查到期的报表
+		reports := protected.Group("/reports")
+		reports.Use(middleware.PlatformAdminRequired(db))
+		{
+			reports.GET("/sections", reportHandler.ListSections)
+			reports.GET("/schedules", reportHandler.ListSchedules)
+			reports.POST("/schedules", reportHandler.CreateSchedule)
+			reports.GET("/schedules/:id", reportHandler.GetSchedule)
+			reports.PUT("/schedules/:id", reportHandler.UpdateSchedule)
+			reports.DELETE("/schedules/:id", reportHandler.DeleteSchedule)
+			reports.GET("/schedules/:id/preview", reportHandler.PreviewSchedule)
+			reports.POST("/schedules/:id/run", reportHandler.RunSchedule)
+			reports.GET("/deliveries", reportHandler.ListDeliveries)
+			reports.GET("/deliveries/:id", reportHandler.GetDelivery)
+		}
+
+		// tenants - 多租户管理（租户维护仅平台管理员，成员/授权/配额/审计开放给租户管理员）
+		tenantSvc := services.NewTenantService(db, permissionSvc, globalRbacSvc)
+		tenantHandler := handlers.NewTenantHandler(db, tenantSvc, opLogSvc)
+		tenants := protected.Group("/tenants")
+		{
+			tenants.GET("/mine", tenantHandler.GetMyTenant)
+
+			tenantAdmin := tenants.Group("")
+			tenantAdmin.Use(middleware.PlatformAdminRequired(db))
+			{
+				tenantAdmin.GET("", tenantHandler.ListTenants)
+				tenantAdmin.POST("", tenantHandler.CreateTenant)
+				tenantAdmin.GET("/:id", tenantHandler.GetTenant)
+				tenantAdmin.PUT("/:id", tenantHandler.UpdateTenant)
+				tenantAdmin.DELETE("/:id", tenantHandler.DeleteTenant)
+				tenantAdmin.POST("/:id/namespaces", tenantHandler.AddTenantNamespace)
+				tenantAdmin.DELETE("/:id/namespaces/:nsId", tenantHandler.RemoveTenantNamespace)
+			}
+
+			tenantScoped := tenants.Group("/:id")
+			tenantScoped.Use(middleware.TenantAdminRequired(db, tenantSvc))
+			{
+				tenantScoped.GET("/members", tenantHandler.ListTenantMembers)
+				tenantScoped.POST("/members", tenantHandler.AddTenantMember)
+				tenantScoped.DELETE("/members/:userId", tenantHandler.RemoveTenantMember)
+				tenantScoped.GET("/permissions", tenantHandler.ListTenantPermissions)
+				tenantScoped.POST("/permissions", tenantHandler.CreateTenantPermission)
+				tenantScoped.DELETE("/permissions/:permissionId", tenantHandler.DeleteTenantPermission)
+				tenantScoped.GET("/quotas", tenantHandler.GetTenantQuotas)
+				tenantScoped.GET("/audit/operations", tenantHandler.GetTenantOperationLogs)
+			}
+		}
+
 		// 集群级权限查询
 		protected.GET("/clusters/:clusterID/my-permissions", permissionHandler.GetMyClusterPermission)
 
@@ -623,6 +973,7 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 		aiChatHandler := handlers.NewAIChatHandler(db, clusterSvc, k8sMgr)
 		aiChat := clusters.Group("/:clusterID/ai")
 		aiChat.Use(permMiddleware.ClusterAccessRequired())
+		aiChat.Use(middleware.TenantScope(db))
 		{
 			aiChat.POST("/chat", aiChatHandler.Chat)
 		}
@@ -630,14 +981,15 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 
 	// WebSocket：建议也加认证
 	ws := r.Group("/ws")
-	ws.Use(middleware.AuthRequired(cfg.JWT.Secret))
+	ws.Use(middleware.AuthRequired(cfg.JWT.Secret), middleware.UserLanguage(userPrefSvc))
 	{
 		// 终端处理器（注入审计服务）
-		replayDir := cfg.Terminal.ReplayDir
-		kctl := handlers.NewKubectlTerminalHandler(clusterSvc, auditSvc)
-		ssh := handlers.NewSSHHandler(auditSvc, replayDir)
-		podTerminal := handlers.NewPodTerminalHandler(clusterSvc, auditSvc, k8sMgr, replayDir)
-		kubectlPod := handlers.NewKubectlPodTerminalHandler(clusterSvc, auditSvc, k8sMgr, replayDir)
+		kctl := handlers.NewKubectlTerminalHandler(clusterSvc, auditSvc, commandPolicySvc, liveHub, k8sMgr, replayStorage, cfg.Terminal.KubectlShellTools, cfg.Terminal.KubectlShellUser)
+		ssh := handlers.NewSSHHandler(clusterSvc, auditSvc, commandPolicySvc, liveHub, k8sMgr, replayStorage, sshVaultSvc)
+		podTerminal := handlers.NewPodTerminalHandler(clusterSvc, auditSvc, commandPolicySvc, liveHub, k8sMgr, replayStorage)
+		kubectlPod := handlers.NewKubectlPodTerminalHandler(clusterSvc, auditSvc, commandPolicySvc, liveHub, k8sMgr, replayStorage)
+		debugTerminal := handlers.NewDebugTerminalHandler(clusterSvc, auditSvc, commandPolicySvc, liveHub, k8sMgr, replayStorage, cfg.Terminal.DebugImages, cfg.Terminal.NodeShellImage)
+		terminalLive := handlers.NewTerminalLiveHandler(db, liveHub, auditSvc)
 		podHandler := handlers.NewPodHandler(db, cfg, clusterSvc, k8sMgr)
 		logCenterHandler := handlers.NewLogCenterHandler(clusterSvc, k8sMgr)
 		arthasHandler := handlers.NewArthasHandler(db, cfg, clusterSvc, k8sMgr, auditSvc)
@@ -645,11 +997,19 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 		// 节点 SSH 终端（需要平台管理员权限）
 		ws.GET("/ssh/terminal", middleware.PlatformAdminRequired(db), ssh.SSHConnect)
 
+		// 旁观在线终端会话（需要平台管理员权限，协同操作需会话所有者同意）
+		ws.GET("/audit/terminal/sessions/:sessionId/watch", middleware.PlatformAdminRequired(db), terminalLive.WatchSession)
+
+		// 端口转发 TCP 隧道（仅转发所有者）
+		ws.GET("/port-forwards/:id/tunnel", portForwardHandler.HandleTunnel)
+
 		// 集群相关的 WebSocket 路由（需要集群权限检查）
 		wsCluster := ws.Group("/clusters/:clusterID")
-		wsCluster.Use(permMiddleware.ClusterAccessRequired()) // 启用集群权限检查
+		wsCluster.Use(permMiddleware.ClusterAccessRequired())  // 启用集群权限检查
+		wsCluster.Use(middleware.TenantScope(db))              // 租户成员收敛到本租户命名空间
+		wsCluster.Use(middleware.PolicyEnforcement(policySvc)) // 细粒度权限策略检查
 		{
-			// 集群级 kubectl 终端（旧方案：本地执行）
+			// 集群级 kubectl 终端（服务端 PTY 受限 shell）
 			wsCluster.GET("/terminal", kctl.HandleKubectlTerminal)
 
 			// 集群级 kubectl 终端（新方案：Pod 模式，支持 tab 补全）
@@ -658,6 +1018,20 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 			// Pod 终端：使用 kubectl exec 连接到 Pod
 			wsCluster.GET("/pods/:namespace/:name/terminal", podTerminal.HandlePodTerminal)
 
+			// 调试终端：Pod 临时调试容器 / 节点特权调试 Pod
+			wsCluster.GET("/pods/:namespace/:name/debug",
+				permMiddleware.NamespaceAccessRequired(),
+				permMiddleware.ActionRequired("pod:debug"),
+				debugTerminal.HandlePodDebug,
+			)
+			wsCluster.GET("/nodes/:name/debug",
+				permMiddleware.ActionRequired("node:debug"),
+				debugTerminal.HandleNodeDebug,
+			)
+
+			// 节点终端（Pod 方式：特权 Pod + nsenter，需集群设置为 pod 方式，仅平台管理员）
+			wsCluster.GET("/nodes/:name/terminal", middleware.PlatformAdminRequired(db), debugTerminal.HandleNodeShell)
+
 			// Pod 日志流式传输
 			wsCluster.GET("/pods/:namespace/:name/logs", podHandler.StreamPodLogs)
 
@@ -674,12 +1048,18 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) (*gin.Engine, *
 		}
 	}
 
+	// 端口转发 HTTP 反向代理（凭据由建立转发的接口下发，位于路径首段；建议配置独立域名 port_forward.proxy_base_url）
+	r.Any("/proxy/port-forwards/:id/*path", portForwardHandler.HandleProxy)
+
 	// 嵌入前端静态文件服务
 	setupStatic(r)
 
 	// TODO:
 	// - 统一错误处理/响应格式中间件
 	// - OpenAPI/Swagger 文档路由（/swagger/*any）
+	// 已批准的变更由服务端经完整路由重放执行
+	changeRequestSvc.SetExecutor(r)
+
 	return r, k8sMgr
 }
 
@@ -705,7 +1085,7 @@ func setupStatic(r *gin.Engine) {
 
 		// API 和 WebSocket 路径返回 404
 		if strings.HasPrefix(path, "/api/") || strings.HasPrefix(path, "/ws/") {
-			response.NotFound(c, "not found")
+			response.FailCode(c, errcode.NotFound)
 			return
 		}
 
@@ -723,7 +1103,7 @@ func setupStatic(r *gin.Engine) {
 		// 回退到 index.html
 		content, err := staticFS.ReadFile("ui/dist/index.html")
 		if err != nil {
-			response.InternalError(c, "frontend not available")
+			response.FailCode(c, errcode.FrontendUnavailable)
 			return
 		}
 		c.Data(200, "text/html; charset=utf-8", content)
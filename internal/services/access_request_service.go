@@ -0,0 +1,509 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/constants"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"k8s.io/client-go/kubernetes"
+)
+
+// jitAccessConfigKey 临时提权配置在 system_settings 中的键
+const jitAccessConfigKey = "jit_access_config"
+
+// ErrNotAccessApprover 当前用户不在审批用户组中
+var ErrNotAccessApprover = errcode.New(errcode.AccessApproverRequired)
+
+// AccessRequestService 临时提权申请服务
+type AccessRequestService struct {
+	db       *gorm.DB
+	rbacSvc  *RBACService
+	opLogSvc *OperationLogService
+}
+
+// NewAccessRequestService 创建临时提权申请服务
+func NewAccessRequestService(db *gorm.DB, rbacSvc *RBACService, opLogSvc *OperationLogService) *AccessRequestService {
+	return &AccessRequestService{
+		db:       db,
+		rbacSvc:  rbacSvc,
+		opLogSvc: opLogSvc,
+	}
+}
+
+// ========== 配置 ==========
+
+// GetConfig 获取临时提权配置
+func (s *AccessRequestService) GetConfig() (*models.JITAccessConfig, error) {
+	var config models.JITAccessConfig
+	found, err := GetSystemSetting(s.db, jitAccessConfigKey, &config)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		defaultConfig := models.GetDefaultJITAccessConfig()
+		return &defaultConfig, nil
+	}
+	return &config, nil
+}
+
+// SaveConfig 保存临时提权配置
+func (s *AccessRequestService) SaveConfig(config *models.JITAccessConfig) error {
+	if config.Enabled && config.ApproverGroupID == 0 {
+		return errcode.New(errcode.AccessApproverGroupRequired)
+	}
+	if config.ApproverGroupID > 0 {
+		var group models.UserGroup
+		if err := s.db.First(&group, config.ApproverGroupID).Error; err != nil {
+			return errcode.New(errcode.ApproverGroupNotFound)
+		}
+	}
+	if config.MaxDurationMinutes <= 0 {
+		config.MaxDurationMinutes = models.GetDefaultJITAccessConfig().MaxDurationMinutes
+	}
+	for _, t := range config.AllowedTypes {
+		if t != models.PermissionTypeOps && t != models.PermissionTypeAdmin && t != models.PermissionTypeDev {
+			return errcode.New(errcode.UnsupportedAccessPermissionType, t)
+		}
+	}
+	return SaveSystemSetting(s.db, jitAccessConfigKey, "jit_access", config)
+}
+
+// ========== 申请 ==========
+
+// CreateAccessRequestRequest 创建临时提权申请请求
+type CreateAccessRequestRequest struct {
+	ClusterID       uint     `json:"cluster_id" binding:"required"`
+	PermissionType  string   `json:"permission_type" binding:"required"`
+	Namespaces      []string `json:"namespaces"`
+	Reason          string   `json:"reason" binding:"required"`
+	DurationMinutes int      `json:"duration_minutes" binding:"required,min=1"`
+}
+
+// CreateRequest 提交临时提权申请
+func (s *AccessRequestService) CreateRequest(userID uint, req *CreateAccessRequestRequest) (*models.AccessRequest, error) {
+	config, err := s.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !config.Enabled {
+		return nil, errcode.New(errcode.AccessRequestDisabled)
+	}
+
+	allowed := false
+	for _, t := range config.AllowedTypes {
+		if t == req.PermissionType {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, errcode.New(errcode.AccessPermissionTypeNotAllowed, req.PermissionType)
+	}
+	if req.DurationMinutes > config.MaxDurationMinutes {
+		return nil, errcode.New(errcode.AccessDurationTooLong, config.MaxDurationMinutes)
+	}
+
+	var cluster models.Cluster
+	if err := s.db.First(&cluster, req.ClusterID).Error; err != nil {
+		return nil, errcode.New(errcode.ClusterNotFound)
+	}
+
+	namespaces := req.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{"*"}
+	}
+	namespacesJSON, _ := json.Marshal(namespaces)
+
+	// 同一用户在同一集群只允许存在一个待审批申请
+	var pending int64
+	s.db.Model(&models.AccessRequest{}).
+		Where("user_id = ? AND cluster_id = ? AND status = ?", userID, req.ClusterID, models.AccessRequestStatusPending).
+		Count(&pending)
+	if pending > 0 {
+		return nil, errcode.New(errcode.AccessRequestPending)
+	}
+
+	accessReq := &models.AccessRequest{
+		UserID:          userID,
+		ClusterID:       req.ClusterID,
+		PermissionType:  req.PermissionType,
+		Namespaces:      string(namespacesJSON),
+		Reason:          req.Reason,
+		DurationMinutes: req.DurationMinutes,
+		Status:          models.AccessRequestStatusPending,
+	}
+	if err := s.db.Create(accessReq).Error; err != nil {
+		return nil, fmt.Errorf("创建申请失败: %w", err)
+	}
+
+	logger.Info("提交临时提权申请", "requestID", accessReq.ID, "userID", userID, "clusterID", req.ClusterID, "type", req.PermissionType)
+	return s.GetRequest(accessReq.ID)
+}
+
+// AccessRequestListRequest 申请列表查询条件
+type AccessRequestListRequest struct {
+	UserID    uint
+	ClusterID uint
+	Status    string
+	Page      int
+	PageSize  int
+}
+
+// ListRequests 获取申请列表
+func (s *AccessRequestService) ListRequests(req *AccessRequestListRequest) ([]models.AccessRequest, int64, error) {
+	query := s.db.Model(&models.AccessRequest{})
+	if req.UserID > 0 {
+		query = query.Where("user_id = ?", req.UserID)
+	}
+	if req.ClusterID > 0 {
+		query = query.Where("cluster_id = ?", req.ClusterID)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计申请数量失败: %w", err)
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+
+	var items []models.AccessRequest
+	err := query.Preload("User").Preload("Reviewer").Preload("Cluster").
+		Order("created_at DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&items).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("获取申请列表失败: %w", err)
+	}
+	return items, total, nil
+}
+
+// GetRequest 获取申请详情
+func (s *AccessRequestService) GetRequest(id uint) (*models.AccessRequest, error) {
+	var accessReq models.AccessRequest
+	if err := s.db.Preload("User").Preload("Reviewer").Preload("Cluster").First(&accessReq, id).Error; err != nil {
+		return nil, errcode.New(errcode.AccessRequestNotFound)
+	}
+	return &accessReq, nil
+}
+
+// IsApprover 判断用户是否为审批用户组成员
+func (s *AccessRequestService) IsApprover(userID uint) bool {
+	config, err := s.GetConfig()
+	if err != nil || config.ApproverGroupID == 0 {
+		return false
+	}
+	var count int64
+	s.db.Model(&models.UserGroupMember{}).
+		Where("user_id = ? AND user_group_id = ?", userID, config.ApproverGroupID).
+		Count(&count)
+	return count > 0
+}
+
+// Approve 审批通过，创建带过期时间的集群权限并同步 RBAC
+func (s *AccessRequestService) Approve(id, reviewerID uint, comment string) (*models.AccessRequest, error) {
+	if !s.IsApprover(reviewerID) {
+		return nil, ErrNotAccessApprover
+	}
+
+	var permission *models.ClusterPermission
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var accessReq models.AccessRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&accessReq, id).Error; err != nil {
+			return errcode.New(errcode.AccessRequestNotFound)
+		}
+		if accessReq.Status != models.AccessRequestStatusPending {
+			return errcode.New(errcode.AccessRequestNotPending, accessReq.Status)
+		}
+		if accessReq.UserID == reviewerID {
+			return errcode.New(errcode.AccessSelfApproval)
+		}
+
+		now := time.Now()
+		expiresAt := now.Add(time.Duration(accessReq.DurationMinutes) * time.Minute)
+		userID := accessReq.UserID
+		permission = &models.ClusterPermission{
+			ClusterID:      accessReq.ClusterID,
+			UserID:         &userID,
+			PermissionType: accessReq.PermissionType,
+			Namespaces:     accessReq.Namespaces,
+			ExpiresAt:      &expiresAt,
+		}
+		if err := tx.Create(permission).Error; err != nil {
+			return fmt.Errorf("创建临时权限失败: %w", err)
+		}
+
+		if err := tx.Model(&accessReq).Updates(map[string]interface{}{
+			"status":         models.AccessRequestStatusApproved,
+			"reviewer_id":    reviewerID,
+			"review_comment": comment,
+			"reviewed_at":    now,
+			"expires_at":     expiresAt,
+			"permission_id":  permission.ID,
+		}).Error; err != nil {
+			return err
+		}
+
+		// RBAC 同步失败时回滚审批，申请保持待审批，失败由操作审计记录
+		if err := s.ensureRBAC(tx, permission); err != nil {
+			return errcode.Wrap(err, errcode.AccessRBACSyncFailed)
+		}
+		return nil
+	})
+	if err != nil {
+		if errcode.Is(err, errcode.AccessRBACSyncFailed) {
+			logger.Error("同步临时权限 RBAC 失败，已回滚审批", "requestID", id, "reviewerID", reviewerID, "error", err)
+			// 清理可能已部分创建的绑定
+			go s.cleanupRBAC(permission)
+		}
+		return nil, err
+	}
+
+	logger.Info("临时提权申请已批准", "requestID", id, "reviewerID", reviewerID, "permissionID", permission.ID, "expiresAt", permission.ExpiresAt)
+	return s.GetRequest(id)
+}
+
+// Reject 拒绝申请
+func (s *AccessRequestService) Reject(id, reviewerID uint, comment string) (*models.AccessRequest, error) {
+	if !s.IsApprover(reviewerID) {
+		return nil, ErrNotAccessApprover
+	}
+
+	now := time.Now()
+	result := s.db.Model(&models.AccessRequest{}).
+		Where("id = ? AND status = ?", id, models.AccessRequestStatusPending).
+		Updates(map[string]interface{}{
+			"status":         models.AccessRequestStatusRejected,
+			"reviewer_id":    reviewerID,
+			"review_comment": comment,
+			"reviewed_at":    now,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("拒绝申请失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errcode.New(errcode.AccessRequestProcessed)
+	}
+
+	logger.Info("临时提权申请已拒绝", "requestID", id, "reviewerID", reviewerID)
+	return s.GetRequest(id)
+}
+
+// Cancel 申请人撤回待审批的申请
+func (s *AccessRequestService) Cancel(id, userID uint) error {
+	result := s.db.Model(&models.AccessRequest{}).
+		Where("id = ? AND user_id = ? AND status = ?", id, userID, models.AccessRequestStatusPending).
+		Update("status", models.AccessRequestStatusCanceled)
+	if result.Error != nil {
+		return fmt.Errorf("撤回申请失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errcode.New(errcode.AccessRequestProcessed)
+	}
+	return nil
+}
+
+// Revoke 提前撤销已生效的临时权限
+func (s *AccessRequestService) Revoke(id, operatorID uint, comment string) (*models.AccessRequest, error) {
+	accessReq, err := s.GetRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	if accessReq.Status != models.AccessRequestStatusApproved {
+		return nil, errcode.New(errcode.AccessRequestNotActive)
+	}
+	if accessReq.UserID != operatorID && !s.IsApprover(operatorID) {
+		return nil, ErrNotAccessApprover
+	}
+
+	if err := s.revokePermission(accessReq, models.AccessRequestStatusRevoked, comment); err != nil {
+		return nil, err
+	}
+
+	logger.Info("临时提权已撤销", "requestID", id, "operatorID", operatorID)
+	return s.GetRequest(id)
+}
+
+// ========== 到期回收 ==========
+
+// ExpireDue 回收所有已到期的临时权限，返回处理数量
+func (s *AccessRequestService) ExpireDue() int {
+	var due []models.AccessRequest
+	if err := s.db.Where("status = ? AND expires_at <= ?", models.AccessRequestStatusApproved, time.Now()).
+		Find(&due).Error; err != nil {
+		logger.Error("查询到期临时权限失败", "error", err)
+		return 0
+	}
+
+	expired := 0
+	for i := range due {
+		accessReq := &due[i]
+		err := s.revokePermission(accessReq, models.AccessRequestStatusExpired, "")
+		s.recordSystemOperation("access_request", accessReq.ID, accessReq.ClusterID, map[string]interface{}{
+			"user_id":         accessReq.UserID,
+			"permission_type": accessReq.PermissionType,
+			"permission_id":   accessReq.PermissionID,
+		}, err)
+		if err != nil {
+			logger.Error("回收到期临时权限失败", "requestID", accessReq.ID, "error", err)
+			continue
+		}
+		expired++
+	}
+
+	// 兜底：清理没有关联申请但已过期的临时权限
+	var orphans []models.ClusterPermission
+	if err := s.db.Where("expires_at IS NOT NULL AND expires_at <= ?", time.Now()).Find(&orphans).Error; err != nil {
+		logger.Error("查询到期临时权限失败", "error", err)
+		return expired
+	}
+	for i := range orphans {
+		orphan := &orphans[i]
+		err := s.db.Delete(orphan).Error
+		s.recordSystemOperation("cluster_permission", orphan.ID, orphan.ClusterID, map[string]interface{}{
+			"user_id":         orphan.UserID,
+			"permission_type": orphan.PermissionType,
+			"namespaces":      orphan.GetNamespaceList(),
+		}, err)
+		if err != nil {
+			logger.Error("删除到期临时权限失败", "permissionID", orphan.ID, "error", err)
+			continue
+		}
+		go s.cleanupRBAC(orphan)
+	}
+
+	return expired
+}
+
+// StartExpiryWorker 启动临时权限到期回收任务
+func (s *AccessRequestService) StartExpiryWorker(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	logger.Info("临时提权回收任务已启动", "interval", interval)
+
+	for range ticker.C {
+		if n := s.ExpireDue(); n > 0 {
+			logger.Info("已回收到期临时权限", "count", n)
+		}
+	}
+}
+
+// revokePermission 删除申请关联的临时权限并更新申请状态
+func (s *AccessRequestService) revokePermission(accessReq *models.AccessRequest, status, comment string) error {
+	var permission models.ClusterPermission
+	hasPermission := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if accessReq.PermissionID != nil {
+			if err := tx.First(&permission, *accessReq.PermissionID).Error; err == nil {
+				hasPermission = true
+				if err := tx.Delete(&permission).Error; err != nil {
+					return fmt.Errorf("删除临时权限失败: %w", err)
+				}
+			}
+		}
+		updates := map[string]interface{}{"status": status}
+		if comment != "" {
+			updates["review_comment"] = comment
+		}
+		return tx.Model(&models.AccessRequest{}).Where("id = ?", accessReq.ID).Updates(updates).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if hasPermission {
+		go s.cleanupRBAC(&permission)
+	}
+	return nil
+}
+
+// recordSystemOperation 记录到期回收任务的操作（无 HTTP 请求上下文）
+// 回收任务本身在后台运行，这里同步写入，确保每次删除都有审计记录
+func (s *AccessRequestService) recordSystemOperation(resourceType string, resourceID, clusterID uint, body map[string]interface{}, err error) {
+	if s.opLogSvc == nil {
+		return
+	}
+	entry := &LogEntry{
+		Username:     "system",
+		Method:       "SYSTEM",
+		Path:         "/internal/access-requests/expire",
+		Module:       constants.ModulePermission,
+		Action:       constants.ActionExpire,
+		ClusterID:    &clusterID,
+		ResourceType: resourceType,
+		ResourceName: strconv.FormatUint(uint64(resourceID), 10),
+		RequestBody:  body,
+		StatusCode:   200,
+		Success:      err == nil,
+	}
+	if err != nil {
+		entry.StatusCode = 500
+		entry.ErrorMessage = err.Error()
+	}
+	if recordErr := s.opLogSvc.Record(entry); recordErr != nil {
+		logger.Error("记录临时权限回收审计失败", "resourceType", resourceType, "resourceID", resourceID, "error", recordErr)
+	}
+}
+
+// ensureRBAC 为临时权限创建集群内 RBAC 资源
+// 在审批事务内调用，集群信息通过同一事务读取
+func (s *AccessRequestService) ensureRBAC(tx *gorm.DB, permission *models.ClusterPermission) error {
+	if s.rbacSvc == nil || permission.UserID == nil {
+		return nil
+	}
+	clientset, err := clusterClientsetByID(tx, permission.ClusterID)
+	if err != nil {
+		return err
+	}
+	config := &UserRBACConfig{
+		UserID:         *permission.UserID,
+		PermissionType: permission.PermissionType,
+		Namespaces:     permission.GetNamespaceList(),
+		ClusterRoleRef: permission.CustomRoleRef,
+	}
+	return s.rbacSvc.EnsureUserRBAC(clientset, config)
+}
+
+// cleanupRBAC 清理临时权限对应的集群内 RBAC 资源
+// 同一用户在该集群上同类型的常驻权限或其他临时权限仍在使用的绑定会被保留
+func (s *AccessRequestService) cleanupRBAC(permission *models.ClusterPermission) {
+	if s.rbacSvc == nil || permission.UserID == nil {
+		return
+	}
+	clientset, err := clusterClientsetByID(s.db, permission.ClusterID)
+	if err != nil {
+		logger.Error("创建 K8s 客户端失败，无法清理临时权限 RBAC", "clusterID", permission.ClusterID, "error", err)
+		return
+	}
+	if err := cleanupRevokedUserRBAC(s.db, s.rbacSvc, clientset, permission); err != nil {
+		logger.Error("清理临时权限 RBAC 失败", "permissionID", permission.ID, "error", err)
+	}
+}
+
+// clusterClientsetByID 根据集群 ID 创建 clientset
+func clusterClientsetByID(db *gorm.DB, clusterID uint) (*kubernetes.Clientset, error) {
+	var cluster models.Cluster
+	if err := db.First(&cluster, clusterID).Error; err != nil {
+		return nil, errcode.Wrap(err, errcode.ClusterNotFound)
+	}
+	k8sClient, err := NewK8sClientForCluster(&cluster)
+	if err != nil {
+		return nil, err
+	}
+	return k8sClient.GetClientset(), nil
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/constants"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+
+	"gorm.io/gorm"
+)
+
+func newAccessRequestTestService(t *testing.T) (*AccessRequestService, *gorm.DB, models.Cluster) {
+	t.Helper()
+	db := newAuditChainTestDB(t)
+	if err := db.AutoMigrate(&models.Cluster{}, &models.UserGroup{}, &models.UserGroupMember{},
+		&models.SystemSetting{}, &models.ClusterPermission{}, &models.AccessRequest{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	cluster := models.Cluster{Name: "prod-1", APIServer: "https://prod-1:6443"}
+	group := models.UserGroup{Name: "approvers"}
+	if err := db.Create(&cluster).Error; err != nil {
+		t.Fatalf("create cluster: %v", err)
+	}
+	if err := db.Create(&group).Error; err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	if err := db.Create(&models.UserGroupMember{UserGroupID: group.ID, UserID: 2}).Error; err != nil {
+		t.Fatalf("create member: %v", err)
+	}
+
+	svc := NewAccessRequestService(db, nil, nil)
+	if err := svc.SaveConfig(&models.JITAccessConfig{
+		Enabled:            true,
+		ApproverGroupID:    group.ID,
+		MaxDurationMinutes: 240,
+		AllowedTypes:       []string{models.PermissionTypeOps, models.PermissionTypeAdmin},
+	}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	return svc, db, cluster
+}
+
+// approveAccessRequest 以用户 1 提交、用户 2 审批一个限定命名空间的临时提权
+func approveAccessRequest(t *testing.T, svc *AccessRequestService, clusterID uint, permissionType string, namespaces ...string) (*models.AccessRequest, *models.ClusterPermission) {
+	t.Helper()
+	req, err := svc.CreateRequest(1, &CreateAccessRequestRequest{
+		ClusterID: clusterID, PermissionType: permissionType, Namespaces: namespaces,
+		Reason: "incident", DurationMinutes: 60,
+	})
+	if err != nil {
+		t.Fatalf("create request: %v", err)
+	}
+	if _, err := svc.Approve(req.ID, 1, ""); err == nil {
+		t.Fatal("requester must not approve own request")
+	}
+	approved, err := svc.Approve(req.ID, 2, "ok")
+	if err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if approved.Status != models.AccessRequestStatusApproved || approved.PermissionID == nil || approved.ExpiresAt == nil {
+		t.Fatalf("unexpected approved request: %+v", approved)
+	}
+
+	var permission models.ClusterPermission
+	if err := svc.db.First(&permission, *approved.PermissionID).Error; err != nil {
+		t.Fatalf("temporary permission not created: %v", err)
+	}
+	if permission.PermissionType != permissionType || !reflect.DeepEqual(permission.GetNamespaceList(), namespaces) || !permission.IsTemporary() {
+		t.Fatalf("unexpected temporary permission: %+v", permission)
+	}
+	return approved, &permission
+}
+
+func TestAccessRequestExpireKeepsPermanentBindings(t *testing.T) {
+	svc, db, cluster := newAccessRequestTestService(t)
+
+	userID := uint(1)
+	permanent := models.ClusterPermission{ClusterID: cluster.ID, UserID: &userID, PermissionType: models.PermissionTypeOps}
+	_ = permanent.SetNamespaceList([]string{"shop", "payment"})
+	if err := db.Create(&permanent).Error; err != nil {
+		t.Fatalf("create permanent permission: %v", err)
+	}
+
+	accessReq, temporary := approveAccessRequest(t, svc, cluster.ID, models.PermissionTypeOps, "payment", "billing")
+	if effective, err := NewPermissionService(db).GetUserClusterPermission(userID, cluster.ID); err != nil || effective.ID != temporary.ID {
+		t.Fatalf("active temporary grant should take precedence, got %+v, %v", effective, err)
+	}
+
+	// 未到期时不回收
+	if n := svc.ExpireDue(); n != 0 {
+		t.Fatalf("expected nothing to expire, got %d", n)
+	}
+	past := time.Now().Add(-time.Minute)
+	db.Model(&models.AccessRequest{}).Where("id = ?", accessReq.ID).Update("expires_at", past)
+	db.Model(&models.ClusterPermission{}).Where("id = ?", temporary.ID).Update("expires_at", past)
+	if n := svc.ExpireDue(); n != 1 {
+		t.Fatalf("expected 1 expired request, got %d", n)
+	}
+
+	got, err := svc.GetRequest(accessReq.ID)
+	if err != nil || got.Status != models.AccessRequestStatusExpired {
+		t.Fatalf("request should be expired, got %+v, %v", got, err)
+	}
+	if err := db.First(&models.ClusterPermission{}, temporary.ID).Error; err == nil {
+		t.Fatal("expired temporary permission should be deleted")
+	}
+
+	// payment 仍由常驻权限使用，只能清理 billing 的绑定
+	clusterBinding, namespaces, err := staleUserBindings(db, temporary)
+	if err != nil {
+		t.Fatalf("stale bindings: %v", err)
+	}
+	if clusterBinding || !reflect.DeepEqual(namespaces, []string{"billing"}) {
+		t.Fatalf("expected only billing binding to be stale, got cluster=%v namespaces=%v", clusterBinding, namespaces)
+	}
+	if effective, err := NewPermissionService(db).GetUserClusterPermission(userID, cluster.ID); err != nil || effective.ID != permanent.ID {
+		t.Fatalf("permanent grant should be effective again, got %+v, %v", effective, err)
+	}
+}
+
+func TestAccessRequestRevoke(t *testing.T) {
+	svc, db, cluster := newAccessRequestTestService(t)
+
+	userID := uint(1)
+	permanent := models.ClusterPermission{ClusterID: cluster.ID, UserID: &userID, PermissionType: models.PermissionTypeAdmin}
+	_ = permanent.SetNamespaceList([]string{"*"})
+	if err := db.Create(&permanent).Error; err != nil {
+		t.Fatalf("create permanent permission: %v", err)
+	}
+
+	accessReq, temporary := approveAccessRequest(t, svc, cluster.ID, models.PermissionTypeAdmin, "shop")
+	if _, err := svc.Revoke(accessReq.ID, 3, ""); err != ErrNotAccessApprover {
+		t.Fatalf("expected ErrNotAccessApprover, got %v", err)
+	}
+	got, err := svc.Revoke(accessReq.ID, 2, "done")
+	if err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if got.Status != models.AccessRequestStatusRevoked || got.ReviewComment != "done" {
+		t.Fatalf("unexpected revoked request: %+v", got)
+	}
+	if _, err := svc.Revoke(accessReq.ID, 2, ""); err == nil {
+		t.Fatal("revoked request must not be revoked twice")
+	}
+	if err := db.First(&models.ClusterPermission{}, temporary.ID).Error; err == nil {
+		t.Fatal("revoked temporary permission should be deleted")
+	}
+
+	// 常驻 admin 覆盖全部命名空间，使用固定 SA，临时权限的 RoleBinding 可以清理
+	clusterBinding, namespaces, err := staleUserBindings(db, temporary)
+	if err != nil {
+		t.Fatalf("stale bindings: %v", err)
+	}
+	if clusterBinding || !reflect.DeepEqual(namespaces, []string{"shop"}) {
+		t.Fatalf("expected shop binding to be stale, got cluster=%v namespaces=%v", clusterBinding, namespaces)
+	}
+}
+
+func TestAccessRequestApproveRollsBackOnRBACFailure(t *testing.T) {
+	svc, db, cluster := newAccessRequestTestService(t)
+	svc.rbacSvc = NewRBACService()
+
+	req, err := svc.CreateRequest(1, &CreateAccessRequestRequest{
+		ClusterID: cluster.ID, PermissionType: models.PermissionTypeOps, Reason: "incident", DurationMinutes: 60,
+	})
+	if err != nil {
+		t.Fatalf("create request: %v", err)
+	}
+	// 集群已被删除，无法创建客户端同步 RBAC
+	if err := db.Delete(&cluster).Error; err != nil {
+		t.Fatalf("delete cluster: %v", err)
+	}
+
+	if _, err := svc.Approve(req.ID, 2, "ok"); !errcode.Is(err, errcode.AccessRBACSyncFailed) {
+		t.Fatalf("expected AccessRBACSyncFailed, got %v", err)
+	}
+	var got models.AccessRequest
+	if err := db.First(&got, req.ID).Error; err != nil || got.Status != models.AccessRequestStatusPending || got.PermissionID != nil {
+		t.Fatalf("request should stay pending without a permission, got %+v, %v", got, err)
+	}
+	var count int64
+	db.Model(&models.ClusterPermission{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("temporary permission should be rolled back, got %d", count)
+	}
+}
+
+func TestAccessRequestExpireAuditsOrphanPermissions(t *testing.T) {
+	svc, db, cluster := newAccessRequestTestService(t)
+	svc.opLogSvc = NewOperationLogService(db, nil, nil)
+
+	userID := uint(1)
+	past := time.Now().Add(-time.Minute)
+	orphan := models.ClusterPermission{ClusterID: cluster.ID, UserID: &userID, PermissionType: models.PermissionTypeOps, ExpiresAt: &past}
+	_ = orphan.SetNamespaceList([]string{"shop"})
+	if err := db.Create(&orphan).Error; err != nil {
+		t.Fatalf("create orphan permission: %v", err)
+	}
+
+	if n := svc.ExpireDue(); n != 0 {
+		t.Fatalf("orphans are not counted as expired requests, got %d", n)
+	}
+	if err := db.First(&models.ClusterPermission{}, orphan.ID).Error; err == nil {
+		t.Fatal("expired orphan permission should be deleted")
+	}
+
+	var logs []models.OperationLog
+	db.Where("resource_type = ?", "cluster_permission").Find(&logs)
+	if len(logs) != 1 {
+		t.Fatalf("expected one audit record for the orphan, got %d", len(logs))
+	}
+	entry := logs[0]
+	if entry.Action != constants.ActionExpire || !entry.Success || entry.Username != "system" ||
+		entry.ResourceName != strconv.FormatUint(uint64(orphan.ID), 10) || entry.ClusterID == nil || *entry.ClusterID != cluster.ID {
+		t.Fatalf("unexpected audit record: %+v", entry)
+	}
+}
+
+func TestStaleUserBindingsClusterWide(t *testing.T) {
+	_, db, cluster := newAccessRequestTestService(t)
+	other := models.Cluster{Name: "dev-1", APIServer: "https://dev-1:6443", Labels: `{"env":"dev"}`}
+	db.Create(&other)
+
+	userID := uint(1)
+	revoked := &models.ClusterPermission{ID: 100, ClusterID: cluster.ID, UserID: &userID, PermissionType: models.PermissionTypeCustom}
+	_ = revoked.SetNamespaceList([]string{"*"})
+
+	clusterBinding, namespaces, err := staleUserBindings(db, revoked)
+	if err != nil || !clusterBinding || len(namespaces) != 0 {
+		t.Fatalf("cluster binding should be stale without other grants, got %v %v %v", clusterBinding, namespaces, err)
+	}
+
+	// 只作用于其他集群的选择器授权不影响清理
+	selector := models.ClusterPermission{UserID: &userID, PermissionType: models.PermissionTypeCustom, ClusterSelector: `{"env":"dev"}`}
+	_ = selector.SetNamespaceList([]string{"*"})
+	db.Create(&selector)
+	if clusterBinding, _, _ := staleUserBindings(db, revoked); !clusterBinding {
+		t.Fatal("selector grant on another cluster must not retain the cluster binding")
+	}
+
+	db.Model(&models.Cluster{}).Where("id = ?", cluster.ID).Update("labels", `{"env":"dev"}`)
+	if clusterBinding, _, _ := staleUserBindings(db, revoked); clusterBinding {
+		t.Fatal("matching selector grant should retain the cluster binding")
+	}
+}
@@ -0,0 +1,617 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// 授权复核标记
+const (
+	AccessReviewFlagInactive    = "inactive"    // 用户长期未登录
+	AccessReviewFlagRedundant   = "redundant"   // 授权被同一用户的其他授权完全覆盖
+	AccessReviewFlagOverlapping = "overlapping" // 同一集群存在多条授权
+	AccessReviewFlagProdAdmin   = "prod_admin"  // 生产集群上的管理员授权
+	AccessReviewFlagUnreviewed  = "unreviewed"  // 从未被复核确认
+)
+
+// 授权来源
+const (
+	AccessGrantSourceDirect = "direct"
+	AccessGrantSourceGroup  = "group"
+)
+
+// DefaultInactiveDays 默认的不活跃天数阈值
+const DefaultInactiveDays = 90
+
+// DefaultProductionSelector 默认的生产集群标签选择器
+const DefaultProductionSelector = "env=prod"
+
+// AccessReviewOptions 授权复核报表参数
+type AccessReviewOptions struct {
+	InactiveDays       int               // 超过该天数未登录视为不活跃
+	ProductionSelector map[string]string // 匹配生产集群的标签选择器
+	UserID             uint              // 仅查看指定用户（0 表示全部）
+	ClusterID          uint              // 仅查看涉及指定集群的授权（0 表示全部）
+	Flag               string            // 仅查看带指定标记的授权
+}
+
+// AccessReviewInput 生成报表所需的原始数据
+type AccessReviewInput struct {
+	Users       []models.User
+	Groups      []models.UserGroup
+	Members     []models.UserGroupMember
+	Permissions []models.ClusterPermission
+	Clusters    []models.Cluster
+	Reviews     []models.AccessReview
+}
+
+// EffectiveGrant 用户的一条有效授权
+type EffectiveGrant struct {
+	PermissionID    uint              `json:"permission_id"`
+	Source          string            `json:"source"` // direct, group
+	UserGroupID     *uint             `json:"user_group_id,omitempty"`
+	UserGroupName   string            `json:"user_group_name,omitempty"`
+	ClusterID       uint              `json:"cluster_id"`
+	ClusterName     string            `json:"cluster_name,omitempty"`
+	ClusterSelector map[string]string `json:"cluster_selector,omitempty"`
+	ClusterIDs      []uint            `json:"cluster_ids"` // 授权实际覆盖的集群
+	PermissionType  string            `json:"permission_type"`
+	CustomRoleRef   string            `json:"custom_role_ref,omitempty"`
+	Namespaces      []string          `json:"namespaces"`
+	ExpiresAt       *time.Time        `json:"expires_at,omitempty"`
+	LastDecision    string            `json:"last_decision,omitempty"`
+	LastReviewedAt  *time.Time        `json:"last_reviewed_at,omitempty"`
+	Flags           []string          `json:"flags"`
+}
+
+// AccessReviewUser 用户维度的复核条目
+type AccessReviewUser struct {
+	UserID         uint             `json:"user_id"`
+	Username       string           `json:"username"`
+	DisplayName    string           `json:"display_name"`
+	Status         string           `json:"status"`
+	LastLoginAt    *time.Time       `json:"last_login_at"`
+	DaysSinceLogin *int             `json:"days_since_login"` // 从未登录时为空
+	Flags          []string         `json:"flags"`
+	Grants         []EffectiveGrant `json:"grants"`
+}
+
+// AccessReviewSummary 复核报表汇总
+type AccessReviewSummary struct {
+	Users            int `json:"users"`
+	Grants           int `json:"grants"`
+	InactiveUsers    int `json:"inactive_users"`
+	RedundantGrants  int `json:"redundant_grants"`
+	OverlapGrants    int `json:"overlapping_grants"`
+	ProdAdminGrants  int `json:"prod_admin_grants"`
+	UnreviewedGrants int `json:"unreviewed_grants"`
+}
+
+// AccessReviewReport 授权复核报表
+type AccessReviewReport struct {
+	GeneratedAt        time.Time           `json:"generated_at"`
+	InactiveDays       int                 `json:"inactive_days"`
+	ProductionSelector map[string]string   `json:"production_selector"`
+	Summary            AccessReviewSummary `json:"summary"`
+	Users              []AccessReviewUser  `json:"users"`
+}
+
+// permissionTypeLevel 权限类型的高低顺序（custom 无法比较，返回 0）
+func permissionTypeLevel(permissionType string) int {
+	switch permissionType {
+	case models.PermissionTypeReadonly:
+		return 1
+	case models.PermissionTypeDev:
+		return 2
+	case models.PermissionTypeOps:
+		return 3
+	case models.PermissionTypeAdmin:
+		return 4
+	}
+	return 0
+}
+
+// grantCovers 判断授权 b 是否完全覆盖授权 a（权限不低于 a，且命名空间范围包含 a）
+func grantCovers(b, a *EffectiveGrant) bool {
+	if a.PermissionType == models.PermissionTypeCustom || b.PermissionType == models.PermissionTypeCustom {
+		if a.PermissionType != b.PermissionType || a.CustomRoleRef != b.CustomRoleRef {
+			return false
+		}
+	} else if permissionTypeLevel(b.PermissionType) < permissionTypeLevel(a.PermissionType) {
+		return false
+	}
+	// 临时授权不能覆盖长期授权
+	if b.ExpiresAt != nil && a.ExpiresAt == nil {
+		return false
+	}
+	for _, ns := range a.Namespaces {
+		if !namespacePatternCovered(b.Namespaces, ns) {
+			return false
+		}
+	}
+	return true
+}
+
+// namespacePatternCovered 判断命名空间（或前缀通配）是否被模式列表覆盖
+func namespacePatternCovered(patterns []string, namespace string) bool {
+	for _, p := range patterns {
+		if p == "*" || p == namespace {
+			return true
+		}
+		if strings.HasSuffix(p, "*") && strings.HasPrefix(strings.TrimSuffix(namespace, "*"), strings.TrimSuffix(p, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildAccessReviewReport 根据权限、用户组成员关系和登录数据生成授权复核报表
+func BuildAccessReviewReport(input *AccessReviewInput, opts AccessReviewOptions, now time.Time) *AccessReviewReport {
+	if opts.InactiveDays <= 0 {
+		opts.InactiveDays = DefaultInactiveDays
+	}
+
+	clusterByID := make(map[uint]*models.Cluster, len(input.Clusters))
+	for i := range input.Clusters {
+		clusterByID[input.Clusters[i].ID] = &input.Clusters[i]
+	}
+	groupNames := make(map[uint]string, len(input.Groups))
+	for _, g := range input.Groups {
+		groupNames[g.ID] = g.Name
+	}
+	userGroups := make(map[uint][]uint)
+	for _, m := range input.Members {
+		userGroups[m.UserID] = append(userGroups[m.UserID], m.UserGroupID)
+	}
+
+	// 每条授权 + 用户只保留最近一次复核结果
+	type reviewKey struct{ permissionID, userID uint }
+	latestReview := make(map[reviewKey]*models.AccessReview)
+	for i := range input.Reviews {
+		r := &input.Reviews[i]
+		key := reviewKey{r.PermissionID, r.UserID}
+		if prev, ok := latestReview[key]; !ok || r.CreatedAt.After(prev.CreatedAt) {
+			latestReview[key] = r
+		}
+	}
+
+	report := &AccessReviewReport{
+		GeneratedAt:        now,
+		InactiveDays:       opts.InactiveDays,
+		ProductionSelector: opts.ProductionSelector,
+		Users:              []AccessReviewUser{},
+	}
+
+	for _, user := range input.Users {
+		if opts.UserID != 0 && user.ID != opts.UserID {
+			continue
+		}
+
+		item := AccessReviewUser{
+			UserID:      user.ID,
+			Username:    user.Username,
+			DisplayName: user.DisplayName,
+			Status:      user.Status,
+			LastLoginAt: user.LastLoginAt,
+			Flags:       []string{},
+			Grants:      []EffectiveGrant{},
+		}
+		lastSeen := user.CreatedAt
+		if user.LastLoginAt != nil {
+			days := int(now.Sub(*user.LastLoginAt).Hours() / 24)
+			item.DaysSinceLogin = &days
+			lastSeen = *user.LastLoginAt
+		}
+		if now.Sub(lastSeen) > time.Duration(opts.InactiveDays)*24*time.Hour {
+			item.Flags = append(item.Flags, AccessReviewFlagInactive)
+		}
+
+		groups := make(map[uint]bool)
+		for _, gid := range userGroups[user.ID] {
+			groups[gid] = true
+		}
+
+		var grants []EffectiveGrant
+		for i := range input.Permissions {
+			p := &input.Permissions[i]
+			if p.ExpiresAt != nil && !p.ExpiresAt.After(now) {
+				continue
+			}
+			grant := EffectiveGrant{
+				PermissionID:   p.ID,
+				ClusterID:      p.ClusterID,
+				PermissionType: p.PermissionType,
+				CustomRoleRef:  p.CustomRoleRef,
+				Namespaces:     p.GetNamespaceList(),
+				ExpiresAt:      p.ExpiresAt,
+				Flags:          []string{},
+			}
+			switch {
+			case p.UserID != nil && *p.UserID == user.ID:
+				grant.Source = AccessGrantSourceDirect
+			case p.UserGroupID != nil && groups[*p.UserGroupID]:
+				grant.Source = AccessGrantSourceGroup
+				grant.UserGroupID = p.UserGroupID
+				grant.UserGroupName = groupNames[*p.UserGroupID]
+			default:
+				continue
+			}
+
+			if p.IsSelectorGrant() {
+				grant.ClusterSelector = p.GetClusterSelector()
+				for _, cluster := range input.Clusters {
+					if models.MatchLabels(grant.ClusterSelector, cluster.GetLabels()) {
+						grant.ClusterIDs = append(grant.ClusterIDs, cluster.ID)
+					}
+				}
+			} else {
+				grant.ClusterIDs = []uint{p.ClusterID}
+				if cluster, ok := clusterByID[p.ClusterID]; ok {
+					grant.ClusterName = cluster.Name
+				}
+			}
+			if grant.ClusterIDs == nil {
+				grant.ClusterIDs = []uint{}
+			}
+
+			if review, ok := latestReview[reviewKey{p.ID, user.ID}]; ok {
+				grant.LastDecision = review.Decision
+				reviewedAt := review.CreatedAt
+				grant.LastReviewedAt = &reviewedAt
+			} else {
+				grant.Flags = append(grant.Flags, AccessReviewFlagUnreviewed)
+			}
+
+			if grant.PermissionType == models.PermissionTypeAdmin && len(opts.ProductionSelector) > 0 {
+				for _, id := range grant.ClusterIDs {
+					if cluster, ok := clusterByID[id]; ok && models.MatchLabels(opts.ProductionSelector, cluster.GetLabels()) {
+						grant.Flags = append(grant.Flags, AccessReviewFlagProdAdmin)
+						break
+					}
+				}
+			}
+			grants = append(grants, grant)
+		}
+
+		flagOverlaps(grants)
+
+		for _, grant := range grants {
+			if opts.ClusterID != 0 && !containsUint(grant.ClusterIDs, opts.ClusterID) {
+				continue
+			}
+			if opts.Flag != "" && !containsString(grant.Flags, opts.Flag) && !containsString(item.Flags, opts.Flag) {
+				continue
+			}
+			item.Grants = append(item.Grants, grant)
+		}
+		if len(item.Grants) == 0 {
+			// 仅按用户标记（如 inactive）过滤时保留无授权的用户
+			userMatched := opts.ClusterID == 0 && (opts.Flag == "" || containsString(item.Flags, opts.Flag))
+			if !userMatched {
+				continue
+			}
+		}
+
+		report.Users = append(report.Users, item)
+		report.Summary.Users++
+		if containsString(item.Flags, AccessReviewFlagInactive) {
+			report.Summary.InactiveUsers++
+		}
+		for _, grant := range item.Grants {
+			report.Summary.Grants++
+			for _, flag := range grant.Flags {
+				switch flag {
+				case AccessReviewFlagRedundant:
+					report.Summary.RedundantGrants++
+				case AccessReviewFlagOverlapping:
+					report.Summary.OverlapGrants++
+				case AccessReviewFlagProdAdmin:
+					report.Summary.ProdAdminGrants++
+				case AccessReviewFlagUnreviewed:
+					report.Summary.UnreviewedGrants++
+				}
+			}
+		}
+	}
+
+	sort.Slice(report.Users, func(i, j int) bool { return report.Users[i].Username < report.Users[j].Username })
+	return report
+}
+
+// flagOverlaps 标记同一用户在相同集群上的重叠/冗余授权
+// 被其他授权在所有集群上完全覆盖的授权标记为 redundant；两条授权完全相同时只标记后一条
+func flagOverlaps(grants []EffectiveGrant) {
+	for i := range grants {
+		a := &grants[i]
+		if len(a.ClusterIDs) == 0 {
+			continue
+		}
+		overlapping := false
+		redundant := true
+		for _, clusterID := range a.ClusterIDs {
+			covered := false
+			for j := range grants {
+				if i == j || !containsUint(grants[j].ClusterIDs, clusterID) {
+					continue
+				}
+				overlapping = true
+				b := &grants[j]
+				if grantCovers(b, a) && (!grantCovers(a, b) || j < i) {
+					covered = true
+				}
+			}
+			if !covered {
+				redundant = false
+			}
+		}
+		if redundant && overlapping {
+			a.Flags = append(a.Flags, AccessReviewFlagRedundant)
+		} else if overlapping {
+			a.Flags = append(a.Flags, AccessReviewFlagOverlapping)
+		}
+	}
+}
+
+// containsUint 判断切片是否包含指定值
+func containsUint(list []uint, v uint) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString 判断切片是否包含指定字符串
+func containsString(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteAccessReviewCSV 以 CSV 格式导出复核报表（每条授权一行）
+func WriteAccessReviewCSV(w io.Writer, report *AccessReviewReport) error {
+	writer := csv.NewWriter(w)
+	header := []string{
+		"user_id", "username", "display_name", "status", "last_login_at", "days_since_login", "user_flags",
+		"permission_id", "source", "user_group", "cluster_id", "cluster_name", "cluster_selector",
+		"permission_type", "namespaces", "expires_at", "last_decision", "last_reviewed_at", "grant_flags",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	for _, user := range report.Users {
+		daysSinceLogin := ""
+		if user.DaysSinceLogin != nil {
+			daysSinceLogin = strconv.Itoa(*user.DaysSinceLogin)
+		}
+		userCols := []string{
+			strconv.FormatUint(uint64(user.UserID), 10), user.Username, user.DisplayName, user.Status,
+			formatTime(user.LastLoginAt), daysSinceLogin, strings.Join(user.Flags, ";"),
+		}
+		if len(user.Grants) == 0 {
+			row := append(append([]string{}, userCols...), make([]string, len(header)-len(userCols))...)
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, grant := range user.Grants {
+			selector := make([]string, 0, len(grant.ClusterSelector))
+			for k, v := range grant.ClusterSelector {
+				selector = append(selector, k+"="+v)
+			}
+			sort.Strings(selector)
+			row := append(append([]string{}, userCols...),
+				strconv.FormatUint(uint64(grant.PermissionID), 10),
+				grant.Source,
+				grant.UserGroupName,
+				strconv.FormatUint(uint64(grant.ClusterID), 10),
+				grant.ClusterName,
+				strings.Join(selector, ","),
+				grant.PermissionType,
+				strings.Join(grant.Namespaces, ";"),
+				formatTime(grant.ExpiresAt),
+				grant.LastDecision,
+				formatTime(grant.LastReviewedAt),
+				strings.Join(grant.Flags, ";"),
+			)
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ========== 复核服务 ==========
+
+// AccessReviewService 授权复核服务
+type AccessReviewService struct {
+	db            *gorm.DB
+	permissionSvc *PermissionService
+	rbacSvc       *RBACService
+}
+
+// NewAccessReviewService 创建授权复核服务
+func NewAccessReviewService(db *gorm.DB, permissionSvc *PermissionService, rbacSvc *RBACService) *AccessReviewService {
+	return &AccessReviewService{db: db, permissionSvc: permissionSvc, rbacSvc: rbacSvc}
+}
+
+// AccessReviewDecisionRequest 复核决定请求
+type AccessReviewDecisionRequest struct {
+	PermissionID uint   `json:"permission_id" binding:"required"`
+	UserID       uint   `json:"user_id" binding:"required"`
+	Comment      string `json:"comment"`
+}
+
+// GenerateReport 生成授权复核报表
+func (s *AccessReviewService) GenerateReport(opts AccessReviewOptions) (*AccessReviewReport, error) {
+	input := &AccessReviewInput{}
+	if err := s.db.Order("username ASC").Find(&input.Users).Error; err != nil {
+		return nil, fmt.Errorf("获取用户失败: %w", err)
+	}
+	if err := s.db.Find(&input.Groups).Error; err != nil {
+		return nil, fmt.Errorf("获取用户组失败: %w", err)
+	}
+	if err := s.db.Find(&input.Members).Error; err != nil {
+		return nil, fmt.Errorf("获取用户组成员失败: %w", err)
+	}
+	if err := s.db.Find(&input.Permissions).Error; err != nil {
+		return nil, fmt.Errorf("获取集群权限失败: %w", err)
+	}
+	if err := s.db.Select("id", "name", "labels").Find(&input.Clusters).Error; err != nil {
+		return nil, fmt.Errorf("获取集群失败: %w", err)
+	}
+	if err := s.db.Find(&input.Reviews).Error; err != nil {
+		return nil, fmt.Errorf("获取复核记录失败: %w", err)
+	}
+	return BuildAccessReviewReport(input, opts, time.Now()), nil
+}
+
+// ListReviews 获取复核记录
+func (s *AccessReviewService) ListReviews(page, pageSize int, userID uint) ([]models.AccessReview, int64, error) {
+	query := s.db.Model(&models.AccessReview{})
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	var reviews []models.AccessReview
+	err := query.Preload("User").Preload("Reviewer").
+		Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&reviews).Error
+	return reviews, total, err
+}
+
+// Attest 确认保留授权
+func (s *AccessReviewService) Attest(reviewerID uint, req *AccessReviewDecisionRequest) (*models.AccessReview, error) {
+	permission, groupID, err := s.resolveGrant(req.PermissionID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.record(permission, req, groupID, reviewerID, models.AccessReviewDecisionAttested)
+}
+
+// Revoke 撤销授权
+// 直接授权删除对应的 ClusterPermission；通过用户组继承的授权将用户移出该用户组
+func (s *AccessReviewService) Revoke(reviewerID uint, req *AccessReviewDecisionRequest) (*models.AccessReview, error) {
+	permission, groupID, err := s.resolveGrant(req.PermissionID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if groupID != nil {
+		if err := s.permissionSvc.RemoveUserFromGroup(req.UserID, *groupID); err != nil {
+			return nil, fmt.Errorf("移出用户组失败: %w", err)
+		}
+	} else {
+		if err := s.permissionSvc.DeleteClusterPermission(permission.ID); err != nil {
+			return nil, fmt.Errorf("删除授权失败: %w", err)
+		}
+		go s.cleanupRBAC(permission)
+	}
+
+	review, err := s.record(permission, req, groupID, reviewerID, models.AccessReviewDecisionRevoked)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("授权复核撤销: permissionID=%d, userID=%d, reviewerID=%d", permission.ID, req.UserID, reviewerID)
+	return review, nil
+}
+
+// resolveGrant 校验授权确实作用于该用户，返回授权来源的用户组（直接授权时为 nil）
+func (s *AccessReviewService) resolveGrant(permissionID, userID uint) (*models.ClusterPermission, *uint, error) {
+	var permission models.ClusterPermission
+	if err := s.db.First(&permission, permissionID).Error; err != nil {
+		return nil, nil, errcode.New(errcode.PermissionNotFound)
+	}
+	if permission.UserID != nil {
+		if *permission.UserID != userID {
+			return nil, nil, errcode.New(errcode.PermissionUserMismatch)
+		}
+		return &permission, nil, nil
+	}
+	if permission.UserGroupID != nil {
+		var count int64
+		s.db.Model(&models.UserGroupMember{}).
+			Where("user_id = ? AND user_group_id = ?", userID, *permission.UserGroupID).
+			Count(&count)
+		if count > 0 {
+			return &permission, permission.UserGroupID, nil
+		}
+	}
+	return nil, nil, errcode.New(errcode.PermissionUserMismatch)
+}
+
+// record 保存复核记录
+func (s *AccessReviewService) record(permission *models.ClusterPermission, req *AccessReviewDecisionRequest, groupID *uint, reviewerID uint, decision string) (*models.AccessReview, error) {
+	review := &models.AccessReview{
+		PermissionID:   permission.ID,
+		UserID:         req.UserID,
+		UserGroupID:    groupID,
+		ClusterID:      permission.ClusterID,
+		PermissionType: permission.PermissionType,
+		Decision:       decision,
+		ReviewerID:     reviewerID,
+		Comment:        req.Comment,
+	}
+	if err := s.db.Create(review).Error; err != nil {
+		return nil, fmt.Errorf("保存复核记录失败: %w", err)
+	}
+	return review, nil
+}
+
+// cleanupRBAC 清理被撤销授权在集群内的 RBAC 资源
+func (s *AccessReviewService) cleanupRBAC(permission *models.ClusterPermission) {
+	if s.rbacSvc == nil || permission.UserID == nil {
+		return
+	}
+	targets := []*models.ClusterPermission{permission}
+	if permission.IsSelectorGrant() {
+		targets = s.permissionSvc.BindSelectorPermission(permission)
+	}
+	for _, target := range targets {
+		clientset, err := clusterClientsetByID(s.db, target.ClusterID)
+		if err != nil {
+			logger.Error("创建 K8s 客户端失败，无法清理 RBAC: clusterID=%d, err=%v", target.ClusterID, err)
+			continue
+		}
+		if err := cleanupRevokedUserRBAC(s.db, s.rbacSvc, clientset, target); err != nil {
+			logger.Error("清理复核撤销授权 RBAC 失败: permissionID=%d, err=%v", target.ID, err)
+		}
+	}
+}
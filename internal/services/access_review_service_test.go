@@ -0,0 +1,127 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+func newAccessReviewInput(now time.Time) *AccessReviewInput {
+	aliceID, bobID := uint(1), uint(2)
+	groupID := uint(10)
+	recentLogin := now.Add(-24 * time.Hour)
+	oldLogin := now.Add(-200 * 24 * time.Hour)
+
+	nsPermission := func(p models.ClusterPermission, namespaces ...string) models.ClusterPermission {
+		_ = p.SetNamespaceList(namespaces)
+		return p
+	}
+
+	return &AccessReviewInput{
+		Users: []models.User{
+			{ID: aliceID, Username: "alice", LastLoginAt: &recentLogin},
+			{ID: bobID, Username: "bob", LastLoginAt: &oldLogin},
+		},
+		Groups:  []models.UserGroup{{ID: groupID, Name: "sre"}},
+		Members: []models.UserGroupMember{{UserID: aliceID, UserGroupID: groupID}},
+		Permissions: []models.ClusterPermission{
+			nsPermission(models.ClusterPermission{ID: 100, ClusterID: 1, UserID: &aliceID, PermissionType: models.PermissionTypeReadonly}, "team-a"),
+			nsPermission(models.ClusterPermission{ID: 101, ClusterID: 1, UserGroupID: &groupID, PermissionType: models.PermissionTypeAdmin}, "*"),
+			nsPermission(models.ClusterPermission{ID: 102, ClusterID: 2, UserID: &bobID, PermissionType: models.PermissionTypeDev}, "team-b"),
+		},
+		Clusters: []models.Cluster{
+			{ID: 1, Name: "prod-eu", Labels: `{"env":"prod"}`},
+			{ID: 2, Name: "dev", Labels: `{"env":"dev"}`},
+		},
+		Reviews: []models.AccessReview{
+			{PermissionID: 102, UserID: bobID, Decision: models.AccessReviewDecisionAttested, CreatedAt: now.Add(-time.Hour)},
+		},
+	}
+}
+
+func grantByPermission(user AccessReviewUser, permissionID uint) *EffectiveGrant {
+	for i := range user.Grants {
+		if user.Grants[i].PermissionID == permissionID {
+			return &user.Grants[i]
+		}
+	}
+	return nil
+}
+
+func TestBuildAccessReviewReportFlagsGrants(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := BuildAccessReviewReport(newAccessReviewInput(now), AccessReviewOptions{
+		InactiveDays:       90,
+		ProductionSelector: map[string]string{"env": "prod"},
+	}, now)
+
+	if len(report.Users) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(report.Users))
+	}
+	alice, bob := report.Users[0], report.Users[1]
+
+	inherited := grantByPermission(alice, 101)
+	if inherited == nil || inherited.Source != AccessGrantSourceGroup || inherited.UserGroupName != "sre" {
+		t.Fatalf("expected group-inherited grant for alice, got %+v", inherited)
+	}
+	if !containsString(inherited.Flags, AccessReviewFlagProdAdmin) {
+		t.Fatalf("admin grant on prod cluster should be flagged, got %v", inherited.Flags)
+	}
+	if !containsString(inherited.Flags, AccessReviewFlagOverlapping) {
+		t.Fatalf("covering grant should be flagged overlapping, got %v", inherited.Flags)
+	}
+	if direct := grantByPermission(alice, 100); direct == nil || !containsString(direct.Flags, AccessReviewFlagRedundant) {
+		t.Fatalf("readonly grant covered by group admin should be redundant, got %+v", direct)
+	}
+	if containsString(alice.Flags, AccessReviewFlagInactive) {
+		t.Fatal("alice logged in recently and should not be inactive")
+	}
+
+	if !containsString(bob.Flags, AccessReviewFlagInactive) {
+		t.Fatalf("bob should be inactive, got %v", bob.Flags)
+	}
+	if g := grantByPermission(bob, 102); g == nil || g.LastDecision != models.AccessReviewDecisionAttested || containsString(g.Flags, AccessReviewFlagUnreviewed) {
+		t.Fatalf("bob's grant should carry the attestation, got %+v", g)
+	}
+
+	if report.Summary.InactiveUsers != 1 || report.Summary.ProdAdminGrants != 1 || report.Summary.RedundantGrants != 1 {
+		t.Fatalf("unexpected summary: %+v", report.Summary)
+	}
+}
+
+func TestBuildAccessReviewReportFilterByFlag(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := BuildAccessReviewReport(newAccessReviewInput(now), AccessReviewOptions{
+		Flag: AccessReviewFlagInactive,
+	}, now)
+
+	if len(report.Users) != 1 || report.Users[0].Username != "bob" {
+		t.Fatalf("expected only bob, got %+v", report.Users)
+	}
+}
+
+func TestWriteAccessReviewCSV(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := BuildAccessReviewReport(newAccessReviewInput(now), AccessReviewOptions{}, now)
+
+	var buf bytes.Buffer
+	if err := WriteAccessReviewCSV(&buf, report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid csv: %v", err)
+	}
+	// 表头 + alice 两条授权 + bob 一条授权
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 rows, got %d", len(rows))
+	}
+	for _, row := range rows {
+		if len(row) != len(rows[0]) {
+			t.Fatalf("row has %d columns, header has %d", len(row), len(rows[0]))
+		}
+	}
+}
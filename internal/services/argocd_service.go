@@ -12,6 +12,7 @@ import (
 
 	"gorm.io/gorm"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
 )
@@ -79,7 +80,7 @@ func (s *ArgoCDService) TestConnection(ctx context.Context, config *models.ArgoC
 		}
 		authToken = token
 	} else {
-		return fmt.Errorf("请提供 API Token 或用户名密码")
+		return errcode.New(errcode.ArgoCDCredentialsRequired)
 	}
 
 	// 使用获取到的 token 验证连接
@@ -98,12 +99,12 @@ func (s *ArgoCDService) TestConnection(ctx context.Context, config *models.ArgoC
 	}()
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		return fmt.Errorf("认证失败: Token 无效或已过期")
+		return errcode.New(errcode.ArgoCDTokenInvalid)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("ArgoCD 响应错误 (状态码 %d): %s", resp.StatusCode, string(body))
+		return errcode.New(errcode.ArgoCDResponseError, resp.StatusCode, string(body))
 	}
 
 	// 更新连接状态
@@ -122,7 +123,7 @@ func (s *ArgoCDService) ListApplications(ctx context.Context, clusterID uint) ([
 		return nil, err
 	}
 	if !config.Enabled {
-		return nil, fmt.Errorf("ArgoCD 集成未启用，请先在插件中心配置")
+		return nil, errcode.New(errcode.ArgoCDNotConfigured)
 	}
 
 	client := s.createHTTPClient(config.Insecure)
@@ -149,7 +150,7 @@ func (s *ArgoCDService) ListApplications(ctx context.Context, clusterID uint) ([
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ArgoCD API 错误 (状态码 %d): %s", resp.StatusCode, string(body))
+		return nil, errcode.New(errcode.ArgoCDAPIError, resp.StatusCode, string(body))
 	}
 
 	var result struct {
@@ -181,7 +182,7 @@ func (s *ArgoCDService) GetApplication(ctx context.Context, clusterID uint, appN
 		return nil, err
 	}
 	if !config.Enabled {
-		return nil, fmt.Errorf("ArgoCD 集成未启用")
+		return nil, errcode.New(errcode.ArgoCDNotConfigured)
 	}
 
 	client := s.createHTTPClient(config.Insecure)
@@ -202,12 +203,12 @@ func (s *ArgoCDService) GetApplication(ctx context.Context, clusterID uint, appN
 	}()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("应用 %s 不存在", appName)
+		return nil, errcode.New(errcode.ArgoCDAppNotFound, appName)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("获取应用详情失败: %s", string(body))
+		return nil, errcode.New(errcode.ArgoCDAppGetFailed, string(body))
 	}
 
 	var item argoCDAppResponse
@@ -226,7 +227,7 @@ func (s *ArgoCDService) CreateApplication(ctx context.Context, clusterID uint, r
 		return nil, err
 	}
 	if !config.Enabled {
-		return nil, fmt.Errorf("ArgoCD 集成未启用")
+		return nil, errcode.New(errcode.ArgoCDNotConfigured)
 	}
 
 	// 设置默认值
@@ -325,7 +326,7 @@ func (s *ArgoCDService) CreateApplication(ctx context.Context, clusterID uint, r
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ArgoCD 创建应用失败 (状态码 %d): %s", resp.StatusCode, string(respBody))
+		return nil, errcode.New(errcode.ArgoCDAppCreateFailed, resp.StatusCode, string(respBody))
 	}
 
 	var item argoCDAppResponse
@@ -345,7 +346,7 @@ func (s *ArgoCDService) UpdateApplication(ctx context.Context, clusterID uint, a
 		return nil, err
 	}
 	if !config.Enabled {
-		return nil, fmt.Errorf("ArgoCD 集成未启用")
+		return nil, errcode.New(errcode.ArgoCDNotConfigured)
 	}
 
 	// 先获取现有应用
@@ -435,7 +436,7 @@ func (s *ArgoCDService) UpdateApplication(ctx context.Context, clusterID uint, a
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ArgoCD 更新应用失败: %s", string(respBody))
+		return nil, errcode.New(errcode.ArgoCDAppUpdateFailed, string(respBody))
 	}
 
 	var item argoCDAppResponse
@@ -455,7 +456,7 @@ func (s *ArgoCDService) SyncApplication(ctx context.Context, clusterID uint, app
 		return err
 	}
 	if !config.Enabled {
-		return fmt.Errorf("ArgoCD 集成未启用")
+		return errcode.New(errcode.ArgoCDNotConfigured)
 	}
 
 	syncReq := map[string]interface{}{
@@ -487,7 +488,7 @@ func (s *ArgoCDService) SyncApplication(ctx context.Context, clusterID uint, app
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("同步失败 (状态码 %d): %s", resp.StatusCode, string(respBody))
+		return errcode.New(errcode.ArgoCDSyncFailed, resp.StatusCode, string(respBody))
 	}
 
 	logger.Info("触发 ArgoCD 应用同步成功", "cluster_id", clusterID, "app_name", appName)
@@ -501,7 +502,7 @@ func (s *ArgoCDService) DeleteApplication(ctx context.Context, clusterID uint, a
 		return err
 	}
 	if !config.Enabled {
-		return fmt.Errorf("ArgoCD 集成未启用")
+		return errcode.New(errcode.ArgoCDNotConfigured)
 	}
 
 	client := s.createHTTPClient(config.Insecure)
@@ -524,7 +525,7 @@ func (s *ArgoCDService) DeleteApplication(ctx context.Context, clusterID uint, a
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("删除失败 (状态码 %d): %s", resp.StatusCode, string(respBody))
+		return errcode.New(errcode.ArgoCDDeleteFailed, resp.StatusCode, string(respBody))
 	}
 
 	logger.Info("删除 ArgoCD 应用成功", "cluster_id", clusterID, "app_name", appName, "cascade", cascade)
@@ -538,7 +539,7 @@ func (s *ArgoCDService) RollbackApplication(ctx context.Context, clusterID uint,
 		return err
 	}
 	if !config.Enabled {
-		return fmt.Errorf("ArgoCD 集成未启用")
+		return errcode.New(errcode.ArgoCDNotConfigured)
 	}
 
 	rollbackReq := map[string]interface{}{
@@ -566,7 +567,7 @@ func (s *ArgoCDService) RollbackApplication(ctx context.Context, clusterID uint,
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("回滚失败 (状态码 %d): %s", resp.StatusCode, string(respBody))
+		return errcode.New(errcode.ArgoCDRollbackFailed, resp.StatusCode, string(respBody))
 	}
 
 	logger.Info("回滚 ArgoCD 应用成功", "cluster_id", clusterID, "app_name", appName, "revision_id", revisionID)
@@ -580,7 +581,7 @@ func (s *ArgoCDService) GetApplicationResources(ctx context.Context, clusterID u
 		return nil, err
 	}
 	if !config.Enabled {
-		return nil, fmt.Errorf("ArgoCD 集成未启用")
+		return nil, errcode.New(errcode.ArgoCDNotConfigured)
 	}
 
 	client := s.createHTTPClient(config.Insecure)
@@ -602,7 +603,7 @@ func (s *ArgoCDService) GetApplicationResources(ctx context.Context, clusterID u
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("获取资源树失败: %s", string(body))
+		return nil, errcode.New(errcode.ArgoCDResourceTreeFailed, string(body))
 	}
 
 	var result struct {
@@ -699,7 +700,7 @@ func (s *ArgoCDService) getSessionToken(config *models.ArgoCDConfig) (string, er
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("登录失败 (状态码 %d): %s", resp.StatusCode, string(respBody))
+		return "", errcode.New(errcode.ArgoCDLoginFailed, resp.StatusCode, string(respBody))
 	}
 
 	// 解析响应获取 token
@@ -711,7 +712,7 @@ func (s *ArgoCDService) getSessionToken(config *models.ArgoCDConfig) (string, er
 	}
 
 	if result.Token == "" {
-		return "", fmt.Errorf("登录成功但未返回 token")
+		return "", errcode.New(errcode.ArgoCDTokenMissing)
 	}
 
 	logger.Info("ArgoCD 登录成功，获取到 session token")
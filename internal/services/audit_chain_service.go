@@ -0,0 +1,389 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/auditchain"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// auditChainRetries 多实例并发写入同一条链时，序号冲突的重试次数
+const auditChainRetries = 5
+
+// auditChainBatch 校验时每批读取的记录数
+const auditChainBatch = 500
+
+// ErrUnknownAuditChain 指定的审计链不存在
+var ErrUnknownAuditChain = errcode.New(errcode.UnknownAuditChain)
+
+// ErrAuditChainBroken 审计链校验未通过，拒绝在其上重新计算哈希
+var ErrAuditChainBroken = errcode.New(errcode.AuditChainBroken)
+
+// AuditChainStreams 纳入哈希链的审计表（流名即表名）
+var AuditChainStreams = []string{
+	models.OperationLog{}.TableName(),
+	models.TerminalSession{}.TableName(),
+	models.TerminalCommand{}.TableName(),
+	models.TerminalSessionEvent{}.TableName(),
+}
+
+// AuditChainService 审计记录哈希链：写入时入链，定期锚定链头，按需校验整条链
+type AuditChainService struct {
+	db  *gorm.DB
+	key []byte
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// NewAuditChainService 创建审计哈希链服务
+func NewAuditChainService(db *gorm.DB, key string) *AuditChainService {
+	return &AuditChainService{db: db, key: []byte(key), locks: make(map[string]*sync.Mutex)}
+}
+
+// chainNow 入链记录的时间统一截断到毫秒，避免数据库按更低精度存储（或四舍五入）后哈希不一致
+func chainNow() time.Time {
+	return time.Now().Truncate(time.Millisecond)
+}
+
+func (s *AuditChainService) streamLock(stream string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.locks[stream]
+	if !ok {
+		l = &sync.Mutex{}
+		s.locks[stream] = l
+	}
+	return l
+}
+
+// chainHead 链头（链为空时序号为 0）
+type chainHead struct {
+	ChainSeq  int64
+	ChainHash string
+}
+
+// head 返回链头；表中入链记录已全部按保留策略清理时，以最近的清理锚点为链头继续编号
+func (s *AuditChainService) head(db *gorm.DB, stream string) (chainHead, error) {
+	var h chainHead
+	err := db.Table(stream).Select("chain_seq", "chain_hash").
+		Where("chain_seq IS NOT NULL").Order("chain_seq DESC").Limit(1).Scan(&h).Error
+	if err != nil || h.ChainSeq != 0 {
+		return h, err
+	}
+	pruned, err := s.prunedAt(db, stream)
+	if err != nil {
+		return h, err
+	}
+	return chainHead{ChainSeq: pruned.Seq, ChainHash: pruned.Hash}, nil
+}
+
+// prunedAt 最近的清理锚点（链从未清理时返回零值）
+func (s *AuditChainService) prunedAt(db *gorm.DB, stream string) (models.AuditChainAnchor, error) {
+	var anchor models.AuditChainAnchor
+	err := db.Where("stream = ? AND kind = ?", stream, models.AnchorKindPrune).
+		Order("seq DESC").Limit(1).Find(&anchor).Error
+	return anchor, err
+}
+
+// MarkPruned 在清理链头部的过期记录之前记录清理锚点：seq 及之前的记录将被删除，校验从该处继续。
+// 必须在删除前写入，删除中途失败时链仍可校验。
+func (s *AuditChainService) MarkPruned(stream string, seq int64) error {
+	pruned, err := s.prunedAt(s.db, stream)
+	if err != nil {
+		return err
+	}
+	if pruned.ID != 0 && pruned.Seq >= seq {
+		return nil
+	}
+	var h chainHead
+	if err := s.db.Table(stream).Select("chain_seq", "chain_hash").Where("chain_seq = ?", seq).Limit(1).Scan(&h).Error; err != nil {
+		return err
+	}
+	if h.ChainSeq == 0 {
+		return errcode.New(errcode.AuditAnchorRecordMissing, stream, seq)
+	}
+	anchor := &models.AuditChainAnchor{Stream: stream, Kind: models.AnchorKindPrune, Seq: seq, Hash: h.ChainHash}
+	if err := s.db.Create(anchor).Error; err != nil {
+		return err
+	}
+	logger.Info("审计哈希链清理锚点: stream=%s, seq=%d, hash=%s", stream, seq, h.ChainHash)
+	return nil
+}
+
+// Reseal 改写链上已有记录（如匿名化）并从第一条被改写的记录起重新计算哈希。
+// rewrite 在事务中执行，返回被改写记录的最小序号（0 表示没有入链记录被改写）。
+// 改写前先校验整条链，链已断裂时拒绝执行，避免重新计算哈希掩盖篡改；
+// 改写点之后的锚点作废，以新的链头写入重封锚点并输出到应用日志。
+func (s *AuditChainService) Reseal(stream string, rewrite func(tx *gorm.DB) (int64, error)) error {
+	lock := s.streamLock(stream)
+	lock.Lock()
+	defer lock.Unlock()
+
+	report, err := s.verifyStream(stream)
+	if err != nil {
+		return err
+	}
+	if !report.Valid {
+		return errcode.Wrap(report.Break, errcode.AuditChainBroken)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		fromSeq, err := rewrite(tx)
+		if err != nil || fromSeq == 0 {
+			return err
+		}
+
+		lastSeq := fromSeq - 1
+		prev := ""
+		for {
+			records, err := loadChainBatch(tx, stream, lastSeq, auditChainBatch)
+			if err != nil {
+				return err
+			}
+			for _, r := range records {
+				link := r.GetChainLink()
+				if *link.ChainSeq == fromSeq {
+					prev = link.ChainPrev
+				}
+				hash := auditchain.Hash(s.key, stream, *link.ChainSeq, prev, r.ChainContent())
+				if err := tx.Table(stream).Where("chain_seq = ?", *link.ChainSeq).
+					Updates(map[string]interface{}{"chain_prev": prev, "chain_hash": hash}).Error; err != nil {
+					return err
+				}
+				prev, lastSeq = hash, *link.ChainSeq
+			}
+			if len(records) < auditChainBatch {
+				break
+			}
+		}
+
+		if err := tx.Where("stream = ? AND seq >= ? AND kind <> ?", stream, fromSeq, models.AnchorKindPrune).
+			Delete(&models.AuditChainAnchor{}).Error; err != nil {
+			return err
+		}
+		anchor := &models.AuditChainAnchor{Stream: stream, Kind: models.AnchorKindReseal, Seq: lastSeq, Hash: prev}
+		if err := tx.Create(anchor).Error; err != nil {
+			return err
+		}
+		logger.Warn("审计哈希链已重封: stream=%s, from_seq=%d, old_head=%d/%s, new_head=%d/%s",
+			stream, fromSeq, report.HeadSeq, report.HeadHash, lastSeq, prev)
+		return nil
+	})
+}
+
+// Create 将记录接到链尾并写入数据库。
+// 同一进程内按流串行；多实例并发时依赖 chain_seq 唯一索引发现冲突并重试。
+func (s *AuditChainService) Create(record models.Chained) error {
+	stream := record.TableName()
+	lock := s.streamLock(stream)
+	lock.Lock()
+	defer lock.Unlock()
+
+	var err error
+	for attempt := 0; attempt < auditChainRetries; attempt++ {
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			h, err := s.head(tx, stream)
+			if err != nil {
+				return err
+			}
+			seq := h.ChainSeq + 1
+			link := record.GetChainLink()
+			link.ChainSeq = &seq
+			link.ChainPrev = h.ChainHash
+			link.ChainHash = auditchain.Hash(s.key, stream, seq, h.ChainHash, record.ChainContent())
+			return tx.Create(record).Error
+		})
+		if err == nil {
+			return nil
+		}
+		*record.GetChainLink() = models.ChainLink{}
+	}
+	return err
+}
+
+// createChained 有哈希链服务时入链写入，否则直接写入
+func createChained(db *gorm.DB, chain *AuditChainService, record models.Chained) error {
+	if chain == nil {
+		return db.Create(record).Error
+	}
+	return chain.Create(record)
+}
+
+// Anchor 为有新记录的链写入链头锚点，并输出到应用日志（便于日志外送后留存在数据库之外）
+func (s *AuditChainService) Anchor() error {
+	for _, stream := range AuditChainStreams {
+		h, err := s.head(s.db, stream)
+		if err != nil {
+			return err
+		}
+		if h.ChainSeq == 0 {
+			continue
+		}
+		var last models.AuditChainAnchor
+		if err := s.db.Where("stream = ?", stream).Order("seq DESC").Limit(1).Find(&last).Error; err != nil {
+			return err
+		}
+		if last.ID != 0 && last.Seq >= h.ChainSeq {
+			continue
+		}
+		anchor := &models.AuditChainAnchor{Stream: stream, Seq: h.ChainSeq, Hash: h.ChainHash}
+		if err := s.db.Create(anchor).Error; err != nil {
+			return err
+		}
+		logger.Info("审计哈希链锚点: stream=%s, seq=%d, hash=%s", stream, h.ChainSeq, h.ChainHash)
+	}
+	return nil
+}
+
+// StartAnchorWorker 定期锚定各链链头
+func (s *AuditChainService) StartAnchorWorker(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := s.Anchor(); err != nil {
+			logger.Error("写入审计哈希链锚点失败: %v", err)
+		}
+	}
+}
+
+// AuditChainReport 单条链的校验结果
+type AuditChainReport struct {
+	Stream    string            `json:"stream"`
+	Valid     bool              `json:"valid"`
+	Records   int64             `json:"records"`    // 已校验通过的记录数
+	Unchained int64             `json:"unchained"`  // 启用哈希链之前写入、未入链的历史记录数
+	PrunedSeq int64             `json:"pruned_seq"` // 按保留策略清理至该序号（含），校验从其后开始
+	HeadSeq   int64             `json:"head_seq"`
+	HeadHash  string            `json:"head_hash"`
+	Anchors   int               `json:"anchors"`
+	Break     *auditchain.Break `json:"break,omitempty"` // 第一处断裂
+}
+
+// Verify 校验全部链；stream 非空时只校验指定的链
+func (s *AuditChainService) Verify(stream string) ([]AuditChainReport, error) {
+	streams := AuditChainStreams
+	if stream != "" {
+		found := false
+		for _, name := range AuditChainStreams {
+			if name == stream {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownAuditChain, stream)
+		}
+		streams = []string{stream}
+	}
+
+	reports := make([]AuditChainReport, 0, len(streams))
+	for _, name := range streams {
+		report, err := s.verifyStream(name)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+	return reports, nil
+}
+
+func (s *AuditChainService) verifyStream(stream string) (*AuditChainReport, error) {
+	report := &AuditChainReport{Stream: stream}
+	if err := s.db.Table(stream).Where("chain_seq IS NULL").Count(&report.Unchained).Error; err != nil {
+		return nil, err
+	}
+
+	var anchors []models.AuditChainAnchor
+	if err := s.db.Where("stream = ?", stream).Order("seq ASC").Find(&anchors).Error; err != nil {
+		return nil, err
+	}
+	report.Anchors = len(anchors)
+	anchorHashes := make(map[int64]string, len(anchors))
+	for _, a := range anchors {
+		anchorHashes[a.Seq] = a.Hash
+	}
+
+	pruned, err := s.prunedAt(s.db, stream)
+	if err != nil {
+		return nil, err
+	}
+	report.PrunedSeq = pruned.Seq
+	v := auditchain.NewVerifierAt(s.key, stream, pruned.Seq, pruned.Hash)
+	lastSeq := pruned.Seq
+	for {
+		records, err := loadChainBatch(s.db, stream, lastSeq, auditChainBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range records {
+			link := r.GetChainLink()
+			if brk := v.Next(r.ChainRecordID(), *link.ChainSeq, link.ChainPrev, link.ChainHash, r.ChainContent()); brk != nil {
+				report.Break = brk
+				break
+			}
+			if hash, ok := anchorHashes[*link.ChainSeq]; ok && hash != link.ChainHash {
+				report.Break = &auditchain.Break{Stream: stream, Seq: *link.ChainSeq, RecordID: r.ChainRecordID(), Reason: "与已锚定的链头哈希不一致，链可能被整体重建"}
+				break
+			}
+			lastSeq = *link.ChainSeq
+		}
+		if report.Break != nil || len(records) < auditChainBatch {
+			break
+		}
+	}
+
+	report.Records = v.Count()
+	report.HeadSeq, report.HeadHash = v.Head()
+	if report.Break == nil && len(anchors) > 0 {
+		if latest := anchors[len(anchors)-1]; latest.Seq > report.HeadSeq {
+			report.Break = &auditchain.Break{
+				Stream: stream,
+				Seq:    report.HeadSeq + 1,
+				Reason: fmt.Sprintf("链尾记录缺失（已锚定至第 %d 条），记录可能被删除", latest.Seq),
+			}
+		}
+	}
+	report.Valid = report.Break == nil
+	return report, nil
+}
+
+// loadChainBatch 按序号读取一批入链记录（含软删除的记录）
+func loadChainBatch(db *gorm.DB, stream string, afterSeq int64, limit int) ([]models.Chained, error) {
+	query := db.Unscoped().Where("chain_seq > ?", afterSeq).Order("chain_seq ASC").Limit(limit)
+	switch stream {
+	case models.OperationLog{}.TableName():
+		return findChained[models.OperationLog](query)
+	case models.TerminalSession{}.TableName():
+		return findChained[models.TerminalSession](query)
+	case models.TerminalCommand{}.TableName():
+		return findChained[models.TerminalCommand](query)
+	case models.TerminalSessionEvent{}.TableName():
+		return findChained[models.TerminalSessionEvent](query)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownAuditChain, stream)
+}
+
+func findChained[T any, PT interface {
+	*T
+	models.Chained
+}](query *gorm.DB) ([]models.Chained, error) {
+	var rows []T
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	records := make([]models.Chained, len(rows))
+	for i := range rows {
+		records[i] = PT(&rows[i])
+	}
+	return records, nil
+}
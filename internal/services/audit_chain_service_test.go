@@ -0,0 +1,100 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func newAuditChainTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.OperationLog{}, &models.TerminalSession{}, &models.TerminalCommand{},
+		&models.TerminalSessionEvent{}, &models.AuditChainAnchor{}, &models.OperationLogSnapshot{},
+		&models.TerminalReplayIndex{}, &models.AuditRetentionRun{}, &models.User{}, &models.PortForwardSession{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func verifyOperationChain(t *testing.T, chain *AuditChainService) AuditChainReport {
+	t.Helper()
+	reports, err := chain.Verify(models.OperationLog{}.TableName())
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	return reports[0]
+}
+
+func TestAuditChainDetectsTampering(t *testing.T) {
+	db := newAuditChainTestDB(t)
+	chain := NewAuditChainService(db, "test-key")
+	opLogSvc := NewOperationLogService(db, chain, nil)
+	for _, path := range []string{"/a", "/b", "/c", "/d"} {
+		if err := opLogSvc.Record(&LogEntry{Username: "admin", Method: "POST", Path: path, Success: true}); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	report := verifyOperationChain(t, chain)
+	if !report.Valid || report.Records != 4 || report.HeadSeq != 4 {
+		t.Fatalf("untouched chain should verify, got %+v", report)
+	}
+
+	// 修改记录内容
+	db.Model(&models.OperationLog{}).Where("path = ?", "/b").Update("username", "someone")
+	report = verifyOperationChain(t, chain)
+	if report.Valid || report.Break.Seq != 2 {
+		t.Fatalf("expected break at seq 2 after edit, got %+v", report)
+	}
+	db.Model(&models.OperationLog{}).Where("path = ?", "/b").Update("username", "admin")
+
+	// 删除中间记录
+	db.Where("path = ?", "/c").Delete(&models.OperationLog{})
+	report = verifyOperationChain(t, chain)
+	if report.Valid || report.Break.Seq != 4 || report.Records != 2 {
+		t.Fatalf("expected break at seq 4 after delete, got %+v", report)
+	}
+}
+
+func TestAuditChainAnchorDetectsTruncation(t *testing.T) {
+	db := newAuditChainTestDB(t)
+	chain := NewAuditChainService(db, "test-key")
+	opLogSvc := NewOperationLogService(db, chain, nil)
+	for _, path := range []string{"/a", "/b", "/c"} {
+		if err := opLogSvc.Record(&LogEntry{Username: "admin", Method: "POST", Path: path}); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+	if err := chain.Anchor(); err != nil {
+		t.Fatalf("anchor: %v", err)
+	}
+
+	// 删除链尾记录，链本身仍然连续，只能靠锚点发现
+	db.Where("path = ?", "/c").Delete(&models.OperationLog{})
+	report := verifyOperationChain(t, chain)
+	if report.Valid || report.Break.Seq != 3 {
+		t.Fatalf("expected truncation detected at seq 3, got %+v", report)
+	}
+
+	// 未入链的历史记录不参与校验
+	db.Create(&models.OperationLog{Username: "legacy", Path: "/old"})
+	if report := verifyOperationChain(t, chain); report.Unchained != 1 {
+		t.Fatalf("expected one unchained record, got %+v", report)
+	}
+}
+
+func TestAuditChainWrongKey(t *testing.T) {
+	db := newAuditChainTestDB(t)
+	if err := NewOperationLogService(db, NewAuditChainService(db, "test-key"), nil).Record(&LogEntry{Path: "/a"}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if report := verifyOperationChain(t, NewAuditChainService(db, "other-key")); report.Valid {
+		t.Fatalf("chain should not verify with a different key")
+	}
+}
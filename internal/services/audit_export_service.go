@@ -0,0 +1,431 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/auditexport"
+	"github.com/clay-wangzhi/KubePolaris/internal/constants"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// 发件箱重试退避
+const (
+	auditOutboxMinBackoff = 5 * time.Second
+	auditOutboxMaxBackoff = 10 * time.Minute
+	auditSinkSendTimeout  = 30 * time.Second
+	auditSinkCacheTTL     = time.Minute // 多实例部署时其他实例修改目标后的生效延迟
+)
+
+// AuditExportService 审计事件外送：按目标过滤写入发件箱，后台批量发送并在失败时退避重试。
+// 多实例部署时每个实例都会发送，目标端可能收到重复事件（以 metadata.uid 去重）。
+type AuditExportService struct {
+	db *gorm.DB
+
+	mu       sync.RWMutex
+	cache    []models.AuditSink // 已启用的目标
+	loadedAt time.Time          // 零值表示需重新加载
+
+	sinkMu sync.Mutex
+	sinks  map[uint]*liveAuditSink // 已建立的目标连接
+}
+
+// liveAuditSink 已建立的目标，配置变化（UpdatedAt）后重建
+type liveAuditSink struct {
+	updatedAt time.Time
+	batch     int
+	sink      auditexport.Sink
+}
+
+// NewAuditExportService 创建审计外送服务
+func NewAuditExportService(db *gorm.DB) *AuditExportService {
+	return &AuditExportService{db: db, sinks: make(map[uint]*liveAuditSink)}
+}
+
+// ========== 目标管理 ==========
+
+// AuditSinkRequest 创建/更新外送目标请求
+type AuditSinkRequest struct {
+	Name          string             `json:"name" binding:"required"`
+	Type          string             `json:"type" binding:"required"`
+	Config        auditexport.Config `json:"config"`
+	Secret        *string            `json:"secret"` // 为空时保持原密钥
+	Modules       []string           `json:"modules"`
+	Actions       []string           `json:"actions"`
+	SuccessFilter string             `json:"success_filter"`
+	Enabled       *bool              `json:"enabled"`
+}
+
+// applyTo 校验并写入目标模型
+func (b *AuditSinkRequest) applyTo(s *models.AuditSink) error {
+	if err := b.Config.Validate(b.Type); err != nil {
+		return err
+	}
+	switch b.SuccessFilter {
+	case models.AuditSinkSuccessAll, models.AuditSinkSuccessOnly, models.AuditSinkSuccessFailure:
+	default:
+		return errcode.New(errcode.InvalidAuditSuccessFilter)
+	}
+	config, _ := json.Marshal(b.Config)
+
+	s.Name = b.Name
+	s.Type = b.Type
+	s.Config = string(config)
+	if b.Secret != nil {
+		s.Secret = *b.Secret
+	}
+	s.Modules = encodeJSONOrEmpty(b.Modules, len(b.Modules) == 0)
+	s.Actions = encodeJSONOrEmpty(b.Actions, len(b.Actions) == 0)
+	s.SuccessFilter = b.SuccessFilter
+	s.Enabled = b.Enabled == nil || *b.Enabled
+	return nil
+}
+
+// CreateSink 创建外送目标
+func (s *AuditExportService) CreateSink(body *AuditSinkRequest) (*models.AuditSink, error) {
+	sink := &models.AuditSink{}
+	if err := body.applyTo(sink); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(sink).Error; err != nil {
+		return nil, fmt.Errorf("创建审计外送目标失败: %w", err)
+	}
+	s.invalidate()
+	logger.Info("创建审计外送目标: id=%d, name=%s, type=%s", sink.ID, sink.Name, sink.Type)
+	sink.HasSecret = sink.Secret != ""
+	return sink, nil
+}
+
+// UpdateSink 更新外送目标
+func (s *AuditExportService) UpdateSink(id uint, body *AuditSinkRequest) (*models.AuditSink, error) {
+	var sink models.AuditSink
+	if err := s.db.First(&sink, id).Error; err != nil {
+		return nil, errcode.New(errcode.AuditSinkNotFound)
+	}
+	if err := body.applyTo(&sink); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(&sink).Error; err != nil {
+		return nil, fmt.Errorf("更新审计外送目标失败: %w", err)
+	}
+	s.invalidate()
+	sink.HasSecret = sink.Secret != ""
+	return &sink, nil
+}
+
+// DeleteSink 删除外送目标及其未发送的事件
+func (s *AuditExportService) DeleteSink(id uint) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.AuditSink{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("删除审计外送目标失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errcode.New(errcode.AuditSinkNotFound)
+		}
+		return tx.Where("sink_id = ?", id).Delete(&models.AuditOutbox{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// GetSink 获取外送目标详情
+func (s *AuditExportService) GetSink(id uint) (*models.AuditSink, error) {
+	var sink models.AuditSink
+	if err := s.db.First(&sink, id).Error; err != nil {
+		return nil, errcode.New(errcode.AuditSinkNotFound)
+	}
+	sink.HasSecret = sink.Secret != ""
+	s.db.Model(&models.AuditOutbox{}).Where("sink_id = ?", id).Count(&sink.Pending)
+	return &sink, nil
+}
+
+// ListSinks 获取外送目标列表（含待发送事件数）
+func (s *AuditExportService) ListSinks() ([]models.AuditSink, error) {
+	var sinks []models.AuditSink
+	if err := s.db.Order("id ASC").Find(&sinks).Error; err != nil {
+		return nil, err
+	}
+	var pending []struct {
+		SinkID uint
+		Count  int64
+	}
+	s.db.Model(&models.AuditOutbox{}).Select("sink_id, COUNT(*) AS count").Group("sink_id").Scan(&pending)
+	counts := make(map[uint]int64, len(pending))
+	for _, p := range pending {
+		counts[p.SinkID] = p.Count
+	}
+	for i := range sinks {
+		sinks[i].HasSecret = sinks[i].Secret != ""
+		sinks[i].Pending = counts[sinks[i].ID]
+	}
+	return sinks, nil
+}
+
+// TestSink 直接向目标发送一条测试事件（不经过发件箱）
+func (s *AuditExportService) TestSink(id uint) error {
+	var sink models.AuditSink
+	if err := s.db.First(&sink, id).Error; err != nil {
+		return errcode.New(errcode.AuditSinkNotFound)
+	}
+	target, _, err := buildAuditSink(&sink)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = target.Close() }()
+
+	event := auditexport.FromOperationLog(&models.OperationLog{
+		Username:  "kubepolaris",
+		Method:    "POST",
+		Path:      fmt.Sprintf("/api/v1/audit/sinks/%d/test", id),
+		Module:    constants.ModuleSystem,
+		Action:    constants.ActionTest,
+		Success:   true,
+		CreatedAt: time.Now(),
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), auditSinkSendTimeout)
+	defer cancel()
+	return target.Send(ctx, []auditexport.Event{event})
+}
+
+// invalidate 使目标缓存失效
+func (s *AuditExportService) invalidate() {
+	s.mu.Lock()
+	s.cache = nil
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// enabledSinks 已启用的目标（缓存）
+func (s *AuditExportService) enabledSinks() []models.AuditSink {
+	s.mu.RLock()
+	if !s.loadedAt.IsZero() && time.Since(s.loadedAt) < auditSinkCacheTTL {
+		defer s.mu.RUnlock()
+		return s.cache
+	}
+	s.mu.RUnlock()
+
+	var sinks []models.AuditSink
+	if err := s.db.Where("enabled = ?", true).Order("id ASC").Find(&sinks).Error; err != nil {
+		logger.Error("加载审计外送目标失败: %v", err)
+		return nil
+	}
+	s.mu.Lock()
+	s.cache, s.loadedAt = sinks, time.Now()
+	s.mu.Unlock()
+	return sinks
+}
+
+// ========== 事件入队 ==========
+
+// Enqueue 将事件写入所有匹配目标的发件箱
+func (s *AuditExportService) Enqueue(event auditexport.Event) {
+	if s == nil {
+		return
+	}
+	sinks := s.enabledSinks()
+	if len(sinks) == 0 {
+		return
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	var rows []models.AuditOutbox
+	for i := range sinks {
+		if auditexport.FilterOf(&sinks[i]).Match(&event) {
+			rows = append(rows, models.AuditOutbox{SinkID: sinks[i].ID, Payload: string(payload), NextAttemptAt: now})
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	if err := s.db.Create(&rows).Error; err != nil {
+		logger.Error("写入审计外送发件箱失败: uid=%s, err=%v", event.Metadata.UID, err)
+	}
+}
+
+// ExportOperationLog 外送操作日志
+func (s *AuditExportService) ExportOperationLog(log *models.OperationLog) {
+	if s == nil {
+		return
+	}
+	s.Enqueue(auditexport.FromOperationLog(log))
+}
+
+// ExportTerminalCommand 外送终端命令（补充会话的用户与集群信息）
+func (s *AuditExportService) ExportTerminalCommand(command *models.TerminalCommand) {
+	if s == nil || len(s.enabledSinks()) == 0 {
+		return
+	}
+	var session models.TerminalSession
+	if err := s.db.Preload("User").Preload("Cluster").First(&session, command.SessionID).Error; err != nil {
+		s.Enqueue(auditexport.FromTerminalCommand(command, nil))
+		return
+	}
+	s.Enqueue(auditexport.FromTerminalCommand(command, &session))
+}
+
+// ========== 发送 ==========
+
+// buildAuditSink 按目标配置创建外送目标
+func buildAuditSink(sink *models.AuditSink) (auditexport.Sink, int, error) {
+	var cfg auditexport.Config
+	if sink.Config != "" {
+		if err := json.Unmarshal([]byte(sink.Config), &cfg); err != nil {
+			return nil, 0, fmt.Errorf("目标配置格式错误: %w", err)
+		}
+	}
+	target, err := auditexport.NewSink(sink.Type, cfg, sink.Secret)
+	if err != nil {
+		return nil, 0, err
+	}
+	return target, cfg.Batch(), nil
+}
+
+// liveSink 获取（必要时重建）目标连接
+func (s *AuditExportService) liveSink(sink *models.AuditSink) (*liveAuditSink, error) {
+	s.sinkMu.Lock()
+	defer s.sinkMu.Unlock()
+	if live, ok := s.sinks[sink.ID]; ok {
+		if live.updatedAt.Equal(sink.UpdatedAt) {
+			return live, nil
+		}
+		_ = live.sink.Close()
+		delete(s.sinks, sink.ID)
+	}
+	target, batch, err := buildAuditSink(sink)
+	if err != nil {
+		return nil, err
+	}
+	live := &liveAuditSink{updatedAt: sink.UpdatedAt, batch: batch, sink: target}
+	s.sinks[sink.ID] = live
+	return live, nil
+}
+
+// closeStaleSinks 关闭已删除或停用目标的连接
+func (s *AuditExportService) closeStaleSinks(enabled []models.AuditSink) {
+	keep := make(map[uint]bool, len(enabled))
+	for _, sink := range enabled {
+		keep[sink.ID] = true
+	}
+	s.sinkMu.Lock()
+	defer s.sinkMu.Unlock()
+	for id, live := range s.sinks {
+		if !keep[id] {
+			_ = live.sink.Close()
+			delete(s.sinks, id)
+		}
+	}
+}
+
+// auditSinkError 截断错误信息以适配 last_error 列长度
+func auditSinkError(err error) string {
+	msg := err.Error()
+	if len(msg) > 500 {
+		msg = strings.ToValidUTF8(msg[:500], "")
+	}
+	return msg
+}
+
+// auditOutboxBackoff 第 attempts 次失败后的重试间隔
+func auditOutboxBackoff(attempts int) time.Duration {
+	d := auditOutboxMinBackoff
+	for i := 1; i < attempts && d < auditOutboxMaxBackoff; i++ {
+		d *= 2
+	}
+	if d > auditOutboxMaxBackoff {
+		d = auditOutboxMaxBackoff
+	}
+	return d
+}
+
+// DispatchOnce 为每个已启用的目标发送一批到期事件，返回发送成功的事件数
+func (s *AuditExportService) DispatchOnce() int {
+	sinks := s.enabledSinks()
+	s.closeStaleSinks(sinks)
+
+	sent := 0
+	for i := range sinks {
+		n, err := s.dispatchSink(&sinks[i])
+		if err != nil {
+			logger.Warn("审计外送失败: sink=%s, err=%v", sinks[i].Name, err)
+		}
+		sent += n
+	}
+	return sent
+}
+
+func (s *AuditExportService) dispatchSink(sink *models.AuditSink) (int, error) {
+	live, err := s.liveSink(sink)
+	if err != nil {
+		s.db.Model(&models.AuditSink{}).Where("id = ?", sink.ID).UpdateColumn("last_error", auditSinkError(err))
+		return 0, err
+	}
+
+	var rows []models.AuditOutbox
+	if err := s.db.Where("sink_id = ? AND next_attempt_at <= ?", sink.ID, time.Now()).
+		Order("id ASC").Limit(live.batch).Find(&rows).Error; err != nil || len(rows) == 0 {
+		return 0, err
+	}
+
+	ids := make([]uint, 0, len(rows))
+	events := make([]auditexport.Event, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+		var e auditexport.Event
+		if err := json.Unmarshal([]byte(row.Payload), &e); err != nil {
+			continue // 无法解析的事件不再重试
+		}
+		events = append(events, e)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), auditSinkSendTimeout)
+	err = live.sink.Send(ctx, events)
+	cancel()
+	if err != nil {
+		attempts := rows[0].Attempts + 1
+		msg := auditSinkError(err)
+		s.db.Model(&models.AuditOutbox{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": time.Now().Add(auditOutboxBackoff(attempts)),
+			"last_error":      msg,
+		})
+		s.db.Model(&models.AuditSink{}).Where("id = ?", sink.ID).UpdateColumn("last_error", msg)
+		return 0, err
+	}
+
+	if err := s.db.Where("id IN ?", ids).Delete(&models.AuditOutbox{}).Error; err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	s.db.Model(&models.AuditSink{}).Where("id = ?", sink.ID).UpdateColumns(map[string]interface{}{
+		"last_sent_at": now,
+		"last_error":   "",
+	})
+	return len(events), nil
+}
+
+// StartDispatcher 定期发送发件箱中的事件；一轮发满一批时立即继续
+func (s *AuditExportService) StartDispatcher(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		// 有积压时连续发送，直到没有到期事件
+		for s.DispatchOnce() > 0 {
+			continue
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/auditexport"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestAuditExportOutboxSurvivesSinkOutage(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&models.OperationLog{}, &models.AuditSink{}, &models.AuditOutbox{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	var up atomic.Bool
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		received.Add(1)
+	}))
+	defer server.Close()
+
+	exporter := NewAuditExportService(db)
+	if _, err := exporter.CreateSink(&AuditSinkRequest{
+		Name:          "siem",
+		Type:          models.AuditSinkWebhook,
+		Config:        auditexport.Config{URL: server.URL},
+		Modules:       []string{"pod"},
+		SuccessFilter: models.AuditSinkSuccessOnly,
+	}); err != nil {
+		t.Fatalf("create sink: %v", err)
+	}
+
+	opLogSvc := NewOperationLogService(db, nil, exporter)
+	for _, entry := range []*LogEntry{
+		{Module: "pod", Action: "delete", Success: true},
+		{Module: "pod", Action: "delete", Success: false},    // 被成功过滤排除
+		{Module: "workload", Action: "scale", Success: true}, // 被模块过滤排除
+	} {
+		if err := opLogSvc.Record(entry); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	var pending int64
+	db.Model(&models.AuditOutbox{}).Count(&pending)
+	if pending != 1 {
+		t.Fatalf("expected 1 outbox row, got %d", pending)
+	}
+
+	// 目标不可用：事件保留在发件箱并推迟重试
+	if sent := exporter.DispatchOnce(); sent != 0 {
+		t.Fatalf("expected nothing sent while sink is down, got %d", sent)
+	}
+	var row models.AuditOutbox
+	db.First(&row)
+	if row.Attempts != 1 || row.LastError == "" {
+		t.Fatalf("expected failed attempt recorded, got %+v", row)
+	}
+
+	// 目标恢复后到期重试成功并清除发件箱
+	up.Store(true)
+	db.Model(&models.AuditOutbox{}).Where("id = ?", row.ID).Update("next_attempt_at", row.CreatedAt)
+	if sent := exporter.DispatchOnce(); sent != 1 || received.Load() != 1 {
+		t.Fatalf("expected event delivered after recovery, sent=%d received=%d", sent, received.Load())
+	}
+	db.Model(&models.AuditOutbox{}).Count(&pending)
+	if pending != 0 {
+		t.Fatalf("expected outbox drained, got %d", pending)
+	}
+}
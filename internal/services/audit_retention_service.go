@@ -0,0 +1,442 @@
+package services
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// auditRetentionBatch 归档与删除时每批处理的记录数
+const auditRetentionBatch = 500
+
+// 清理任务触发方式
+const (
+	AuditRetentionTriggerSchedule = "schedule"
+	AuditRetentionTriggerManual   = "manual"
+)
+
+// ErrAuditRetentionRunning 已有清理任务在执行
+var ErrAuditRetentionRunning = errcode.New(errcode.AuditRetentionRunning)
+
+// ReplayStore 终端录像存储（由 terminalreplay.Storage 实现）
+type ReplayStore interface {
+	Open(ctx context.Context, key string, size int64, digest string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// AuditRetentionPolicy 各审计表的保留天数（0 表示不清理）
+type AuditRetentionPolicy struct {
+	OperationLogDays    int `json:"operation_log_days"`
+	TerminalSessionDays int `json:"terminal_session_days"`
+	TerminalCommandDays int `json:"terminal_command_days"`
+}
+
+// auditRetentionTable 按保留策略清理的表
+type auditRetentionTable struct {
+	stream     string
+	timeColumn string
+	// keep 已过期但仍须保留的记录（如进行中的会话、仍被命令引用的会话）
+	keep string
+	// children 随主记录一起归档并删除的子表
+	children []auditRetentionChild
+	// replays 删除记录时一并删除录像文件
+	replays bool
+	days    func(AuditRetentionPolicy) int
+}
+
+// auditRetentionChild 子表及其引用主记录的列
+type auditRetentionChild struct {
+	table  string
+	column string
+}
+
+// auditRetentionTables 按顺序清理：先清理命令，会话不再被引用后才能清理
+var auditRetentionTables = []auditRetentionTable{
+	{
+		stream:     models.OperationLog{}.TableName(),
+		timeColumn: "created_at",
+		children:   []auditRetentionChild{{table: models.OperationLogSnapshot{}.TableName(), column: "operation_log_id"}},
+		days:       func(p AuditRetentionPolicy) int { return p.OperationLogDays },
+	},
+	{
+		stream:     models.TerminalCommand{}.TableName(),
+		timeColumn: "timestamp",
+		days:       func(p AuditRetentionPolicy) int { return p.TerminalCommandDays },
+	},
+	{
+		stream:     models.TerminalSessionEvent{}.TableName(),
+		timeColumn: "created_at",
+		days:       func(p AuditRetentionPolicy) int { return p.TerminalSessionDays },
+	},
+	{
+		stream:     models.TerminalSession{}.TableName(),
+		timeColumn: "start_at",
+		keep:       "end_at IS NULL OR EXISTS (SELECT 1 FROM terminal_commands WHERE terminal_commands.session_id = terminal_sessions.id)",
+		children:   []auditRetentionChild{{table: models.TerminalReplayIndex{}.TableName(), column: "session_id"}},
+		replays:    true,
+		days:       func(p AuditRetentionPolicy) int { return p.TerminalSessionDays },
+	},
+}
+
+// AuditRetentionService 审计记录保留策略：过期记录归档为 gzip 压缩的 JSON Lines 文件后从数据库删除。
+// 入链的表只清理哈希链头部连续的过期记录（被保留的记录之后的记录即使过期也等下次清理），
+// 删除前写入清理锚点，链校验从锚点处继续。
+type AuditRetentionService struct {
+	db         *gorm.DB
+	chain      *AuditChainService
+	replays    ReplayStore
+	policy     AuditRetentionPolicy
+	archiveDir string
+
+	running sync.Mutex
+}
+
+// NewAuditRetentionService 创建审计记录保留服务，replays 为空表示未启用录像
+func NewAuditRetentionService(db *gorm.DB, chain *AuditChainService, replays ReplayStore, policy AuditRetentionPolicy, archiveDir string) *AuditRetentionService {
+	return &AuditRetentionService{db: db, chain: chain, replays: replays, policy: policy, archiveDir: archiveDir}
+}
+
+// Policy 当前保留策略
+func (s *AuditRetentionService) Policy() AuditRetentionPolicy {
+	return s.policy
+}
+
+// Enabled 是否配置了任何表的保留天数
+func (s *AuditRetentionService) Enabled() bool {
+	for _, t := range auditRetentionTables {
+		if t.days(s.policy) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// RunOnce 按保留策略清理各表，返回本次产生的清理任务（没有过期记录的表不产生任务）
+func (s *AuditRetentionService) RunOnce(trigger string) ([]models.AuditRetentionRun, error) {
+	if !s.running.TryLock() {
+		return nil, ErrAuditRetentionRunning
+	}
+	defer s.running.Unlock()
+
+	var runs []models.AuditRetentionRun
+	for _, t := range auditRetentionTables {
+		days := t.days(s.policy)
+		if days <= 0 {
+			continue
+		}
+		cutoff := time.Now().AddDate(0, 0, -days)
+		run, err := s.purgeTable(t, cutoff, trigger)
+		if run != nil {
+			runs = append(runs, *run)
+		}
+		if err != nil {
+			return runs, err
+		}
+	}
+	return runs, nil
+}
+
+// StartWorker 启动时及之后定期执行清理
+func (s *AuditRetentionService) StartWorker(interval time.Duration) {
+	if !s.Enabled() || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for ; ; <-ticker.C {
+		runs, err := s.RunOnce(AuditRetentionTriggerSchedule)
+		if err != nil {
+			logger.Error("审计记录清理失败: %v", err)
+			continue
+		}
+		for _, r := range runs {
+			logger.Info("审计记录清理完成: stream=%s, purged=%d, archive=%s", r.Stream, r.Purged, r.ArchivePath)
+		}
+	}
+}
+
+// ListRuns 分页查询清理任务，stream 为空表示全部
+func (s *AuditRetentionService) ListRuns(stream string, page, pageSize int) ([]models.AuditRetentionRun, int64, error) {
+	query := s.db.Model(&models.AuditRetentionRun{})
+	if stream != "" {
+		query = query.Where("stream = ?", stream)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var runs []models.AuditRetentionRun
+	err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&runs).Error
+	return runs, total, err
+}
+
+// purgeCondition 本次可清理的记录：入链记录取链头部连续过期的部分（序号不超过 boundary），
+// 未入链的历史记录按时间判断
+func (t auditRetentionTable) purgeCondition(cutoff time.Time, boundary int64) (string, []interface{}) {
+	expired := t.timeColumn + " < ?"
+	if t.keep != "" {
+		expired = "(" + expired + " AND NOT (" + t.keep + "))"
+	}
+	return "((chain_seq IS NOT NULL AND chain_seq <= ?) OR (chain_seq IS NULL AND " + expired + "))",
+		[]interface{}{boundary, cutoff}
+}
+
+// chainBoundary 链头部连续过期记录的最大序号：第一条需保留的入链记录之前的位置
+func (s *AuditRetentionService) chainBoundary(t auditRetentionTable, cutoff time.Time) (int64, error) {
+	keep := t.timeColumn + " >= ?"
+	if t.keep != "" {
+		keep = "(" + keep + " OR " + t.keep + ")"
+	}
+	var kept, head struct{ Seq *int64 }
+	if err := s.db.Table(t.stream).Select("MIN(chain_seq) AS seq").
+		Where("chain_seq IS NOT NULL AND "+keep, cutoff).Scan(&kept).Error; err != nil {
+		return 0, err
+	}
+	if kept.Seq != nil {
+		return *kept.Seq - 1, nil
+	}
+	if err := s.db.Table(t.stream).Select("MAX(chain_seq) AS seq").Scan(&head).Error; err != nil {
+		return 0, err
+	}
+	if head.Seq == nil {
+		return 0, nil
+	}
+	return *head.Seq, nil
+}
+
+// purgeTable 归档并删除一张表的过期记录
+func (s *AuditRetentionService) purgeTable(t auditRetentionTable, cutoff time.Time, trigger string) (*models.AuditRetentionRun, error) {
+	boundary, err := s.chainBoundary(t, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	where, args := t.purgeCondition(cutoff, boundary)
+	var pending int64
+	if err := s.db.Table(t.stream).Where(where, args...).Count(&pending).Error; err != nil {
+		return nil, err
+	}
+	if pending == 0 {
+		return nil, nil
+	}
+
+	run := &models.AuditRetentionRun{
+		Stream:    t.stream,
+		Cutoff:    cutoff,
+		Status:    models.AuditRetentionRunning,
+		ChainSeq:  boundary,
+		Trigger:   trigger,
+		StartedAt: time.Now(),
+	}
+	if err := s.db.Create(run).Error; err != nil {
+		return nil, err
+	}
+
+	err = s.archiveAndPurge(t, run, where, args)
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Status = models.AuditRetentionSuccess
+	if err != nil {
+		run.Status = models.AuditRetentionFailed
+		run.Error = err.Error()
+	}
+	if saveErr := s.db.Save(run).Error; saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return run, err
+}
+
+func (s *AuditRetentionService) archiveAndPurge(t auditRetentionTable, run *models.AuditRetentionRun, where string, args []interface{}) error {
+	lastID, err := s.archive(t, run, where, args)
+	if err != nil {
+		return fmt.Errorf("归档失败: %w", err)
+	}
+	if s.chain != nil && run.ChainSeq > 0 {
+		if err := s.chain.MarkPruned(t.stream, run.ChainSeq); err != nil {
+			return fmt.Errorf("记录清理锚点失败: %w", err)
+		}
+	}
+	// 只删除已归档的记录
+	where += " AND id <= ?"
+	args = append(args, lastID)
+	for {
+		var ids []uint
+		if err := s.db.Table(t.stream).Where(where, args...).Order("id ASC").Limit(auditRetentionBatch).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := s.deleteBatch(t, ids); err != nil {
+			return fmt.Errorf("删除失败: %w", err)
+		}
+		run.Purged += int64(len(ids))
+		s.db.Model(run).Update("purged", run.Purged)
+	}
+}
+
+// archive 将待清理记录及其子记录写入归档文件，返回已归档的最大主键。
+// 先写入临时文件并落盘，完成后再改名，归档文件存在即表示内容完整。
+func (s *AuditRetentionService) archive(t auditRetentionTable, run *models.AuditRetentionRun, where string, args []interface{}) (uint, error) {
+	dir := filepath.Join(s.archiveDir, t.stream)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return 0, err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s-%d.jsonl.gz", t.stream, run.StartedAt.Format("20060102-150405"), run.ID))
+	partial := path + ".partial"
+	f, err := os.OpenFile(partial, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(partial)
+	}()
+
+	hasher := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(f, hasher)}
+	gz := gzip.NewWriter(counter)
+	enc := json.NewEncoder(gz)
+	write := func(table string, rows []map[string]interface{}) error {
+		for _, row := range rows {
+			if err := enc.Encode(auditArchiveLine{Table: table, Record: row}); err != nil {
+				return err
+			}
+		}
+		run.Archived += int64(len(rows))
+		return nil
+	}
+
+	var lastID uint
+	for {
+		var rows []map[string]interface{}
+		if err := s.db.Table(t.stream).Where(where, args...).Where("id > ?", lastID).
+			Order("id ASC").Limit(auditRetentionBatch).Find(&rows).Error; err != nil {
+			return 0, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+		ids := make([]uint, 0, len(rows))
+		for _, row := range rows {
+			ids = append(ids, toUint(row["id"]))
+		}
+		lastID = ids[len(ids)-1]
+		if err := write(t.stream, rows); err != nil {
+			return 0, err
+		}
+		for _, child := range t.children {
+			var childRows []map[string]interface{}
+			if err := s.db.Table(child.table).Where(child.column+" IN ?", ids).Order("id ASC").Find(&childRows).Error; err != nil {
+				return 0, err
+			}
+			if err := write(child.table, childRows); err != nil {
+				return 0, err
+			}
+		}
+		s.db.Model(run).Update("archived", run.Archived)
+	}
+
+	if err := gz.Close(); err != nil {
+		return 0, err
+	}
+	if err := f.Sync(); err != nil {
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(partial, path); err != nil {
+		return 0, err
+	}
+
+	run.ArchivePath = path
+	run.ArchiveSize = counter.n
+	run.ArchiveSHA256 = hex.EncodeToString(hasher.Sum(nil))
+	if err := s.db.Model(run).Updates(map[string]interface{}{
+		"archived":       run.Archived,
+		"archive_path":   run.ArchivePath,
+		"archive_size":   run.ArchiveSize,
+		"archive_sha256": run.ArchiveSHA256,
+	}).Error; err != nil {
+		return 0, err
+	}
+	return lastID, nil
+}
+
+// deleteBatch 删除一批记录及其子记录，会话的录像文件在记录删除后清理
+func (s *AuditRetentionService) deleteBatch(t auditRetentionTable, ids []uint) error {
+	var replayKeys []string
+	if t.replays && s.replays != nil {
+		if err := s.db.Table(t.stream).Where("id IN ? AND replay_path <> ''", ids).Pluck("replay_path", &replayKeys).Error; err != nil {
+			return err
+		}
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, child := range t.children {
+			if err := tx.Exec("DELETE FROM "+child.table+" WHERE "+child.column+" IN ?", ids).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Exec("DELETE FROM "+t.stream+" WHERE id IN ?", ids).Error
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range replayKeys {
+		if err := s.replays.Delete(context.Background(), key); err != nil {
+			logger.Warn("删除过期会话录像失败: key=%s, err=%v", key, err)
+		}
+	}
+	return nil
+}
+
+// auditArchiveLine 归档文件中的一行
+type auditArchiveLine struct {
+	Table  string                 `json:"table"`
+	Record map[string]interface{} `json:"record"`
+}
+
+// countingWriter 统计写入的字节数
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// toUint 将按 map 读出的主键转换为 uint（不同数据库驱动返回的整数类型不同）
+func toUint(v interface{}) uint {
+	switch n := v.(type) {
+	case int64:
+		return uint(n)
+	case uint64:
+		return uint(n)
+	case int32:
+		return uint(n)
+	case uint32:
+		return uint(n)
+	case int:
+		return uint(n)
+	case uint:
+		return n
+	}
+	return 0
+}
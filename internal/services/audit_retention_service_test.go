@@ -0,0 +1,175 @@
+package services
+
+import (
+	"bufio"
+	"compress/gzip"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+func TestAuditRetentionArchivesAndPrunesChain(t *testing.T) {
+	db := newAuditChainTestDB(t)
+	chain := NewAuditChainService(db, "test-key")
+	opLogSvc := NewOperationLogService(db, chain, nil)
+	record := func(path string, snaps ...*models.OperationLogSnapshot) {
+		if err := opLogSvc.Record(&LogEntry{Username: "admin", Method: "POST", Path: path, Snapshots: snaps}); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+	record("/a", &models.OperationLogSnapshot{Resource: "deployments", Name: "web"})
+	record("/b")
+	record("/c")
+	time.Sleep(5 * time.Millisecond)
+	cutoff := time.Now()
+	time.Sleep(5 * time.Millisecond)
+	record("/d")
+
+	svc := NewAuditRetentionService(db, chain, nil, AuditRetentionPolicy{}, t.TempDir())
+	run, err := svc.purgeTable(auditRetentionTables[0], cutoff, AuditRetentionTriggerManual)
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if run.Status != models.AuditRetentionSuccess || run.Purged != 3 || run.Archived != 4 || run.ChainSeq != 3 {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+
+	// 归档文件包含 3 条日志及其快照
+	f, err := os.Open(run.ArchivePath)
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	var lines []string
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 4 || !strings.Contains(lines[0], `"/a"`) || !strings.Contains(lines[3], `"operation_log_snapshots"`) {
+		t.Fatalf("unexpected archive: %v", lines)
+	}
+
+	var logs, snaps int64
+	db.Model(&models.OperationLog{}).Count(&logs)
+	db.Model(&models.OperationLogSnapshot{}).Count(&snaps)
+	if logs != 1 || snaps != 0 {
+		t.Fatalf("expected 1 log and no snapshots left, got %d/%d", logs, snaps)
+	}
+	report := verifyOperationChain(t, chain)
+	if !report.Valid || report.PrunedSeq != 3 || report.Records != 1 {
+		t.Fatalf("pruned chain should verify from the prune anchor, got %+v", report)
+	}
+
+	// 全部清理后新记录接着原链头编号
+	if _, err := svc.purgeTable(auditRetentionTables[0], time.Now().Add(time.Second), AuditRetentionTriggerManual); err != nil {
+		t.Fatalf("purge all: %v", err)
+	}
+	record("/e")
+	report = verifyOperationChain(t, chain)
+	if !report.Valid || report.HeadSeq != 5 || report.Records != 1 {
+		t.Fatalf("chain should continue after full purge, got %+v", report)
+	}
+}
+
+func TestAuditRetentionKeepsReferencedSessions(t *testing.T) {
+	db := newAuditChainTestDB(t)
+	chain := NewAuditChainService(db, "test-key")
+	auditSvc := NewAuditService(db, chain, nil)
+	ended, err := auditSvc.CreateSession(&CreateSessionRequest{UserID: 1, ClusterID: 1, TargetType: "pod"})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	active, _ := auditSvc.CreateSession(&CreateSessionRequest{UserID: 1, ClusterID: 1, TargetType: "pod"})
+	if err := auditSvc.CloseSession(ended.ID, "closed"); err != nil {
+		t.Fatalf("close session: %v", err)
+	}
+	if err := auditSvc.RecordCommand(ended.ID, "ls", "ls", nil); err != nil {
+		t.Fatalf("record command: %v", err)
+	}
+
+	svc := NewAuditRetentionService(db, chain, nil, AuditRetentionPolicy{}, t.TempDir())
+	sessions := auditRetentionTables[len(auditRetentionTables)-1]
+	cutoff := time.Now().Add(time.Second)
+
+	// 会话仍被命令引用时不清理
+	if run, err := svc.purgeTable(sessions, cutoff, AuditRetentionTriggerManual); err != nil || run != nil {
+		t.Fatalf("expected nothing purged, got %+v, %v", run, err)
+	}
+	if _, err := svc.purgeTable(auditRetentionTables[1], cutoff, AuditRetentionTriggerManual); err != nil {
+		t.Fatalf("purge commands: %v", err)
+	}
+	run, err := svc.purgeTable(sessions, cutoff, AuditRetentionTriggerManual)
+	if err != nil || run == nil || run.Purged != 1 {
+		t.Fatalf("expected the ended session purged, got %+v, %v", run, err)
+	}
+	var left []models.TerminalSession
+	db.Unscoped().Find(&left)
+	if len(left) != 1 || left[0].ID != active.ID {
+		t.Fatalf("active session should be kept, got %+v", left)
+	}
+}
+
+func TestPseudonymizeReseals(t *testing.T) {
+	db := newAuditChainTestDB(t)
+	chain := NewAuditChainService(db, "test-key")
+	opLogSvc := NewOperationLogService(db, chain, nil)
+
+	user := &models.User{Username: "alice", Email: "alice@example.com", Phone: "123"}
+	other := &models.User{Username: "bob"}
+	db.Create(user)
+	db.Create(other)
+	record := func(entry *LogEntry) {
+		if err := opLogSvc.Record(entry); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+	record(&LogEntry{Username: "admin", Method: "POST", Path: "/a"})
+	record(&LogEntry{Username: "admin", Method: "POST", Path: "/users", RequestBody: map[string]string{"username": "alice"}})
+	record(&LogEntry{UserID: &user.ID, Username: "alice", Method: "POST", Path: "/auth/login", ClientIP: "10.0.0.1"})
+	record(&LogEntry{Username: "admin", Method: "POST", Path: "/b"})
+	if err := chain.Anchor(); err != nil {
+		t.Fatalf("anchor: %v", err)
+	}
+
+	svc := NewAuditUserDataService(db, chain, nil)
+	if _, err := svc.Pseudonymize(other.ID); err != ErrUserNotDeleted {
+		t.Fatalf("expected ErrUserNotDeleted, got %v", err)
+	}
+	db.Delete(user)
+	result, err := svc.Pseudonymize(user.ID)
+	if err != nil {
+		t.Fatalf("pseudonymize: %v", err)
+	}
+	if result.OperationLogs != 2 || result.ResealedFrom != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	var logs []models.OperationLog
+	db.Order("id ASC").Find(&logs)
+	for _, l := range logs {
+		if strings.Contains(l.Username+l.RequestBody, "alice") || l.ClientIP != "" {
+			t.Fatalf("identity left in log: %+v", l)
+		}
+	}
+	var scrubbed models.User
+	db.Unscoped().First(&scrubbed, user.ID)
+	if scrubbed.Username != result.Pseudonym || scrubbed.Email != "" || scrubbed.Phone != "" {
+		t.Fatalf("user not scrubbed: %+v", scrubbed)
+	}
+	if report := verifyOperationChain(t, chain); !report.Valid || report.Records != 4 {
+		t.Fatalf("resealed chain should verify, got %+v", report)
+	}
+
+	// 链已断裂时拒绝重新计算哈希
+	db.Model(&models.OperationLog{}).Where("path = ?", "/a").Update("username", "mallory")
+	if _, err := svc.Pseudonymize(user.ID); err == nil {
+		t.Fatal("expected pseudonymize to refuse a broken chain")
+	}
+}
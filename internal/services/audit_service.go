@@ -2,7 +2,9 @@ package services
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
@@ -12,12 +14,20 @@ import (
 
 // AuditService 审计服务
 type AuditService struct {
-	db *gorm.DB
+	db       *gorm.DB
+	chain    *AuditChainService   // 为空时会话、命令与事件不入哈希链
+	exporter *AuditExportService  // 为空时终端命令不外送
+	notifier *NotificationService // 为空时打开终端不产生通知事件
 }
 
 // NewAuditService 创建审计服务
-func NewAuditService(db *gorm.DB) *AuditService {
-	return &AuditService{db: db}
+func NewAuditService(db *gorm.DB, chain *AuditChainService, exporter *AuditExportService) *AuditService {
+	return &AuditService{db: db, chain: chain, exporter: exporter}
+}
+
+// SetNotifier 设置事件通知服务
+func (s *AuditService) SetNotifier(notifier *NotificationService) {
+	s.notifier = notifier
 }
 
 // TerminalType 终端类型
@@ -73,16 +83,17 @@ func (s *AuditService) CreateSession(req *CreateSessionRequest) (*models.Termina
 		Pod:        req.Pod,
 		Container:  req.Container,
 		Node:       req.Node,
-		StartAt:    time.Now(),
+		StartAt:    chainNow(),
 		Status:     "active",
 	}
 
-	if err := s.db.Create(session).Error; err != nil {
+	if err := createChained(s.db, s.chain, session); err != nil {
 		logger.Error("创建终端会话失败", "error", err)
 		return nil, err
 	}
 
 	logger.Info("终端会话已创建", "sessionID", session.ID, "userID", req.UserID, "type", req.TargetType)
+	go s.notifier.NotifyTerminalOpened(session)
 	return session, nil
 }
 
@@ -105,28 +116,55 @@ func (s *AuditService) CloseSession(sessionID uint, status string) error {
 	return nil
 }
 
-// SetSessionReplayReady 会话结束时写入录像相对路径与大小（gzip 后）。
-func (s *AuditService) SetSessionReplayReady(sessionID uint, replayPath string, size int64) error {
+// SetSessionReplayReady 会话结束时写入录像对象键、大小与 SHA-256（gzip 后）。
+func (s *AuditService) SetSessionReplayReady(sessionID uint, replayPath string, size int64, digest string) error {
 	return s.db.Model(&models.TerminalSession{}).Where("id = ?", sessionID).Updates(map[string]interface{}{
-		"replay_path": replayPath,
-		"replay_size": size,
+		"replay_path":   replayPath,
+		"replay_size":   size,
+		"replay_sha256": digest,
 	}).Error
 }
 
+// ListStoredReplays 列出仍保留录像的会话（按开始时间倒序，仅含保留策略所需字段）
+func (s *AuditService) ListStoredReplays() ([]models.TerminalSession, error) {
+	var sessions []models.TerminalSession
+	err := s.db.Model(&models.TerminalSession{}).
+		Select("id", "replay_path", "replay_size", "start_at").
+		Where("replay_path <> ''").
+		Order("start_at DESC, id DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// ClearSessionReplay 清除会话的录像信息及命令索引（录像文件已被保留策略删除）
+func (s *AuditService) ClearSessionReplay(sessionID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", sessionID).Delete(&models.TerminalReplayIndex{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.TerminalSession{}).Where("id = ?", sessionID).Updates(map[string]interface{}{
+			"replay_path":   "",
+			"replay_size":   0,
+			"replay_sha256": "",
+		}).Error
+	})
+}
+
 // RecordCommand 记录命令（异步调用，不阻塞终端）
 func (s *AuditService) RecordCommand(sessionID uint, rawInput, parsedCmd string, exitCode *int) error {
 	command := &models.TerminalCommand{
 		SessionID: sessionID,
-		Timestamp: time.Now(),
+		Timestamp: chainNow(),
 		RawInput:  rawInput,
 		ParsedCmd: parsedCmd,
 		ExitCode:  exitCode,
 	}
 
-	if err := s.db.Create(command).Error; err != nil {
+	if err := createChained(s.db, s.chain, command); err != nil {
 		logger.Error("记录命令失败", "error", err, "sessionID", sessionID)
 		return err
 	}
+	s.exporter.ExportTerminalCommand(command)
 
 	// 更新会话的输入大小
 	s.db.Model(&models.TerminalSession{}).
@@ -145,6 +183,187 @@ func (s *AuditService) RecordCommandAsync(sessionID uint, rawInput, parsedCmd st
 	}()
 }
 
+// RecordBlockedCommandAsync 异步记录被终端命令策略拦截的命令
+func (s *AuditService) RecordBlockedCommandAsync(sessionID uint, parsedCmd, ruleName string) {
+	go func() {
+		command := &models.TerminalCommand{
+			SessionID:  sessionID,
+			Timestamp:  chainNow(),
+			RawInput:   parsedCmd,
+			ParsedCmd:  parsedCmd,
+			Blocked:    true,
+			PolicyRule: ruleName,
+		}
+		if err := createChained(s.db, s.chain, command); err != nil {
+			logger.Error("记录拦截命令失败: sessionID=%d, err=%v", sessionID, err)
+			return
+		}
+		s.exporter.ExportTerminalCommand(command)
+	}()
+}
+
+// RecordSessionEventAsync 异步记录终端会话事件（旁观、协同操作、强制终止）
+func (s *AuditService) RecordSessionEventAsync(sessionID uint, actorID *uint, event, detail string) {
+	go func() {
+		e := &models.TerminalSessionEvent{
+			SessionID: sessionID,
+			Event:     event,
+			ActorID:   actorID,
+			Detail:    detail,
+			CreatedAt: chainNow(),
+		}
+		if err := createChained(s.db, s.chain, e); err != nil {
+			logger.Error("记录终端会话事件失败: sessionID=%d, event=%s, err=%v", sessionID, event, err)
+		}
+	}()
+}
+
+// GetSessionEvents 获取终端会话事件（按时间顺序）
+func (s *AuditService) GetSessionEvents(sessionID uint) ([]models.TerminalSessionEvent, error) {
+	var events []models.TerminalSessionEvent
+	if err := s.db.Where("session_id = ?", sessionID).Order("created_at ASC, id ASC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// SaveReplayIndex 替换会话的录像命令索引
+func (s *AuditService) SaveReplayIndex(sessionID uint, entries []models.TerminalReplayIndex) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", sessionID).Delete(&models.TerminalReplayIndex{}).Error; err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		for i := range entries {
+			entries[i].ID = 0
+			entries[i].SessionID = sessionID
+		}
+		return tx.CreateInBatches(entries, 100).Error
+	})
+}
+
+// ReplaySearchRequest 录像检索请求
+type ReplaySearchRequest struct {
+	Keyword   string
+	Scope     string // command（默认）、output、all
+	UserID    uint
+	ClusterID uint
+	StartTime *time.Time
+	EndTime   *time.Time
+	Page      int
+	PageSize  int
+}
+
+// ReplaySearchItem 录像检索结果
+type ReplaySearchItem struct {
+	SessionID   uint      `json:"session_id"`
+	Seq         int       `json:"seq"`
+	Offset      float64   `json:"offset" gorm:"column:offset_sec"` // 回放跳转位置（秒）
+	Command     string    `json:"command"`
+	Snippet     string    `json:"snippet,omitempty"` // 输出中命中位置附近的片段
+	UserID      uint      `json:"user_id"`
+	Username    string    `json:"username"`
+	ClusterID   uint      `json:"cluster_id"`
+	ClusterName string    `json:"cluster_name"`
+	TargetType  string    `json:"target_type"`
+	Namespace   string    `json:"namespace"`
+	Pod         string    `json:"pod"`
+	Node        string    `json:"node"`
+	StartAt     time.Time `json:"start_at"`
+}
+
+// SearchReplayIndex 按命令或输出检索终端录像
+func (s *AuditService) SearchReplayIndex(req *ReplaySearchRequest) ([]ReplaySearchItem, int64, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+
+	like := "%" + req.Keyword + "%"
+	query := s.db.Table("terminal_replay_index AS idx").
+		Joins("JOIN terminal_sessions ON terminal_sessions.id = idx.session_id").
+		Joins("LEFT JOIN users ON users.id = terminal_sessions.user_id").
+		Joins("LEFT JOIN clusters ON clusters.id = terminal_sessions.cluster_id").
+		Where("terminal_sessions.deleted_at IS NULL")
+
+	switch req.Scope {
+	case "output":
+		query = query.Where("idx.output LIKE ?", like)
+	case "all":
+		query = query.Where("idx.command LIKE ? OR idx.output LIKE ?", like, like)
+	default:
+		query = query.Where("idx.command LIKE ?", like)
+	}
+	if req.UserID > 0 {
+		query = query.Where("terminal_sessions.user_id = ?", req.UserID)
+	}
+	if req.ClusterID > 0 {
+		query = query.Where("terminal_sessions.cluster_id = ?", req.ClusterID)
+	}
+	if req.StartTime != nil {
+		query = query.Where("terminal_sessions.start_at >= ?", req.StartTime)
+	}
+	if req.EndTime != nil {
+		query = query.Where("terminal_sessions.start_at <= ?", req.EndTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []struct {
+		ReplaySearchItem
+		Output string
+	}
+	err := query.Select(`idx.session_id, idx.seq, idx.offset_sec, idx.command, idx.output,
+			terminal_sessions.user_id, users.username, terminal_sessions.cluster_id, clusters.name AS cluster_name,
+			terminal_sessions.target_type, terminal_sessions.namespace, terminal_sessions.pod, terminal_sessions.node,
+			terminal_sessions.start_at`).
+		Order("terminal_sessions.start_at DESC, idx.seq ASC").
+		Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]ReplaySearchItem, 0, len(rows))
+	for _, row := range rows {
+		item := row.ReplaySearchItem
+		if req.Scope == "output" || req.Scope == "all" {
+			item.Snippet = replaySnippet(row.Output, req.Keyword, 120)
+		}
+		items = append(items, item)
+	}
+	return items, total, nil
+}
+
+// replaySnippet 截取关键字附近的输出片段
+func replaySnippet(output, keyword string, radius int) string {
+	idx := strings.Index(output, keyword)
+	if idx < 0 {
+		return ""
+	}
+	start, end := idx-radius, idx+len(keyword)+radius
+	if start < 0 {
+		start = 0
+	}
+	if end > len(output) {
+		end = len(output)
+	}
+	for start > 0 && !utf8.RuneStart(output[start]) {
+		start--
+	}
+	for end < len(output) && !utf8.RuneStart(output[end]) {
+		end++
+	}
+	return output[start:end]
+}
+
 // SessionListRequest 会话列表请求
 type SessionListRequest struct {
 	UserID     uint
@@ -329,6 +548,7 @@ type SessionDetailResponse struct {
 	InputSize    int64                    `json:"input_size"`
 	ReplayPath   string                   `json:"replay_path"`
 	ReplaySize   int64                    `json:"replay_size"`
+	ReplaySHA256 string                   `json:"replay_sha256"`
 	Status       string                   `json:"status"`
 	CommandCount int64                    `json:"command_count"`
 	Duration     string                   `json:"duration"`
@@ -389,6 +609,7 @@ func (s *AuditService) GetSessionDetail(sessionID uint) (*SessionDetailResponse,
 		InputSize:    result.InputSize,
 		ReplayPath:   result.ReplayPath,
 		ReplaySize:   result.ReplaySize,
+		ReplaySHA256: result.ReplaySHA256,
 		Status:       result.Status,
 		CommandCount: commandCount,
 		Duration:     duration,
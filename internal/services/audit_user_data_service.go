@@ -0,0 +1,330 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/constants"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// ErrUserNotDeleted 只能匿名化已删除的用户
+var ErrUserNotDeleted = errcode.New(errcode.UserNotDeleted)
+
+// AuditUserDataService 用户审计数据：导出与某个用户相关的全部审计记录，匿名化已删除用户的身份信息
+type AuditUserDataService struct {
+	db      *gorm.DB
+	chain   *AuditChainService
+	replays ReplayStore
+}
+
+// NewAuditUserDataService 创建用户审计数据服务，replays 为空表示未启用录像
+func NewAuditUserDataService(db *gorm.DB, chain *AuditChainService, replays ReplayStore) *AuditUserDataService {
+	return &AuditUserDataService{db: db, chain: chain, replays: replays}
+}
+
+// UserDataManifest 导出包清单
+type UserDataManifest struct {
+	UserID      uint               `json:"user_id"`
+	Username    string             `json:"username"`
+	GeneratedAt time.Time          `json:"generated_at"`
+	GeneratedBy string             `json:"generated_by"`
+	Files       []UserDataFileInfo `json:"files"`
+	Errors      []string           `json:"errors,omitempty"` // 未能导出的内容（如录像校验失败）
+}
+
+// UserDataFileInfo 导出包中的文件
+type UserDataFileInfo struct {
+	Name    string `json:"name"`
+	Records int    `json:"records,omitempty"`
+	Size    int64  `json:"size"`
+	SHA256  string `json:"sha256"`
+}
+
+// userDataZip 写入导出包并记录各文件的摘要
+type userDataZip struct {
+	zw       *zip.Writer
+	manifest *UserDataManifest
+}
+
+func (z *userDataZip) create(name string, method uint16) (*countingWriter, func(), error) {
+	w, err := z.zw.CreateHeader(&zip.FileHeader{Name: name, Method: method, Modified: z.manifest.GeneratedAt})
+	if err != nil {
+		return nil, nil, err
+	}
+	hasher := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(w, hasher)}
+	done := func() {
+		z.manifest.Files = append(z.manifest.Files, UserDataFileInfo{
+			Name: name, Size: counter.n, SHA256: hex.EncodeToString(hasher.Sum(nil)),
+		})
+	}
+	return counter, done, nil
+}
+
+// writeJSON 写入单个 JSON 文件
+func (z *userDataZip) writeJSON(name string, v interface{}) error {
+	w, done, err := z.create(name, zip.Deflate)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	done()
+	return nil
+}
+
+// writeUserDataLines 写入 JSON Lines 文件
+func writeUserDataLines[T any](z *userDataZip, name string, rows []T) error {
+	w, done, err := z.create(name, zip.Deflate)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	for i := range rows {
+		if err := enc.Encode(&rows[i]); err != nil {
+			return err
+		}
+	}
+	done()
+	z.manifest.Files[len(z.manifest.Files)-1].Records = len(rows)
+	return nil
+}
+
+// getUser 读取用户（含已删除的用户）
+func (s *AuditUserDataService) getUser(userID uint) (*models.User, error) {
+	var user models.User
+	if err := s.db.Unscoped().First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// userOperationLogs 用户本人的操作日志（登录失败的记录没有用户ID，按用户名匹配）
+func (s *AuditUserDataService) userOperationLogs(user *models.User) *gorm.DB {
+	return s.db.Model(&models.OperationLog{}).Where("user_id = ? OR username = ?", user.ID, user.Username)
+}
+
+// Export 将与用户相关的审计记录打包为 zip 写入 w：用户信息、登录记录、操作日志、
+// 终端会话/命令/会话事件、端口转发记录以及会话录像，manifest.json 记录各文件的记录数与 SHA-256
+func (s *AuditUserDataService) Export(ctx context.Context, userID uint, operator string, w io.Writer) (*UserDataManifest, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var logins, operations []models.OperationLog
+	if err := s.userOperationLogs(user).Where("module = ?", constants.ModuleAuth).Order("id ASC").Find(&logins).Error; err != nil {
+		return nil, err
+	}
+	if err := s.userOperationLogs(user).Where("module <> ?", constants.ModuleAuth).
+		Preload("Snapshots").Order("id ASC").Find(&operations).Error; err != nil {
+		return nil, err
+	}
+
+	var sessions []models.TerminalSession
+	if err := s.db.Unscoped().Where("user_id = ?", user.ID).Order("id ASC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	sessionIDs := make([]uint, 0, len(sessions))
+	for _, sess := range sessions {
+		sessionIDs = append(sessionIDs, sess.ID)
+	}
+	var commands []models.TerminalCommand
+	var events []models.TerminalSessionEvent
+	if err := s.db.Where("session_id IN ?", sessionIDs).Order("id ASC").Find(&commands).Error; err != nil {
+		return nil, err
+	}
+	// 会话事件包括本人会话中的事件与本人旁观/协同其他会话的事件
+	if err := s.db.Where("session_id IN ? OR actor_id = ?", sessionIDs, user.ID).Order("id ASC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	var forwards []models.PortForwardSession
+	if err := s.db.Where("user_id = ?", user.ID).Order("id ASC").Find(&forwards).Error; err != nil {
+		return nil, err
+	}
+
+	manifest := &UserDataManifest{
+		UserID:      user.ID,
+		Username:    user.Username,
+		GeneratedAt: time.Now(),
+		GeneratedBy: operator,
+	}
+	z := &userDataZip{zw: zip.NewWriter(w), manifest: manifest}
+	if err := z.writeJSON("user.json", user); err != nil {
+		return nil, err
+	}
+	if err := writeUserDataLines(z, "logins.jsonl", logins); err != nil {
+		return nil, err
+	}
+	if err := writeUserDataLines(z, "operations.jsonl", operations); err != nil {
+		return nil, err
+	}
+	if err := writeUserDataLines(z, "terminal_sessions.jsonl", sessions); err != nil {
+		return nil, err
+	}
+	if err := writeUserDataLines(z, "terminal_commands.jsonl", commands); err != nil {
+		return nil, err
+	}
+	if err := writeUserDataLines(z, "terminal_session_events.jsonl", events); err != nil {
+		return nil, err
+	}
+	if err := writeUserDataLines(z, "port_forwards.jsonl", forwards); err != nil {
+		return nil, err
+	}
+
+	for _, sess := range sessions {
+		if sess.ReplayPath == "" {
+			continue
+		}
+		if s.replays == nil {
+			manifest.Errors = append(manifest.Errors, fmt.Sprintf("会话 %d 的录像未导出：未启用录像存储", sess.ID))
+			continue
+		}
+		if err := s.exportReplay(ctx, z, &sess); err != nil {
+			// 录像读取失败不中断导出，已写入的部分仍可在清单中核对
+			manifest.Errors = append(manifest.Errors, fmt.Sprintf("会话 %d 的录像未导出: %v", sess.ID, err))
+		}
+	}
+
+	if err := z.writeJSON("manifest.json", manifest); err != nil {
+		return nil, err
+	}
+	return manifest, z.zw.Close()
+}
+
+// exportReplay 录像本身已是 gzip，按原样存入导出包
+func (s *AuditUserDataService) exportReplay(ctx context.Context, z *userDataZip, sess *models.TerminalSession) error {
+	rc, err := s.replays.Open(ctx, sess.ReplayPath, sess.ReplaySize, sess.ReplaySHA256)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	w, done, err := z.create(fmt.Sprintf("replays/%d.cast.gz", sess.ID), zip.Store)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, rc); err != nil {
+		return err
+	}
+	done()
+	return nil
+}
+
+// PseudonymizeResult 匿名化结果
+type PseudonymizeResult struct {
+	UserID        uint   `json:"user_id"`
+	Pseudonym     string `json:"pseudonym"`
+	OperationLogs int64  `json:"operation_logs"` // 改写的操作日志数
+	PortForwards  int64  `json:"port_forwards"`  // 改写的端口转发记录数
+	ResealedFrom  int64  `json:"resealed_from"`  // 操作日志哈希链从该序号起重新计算（0 表示未改写入链记录）
+}
+
+// Pseudonymize 将已删除用户在历史记录中的身份替换为假名：
+// 操作日志中的用户名及请求体中出现的用户名替换为假名，清空本人操作的客户端 IP 与 User-Agent，
+// 并清空用户记录的联系方式。用户ID保留，记录之间的关联不受影响。
+// 操作日志已入哈希链，改写后从第一条被改写的记录起重新计算哈希。
+func (s *AuditUserDataService) Pseudonymize(userID uint) (*PseudonymizeResult, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.DeletedAt.Valid {
+		return nil, ErrUserNotDeleted
+	}
+	result := &PseudonymizeResult{UserID: user.ID, Pseudonym: fmt.Sprintf("deleted-user-%d", user.ID)}
+
+	rewrite := func(tx *gorm.DB) (int64, error) {
+		return s.pseudonymizeOperationLogs(tx, user, result)
+	}
+	if s.chain != nil {
+		err = s.chain.Reseal(models.OperationLog{}.TableName(), rewrite)
+	} else {
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			_, err := rewrite(tx)
+			return err
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.PortForwardSession{}).Where("user_id = ?", user.ID).Update("username", result.Pseudonym)
+		if res.Error != nil {
+			return res.Error
+		}
+		result.PortForwards = res.RowsAffected
+		return tx.Unscoped().Model(user).Updates(map[string]interface{}{
+			"username":      result.Pseudonym,
+			"email":         "",
+			"display_name":  "",
+			"phone":         "",
+			"last_login_ip": "",
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("已匿名化用户: id=%d, logs=%d, resealed_from=%d", user.ID, result.OperationLogs, result.ResealedFrom)
+	return result, nil
+}
+
+// pseudonymizeOperationLogs 改写操作日志，返回被改写的入链记录的最小序号
+func (s *AuditUserDataService) pseudonymizeOperationLogs(tx *gorm.DB, user *models.User, result *PseudonymizeResult) (int64, error) {
+	// 请求体为 JSON，按 JSON 字符串匹配用户名
+	quotedJSON, _ := json.Marshal(user.Username)
+	replacementJSON, _ := json.Marshal(result.Pseudonym)
+	quoted, replacement := string(quotedJSON), string(replacementJSON)
+
+	var logs []models.OperationLog
+	// LIKE 中用户名里的通配符只会扩大匹配范围，是否包含用户名在下面逐条判断
+	if err := tx.Where("user_id = ? OR username = ? OR approver_id = ? OR request_body LIKE ?", user.ID, user.Username, user.ID, "%"+quoted+"%").
+		Order("id ASC").Find(&logs).Error; err != nil {
+		return 0, err
+	}
+
+	var fromSeq int64
+	for i := range logs {
+		l := &logs[i]
+		updates := map[string]interface{}{}
+		own := (l.UserID != nil && *l.UserID == user.ID) || l.Username == user.Username
+		if own {
+			updates["username"] = result.Pseudonym
+			updates["client_ip"] = ""
+			updates["user_agent"] = ""
+		}
+		if l.ApproverID != nil && *l.ApproverID == user.ID {
+			updates["approver_name"] = result.Pseudonym
+		}
+		if strings.Contains(l.RequestBody, quoted) {
+			updates["request_body"] = strings.ReplaceAll(l.RequestBody, quoted, replacement)
+		}
+		if len(updates) == 0 {
+			continue
+		}
+		if err := tx.Model(l).Updates(updates).Error; err != nil {
+			return 0, err
+		}
+		result.OperationLogs++
+		if seq := l.ChainSeq; seq != nil && (fromSeq == 0 || *seq < fromSeq) {
+			fromSeq = *seq
+		}
+	}
+	result.ResealedFrom = fromSeq
+	return fromSeq, nil
+}
@@ -8,6 +8,7 @@ import (
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
 )
@@ -55,20 +56,20 @@ func (s *AuthService) Login(username, password, authType, clientIP string) (*Log
 	case "local":
 		user, err = s.authenticateLocal(username, password)
 	default:
-		return nil, fmt.Errorf("不支持的认证类型")
+		return nil, errcode.New(errcode.UnsupportedAuthType)
 	}
 	if err != nil {
 		return nil, err
 	}
 
 	if user.Status != "active" {
-		return nil, fmt.Errorf("用户账号已被禁用")
+		return nil, errcode.New(errcode.AccountDisabled)
 	}
 
 	// 生成JWT token
 	tokenString, expiresAt, err := s.generateJWT(user)
 	if err != nil {
-		return nil, fmt.Errorf("JWT token生成失败: %w", err)
+		return nil, errcode.Wrap(err, errcode.TokenSignFailed)
 	}
 
 	// 更新最后登录时间和IP
@@ -94,27 +95,27 @@ func (s *AuthService) Login(username, password, authType, clientIP string) (*Log
 func (s *AuthService) ChangePassword(userID uint, oldPassword, newPassword string) error {
 	var user models.User
 	if err := s.db.First(&user, userID).Error; err != nil {
-		return fmt.Errorf("用户不存在")
+		return errcode.New(errcode.UserNotFound)
 	}
 
 	if user.AuthType == "ldap" {
-		return fmt.Errorf("LDAP用户不能在此修改密码")
+		return errcode.New(errcode.LDAPPasswordReadOnly)
 	}
 
 	// 验证旧密码
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(oldPassword+user.Salt)); err != nil {
-		return fmt.Errorf("原密码错误")
+		return errcode.New(errcode.WrongPassword)
 	}
 
 	// 生成新密码哈希
 	newHashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword+user.Salt), bcrypt.DefaultCost)
 	if err != nil {
-		return fmt.Errorf("密码加密失败: %w", err)
+		return errcode.Wrap(err, errcode.PasswordUpdateFailed)
 	}
 
 	user.PasswordHash = string(newHashedPassword)
 	if err := s.db.Save(&user).Error; err != nil {
-		return fmt.Errorf("密码更新失败: %w", err)
+		return errcode.Wrap(err, errcode.PasswordUpdateFailed)
 	}
 
 	logger.Info("用户修改密码成功: %s", user.Username)
@@ -125,7 +126,7 @@ func (s *AuthService) ChangePassword(userID uint, oldPassword, newPassword strin
 func (s *AuthService) GetProfile(userID uint) (*models.User, error) {
 	var user models.User
 	if err := s.db.First(&user, userID).Error; err != nil {
-		return nil, fmt.Errorf("用户不存在")
+		return nil, errcode.New(errcode.UserNotFound)
 	}
 	return &user, nil
 }
@@ -143,7 +144,7 @@ func (s *AuthService) GetAuthStatus() (bool, error) {
 func (s *AuthService) authenticateLocal(username, password string) (*models.User, error) {
 	var user models.User
 	if err := s.db.Where("username = ?", username).First(&user).Error; err != nil {
-		return nil, fmt.Errorf("用户名或密码错误")
+		return nil, errcode.New(errcode.InvalidCredentials)
 	}
 
 	passwordWithSalt := password + user.Salt
@@ -151,7 +152,7 @@ func (s *AuthService) authenticateLocal(username, password string) (*models.User
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(passwordWithSalt)); err != nil {
 		logger.Warn("密码验证失败 - 用户: %s, 错误: %v", username, err)
-		return nil, fmt.Errorf("用户名或密码错误")
+		return nil, errcode.New(errcode.InvalidCredentials)
 	}
 
 	return &user, nil
@@ -161,16 +162,16 @@ func (s *AuthService) authenticateLocal(username, password string) (*models.User
 func (s *AuthService) authenticateLDAP(username, password string) (*models.User, error) {
 	ldapConfig, err := s.ldapService.GetLDAPConfig()
 	if err != nil {
-		return nil, fmt.Errorf("获取LDAP配置失败")
+		return nil, errcode.New(errcode.LDAPConfigFailed)
 	}
 
 	if !ldapConfig.Enabled {
-		return nil, fmt.Errorf("LDAP认证未启用")
+		return nil, errcode.New(errcode.LDAPDisabled)
 	}
 
 	ldapUser, err := s.ldapService.Authenticate(username, password)
 	if err != nil {
-		return nil, fmt.Errorf("LDAP认证失败: %v", err)
+		return nil, errcode.Wrap(err, errcode.LDAPAuthFailed)
 	}
 
 	var user models.User
@@ -185,11 +186,11 @@ func (s *AuthService) authenticateLDAP(username, password string) (*models.User,
 			Status:      "active",
 		}
 		if err := s.db.Create(&user).Error; err != nil {
-			return nil, fmt.Errorf("创建用户记录失败")
+			return nil, errcode.New(errcode.UserCreateFailed)
 		}
 		logger.Info("LDAP用户首次登录，已创建本地记录: %s", username)
 	} else if result.Error != nil {
-		return nil, fmt.Errorf("查询用户失败")
+		return nil, errcode.New(errcode.UserQueryFailed)
 	} else {
 		user.Email = ldapUser.Email
 		user.DisplayName = ldapUser.DisplayName
@@ -0,0 +1,541 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// changeApprovalCacheTTL 审批策略缓存有效期（增删改时会主动失效）
+const changeApprovalCacheTTL = 30 * time.Second
+
+// changeRequestResultLimit 保存的执行结果响应体上限
+const changeRequestResultLimit = 64 << 10
+
+// changeRequestDefaultExpireHours 未配置有效期时待审批变更的有效期
+const changeRequestDefaultExpireHours = 24
+
+// ErrNotChangeApprover 当前用户不在变更的审批用户组中
+var ErrNotChangeApprover = errcode.New(errcode.ChangeApproverRequired)
+
+// ChangeExecution 服务端执行已批准变更时的身份信息，随请求上下文传递（外部请求无法伪造）
+type ChangeExecution struct {
+	RequestID     uint
+	RequesterID   uint
+	RequesterName string
+	ApproverID    uint
+	ApproverName  string
+}
+
+type changeExecutionKey struct{}
+
+// WithChangeExecution 将变更执行信息写入上下文
+func WithChangeExecution(ctx context.Context, exec *ChangeExecution) context.Context {
+	return context.WithValue(ctx, changeExecutionKey{}, exec)
+}
+
+// ChangeExecutionFrom 从上下文读取变更执行信息，普通请求返回 nil
+func ChangeExecutionFrom(ctx context.Context) *ChangeExecution {
+	exec, _ := ctx.Value(changeExecutionKey{}).(*ChangeExecution)
+	return exec
+}
+
+// ChangeRequestService 变更双人审批服务：维护审批策略、保存待审批变更，批准后由服务端重放原始请求
+type ChangeRequestService struct {
+	db *gorm.DB
+
+	// executor 执行已批准变更的 HTTP 处理器（路由注册完成后设置为 gin 引擎）
+	executor http.Handler
+
+	mu       sync.RWMutex
+	cache    []models.ChangeApprovalPolicy
+	loadedAt time.Time
+}
+
+// NewChangeRequestService 创建变更审批服务
+func NewChangeRequestService(db *gorm.DB) *ChangeRequestService {
+	return &ChangeRequestService{db: db}
+}
+
+// SetExecutor 设置执行已批准变更的 HTTP 处理器
+func (s *ChangeRequestService) SetExecutor(executor http.Handler) {
+	s.executor = executor
+}
+
+// ========== 审批策略 ==========
+
+// ChangeApprovalPolicyRequest 创建/更新审批策略请求
+type ChangeApprovalPolicyRequest struct {
+	Name            string            `json:"name" binding:"required"`
+	Description     string            `json:"description"`
+	ClusterSelector map[string]string `json:"cluster_selector"`
+	Resources       []string          `json:"resources"`
+	Actions         []string          `json:"actions" binding:"required"`
+	ApproverGroupID uint              `json:"approver_group_id" binding:"required"`
+	ExpireHours     int               `json:"expire_hours"`
+	Enabled         *bool             `json:"enabled"`
+}
+
+// applyTo 校验并写入策略模型
+func (b *ChangeApprovalPolicyRequest) applyTo(p *models.ChangeApprovalPolicy) error {
+	if len(b.Actions) == 0 {
+		return errcode.New(errcode.ApprovalActionsRequired)
+	}
+	for _, action := range b.Actions {
+		if action != "*" && IsReadPolicyAction(action) {
+			return errcode.New(errcode.ReadOnlyActionNoApproval, action)
+		}
+	}
+	for _, list := range [][]string{b.Resources, b.Actions} {
+		for _, pattern := range list {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return errcode.New(errcode.InvalidApprovalPattern, pattern)
+			}
+		}
+	}
+	if b.ExpireHours < 0 {
+		return errcode.New(errcode.NegativeApprovalTTL)
+	}
+
+	p.Name = b.Name
+	p.Description = b.Description
+	p.ClusterSelector = encodeJSONOrEmpty(b.ClusterSelector, len(b.ClusterSelector) == 0)
+	p.Resources = encodeJSONOrEmpty(b.Resources, len(b.Resources) == 0)
+	p.Actions = encodeJSONOrEmpty(b.Actions, false)
+	p.ApproverGroupID = b.ApproverGroupID
+	p.ExpireHours = b.ExpireHours
+	if p.ExpireHours == 0 {
+		p.ExpireHours = changeRequestDefaultExpireHours
+	}
+	p.Enabled = b.Enabled == nil || *b.Enabled
+	return nil
+}
+
+// checkApproverGroup 校验审批用户组存在
+func (s *ChangeRequestService) checkApproverGroup(groupID uint) error {
+	var count int64
+	s.db.Model(&models.UserGroup{}).Where("id = ?", groupID).Count(&count)
+	if count == 0 {
+		return errcode.New(errcode.ApproverGroupNotFound)
+	}
+	return nil
+}
+
+// CreatePolicy 创建审批策略
+func (s *ChangeRequestService) CreatePolicy(body *ChangeApprovalPolicyRequest) (*models.ChangeApprovalPolicy, error) {
+	policy := &models.ChangeApprovalPolicy{}
+	if err := body.applyTo(policy); err != nil {
+		return nil, err
+	}
+	if err := s.checkApproverGroup(policy.ApproverGroupID); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(policy).Error; err != nil {
+		return nil, fmt.Errorf("创建审批策略失败: %w", err)
+	}
+	s.invalidate()
+	logger.Info("创建变更审批策略: id=%d, name=%s", policy.ID, policy.Name)
+	return s.GetPolicy(policy.ID)
+}
+
+// UpdatePolicy 更新审批策略
+func (s *ChangeRequestService) UpdatePolicy(id uint, body *ChangeApprovalPolicyRequest) (*models.ChangeApprovalPolicy, error) {
+	var policy models.ChangeApprovalPolicy
+	if err := s.db.First(&policy, id).Error; err != nil {
+		return nil, errcode.New(errcode.ApprovalPolicyNotFound)
+	}
+	if err := body.applyTo(&policy); err != nil {
+		return nil, err
+	}
+	if err := s.checkApproverGroup(policy.ApproverGroupID); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(&policy).Error; err != nil {
+		return nil, fmt.Errorf("更新审批策略失败: %w", err)
+	}
+	s.invalidate()
+	return s.GetPolicy(id)
+}
+
+// DeletePolicy 删除审批策略（已提交的变更不受影响）
+func (s *ChangeRequestService) DeletePolicy(id uint) error {
+	result := s.db.Delete(&models.ChangeApprovalPolicy{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除审批策略失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errcode.New(errcode.ApprovalPolicyNotFound)
+	}
+	s.invalidate()
+	return nil
+}
+
+// GetPolicy 获取审批策略详情
+func (s *ChangeRequestService) GetPolicy(id uint) (*models.ChangeApprovalPolicy, error) {
+	var policy models.ChangeApprovalPolicy
+	if err := s.db.Preload("ApproverGroup").First(&policy, id).Error; err != nil {
+		return nil, errcode.New(errcode.ApprovalPolicyNotFound)
+	}
+	return &policy, nil
+}
+
+// ListPolicies 获取审批策略列表
+func (s *ChangeRequestService) ListPolicies() ([]models.ChangeApprovalPolicy, error) {
+	var policies []models.ChangeApprovalPolicy
+	if err := s.db.Preload("ApproverGroup").Order("id ASC").Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("获取审批策略失败: %w", err)
+	}
+	return policies, nil
+}
+
+// enabledPolicies 获取已启用的审批策略（带缓存）
+func (s *ChangeRequestService) enabledPolicies() ([]models.ChangeApprovalPolicy, error) {
+	s.mu.RLock()
+	if s.cache != nil && time.Since(s.loadedAt) < changeApprovalCacheTTL {
+		policies := s.cache
+		s.mu.RUnlock()
+		return policies, nil
+	}
+	s.mu.RUnlock()
+
+	var policies []models.ChangeApprovalPolicy
+	if err := s.db.Where("enabled = ?", true).Order("id ASC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache = policies
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return policies, nil
+}
+
+// invalidate 使策略缓存失效
+func (s *ChangeRequestService) invalidate() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}
+
+// MatchChangeApprovalPolicy 查找要求审批的策略，多条命中时取第一条
+func MatchChangeApprovalPolicy(policies []models.ChangeApprovalPolicy, clusterLabels map[string]string, resource, action string) *models.ChangeApprovalPolicy {
+	for i := range policies {
+		p := &policies[i]
+		if !p.Enabled || !models.MatchLabels(p.GetClusterSelector(), clusterLabels) {
+			continue
+		}
+		if resources := p.GetResourceList(); len(resources) > 0 && !matchPolicyPattern(resources, resource) {
+			continue
+		}
+		if matchPolicyPattern(p.GetActionList(), action) {
+			return p
+		}
+	}
+	return nil
+}
+
+// Match 判定写请求是否需要审批，不需要时返回 nil（无审批策略时不查询集群标签）
+func (s *ChangeRequestService) Match(clusterID uint, resource, action string) (*models.ChangeApprovalPolicy, error) {
+	policies, err := s.enabledPolicies()
+	if err != nil {
+		return nil, fmt.Errorf("加载审批策略失败: %w", err)
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+
+	var cluster models.Cluster
+	if err := s.db.Select("id", "labels").First(&cluster, clusterID).Error; err != nil {
+		return nil, fmt.Errorf("获取集群标签失败: %w", err)
+	}
+	return MatchChangeApprovalPolicy(policies, cluster.GetLabels(), resource, action), nil
+}
+
+// ========== 待审批变更 ==========
+
+// Submit 保存命中审批策略的写请求为待审批变更
+func (s *ChangeRequestService) Submit(policy *models.ChangeApprovalPolicy, change *models.ChangeRequest) (*models.ChangeRequest, error) {
+	expireHours := policy.ExpireHours
+	if expireHours <= 0 {
+		expireHours = changeRequestDefaultExpireHours
+	}
+	change.PolicyID = policy.ID
+	change.PolicyName = policy.Name
+	change.ApproverGroupID = policy.ApproverGroupID
+	change.Status = models.ChangeRequestStatusPending
+	change.ExpiresAt = time.Now().Add(time.Duration(expireHours) * time.Hour)
+	if err := s.db.Create(change).Error; err != nil {
+		return nil, fmt.Errorf("保存待审批变更失败: %w", err)
+	}
+	logger.Info("提交待审批变更: id=%d, policy=%s, user=%s, %s %s", change.ID, policy.Name, change.RequesterName, change.Method, change.Path)
+	return change, nil
+}
+
+// ChangeRequestListRequest 待审批变更查询条件
+type ChangeRequestListRequest struct {
+	// ViewerID 非 0 时只返回该用户提交的、或由其所在用户组审批的变更
+	ViewerID  uint
+	Mine      bool
+	ClusterID uint
+	Status    string
+	Page      int
+	PageSize  int
+}
+
+// approverGroupIDs 用户所在的用户组
+func (s *ChangeRequestService) approverGroupIDs(userID uint) []uint {
+	var groupIDs []uint
+	s.db.Model(&models.UserGroupMember{}).Where("user_id = ?", userID).Pluck("user_group_id", &groupIDs)
+	return groupIDs
+}
+
+// ListRequests 获取待审批变更列表
+func (s *ChangeRequestService) ListRequests(req *ChangeRequestListRequest) ([]models.ChangeRequest, int64, error) {
+	query := s.db.Model(&models.ChangeRequest{})
+	if req.ViewerID > 0 {
+		groupIDs := s.approverGroupIDs(req.ViewerID)
+		if req.Mine || len(groupIDs) == 0 {
+			query = query.Where("requester_id = ?", req.ViewerID)
+		} else {
+			query = query.Where("requester_id = ? OR approver_group_id IN ?", req.ViewerID, groupIDs)
+		}
+	}
+	if req.ClusterID > 0 {
+		query = query.Where("cluster_id = ?", req.ClusterID)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计变更数量失败: %w", err)
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+
+	var items []models.ChangeRequest
+	err := query.Omit("body", "result_body").Preload("Cluster", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "name")
+	}).Order("id DESC").Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).Find(&items).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("获取变更列表失败: %w", err)
+	}
+	return items, total, nil
+}
+
+// GetRequest 获取待审批变更详情（含原始请求体与执行结果）
+func (s *ChangeRequestService) GetRequest(id uint) (*models.ChangeRequest, error) {
+	var change models.ChangeRequest
+	err := s.db.Preload("Cluster", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") }).First(&change, id).Error
+	if err != nil {
+		return nil, errcode.New(errcode.ChangeRequestNotFound)
+	}
+	return &change, nil
+}
+
+// IsApprover 判断用户是否为变更审批用户组成员
+func (s *ChangeRequestService) IsApprover(userID uint, change *models.ChangeRequest) bool {
+	return isChangeApprover(s.db, userID, change)
+}
+
+func isChangeApprover(db *gorm.DB, userID uint, change *models.ChangeRequest) bool {
+	var count int64
+	db.Model(&models.UserGroupMember{}).
+		Where("user_id = ? AND user_group_id = ?", userID, change.ApproverGroupID).
+		Count(&count)
+	return count > 0
+}
+
+// CanView 申请人与审批用户组成员可以查看变更
+func (s *ChangeRequestService) CanView(userID uint, change *models.ChangeRequest) bool {
+	return change.RequesterID == userID || s.IsApprover(userID, change)
+}
+
+// Approve 批准变更（审批人不能是申请人），随后由服务端以申请人身份执行原始请求
+func (s *ChangeRequestService) Approve(id, approverID uint, approverName, comment string) (*models.ChangeRequest, error) {
+	var change models.ChangeRequest
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&change, id).Error; err != nil {
+			return errcode.New(errcode.ChangeRequestNotFound)
+		}
+		if change.Status != models.ChangeRequestStatusPending {
+			return errcode.New(errcode.ChangeRequestNotPending, change.Status)
+		}
+		if change.RequesterID == approverID {
+			return errcode.New(errcode.ChangeSelfApproval)
+		}
+		if !isChangeApprover(tx, approverID, &change) {
+			return ErrNotChangeApprover
+		}
+		if time.Now().After(change.ExpiresAt) {
+			// 状态由到期任务置为 expired
+			return errcode.New(errcode.ChangeRequestExpired)
+		}
+
+		now := time.Now()
+		change.Status = models.ChangeRequestStatusApproved
+		change.ApproverID = &approverID
+		change.ApproverName = approverName
+		change.ReviewComment = comment
+		change.ReviewedAt = &now
+		return tx.Model(&change).Updates(map[string]interface{}{
+			"status":         change.Status,
+			"approver_id":    approverID,
+			"approver_name":  approverName,
+			"review_comment": comment,
+			"reviewed_at":    now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("变更已批准: id=%d, requester=%s, approver=%s", change.ID, change.RequesterName, approverName)
+	go s.execute(&change)
+	return s.GetRequest(id)
+}
+
+// Reject 拒绝变更
+func (s *ChangeRequestService) Reject(id, approverID uint, approverName, comment string) (*models.ChangeRequest, error) {
+	change, err := s.GetRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	if change.RequesterID == approverID {
+		return nil, errcode.New(errcode.ChangeSelfReject)
+	}
+	if !s.IsApprover(approverID, change) {
+		return nil, ErrNotChangeApprover
+	}
+
+	result := s.db.Model(&models.ChangeRequest{}).
+		Where("id = ? AND status = ?", id, models.ChangeRequestStatusPending).
+		Updates(map[string]interface{}{
+			"status":         models.ChangeRequestStatusRejected,
+			"approver_id":    approverID,
+			"approver_name":  approverName,
+			"review_comment": comment,
+			"reviewed_at":    time.Now(),
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("拒绝变更失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errcode.New(errcode.ChangeRequestProcessed)
+	}
+	return s.GetRequest(id)
+}
+
+// Cancel 申请人撤回待审批的变更
+func (s *ChangeRequestService) Cancel(id, userID uint) error {
+	result := s.db.Model(&models.ChangeRequest{}).
+		Where("id = ? AND requester_id = ? AND status = ?", id, userID, models.ChangeRequestStatusPending).
+		Update("status", models.ChangeRequestStatusCanceled)
+	if result.Error != nil {
+		return fmt.Errorf("撤回变更失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errcode.New(errcode.ChangeRequestProcessed)
+	}
+	return nil
+}
+
+// execute 以申请人身份重放原始请求：请求经过完整的鉴权、权限与冻结检查，操作日志同时记录申请人与审批人
+func (s *ChangeRequestService) execute(change *models.ChangeRequest) {
+	status, result := s.replay(change)
+	finalStatus := models.ChangeRequestStatusExecuted
+	if status >= http.StatusBadRequest || status == 0 {
+		finalStatus = models.ChangeRequestStatusFailed
+	}
+	if err := s.db.Model(change).Updates(map[string]interface{}{
+		"status":        finalStatus,
+		"executed_at":   time.Now(),
+		"result_status": status,
+		"result_body":   result,
+	}).Error; err != nil {
+		logger.Error("更新变更执行结果失败: id=%d, err=%v", change.ID, err)
+	}
+	logger.Info("变更执行完成: id=%d, status=%s, http=%d", change.ID, finalStatus, status)
+}
+
+// replay 重放原始请求，返回状态码与（截断后的）响应体
+func (s *ChangeRequestService) replay(change *models.ChangeRequest) (int, string) {
+	if s.executor == nil {
+		return 0, "变更执行器未初始化"
+	}
+	target := change.Path
+	if change.Query != "" {
+		target += "?" + change.Query
+	}
+	approverID := uint(0)
+	if change.ApproverID != nil {
+		approverID = *change.ApproverID
+	}
+	ctx := WithChangeExecution(context.Background(), &ChangeExecution{
+		RequestID:     change.ID,
+		RequesterID:   change.RequesterID,
+		RequesterName: change.RequesterName,
+		ApproverID:    approverID,
+		ApproverName:  change.ApproverName,
+	})
+	req, err := http.NewRequestWithContext(ctx, change.Method, target, bytes.NewReader([]byte(change.Body)))
+	if err != nil {
+		return 0, "构造请求失败: " + err.Error()
+	}
+	req.RemoteAddr = "127.0.0.1:0"
+	if change.ContentType != "" {
+		req.Header.Set("Content-Type", change.ContentType)
+	}
+	if change.FreezeOverrideReason != "" {
+		req.Header.Set(FreezeOverrideHeader, url.QueryEscape(change.FreezeOverrideReason))
+	}
+
+	rec := httptest.NewRecorder()
+	s.executor.ServeHTTP(rec, req)
+	body := rec.Body.String()
+	if len(body) > changeRequestResultLimit {
+		body = body[:changeRequestResultLimit]
+	}
+	return rec.Code, body
+}
+
+// ExpireDue 将超过有效期仍未审批的变更标记为过期，返回处理数量
+func (s *ChangeRequestService) ExpireDue() int64 {
+	result := s.db.Model(&models.ChangeRequest{}).
+		Where("status = ? AND expires_at <= ?", models.ChangeRequestStatusPending, time.Now()).
+		Update("status", models.ChangeRequestStatusExpired)
+	if result.Error != nil {
+		logger.Error("标记过期变更失败: %v", result.Error)
+		return 0
+	}
+	return result.RowsAffected
+}
+
+// StartExpiryWorker 启动待审批变更过期任务
+func (s *ChangeRequestService) StartExpiryWorker(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if n := s.ExpireDue(); n > 0 {
+			logger.Info("已过期待审批变更: %d", n)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+func TestMatchChangeApprovalPolicy(t *testing.T) {
+	policies := []models.ChangeApprovalPolicy{
+		{ID: 1, Name: "disabled", Enabled: false, Actions: `["*"]`},
+		{ID: 2, Name: "prod-storage", Enabled: true, ClusterSelector: `{"env":"prod"}`, Resources: `["namespaces","persistentvolumes","storageclasses"]`, Actions: `["delete"]`},
+		{ID: 3, Name: "prod-nodes", Enabled: true, ClusterSelector: `{"env":"prod"}`, Resources: `["nodes"]`, Actions: `["drain"]`},
+	}
+	prod := map[string]string{"env": "prod"}
+
+	if p := MatchChangeApprovalPolicy(policies, prod, "namespaces", "delete"); p == nil || p.ID != 2 {
+		t.Fatalf("expected prod-storage policy, got %+v", p)
+	}
+	if p := MatchChangeApprovalPolicy(policies, prod, "nodes", "drain"); p == nil || p.ID != 3 {
+		t.Fatalf("expected prod-nodes policy, got %+v", p)
+	}
+	if p := MatchChangeApprovalPolicy(policies, prod, "namespaces", "create"); p != nil {
+		t.Fatalf("create should not require approval, got %+v", p)
+	}
+	if p := MatchChangeApprovalPolicy(policies, map[string]string{"env": "dev"}, "namespaces", "delete"); p != nil {
+		t.Fatalf("dev cluster should not require approval, got %+v", p)
+	}
+}
+
+func TestChangeRequestApproveExecutesAsRequester(t *testing.T) {
+	db := newAuditChainTestDB(t)
+	if err := db.AutoMigrate(&models.Cluster{}, &models.UserGroup{}, &models.UserGroupMember{},
+		&models.ChangeApprovalPolicy{}, &models.ChangeRequest{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	cluster := models.Cluster{Name: "prod-1", APIServer: "https://prod-1:6443", Labels: `{"env":"prod"}`}
+	group := models.UserGroup{Name: "sre"}
+	if err := db.Create(&cluster).Error; err != nil {
+		t.Fatalf("create cluster: %v", err)
+	}
+	if err := db.Create(&group).Error; err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	if err := db.Create(&models.UserGroupMember{UserGroupID: group.ID, UserID: 2}).Error; err != nil {
+		t.Fatalf("create member: %v", err)
+	}
+
+	svc := NewChangeRequestService(db)
+	executed := make(chan *ChangeExecution, 1)
+	svc.SetExecutor(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		executed <- ChangeExecutionFrom(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	if _, err := svc.CreatePolicy(&ChangeApprovalPolicyRequest{Name: "bad", Actions: []string{"get"}, ApproverGroupID: group.ID}); err == nil {
+		t.Fatal("expected read-only action to be rejected")
+	}
+	if _, err := svc.CreatePolicy(&ChangeApprovalPolicyRequest{
+		Name: "prod-namespaces", ClusterSelector: map[string]string{"env": "prod"},
+		Resources: []string{"namespaces"}, Actions: []string{"delete"}, ApproverGroupID: group.ID,
+	}); err != nil {
+		t.Fatalf("create policy: %v", err)
+	}
+
+	policy, err := svc.Match(cluster.ID, "namespaces", "delete")
+	if err != nil || policy == nil {
+		t.Fatalf("expected policy match, got %+v, %v", policy, err)
+	}
+	change, err := svc.Submit(policy, &models.ChangeRequest{
+		RequesterID: 1, RequesterName: "alice", ClusterID: cluster.ID, Namespace: "shop",
+		Resource: "namespaces", Action: "delete", Method: http.MethodDelete,
+		Path: "/api/v1/clusters/1/namespaces/shop",
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	if _, err := svc.Approve(change.ID, 1, "alice", ""); err == nil {
+		t.Fatal("requester must not approve own change")
+	}
+	if _, err := svc.Approve(change.ID, 3, "mallory", ""); err != ErrNotChangeApprover {
+		t.Fatalf("expected ErrNotChangeApprover, got %v", err)
+	}
+	if _, err := svc.Approve(change.ID, 2, "bob", "ok"); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+
+	select {
+	case exec := <-executed:
+		if exec == nil || exec.RequesterID != 1 || exec.ApproverID != 2 || exec.ApproverName != "bob" {
+			t.Fatalf("unexpected execution identity: %+v", exec)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("approved change was not executed")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := svc.GetRequest(change.ID)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.Status == models.ChangeRequestStatusExecuted {
+			if got.ResultStatus != http.StatusOK {
+				t.Fatalf("unexpected result status: %d", got.ResultStatus)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("change status = %s, want executed", got.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := svc.Approve(change.ID, 2, "bob", ""); err == nil {
+		t.Fatal("executed change must not be approved twice")
+	}
+}
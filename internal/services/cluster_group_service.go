@@ -0,0 +1,190 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// ClusterGroupService 集群分组服务
+type ClusterGroupService struct {
+	db *gorm.DB
+}
+
+// NewClusterGroupService 创建集群分组服务
+func NewClusterGroupService(db *gorm.DB) *ClusterGroupService {
+	return &ClusterGroupService{db: db}
+}
+
+// ClusterGroupRequest 创建/更新集群分组请求
+type ClusterGroupRequest struct {
+	Name        string            `json:"name" binding:"required"`
+	Description string            `json:"description"`
+	Selector    map[string]string `json:"selector" binding:"required"`
+}
+
+// ClusterGroupResponse 集群分组响应
+type ClusterGroupResponse struct {
+	ID           uint              `json:"id"`
+	Name         string            `json:"name"`
+	Description  string            `json:"description"`
+	Selector     map[string]string `json:"selector"`
+	ClusterIDs   []uint            `json:"cluster_ids"`
+	ClusterCount int               `json:"cluster_count"`
+}
+
+// CreateGroup 创建集群分组
+func (s *ClusterGroupService) CreateGroup(req *ClusterGroupRequest) (*models.ClusterGroup, error) {
+	if len(req.Selector) == 0 {
+		return nil, errcode.New(errcode.ClusterGroupSelectorRequired)
+	}
+	selectorJSON, _ := json.Marshal(req.Selector)
+	group := &models.ClusterGroup{
+		Name:        req.Name,
+		Description: req.Description,
+		Selector:    string(selectorJSON),
+	}
+	if err := s.db.Create(group).Error; err != nil {
+		return nil, fmt.Errorf("创建集群分组失败: %w", err)
+	}
+	logger.Info("创建集群分组: name=%s, selector=%s", group.Name, group.Selector)
+	return group, nil
+}
+
+// UpdateGroup 更新集群分组
+func (s *ClusterGroupService) UpdateGroup(id uint, req *ClusterGroupRequest) (*models.ClusterGroup, error) {
+	if len(req.Selector) == 0 {
+		return nil, errcode.New(errcode.ClusterGroupSelectorRequired)
+	}
+	group, err := s.GetGroup(id)
+	if err != nil {
+		return nil, err
+	}
+	selectorJSON, _ := json.Marshal(req.Selector)
+	group.Name = req.Name
+	group.Description = req.Description
+	group.Selector = string(selectorJSON)
+	if err := s.db.Save(group).Error; err != nil {
+		return nil, fmt.Errorf("更新集群分组失败: %w", err)
+	}
+	return group, nil
+}
+
+// DeleteGroup 删除集群分组（不影响已按选择器创建的权限）
+func (s *ClusterGroupService) DeleteGroup(id uint) error {
+	result := s.db.Delete(&models.ClusterGroup{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除集群分组失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errcode.New(errcode.ClusterGroupNotFound)
+	}
+	return nil
+}
+
+// GetGroup 获取集群分组
+func (s *ClusterGroupService) GetGroup(id uint) (*models.ClusterGroup, error) {
+	var group models.ClusterGroup
+	if err := s.db.First(&group, id).Error; err != nil {
+		return nil, errcode.New(errcode.ClusterGroupNotFound)
+	}
+	return &group, nil
+}
+
+// ListGroups 获取集群分组列表，附带当前匹配的集群
+func (s *ClusterGroupService) ListGroups() ([]ClusterGroupResponse, error) {
+	var groups []models.ClusterGroup
+	if err := s.db.Order("name ASC").Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("获取集群分组列表失败: %w", err)
+	}
+	var clusters []*models.Cluster
+	if err := s.db.Select("id", "labels").Find(&clusters).Error; err != nil {
+		return nil, fmt.Errorf("获取集群列表失败: %w", err)
+	}
+
+	result := make([]ClusterGroupResponse, 0, len(groups))
+	for i := range groups {
+		result = append(result, buildClusterGroupResponse(&groups[i], clusters))
+	}
+	return result, nil
+}
+
+// GetGroupDetail 获取集群分组详情
+func (s *ClusterGroupService) GetGroupDetail(id uint) (*ClusterGroupResponse, error) {
+	group, err := s.GetGroup(id)
+	if err != nil {
+		return nil, err
+	}
+	var clusters []*models.Cluster
+	if err := s.db.Select("id", "labels").Find(&clusters).Error; err != nil {
+		return nil, fmt.Errorf("获取集群列表失败: %w", err)
+	}
+	resp := buildClusterGroupResponse(group, clusters)
+	return &resp, nil
+}
+
+// buildClusterGroupResponse 构造分组响应并解析成员集群
+func buildClusterGroupResponse(group *models.ClusterGroup, clusters []*models.Cluster) ClusterGroupResponse {
+	selector := group.GetSelector()
+	members := FilterClustersByLabels(clusters, selector)
+	ids := make([]uint, 0, len(members))
+	for _, cluster := range members {
+		ids = append(ids, cluster.ID)
+	}
+	return ClusterGroupResponse{
+		ID:           group.ID,
+		Name:         group.Name,
+		Description:  group.Description,
+		Selector:     selector,
+		ClusterIDs:   ids,
+		ClusterCount: len(ids),
+	}
+}
+
+// FilterClustersByLabels 按标签选择器过滤集群，空选择器返回原列表
+func FilterClustersByLabels(clusters []*models.Cluster, selector map[string]string) []*models.Cluster {
+	if len(selector) == 0 {
+		return clusters
+	}
+	filtered := make([]*models.Cluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		if models.MatchLabels(selector, cluster.GetLabels()) {
+			filtered = append(filtered, cluster)
+		}
+	}
+	return filtered
+}
+
+// ResolveClusterLabelFilter 解析集群标签过滤条件
+// labelSelector 形如 "env=prod,region=eu"；clusterGroupID 指定分组时合并分组的选择器
+func ResolveClusterLabelFilter(db *gorm.DB, labelSelector, clusterGroupID string) (map[string]string, error) {
+	selector, err := models.ParseLabelSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	if clusterGroupID == "" {
+		return selector, nil
+	}
+
+	id, err := strconv.ParseUint(clusterGroupID, 10, 64)
+	if err != nil {
+		return nil, errcode.New(errcode.InvalidClusterGroupID)
+	}
+	var group models.ClusterGroup
+	if err := db.First(&group, id).Error; err != nil {
+		return nil, errcode.New(errcode.ClusterGroupNotFound)
+	}
+	for k, v := range group.GetSelector() {
+		if existing, ok := selector[k]; ok && existing != v {
+			return nil, errcode.New(errcode.ClusterGroupFilterConflict, k)
+		}
+		selector[k] = v
+	}
+	return selector, nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+func TestFilterClustersByLabelsMatchesAllSelectorKeys(t *testing.T) {
+	clusters := []*models.Cluster{
+		{ID: 1, Labels: `{"env":"prod","region":"eu"}`},
+		{ID: 2, Labels: `{"env":"prod","region":"us"}`},
+		{ID: 3, Labels: `{}`},
+	}
+
+	filtered := FilterClustersByLabels(clusters, map[string]string{"env": "prod", "region": "eu"})
+	if len(filtered) != 1 || filtered[0].ID != 1 {
+		t.Fatalf("expected only cluster 1, got %+v", filtered)
+	}
+
+	if all := FilterClustersByLabels(clusters, nil); len(all) != 3 {
+		t.Fatalf("empty selector should keep all clusters, got %d", len(all))
+	}
+}
+
+func TestParseLabelSelector(t *testing.T) {
+	selector, err := models.ParseLabelSelector(" env=prod , region=eu ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if selector["env"] != "prod" || selector["region"] != "eu" || len(selector) != 2 {
+		t.Fatalf("unexpected selector: %+v", selector)
+	}
+
+	if _, err := models.ParseLabelSelector("env"); err == nil {
+		t.Fatal("expected error for selector without value")
+	}
+}
+
+func TestExpandSelectorPermissionsPrefersExplicitGrant(t *testing.T) {
+	userID := uint(5)
+	permissions := []models.ClusterPermission{
+		{ID: 1, ClusterID: 2, UserID: &userID, PermissionType: models.PermissionTypeReadonly},
+		{ID: 2, ClusterSelector: `{"env":"prod"}`, UserID: &userID, PermissionType: models.PermissionTypeOps},
+	}
+	clusters := []models.Cluster{
+		{ID: 1, Labels: `{"env":"prod"}`},
+		{ID: 2, Labels: `{"env":"prod"}`},
+		{ID: 3, Labels: `{"env":"dev"}`},
+	}
+
+	expanded := expandSelectorPermissions(permissions, clusters)
+
+	byCluster := make(map[uint]models.ClusterPermission)
+	for _, p := range expanded {
+		byCluster[p.ClusterID] = p
+	}
+	if len(expanded) != 2 {
+		t.Fatalf("expected 2 permissions, got %d", len(expanded))
+	}
+	if byCluster[1].PermissionType != models.PermissionTypeOps {
+		t.Fatalf("cluster 1 should inherit selector grant, got %q", byCluster[1].PermissionType)
+	}
+	if byCluster[2].PermissionType != models.PermissionTypeReadonly {
+		t.Fatalf("explicit grant should win on cluster 2, got %q", byCluster[2].PermissionType)
+	}
+	if _, ok := byCluster[3]; ok {
+		t.Fatal("dev cluster should not match prod selector")
+	}
+}
+
+func TestSelectorGrantChangesOnLabelUpdate(t *testing.T) {
+	db := newAuditChainTestDB(t)
+	if err := db.AutoMigrate(&models.Cluster{}, &models.ClusterPermission{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	userID, groupID := uint(5), uint(7)
+	grants := []models.ClusterPermission{
+		{ClusterSelector: `{"env":"prod"}`, UserID: &userID, PermissionType: models.PermissionTypeOps, Namespaces: `["*"]`},
+		{ClusterSelector: `{"env":"dev"}`, UserID: &userID, PermissionType: models.PermissionTypeDev, Namespaces: `["shop"]`},
+		{ClusterSelector: `{"env":"prod"}`, UserGroupID: &groupID, PermissionType: models.PermissionTypeReadonly},
+		{ClusterID: 9, UserID: &userID, PermissionType: models.PermissionTypeAdmin},
+	}
+	for i := range grants {
+		if err := db.Create(&grants[i]).Error; err != nil {
+			t.Fatalf("create grant: %v", err)
+		}
+	}
+	svc := NewPermissionService(db)
+
+	// 导入集群：只有匹配的用户选择器授权需要创建绑定
+	matched, unmatched, err := svc.SelectorGrantChanges(3, nil, map[string]string{"env": "prod"})
+	if err != nil {
+		t.Fatalf("changes: %v", err)
+	}
+	if len(matched) != 1 || matched[0].ID != grants[0].ID || matched[0].ClusterID != 3 || len(unmatched) != 0 {
+		t.Fatalf("import should bind the prod user grant, got matched=%+v unmatched=%+v", matched, unmatched)
+	}
+
+	// prod -> dev：prod 授权不再匹配，dev 授权开始匹配
+	matched, unmatched, err = svc.SelectorGrantChanges(3, map[string]string{"env": "prod"}, map[string]string{"env": "dev"})
+	if err != nil {
+		t.Fatalf("changes: %v", err)
+	}
+	if len(matched) != 1 || matched[0].ID != grants[1].ID || len(unmatched) != 1 || unmatched[0].ID != grants[0].ID || unmatched[0].ClusterID != 3 {
+		t.Fatalf("unexpected changes: matched=%+v unmatched=%+v", matched, unmatched)
+	}
+
+	// 与标签无关的变化不触发同步
+	matched, unmatched, _ = svc.SelectorGrantChanges(3, map[string]string{"env": "dev"}, map[string]string{"env": "dev", "team": "a"})
+	if len(matched) != 0 || len(unmatched) != 0 {
+		t.Fatalf("unrelated label change should not reconcile, got matched=%+v unmatched=%+v", matched, unmatched)
+	}
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// clusterUnreachableThreshold 连续探测失败达到该次数后判定集群不可达，避免偶发抖动误报
+const clusterUnreachableThreshold = 2
+
+// clusterProbeTimeout 单次探测超时
+const clusterProbeTimeout = 10 * time.Second
+
+// ClusterHealthMonitor 定期探测集群 API Server 连通性，维护集群状态并在集群变为不可达时通知
+type ClusterHealthMonitor struct {
+	db       *gorm.DB
+	notifier *NotificationService
+	probe    func(ctx context.Context, cluster *models.Cluster) (string, error)
+
+	mu       sync.Mutex
+	failures map[uint]int
+}
+
+// NewClusterHealthMonitor 创建集群连通性探测
+func NewClusterHealthMonitor(db *gorm.DB, notifier *NotificationService) *ClusterHealthMonitor {
+	return &ClusterHealthMonitor{db: db, notifier: notifier, probe: probeClusterVersion, failures: make(map[uint]int)}
+}
+
+// probeClusterVersion 请求 /version 探测 API Server，返回集群版本
+func probeClusterVersion(ctx context.Context, cluster *models.Cluster) (string, error) {
+	client, err := NewK8sClientForCluster(cluster)
+	if err != nil {
+		return "", err
+	}
+	data, err := client.GetClientset().Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Raw()
+	if err != nil {
+		return "", err
+	}
+	var info struct {
+		GitVersion string `json:"gitVersion"`
+	}
+	_ = json.Unmarshal(data, &info)
+	return info.GitVersion, nil
+}
+
+// CheckOnce 探测所有集群一次
+func (m *ClusterHealthMonitor) CheckOnce() {
+	var clusters []models.Cluster
+	if err := m.db.Find(&clusters).Error; err != nil {
+		logger.Error("获取集群列表失败: %v", err)
+		return
+	}
+	for i := range clusters {
+		m.check(&clusters[i])
+	}
+}
+
+func (m *ClusterHealthMonitor) check(cluster *models.Cluster) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterProbeTimeout)
+	version, err := m.probe(ctx, cluster)
+	cancel()
+
+	m.mu.Lock()
+	if err == nil {
+		delete(m.failures, cluster.ID)
+	} else {
+		m.failures[cluster.ID]++
+	}
+	failures := m.failures[cluster.ID]
+	m.mu.Unlock()
+
+	now := time.Now()
+	if err == nil {
+		updates := map[string]interface{}{"last_heartbeat": &now}
+		if version != "" {
+			updates["version"] = version
+		}
+		// 节点未就绪等导致的 warning 由连接测试维护，这里只恢复不可达/未知状态
+		if cluster.Status != "healthy" && cluster.Status != "warning" {
+			updates["status"] = "healthy"
+			logger.Info("集群恢复可达: %s", cluster.Name)
+		}
+		m.db.Model(&models.Cluster{}).Where("id = ?", cluster.ID).Updates(updates)
+		return
+	}
+
+	if failures < clusterUnreachableThreshold || cluster.Status == "unhealthy" {
+		return
+	}
+	m.db.Model(&models.Cluster{}).Where("id = ?", cluster.ID).Update("status", "unhealthy")
+	logger.Warn("集群不可达: %s, err=%v", cluster.Name, err)
+	m.notifier.NotifyClusterUnreachable(cluster.ID, err.Error())
+}
+
+// Start 启动定期探测
+func (m *ClusterHealthMonitor) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.CheckOnce()
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
 
@@ -82,7 +83,7 @@ func (s *ClusterService) GetCluster(id uint) (*models.Cluster, error) {
 	var cluster models.Cluster
 	if err := s.db.First(&cluster, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("集群不存在: %d", id)
+			return nil, errcode.New(errcode.ClusterNotFound)
 		}
 		return nil, fmt.Errorf("获取集群失败: %w", err)
 	}
@@ -114,12 +115,50 @@ func (s *ClusterService) UpdateClusterStatus(id uint, status string, version str
 	}
 
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("集群不存在: %d", id)
+		return errcode.New(errcode.ClusterNotFound)
 	}
 
 	return nil
 }
 
+// UpdateClusterLabels 更新集群标签
+func (s *ClusterService) UpdateClusterLabels(id uint, labels map[string]string) error {
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labelsJSON, err := json.Marshal(labels)
+	if err != nil {
+		return fmt.Errorf("序列化集群标签失败: %w", err)
+	}
+
+	result := s.db.Model(&models.Cluster{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"labels":     string(labelsJSON),
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("更新集群标签失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errcode.New(errcode.ClusterNotFound)
+	}
+	return nil
+}
+
+// UpdateNodeTerminalMode 更新集群的节点终端方式
+func (s *ClusterService) UpdateNodeTerminalMode(id uint, mode string) error {
+	result := s.db.Model(&models.Cluster{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"node_terminal_mode": mode,
+		"updated_at":         time.Now(),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("更新节点终端方式失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errcode.New(errcode.ClusterNotFound)
+	}
+	return nil
+}
+
 // DeleteCluster 删除集群
 func (s *ClusterService) DeleteCluster(id uint) error {
 	// 使用事务确保数据一致性
@@ -128,7 +167,7 @@ func (s *ClusterService) DeleteCluster(id uint) error {
 		var cluster models.Cluster
 		if err := tx.First(&cluster, id).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
-				return fmt.Errorf("集群不存在: %d", id)
+				return errcode.New(errcode.ClusterNotFound)
 			}
 			return fmt.Errorf("查询集群失败: %w", err)
 		}
@@ -175,7 +214,13 @@ func (s *ClusterService) DeleteCluster(id uint) error {
 			// 监控指标删除失败不阻止删除
 		}
 
-		// 7. 硬删除集群（使用 Unscoped 绕过软删除）
+		// 7. 删除 API Server 审计 Webhook 配置（接收令牌随之失效，已接收的事件保留）
+		if err := tx.Where("cluster_id = ?", id).Delete(&models.K8sAuditWebhook{}).Error; err != nil {
+			logger.Error("删除审计 Webhook 配置失败", "cluster_id", id, "error", err)
+			return fmt.Errorf("删除审计 Webhook 配置失败: %w", err)
+		}
+
+		// 8. 硬删除集群（使用 Unscoped 绕过软删除）
 		if err := tx.Unscoped().Delete(&cluster).Error; err != nil {
 			return fmt.Errorf("删除集群失败: %w", err)
 		}
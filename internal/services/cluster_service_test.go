@@ -210,6 +210,9 @@ func (s *ClusterServiceTestSuite) TestDeleteCluster_Success() {
 	s.mock.ExpectExec(`DELETE FROM.*cluster_metrics.*WHERE.*cluster_id`).
 		WithArgs(1).
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectExec(`DELETE FROM.*k8s_audit_webhooks.*WHERE.*cluster_id`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
 	// 删除集群 - 使用 Unscoped
 	s.mock.ExpectExec(`DELETE FROM.*clusters.*WHERE.*id`).
 		WithArgs(1).
@@ -0,0 +1,491 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/constants"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// freezeCacheTTL 冻结日历缓存有效期（增删改时会主动失效）
+const freezeCacheTTL = 30 * time.Second
+
+// FreezeOverrideHeader 冻结期间紧急放行理由的请求头（非 ASCII 字符需 URL 编码）
+const FreezeOverrideHeader = "X-Freeze-Override-Reason"
+
+// freezeNotifyTimeout 紧急放行通知的请求超时
+const freezeNotifyTimeout = 5 * time.Second
+
+// FreezeService 变更冻结日历服务：维护冻结窗口、判定写操作是否处于冻结期并记录紧急放行
+type FreezeService struct {
+	db       *gorm.DB
+	opLogSvc *OperationLogService
+	client   *http.Client
+
+	mu       sync.RWMutex
+	cache    []models.FreezeCalendar // 已启用且含未结束窗口的日历
+	loadedAt time.Time
+}
+
+// NewFreezeService 创建变更冻结服务
+func NewFreezeService(db *gorm.DB, opLogSvc *OperationLogService) *FreezeService {
+	return &FreezeService{
+		db:       db,
+		opLogSvc: opLogSvc,
+		client:   &http.Client{Timeout: freezeNotifyTimeout},
+	}
+}
+
+// ========== 日历与窗口管理 ==========
+
+// FreezeCalendarRequest 创建/更新冻结日历请求
+type FreezeCalendarRequest struct {
+	Name            string            `json:"name" binding:"required"`
+	Description     string            `json:"description"`
+	ClusterSelector map[string]string `json:"cluster_selector"`
+	Namespaces      []string          `json:"namespaces"`
+	AllowBreakGlass *bool             `json:"allow_break_glass"`
+	NotifyWebhook   string            `json:"notify_webhook"`
+	Enabled         *bool             `json:"enabled"`
+}
+
+// applyTo 校验并写入日历模型
+func (b *FreezeCalendarRequest) applyTo(f *models.FreezeCalendar) error {
+	for _, pattern := range b.Namespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errcode.New(errcode.InvalidFreezeNamespacePattern, pattern)
+		}
+	}
+	if b.NotifyWebhook != "" {
+		u, err := url.Parse(b.NotifyWebhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errcode.New(errcode.InvalidFreezeWebhook)
+		}
+	}
+
+	f.Name = b.Name
+	f.Description = b.Description
+	f.ClusterSelector = encodeJSONOrEmpty(b.ClusterSelector, len(b.ClusterSelector) == 0)
+	f.Namespaces = encodeJSONOrEmpty(b.Namespaces, len(b.Namespaces) == 0)
+	f.AllowBreakGlass = b.AllowBreakGlass == nil || *b.AllowBreakGlass
+	f.NotifyWebhook = b.NotifyWebhook
+	f.Enabled = b.Enabled == nil || *b.Enabled
+	return nil
+}
+
+// CreateCalendar 创建冻结日历
+func (s *FreezeService) CreateCalendar(body *FreezeCalendarRequest, operator string) (*models.FreezeCalendar, error) {
+	calendar := &models.FreezeCalendar{CreatedBy: operator}
+	if err := body.applyTo(calendar); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(calendar).Error; err != nil {
+		return nil, fmt.Errorf("创建冻结日历失败: %w", err)
+	}
+	s.invalidate()
+	logger.Info("创建变更冻结日历: id=%d, name=%s", calendar.ID, calendar.Name)
+	return s.GetCalendar(calendar.ID)
+}
+
+// UpdateCalendar 更新冻结日历
+func (s *FreezeService) UpdateCalendar(id uint, body *FreezeCalendarRequest) (*models.FreezeCalendar, error) {
+	var calendar models.FreezeCalendar
+	if err := s.db.First(&calendar, id).Error; err != nil {
+		return nil, errcode.New(errcode.FreezeCalendarNotFound)
+	}
+	if err := body.applyTo(&calendar); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(&calendar).Error; err != nil {
+		return nil, fmt.Errorf("更新冻结日历失败: %w", err)
+	}
+	s.invalidate()
+	return s.GetCalendar(id)
+}
+
+// DeleteCalendar 删除冻结日历及其窗口
+func (s *FreezeService) DeleteCalendar(id uint) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.FreezeCalendar{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("删除冻结日历失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errcode.New(errcode.FreezeCalendarNotFound)
+		}
+		return tx.Where("calendar_id = ?", id).Delete(&models.FreezeWindow{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// GetCalendar 获取冻结日历详情（含全部窗口）
+func (s *FreezeService) GetCalendar(id uint) (*models.FreezeCalendar, error) {
+	var calendar models.FreezeCalendar
+	err := s.db.Preload("Windows", func(db *gorm.DB) *gorm.DB { return db.Order("start_at ASC") }).
+		First(&calendar, id).Error
+	if err != nil {
+		return nil, errcode.New(errcode.FreezeCalendarNotFound)
+	}
+	return &calendar, nil
+}
+
+// ListCalendars 获取冻结日历列表
+func (s *FreezeService) ListCalendars() ([]models.FreezeCalendar, error) {
+	var calendars []models.FreezeCalendar
+	err := s.db.Preload("Windows", func(db *gorm.DB) *gorm.DB { return db.Order("start_at ASC") }).
+		Order("id ASC").Find(&calendars).Error
+	if err != nil {
+		return nil, fmt.Errorf("获取冻结日历失败: %w", err)
+	}
+	return calendars, nil
+}
+
+// FreezeWindowRequest 创建/更新冻结窗口请求
+type FreezeWindowRequest struct {
+	Name    string    `json:"name" binding:"required"`
+	Reason  string    `json:"reason"`
+	StartAt time.Time `json:"start_at" binding:"required"`
+	EndAt   time.Time `json:"end_at" binding:"required"`
+}
+
+// applyTo 校验并写入窗口模型
+func (b *FreezeWindowRequest) applyTo(w *models.FreezeWindow) error {
+	if !b.EndAt.After(b.StartAt) {
+		return errcode.New(errcode.InvalidFreezeWindow)
+	}
+	w.Name = b.Name
+	w.Reason = b.Reason
+	w.StartAt = b.StartAt
+	w.EndAt = b.EndAt
+	return nil
+}
+
+// CreateWindow 在日历中添加冻结窗口
+func (s *FreezeService) CreateWindow(calendarID uint, body *FreezeWindowRequest) (*models.FreezeWindow, error) {
+	var calendar models.FreezeCalendar
+	if err := s.db.Select("id").First(&calendar, calendarID).Error; err != nil {
+		return nil, errcode.New(errcode.FreezeCalendarNotFound)
+	}
+	window := &models.FreezeWindow{CalendarID: calendarID}
+	if err := body.applyTo(window); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(window).Error; err != nil {
+		return nil, fmt.Errorf("创建冻结窗口失败: %w", err)
+	}
+	s.invalidate()
+	return window, nil
+}
+
+// UpdateWindow 更新冻结窗口
+func (s *FreezeService) UpdateWindow(id uint, body *FreezeWindowRequest) (*models.FreezeWindow, error) {
+	var window models.FreezeWindow
+	if err := s.db.First(&window, id).Error; err != nil {
+		return nil, errcode.New(errcode.FreezeWindowNotFound)
+	}
+	if err := body.applyTo(&window); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(&window).Error; err != nil {
+		return nil, fmt.Errorf("更新冻结窗口失败: %w", err)
+	}
+	s.invalidate()
+	return &window, nil
+}
+
+// DeleteWindow 删除冻结窗口
+func (s *FreezeService) DeleteWindow(id uint) error {
+	result := s.db.Delete(&models.FreezeWindow{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除冻结窗口失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errcode.New(errcode.FreezeWindowNotFound)
+	}
+	s.invalidate()
+	return nil
+}
+
+// ========== 冻结判定 ==========
+
+// FreezeMatch 命中的冻结窗口
+type FreezeMatch struct {
+	Calendar *models.FreezeCalendar `json:"calendar"`
+	Window   *models.FreezeWindow   `json:"window"`
+}
+
+// FreezeAnyNamespace 无法确定写请求的目标命名空间时使用，匹配所有限定命名空间的日历
+const FreezeAnyNamespace = "*"
+
+// calendarApplies 日历是否覆盖该集群与命名空间。
+// 请求不涉及命名空间时，仅匹配未限定命名空间的日历；命名空间未知（FreezeAnyNamespace）时匹配所有日历
+func calendarApplies(calendar *models.FreezeCalendar, clusterLabels map[string]string, namespace string) bool {
+	if !models.MatchLabels(calendar.GetClusterSelector(), clusterLabels) {
+		return false
+	}
+	if namespaces := calendar.GetNamespaceList(); len(namespaces) > 0 {
+		return namespace == FreezeAnyNamespace || matchPolicyPattern(namespaces, namespace)
+	}
+	return true
+}
+
+// MatchFreeze 查找在指定时间覆盖该集群与命名空间的冻结窗口。
+// 多个窗口同时生效时，不允许紧急放行的优先，其次取结束最晚的
+func MatchFreeze(calendars []models.FreezeCalendar, clusterLabels map[string]string, namespace string, at time.Time) *FreezeMatch {
+	var match *FreezeMatch
+	for i := range calendars {
+		calendar := &calendars[i]
+		if !calendar.Enabled || !calendarApplies(calendar, clusterLabels, namespace) {
+			continue
+		}
+		for j := range calendar.Windows {
+			window := &calendar.Windows[j]
+			if !window.Active(at) {
+				continue
+			}
+			if match == nil || freezeMoreRestrictive(calendar, window, match) {
+				match = &FreezeMatch{Calendar: calendar, Window: window}
+			}
+		}
+	}
+	return match
+}
+
+func freezeMoreRestrictive(calendar *models.FreezeCalendar, window *models.FreezeWindow, current *FreezeMatch) bool {
+	if calendar.AllowBreakGlass != current.Calendar.AllowBreakGlass {
+		return !calendar.AllowBreakGlass
+	}
+	return window.EndAt.After(current.Window.EndAt)
+}
+
+// activeCalendars 获取已启用且含未结束窗口的日历（带缓存）
+func (s *FreezeService) activeCalendars() ([]models.FreezeCalendar, error) {
+	s.mu.RLock()
+	if s.cache != nil && time.Since(s.loadedAt) < freezeCacheTTL {
+		calendars := s.cache
+		s.mu.RUnlock()
+		return calendars, nil
+	}
+	s.mu.RUnlock()
+
+	now := time.Now()
+	var calendars []models.FreezeCalendar
+	err := s.db.Where("enabled = ?", true).
+		Preload("Windows", "end_at > ?", now).
+		Find(&calendars).Error
+	if err != nil {
+		return nil, err
+	}
+	active := make([]models.FreezeCalendar, 0, len(calendars))
+	for _, calendar := range calendars {
+		if len(calendar.Windows) > 0 {
+			active = append(active, calendar)
+		}
+	}
+
+	s.mu.Lock()
+	s.cache = active
+	s.loadedAt = now
+	s.mu.Unlock()
+	return active, nil
+}
+
+// invalidate 使日历缓存失效
+func (s *FreezeService) invalidate() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}
+
+// Check 判定集群/命名空间当前是否处于冻结期，未处于冻结期时返回 nil（无冻结日历时不查询集群标签）
+func (s *FreezeService) Check(clusterID uint, namespace string) (*FreezeMatch, error) {
+	calendars, err := s.activeCalendars()
+	if err != nil {
+		return nil, fmt.Errorf("加载冻结日历失败: %w", err)
+	}
+	if len(calendars) == 0 {
+		return nil, nil
+	}
+
+	var cluster models.Cluster
+	if err := s.db.Select("id", "labels").First(&cluster, clusterID).Error; err != nil {
+		return nil, fmt.Errorf("获取集群标签失败: %w", err)
+	}
+	return MatchFreeze(calendars, cluster.GetLabels(), namespace, time.Now()), nil
+}
+
+// ========== 紧急放行 ==========
+
+// freezeBreakGlassNotice 紧急放行通知内容
+type freezeBreakGlassNotice struct {
+	Event     string    `json:"event"`
+	Calendar  string    `json:"calendar"`
+	Window    string    `json:"window"`
+	WindowEnd time.Time `json:"window_end"`
+	Username  string    `json:"username"`
+	ClusterID uint      `json:"cluster_id"`
+	Namespace string    `json:"namespace,omitempty"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Reason    string    `json:"reason"`
+	ClientIP  string    `json:"client_ip"`
+	Time      time.Time `json:"time"`
+	Text      string    `json:"text"` // 可读摘要，便于直接推送到 IM 机器人
+}
+
+// BreakGlass 记录冻结期间的紧急放行：写入放行记录与操作日志，并异步通知日历配置的地址
+func (s *FreezeService) BreakGlass(match *FreezeMatch, override *models.FreezeOverride) error {
+	override.CalendarID = match.Calendar.ID
+	override.WindowID = match.Window.ID
+	if err := s.db.Create(override).Error; err != nil {
+		return fmt.Errorf("记录紧急放行失败: %w", err)
+	}
+	logger.Warn("变更冻结期间紧急放行: calendar=%s, window=%s, user=%s, cluster=%d, %s %s, reason=%s",
+		match.Calendar.Name, match.Window.Name, override.Username, override.ClusterID, override.Method, override.Path, override.Reason)
+
+	if s.opLogSvc != nil {
+		userID, clusterID := override.UserID, override.ClusterID
+		if err := s.opLogSvc.Record(&LogEntry{
+			UserID:       &userID,
+			Username:     override.Username,
+			Method:       override.Method,
+			Path:         override.Path,
+			Module:       constants.ModuleCluster,
+			Action:       constants.ActionBreakGlass,
+			ClusterID:    &clusterID,
+			Namespace:    override.Namespace,
+			ResourceType: "freeze_window",
+			ResourceName: match.Window.Name,
+			RequestBody: map[string]interface{}{
+				"calendar": match.Calendar.Name,
+				"window":   match.Window.Name,
+				"reason":   override.Reason,
+			},
+			Success:  true,
+			ClientIP: override.ClientIP,
+		}); err != nil {
+			logger.Error("记录紧急放行操作日志失败: %v", err)
+		}
+	}
+
+	if match.Calendar.NotifyWebhook != "" {
+		notice := freezeBreakGlassNotice{
+			Event:     "freeze_break_glass",
+			Calendar:  match.Calendar.Name,
+			Window:    match.Window.Name,
+			WindowEnd: match.Window.EndAt,
+			Username:  override.Username,
+			ClusterID: override.ClusterID,
+			Namespace: override.Namespace,
+			Method:    override.Method,
+			Path:      override.Path,
+			Reason:    override.Reason,
+			ClientIP:  override.ClientIP,
+			Time:      override.CreatedAt,
+			Text: fmt.Sprintf("[变更冻结紧急放行] %s 在冻结窗口「%s / %s」期间执行 %s %s，理由：%s",
+				override.Username, match.Calendar.Name, match.Window.Name, override.Method, override.Path, override.Reason),
+		}
+		go s.notify(match.Calendar.NotifyWebhook, override.ID, &notice)
+	}
+	return nil
+}
+
+// notify 推送紧急放行通知并记录是否成功
+func (s *FreezeService) notify(webhook string, overrideID uint, notice *freezeBreakGlassNotice) {
+	body, err := json.Marshal(notice)
+	if err != nil {
+		return
+	}
+	resp, err := s.client.Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		logger.Error("发送紧急放行通知失败: override=%d, err=%v", overrideID, err)
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		logger.Error("发送紧急放行通知失败: override=%d, status=%d", overrideID, resp.StatusCode)
+		return
+	}
+	s.db.Model(&models.FreezeOverride{}).Where("id = ?", overrideID).Update("notified", true)
+}
+
+// ListOverrides 分页查询紧急放行记录
+func (s *FreezeService) ListOverrides(page, pageSize int) ([]models.FreezeOverride, int64, error) {
+	var total int64
+	if err := s.db.Model(&models.FreezeOverride{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var overrides []models.FreezeOverride
+	err := s.db.Preload("Window").Order("id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&overrides).Error
+	return overrides, total, err
+}
+
+// ========== 即将到来的窗口 ==========
+
+// UpcomingFreezeWindow 即将开始或正在生效的冻结窗口
+type UpcomingFreezeWindow struct {
+	models.FreezeWindow
+	CalendarName    string            `json:"calendar_name"`
+	ClusterSelector map[string]string `json:"cluster_selector"`
+	Namespaces      []string          `json:"namespaces"`
+	AllowBreakGlass bool              `json:"allow_break_glass"`
+	Active          bool              `json:"active"`
+}
+
+// Upcoming 列出未来若干天内（含正在生效）的冻结窗口；指定集群时只返回覆盖该集群的窗口
+func (s *FreezeService) Upcoming(clusterID uint, days int) ([]UpcomingFreezeWindow, error) {
+	now := time.Now()
+	var calendars []models.FreezeCalendar
+	err := s.db.Where("enabled = ?", true).
+		Preload("Windows", "end_at > ? AND start_at < ?", now, now.AddDate(0, 0, days)).
+		Find(&calendars).Error
+	if err != nil {
+		return nil, fmt.Errorf("获取冻结日历失败: %w", err)
+	}
+
+	var clusterLabels map[string]string
+	if clusterID != 0 {
+		var cluster models.Cluster
+		if err := s.db.Select("id", "labels").First(&cluster, clusterID).Error; err != nil {
+			return nil, errcode.New(errcode.ClusterNotFound)
+		}
+		clusterLabels = cluster.GetLabels()
+	}
+
+	windows := []UpcomingFreezeWindow{}
+	for i := range calendars {
+		calendar := &calendars[i]
+		if clusterID != 0 && !models.MatchLabels(calendar.GetClusterSelector(), clusterLabels) {
+			continue
+		}
+		for _, window := range calendar.Windows {
+			windows = append(windows, UpcomingFreezeWindow{
+				FreezeWindow:    window,
+				CalendarName:    calendar.Name,
+				ClusterSelector: calendar.GetClusterSelector(),
+				Namespaces:      calendar.GetNamespaceList(),
+				AllowBreakGlass: calendar.AllowBreakGlass,
+				Active:          window.Active(now),
+			})
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].StartAt.Before(windows[j].StartAt) })
+	return windows, nil
+}
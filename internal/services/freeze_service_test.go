@@ -0,0 +1,121 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/constants"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+func TestMatchFreezePrefersWindowsWithoutBreakGlass(t *testing.T) {
+	now := time.Now()
+	calendars := []models.FreezeCalendar{
+		{
+			ID: 1, Name: "holiday", Enabled: true, AllowBreakGlass: true,
+			Windows: []models.FreezeWindow{{ID: 1, Name: "national-day", StartAt: now.Add(-time.Hour), EndAt: now.Add(48 * time.Hour)}},
+		},
+		{
+			ID: 2, Name: "launch", Enabled: true, ClusterSelector: `{"env":"prod"}`, Namespaces: `["shop-*"]`,
+			Windows: []models.FreezeWindow{{ID: 2, Name: "big-sale", StartAt: now.Add(-time.Hour), EndAt: now.Add(time.Hour)}},
+		},
+		{
+			ID: 3, Name: "disabled", Enabled: false,
+			Windows: []models.FreezeWindow{{ID: 3, Name: "ignored", StartAt: now.Add(-time.Hour), EndAt: now.Add(time.Hour)}},
+		},
+	}
+
+	if m := MatchFreeze(calendars, map[string]string{"env": "prod"}, "shop-web", now); m == nil || m.Window.ID != 2 {
+		t.Fatalf("expected launch window without break-glass to win, got %+v", m)
+	}
+	if m := MatchFreeze(calendars, map[string]string{"env": "dev"}, "shop-web", now); m == nil || m.Window.ID != 1 {
+		t.Fatalf("expected holiday window for dev cluster, got %+v", m)
+	}
+	if m := MatchFreeze(calendars, map[string]string{"env": "prod"}, "shop-web", now.Add(72*time.Hour)); m != nil {
+		t.Fatalf("expected no freeze after windows end, got %+v", m)
+	}
+}
+
+func TestFreezeCheckAndBreakGlass(t *testing.T) {
+	db := newAuditChainTestDB(t)
+	if err := db.AutoMigrate(&models.Cluster{}, &models.FreezeCalendar{}, &models.FreezeWindow{}, &models.FreezeOverride{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	cluster := models.Cluster{Name: "prod-1", APIServer: "https://prod-1:6443", Labels: `{"env":"prod"}`}
+	if err := db.Create(&cluster).Error; err != nil {
+		t.Fatalf("create cluster: %v", err)
+	}
+
+	notices := make(chan freezeBreakGlassNotice, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var notice freezeBreakGlassNotice
+		_ = json.NewDecoder(r.Body).Decode(&notice)
+		notices <- notice
+	}))
+	defer webhook.Close()
+
+	svc := NewFreezeService(db, NewOperationLogService(db, nil, nil))
+	calendar, err := svc.CreateCalendar(&FreezeCalendarRequest{
+		Name:            "launch",
+		ClusterSelector: map[string]string{"env": "prod"},
+		Namespaces:      []string{"shop-*"},
+		NotifyWebhook:   webhook.URL,
+	}, "admin")
+	if err != nil {
+		t.Fatalf("create calendar: %v", err)
+	}
+	now := time.Now()
+	if _, err := svc.CreateWindow(calendar.ID, &FreezeWindowRequest{Name: "bad", StartAt: now, EndAt: now}); err == nil {
+		t.Fatal("expected error for empty window")
+	}
+	active, err := svc.CreateWindow(calendar.ID, &FreezeWindowRequest{Name: "big-sale", StartAt: now.Add(-time.Minute), EndAt: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("create window: %v", err)
+	}
+	if _, err := svc.CreateWindow(calendar.ID, &FreezeWindowRequest{Name: "holiday", StartAt: now.AddDate(0, 0, 10), EndAt: now.AddDate(0, 0, 12)}); err != nil {
+		t.Fatalf("create window: %v", err)
+	}
+
+	match, err := svc.Check(cluster.ID, "shop-web")
+	if err != nil || match == nil || match.Window.ID != active.ID {
+		t.Fatalf("expected active freeze, got %+v, err=%v", match, err)
+	}
+	for _, namespace := range []string{"infra", ""} {
+		if m, _ := svc.Check(cluster.ID, namespace); m != nil {
+			t.Fatalf("namespace %q should not be frozen", namespace)
+		}
+	}
+
+	override := &models.FreezeOverride{
+		UserID: 3, Username: "alice", ClusterID: cluster.ID, Namespace: "shop-web",
+		Method: "POST", Path: "/api/v1/clusters/1/deployments/shop-web/web/scale", Reason: "回滚故障版本",
+	}
+	if err := svc.BreakGlass(match, override); err != nil {
+		t.Fatalf("break glass: %v", err)
+	}
+	select {
+	case notice := <-notices:
+		if notice.Username != "alice" || notice.Reason != "回滚故障版本" || notice.Window != "big-sale" {
+			t.Fatalf("unexpected notice: %+v", notice)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("break-glass notification not sent")
+	}
+
+	var logs []models.OperationLog
+	db.Where("action = ?", constants.ActionBreakGlass).Find(&logs)
+	if len(logs) != 1 || logs[0].Username != "alice" {
+		t.Fatalf("expected one break-glass operation log, got %+v", logs)
+	}
+
+	upcoming, err := svc.Upcoming(cluster.ID, 30)
+	if err != nil || len(upcoming) != 2 {
+		t.Fatalf("upcoming: %+v, err=%v", upcoming, err)
+	}
+	if !upcoming[0].Active || upcoming[0].Name != "big-sale" || upcoming[1].Active {
+		t.Fatalf("unexpected upcoming order: %+v", upcoming)
+	}
+}
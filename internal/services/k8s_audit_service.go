@@ -0,0 +1,369 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/k8saudit"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/templates/rbac"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 平台操作日志关联参数
+const (
+	// k8sAuditClockSkew 集群与平台之间允许的时钟偏差
+	k8sAuditClockSkew = 5 * time.Second
+	// k8sAuditCorrelateWindow 平台身份的事件在该时间内持续尝试关联操作日志（操作日志为异步写入）
+	k8sAuditCorrelateWindow = 15 * time.Minute
+	// k8sAuditMaxOperation 关联时操作日志的最长耗时（如节点驱逐）
+	k8sAuditMaxOperation = 10 * time.Minute
+)
+
+// ErrK8sAuditUnauthorized 审计 Webhook 令牌无效或未启用
+var ErrK8sAuditUnauthorized = errcode.New(errcode.K8sAuditUnauthorized)
+
+// K8sAuditService Kubernetes API Server 审计事件：接收 Webhook 推送、识别来源并关联平台操作日志
+type K8sAuditService struct {
+	db *gorm.DB
+}
+
+// NewK8sAuditService 创建 K8s 审计事件服务
+func NewK8sAuditService(db *gorm.DB) *K8sAuditService {
+	return &K8sAuditService{db: db}
+}
+
+func hashK8sAuditToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ========== Webhook 配置 ==========
+
+// GetWebhook 获取集群的审计 Webhook 配置（未配置时返回未启用的空配置）
+func (s *K8sAuditService) GetWebhook(clusterID uint) (*models.K8sAuditWebhook, error) {
+	webhook := &models.K8sAuditWebhook{ClusterID: clusterID}
+	if err := s.db.Where("cluster_id = ?", clusterID).Limit(1).Find(webhook).Error; err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// K8sAuditWebhookRequest 配置审计 Webhook 请求
+type K8sAuditWebhookRequest struct {
+	Enabled          bool   `json:"enabled"`
+	PlatformUsername string `json:"platform_username"`
+	RotateToken      bool   `json:"rotate_token"` // 重新生成接收令牌，旧令牌立即失效
+}
+
+// ConfigureWebhook 更新集群的审计 Webhook 配置，首次启用或轮换时返回新令牌（仅此一次）
+func (s *K8sAuditService) ConfigureWebhook(clusterID uint, req *K8sAuditWebhookRequest) (*models.K8sAuditWebhook, string, error) {
+	webhook, err := s.GetWebhook(clusterID)
+	if err != nil {
+		return nil, "", err
+	}
+	webhook.Enabled = req.Enabled
+	webhook.PlatformUsername = req.PlatformUsername
+
+	var token string
+	if req.RotateToken || (req.Enabled && webhook.TokenHash == "") {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, "", err
+		}
+		token = hex.EncodeToString(buf)
+		webhook.TokenHash = hashK8sAuditToken(token)
+	}
+	if err := s.db.Save(webhook).Error; err != nil {
+		return nil, "", err
+	}
+	return webhook, token, nil
+}
+
+// Authenticate 校验 Webhook 推送的令牌
+func (s *K8sAuditService) Authenticate(clusterID uint, token string) (*models.K8sAuditWebhook, error) {
+	webhook, err := s.GetWebhook(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if !webhook.Enabled || webhook.TokenHash == "" || token == "" ||
+		subtle.ConstantTimeCompare([]byte(webhook.TokenHash), []byte(hashK8sAuditToken(token))) != 1 {
+		return nil, ErrK8sAuditUnauthorized
+	}
+	return webhook, nil
+}
+
+// ========== 接收与关联 ==========
+
+// Ingest 保存一批审计事件中的变更类请求，返回新保存的事件数。
+// API Server 重试推送时按 auditID 去重。
+func (s *K8sAuditService) Ingest(webhook *models.K8sAuditWebhook, list *k8saudit.EventList) (int64, error) {
+	events := make([]*models.K8sAuditEvent, 0, len(list.Items))
+	usernames := map[string]*uint{}
+	for i := range list.Items {
+		item := &list.Items[i]
+		if !item.Recordable() {
+			continue
+		}
+		ev := k8saudit.Normalize(webhook.ClusterID, item)
+		s.classify(webhook, ev, usernames)
+		events = append(events, ev)
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	res := s.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(events, 100)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	now := time.Now()
+	s.db.Model(webhook).Update("last_event_at", now)
+
+	if webhook.PlatformUsername != "" {
+		if _, err := s.CorrelatePending(); err != nil {
+			logger.Warn("关联 K8s 审计事件失败: %v", err)
+		}
+	}
+	return res.RowsAffected, nil
+}
+
+// classify 识别事件来源：平台凭据、平台终端的 ServiceAccount，或其他身份（按同名平台用户关联）
+func (s *K8sAuditService) classify(webhook *models.K8sAuditWebhook, ev *models.K8sAuditEvent, usernames map[string]*uint) {
+	if webhook.PlatformUsername != "" && ev.ImpersonatedUser == "" && ev.Username == webhook.PlatformUsername {
+		ev.Source = models.K8sAuditSourcePlatform
+		return
+	}
+	if namespace, name, ok := k8saudit.ServiceAccount(ev.Actor); ok && namespace == rbac.KubePolarisNamespace {
+		ev.Source = models.K8sAuditSourceTerminal
+		// 用户专属 SA 可直接对应到用户；按权限类型共用的 SA 无法区分用户
+		if userID, ok := ParseUserServiceAccountName(name); ok {
+			ev.UserID = &userID
+		}
+		return
+	}
+
+	ev.Source = models.K8sAuditSourceDirect
+	userID, cached := usernames[ev.Actor]
+	if !cached {
+		var user models.User
+		if err := s.db.Select("id").Where("username = ?", ev.Actor).Limit(1).Find(&user).Error; err == nil && user.ID != 0 {
+			userID = &user.ID
+		}
+		usernames[ev.Actor] = userID
+	}
+	ev.UserID = userID
+}
+
+// CorrelatePending 为近期平台身份发起、尚未关联的事件查找对应的操作日志：
+// 同一集群、对象一致，且事件发生在操作开始到日志写入之间（允许时钟偏差），取最早的一条
+func (s *K8sAuditService) CorrelatePending() (int, error) {
+	var events []models.K8sAuditEvent
+	if err := s.db.Where("source = ? AND operation_log_id IS NULL AND request_received_at > ?",
+		models.K8sAuditSourcePlatform, time.Now().Add(-k8sAuditCorrelateWindow)).
+		Order("id ASC").Limit(500).Find(&events).Error; err != nil {
+		return 0, err
+	}
+
+	correlated := 0
+	for i := range events {
+		ev := &events[i]
+		at := ev.RequestReceivedAt
+		query := s.db.Where("cluster_id = ? AND method <> ? AND created_at BETWEEN ? AND ?",
+			ev.ClusterID, "GET", at.Add(-k8sAuditClockSkew), at.Add(k8sAuditMaxOperation))
+		if ev.Namespace != "" {
+			query = query.Where("namespace IN ?", []string{"", ev.Namespace})
+		}
+		if ev.Name != "" {
+			query = query.Where("resource_name IN ?", []string{"", ev.Name})
+		}
+		var candidates []models.OperationLog
+		if err := query.Order("created_at ASC").Limit(20).Find(&candidates).Error; err != nil {
+			return correlated, err
+		}
+		for _, op := range candidates {
+			if !k8sAuditMatchesOperation(ev, &op) {
+				continue
+			}
+			if err := s.db.Model(ev).Updates(map[string]interface{}{
+				"operation_log_id": op.ID,
+				"user_id":          op.UserID,
+			}).Error; err != nil {
+				return correlated, err
+			}
+			correlated++
+			break
+		}
+	}
+	return correlated, nil
+}
+
+// k8sAuditMatchesOperation 事件是否属于该操作：资源类型一致（操作记录了类型时），且发生在操作期间
+func k8sAuditMatchesOperation(ev *models.K8sAuditEvent, op *models.OperationLog) bool {
+	if op.ResourceType != "" && op.ResourceType != k8saudit.OperationResourceType(ev.Resource) {
+		return false
+	}
+	start := op.CreatedAt.Add(-time.Duration(op.Duration) * time.Millisecond)
+	return !ev.RequestReceivedAt.Before(start.Add(-k8sAuditClockSkew))
+}
+
+// PurgeExpired 删除超过保留天数的事件
+func (s *K8sAuditService) PurgeExpired(days int) (int64, error) {
+	if days <= 0 {
+		return 0, nil
+	}
+	res := s.db.Where("request_received_at < ?", time.Now().AddDate(0, 0, -days)).Delete(&models.K8sAuditEvent{})
+	return res.RowsAffected, res.Error
+}
+
+// StartWorker 定期关联平台操作日志（Webhook 推送早于操作日志写入时）并清理过期事件
+func (s *K8sAuditService) StartWorker(interval time.Duration, retainDays int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	lastPurge := time.Time{}
+	for range ticker.C {
+		if _, err := s.CorrelatePending(); err != nil {
+			logger.Warn("关联 K8s 审计事件失败: %v", err)
+		}
+		if time.Since(lastPurge) > time.Hour {
+			lastPurge = time.Now()
+			if n, err := s.PurgeExpired(retainDays); err != nil {
+				logger.Error("清理过期 K8s 审计事件失败: %v", err)
+			} else if n > 0 {
+				logger.Info("已清理过期 K8s 审计事件: %d", n)
+			}
+		}
+	}
+}
+
+// ========== 查询 ==========
+
+// K8sAuditEventListRequest 审计事件查询条件
+type K8sAuditEventListRequest struct {
+	ClusterID uint
+	Namespace string
+	Resource  string
+	Name      string
+	Actor     string
+	Verb      string
+	Source    string
+	StartTime *time.Time
+	EndTime   *time.Time
+	Page      int
+	PageSize  int
+}
+
+// ListEvents 分页查询审计事件
+func (s *K8sAuditService) ListEvents(req *K8sAuditEventListRequest) ([]models.K8sAuditEvent, int64, error) {
+	query := s.db.Model(&models.K8sAuditEvent{})
+	if req.ClusterID != 0 {
+		query = query.Where("cluster_id = ?", req.ClusterID)
+	}
+	for column, value := range map[string]string{
+		"namespace": req.Namespace, "resource": req.Resource, "name": req.Name, "verb": req.Verb, "source": req.Source,
+	} {
+		if value != "" {
+			query = query.Where(column+" = ?", value)
+		}
+	}
+	if req.Actor != "" {
+		query = query.Where("actor LIKE ?", "%"+req.Actor+"%")
+	}
+	if req.StartTime != nil {
+		query = query.Where("request_received_at >= ?", *req.StartTime)
+	}
+	if req.EndTime != nil {
+		query = query.Where("request_received_at <= ?", *req.EndTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var events []models.K8sAuditEvent
+	err := query.Order("request_received_at DESC, id DESC").
+		Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).Find(&events).Error
+	return events, total, err
+}
+
+// ObjectTimelineItem 对象变更时间线中的一项：经平台发起的操作（附带对应的 API Server 事件），
+// 或未经平台发起的 API Server 事件
+type ObjectTimelineItem struct {
+	Time      time.Time              `json:"time"`
+	Source    string                 `json:"source"` // kubepolaris / terminal / direct / platform（平台凭据但未找到对应操作）
+	UserID    *uint                  `json:"user_id"`
+	Actor     string                 `json:"actor"`
+	Action    string                 `json:"action"`
+	Success   bool                   `json:"success"`
+	Operation *models.OperationLog   `json:"operation,omitempty"`
+	K8sEvents []models.K8sAuditEvent `json:"k8s_events,omitempty"`
+}
+
+// 时间线中经平台 API 发起的操作
+const objectTimelineSourceKubePolaris = "kubepolaris"
+
+// ObjectTimeline 某个对象的变更时间线：合并平台操作日志与 API Server 审计事件，按时间倒序
+func (s *K8sAuditService) ObjectTimeline(clusterID uint, resource, namespace, name string, limit int) ([]ObjectTimelineItem, error) {
+	var ops []models.OperationLog
+	opQuery := s.db.Where("cluster_id = ? AND resource_type = ? AND resource_name = ? AND method <> ?",
+		clusterID, k8saudit.OperationResourceType(resource), name, "GET")
+	if namespace != "" {
+		opQuery = opQuery.Where("namespace = ?", namespace)
+	}
+	if err := opQuery.Order("created_at DESC").Limit(limit).Find(&ops).Error; err != nil {
+		return nil, err
+	}
+
+	var events []models.K8sAuditEvent
+	if err := s.db.Where("cluster_id = ? AND resource = ? AND namespace = ? AND name = ?", clusterID, resource, namespace, name).
+		Order("request_received_at DESC").Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	items := make([]ObjectTimelineItem, 0, len(ops)+len(events))
+	byOperation := make(map[uint]int, len(ops))
+	for i := range ops {
+		op := &ops[i]
+		byOperation[op.ID] = len(items)
+		items = append(items, ObjectTimelineItem{
+			Time:      op.CreatedAt,
+			Source:    objectTimelineSourceKubePolaris,
+			UserID:    op.UserID,
+			Actor:     op.Username,
+			Action:    op.Action,
+			Success:   op.Success,
+			Operation: op,
+		})
+	}
+	for _, ev := range events {
+		if ev.OperationLogID != nil {
+			if idx, ok := byOperation[*ev.OperationLogID]; ok {
+				items[idx].K8sEvents = append(items[idx].K8sEvents, ev)
+				continue
+			}
+		}
+		items = append(items, ObjectTimelineItem{
+			Time:      ev.RequestReceivedAt,
+			Source:    ev.Source,
+			UserID:    ev.UserID,
+			Actor:     ev.Actor,
+			Action:    ev.Verb,
+			Success:   ev.ResponseCode < 400,
+			K8sEvents: []models.K8sAuditEvent{ev},
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Time.After(items[j].Time) })
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
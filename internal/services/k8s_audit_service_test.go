@@ -0,0 +1,100 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/k8saudit"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+func k8sAuditTestEvent(auditID, username string, at time.Time) k8saudit.Event {
+	return k8saudit.Event{
+		AuditID:                  auditID,
+		Stage:                    k8saudit.StageResponseComplete,
+		Verb:                     "patch",
+		User:                     k8saudit.UserInfo{Username: username},
+		ObjectRef:                &k8saudit.ObjectReference{Resource: "deployments", Namespace: "default", Name: "web"},
+		ResponseStatus:           &k8saudit.Status{Code: 200},
+		RequestReceivedTimestamp: at,
+		StageTimestamp:           at,
+	}
+}
+
+func TestK8sAuditIngestClassifiesAndCorrelates(t *testing.T) {
+	db := newAuditChainTestDB(t)
+	if err := db.AutoMigrate(&models.K8sAuditEvent{}, &models.K8sAuditWebhook{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	svc := NewK8sAuditService(db)
+
+	alice := models.User{Username: "alice"}
+	if err := db.Create(&alice).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	clusterID, operatorID := uint(1), uint(9)
+	now := time.Now()
+	op := models.OperationLog{
+		UserID: &operatorID, Username: "admin", Method: "PUT", Action: "update",
+		ClusterID: &clusterID, Namespace: "default", ResourceType: "deployment", ResourceName: "web",
+		Success: true, Duration: 200, CreatedAt: now.Add(time.Second),
+	}
+	if err := db.Create(&op).Error; err != nil {
+		t.Fatalf("create op log: %v", err)
+	}
+
+	webhook, token, err := svc.ConfigureWebhook(clusterID, &K8sAuditWebhookRequest{Enabled: true, PlatformUsername: "kubepolaris"})
+	if err != nil || token == "" {
+		t.Fatalf("configure webhook: token=%q err=%v", token, err)
+	}
+	if _, err := svc.Authenticate(clusterID, "wrong"); err != ErrK8sAuditUnauthorized {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+	if webhook, err = svc.Authenticate(clusterID, token); err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+
+	list := &k8saudit.EventList{Items: []k8saudit.Event{
+		k8sAuditTestEvent("platform", "kubepolaris", now),
+		k8sAuditTestEvent("terminal", "system:serviceaccount:kubepolaris-system:kubepolaris-user-5-sa", now.Add(time.Minute)),
+		k8sAuditTestEvent("direct", "alice", now.Add(2*time.Minute)),
+	}}
+	stored, err := svc.Ingest(webhook, list)
+	if err != nil || stored != 3 {
+		t.Fatalf("ingest: stored=%d err=%v", stored, err)
+	}
+	// API Server 重试推送时不重复保存
+	if stored, err = svc.Ingest(webhook, list); err != nil || stored != 0 {
+		t.Fatalf("re-ingest: stored=%d err=%v", stored, err)
+	}
+
+	events := map[string]models.K8sAuditEvent{}
+	var rows []models.K8sAuditEvent
+	db.Find(&rows)
+	for _, ev := range rows {
+		events[ev.AuditID] = ev
+	}
+	if ev := events["platform"]; ev.Source != models.K8sAuditSourcePlatform || ev.OperationLogID == nil || *ev.OperationLogID != op.ID || *ev.UserID != operatorID {
+		t.Fatalf("platform event not correlated: %+v", ev)
+	}
+	if ev := events["terminal"]; ev.Source != models.K8sAuditSourceTerminal || ev.UserID == nil || *ev.UserID != 5 {
+		t.Fatalf("terminal event: %+v", ev)
+	}
+	if ev := events["direct"]; ev.Source != models.K8sAuditSourceDirect || ev.UserID == nil || *ev.UserID != alice.ID {
+		t.Fatalf("direct event: %+v", ev)
+	}
+
+	items, err := svc.ObjectTimeline(clusterID, "deployments", "default", "web", 10)
+	if err != nil {
+		t.Fatalf("timeline: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("timeline items = %d, want 3", len(items))
+	}
+	if items[0].Source != models.K8sAuditSourceDirect || items[1].Source != models.K8sAuditSourceTerminal {
+		t.Fatalf("unexpected timeline order: %s, %s", items[0].Source, items[1].Source)
+	}
+	if last := items[2]; last.Source != objectTimelineSourceKubePolaris || len(last.K8sEvents) != 1 || last.K8sEvents[0].AuditID != "platform" {
+		t.Fatalf("platform event not folded into operation: %+v", last)
+	}
+}
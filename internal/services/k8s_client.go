@@ -12,6 +12,7 @@ import (
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -335,6 +336,15 @@ func (c *K8sClient) GetRestConfig() *rest.Config {
 	return c.config
 }
 
+// GetDynamicClient 获取动态客户端（按 GVR 读写任意资源）
+func (c *K8sClient) GetDynamicClient() (dynamic.Interface, error) {
+	dynamicClient, err := dynamic.NewForConfig(c.config)
+	if err != nil {
+		return nil, fmt.Errorf("创建动态客户端失败: %w", err)
+	}
+	return dynamicClient, nil
+}
+
 // GetRolloutClient 获取Argo Rollouts客户端
 func (c *K8sClient) GetRolloutClient() (*rolloutsclientset.Clientset, error) {
 	rolloutClient, err := rolloutsclientset.NewForConfig(c.config)
@@ -2,9 +2,9 @@ package services
 
 import (
 	"crypto/tls"
-	"errors"
 	"fmt"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
 
@@ -57,7 +57,7 @@ func (s *LDAPService) Authenticate(username, password string) (*LDAPUser, error)
 	}
 
 	if !config.Enabled {
-		return nil, errors.New("LDAP未启用")
+		return nil, errcode.New(errcode.LDAPDisabled)
 	}
 
 	return s.AuthenticateWithConfig(username, password, config)
@@ -99,11 +99,11 @@ func (s *LDAPService) AuthenticateWithConfig(username, password string, config *
 	}
 
 	if len(result.Entries) == 0 {
-		return nil, errors.New("用户不存在")
+		return nil, errcode.New(errcode.UserNotFound)
 	}
 
 	if len(result.Entries) > 1 {
-		return nil, errors.New("找到多个匹配用户")
+		return nil, errcode.New(errcode.LDAPMultipleUsers)
 	}
 
 	userEntry := result.Entries[0]
@@ -111,7 +111,7 @@ func (s *LDAPService) AuthenticateWithConfig(username, password string, config *
 
 	// 使用用户DN和密码进行绑定验证
 	if err := conn.Bind(userDN, password); err != nil {
-		return nil, errors.New("密码错误")
+		return nil, errcode.New(errcode.InvalidCredentials)
 	}
 
 	// 构建用户信息
@@ -0,0 +1,566 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/constants"
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/notify"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// 通知投递重试
+const (
+	notificationMaxAttempts = 6
+	notificationMinBackoff  = 30 * time.Second
+	notificationMaxBackoff  = 30 * time.Minute
+	notificationSendTimeout = 30 * time.Second
+	notificationBatchSize   = 50
+	notificationCacheTTL    = 30 * time.Second
+)
+
+// notificationSecretMask 查询配置时密钥与密码的占位符，更新时原样提交表示保持不变
+const notificationSecretMask = "******"
+
+// NotificationService 事件通知：按订阅将平台事件写入投递记录，后台发送并在失败时退避重试
+type NotificationService struct {
+	db *gorm.DB
+
+	mu       sync.RWMutex
+	cache    *models.NotificationConfig
+	loadedAt time.Time
+
+	scoreMu     sync.Mutex
+	lastScores  map[uint]int // 各集群上次诊断的健康评分
+	newSenderFn func(ch *models.NotificationChannel) (notify.Sender, error)
+}
+
+// NewNotificationService 创建事件通知服务
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{db: db, lastScores: make(map[uint]int), newSenderFn: notify.NewSender}
+}
+
+// ========== 配置 ==========
+
+// config 通知配置（缓存）
+func (s *NotificationService) config() (*models.NotificationConfig, error) {
+	s.mu.RLock()
+	if s.cache != nil && time.Since(s.loadedAt) < notificationCacheTTL {
+		defer s.mu.RUnlock()
+		return s.cache, nil
+	}
+	s.mu.RUnlock()
+
+	config := models.GetDefaultNotificationConfig()
+	if _, err := GetSystemSetting(s.db, models.NotificationSettingKey, &config); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.cache, s.loadedAt = &config, time.Now()
+	s.mu.Unlock()
+	return &config, nil
+}
+
+// GetConfig 获取通知配置（密钥与密码以占位符返回）
+func (s *NotificationService) GetConfig() (*models.NotificationConfig, error) {
+	config, err := s.config()
+	if err != nil {
+		return nil, err
+	}
+	safe := *config
+	safe.Channels = make([]models.NotificationChannel, len(config.Channels))
+	for i, ch := range config.Channels {
+		if ch.Config.Secret != "" {
+			ch.Config.Secret = notificationSecretMask
+		}
+		if ch.Config.Password != "" {
+			ch.Config.Password = notificationSecretMask
+		}
+		safe.Channels[i] = ch
+	}
+	return &safe, nil
+}
+
+// SaveConfig 校验并保存通知配置；密钥、密码为占位符时沿用同名渠道的原值
+func (s *NotificationService) SaveConfig(config *models.NotificationConfig) error {
+	existing, err := s.config()
+	if err != nil {
+		return err
+	}
+	previous := make(map[string]models.NotificationChannelConfig, len(existing.Channels))
+	for _, ch := range existing.Channels {
+		previous[ch.Name] = ch.Config
+	}
+
+	names := make(map[string]bool, len(config.Channels))
+	for i := range config.Channels {
+		ch := &config.Channels[i]
+		ch.Name = strings.TrimSpace(ch.Name)
+		if names[ch.Name] {
+			return errcode.New(errcode.NotificationChannelDuplicate, ch.Name)
+		}
+		names[ch.Name] = true
+		if ch.Config.Secret == notificationSecretMask {
+			ch.Config.Secret = previous[ch.Name].Secret
+		}
+		if ch.Config.Password == notificationSecretMask {
+			ch.Config.Password = previous[ch.Name].Password
+		}
+		if err := notify.Validate(ch); err != nil {
+			return fmt.Errorf("渠道 %s: %w", ch.Name, err)
+		}
+	}
+	for _, sub := range config.Subscriptions {
+		if sub.Name == "" {
+			return errcode.New(errcode.SubscriptionNameRequired)
+		}
+		if len(sub.Events) == 0 || len(sub.Channels) == 0 {
+			return errcode.New(errcode.SubscriptionIncomplete, sub.Name)
+		}
+		for _, event := range sub.Events {
+			if _, ok := models.NotifyEventNames[event]; !ok {
+				return errcode.New(errcode.SubscriptionUnknownEvent, sub.Name, event)
+			}
+		}
+		for _, name := range sub.Channels {
+			if !names[name] {
+				return errcode.New(errcode.SubscriptionChannelNotFound, sub.Name, name)
+			}
+		}
+	}
+	if config.HealthScoreDropThreshold <= 0 {
+		config.HealthScoreDropThreshold = models.GetDefaultNotificationConfig().HealthScoreDropThreshold
+	}
+	if config.Channels == nil {
+		config.Channels = []models.NotificationChannel{}
+	}
+	if config.Subscriptions == nil {
+		config.Subscriptions = []models.NotificationSubscription{}
+	}
+
+	if err := SaveSystemSetting(s.db, models.NotificationSettingKey, "notification", config); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+	return nil
+}
+
+// notificationChannels 渠道列表
+type notificationChannels []models.NotificationChannel
+
+// find 按名称查找渠道
+func (c notificationChannels) find(name string) *models.NotificationChannel {
+	for i := range c {
+		if c[i].Name == name {
+			return &c[i]
+		}
+	}
+	return nil
+}
+
+// TestChannel 直接向渠道发送一条测试消息（不写投递记录）
+func (s *NotificationService) TestChannel(name string) error {
+	config, err := s.config()
+	if err != nil {
+		return err
+	}
+	ch := notificationChannels(config.Channels).find(name)
+	if ch == nil {
+		return errcode.New(errcode.NotificationChannelNotFound, name)
+	}
+	sender, err := s.newSenderFn(ch)
+	if err != nil {
+		return err
+	}
+	event := notify.NewEvent("test", notify.SeverityInfo, "测试通知", "这是一条来自 KubePolaris 的测试通知")
+	ctx, cancel := context.WithTimeout(context.Background(), notificationSendTimeout)
+	defer cancel()
+	return sender.Send(ctx, event)
+}
+
+// EmailChannel 按名称获取已启用的邮件渠道（供定时报表等复用 SMTP 配置）
+func (s *NotificationService) EmailChannel(name string) (*models.NotificationChannel, error) {
+	config, err := s.config()
+	if err != nil {
+		return nil, err
+	}
+	ch := notificationChannels(config.Channels).find(name)
+	if ch == nil {
+		return nil, errcode.New(errcode.NotificationChannelNotFound, name)
+	}
+	if ch.Type != models.NotifyChannelEmail {
+		return nil, errcode.New(errcode.NotEmailChannel, name)
+	}
+	if !ch.Enabled {
+		return nil, errcode.New(errcode.NotificationChannelDisabled, name)
+	}
+	copied := *ch
+	return &copied, nil
+}
+
+// ========== 事件发布 ==========
+
+// Publish 将事件写入所有匹配订阅的渠道投递记录（同一事件对同一渠道只投递一次）
+func (s *NotificationService) Publish(event *notify.Event) {
+	if s == nil {
+		return
+	}
+	config, err := s.config()
+	if err != nil {
+		logger.Error("加载通知配置失败: %v", err)
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	var clusterID *uint
+	if event.ClusterID != 0 {
+		clusterID = &event.ClusterID
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool)
+	var rows []models.NotificationDelivery
+	for _, sub := range config.Subscriptions {
+		if !sub.Enabled || !containsString(sub.Events, event.Type) {
+			continue
+		}
+		if len(sub.ClusterSelector) > 0 && !models.MatchLabels(sub.ClusterSelector, event.ClusterLabels) {
+			continue
+		}
+		for _, name := range sub.Channels {
+			ch := notificationChannels(config.Channels).find(name)
+			if ch == nil || !ch.Enabled || seen[name] {
+				continue
+			}
+			seen[name] = true
+			rows = append(rows, models.NotificationDelivery{
+				EventType:     event.Type,
+				Subscription:  sub.Name,
+				Channel:       ch.Name,
+				ChannelType:   ch.Type,
+				ClusterID:     clusterID,
+				Title:         truncateRunes(event.Title, 255),
+				Payload:       string(payload),
+				Status:        models.NotificationDeliveryPending,
+				NextAttemptAt: now,
+			})
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	if err := s.db.Create(&rows).Error; err != nil {
+		logger.Error("写入通知投递记录失败: event=%s, err=%v", event.Type, err)
+	}
+}
+
+// withCluster 补充事件的集群名称与标签（用于订阅的集群选择器）
+func (s *NotificationService) withCluster(event *notify.Event, clusterID uint) *notify.Event {
+	if clusterID == 0 {
+		return event
+	}
+	event.ClusterID = clusterID
+	var cluster models.Cluster
+	if err := s.db.Select("id", "name", "labels").First(&cluster, clusterID).Error; err == nil {
+		event.ClusterName = cluster.Name
+		event.ClusterLabels = cluster.GetLabels()
+	}
+	return event
+}
+
+// NotifyOperationLog 由操作日志产生事件：操作失败、节点驱逐、ArgoCD 同步失败
+func (s *NotificationService) NotifyOperationLog(log *models.OperationLog) {
+	if s == nil {
+		return
+	}
+	// 登录失败等认证事件不属于平台操作
+	if log.Module == constants.ModuleAuth {
+		return
+	}
+
+	var clusterID uint
+	if log.ClusterID != nil {
+		clusterID = *log.ClusterID
+	}
+	fields := map[string]string{
+		"用户": log.Username,
+		"操作": log.Method + " " + log.Path,
+	}
+	if log.Namespace != "" {
+		fields["命名空间"] = log.Namespace
+	}
+	if log.ResourceName != "" {
+		fields["资源"] = log.ResourceType + "/" + log.ResourceName
+	}
+
+	publish := func(eventType, severity, message string) {
+		event := notify.NewEvent(eventType, severity, "", message)
+		for k, v := range fields {
+			event.Fields[k] = v
+		}
+		s.Publish(s.withCluster(event, clusterID))
+	}
+
+	switch {
+	case log.Module == constants.ModuleNode && log.Action == constants.ActionDrain && log.Success:
+		publish(models.NotifyEventNodeDrained, notify.SeverityWarning,
+			fmt.Sprintf("%s 驱逐了节点 %s", log.Username, log.ResourceName))
+	case log.Module == constants.ModuleArgoCD && log.Action == constants.ActionSync && !log.Success:
+		publish(models.NotifyEventArgoCDSyncFailed, notify.SeverityCritical,
+			fmt.Sprintf("应用 %s 同步失败: %s", log.ResourceName, log.ErrorMessage))
+	}
+	if !log.Success {
+		publish(models.NotifyEventOperationFailed, notify.SeverityWarning,
+			fmt.Sprintf("%s 的操作失败（HTTP %d）: %s", log.Username, log.StatusCode, log.ErrorMessage))
+	}
+}
+
+// NotifyTerminalOpened 打开终端会话（通过订阅的集群选择器限定生产集群等）
+func (s *NotificationService) NotifyTerminalOpened(session *models.TerminalSession) {
+	if s == nil {
+		return
+	}
+	var user models.User
+	s.db.Select("id", "username").First(&user, session.UserID)
+
+	target := session.Node
+	if session.Pod != "" {
+		target = session.Namespace + "/" + session.Pod
+		if session.Container != "" {
+			target += "/" + session.Container
+		}
+	}
+	event := notify.NewEvent(models.NotifyEventTerminalOpened, notify.SeverityInfo, "",
+		fmt.Sprintf("%s 打开了 %s 终端", user.Username, session.TargetType))
+	event.Fields["用户"] = user.Username
+	event.Fields["会话"] = fmt.Sprintf("%d", session.ID)
+	if target != "" {
+		event.Fields["目标"] = target
+	}
+	s.Publish(s.withCluster(event, session.ClusterID))
+}
+
+// NotifyHealthScore 记录集群健康评分，较上次诊断下降达到阈值时产生事件
+func (s *NotificationService) NotifyHealthScore(clusterID uint, score int, status string) {
+	if s == nil {
+		return
+	}
+	s.scoreMu.Lock()
+	previous, ok := s.lastScores[clusterID]
+	s.lastScores[clusterID] = score
+	s.scoreMu.Unlock()
+	if !ok {
+		return
+	}
+
+	config, err := s.config()
+	if err != nil || previous-score < config.HealthScoreDropThreshold {
+		return
+	}
+	event := notify.NewEvent(models.NotifyEventHealthScoreDropped, notify.SeverityWarning, "",
+		fmt.Sprintf("集群健康评分由 %d 下降到 %d", previous, score))
+	event.Fields["评分"] = fmt.Sprintf("%d -> %d", previous, score)
+	event.Fields["状态"] = status
+	s.Publish(s.withCluster(event, clusterID))
+}
+
+// NotifyClusterUnreachable 集群连续探测失败
+func (s *NotificationService) NotifyClusterUnreachable(clusterID uint, reason string) {
+	if s == nil {
+		return
+	}
+	event := notify.NewEvent(models.NotifyEventClusterUnreachable, notify.SeverityCritical, "",
+		"无法连接集群 API Server: "+reason)
+	s.Publish(s.withCluster(event, clusterID))
+}
+
+// ========== 投递 ==========
+
+// notificationBackoff 第 attempts 次失败后的重试间隔
+func notificationBackoff(attempts int) time.Duration {
+	d := notificationMinBackoff
+	for i := 1; i < attempts && d < notificationMaxBackoff; i++ {
+		d *= 2
+	}
+	if d > notificationMaxBackoff {
+		d = notificationMaxBackoff
+	}
+	return d
+}
+
+// DispatchOnce 发送一批到期的投递，返回发送成功的数量
+func (s *NotificationService) DispatchOnce() int {
+	var rows []models.NotificationDelivery
+	if err := s.db.Where("status = ? AND next_attempt_at <= ?", models.NotificationDeliveryPending, time.Now()).
+		Order("id ASC").Limit(notificationBatchSize).Find(&rows).Error; err != nil || len(rows) == 0 {
+		return 0
+	}
+	config, err := s.config()
+	if err != nil {
+		logger.Error("加载通知配置失败: %v", err)
+		return 0
+	}
+
+	sent := 0
+	senders := make(map[string]notify.Sender)
+	for i := range rows {
+		row := &rows[i]
+		err := s.deliver(config, senders, row)
+		if err == nil {
+			now := time.Now()
+			s.db.Model(row).Updates(map[string]interface{}{
+				"status":     models.NotificationDeliverySent,
+				"attempts":   row.Attempts + 1,
+				"sent_at":    now,
+				"last_error": "",
+			})
+			sent++
+			continue
+		}
+
+		attempts := row.Attempts + 1
+		updates := map[string]interface{}{
+			"attempts":        attempts,
+			"last_error":      auditSinkError(err),
+			"next_attempt_at": time.Now().Add(notificationBackoff(attempts)),
+		}
+		if attempts >= notificationMaxAttempts {
+			updates["status"] = models.NotificationDeliveryFailed
+		}
+		s.db.Model(row).Updates(updates)
+		logger.Warn("通知发送失败: channel=%s, event=%s, attempts=%d, err=%v", row.Channel, row.EventType, attempts, err)
+	}
+	return sent
+}
+
+// deliver 发送单条投递（同一批次内复用渠道发送器）
+func (s *NotificationService) deliver(config *models.NotificationConfig, senders map[string]notify.Sender, row *models.NotificationDelivery) error {
+	sender, ok := senders[row.Channel]
+	if !ok {
+		ch := notificationChannels(config.Channels).find(row.Channel)
+		if ch == nil || !ch.Enabled {
+			return errcode.New(errcode.NotificationChannelUnavailable)
+		}
+		var err error
+		if sender, err = s.newSenderFn(ch); err != nil {
+			return err
+		}
+		senders[row.Channel] = sender
+	}
+
+	var event notify.Event
+	if err := json.Unmarshal([]byte(row.Payload), &event); err != nil {
+		return fmt.Errorf("事件格式错误: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notificationSendTimeout)
+	defer cancel()
+	return sender.Send(ctx, &event)
+}
+
+// NotificationDeliveryListRequest 投递记录查询条件
+type NotificationDeliveryListRequest struct {
+	Status    string
+	Channel   string
+	EventType string
+	Page      int
+	PageSize  int
+}
+
+// ListDeliveries 获取投递记录（不含事件内容）
+func (s *NotificationService) ListDeliveries(req *NotificationDeliveryListRequest) ([]models.NotificationDelivery, int64, error) {
+	query := s.db.Model(&models.NotificationDelivery{})
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.Channel != "" {
+		query = query.Where("channel = ?", req.Channel)
+	}
+	if req.EventType != "" {
+		query = query.Where("event_type = ?", req.EventType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+	var items []models.NotificationDelivery
+	err := query.Omit("payload").Order("id DESC").
+		Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).Find(&items).Error
+	return items, total, err
+}
+
+// RetryDelivery 将失败的投递重新排队
+func (s *NotificationService) RetryDelivery(id uint) error {
+	result := s.db.Model(&models.NotificationDelivery{}).
+		Where("id = ? AND status = ?", id, models.NotificationDeliveryFailed).
+		Updates(map[string]interface{}{
+			"status":          models.NotificationDeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errcode.New(errcode.DeliveryNotRetryable)
+	}
+	return nil
+}
+
+// PurgeDeliveries 清理超过保留天数的已完成投递记录
+func (s *NotificationService) PurgeDeliveries(retainDays int) int64 {
+	if retainDays <= 0 {
+		return 0
+	}
+	result := s.db.Where("status <> ? AND created_at < ?", models.NotificationDeliveryPending,
+		time.Now().AddDate(0, 0, -retainDays)).Delete(&models.NotificationDelivery{})
+	if result.Error != nil {
+		logger.Error("清理通知投递记录失败: %v", result.Error)
+		return 0
+	}
+	return result.RowsAffected
+}
+
+// StartWorker 启动通知发送任务（每小时清理一次过期投递记录）
+func (s *NotificationService) StartWorker(interval time.Duration, retainDays int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	lastPurge := time.Time{}
+	for range ticker.C {
+		s.DispatchOnce()
+		if time.Since(lastPurge) >= time.Hour {
+			if n := s.PurgeDeliveries(retainDays); n > 0 {
+				logger.Info("已清理通知投递记录: %d", n)
+			}
+			lastPurge = time.Now()
+		}
+	}
+}
+
+// truncateRunes 按字符截断字符串
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/constants"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/notify"
+)
+
+// fakeNotifySender 前 failures 次发送失败，之后记录收到的事件
+type fakeNotifySender struct {
+	mu       sync.Mutex
+	failures int
+	events   []*notify.Event
+}
+
+func (f *fakeNotifySender) Send(_ context.Context, e *notify.Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("connection refused")
+	}
+	f.events = append(f.events, e)
+	return nil
+}
+
+func newNotificationTestService(t *testing.T) (*NotificationService, *fakeNotifySender, models.Cluster, models.Cluster) {
+	t.Helper()
+	db := newAuditChainTestDB(t)
+	if err := db.AutoMigrate(&models.SystemSetting{}, &models.Cluster{}, &models.NotificationDelivery{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	prod := models.Cluster{Name: "prod-1", APIServer: "https://prod-1:6443", Labels: `{"env":"prod"}`}
+	dev := models.Cluster{Name: "dev-1", APIServer: "https://dev-1:6443", Labels: `{"env":"dev"}`}
+	if err := db.Create(&prod).Error; err != nil {
+		t.Fatalf("create cluster: %v", err)
+	}
+	if err := db.Create(&dev).Error; err != nil {
+		t.Fatalf("create cluster: %v", err)
+	}
+
+	sender := &fakeNotifySender{}
+	svc := NewNotificationService(db)
+	svc.newSenderFn = func(*models.NotificationChannel) (notify.Sender, error) { return sender, nil }
+
+	err := svc.SaveConfig(&models.NotificationConfig{
+		Channels: []models.NotificationChannel{{
+			Name: "ops-dingtalk", Type: models.NotifyChannelDingTalk, Enabled: true,
+			Config: models.NotificationChannelConfig{URL: "https://oapi.dingtalk.com/robot/send?access_token=x", Secret: "SEC"},
+		}},
+		Subscriptions: []models.NotificationSubscription{{
+			Name: "prod", Enabled: true, Channels: []string{"ops-dingtalk"},
+			Events:          []string{models.NotifyEventNodeDrained, models.NotifyEventOperationFailed, models.NotifyEventHealthScoreDropped},
+			ClusterSelector: map[string]string{"env": "prod"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	return svc, sender, prod, dev
+}
+
+func TestNotificationConfigKeepsMaskedSecret(t *testing.T) {
+	svc, _, _, _ := newNotificationTestService(t)
+
+	config, err := svc.GetConfig()
+	if err != nil {
+		t.Fatalf("get config: %v", err)
+	}
+	if config.Channels[0].Config.Secret != notificationSecretMask {
+		t.Fatalf("secret should be masked, got %q", config.Channels[0].Config.Secret)
+	}
+	if err := svc.SaveConfig(config); err != nil {
+		t.Fatalf("save masked config: %v", err)
+	}
+	stored, _ := svc.config()
+	if stored.Channels[0].Config.Secret != "SEC" {
+		t.Fatalf("masked secret should keep stored value, got %q", stored.Channels[0].Config.Secret)
+	}
+
+	config.Subscriptions[0].Channels = []string{"missing"}
+	if err := svc.SaveConfig(config); err == nil {
+		t.Fatal("expected unknown channel to be rejected")
+	}
+}
+
+func TestNotificationPublishAndRetry(t *testing.T) {
+	svc, sender, prod, dev := newNotificationTestService(t)
+
+	svc.NotifyOperationLog(&models.OperationLog{
+		Username: "alice", Method: "POST", Path: "/api/v1/clusters/1/nodes/node-1/drain",
+		Module: constants.ModuleNode, Action: constants.ActionDrain, ClusterID: &prod.ID,
+		ResourceType: "node", ResourceName: "node-1", Success: true, StatusCode: 204,
+	})
+	// dev 集群不匹配订阅的集群选择器
+	svc.NotifyOperationLog(&models.OperationLog{
+		Username: "alice", Method: "POST", Path: "/api/v1/clusters/2/nodes/node-2/drain",
+		Module: constants.ModuleNode, Action: constants.ActionDrain, ClusterID: &dev.ID,
+		ResourceType: "node", ResourceName: "node-2", Success: true, StatusCode: 204,
+	})
+
+	var rows []models.NotificationDelivery
+	svc.db.Find(&rows)
+	if len(rows) != 1 || rows[0].EventType != models.NotifyEventNodeDrained || rows[0].Channel != "ops-dingtalk" {
+		t.Fatalf("unexpected deliveries: %+v", rows)
+	}
+
+	sender.failures = 1
+	if sent := svc.DispatchOnce(); sent != 0 {
+		t.Fatalf("first attempt should fail, sent=%d", sent)
+	}
+	var row models.NotificationDelivery
+	svc.db.First(&row, rows[0].ID)
+	if row.Status != models.NotificationDeliveryPending || row.Attempts != 1 || !row.NextAttemptAt.After(time.Now()) {
+		t.Fatalf("failed delivery should be rescheduled: %+v", row)
+	}
+	if sent := svc.DispatchOnce(); sent != 0 {
+		t.Fatal("delivery must wait for its backoff")
+	}
+
+	svc.db.Model(&row).Update("next_attempt_at", time.Now().Add(-time.Second))
+	if sent := svc.DispatchOnce(); sent != 1 {
+		t.Fatalf("retry should succeed, sent=%d", sent)
+	}
+	svc.db.First(&row, rows[0].ID)
+	if row.Status != models.NotificationDeliverySent || row.SentAt == nil || row.Attempts != 2 {
+		t.Fatalf("unexpected delivery after retry: %+v", row)
+	}
+	if len(sender.events) != 1 || sender.events[0].ClusterName != "prod-1" || sender.events[0].Fields["资源"] != "node/node-1" {
+		t.Fatalf("unexpected delivered event: %+v", sender.events)
+	}
+}
+
+func TestNotificationDeliveryGivesUp(t *testing.T) {
+	svc, sender, prod, _ := newNotificationTestService(t)
+	sender.failures = notificationMaxAttempts
+
+	svc.NotifyOperationLog(&models.OperationLog{
+		Username: "bob", Method: "DELETE", Path: "/api/v1/clusters/1/namespaces/shop",
+		Module: constants.ModuleNamespace, Action: constants.ActionDelete, ClusterID: &prod.ID,
+		Success: false, StatusCode: 500, ErrorMessage: "boom",
+	})
+	for i := 0; i < notificationMaxAttempts; i++ {
+		svc.db.Model(&models.NotificationDelivery{}).Where("status = ?", models.NotificationDeliveryPending).
+			Update("next_attempt_at", time.Now().Add(-time.Second))
+		svc.DispatchOnce()
+	}
+
+	var row models.NotificationDelivery
+	svc.db.First(&row)
+	if row.Status != models.NotificationDeliveryFailed || row.Attempts != notificationMaxAttempts || row.LastError == "" {
+		t.Fatalf("delivery should fail after max attempts: %+v", row)
+	}
+	if err := svc.RetryDelivery(row.ID); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if svc.DispatchOnce() != 1 {
+		t.Fatal("manually retried delivery should be sent")
+	}
+}
+
+func TestNotifyHealthScoreDrop(t *testing.T) {
+	svc, _, prod, _ := newNotificationTestService(t)
+
+	svc.NotifyHealthScore(prod.ID, 95, "healthy")
+	svc.NotifyHealthScore(prod.ID, 90, "healthy") // 下降未达到默认阈值
+	svc.NotifyHealthScore(prod.ID, 70, "warning")
+
+	var rows []models.NotificationDelivery
+	svc.db.Find(&rows)
+	if len(rows) != 1 || rows[0].EventType != models.NotifyEventHealthScoreDropped {
+		t.Fatalf("expected a single health score event, got %+v", rows)
+	}
+}
+
+func TestClusterHealthMonitorNotifiesOnce(t *testing.T) {
+	svc, _, prod, _ := newNotificationTestService(t)
+	config, _ := svc.config()
+	config.Subscriptions[0].Events = append(config.Subscriptions[0].Events, models.NotifyEventClusterUnreachable)
+
+	monitor := NewClusterHealthMonitor(svc.db, svc)
+	monitor.probe = func(_ context.Context, cluster *models.Cluster) (string, error) {
+		if cluster.ID == prod.ID {
+			return "", errors.New("dial tcp: i/o timeout")
+		}
+		return "v1.30.0", nil
+	}
+	for i := 0; i < 3; i++ {
+		monitor.CheckOnce()
+	}
+
+	var cluster models.Cluster
+	svc.db.First(&cluster, prod.ID)
+	if cluster.Status != "unhealthy" {
+		t.Fatalf("cluster status = %s, want unhealthy", cluster.Status)
+	}
+	var count int64
+	svc.db.Model(&models.NotificationDelivery{}).Where("event_type = ?", models.NotifyEventClusterUnreachable).Count(&count)
+	if count != 1 {
+		t.Fatalf("expected one unreachable notification, got %d", count)
+	}
+}
@@ -21,6 +21,7 @@ import (
 type OMService struct {
 	prometheusSvc       *PrometheusService
 	monitoringConfigSvc *MonitoringConfigService
+	notifier            *NotificationService // 为空时健康评分下降不产生通知事件
 }
 
 // NewOMService 创建运维服务
@@ -31,6 +32,11 @@ func NewOMService(prometheusSvc *PrometheusService, monitoringConfigSvc *Monitor
 	}
 }
 
+// SetNotifier 设置事件通知服务
+func (s *OMService) SetNotifier(notifier *NotificationService) {
+	s.notifier = notifier
+}
+
 // GetHealthDiagnosis 获取集群健康诊断
 func (s *OMService) GetHealthDiagnosis(ctx context.Context, clientset *kubernetes.Clientset, clusterID uint) (*models.HealthDiagnosisResponse, error) {
 	response := &models.HealthDiagnosisResponse{
@@ -110,6 +116,9 @@ func (s *OMService) GetHealthDiagnosis(ctx context.Context, clientset *kubernete
 	// 生成诊断建议
 	response.Suggestions = s.generateSuggestions(response.RiskItems)
 
+	// 与上次诊断比较，评分明显下降时通知
+	s.notifier.NotifyHealthScore(clusterID, response.HealthScore, response.Status)
+
 	return response, nil
 }
 
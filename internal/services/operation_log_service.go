@@ -14,18 +14,27 @@ import (
 
 // OperationLogService 操作审计日志服务
 type OperationLogService struct {
-	db *gorm.DB
+	db       *gorm.DB
+	chain    *AuditChainService   // 为空时不入哈希链
+	exporter *AuditExportService  // 为空时不外送
+	notifier *NotificationService // 为空时不产生通知事件
 }
 
 // NewOperationLogService 创建操作审计日志服务
-func NewOperationLogService(db *gorm.DB) *OperationLogService {
-	return &OperationLogService{db: db}
+func NewOperationLogService(db *gorm.DB, chain *AuditChainService, exporter *AuditExportService) *OperationLogService {
+	return &OperationLogService{db: db, chain: chain, exporter: exporter}
+}
+
+// SetNotifier 设置事件通知服务（操作失败、节点驱逐等事件由操作日志产生）
+func (s *OperationLogService) SetNotifier(notifier *NotificationService) {
+	s.notifier = notifier
 }
 
 // LogEntry 日志条目（用于记录）
 type LogEntry struct {
 	UserID       *uint
 	Username     string
+	TenantID     *uint
 	Method       string
 	Path         string
 	Query        string
@@ -43,13 +52,28 @@ type LogEntry struct {
 	ClientIP     string
 	UserAgent    string
 	Duration     int64
+	Snapshots    []*models.OperationLogSnapshot // 变更前后的对象快照，与日志同一事务写入
+
+	// 双人审批执行的变更
+	ChangeRequestID *uint
+	ApproverID      *uint
+	ApproverName    string
 }
 
 // Record 记录操作日志
 func (s *OperationLogService) Record(entry *LogEntry) error {
+	// 未显式指定租户时按操作者所属租户记录，便于租户管理员查看本租户审计
+	if entry.TenantID == nil && entry.UserID != nil {
+		var member models.TenantMember
+		if err := s.db.Select("tenant_id").Where("user_id = ?", *entry.UserID).Limit(1).Find(&member).Error; err == nil && member.TenantID != 0 {
+			entry.TenantID = &member.TenantID
+		}
+	}
+
 	log := &models.OperationLog{
 		UserID:       entry.UserID,
 		Username:     entry.Username,
+		TenantID:     entry.TenantID,
 		Method:       entry.Method,
 		Path:         entry.Path,
 		Query:        entry.Query,
@@ -67,12 +91,22 @@ func (s *OperationLogService) Record(entry *LogEntry) error {
 		ClientIP:     entry.ClientIP,
 		UserAgent:    entry.UserAgent,
 		Duration:     entry.Duration,
+		CreatedAt:    chainNow(),
+
+		ChangeRequestID: entry.ChangeRequestID,
+		ApproverID:      entry.ApproverID,
+		ApproverName:    entry.ApproverName,
+	}
+	for _, snap := range entry.Snapshots {
+		log.Snapshots = append(log.Snapshots, *snap)
 	}
 
-	if err := s.db.Create(log).Error; err != nil {
+	if err := createChained(s.db, s.chain, log); err != nil {
 		logger.Error("记录操作日志失败", "error", err)
 		return err
 	}
+	s.exporter.ExportOperationLog(log)
+	s.notifier.NotifyOperationLog(log)
 
 	return nil
 }
@@ -238,6 +272,7 @@ type OperationLogListRequest struct {
 	Action       string
 	ResourceType string
 	ClusterID    *uint
+	TenantID     *uint
 	Success      *bool
 	StartTime    *time.Time
 	EndTime      *time.Time
@@ -257,6 +292,7 @@ type OperationLogItem struct {
 	ID           uint      `json:"id"`
 	UserID       *uint     `json:"user_id"`
 	Username     string    `json:"username"`
+	TenantID     *uint     `json:"tenant_id"`
 	Method       string    `json:"method"`
 	Path         string    `json:"path"`
 	Module       string    `json:"module"`
@@ -299,6 +335,9 @@ func (s *OperationLogService) List(req *OperationLogListRequest) (*OperationLogL
 	if req.ClusterID != nil {
 		query = query.Where("cluster_id = ?", *req.ClusterID)
 	}
+	if req.TenantID != nil {
+		query = query.Where("tenant_id = ?", *req.TenantID)
+	}
 	if req.Success != nil {
 		query = query.Where("success = ?", *req.Success)
 	}
@@ -342,6 +381,7 @@ func (s *OperationLogService) List(req *OperationLogListRequest) (*OperationLogL
 			ID:           log.ID,
 			UserID:       log.UserID,
 			Username:     log.Username,
+			TenantID:     log.TenantID,
 			Method:       log.Method,
 			Path:         log.Path,
 			Module:       log.Module,
@@ -373,12 +413,35 @@ func (s *OperationLogService) List(req *OperationLogListRequest) (*OperationLogL
 // GetDetail 获取操作日志详情
 func (s *OperationLogService) GetDetail(id uint) (*models.OperationLog, error) {
 	var log models.OperationLog
-	if err := s.db.First(&log, id).Error; err != nil {
+	if err := s.db.Preload("Snapshots").First(&log, id).Error; err != nil {
 		return nil, err
 	}
 	return &log, nil
 }
 
+// GetSnapshot 获取操作日志下的对象快照
+func (s *OperationLogService) GetSnapshot(logID, snapshotID uint) (*models.OperationLogSnapshot, error) {
+	var snap models.OperationLogSnapshot
+	if err := s.db.Where("operation_log_id = ?", logID).First(&snap, snapshotID).Error; err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// MarkSnapshotReverted 标记快照已回滚；快照已被回滚过时返回 gorm.ErrRecordNotFound
+func (s *OperationLogService) MarkSnapshotReverted(snapshotID, userID uint) error {
+	result := s.db.Model(&models.OperationLogSnapshot{}).
+		Where("id = ? AND reverted_at IS NULL", snapshotID).
+		Updates(map[string]interface{}{"reverted_at": time.Now(), "reverted_by": userID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 // OperationLogStats 操作日志统计
 type OperationLogStats struct {
 	TotalCount     int64               `json:"total_count"`
@@ -491,6 +554,7 @@ func (s *OperationLogService) GetStats(startTime, endTime *time.Time) (*Operatio
 			ID:           log.ID,
 			UserID:       log.UserID,
 			Username:     log.Username,
+			TenantID:     log.TenantID,
 			Method:       log.Method,
 			Path:         log.Path,
 			Module:       log.Module,
@@ -533,6 +597,81 @@ func (s *OperationLogService) GetStats(startTime, endTime *time.Time) (*Operatio
 	return stats, nil
 }
 
+// GetClusterStats 按集群与时间区间统计操作日志（clusterIDs 为 nil 表示不按集群过滤，用于定时报表）
+func (s *OperationLogService) GetClusterStats(clusterIDs []uint, startTime, endTime time.Time) (*OperationLogStats, error) {
+	scoped := func() *gorm.DB {
+		q := s.db.Model(&models.OperationLog{}).Where("created_at >= ? AND created_at <= ?", startTime, endTime)
+		if clusterIDs != nil {
+			q = q.Where("cluster_id IN ?", clusterIDs)
+		}
+		return q
+	}
+	if clusterIDs != nil && len(clusterIDs) == 0 {
+		return &OperationLogStats{}, nil
+	}
+
+	stats := &OperationLogStats{}
+	if err := scoped().Count(&stats.TotalCount).Error; err != nil {
+		return nil, err
+	}
+	if err := scoped().Where("success = ?", true).Count(&stats.SuccessCount).Error; err != nil {
+		return nil, err
+	}
+	stats.FailedCount = stats.TotalCount - stats.SuccessCount
+
+	var moduleStats []struct {
+		Module string
+		Count  int64
+	}
+	if err := scoped().Select("module, COUNT(*) as count").Group("module").
+		Order("count DESC").Limit(10).Scan(&moduleStats).Error; err != nil {
+		return nil, err
+	}
+	for _, ms := range moduleStats {
+		stats.ModuleStats = append(stats.ModuleStats, ModuleStat{Module: ms.Module, ModuleName: getModuleName(ms.Module), Count: ms.Count})
+	}
+
+	var userStats []struct {
+		UserID   uint
+		Username string
+		Count    int64
+	}
+	if err := scoped().Select("user_id, username, COUNT(*) as count").Where("user_id IS NOT NULL").
+		Group("user_id, username").Order("count DESC").Limit(10).Scan(&userStats).Error; err != nil {
+		return nil, err
+	}
+	for _, us := range userStats {
+		stats.UserStats = append(stats.UserStats, UserOperationStat{UserID: us.UserID, Username: us.Username, Count: us.Count})
+	}
+
+	var failures []models.OperationLog
+	if err := scoped().Where("success = ?", false).Order("created_at DESC").Limit(10).Find(&failures).Error; err != nil {
+		return nil, err
+	}
+	for _, log := range failures {
+		stats.RecentFailures = append(stats.RecentFailures, OperationLogItem{
+			ID:           log.ID,
+			UserID:       log.UserID,
+			Username:     log.Username,
+			Method:       log.Method,
+			Path:         log.Path,
+			Module:       log.Module,
+			ModuleName:   getModuleName(log.Module),
+			Action:       log.Action,
+			ActionName:   getActionName(log.Action),
+			ClusterID:    log.ClusterID,
+			ClusterName:  log.ClusterName,
+			ResourceType: log.ResourceType,
+			ResourceName: log.ResourceName,
+			StatusCode:   log.StatusCode,
+			Success:      log.Success,
+			ErrorMessage: log.ErrorMessage,
+			CreatedAt:    log.CreatedAt,
+		})
+	}
+	return stats, nil
+}
+
 // getModuleName 获取模块中文名称
 func getModuleName(module string) string {
 	names := map[string]string{
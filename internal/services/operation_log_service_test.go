@@ -0,0 +1,45 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestOperationLogSnapshotsRecordedWithLog(t *testing.T) {
+	db := newAuditChainTestDB(t)
+	chain := NewAuditChainService(db, "test-key")
+	opLogSvc := NewOperationLogService(db, chain, nil)
+
+	snap := &models.OperationLogSnapshot{ClusterID: 1, Version: "v1", Resource: "configmaps", Kind: "ConfigMap",
+		Namespace: "default", Name: "app", Before: `{"data":{"k":"1"}}`, After: `{"data":{"k":"2"}}`, Diff: `[]`}
+	if err := opLogSvc.Record(&LogEntry{Method: "PUT", Path: "/x", Success: true, Snapshots: []*models.OperationLogSnapshot{snap}}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	var log models.OperationLog
+	db.First(&log)
+	detail, err := opLogSvc.GetDetail(log.ID)
+	if err != nil || len(detail.Snapshots) != 1 || detail.Snapshots[0].Name != "app" {
+		t.Fatalf("expected snapshot preloaded in detail, got %+v (%v)", detail, err)
+	}
+	if report := verifyOperationChain(t, chain); !report.Valid {
+		t.Fatalf("snapshots must not affect chain, got %+v", report)
+	}
+
+	got, err := opLogSvc.GetSnapshot(log.ID, detail.Snapshots[0].ID)
+	if err != nil || !got.Revertible() {
+		t.Fatalf("expected revertible snapshot, got %+v (%v)", got, err)
+	}
+	if err := opLogSvc.MarkSnapshotReverted(got.ID, 7); err != nil {
+		t.Fatalf("mark reverted: %v", err)
+	}
+	if err := opLogSvc.MarkSnapshotReverted(got.ID, 7); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected second revert rejected, got %v", err)
+	}
+	if _, err := opLogSvc.GetSnapshot(log.ID+1, got.ID); err == nil {
+		t.Fatal("snapshot must belong to the requested log")
+	}
+}
@@ -218,6 +218,7 @@ func (s *OverviewService) GetOverviewStats(ctx context.Context) (*OverviewStatsR
 
 	stats := &OverviewStatsResponse{}
 	versionMap := make(map[string][]string)
+	tenantScope := TenantScopeFromContext(ctx)
 
 	for _, cluster := range clusters {
 		// 集群健康统计
@@ -246,6 +247,9 @@ func (s *OverviewService) GetOverviewStats(ctx context.Context) (*OverviewStatsR
 					logger.Error("获取集群 Pod 列表失败", "cluster", cluster.Name, "error", err)
 				} else {
 					for _, pod := range pods {
+						if tenantScope != nil && !tenantScope.Allows(cluster.ID, pod.Namespace) {
+							continue
+						}
 						stats.PodStats.Total++
 						switch pod.Status.Phase {
 						case corev1.PodRunning:
@@ -734,6 +738,8 @@ func (s *OverviewService) GetAbnormalWorkloads(ctx context.Context, limit int) (
 			}
 		}
 
+		workloads = filterTenantWorkloads(workloads, TenantScopeFromContext(ctx))
+
 		// 限制数量
 		if len(workloads) >= limit {
 			break
@@ -748,6 +754,20 @@ func (s *OverviewService) GetAbnormalWorkloads(ctx context.Context, limit int) (
 	return workloads, nil
 }
 
+// filterTenantWorkloads 过滤掉不属于租户命名空间的工作负载
+func filterTenantWorkloads(workloads []AbnormalWorkload, scope *TenantScope) []AbnormalWorkload {
+	if scope == nil {
+		return workloads
+	}
+	filtered := workloads[:0]
+	for _, w := range workloads {
+		if scope.Allows(w.ClusterID, w.Namespace) {
+			filtered = append(filtered, w)
+		}
+	}
+	return filtered
+}
+
 // GetGlobalAlertStats 获取全局告警统计（聚合所有集群的告警数据）
 func (s *OverviewService) GetGlobalAlertStats(ctx context.Context) (*GlobalAlertStats, error) {
 	clusters, err := s.getClusters(ctx)
@@ -2,13 +2,15 @@ package services
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
+	"time"
 
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
 
 	"gorm.io/gorm"
+	"k8s.io/client-go/kubernetes"
 )
 
 // PermissionService 权限服务
@@ -39,7 +41,7 @@ func (s *PermissionService) CreateUserGroup(name, description string) (*models.U
 func (s *PermissionService) UpdateUserGroup(id uint, name, description string) (*models.UserGroup, error) {
 	var group models.UserGroup
 	if err := s.db.First(&group, id).Error; err != nil {
-		return nil, fmt.Errorf("用户组不存在")
+		return nil, errcode.New(errcode.UserGroupNotFound)
 	}
 
 	group.Name = name
@@ -56,7 +58,7 @@ func (s *PermissionService) DeleteUserGroup(id uint) error {
 	var count int64
 	s.db.Model(&models.ClusterPermission{}).Where("user_group_id = ?", id).Count(&count)
 	if count > 0 {
-		return fmt.Errorf("该用户组还有关联的权限配置，请先删除相关权限")
+		return errcode.New(errcode.UserGroupInUse)
 	}
 
 	// 删除用户组成员关联
@@ -73,7 +75,7 @@ func (s *PermissionService) DeleteUserGroup(id uint) error {
 func (s *PermissionService) GetUserGroup(id uint) (*models.UserGroup, error) {
 	var group models.UserGroup
 	if err := s.db.Preload("Users").First(&group, id).Error; err != nil {
-		return nil, fmt.Errorf("用户组不存在")
+		return nil, errcode.New(errcode.UserGroupNotFound)
 	}
 	return &group, nil
 }
@@ -92,13 +94,13 @@ func (s *PermissionService) AddUserToGroup(userID, groupID uint) error {
 	// 检查用户是否存在
 	var user models.User
 	if err := s.db.First(&user, userID).Error; err != nil {
-		return fmt.Errorf("用户不存在")
+		return errcode.New(errcode.UserNotFound)
 	}
 
 	// 检查用户组是否存在
 	var group models.UserGroup
 	if err := s.db.First(&group, groupID).Error; err != nil {
-		return fmt.Errorf("用户组不存在")
+		return errcode.New(errcode.UserGroupNotFound)
 	}
 
 	// 检查是否已在组中
@@ -125,15 +127,30 @@ func (s *PermissionService) RemoveUserFromGroup(userID, groupID uint) error {
 
 // CreateClusterPermission 创建集群权限
 func (s *PermissionService) CreateClusterPermission(req *CreateClusterPermissionRequest) (*models.ClusterPermission, error) {
+	// 指定集群分组时使用分组的标签选择器
+	if req.ClusterGroupID != nil {
+		if req.ClusterID != 0 || len(req.ClusterSelector) > 0 {
+			return nil, errcode.New(errcode.PermissionGroupScopeConflict)
+		}
+		var group models.ClusterGroup
+		if err := s.db.First(&group, *req.ClusterGroupID).Error; err != nil {
+			return nil, errcode.New(errcode.ClusterGroupNotFound)
+		}
+		req.ClusterSelector = group.GetSelector()
+	}
+
 	// 验证参数
-	if req.ClusterID == 0 {
-		return nil, errors.New("集群ID不能为空")
+	if req.ClusterID == 0 && len(req.ClusterSelector) == 0 {
+		return nil, errcode.New(errcode.PermissionScopeRequired)
+	}
+	if req.ClusterID != 0 && len(req.ClusterSelector) > 0 {
+		return nil, errcode.New(errcode.PermissionScopeConflict)
 	}
 	if req.UserID == nil && req.UserGroupID == nil {
-		return nil, errors.New("必须指定用户或用户组")
+		return nil, errcode.New(errcode.PermissionSubjectRequired)
 	}
 	if req.UserID != nil && req.UserGroupID != nil {
-		return nil, errors.New("不能同时指定用户和用户组")
+		return nil, errcode.New(errcode.PermissionSubjectConflict)
 	}
 
 	// 验证权限类型
@@ -145,16 +162,26 @@ func (s *PermissionService) CreateClusterPermission(req *CreateClusterPermission
 		models.PermissionTypeCustom:   true,
 	}
 	if !validTypes[req.PermissionType] {
-		return nil, errors.New("无效的权限类型")
+		return nil, errcode.New(errcode.InvalidPermissionType)
 	}
 
 	// 自定义权限必须指定角色
 	if req.PermissionType == models.PermissionTypeCustom && req.CustomRoleRef == "" {
-		return nil, errors.New("自定义权限必须指定ClusterRole或Role")
+		return nil, errcode.New(errcode.CustomRoleRequired)
+	}
+
+	// 按标签选择器授权时，选择器序列化结果（键有序）作为判重依据
+	var clusterSelector string
+	if len(req.ClusterSelector) > 0 {
+		selectorJSON, _ := json.Marshal(req.ClusterSelector)
+		clusterSelector = string(selectorJSON)
 	}
 
-	// 检查是否已存在相同的权限配置
-	query := s.db.Model(&models.ClusterPermission{}).Where("cluster_id = ?", req.ClusterID)
+	// 检查是否已存在相同的权限配置（临时提权产生的权限不参与判重）
+	query := s.db.Model(&models.ClusterPermission{}).Where("cluster_id = ? AND expires_at IS NULL", req.ClusterID)
+	if clusterSelector != "" {
+		query = query.Where("cluster_selector = ?", clusterSelector)
+	}
 	if req.UserID != nil {
 		query = query.Where("user_id = ?", *req.UserID)
 	} else {
@@ -163,7 +190,7 @@ func (s *PermissionService) CreateClusterPermission(req *CreateClusterPermission
 	var count int64
 	query.Count(&count)
 	if count > 0 {
-		return nil, errors.New("该用户/用户组在此集群已有权限配置")
+		return nil, errcode.New(errcode.PermissionExists)
 	}
 
 	// 处理命名空间
@@ -174,12 +201,13 @@ func (s *PermissionService) CreateClusterPermission(req *CreateClusterPermission
 	namespacesJSON, _ := json.Marshal(namespaces)
 
 	permission := &models.ClusterPermission{
-		ClusterID:      req.ClusterID,
-		UserID:         req.UserID,
-		UserGroupID:    req.UserGroupID,
-		PermissionType: req.PermissionType,
-		Namespaces:     string(namespacesJSON),
-		CustomRoleRef:  req.CustomRoleRef,
+		ClusterID:       req.ClusterID,
+		ClusterSelector: clusterSelector,
+		UserID:          req.UserID,
+		UserGroupID:     req.UserGroupID,
+		PermissionType:  req.PermissionType,
+		Namespaces:      string(namespacesJSON),
+		CustomRoleRef:   req.CustomRoleRef,
 	}
 
 	if err := s.db.Create(permission).Error; err != nil {
@@ -189,27 +217,29 @@ func (s *PermissionService) CreateClusterPermission(req *CreateClusterPermission
 	// 预加载关联数据
 	s.db.Preload("User").Preload("UserGroup").Preload("Cluster").First(permission, permission.ID)
 
-	logger.Info("创建集群权限: clusterID=%d, userID=%v, userGroupID=%v, type=%s",
-		req.ClusterID, req.UserID, req.UserGroupID, req.PermissionType)
+	logger.Info("创建集群权限: clusterID=%d, selector=%s, userID=%v, userGroupID=%v, type=%s",
+		req.ClusterID, clusterSelector, req.UserID, req.UserGroupID, req.PermissionType)
 
 	return permission, nil
 }
 
 // CreateClusterPermissionRequest 创建集群权限请求
 type CreateClusterPermissionRequest struct {
-	ClusterID      uint     `json:"cluster_id" binding:"required"`
-	UserID         *uint    `json:"user_id"`
-	UserGroupID    *uint    `json:"user_group_id"`
-	PermissionType string   `json:"permission_type" binding:"required"`
-	Namespaces     []string `json:"namespaces"`
-	CustomRoleRef  string   `json:"custom_role_ref"`
+	ClusterID       uint              `json:"cluster_id"`
+	ClusterSelector map[string]string `json:"cluster_selector"` // 与 ClusterID 二选一
+	ClusterGroupID  *uint             `json:"cluster_group_id"` // 使用集群分组的选择器（创建时复制）
+	UserID          *uint             `json:"user_id"`
+	UserGroupID     *uint             `json:"user_group_id"`
+	PermissionType  string            `json:"permission_type" binding:"required"`
+	Namespaces      []string          `json:"namespaces"`
+	CustomRoleRef   string            `json:"custom_role_ref"`
 }
 
 // UpdateClusterPermission 更新集群权限
 func (s *PermissionService) UpdateClusterPermission(id uint, req *UpdateClusterPermissionRequest) (*models.ClusterPermission, error) {
 	var permission models.ClusterPermission
 	if err := s.db.First(&permission, id).Error; err != nil {
-		return nil, errors.New("权限配置不存在")
+		return nil, errcode.New(errcode.PermissionNotFound)
 	}
 
 	// 验证权限类型
@@ -222,7 +252,7 @@ func (s *PermissionService) UpdateClusterPermission(id uint, req *UpdateClusterP
 			models.PermissionTypeCustom:   true,
 		}
 		if !validTypes[req.PermissionType] {
-			return nil, errors.New("无效的权限类型")
+			return nil, errcode.New(errcode.InvalidPermissionType)
 		}
 		permission.PermissionType = req.PermissionType
 	}
@@ -232,7 +262,7 @@ func (s *PermissionService) UpdateClusterPermission(id uint, req *UpdateClusterP
 		if req.CustomRoleRef != "" {
 			permission.CustomRoleRef = req.CustomRoleRef
 		} else if permission.CustomRoleRef == "" {
-			return nil, errors.New("自定义权限必须指定ClusterRole或Role")
+			return nil, errcode.New(errcode.CustomRoleRequired)
 		}
 	}
 
@@ -242,6 +272,15 @@ func (s *PermissionService) UpdateClusterPermission(id uint, req *UpdateClusterP
 		permission.Namespaces = string(namespacesJSON)
 	}
 
+	// 更新集群标签选择器（仅按选择器授权的权限可修改）
+	if len(req.ClusterSelector) > 0 {
+		if !permission.IsSelectorGrant() {
+			return nil, errcode.New(errcode.PermissionSelectorOnCluster)
+		}
+		selectorJSON, _ := json.Marshal(req.ClusterSelector)
+		permission.ClusterSelector = string(selectorJSON)
+	}
+
 	if err := s.db.Save(&permission).Error; err != nil {
 		return nil, fmt.Errorf("更新权限配置失败: %w", err)
 	}
@@ -254,9 +293,10 @@ func (s *PermissionService) UpdateClusterPermission(id uint, req *UpdateClusterP
 
 // UpdateClusterPermissionRequest 更新集群权限请求
 type UpdateClusterPermissionRequest struct {
-	PermissionType string   `json:"permission_type"`
-	Namespaces     []string `json:"namespaces"`
-	CustomRoleRef  string   `json:"custom_role_ref"`
+	PermissionType  string            `json:"permission_type"`
+	Namespaces      []string          `json:"namespaces"`
+	CustomRoleRef   string            `json:"custom_role_ref"`
+	ClusterSelector map[string]string `json:"cluster_selector"`
 }
 
 // DeleteClusterPermission 删除集群权限
@@ -266,7 +306,7 @@ func (s *PermissionService) DeleteClusterPermission(id uint) error {
 		return fmt.Errorf("删除权限配置失败: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return errors.New("权限配置不存在")
+		return errcode.New(errcode.PermissionNotFound)
 	}
 	return nil
 }
@@ -275,22 +315,33 @@ func (s *PermissionService) DeleteClusterPermission(id uint) error {
 func (s *PermissionService) GetClusterPermission(id uint) (*models.ClusterPermission, error) {
 	var permission models.ClusterPermission
 	if err := s.db.Preload("User").Preload("UserGroup").Preload("Cluster").First(&permission, id).Error; err != nil {
-		return nil, errors.New("权限配置不存在")
+		return nil, errcode.New(errcode.PermissionNotFound)
 	}
 	return &permission, nil
 }
 
-// ListClusterPermissions 获取集群的权限列表
+// ListClusterPermissions 获取集群的权限列表（包含标签选择器匹配该集群的权限）
 func (s *PermissionService) ListClusterPermissions(clusterID uint) ([]models.ClusterPermission, error) {
 	var permissions []models.ClusterPermission
 	query := s.db.Preload("User").Preload("UserGroup")
 	if clusterID > 0 {
-		query = query.Where("cluster_id = ?", clusterID)
+		query = query.Where("cluster_id = ? OR (cluster_id = 0 AND cluster_selector <> '')", clusterID)
 	}
 	if err := query.Find(&permissions).Error; err != nil {
 		return nil, fmt.Errorf("获取权限列表失败: %w", err)
 	}
-	return permissions, nil
+	if clusterID == 0 {
+		return permissions, nil
+	}
+
+	labels := s.getClusterLabels(clusterID)
+	filtered := make([]models.ClusterPermission, 0, len(permissions))
+	for _, p := range permissions {
+		if p.AppliesToCluster(clusterID, labels) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered, nil
 }
 
 // ListAllClusterPermissions 获取所有集群的权限列表
@@ -306,12 +357,22 @@ func (s *PermissionService) ListAllClusterPermissions() ([]models.ClusterPermiss
 
 // GetUserClusterPermission 获取用户在指定集群的权限
 // 权限优先级：用户直接权限 > 用户组权限 > 默认权限
+// 同一主体下，单集群授权优先于标签选择器授权
 func (s *PermissionService) GetUserClusterPermission(userID, clusterID uint) (*models.ClusterPermission, error) {
-	// 1. 先查找用户直接权限
-	var directPermission models.ClusterPermission
-	err := s.db.Where("cluster_id = ? AND user_id = ?", clusterID, userID).First(&directPermission).Error
+	now := time.Now()
+
+	// 1. 先查找用户直接权限（生效中的临时提权优先于永久权限）
+	var directPermissions []models.ClusterPermission
+	err := s.db.Where("user_id = ?", userID).
+		Where("cluster_id = ? OR (cluster_id = 0 AND cluster_selector <> '')", clusterID).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Order("expires_at DESC").
+		Order("cluster_id DESC").
+		Find(&directPermissions).Error
 	if err == nil {
-		return &directPermission, nil
+		if permission := s.pickClusterPermission(directPermissions, clusterID); permission != nil {
+			return permission, nil
+		}
 	}
 
 	// 2. 查找用户组权限
@@ -324,12 +385,17 @@ func (s *PermissionService) GetUserClusterPermission(userID, clusterID uint) (*m
 			groupIDs[i] = ug.UserGroupID
 		}
 
-		var groupPermission models.ClusterPermission
-		err = s.db.Where("cluster_id = ? AND user_group_id IN ?", clusterID, groupIDs).
+		var groupPermissions []models.ClusterPermission
+		err = s.db.Where("user_group_id IN ?", groupIDs).
+			Where("cluster_id = ? OR (cluster_id = 0 AND cluster_selector <> '')", clusterID).
+			Where("expires_at IS NULL OR expires_at > ?", now).
 			Order("FIELD(permission_type, 'admin', 'ops', 'dev', 'readonly', 'custom')"). // 优先返回权限最大的
-			First(&groupPermission).Error
+			Order("cluster_id DESC").
+			Find(&groupPermissions).Error
 		if err == nil {
-			return &groupPermission, nil
+			if permission := s.pickClusterPermission(groupPermissions, clusterID); permission != nil {
+				return permission, nil
+			}
 		}
 	}
 
@@ -337,13 +403,195 @@ func (s *PermissionService) GetUserClusterPermission(userID, clusterID uint) (*m
 	return s.getDefaultPermission(userID, clusterID)
 }
 
+// pickClusterPermission 按顺序选出第一个作用于指定集群的权限
+// 标签选择器授权会绑定到当前集群返回，集群标签仅在需要时查询
+func (s *PermissionService) pickClusterPermission(permissions []models.ClusterPermission, clusterID uint) *models.ClusterPermission {
+	var labels map[string]string
+	for i := range permissions {
+		permission := permissions[i]
+		if !permission.IsSelectorGrant() {
+			return &permission
+		}
+		if labels == nil {
+			labels = s.getClusterLabels(clusterID)
+		}
+		if permission.AppliesToCluster(clusterID, labels) {
+			permission.ClusterID = clusterID
+			return &permission
+		}
+	}
+	return nil
+}
+
+// BindSelectorPermission 将标签选择器授权绑定到当前匹配的各个集群
+func (s *PermissionService) BindSelectorPermission(permission *models.ClusterPermission) []*models.ClusterPermission {
+	if !permission.IsSelectorGrant() {
+		return []*models.ClusterPermission{permission}
+	}
+	var clusters []models.Cluster
+	if err := s.db.Select("id", "labels").Find(&clusters).Error; err != nil {
+		logger.Error("获取集群列表失败: %v", err)
+		return nil
+	}
+
+	selector := permission.GetClusterSelector()
+	var bound []*models.ClusterPermission
+	for _, cluster := range clusters {
+		if models.MatchLabels(selector, cluster.GetLabels()) {
+			p := *permission
+			p.ClusterID = cluster.ID
+			bound = append(bound, &p)
+		}
+	}
+	return bound
+}
+
+// SelectorGrantChanges 集群标签变化后，返回开始匹配与不再匹配该集群的用户标签选择器授权（已绑定到该集群）
+// 导入集群时 oldLabels 传 nil
+func (s *PermissionService) SelectorGrantChanges(clusterID uint, oldLabels, newLabels map[string]string) ([]*models.ClusterPermission, []*models.ClusterPermission, error) {
+	var grants []models.ClusterPermission
+	err := s.db.Where("cluster_id = 0 AND cluster_selector <> '' AND user_id IS NOT NULL").
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Find(&grants).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取标签选择器授权失败: %w", err)
+	}
+
+	var matched, unmatched []*models.ClusterPermission
+	for i := range grants {
+		selector := grants[i].GetClusterSelector()
+		before, after := oldLabels != nil && models.MatchLabels(selector, oldLabels), models.MatchLabels(selector, newLabels)
+		if before == after {
+			continue
+		}
+		p := grants[i]
+		p.ClusterID = clusterID
+		if after {
+			matched = append(matched, &p)
+		} else {
+			unmatched = append(unmatched, &p)
+		}
+	}
+	return matched, unmatched, nil
+}
+
+// ReconcileSelectorRBAC 集群导入或标签变化后同步标签选择器授权在该集群内的 RBAC
+// 开始匹配的授权创建绑定，不再匹配的授权清理绑定（其他有效权限仍在使用的绑定予以保留）
+func (s *PermissionService) ReconcileSelectorRBAC(rbacSvc *RBACService, clusterID uint, oldLabels, newLabels map[string]string) {
+	matched, unmatched, err := s.SelectorGrantChanges(clusterID, oldLabels, newLabels)
+	if err != nil {
+		logger.Error("同步标签选择器授权失败: clusterID=%d, err=%v", clusterID, err)
+		return
+	}
+	if len(matched) == 0 && len(unmatched) == 0 {
+		return
+	}
+
+	clientset, err := clusterClientsetByID(s.db, clusterID)
+	if err != nil {
+		logger.Error("创建 K8s 客户端失败，无法同步标签选择器授权 RBAC: clusterID=%d, err=%v", clusterID, err)
+		return
+	}
+	// 先清理后创建，同类型的新匹配授权可以补回被清理的绑定
+	for _, permission := range unmatched {
+		if err := cleanupRevokedUserRBAC(s.db, rbacSvc, clientset, permission); err != nil {
+			logger.Error("清理标签选择器授权 RBAC 失败: permissionID=%d, clusterID=%d, err=%v", permission.ID, clusterID, err)
+		}
+	}
+	for _, permission := range matched {
+		config := &UserRBACConfig{
+			UserID:         *permission.UserID,
+			PermissionType: permission.PermissionType,
+			Namespaces:     permission.GetNamespaceList(),
+			ClusterRoleRef: permission.CustomRoleRef,
+		}
+		if err := rbacSvc.EnsureUserRBAC(clientset, config); err != nil {
+			logger.Error("创建标签选择器授权 RBAC 失败: permissionID=%d, clusterID=%d, err=%v", permission.ID, clusterID, err)
+		}
+	}
+	logger.Info("标签选择器授权已同步: clusterID=%d, matched=%d, unmatched=%d", clusterID, len(matched), len(unmatched))
+}
+
+// StaleUserBindings 计算已删除的用户权限在集群内可以清理的绑定
+func (s *PermissionService) StaleUserBindings(permission *models.ClusterPermission) (bool, []string, error) {
+	return staleUserBindings(s.db, permission)
+}
+
+// staleUserBindings 计算已删除的用户权限在集群内可以清理的绑定
+// 用户专属绑定的名称只按用户与权限类型区分，同一集群上同类型的其他有效权限（如常驻权限与临时提权并存）
+// 仍在使用的绑定必须保留：ClusterRoleBinding 仅在被删除权限覆盖全部命名空间、且没有其他同类全部命名空间权限时清理；
+// RoleBinding 只清理其他同类权限未覆盖的命名空间
+func staleUserBindings(db *gorm.DB, permission *models.ClusterPermission) (bool, []string, error) {
+	var others []models.ClusterPermission
+	err := db.Where("user_id = ? AND permission_type = ? AND id <> ?", *permission.UserID, permission.PermissionType, permission.ID).
+		Where("cluster_id = ? OR (cluster_id = 0 AND cluster_selector <> '')", permission.ClusterID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Find(&others).Error
+	if err != nil {
+		return false, nil, fmt.Errorf("查询同类权限失败: %w", err)
+	}
+
+	var labels map[string]string
+	retainedAll := false
+	retained := make(map[string]bool)
+	for i := range others {
+		other := &others[i]
+		if other.IsSelectorGrant() {
+			if labels == nil {
+				labels = clusterLabels(db, permission.ClusterID)
+			}
+			if !other.AppliesToCluster(permission.ClusterID, labels) {
+				continue
+			}
+		}
+		for _, ns := range other.GetNamespaceList() {
+			if ns == "*" {
+				retainedAll = true
+			}
+			retained[ns] = true
+		}
+	}
+
+	namespaces := permission.GetNamespaceList()
+	var stale []string
+	for _, ns := range namespaces {
+		if ns != "" && ns != "*" && !retained[ns] {
+			stale = append(stale, ns)
+		}
+	}
+	return hasAllNamespaces(namespaces) && !retainedAll, stale, nil
+}
+
+// cleanupRevokedUserRBAC 清理已删除权限对应的用户绑定，同类型其他有效权限仍在使用的绑定予以保留
+func cleanupRevokedUserRBAC(db *gorm.DB, rbacSvc *RBACService, clientset *kubernetes.Clientset, permission *models.ClusterPermission) error {
+	clusterBinding, namespaces, err := staleUserBindings(db, permission)
+	if err != nil {
+		return err
+	}
+	return rbacSvc.CleanupUserBindings(clientset, *permission.UserID, permission.PermissionType, clusterBinding, namespaces)
+}
+
+// getClusterLabels 获取集群标签
+func (s *PermissionService) getClusterLabels(clusterID uint) map[string]string {
+	return clusterLabels(s.db, clusterID)
+}
+
+// clusterLabels 获取集群标签
+func clusterLabels(db *gorm.DB, clusterID uint) map[string]string {
+	var cluster models.Cluster
+	if err := db.Select("id", "labels").First(&cluster, clusterID).Error; err != nil {
+		return map[string]string{}
+	}
+	return cluster.GetLabels()
+}
+
 // getDefaultPermission 获取用户的默认权限
 // admin 用户默认为管理员权限，其他用户默认为只读权限
 func (s *PermissionService) getDefaultPermission(userID, clusterID uint) (*models.ClusterPermission, error) {
 	// 查询用户信息
 	var user models.User
 	if err := s.db.First(&user, userID).Error; err != nil {
-		return nil, errors.New("用户不存在")
+		return nil, errcode.New(errcode.UserNotFound)
 	}
 
 	// 确定默认权限类型
@@ -378,28 +626,32 @@ func (s *PermissionService) GetUserAllClusterPermissions(userID uint) ([]models.
 		groupIDs[i] = ug.UserGroupID
 	}
 
-	// 查询用户直接权限和用户组权限
+	// 查询用户直接权限和用户组权限（排除已过期的临时权限）
 	query := s.db.Preload("Cluster").Where("user_id = ?", userID)
 	if len(groupIDs) > 0 {
 		query = s.db.Preload("Cluster").Where("user_id = ? OR user_group_id IN ?", userID, groupIDs)
 	}
+	query = query.Where("expires_at IS NULL OR expires_at > ?", time.Now())
 
 	if err := query.Find(&permissions).Error; err != nil {
 		return nil, fmt.Errorf("获取用户权限失败: %w", err)
 	}
 
-	// 获取已配置权限的集群ID
-	configuredClusterIDs := make(map[uint]bool)
-	for _, p := range permissions {
-		configuredClusterIDs[p.ClusterID] = true
-	}
-
 	// 获取所有集群，为未配置权限的集群添加默认权限
 	var allClusters []models.Cluster
 	if err := s.db.Find(&allClusters).Error; err != nil {
 		return nil, fmt.Errorf("获取集群列表失败: %w", err)
 	}
 
+	// 标签选择器授权展开为匹配集群上的权限，单集群授权优先
+	permissions = expandSelectorPermissions(permissions, allClusters)
+
+	// 获取已配置权限的集群ID
+	configuredClusterIDs := make(map[uint]bool)
+	for _, p := range permissions {
+		configuredClusterIDs[p.ClusterID] = true
+	}
+
 	// 查询用户信息（用于确定默认权限类型）
 	var user models.User
 	if err := s.db.First(&user, userID).Error; err != nil {
@@ -477,6 +729,21 @@ func HasAllNamespaceAccess(cp *models.ClusterPermission) bool {
 	return false
 }
 
+// explicitGrantActions 高危操作（调试容器、节点特权调试、向容器写文件）：
+// 除 admin 外默认不允许，ops、dev 与自定义权限需通过细粒度策略显式允许
+var explicitGrantActions = map[string]bool{
+	"node:debug": true, "pod:debug": true, "pod:upload": true,
+}
+
+// RequiresExplicitGrant 操作是否需要细粒度策略显式允许（只读权限不能通过策略放宽）
+func RequiresExplicitGrant(cp *models.ClusterPermission, action string) bool {
+	switch cp.PermissionType {
+	case models.PermissionTypeOps, models.PermissionTypeDev, models.PermissionTypeCustom:
+		return explicitGrantActions[action]
+	}
+	return false
+}
+
 // CanPerformAction 检查权限类型是否允许执行指定操作
 func CanPerformAction(cp *models.ClusterPermission, action string) bool {
 	switch cp.PermissionType {
@@ -489,8 +756,11 @@ func CanPerformAction(cp *models.ClusterPermission, action string) bool {
 			"storageclass:create": true, "storageclass:delete": true,
 			"quota:create": true, "quota:update": true, "quota:delete": true,
 		}
-		return !restrictedActions[action]
+		return !restrictedActions[action] && !explicitGrantActions[action]
 	case models.PermissionTypeDev:
+		if explicitGrantActions[action] {
+			return false
+		}
 		allowedPrefixes := []string{
 			"pod:", "deployment:", "statefulset:", "daemonset:",
 			"job:", "cronjob:", "service:", "ingress:",
@@ -505,7 +775,8 @@ func CanPerformAction(cp *models.ClusterPermission, action string) bool {
 	case models.PermissionTypeReadonly:
 		return action == "view" || action == "list" || action == "get"
 	case models.PermissionTypeCustom:
-		return true
+		// 自定义权限的实际范围由引用的 ClusterRole/Role 决定，平台无法据此判断是否包含高危操作
+		return !explicitGrantActions[action]
 	default:
 		return false
 	}
@@ -526,7 +797,7 @@ func (s *PermissionService) ListUsers() ([]models.User, error) {
 func (s *PermissionService) GetUser(id uint) (*models.User, error) {
 	var user models.User
 	if err := s.db.Preload("Roles").First(&user, id).Error; err != nil {
-		return nil, errors.New("用户不存在")
+		return nil, errcode.New(errcode.UserNotFound)
 	}
 	return &user, nil
 }
@@ -536,7 +807,7 @@ func (s *PermissionService) GetUser(id uint) (*models.User, error) {
 func (s *PermissionService) GetUserAccessibleClusterIDs(userID uint) ([]uint, bool, error) {
 	var user models.User
 	if err := s.db.First(&user, userID).Error; err != nil {
-		return nil, false, errors.New("用户不存在")
+		return nil, false, errcode.New(errcode.UserNotFound)
 	}
 
 	// admin 用户拥有全部集群权限
@@ -544,10 +815,22 @@ func (s *PermissionService) GetUserAccessibleClusterIDs(userID uint) ([]uint, bo
 		return nil, true, nil
 	}
 
-	// 检查用户是否直接拥有 admin 权限（即为平台管理员）
+	// 租户成员只能访问租户拥有命名空间的集群
+	scope, err := LoadTenantScope(s.db, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if scope != nil {
+		if scope.Disabled {
+			return []uint{}, false, nil
+		}
+		return scope.ClusterIDs(), false, nil
+	}
+
+	// 检查用户是否直接拥有 admin 权限（即为平台管理员，临时提权不计入）
 	var adminCount int64
 	s.db.Model(&models.ClusterPermission{}).
-		Where("user_id = ? AND permission_type = ?", userID, models.PermissionTypeAdmin).
+		Where("user_id = ? AND permission_type = ? AND expires_at IS NULL", userID, models.PermissionTypeAdmin).
 		Count(&adminCount)
 	if adminCount > 0 {
 		return nil, true, nil
@@ -560,7 +843,7 @@ func (s *PermissionService) GetUserAccessibleClusterIDs(userID uint) ([]uint, bo
 	// 检查用户组是否有 admin 权限
 	if len(groupIDs) > 0 {
 		s.db.Model(&models.ClusterPermission{}).
-			Where("user_group_id IN ? AND permission_type = ?", groupIDs, models.PermissionTypeAdmin).
+			Where("user_group_id IN ? AND permission_type = ? AND expires_at IS NULL", groupIDs, models.PermissionTypeAdmin).
 			Count(&adminCount)
 		if adminCount > 0 {
 			return nil, true, nil
@@ -568,22 +851,91 @@ func (s *PermissionService) GetUserAccessibleClusterIDs(userID uint) ([]uint, bo
 	}
 
 	// 收集用户有明确权限的集群 ID（直接权限 + 用户组权限）
-	var clusterIDs []uint
-	query := s.db.Model(&models.ClusterPermission{}).Where("user_id = ?", userID)
+	var grants []models.ClusterPermission
+	query := s.db.Select("id", "cluster_id", "cluster_selector").Where("user_id = ?", userID)
 	if len(groupIDs) > 0 {
-		query = s.db.Model(&models.ClusterPermission{}).Where("user_id = ? OR user_group_id IN ?", userID, groupIDs)
+		query = s.db.Select("id", "cluster_id", "cluster_selector").Where("user_id = ? OR user_group_id IN ?", userID, groupIDs)
 	}
-	query.Distinct().Pluck("cluster_id", &clusterIDs)
+	query.Where("expires_at IS NULL OR expires_at > ?", time.Now()).Find(&grants)
 
 	// 没有任何明确权限记录的用户，拥有所有集群的默认只读权限
 	// 与 GetUserAllClusterPermissions / GetUserClusterPermission 保持一致
-	if len(clusterIDs) == 0 {
+	if len(grants) == 0 {
 		return nil, true, nil
 	}
 
+	seen := make(map[uint]bool)
+	clusterIDs := make([]uint, 0, len(grants))
+	var selectorGrants []models.ClusterPermission
+	for _, grant := range grants {
+		if grant.IsSelectorGrant() {
+			selectorGrants = append(selectorGrants, grant)
+			continue
+		}
+		if !seen[grant.ClusterID] {
+			seen[grant.ClusterID] = true
+			clusterIDs = append(clusterIDs, grant.ClusterID)
+		}
+	}
+
+	// 标签选择器授权：匹配的集群（含新导入集群）自动纳入
+	if len(selectorGrants) > 0 {
+		var clusters []models.Cluster
+		s.db.Select("id", "labels").Find(&clusters)
+		for _, cluster := range clusters {
+			if seen[cluster.ID] {
+				continue
+			}
+			labels := cluster.GetLabels()
+			for _, grant := range selectorGrants {
+				if grant.AppliesToCluster(cluster.ID, labels) {
+					seen[cluster.ID] = true
+					clusterIDs = append(clusterIDs, cluster.ID)
+					break
+				}
+			}
+		}
+	}
+
 	return clusterIDs, false, nil
 }
 
+// expandSelectorPermissions 将标签选择器授权展开为各匹配集群上的权限
+// 已有单集群授权的集群不再叠加选择器授权
+func expandSelectorPermissions(permissions []models.ClusterPermission, clusters []models.Cluster) []models.ClusterPermission {
+	expanded := make([]models.ClusterPermission, 0, len(permissions))
+	explicit := make(map[uint]bool)
+	var selectorGrants []models.ClusterPermission
+	for _, p := range permissions {
+		if p.IsSelectorGrant() {
+			selectorGrants = append(selectorGrants, p)
+			continue
+		}
+		explicit[p.ClusterID] = true
+		expanded = append(expanded, p)
+	}
+	if len(selectorGrants) == 0 {
+		return expanded
+	}
+
+	for i := range clusters {
+		cluster := &clusters[i]
+		if explicit[cluster.ID] {
+			continue
+		}
+		labels := cluster.GetLabels()
+		for _, grant := range selectorGrants {
+			if grant.AppliesToCluster(cluster.ID, labels) {
+				grant.ClusterID = cluster.ID
+				grant.Cluster = cluster
+				expanded = append(expanded, grant)
+				break
+			}
+		}
+	}
+	return expanded
+}
+
 // BatchDeleteClusterPermissions 批量删除集群权限
 func (s *PermissionService) BatchDeleteClusterPermissions(ids []uint) error {
 	if len(ids) == 0 {
@@ -10,6 +10,8 @@ import (
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
 )
 
 // PermissionServiceTestSuite 定义权限服务测试套件
@@ -187,6 +189,25 @@ func (s *PermissionServiceTestSuite) TestListUsers() {
 	assert.Len(s.T(), users, 2)
 }
 
+// TestCanPerformAction 各权限类型对高危操作的判定
+func TestCanPerformAction(t *testing.T) {
+	actions := []string{"view", "pod:exec", "pod:debug", "pod:upload", "node:debug", "node:drain", "pv:delete"}
+	cases := map[string][]bool{
+		models.PermissionTypeAdmin:    {true, true, true, true, true, true, true},
+		models.PermissionTypeOps:      {true, true, false, false, false, false, false},
+		models.PermissionTypeDev:      {false, true, false, false, false, false, false},
+		models.PermissionTypeReadonly: {true, false, false, false, false, false, false},
+		models.PermissionTypeCustom:   {true, true, false, false, false, true, true},
+		"unknown":                     {false, false, false, false, false, false, false},
+	}
+	for permissionType, want := range cases {
+		cp := &models.ClusterPermission{PermissionType: permissionType}
+		for i, action := range actions {
+			assert.Equal(t, want[i], CanPerformAction(cp, action), "%s %s", permissionType, action)
+		}
+	}
+}
+
 // TestPermissionServiceSuite 运行测试套件
 func TestPermissionServiceSuite(t *testing.T) {
 	suite.Run(t, new(PermissionServiceTestSuite))
@@ -0,0 +1,453 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// policyCacheTTL 策略缓存有效期（增删改时会主动失效）
+const policyCacheTTL = 30 * time.Second
+
+// PolicyRequest 策略评估请求
+type PolicyRequest struct {
+	UserID        uint              `json:"user_id"`
+	GroupIDs      []uint            `json:"group_ids,omitempty"`
+	ClusterID     uint              `json:"cluster_id"`
+	ClusterLabels map[string]string `json:"cluster_labels,omitempty"`
+	Namespace     string            `json:"namespace"`
+	Resource      string            `json:"resource"`
+	Action        string            `json:"action"`
+}
+
+// PolicyRuleRef 命中的策略规则摘要
+type PolicyRuleRef struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Effect   string `json:"effect"`
+	Priority int    `json:"priority"`
+}
+
+// PolicyDecision 策略评估结果
+type PolicyDecision struct {
+	// Effect 为 allow / deny，未命中任何规则时为空，表示回退到权限类型判断
+	Effect       string          `json:"effect"`
+	DecidingRule *PolicyRuleRef  `json:"deciding_rule,omitempty"`
+	MatchedRules []PolicyRuleRef `json:"matched_rules"`
+	Reason       string          `json:"reason"`
+}
+
+// Denied 是否被策略拒绝
+func (d *PolicyDecision) Denied() bool {
+	return d != nil && d.Effect == models.PolicyEffectDeny
+}
+
+// Allowed 是否被策略显式允许
+func (d *PolicyDecision) Allowed() bool {
+	return d != nil && d.Effect == models.PolicyEffectAllow
+}
+
+// EvaluatePolicies 对请求评估策略列表，拒绝优先于允许
+func EvaluatePolicies(policies []models.PermissionPolicy, req *PolicyRequest) *PolicyDecision {
+	decision := &PolicyDecision{MatchedRules: []PolicyRuleRef{}}
+
+	var deny, allow *PolicyRuleRef
+	for i := range policies {
+		p := &policies[i]
+		if !p.Enabled || !policyMatches(p, req) {
+			continue
+		}
+		ref := PolicyRuleRef{ID: p.ID, Name: p.Name, Effect: p.Effect, Priority: p.Priority}
+		decision.MatchedRules = append(decision.MatchedRules, ref)
+
+		switch p.Effect {
+		case models.PolicyEffectDeny:
+			if deny == nil || ref.Priority > deny.Priority {
+				r := ref
+				deny = &r
+			}
+		case models.PolicyEffectAllow:
+			if allow == nil || ref.Priority > allow.Priority {
+				r := ref
+				allow = &r
+			}
+		}
+	}
+
+	switch {
+	case deny != nil:
+		decision.Effect = models.PolicyEffectDeny
+		decision.DecidingRule = deny
+		decision.Reason = fmt.Sprintf("命中拒绝策略 %q", deny.Name)
+	case allow != nil:
+		decision.Effect = models.PolicyEffectAllow
+		decision.DecidingRule = allow
+		decision.Reason = fmt.Sprintf("命中允许策略 %q", allow.Name)
+	default:
+		decision.Reason = "未命中任何策略，按权限类型判断"
+	}
+	return decision
+}
+
+// policyClusterScopedResources 不属于任何命名空间的集群资源
+var policyClusterScopedResources = map[string]bool{
+	"nodes":          true,
+	"pvs":            true,
+	"storageclasses": true,
+}
+
+// policyMatches 判断单条策略是否适用于请求
+func policyMatches(p *models.PermissionPolicy, req *PolicyRequest) bool {
+	// 主体
+	if p.UserID != nil && *p.UserID != req.UserID {
+		return false
+	}
+	if p.UserGroupID != nil {
+		inGroup := false
+		for _, gid := range req.GroupIDs {
+			if gid == *p.UserGroupID {
+				inGroup = true
+				break
+			}
+		}
+		if !inGroup {
+			return false
+		}
+	}
+
+	// 集群标签选择器
+	if !models.MatchLabels(p.GetClusterSelector(), req.ClusterLabels) {
+		return false
+	}
+
+	// 命名空间：请求不涉及命名空间时（如跨命名空间的列表），限定命名空间的拒绝策略视为命中，
+	// 避免通过全部命名空间的路由绕过；允许策略只匹配未限定命名空间的请求。集群级资源不受命名空间限定
+	if namespaces := p.GetNamespaceList(); len(namespaces) > 0 {
+		if req.Namespace == "" {
+			if p.Effect != models.PolicyEffectDeny || policyClusterScopedResources[strings.ToLower(req.Resource)] {
+				return false
+			}
+		} else if !matchPolicyPattern(namespaces, req.Namespace) {
+			return false
+		}
+	}
+
+	if resources := p.GetResourceList(); len(resources) > 0 {
+		if !matchPolicyPattern(resources, strings.ToLower(req.Resource)) {
+			return false
+		}
+	}
+
+	return matchPolicyPattern(p.GetActionList(), req.Action)
+}
+
+// matchPolicyPattern 判断值是否匹配模式列表（支持 * 通配）
+func matchPolicyPattern(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" {
+			return true
+		}
+		if value == "" {
+			continue
+		}
+		if ok, err := path.Match(strings.ToLower(pattern), value); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReadPolicyAction 是否为只读操作
+func IsReadPolicyAction(action string) bool {
+	switch action {
+	case models.PolicyActionGet, models.PolicyActionList, models.PolicyActionLogs, models.PolicyActionSecretRead:
+		return true
+	default:
+		return false
+	}
+}
+
+// ========== 策略管理 ==========
+
+// PolicyService 细粒度权限策略服务
+type PolicyService struct {
+	db *gorm.DB
+
+	mu       sync.RWMutex
+	cache    []models.PermissionPolicy
+	loadedAt time.Time
+}
+
+// NewPolicyService 创建策略服务
+func NewPolicyService(db *gorm.DB) *PolicyService {
+	return &PolicyService{db: db}
+}
+
+// PolicyRequestBody 创建/更新策略请求
+type PolicyRequestBody struct {
+	Name            string            `json:"name" binding:"required"`
+	Description     string            `json:"description"`
+	Effect          string            `json:"effect" binding:"required"`
+	Priority        int               `json:"priority"`
+	UserID          *uint             `json:"user_id"`
+	UserGroupID     *uint             `json:"user_group_id"`
+	ClusterSelector map[string]string `json:"cluster_selector"`
+	Namespaces      []string          `json:"namespaces"`
+	Resources       []string          `json:"resources"`
+	Actions         []string          `json:"actions" binding:"required"`
+	Enabled         *bool             `json:"enabled"`
+}
+
+// applyTo 校验并写入策略模型
+func (b *PolicyRequestBody) applyTo(p *models.PermissionPolicy) error {
+	if b.Effect != models.PolicyEffectAllow && b.Effect != models.PolicyEffectDeny {
+		return errcode.New(errcode.InvalidPolicyEffect)
+	}
+	if b.UserID != nil && b.UserGroupID != nil {
+		return errcode.New(errcode.PermissionSubjectConflict)
+	}
+	if len(b.Actions) == 0 {
+		return errcode.New(errcode.ApprovalActionsRequired)
+	}
+	for _, list := range [][]string{b.Namespaces, b.Resources, b.Actions} {
+		for _, pattern := range list {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return errcode.New(errcode.InvalidApprovalPattern, pattern)
+			}
+		}
+	}
+
+	p.Name = b.Name
+	p.Description = b.Description
+	p.Effect = b.Effect
+	p.Priority = b.Priority
+	p.UserID = b.UserID
+	p.UserGroupID = b.UserGroupID
+	p.ClusterSelector = encodeJSONOrEmpty(b.ClusterSelector, len(b.ClusterSelector) == 0)
+	p.Namespaces = encodeJSONOrEmpty(b.Namespaces, len(b.Namespaces) == 0)
+	p.Resources = encodeJSONOrEmpty(b.Resources, len(b.Resources) == 0)
+	p.Actions = encodeJSONOrEmpty(b.Actions, false)
+	p.Enabled = b.Enabled == nil || *b.Enabled
+	return nil
+}
+
+// encodeJSONOrEmpty 序列化为 JSON，empty 为 true 时返回空串
+func encodeJSONOrEmpty(v interface{}, empty bool) string {
+	if empty {
+		return ""
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// CreatePolicy 创建策略
+func (s *PolicyService) CreatePolicy(body *PolicyRequestBody) (*models.PermissionPolicy, error) {
+	policy := &models.PermissionPolicy{}
+	if err := body.applyTo(policy); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(policy).Error; err != nil {
+		return nil, fmt.Errorf("创建策略失败: %w", err)
+	}
+	s.invalidate()
+	logger.Info("创建权限策略: id=%d, name=%s, effect=%s", policy.ID, policy.Name, policy.Effect)
+	return s.GetPolicy(policy.ID)
+}
+
+// UpdatePolicy 更新策略
+func (s *PolicyService) UpdatePolicy(id uint, body *PolicyRequestBody) (*models.PermissionPolicy, error) {
+	var policy models.PermissionPolicy
+	if err := s.db.First(&policy, id).Error; err != nil {
+		return nil, errcode.New(errcode.PolicyNotFound)
+	}
+	if err := body.applyTo(&policy); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(&policy).Error; err != nil {
+		return nil, fmt.Errorf("更新策略失败: %w", err)
+	}
+	s.invalidate()
+	return s.GetPolicy(id)
+}
+
+// DeletePolicy 删除策略
+func (s *PolicyService) DeletePolicy(id uint) error {
+	result := s.db.Delete(&models.PermissionPolicy{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除策略失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errcode.New(errcode.PolicyNotFound)
+	}
+	s.invalidate()
+	return nil
+}
+
+// GetPolicy 获取策略详情
+func (s *PolicyService) GetPolicy(id uint) (*models.PermissionPolicy, error) {
+	var policy models.PermissionPolicy
+	if err := s.db.Preload("User").Preload("UserGroup").First(&policy, id).Error; err != nil {
+		return nil, errcode.New(errcode.PolicyNotFound)
+	}
+	return &policy, nil
+}
+
+// ListPolicies 获取策略列表
+func (s *PolicyService) ListPolicies() ([]models.PermissionPolicy, error) {
+	var policies []models.PermissionPolicy
+	if err := s.db.Preload("User").Preload("UserGroup").Order("priority DESC, id ASC").Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("获取策略列表失败: %w", err)
+	}
+	return policies, nil
+}
+
+// ========== 策略评估 ==========
+
+// enabledPolicies 获取已启用策略（带缓存）
+func (s *PolicyService) enabledPolicies() ([]models.PermissionPolicy, error) {
+	s.mu.RLock()
+	if s.cache != nil && time.Since(s.loadedAt) < policyCacheTTL {
+		policies := s.cache
+		s.mu.RUnlock()
+		return policies, nil
+	}
+	s.mu.RUnlock()
+
+	var policies []models.PermissionPolicy
+	if err := s.db.Where("enabled = ?", true).Order("priority DESC, id ASC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache = policies
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return policies, nil
+}
+
+// invalidate 使策略缓存失效
+func (s *PolicyService) invalidate() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}
+
+// BuildRequest 补全评估请求中的用户组与集群标签
+func (s *PolicyService) BuildRequest(userID, clusterID uint, namespace, resource, action string) *PolicyRequest {
+	req := &PolicyRequest{
+		UserID:    userID,
+		ClusterID: clusterID,
+		Namespace: namespace,
+		Resource:  resource,
+		Action:    action,
+	}
+	s.db.Model(&models.UserGroupMember{}).Where("user_id = ?", userID).Pluck("user_group_id", &req.GroupIDs)
+
+	var cluster models.Cluster
+	if err := s.db.Select("id", "labels").First(&cluster, clusterID).Error; err == nil {
+		req.ClusterLabels = cluster.GetLabels()
+	}
+	return req
+}
+
+// Evaluate 评估用户请求
+func (s *PolicyService) Evaluate(req *PolicyRequest) (*PolicyDecision, error) {
+	policies, err := s.enabledPolicies()
+	if err != nil {
+		return nil, fmt.Errorf("加载权限策略失败: %w", err)
+	}
+	if len(policies) == 0 {
+		return &PolicyDecision{MatchedRules: []PolicyRuleRef{}, Reason: "未配置任何策略，按权限类型判断"}, nil
+	}
+	return EvaluatePolicies(policies, req), nil
+}
+
+// Decide 在线评估用户请求，未配置任何策略时返回 nil（避免额外查询用户组与集群标签）
+func (s *PolicyService) Decide(userID, clusterID uint, namespace, resource, action string) (*PolicyDecision, error) {
+	policies, err := s.enabledPolicies()
+	if err != nil {
+		return nil, fmt.Errorf("加载权限策略失败: %w", err)
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	return EvaluatePolicies(policies, s.BuildRequest(userID, clusterID, namespace, resource, action)), nil
+}
+
+// PolicyExplanation 策略决策解释
+type PolicyExplanation struct {
+	Request            *PolicyRequest  `json:"request"`
+	Decision           *PolicyDecision `json:"decision"`
+	PermissionType     string          `json:"permission_type"`
+	PermissionID       uint            `json:"permission_id,omitempty"`
+	Allowed            bool            `json:"allowed"`
+	DecidedBy          string          `json:"decided_by"` // policy, permission_type
+	PermissionTypeNote string          `json:"permission_type_note,omitempty"`
+}
+
+// Explain 解释某用户请求最终由哪条规则决定
+func (s *PolicyService) Explain(permissionSvc *PermissionService, req *PolicyRequest) (*PolicyExplanation, error) {
+	decision, err := s.Evaluate(req)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &PolicyExplanation{Request: req, Decision: decision}
+	permission, err := permissionSvc.GetUserClusterPermission(req.UserID, req.ClusterID)
+	if err == nil {
+		explanation.PermissionType = permission.PermissionType
+		explanation.PermissionID = permission.ID
+	}
+
+	// 策略评估需要同时通过集群访问和命名空间范围检查
+	if permission == nil {
+		explanation.DecidedBy = "permission_type"
+		explanation.PermissionTypeNote = "用户无权访问该集群"
+		return explanation, nil
+	}
+	if req.Namespace != "" && !HasNamespaceAccess(permission, req.Namespace) {
+		explanation.DecidedBy = "permission_type"
+		explanation.PermissionTypeNote = "命名空间不在授权范围内"
+		return explanation, nil
+	}
+
+	switch {
+	case decision.Denied():
+		explanation.DecidedBy = "policy"
+		explanation.Allowed = false
+	case decision.Allowed():
+		explanation.DecidedBy = "policy"
+		explanation.Allowed = true
+	default:
+		explanation.DecidedBy = "permission_type"
+		explanation.Allowed = IsReadPolicyAction(req.Action) || permission.PermissionType != models.PermissionTypeReadonly
+		if !explanation.Allowed {
+			explanation.PermissionTypeNote = "只读权限无法执行写操作"
+		}
+	}
+	return explanation, nil
+}
+
+// SupportedPolicyActions 返回支持的策略操作列表
+func SupportedPolicyActions() []string {
+	actions := []string{
+		models.PolicyActionGet, models.PolicyActionList, models.PolicyActionCreate,
+		models.PolicyActionUpdate, models.PolicyActionDelete, models.PolicyActionApply,
+		models.PolicyActionScale, models.PolicyActionExec, models.PolicyActionLogs,
+		models.PolicyActionSecretRead, models.PolicyActionCordon, models.PolicyActionDrain,
+		models.PolicyActionSync, models.PolicyActionRollback, models.PolicyActionDebug,
+		models.PolicyActionPortForward,
+	}
+	sort.Strings(actions)
+	return actions
+}
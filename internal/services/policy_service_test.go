@@ -0,0 +1,131 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+func testPolicy(id uint, name, effect, selector, namespaces, resources, actions string) models.PermissionPolicy {
+	return models.PermissionPolicy{
+		ID:              id,
+		Name:            name,
+		Effect:          effect,
+		ClusterSelector: selector,
+		Namespaces:      namespaces,
+		Resources:       resources,
+		Actions:         actions,
+		Enabled:         true,
+	}
+}
+
+func TestEvaluatePoliciesDenyOverridesAllow(t *testing.T) {
+	policies := []models.PermissionPolicy{
+		testPolicy(1, "allow-exec", models.PolicyEffectAllow, "", "", `["pods"]`, `["exec"]`),
+		testPolicy(2, "deny-prod-exec", models.PolicyEffectDeny, `{"env":"prod"}`, `["prod-*"]`, `["pods"]`, `["exec"]`),
+	}
+
+	decision := EvaluatePolicies(policies, &PolicyRequest{
+		UserID:        7,
+		ClusterLabels: map[string]string{"env": "prod"},
+		Namespace:     "prod-payments",
+		Resource:      "pods",
+		Action:        models.PolicyActionExec,
+	})
+
+	if !decision.Denied() {
+		t.Fatalf("expected deny, got %q", decision.Effect)
+	}
+	if decision.DecidingRule == nil || decision.DecidingRule.Name != "deny-prod-exec" {
+		t.Fatalf("expected deny-prod-exec to decide, got %+v", decision.DecidingRule)
+	}
+	if len(decision.MatchedRules) != 2 {
+		t.Fatalf("expected 2 matched rules, got %d", len(decision.MatchedRules))
+	}
+}
+
+func TestEvaluatePoliciesRespectsClusterSelectorAndNamespacePattern(t *testing.T) {
+	policies := []models.PermissionPolicy{
+		testPolicy(1, "deny-prod-exec", models.PolicyEffectDeny, `{"env":"prod"}`, `["prod-*"]`, "", `["exec"]`),
+	}
+
+	staging := EvaluatePolicies(policies, &PolicyRequest{
+		ClusterLabels: map[string]string{"env": "staging"},
+		Namespace:     "prod-payments",
+		Resource:      "pods",
+		Action:        models.PolicyActionExec,
+	})
+	if staging.Effect != "" {
+		t.Fatalf("policy should not match staging cluster, got %q", staging.Effect)
+	}
+
+	otherNs := EvaluatePolicies(policies, &PolicyRequest{
+		ClusterLabels: map[string]string{"env": "prod"},
+		Namespace:     "dev-payments",
+		Resource:      "pods",
+		Action:        models.PolicyActionExec,
+	})
+	if otherNs.Effect != "" {
+		t.Fatalf("policy should not match dev namespace, got %q", otherNs.Effect)
+	}
+}
+
+func TestEvaluatePoliciesMatchesSubjects(t *testing.T) {
+	userID := uint(3)
+	groupID := uint(9)
+	byUser := testPolicy(1, "allow-user-scale", models.PolicyEffectAllow, "", "", "", `["scale"]`)
+	byUser.UserID = &userID
+	byGroup := testPolicy(2, "deny-group-scale", models.PolicyEffectDeny, "", "", "", `["scale"]`)
+	byGroup.UserGroupID = &groupID
+	policies := []models.PermissionPolicy{byUser, byGroup}
+
+	allowed := EvaluatePolicies(policies, &PolicyRequest{UserID: 3, Resource: "deployments", Action: models.PolicyActionScale})
+	if !allowed.Allowed() {
+		t.Fatalf("expected allow for user 3, got %q", allowed.Effect)
+	}
+
+	denied := EvaluatePolicies(policies, &PolicyRequest{UserID: 3, GroupIDs: []uint{9}, Resource: "deployments", Action: models.PolicyActionScale})
+	if !denied.Denied() {
+		t.Fatalf("expected group deny to override user allow, got %q", denied.Effect)
+	}
+
+	other := EvaluatePolicies(policies, &PolicyRequest{UserID: 4, Resource: "deployments", Action: models.PolicyActionScale})
+	if other.Effect != "" {
+		t.Fatalf("expected no decision for unrelated user, got %q", other.Effect)
+	}
+}
+
+func TestEvaluatePoliciesSkipsDisabledPolicies(t *testing.T) {
+	policy := testPolicy(1, "deny-all-delete", models.PolicyEffectDeny, "", "", "", `["delete"]`)
+	policy.Enabled = false
+
+	decision := EvaluatePolicies([]models.PermissionPolicy{policy}, &PolicyRequest{Resource: "pods", Action: models.PolicyActionDelete})
+	if decision.Effect != "" || len(decision.MatchedRules) != 0 {
+		t.Fatalf("disabled policy should be ignored, got %+v", decision)
+	}
+}
+
+func TestEvaluatePoliciesNamespaceScopedDenyCoversCrossNamespaceRequests(t *testing.T) {
+	policies := []models.PermissionPolicy{
+		testPolicy(1, "deny-prod-secrets", models.PolicyEffectDeny, "", `["prod-*"]`, `["secrets"]`, `["*"]`),
+		testPolicy(2, "allow-prod-scale", models.PolicyEffectAllow, "", `["prod-*"]`, "", `["scale"]`),
+		testPolicy(3, "deny-prod-delete", models.PolicyEffectDeny, "", `["prod-*"]`, "", `["delete"]`),
+	}
+
+	// 跨命名空间列表可能包含 prod-* 中的对象，按拒绝处理
+	list := EvaluatePolicies(policies, &PolicyRequest{Resource: "secrets", Action: models.PolicyActionList})
+	if !list.Denied() || list.DecidingRule.Name != "deny-prod-secrets" {
+		t.Fatalf("cluster-wide secret list should be denied, got %+v", list)
+	}
+	// 允许策略不因命名空间未知而放宽
+	if scale := EvaluatePolicies(policies, &PolicyRequest{Resource: "deployments", Action: models.PolicyActionScale}); scale.Effect != "" {
+		t.Fatalf("namespace-scoped allow must not match requests without namespace, got %q", scale.Effect)
+	}
+	// 集群级资源不属于任何命名空间
+	if node := EvaluatePolicies(policies, &PolicyRequest{Resource: "nodes", Action: models.PolicyActionDelete}); node.Effect != "" {
+		t.Fatalf("namespace-scoped deny must not match cluster-scoped resources, got %q", node.Effect)
+	}
+	if dev := EvaluatePolicies(policies, &PolicyRequest{Namespace: "dev-shop", Resource: "secrets", Action: models.PolicyActionList}); dev.Effect != "" {
+		t.Fatalf("dev namespace should not be denied, got %q", dev.Effect)
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/portforward"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// PortForwardService 端口转发审计记录服务
+type PortForwardService struct {
+	db *gorm.DB
+}
+
+// NewPortForwardService 创建端口转发审计记录服务
+func NewPortForwardService(db *gorm.DB) *PortForwardService {
+	return &PortForwardService{db: db}
+}
+
+// RecordOpen 记录转发建立
+func (s *PortForwardService) RecordOpen(info portforward.Info) error {
+	return s.db.Create(&models.PortForwardSession{
+		ForwardID:  info.ID,
+		UserID:     info.UserID,
+		Username:   info.Username,
+		ClusterID:  info.ClusterID,
+		Namespace:  info.Namespace,
+		TargetKind: info.TargetKind,
+		TargetName: info.TargetName,
+		Pod:        info.Pod,
+		RemotePort: info.RemotePort,
+		Status:     models.PortForwardStatusActive,
+		ExpiresAt:  info.ExpiresAt,
+		CreatedAt:  info.CreatedAt,
+	}).Error
+}
+
+// RecordClose 记录转发结束及流量统计
+func (s *PortForwardService) RecordClose(info portforward.Info, status, reason string) {
+	now := time.Now()
+	err := s.db.Model(&models.PortForwardSession{}).
+		Where("forward_id = ?", info.ID).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"close_reason": reason,
+			"connections":  info.Connections,
+			"bytes_in":     info.BytesIn,
+			"bytes_out":    info.BytesOut,
+			"closed_at":    &now,
+		}).Error
+	if err != nil {
+		logger.Error("记录端口转发结束失败", "forwardID", info.ID, "error", err)
+	}
+}
+
+// CloseStaleSessions 将上次运行遗留的 active 记录标记为结束（服务重启后转发已不存在）
+func (s *PortForwardService) CloseStaleSessions() error {
+	now := time.Now()
+	return s.db.Model(&models.PortForwardSession{}).
+		Where("status = ?", models.PortForwardStatusActive).
+		Updates(map[string]interface{}{
+			"status":       models.PortForwardStatusClosed,
+			"close_reason": "服务重启",
+			"closed_at":    &now,
+		}).Error
+}
+
+// PortForwardListRequest 端口转发记录查询
+type PortForwardListRequest struct {
+	UserID    uint
+	ClusterID uint
+	Status    string
+	Page      int
+	PageSize  int
+}
+
+// ListSessions 分页查询端口转发记录
+func (s *PortForwardService) ListSessions(req *PortForwardListRequest) ([]models.PortForwardSession, int64, error) {
+	query := s.db.Model(&models.PortForwardSession{})
+	if req.UserID > 0 {
+		query = query.Where("user_id = ?", req.UserID)
+	}
+	if req.ClusterID > 0 {
+		query = query.Where("cluster_id = ?", req.ClusterID)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+
+	var items []models.PortForwardSession
+	err := query.Order("created_at DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&items).Error
+	return items, total, err
+}
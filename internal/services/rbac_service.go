@@ -483,6 +483,15 @@ func GetUserServiceAccountName(userID uint) string {
 	return fmt.Sprintf("kubepolaris-user-%d-sa", userID)
 }
 
+// ParseUserServiceAccountName 从用户专属 SA 名称解析用户ID
+func ParseUserServiceAccountName(name string) (uint, bool) {
+	var userID uint
+	if _, err := fmt.Sscanf(name, "kubepolaris-user-%d-sa", &userID); err != nil || GetUserServiceAccountName(userID) != name {
+		return 0, false
+	}
+	return userID, true
+}
+
 // GetUserRoleBindingName 获取用户 RoleBinding 名称
 func GetUserRoleBindingName(userID uint, permissionType string) string {
 	return fmt.Sprintf("kubepolaris-user-%d-%s", userID, permissionType)
@@ -515,14 +524,9 @@ func (s *RBACService) EnsureUserRBAC(clientset *kubernetes.Clientset, config *Us
 		clusterRoleName = rbac.GetClusterRoleByPermissionType(config.PermissionType)
 	}
 
-	// admin 和 ops 使用固定 SA，不需要动态创建
-	if config.PermissionType == "admin" || config.PermissionType == "ops" {
-		logger.Info("admin/ops 使用固定 SA，无需动态创建", "userID", config.UserID, "permissionType", config.PermissionType)
-		return nil
-	}
-
-	// dev/readonly 全部命名空间时使用固定 SA
-	if (config.PermissionType == "dev" || config.PermissionType == "readonly") && hasAllAccess {
+	// 内置权限类型全部命名空间时使用固定 SA，不需要动态创建；
+	// 限定命名空间时（包括临时提权的 admin/ops）使用用户专属 SA 按命名空间绑定
+	if config.PermissionType != "custom" && hasAllAccess {
 		logger.Info("全部命名空间使用固定 SA，无需动态创建", "userID", config.UserID, "permissionType", config.PermissionType)
 		return nil
 	}
@@ -629,15 +633,23 @@ func (s *RBACService) ensureUserClusterRoleBinding(ctx context.Context, clientse
 
 // CleanupUserRBAC 清理用户的 RBAC 资源
 func (s *RBACService) CleanupUserRBAC(clientset *kubernetes.Clientset, userID uint, permissionType string, namespaces []string) error {
+	return s.CleanupUserBindings(clientset, userID, permissionType, true, namespaces)
+}
+
+// CleanupUserBindings 清理用户指定范围的绑定，clusterBinding 为 false 时保留 ClusterRoleBinding
+// 配合 PermissionService.StaleUserBindings 使用，避免删除同类型其他有效权限仍在使用的绑定
+func (s *RBACService) CleanupUserBindings(clientset *kubernetes.Clientset, userID uint, permissionType string, clusterBinding bool, namespaces []string) error {
 	ctx := context.Background()
 	saName := GetUserServiceAccountName(userID)
 
 	logger.Info("清理用户 RBAC 资源", "userID", userID, "saName", saName)
 
 	// 1. 删除 ClusterRoleBinding
-	crbName := GetUserClusterRoleBindingName(userID, permissionType)
-	if err := clientset.RbacV1().ClusterRoleBindings().Delete(ctx, crbName, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
-		logger.Warn("删除 ClusterRoleBinding 失败", "name", crbName, "error", err)
+	if clusterBinding {
+		crbName := GetUserClusterRoleBindingName(userID, permissionType)
+		if err := clientset.RbacV1().ClusterRoleBindings().Delete(ctx, crbName, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			logger.Warn("删除 ClusterRoleBinding 失败", "name", crbName, "error", err)
+		}
 	}
 
 	// 2. 删除 RoleBinding（每个命名空间）
@@ -664,9 +676,15 @@ func (s *RBACService) GetEffectiveServiceAccount(config *UserRBACConfig) string
 
 	switch config.PermissionType {
 	case "admin":
-		return rbac.SAClusterAdmin
+		if hasAllAccess {
+			return rbac.SAClusterAdmin
+		}
+		return GetUserServiceAccountName(config.UserID)
 	case "ops":
-		return rbac.SAOps
+		if hasAllAccess {
+			return rbac.SAOps
+		}
+		return GetUserServiceAccountName(config.UserID)
 	case "dev":
 		if hasAllAccess {
 			return rbac.SADev
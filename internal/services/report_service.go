@@ -0,0 +1,596 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/notify"
+	"github.com/clay-wangzhi/KubePolaris/internal/report"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+)
+
+const (
+	reportClusterTimeout  = 10 * time.Second // 单个集群 informer 同步等待时间
+	reportSendTimeout     = time.Minute
+	reportAbnormalLimit   = 20
+	reportMaxPeriodDays   = 90
+	reportDefaultPeriod   = 7
+	reportTopRisks        = 3
+	reportErrorMaxLength  = 1000
+	reportDefaultSubjectF = "[KubePolaris] %s（%s）"
+)
+
+// ReportClusterProvider 报表采集集群数据所需的能力（由 k8s.ClusterInformerManager 实现）
+type ReportClusterProvider interface {
+	InformerListerProvider
+	EnsureClient(ctx context.Context, cluster *models.Cluster, timeout time.Duration) (*K8sClient, error)
+}
+
+// ReportService 定时报表服务：按 Cron 生成报表并通过通知配置中的邮件渠道发送
+type ReportService struct {
+	db          *gorm.DB
+	overviewSvc *OverviewService
+	omSvc       *OMService
+	opLogSvc    *OperationLogService
+	notifier    *NotificationService
+	clusters    ReportClusterProvider
+
+	// sendMailFn 发送邮件，测试中可替换
+	sendMailFn func(ctx context.Context, cfg models.NotificationChannelConfig, m *notify.Mail) error
+}
+
+// NewReportService 创建定时报表服务
+func NewReportService(
+	db *gorm.DB,
+	overviewSvc *OverviewService,
+	omSvc *OMService,
+	opLogSvc *OperationLogService,
+	notifier *NotificationService,
+	clusters ReportClusterProvider,
+) *ReportService {
+	return &ReportService{
+		db:          db,
+		overviewSvc: overviewSvc,
+		omSvc:       omSvc,
+		opLogSvc:    opLogSvc,
+		notifier:    notifier,
+		clusters:    clusters,
+		sendMailFn:  notify.SendMail,
+	}
+}
+
+// ========== 报表计划管理 ==========
+
+// ReportScheduleRequest 创建/更新定时报表请求
+type ReportScheduleRequest struct {
+	Name            string            `json:"name" binding:"required"`
+	Description     string            `json:"description"`
+	Cron            string            `json:"cron" binding:"required"`
+	Timezone        string            `json:"timezone"`
+	Format          string            `json:"format"`
+	Sections        []string          `json:"sections"`
+	ClusterSelector map[string]string `json:"cluster_selector"`
+	PeriodDays      int               `json:"period_days"`
+	Channel         string            `json:"channel" binding:"required"`
+	Recipients      []string          `json:"recipients"`
+	Enabled         *bool             `json:"enabled"`
+}
+
+// applyTo 校验并写入报表计划模型
+func (b *ReportScheduleRequest) applyTo(r *models.ReportSchedule) error {
+	if _, err := report.ParseCron(b.Cron); err != nil {
+		return errcode.Wrap(err, errcode.InvalidCron)
+	}
+	if b.Timezone != "" {
+		if _, err := time.LoadLocation(b.Timezone); err != nil {
+			return errcode.New(errcode.InvalidTimezone, b.Timezone)
+		}
+	}
+	format := b.Format
+	if format == "" {
+		format = report.FormatHTML
+	}
+	if format != report.FormatHTML && format != report.FormatMarkdown {
+		return errcode.New(errcode.UnsupportedReportFormat, b.Format)
+	}
+	for _, section := range b.Sections {
+		if !containsString(report.Sections, section) {
+			return errcode.New(errcode.UnknownReportSection, section)
+		}
+	}
+	period := b.PeriodDays
+	if period == 0 {
+		period = reportDefaultPeriod
+	}
+	if period < 1 || period > reportMaxPeriodDays {
+		return errcode.New(errcode.InvalidReportPeriod, reportMaxPeriodDays)
+	}
+	for _, to := range b.Recipients {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return errcode.New(errcode.InvalidEmailAddress, to)
+		}
+	}
+
+	r.Name = b.Name
+	r.Description = b.Description
+	r.Cron = strings.TrimSpace(b.Cron)
+	r.Timezone = b.Timezone
+	r.Format = format
+	r.Sections = encodeJSONOrEmpty(b.Sections, len(b.Sections) == 0)
+	r.ClusterSelector = encodeJSONOrEmpty(b.ClusterSelector, len(b.ClusterSelector) == 0)
+	r.PeriodDays = period
+	r.Channel = b.Channel
+	r.Recipients = encodeJSONOrEmpty(b.Recipients, len(b.Recipients) == 0)
+	r.Enabled = b.Enabled == nil || *b.Enabled
+	return nil
+}
+
+// nextReportRun 计算报表计划在 from 之后的下一次执行时间，未启用或永不触发时返回 nil
+func nextReportRun(r *models.ReportSchedule, from time.Time) *time.Time {
+	if !r.Enabled {
+		return nil
+	}
+	schedule, err := report.ParseCron(r.Cron)
+	if err != nil {
+		return nil
+	}
+	loc := time.Local
+	if r.Timezone != "" {
+		if l, err := time.LoadLocation(r.Timezone); err == nil {
+			loc = l
+		}
+	}
+	next := schedule.Next(from.In(loc))
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
+
+// checkChannel 校验邮件渠道及收件人
+func (s *ReportService) checkChannel(r *models.ReportSchedule) error {
+	ch, err := s.notifier.EmailChannel(r.Channel)
+	if err != nil {
+		return err
+	}
+	if len(r.GetRecipientList()) == 0 && len(ch.Config.To) == 0 {
+		return errcode.New(errcode.ReportRecipientsRequired)
+	}
+	return nil
+}
+
+// CreateSchedule 创建定时报表
+func (s *ReportService) CreateSchedule(body *ReportScheduleRequest, operator string) (*models.ReportSchedule, error) {
+	schedule := &models.ReportSchedule{CreatedBy: operator}
+	if err := body.applyTo(schedule); err != nil {
+		return nil, err
+	}
+	if err := s.checkChannel(schedule); err != nil {
+		return nil, err
+	}
+	schedule.NextRunAt = nextReportRun(schedule, time.Now())
+	if err := s.db.Create(schedule).Error; err != nil {
+		return nil, fmt.Errorf("创建定时报表失败: %w", err)
+	}
+	logger.Info("创建定时报表: id=%d, name=%s, cron=%s", schedule.ID, schedule.Name, schedule.Cron)
+	return schedule, nil
+}
+
+// UpdateSchedule 更新定时报表
+func (s *ReportService) UpdateSchedule(id uint, body *ReportScheduleRequest) (*models.ReportSchedule, error) {
+	schedule, err := s.GetSchedule(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := body.applyTo(schedule); err != nil {
+		return nil, err
+	}
+	if err := s.checkChannel(schedule); err != nil {
+		return nil, err
+	}
+	schedule.NextRunAt = nextReportRun(schedule, time.Now())
+	if err := s.db.Save(schedule).Error; err != nil {
+		return nil, fmt.Errorf("更新定时报表失败: %w", err)
+	}
+	return schedule, nil
+}
+
+// DeleteSchedule 删除定时报表（保留发送记录）
+func (s *ReportService) DeleteSchedule(id uint) error {
+	result := s.db.Delete(&models.ReportSchedule{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除定时报表失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errcode.New(errcode.ReportScheduleNotFound)
+	}
+	return nil
+}
+
+// GetSchedule 获取定时报表详情
+func (s *ReportService) GetSchedule(id uint) (*models.ReportSchedule, error) {
+	var schedule models.ReportSchedule
+	if err := s.db.First(&schedule, id).Error; err != nil {
+		return nil, errcode.New(errcode.ReportScheduleNotFound)
+	}
+	return &schedule, nil
+}
+
+// ListSchedules 获取定时报表列表
+func (s *ReportService) ListSchedules() ([]models.ReportSchedule, error) {
+	var schedules []models.ReportSchedule
+	if err := s.db.Order("id ASC").Find(&schedules).Error; err != nil {
+		return nil, fmt.Errorf("获取定时报表失败: %w", err)
+	}
+	return schedules, nil
+}
+
+// ========== 报表生成 ==========
+
+// selectClusters 按报表的集群选择器筛选集群
+func (s *ReportService) selectClusters(r *models.ReportSchedule) ([]*models.Cluster, error) {
+	var all []*models.Cluster
+	if err := s.db.Order("id ASC").Find(&all).Error; err != nil {
+		return nil, fmt.Errorf("获取集群列表失败: %w", err)
+	}
+	selector := r.GetClusterSelector()
+	clusters := make([]*models.Cluster, 0, len(all))
+	for _, cluster := range all {
+		if models.MatchLabels(selector, cluster.GetLabels()) {
+			clusters = append(clusters, cluster)
+		}
+	}
+	return clusters, nil
+}
+
+// Generate 采集报表数据（单个章节失败时记录在 Errors 中，不中断整个报表）
+func (s *ReportService) Generate(ctx context.Context, r *models.ReportSchedule) (*report.Data, error) {
+	clusters, err := s.selectClusters(r)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if r.Timezone != "" {
+		if loc, err := time.LoadLocation(r.Timezone); err == nil {
+			now = now.In(loc)
+		}
+	}
+	data := &report.Data{
+		Title:       r.Name,
+		GeneratedAt: now,
+		PeriodStart: now.AddDate(0, 0, -r.PeriodDays),
+		PeriodEnd:   now,
+	}
+
+	sections := r.GetSectionList()
+	if len(sections) == 0 {
+		sections = report.Sections
+	}
+	has := func(section string) bool { return containsString(sections, section) }
+	needsInformer := has(report.SectionOverview) || has(report.SectionAbnormalWorkloads) || has(report.SectionHealth)
+
+	// 连接集群：informer 同步后总览统计才有数据，健康诊断需要客户端
+	ids := make([]uint, 0, len(clusters))
+	clients := make(map[uint]*K8sClient, len(clusters))
+	for _, cluster := range clusters {
+		ids = append(ids, cluster.ID)
+		data.Clusters = append(data.Clusters, cluster.Name)
+		if !needsInformer || s.clusters == nil {
+			continue
+		}
+		client, err := s.clusters.EnsureClient(ctx, cluster, reportClusterTimeout)
+		if err != nil {
+			data.Errors = append(data.Errors, fmt.Sprintf("集群 %s 连接失败: %v", cluster.Name, err))
+			continue
+		}
+		clients[cluster.ID] = client
+	}
+	scoped := ContextWithClusterFilter(ctx, ids)
+
+	if has(report.SectionOverview) && s.overviewSvc != nil {
+		if stats, err := s.overviewSvc.GetOverviewStats(scoped); err != nil {
+			data.Errors = append(data.Errors, "资源概况: "+err.Error())
+		} else {
+			data.Overview = &report.Overview{
+				ClustersTotal: stats.ClusterStats.Total, ClustersHealthy: stats.ClusterStats.Healthy,
+				ClustersUnhealthy: stats.ClusterStats.Unhealthy, ClustersUnknown: stats.ClusterStats.Unknown,
+				NodesTotal: stats.NodeStats.Total, NodesReady: stats.NodeStats.Ready, NodesNotReady: stats.NodeStats.NotReady,
+				PodsTotal: stats.PodStats.Total, PodsRunning: stats.PodStats.Running,
+				PodsPending: stats.PodStats.Pending, PodsFailed: stats.PodStats.Failed,
+			}
+		}
+	}
+
+	if has(report.SectionAbnormalWorkloads) && s.overviewSvc != nil {
+		data.HasAbnormalWorkloads = true
+		if workloads, err := s.overviewSvc.GetAbnormalWorkloads(scoped, reportAbnormalLimit); err != nil {
+			data.Errors = append(data.Errors, "异常工作负载: "+err.Error())
+		} else {
+			for _, w := range workloads {
+				data.AbnormalWorkloads = append(data.AbnormalWorkloads, report.Workload{
+					Cluster: w.ClusterName, Namespace: w.Namespace, Name: w.Name, Type: w.Type,
+					Reason: w.Reason, Duration: w.Duration, Severity: w.Severity,
+				})
+			}
+		}
+	}
+
+	if has(report.SectionHealth) && s.omSvc != nil {
+		data.HasHealth = true
+		for _, cluster := range clusters {
+			client := clients[cluster.ID]
+			if client == nil || client.GetClientset() == nil {
+				data.Health = append(data.Health, report.ClusterHealth{Cluster: cluster.Name, Error: "集群不可达"})
+				continue
+			}
+			diagnosis, err := s.omSvc.GetHealthDiagnosis(ctx, client.GetClientset(), cluster.ID)
+			if err != nil {
+				data.Health = append(data.Health, report.ClusterHealth{Cluster: cluster.Name, Error: err.Error()})
+				continue
+			}
+			data.Health = append(data.Health, summarizeHealth(cluster.Name, diagnosis))
+		}
+	}
+
+	if has(report.SectionAlerts) && s.overviewSvc != nil {
+		if stats, err := s.overviewSvc.GetGlobalAlertStats(scoped); err != nil {
+			data.Errors = append(data.Errors, "告警统计: "+err.Error())
+		} else {
+			alerts := &report.Alerts{Total: stats.Total, Firing: stats.Firing, Suppressed: stats.Suppressed, BySeverity: stats.BySeverity}
+			for _, c := range stats.ByCluster {
+				alerts.ByCluster = append(alerts.ByCluster, report.ClusterCount{Cluster: c.ClusterName, Total: c.Total, Firing: c.Firing})
+			}
+			data.Alerts = alerts
+		}
+	}
+
+	if has(report.SectionOperations) && s.opLogSvc != nil {
+		// 未配置集群选择器时统计全部操作（含平台级操作）
+		var opClusterIDs []uint
+		if len(r.GetClusterSelector()) > 0 {
+			opClusterIDs = ids
+		}
+		if stats, err := s.opLogSvc.GetClusterStats(opClusterIDs, data.PeriodStart, data.PeriodEnd); err != nil {
+			data.Errors = append(data.Errors, "操作统计: "+err.Error())
+		} else {
+			data.Operations = summarizeOperations(stats)
+		}
+	}
+	return data, nil
+}
+
+// summarizeHealth 汇总健康诊断：按严重程度计数，取前几条严重风险
+func summarizeHealth(cluster string, diagnosis *models.HealthDiagnosisResponse) report.ClusterHealth {
+	h := report.ClusterHealth{Cluster: cluster, Score: diagnosis.HealthScore, Status: diagnosis.Status}
+	risks := append([]models.RiskItem(nil), diagnosis.RiskItems...)
+	sort.SliceStable(risks, func(i, j int) bool {
+		return risks[i].Severity == "critical" && risks[j].Severity != "critical"
+	})
+	for _, item := range risks {
+		switch item.Severity {
+		case "critical":
+			h.Critical++
+		case "warning":
+			h.Warning++
+		default:
+			continue
+		}
+		if len(h.TopRisks) < reportTopRisks {
+			h.TopRisks = append(h.TopRisks, item.Title)
+		}
+	}
+	return h
+}
+
+// summarizeOperations 转换操作日志统计
+func summarizeOperations(stats *OperationLogStats) *report.Operations {
+	ops := &report.Operations{Total: stats.TotalCount, Success: stats.SuccessCount, Failed: stats.FailedCount}
+	for _, m := range stats.ModuleStats {
+		ops.TopModules = append(ops.TopModules, report.NameCount{Name: m.ModuleName, Count: m.Count})
+	}
+	for _, u := range stats.UserStats {
+		ops.TopUsers = append(ops.TopUsers, report.NameCount{Name: u.Username, Count: u.Count})
+	}
+	for _, f := range stats.RecentFailures {
+		ops.RecentFailures = append(ops.RecentFailures, report.Failure{
+			Time: f.CreatedAt, Username: f.Username, Action: f.ActionName, Path: f.Path,
+			Error: truncateRunes(f.ErrorMessage, 200),
+		})
+	}
+	return ops
+}
+
+// ReportPreview 报表预览
+type ReportPreview struct {
+	Subject string `json:"subject"`
+	Format  string `json:"format"`
+	Content string `json:"content"`
+}
+
+// render 生成并渲染报表
+func (s *ReportService) render(ctx context.Context, r *models.ReportSchedule) (*ReportPreview, error) {
+	data, err := s.Generate(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	content, err := report.Render(r.Format, data)
+	if err != nil {
+		return nil, err
+	}
+	return &ReportPreview{
+		Subject: fmt.Sprintf(reportDefaultSubjectF, r.Name, data.GeneratedAt.Format("2006-01-02")),
+		Format:  r.Format,
+		Content: content,
+	}, nil
+}
+
+// Preview 生成报表但不发送
+func (s *ReportService) Preview(ctx context.Context, id uint) (*ReportPreview, error) {
+	schedule, err := s.GetSchedule(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.render(ctx, schedule)
+}
+
+// ========== 发送 ==========
+
+// Run 生成并发送报表，记录发送历史；发送失败时返回的记录包含错误原因
+func (s *ReportService) Run(ctx context.Context, r *models.ReportSchedule, trigger, operator string) (*models.ReportDelivery, error) {
+	start := time.Now()
+	delivery := &models.ReportDelivery{
+		ScheduleID:   r.ID,
+		ScheduleName: r.Name,
+		Trigger:      trigger,
+		TriggeredBy:  operator,
+		Format:       r.Format,
+	}
+
+	err := s.send(ctx, r, delivery)
+	delivery.Duration = time.Since(start).Milliseconds()
+	if err != nil {
+		delivery.Status = models.ReportDeliveryFailed
+		delivery.Error = truncateRunes(err.Error(), reportErrorMaxLength)
+		logger.Warn("定时报表发送失败: schedule=%s, error=%v", r.Name, err)
+	} else {
+		now := time.Now()
+		delivery.Status = models.ReportDeliverySent
+		delivery.SentAt = &now
+	}
+	if dbErr := s.db.Create(delivery).Error; dbErr != nil {
+		logger.Error("保存报表发送记录失败: %v", dbErr)
+	}
+	if r.ID != 0 {
+		s.db.Model(&models.ReportSchedule{}).Where("id = ?", r.ID).Updates(map[string]interface{}{
+			"last_run_at": start, "last_status": delivery.Status,
+		})
+	}
+	return delivery, err
+}
+
+// send 渲染报表并通过邮件渠道发送
+func (s *ReportService) send(ctx context.Context, r *models.ReportSchedule, delivery *models.ReportDelivery) error {
+	ch, err := s.notifier.EmailChannel(r.Channel)
+	if err != nil {
+		return err
+	}
+	recipients := r.GetRecipientList()
+	if len(recipients) == 0 {
+		recipients = ch.Config.To
+	}
+	delivery.Recipients = encodeJSONOrEmpty(recipients, len(recipients) == 0)
+
+	preview, err := s.render(ctx, r)
+	if err != nil {
+		return err
+	}
+	delivery.Subject = truncateRunes(preview.Subject, 255)
+	delivery.Content = preview.Content
+
+	sendCtx, cancel := context.WithTimeout(ctx, reportSendTimeout)
+	defer cancel()
+	return s.sendMailFn(sendCtx, ch.Config, &notify.Mail{
+		To:      recipients,
+		Subject: preview.Subject,
+		Body:    preview.Content,
+		HTML:    r.Format != report.FormatMarkdown,
+	})
+}
+
+// RunNow 手动立即发送报表
+func (s *ReportService) RunNow(ctx context.Context, id uint, operator string) (*models.ReportDelivery, error) {
+	schedule, err := s.GetSchedule(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.Run(ctx, schedule, models.ReportTriggerManual, operator)
+}
+
+// RunDue 执行到期的定时报表，返回执行数量
+// 先以条件更新 next_run_at 抢占本次执行，多实例部署时同一报表只会发送一次
+func (s *ReportService) RunDue(now time.Time) int {
+	var due []models.ReportSchedule
+	if err := s.db.Where("enabled = ? AND next_run_at IS NOT NULL AND next_run_at <= ?", true, now).
+		Order("next_run_at ASC").Find(&due).Error; err != nil {
+		logger.Error("查询到期定时报表失败: %v", err)
+		return 0
+	}
+
+	ran := 0
+	for i := range due {
+		schedule := &due[i]
+		next := nextReportRun(schedule, now)
+		claim := s.db.Model(&models.ReportSchedule{}).
+			Where("id = ? AND next_run_at = ?", schedule.ID, schedule.NextRunAt).
+			Update("next_run_at", next)
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			continue
+		}
+		schedule.NextRunAt = next
+		_, _ = s.Run(context.Background(), schedule, models.ReportTriggerSchedule, "")
+		ran++
+	}
+	return ran
+}
+
+// StartScheduler 定时检查并发送到期报表
+func (s *ReportService) StartScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.RunDue(time.Now())
+	}
+}
+
+// ========== 发送记录 ==========
+
+// ReportDeliveryListRequest 报表发送记录查询条件
+type ReportDeliveryListRequest struct {
+	ScheduleID uint
+	Status     string
+	Page       int
+	PageSize   int
+}
+
+// ListDeliveries 获取报表发送记录（不含报表内容）
+func (s *ReportService) ListDeliveries(req *ReportDeliveryListRequest) ([]models.ReportDelivery, int64, error) {
+	query := s.db.Model(&models.ReportDelivery{})
+	if req.ScheduleID != 0 {
+		query = query.Where("schedule_id = ?", req.ScheduleID)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+	var items []models.ReportDelivery
+	err := query.Omit("content").Order("id DESC").
+		Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).Find(&items).Error
+	return items, total, err
+}
+
+// GetDelivery 获取报表发送记录详情（含报表内容）
+func (s *ReportService) GetDelivery(id uint) (*models.ReportDelivery, error) {
+	var delivery models.ReportDelivery
+	if err := s.db.First(&delivery, id).Error; err != nil {
+		return nil, errcode.New(errcode.ReportDeliveryNotFound)
+	}
+	return &delivery, nil
+}
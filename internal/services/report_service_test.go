@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/notify"
+	"github.com/clay-wangzhi/KubePolaris/internal/report"
+)
+
+func newReportTestService(t *testing.T) (*ReportService, *[]*notify.Mail, models.Cluster, models.Cluster) {
+	t.Helper()
+	db := newAuditChainTestDB(t)
+	if err := db.AutoMigrate(&models.SystemSetting{}, &models.Cluster{}, &models.ReportSchedule{}, &models.ReportDelivery{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	prod := models.Cluster{Name: "prod-1", APIServer: "https://prod-1:6443", Labels: `{"env":"prod"}`}
+	dev := models.Cluster{Name: "dev-1", APIServer: "https://dev-1:6443", Labels: `{"env":"dev"}`}
+	for _, c := range []*models.Cluster{&prod, &dev} {
+		if err := db.Create(c).Error; err != nil {
+			t.Fatalf("create cluster: %v", err)
+		}
+	}
+
+	notifier := NewNotificationService(db)
+	err := notifier.SaveConfig(&models.NotificationConfig{
+		Channels: []models.NotificationChannel{
+			{Name: "mail", Type: models.NotifyChannelEmail, Enabled: true, Config: models.NotificationChannelConfig{
+				SMTPHost: "smtp.example.com", SMTPPort: 25, From: "noreply@example.com", To: []string{"ops@example.com"},
+			}},
+			{Name: "ding", Type: models.NotifyChannelDingTalk, Enabled: true, Config: models.NotificationChannelConfig{URL: "https://oapi.dingtalk.com/robot/send"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("save notification config: %v", err)
+	}
+
+	var sent []*notify.Mail
+	svc := NewReportService(db, nil, nil, NewOperationLogService(db, nil, nil), notifier, nil)
+	svc.sendMailFn = func(_ context.Context, cfg models.NotificationChannelConfig, m *notify.Mail) error {
+		if cfg.SMTPHost != "smtp.example.com" {
+			return errors.New("unexpected smtp config")
+		}
+		sent = append(sent, m)
+		return nil
+	}
+	return svc, &sent, prod, dev
+}
+
+func TestReportScheduleValidation(t *testing.T) {
+	svc, _, _, _ := newReportTestService(t)
+
+	base := ReportScheduleRequest{Name: "weekly", Cron: "0 9 * * 1", Channel: "mail"}
+	bad := []func(r *ReportScheduleRequest){
+		func(r *ReportScheduleRequest) { r.Cron = "0 25 * * *" },
+		func(r *ReportScheduleRequest) { r.Timezone = "Mars/Olympus" },
+		func(r *ReportScheduleRequest) { r.Format = "pdf" },
+		func(r *ReportScheduleRequest) { r.Sections = []string{"costs"} },
+		func(r *ReportScheduleRequest) { r.Recipients = []string{"not-an-address"} },
+		func(r *ReportScheduleRequest) { r.Channel = "ding" },
+		func(r *ReportScheduleRequest) { r.Channel = "missing" },
+	}
+	for i, mutate := range bad {
+		req := base
+		mutate(&req)
+		if _, err := svc.CreateSchedule(&req, "admin"); err == nil {
+			t.Errorf("case %d should be rejected", i)
+		}
+	}
+
+	req := base
+	req.Timezone = "Asia/Shanghai"
+	schedule, err := svc.CreateSchedule(&req, "admin")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if schedule.Format != report.FormatHTML || schedule.PeriodDays != 7 || schedule.NextRunAt == nil {
+		t.Fatalf("unexpected defaults: %+v", schedule)
+	}
+	next := schedule.NextRunAt.In(time.FixedZone("CST", 8*3600))
+	if next.Weekday() != time.Monday || next.Hour() != 9 || next.Minute() != 0 {
+		t.Fatalf("next run should be Monday 09:00 in Asia/Shanghai, got %s", next)
+	}
+
+	disabled := false
+	req.Enabled = &disabled
+	schedule, err = svc.UpdateSchedule(schedule.ID, &req)
+	if err != nil || schedule.NextRunAt != nil {
+		t.Fatalf("disabled schedule should have no next run: %+v, %v", schedule, err)
+	}
+}
+
+func TestReportRunDueSendsOnce(t *testing.T) {
+	svc, sent, prod, dev := newReportTestService(t)
+	now := time.Now()
+	logs := []models.OperationLog{
+		{Username: "alice", Module: "workload", Action: "delete", ClusterID: &prod.ID, Success: false, ErrorMessage: "forbidden", CreatedAt: now.Add(-time.Hour)},
+		{Username: "alice", Module: "workload", Action: "scale", ClusterID: &prod.ID, Success: true, CreatedAt: now.Add(-2 * time.Hour)},
+		{Username: "bob", Module: "node", Action: "drain", ClusterID: &dev.ID, Success: true, CreatedAt: now.Add(-time.Hour)},
+		{Username: "carol", Module: "node", Action: "cordon", ClusterID: &prod.ID, Success: true, CreatedAt: now.AddDate(0, 0, -30)},
+	}
+	if err := svc.db.Create(&logs).Error; err != nil {
+		t.Fatalf("create logs: %v", err)
+	}
+
+	schedule, err := svc.CreateSchedule(&ReportScheduleRequest{
+		Name: "prod weekly", Cron: "0 9 * * 1", Channel: "mail", Format: report.FormatMarkdown,
+		Sections: []string{report.SectionOperations}, ClusterSelector: map[string]string{"env": "prod"},
+		Recipients: []string{"manager@example.com"},
+	}, "admin")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	svc.db.Model(schedule).Update("next_run_at", now.Add(-time.Minute))
+
+	if ran := svc.RunDue(now); ran != 1 {
+		t.Fatalf("expected one report to run, got %d", ran)
+	}
+	if ran := svc.RunDue(now); ran != 0 {
+		t.Fatalf("report must not run twice for the same slot, got %d", ran)
+	}
+
+	if len(*sent) != 1 {
+		t.Fatalf("expected one mail, got %d", len(*sent))
+	}
+	mail := (*sent)[0]
+	if mail.HTML || len(mail.To) != 1 || mail.To[0] != "manager@example.com" || !strings.Contains(mail.Subject, "prod weekly") {
+		t.Fatalf("unexpected mail: %+v", mail)
+	}
+	// 只统计 prod 集群且在统计区间内的操作
+	if !strings.Contains(mail.Body, "操作 2 次，成功 1，失败 1") || !strings.Contains(mail.Body, "forbidden") {
+		t.Fatalf("unexpected report body:\n%s", mail.Body)
+	}
+	if strings.Contains(mail.Body, "bob") || strings.Contains(mail.Body, "carol") {
+		t.Fatalf("report should be scoped by cluster selector and period:\n%s", mail.Body)
+	}
+
+	deliveries, total, err := svc.ListDeliveries(&ReportDeliveryListRequest{ScheduleID: schedule.ID})
+	if err != nil || total != 1 || deliveries[0].Status != models.ReportDeliverySent ||
+		deliveries[0].Trigger != models.ReportTriggerSchedule || deliveries[0].Content != "" {
+		t.Fatalf("unexpected deliveries: %+v, %v", deliveries, err)
+	}
+	stored, _ := svc.GetSchedule(schedule.ID)
+	if stored.LastStatus != models.ReportDeliverySent || stored.NextRunAt == nil || !stored.NextRunAt.After(now) {
+		t.Fatalf("schedule should be advanced: %+v", stored)
+	}
+}
+
+func TestReportRunRecordsFailure(t *testing.T) {
+	svc, _, _, _ := newReportTestService(t)
+	svc.sendMailFn = func(context.Context, models.NotificationChannelConfig, *notify.Mail) error {
+		return errors.New("535 authentication failed")
+	}
+	schedule, err := svc.CreateSchedule(&ReportScheduleRequest{
+		Name: "daily", Cron: "@daily", Channel: "mail", Sections: []string{report.SectionOperations},
+	}, "admin")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	delivery, err := svc.RunNow(context.Background(), schedule.ID, "admin")
+	if err == nil || delivery.Status != models.ReportDeliveryFailed || !strings.Contains(delivery.Error, "535") {
+		t.Fatalf("failure should be recorded: %+v, %v", delivery, err)
+	}
+	stored, _ := svc.GetDelivery(delivery.ID)
+	if stored.Trigger != models.ReportTriggerManual || stored.TriggeredBy != "admin" || stored.Recipients != `["ops@example.com"]` ||
+		!strings.Contains(stored.Content, "<h1>daily</h1>") {
+		t.Fatalf("unexpected stored delivery: %+v", stored)
+	}
+}
@@ -0,0 +1,410 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/sshvault"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"golang.org/x/crypto/ssh"
+	"gorm.io/gorm"
+)
+
+// SSHVaultService 节点 SSH 凭据库：凭据配置、按会话签发证书、主机密钥 TOFU 记录
+type SSHVaultService struct {
+	db       *gorm.DB
+	cipher   *sshvault.Cipher
+	settings *SSHSettingService
+	certTTL  time.Duration
+	now      func() time.Time
+}
+
+// NewSSHVaultService 创建节点 SSH 凭据库服务
+func NewSSHVaultService(db *gorm.DB, cipher *sshvault.Cipher, certTTL time.Duration) *SSHVaultService {
+	return &SSHVaultService{
+		db:       db,
+		cipher:   cipher,
+		settings: NewSSHSettingService(db),
+		certTTL:  certTTL,
+		now:      time.Now,
+	}
+}
+
+// ========== 凭据配置 ==========
+
+// SSHProfileRequest 创建/更新凭据配置请求，密码与私钥留空表示保持不变
+type SSHProfileRequest struct {
+	Name         string            `json:"name" binding:"required"`
+	Description  string            `json:"description"`
+	ClusterID    uint              `json:"cluster_id"`
+	NodeSelector map[string]string `json:"node_selector"`
+	Priority     int               `json:"priority"`
+	Username     string            `json:"username" binding:"required"`
+	Port         int               `json:"port"`
+	AuthType     string            `json:"auth_type" binding:"required"`
+	Password     string            `json:"password"`
+	PrivateKey   string            `json:"private_key"` // key 方式为登录私钥，certificate 方式为 CA 私钥
+	CertTTL      int               `json:"cert_ttl_minutes"`
+}
+
+// applyTo 校验并写入凭据配置，敏感字段加密存储
+func (s *SSHVaultService) applyTo(body *SSHProfileRequest, p *models.SSHCredentialProfile) error {
+	if body.Port < 0 || body.Port > 65535 {
+		return errcode.New(errcode.InvalidSSHPort)
+	}
+	if body.CertTTL < 0 {
+		return errcode.New(errcode.NegativeSSHCertTTL)
+	}
+
+	switch body.AuthType {
+	case models.SSHAuthTypePassword:
+		if body.Password == "" && (p.PasswordEnc == "" || p.AuthType != body.AuthType) {
+			return errcode.New(errcode.SSHPasswordRequired)
+		}
+		if body.Password != "" {
+			enc, err := s.cipher.Encrypt(body.Password)
+			if err != nil {
+				return err
+			}
+			p.PasswordEnc = enc
+		}
+		p.SecretKeyEnc = ""
+		p.PublicKey = ""
+	case models.SSHAuthTypeKey, models.SSHAuthTypeCertificate:
+		if body.PrivateKey == "" && (p.SecretKeyEnc == "" || p.AuthType != body.AuthType) {
+			return errcode.New(errcode.SSHPrivateKeyRequired)
+		}
+		if body.PrivateKey != "" {
+			signer, err := ssh.ParsePrivateKey([]byte(body.PrivateKey))
+			if err != nil {
+				return errcode.Wrap(err, errcode.InvalidSSHPrivateKey)
+			}
+			enc, err := s.cipher.Encrypt(body.PrivateKey)
+			if err != nil {
+				return err
+			}
+			p.SecretKeyEnc = enc
+			p.PublicKey = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+		}
+		p.PasswordEnc = ""
+	default:
+		return errcode.New(errcode.InvalidSSHAuthType)
+	}
+
+	p.Name = body.Name
+	p.Description = body.Description
+	p.ClusterID = body.ClusterID
+	p.NodeSelector = encodeJSONOrEmpty(body.NodeSelector, len(body.NodeSelector) == 0)
+	p.Priority = body.Priority
+	p.Username = body.Username
+	p.Port = body.Port
+	if p.Port == 0 {
+		p.Port = 22
+	}
+	p.AuthType = body.AuthType
+	p.CertTTL = body.CertTTL
+	return nil
+}
+
+// CreateProfile 创建凭据配置
+func (s *SSHVaultService) CreateProfile(body *SSHProfileRequest, userID uint) (*models.SSHCredentialProfile, error) {
+	profile := &models.SSHCredentialProfile{CreatedBy: userID}
+	if err := s.applyTo(body, profile); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(profile).Error; err != nil {
+		return nil, fmt.Errorf("创建 SSH 凭据配置失败: %w", err)
+	}
+	logger.Info("创建 SSH 凭据配置: id=%d, name=%s, auth_type=%s", profile.ID, profile.Name, profile.AuthType)
+	return maskProfile(profile), nil
+}
+
+// UpdateProfile 更新凭据配置
+func (s *SSHVaultService) UpdateProfile(id uint, body *SSHProfileRequest) (*models.SSHCredentialProfile, error) {
+	var profile models.SSHCredentialProfile
+	if err := s.db.First(&profile, id).Error; err != nil {
+		return nil, errcode.New(errcode.SSHProfileNotFound)
+	}
+	if err := s.applyTo(body, &profile); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(&profile).Error; err != nil {
+		return nil, fmt.Errorf("更新 SSH 凭据配置失败: %w", err)
+	}
+	return maskProfile(&profile), nil
+}
+
+// DeleteProfile 删除凭据配置
+func (s *SSHVaultService) DeleteProfile(id uint) error {
+	result := s.db.Delete(&models.SSHCredentialProfile{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除 SSH 凭据配置失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errcode.New(errcode.SSHProfileNotFound)
+	}
+	return nil
+}
+
+// ListProfiles 获取凭据配置列表（不含敏感字段）
+func (s *SSHVaultService) ListProfiles() ([]models.SSHCredentialProfile, error) {
+	var profiles []models.SSHCredentialProfile
+	if err := s.db.Order("priority DESC, id ASC").Find(&profiles).Error; err != nil {
+		return nil, fmt.Errorf("获取 SSH 凭据配置失败: %w", err)
+	}
+	for i := range profiles {
+		maskProfile(&profiles[i])
+	}
+	return profiles, nil
+}
+
+// maskProfile 标记凭据是否已设置（敏感字段本身不序列化）
+func maskProfile(p *models.SSHCredentialProfile) *models.SSHCredentialProfile {
+	p.HasPassword = p.PasswordEnc != ""
+	p.HasSecretKey = p.SecretKeyEnc != ""
+	return p
+}
+
+// MatchSSHProfile 选择适用于节点的凭据配置：优先级高者优先，同优先级时指定集群的配置优先于全局配置
+func MatchSSHProfile(profiles []models.SSHCredentialProfile, clusterID uint, nodeLabels map[string]string) *models.SSHCredentialProfile {
+	var matched []*models.SSHCredentialProfile
+	for i := range profiles {
+		p := &profiles[i]
+		if p.ClusterID != 0 && p.ClusterID != clusterID {
+			continue
+		}
+		if !models.MatchLabels(p.GetNodeSelector(), nodeLabels) {
+			continue
+		}
+		matched = append(matched, p)
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if (a.ClusterID != 0) != (b.ClusterID != 0) {
+			return a.ClusterID != 0
+		}
+		return a.ID < b.ID
+	})
+	return matched[0]
+}
+
+// ========== 会话凭据 ==========
+
+// SSHCredential 单次会话使用的凭据，只存在于服务端
+type SSHCredential struct {
+	Username string
+	Port     int
+	Auth     []ssh.AuthMethod
+	Source   string // 凭据来源，用于审计与提示
+}
+
+// ResolveCredential 为节点解析会话凭据：先匹配凭据配置，未匹配时回退到全局 SSH 配置。
+// certificate 方式为本次会话签发短期证书，keyID 写入证书便于在节点日志中追溯。
+func (s *SSHVaultService) ResolveCredential(clusterID uint, nodeLabels map[string]string, keyID string) (*SSHCredential, error) {
+	var profiles []models.SSHCredentialProfile
+	if err := s.db.Where("cluster_id IN ?", []uint{0, clusterID}).Find(&profiles).Error; err != nil {
+		return nil, fmt.Errorf("获取 SSH 凭据配置失败: %w", err)
+	}
+	if p := MatchSSHProfile(profiles, clusterID, nodeLabels); p != nil {
+		return s.profileCredential(p, keyID)
+	}
+
+	global, err := s.settings.GetSSHConfig()
+	if err != nil {
+		return nil, fmt.Errorf("获取全局 SSH 配置失败: %w", err)
+	}
+	if !global.Enabled {
+		return nil, errcode.New(errcode.SSHProfileUnavailable)
+	}
+	auth, err := SSHAuthMethods(global.AuthType, global.Password, global.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &SSHCredential{Username: global.Username, Port: global.Port, Auth: auth, Source: "global"}, nil
+}
+
+// profileCredential 由凭据配置生成会话凭据
+func (s *SSHVaultService) profileCredential(p *models.SSHCredentialProfile, keyID string) (*SSHCredential, error) {
+	cred := &SSHCredential{Username: p.Username, Port: p.Port, Source: "profile:" + p.Name}
+
+	if p.AuthType != models.SSHAuthTypeCertificate {
+		password, err := s.cipher.Decrypt(p.PasswordEnc)
+		if err != nil {
+			return nil, err
+		}
+		privateKey, err := s.cipher.Decrypt(p.SecretKeyEnc)
+		if err != nil {
+			return nil, err
+		}
+		cred.Auth, err = SSHAuthMethods(p.AuthType, password, privateKey)
+		if err != nil {
+			return nil, err
+		}
+		return cred, nil
+	}
+
+	caKey, err := s.cipher.Decrypt(p.SecretKeyEnc)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := ssh.ParsePrivateKey([]byte(caKey))
+	if err != nil {
+		return nil, errcode.Wrap(err, errcode.InvalidSSHCAKey)
+	}
+	ttl := s.certTTL
+	if p.CertTTL > 0 {
+		ttl = time.Duration(p.CertTTL) * time.Minute
+	}
+	signer, cert, err := sshvault.IssueCertificate(ca, keyID, []string{p.Username}, ttl, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("签发 SSH 证书失败: %w", err)
+	}
+	logger.Info("签发 SSH 会话证书", "profile", p.Name, "keyID", keyID, "serial", cert.Serial, "validBefore", time.Unix(int64(cert.ValidBefore), 0))
+	cred.Auth = []ssh.AuthMethod{ssh.PublicKeys(signer)}
+	return cred, nil
+}
+
+// SSHAuthMethods 由密码或私钥构建认证方式
+func SSHAuthMethods(authType, password, privateKey string) ([]ssh.AuthMethod, error) {
+	switch authType {
+	case models.SSHAuthTypePassword:
+		if password == "" {
+			return nil, errcode.New(errcode.SSHPasswordRequired)
+		}
+		return []ssh.AuthMethod{ssh.Password(password)}, nil
+	case models.SSHAuthTypeKey:
+		if privateKey == "" {
+			return nil, errcode.New(errcode.SSHPrivateKeyRequired)
+		}
+		signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+		if err != nil {
+			return nil, errcode.Wrap(err, errcode.InvalidSSHPrivateKey)
+		}
+		return []ssh.AuthMethod{ssh.PublicKeys(signer)}, nil
+	default:
+		return nil, errcode.New(errcode.UnsupportedSSHAuthType, authType)
+	}
+}
+
+// ========== 主机密钥（实现 sshvault.HostKeyStore）==========
+
+// LookupHostKey 查询已信任的主机密钥
+func (s *SSHVaultService) LookupHostKey(clusterID uint, host string) (*sshvault.KnownHost, error) {
+	var record models.SSHHostKey
+	err := s.db.Where("cluster_id = ? AND host = ?", clusterID, host).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sshvault.KnownHost{KeyType: record.KeyType, Fingerprint: record.Fingerprint}, nil
+}
+
+// TrustHostKey 首次连接记录主机密钥，已记录时刷新最近使用时间
+func (s *SSHVaultService) TrustHostKey(clusterID uint, host, node string, key ssh.PublicKey) error {
+	now := s.now()
+	result := s.db.Model(&models.SSHHostKey{}).
+		Where("cluster_id = ? AND host = ? AND fingerprint = ?", clusterID, host, ssh.FingerprintSHA256(key)).
+		Updates(map[string]interface{}{"last_seen_at": now, "node": node})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	logger.Info("记录 SSH 主机密钥（首次连接）", "clusterID", clusterID, "host", host, "node", node, "fingerprint", ssh.FingerprintSHA256(key))
+	return s.db.Create(&models.SSHHostKey{
+		ClusterID:   clusterID,
+		Host:        host,
+		Node:        node,
+		KeyType:     key.Type(),
+		Fingerprint: ssh.FingerprintSHA256(key),
+		PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))),
+		FirstSeenAt: now,
+		LastSeenAt:  now,
+	}).Error
+}
+
+// RecordHostKeyMismatch 记录不一致的主机密钥并告警
+func (s *SSHVaultService) RecordHostKeyMismatch(clusterID uint, host, node string, key ssh.PublicKey) error {
+	now := s.now()
+	logger.Error("SSH 主机密钥不一致，可能存在中间人攻击", "clusterID", clusterID, "host", host, "node", node, "fingerprint", ssh.FingerprintSHA256(key))
+	return s.db.Model(&models.SSHHostKey{}).
+		Where("cluster_id = ? AND host = ?", clusterID, host).
+		Updates(map[string]interface{}{
+			"mismatch_key_type":    key.Type(),
+			"mismatch_fingerprint": ssh.FingerprintSHA256(key),
+			"mismatch_public_key":  strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))),
+			"mismatch_count":       gorm.Expr("mismatch_count + 1"),
+			"last_mismatch_at":     &now,
+		}).Error
+}
+
+// ListHostKeys 获取主机密钥列表，mismatchOnly 时只返回存在未确认告警的记录
+func (s *SSHVaultService) ListHostKeys(clusterID uint, mismatchOnly bool) ([]models.SSHHostKey, error) {
+	query := s.db.Model(&models.SSHHostKey{})
+	if clusterID > 0 {
+		query = query.Where("cluster_id = ?", clusterID)
+	}
+	if mismatchOnly {
+		query = query.Where("mismatch_fingerprint <> ''")
+	}
+	var keys []models.SSHHostKey
+	if err := query.Order("last_mismatch_at DESC, id ASC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("获取主机密钥失败: %w", err)
+	}
+	return keys, nil
+}
+
+// AcceptHostKey 管理员确认节点密钥已合法变更（如节点重装），以最近一次不一致的密钥替换记录
+func (s *SSHVaultService) AcceptHostKey(id uint) (*models.SSHHostKey, error) {
+	var record models.SSHHostKey
+	if err := s.db.First(&record, id).Error; err != nil {
+		return nil, errcode.New(errcode.HostKeyNotFound)
+	}
+	if record.MismatchFingerprint == "" {
+		return nil, errcode.New(errcode.HostKeyNotPending)
+	}
+	now := s.now()
+	record.KeyType = record.MismatchKeyType
+	record.Fingerprint = record.MismatchFingerprint
+	record.PublicKey = record.MismatchPublicKey
+	record.FirstSeenAt = now
+	record.LastSeenAt = now
+	record.MismatchKeyType = ""
+	record.MismatchFingerprint = ""
+	record.MismatchPublicKey = ""
+	record.MismatchCount = 0
+	record.LastMismatchAt = nil
+	if err := s.db.Save(&record).Error; err != nil {
+		return nil, fmt.Errorf("更新主机密钥失败: %w", err)
+	}
+	logger.Info("确认 SSH 主机新密钥", "clusterID", record.ClusterID, "host", record.Host, "fingerprint", record.Fingerprint)
+	return &record, nil
+}
+
+// DeleteHostKey 删除主机密钥记录，下次连接时重新首次信任
+func (s *SSHVaultService) DeleteHostKey(id uint) error {
+	result := s.db.Delete(&models.SSHHostKey{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除主机密钥失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errcode.New(errcode.HostKeyNotFound)
+	}
+	return nil
+}
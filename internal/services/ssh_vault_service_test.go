@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+func TestMatchSSHProfile(t *testing.T) {
+	profiles := []models.SSHCredentialProfile{
+		{ID: 1, Name: "global", Username: "root"},
+		{ID: 2, Name: "cluster", ClusterID: 7, Username: "ops"},
+		{ID: 3, Name: "gpu", NodeSelector: `{"pool":"gpu"}`, Priority: 10, Username: "gpu"},
+		{ID: 4, Name: "other-cluster", ClusterID: 8, Priority: 100, Username: "x"},
+	}
+
+	cases := []struct {
+		clusterID uint
+		labels    map[string]string
+		want      string
+	}{
+		{7, map[string]string{"pool": "gpu"}, "gpu"},
+		{7, map[string]string{"pool": "cpu"}, "cluster"},
+		{9, nil, "global"},
+	}
+	for _, tc := range cases {
+		got := MatchSSHProfile(profiles, tc.clusterID, tc.labels)
+		if got == nil || got.Name != tc.want {
+			t.Fatalf("cluster=%d labels=%v: got %v, want %s", tc.clusterID, tc.labels, got, tc.want)
+		}
+	}
+
+	if got := MatchSSHProfile(profiles[3:], 7, nil); got != nil {
+		t.Fatalf("profile of another cluster should not match, got %s", got.Name)
+	}
+}
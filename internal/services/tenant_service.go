@@ -0,0 +1,629 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// ========== 租户可见范围 ==========
+
+// TenantScope 租户成员的可见范围
+type TenantScope struct {
+	TenantID   uint   `json:"tenant_id"`
+	TenantName string `json:"tenant_name"`
+	Role       string `json:"role"`
+	Disabled   bool   `json:"disabled"`
+
+	namespaces map[uint]map[string]bool
+}
+
+// NewTenantScope 根据租户命名空间构造可见范围
+func NewTenantScope(member *models.TenantMember, namespaces []models.TenantNamespace) *TenantScope {
+	scope := &TenantScope{
+		TenantID:   member.TenantID,
+		Role:       member.Role,
+		namespaces: make(map[uint]map[string]bool),
+	}
+	if member.Tenant != nil {
+		scope.TenantName = member.Tenant.Name
+		scope.Disabled = member.Tenant.Status == models.TenantStatusDisabled
+	}
+	for _, ns := range namespaces {
+		if scope.namespaces[ns.ClusterID] == nil {
+			scope.namespaces[ns.ClusterID] = make(map[string]bool)
+		}
+		scope.namespaces[ns.ClusterID][ns.Namespace] = true
+	}
+	return scope
+}
+
+// IsAdmin 是否为租户管理员
+func (s *TenantScope) IsAdmin() bool {
+	return s.Role == models.TenantRoleAdmin
+}
+
+// Allows 租户是否拥有指定集群的命名空间
+func (s *TenantScope) Allows(clusterID uint, namespace string) bool {
+	return s.OwnsCluster(clusterID) || s.namespaces[clusterID][namespace]
+}
+
+// OwnsCluster 租户是否独占整个集群（命名空间为 *）
+func (s *TenantScope) OwnsCluster(clusterID uint) bool {
+	return s.namespaces[clusterID][models.TenantNamespaceAll]
+}
+
+// ClusterIDs 租户拥有命名空间的集群列表
+func (s *TenantScope) ClusterIDs() []uint {
+	ids := make([]uint, 0, len(s.namespaces))
+	for id := range s.namespaces {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Namespaces 租户在指定集群拥有的命名空间
+func (s *TenantScope) Namespaces(clusterID uint) []string {
+	namespaces := make([]string, 0, len(s.namespaces[clusterID]))
+	for ns := range s.namespaces[clusterID] {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// LoadTenantScope 加载用户所属租户的可见范围，用户不属于任何租户时返回 nil
+func LoadTenantScope(db *gorm.DB, userID uint) (*TenantScope, error) {
+	var member models.TenantMember
+	err := db.Preload("Tenant").Where("user_id = ?", userID).Limit(1).Find(&member).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询租户成员失败: %w", err)
+	}
+	if member.ID == 0 {
+		return nil, nil
+	}
+
+	var namespaces []models.TenantNamespace
+	if err := db.Where("tenant_id = ?", member.TenantID).Find(&namespaces).Error; err != nil {
+		return nil, fmt.Errorf("查询租户命名空间失败: %w", err)
+	}
+	return NewTenantScope(&member, namespaces), nil
+}
+
+// ScopePermissionToTenant 将集群权限收敛到租户命名空间内
+// 租户成员不具备集群级权限：admin/ops 降级为命名空间内读写的 dev
+// 租户独占的集群不做收敛
+func ScopePermissionToTenant(permission *models.ClusterPermission, scope *TenantScope, clusterID uint) (*models.ClusterPermission, bool) {
+	if scope.OwnsCluster(clusterID) {
+		return permission, true
+	}
+
+	var allowed []string
+	for _, ns := range scope.Namespaces(clusterID) {
+		if HasNamespaceAccess(permission, ns) {
+			allowed = append(allowed, ns)
+		}
+	}
+	if len(allowed) == 0 {
+		return nil, false
+	}
+
+	scoped := *permission
+	_ = scoped.SetNamespaceList(allowed)
+	if scoped.PermissionType == models.PermissionTypeAdmin || scoped.PermissionType == models.PermissionTypeOps {
+		scoped.PermissionType = models.PermissionTypeDev
+	}
+	return &scoped, true
+}
+
+// tenantScopeCtxKey 用于在 context 中传递租户可见范围
+type tenantScopeCtxKey struct{}
+
+// ContextWithTenantScope 将租户可见范围注入 context
+func ContextWithTenantScope(ctx context.Context, scope *TenantScope) context.Context {
+	return context.WithValue(ctx, tenantScopeCtxKey{}, scope)
+}
+
+// TenantScopeFromContext 从 context 获取租户可见范围
+func TenantScopeFromContext(ctx context.Context) *TenantScope {
+	scope, _ := ctx.Value(tenantScopeCtxKey{}).(*TenantScope)
+	return scope
+}
+
+// ========== 租户管理 ==========
+
+// TenantService 租户服务
+type TenantService struct {
+	db            *gorm.DB
+	permissionSvc *PermissionService
+	rbacSvc       *RBACService
+}
+
+// NewTenantService 创建租户服务
+func NewTenantService(db *gorm.DB, permissionSvc *PermissionService, rbacSvc *RBACService) *TenantService {
+	return &TenantService{db: db, permissionSvc: permissionSvc, rbacSvc: rbacSvc}
+}
+
+// TenantRequest 创建/更新租户请求
+type TenantRequest struct {
+	Name        string `json:"name" binding:"required"`
+	DisplayName string `json:"display_name"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+}
+
+// TenantListItem 租户列表项
+type TenantListItem struct {
+	models.Tenant
+	NamespaceCount int64 `json:"namespace_count"`
+	MemberCount    int64 `json:"member_count"`
+}
+
+// ListTenants 获取租户列表
+func (s *TenantService) ListTenants() ([]TenantListItem, error) {
+	var tenants []models.Tenant
+	if err := s.db.Order("name ASC").Find(&tenants).Error; err != nil {
+		return nil, fmt.Errorf("获取租户列表失败: %w", err)
+	}
+
+	items := make([]TenantListItem, 0, len(tenants))
+	for _, tenant := range tenants {
+		item := TenantListItem{Tenant: tenant}
+		s.db.Model(&models.TenantNamespace{}).Where("tenant_id = ?", tenant.ID).Count(&item.NamespaceCount)
+		s.db.Model(&models.TenantMember{}).Where("tenant_id = ?", tenant.ID).Count(&item.MemberCount)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// GetTenant 获取租户详情（包含命名空间与成员）
+func (s *TenantService) GetTenant(id uint) (*models.Tenant, error) {
+	var tenant models.Tenant
+	err := s.db.Preload("Namespaces.Cluster", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "name")
+	}).Preload("Members.User").First(&tenant, id).Error
+	if err != nil {
+		return nil, errcode.New(errcode.TenantNotFound)
+	}
+	return &tenant, nil
+}
+
+// CreateTenant 创建租户
+func (s *TenantService) CreateTenant(req *TenantRequest) (*models.Tenant, error) {
+	tenant := &models.Tenant{
+		Name:        req.Name,
+		DisplayName: req.DisplayName,
+		Description: req.Description,
+		Status:      models.TenantStatusActive,
+	}
+	if req.Status != "" {
+		if err := validateTenantStatus(req.Status); err != nil {
+			return nil, err
+		}
+		tenant.Status = req.Status
+	}
+	if err := s.db.Create(tenant).Error; err != nil {
+		return nil, fmt.Errorf("创建租户失败: %w", err)
+	}
+	logger.Info("创建租户: id=%d, name=%s", tenant.ID, tenant.Name)
+	return tenant, nil
+}
+
+// UpdateTenant 更新租户
+func (s *TenantService) UpdateTenant(id uint, req *TenantRequest) (*models.Tenant, error) {
+	var tenant models.Tenant
+	if err := s.db.First(&tenant, id).Error; err != nil {
+		return nil, errcode.New(errcode.TenantNotFound)
+	}
+	if req.Status != "" {
+		if err := validateTenantStatus(req.Status); err != nil {
+			return nil, err
+		}
+		tenant.Status = req.Status
+	}
+	tenant.Name = req.Name
+	tenant.DisplayName = req.DisplayName
+	tenant.Description = req.Description
+	if err := s.db.Save(&tenant).Error; err != nil {
+		return nil, fmt.Errorf("更新租户失败: %w", err)
+	}
+	return &tenant, nil
+}
+
+// DeleteTenant 删除租户，同时移除命名空间归属、成员及租户授予的权限
+func (s *TenantService) DeleteTenant(id uint) error {
+	var grants []models.ClusterPermission
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var tenant models.Tenant
+		if err := tx.First(&tenant, id).Error; err != nil {
+			return errcode.New(errcode.TenantNotFound)
+		}
+		if err := tx.Where("tenant_id = ?", id).Find(&grants).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tenant_id = ?", id).Delete(&models.ClusterPermission{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tenant_id = ?", id).Delete(&models.TenantNamespace{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tenant_id = ?", id).Delete(&models.TenantMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&tenant).Error
+	})
+	if err != nil {
+		return err
+	}
+	for i := range grants {
+		go s.cleanupGrantRBAC(&grants[i])
+	}
+	logger.Info("删除租户: id=%d, 回收授权 %d 条", id, len(grants))
+	return nil
+}
+
+// validateTenantStatus 校验租户状态
+func validateTenantStatus(status string) error {
+	if status != models.TenantStatusActive && status != models.TenantStatusDisabled {
+		return errcode.New(errcode.InvalidTenantStatus)
+	}
+	return nil
+}
+
+// AddNamespace 为租户分配命名空间
+func (s *TenantService) AddNamespace(tenantID, clusterID uint, namespace string) (*models.TenantNamespace, error) {
+	if namespace == "" {
+		return nil, errcode.New(errcode.TenantNamespaceRequired)
+	}
+	if err := s.db.First(&models.Tenant{}, tenantID).Error; err != nil {
+		return nil, errcode.New(errcode.TenantNotFound)
+	}
+	if err := s.db.Select("id").First(&models.Cluster{}, clusterID).Error; err != nil {
+		return nil, errcode.New(errcode.ClusterNotFound)
+	}
+
+	var existing models.TenantNamespace
+	s.db.Where("cluster_id = ? AND namespace = ?", clusterID, namespace).Limit(1).Find(&existing)
+	if existing.ID != 0 {
+		if existing.TenantID == tenantID {
+			return &existing, nil
+		}
+		return nil, errcode.New(errcode.TenantNamespaceTaken)
+	}
+
+	// 独占整个集群时不能与其他租户的命名空间共存
+	var conflicts int64
+	query := s.db.Model(&models.TenantNamespace{}).Where("cluster_id = ? AND tenant_id <> ?", clusterID, tenantID)
+	if namespace != models.TenantNamespaceAll {
+		query = query.Where("namespace = ?", models.TenantNamespaceAll)
+	}
+	query.Count(&conflicts)
+	if conflicts > 0 {
+		return nil, errcode.New(errcode.TenantClusterTaken)
+	}
+
+	tenantNs := &models.TenantNamespace{TenantID: tenantID, ClusterID: clusterID, Namespace: namespace}
+	if err := s.db.Create(tenantNs).Error; err != nil {
+		return nil, fmt.Errorf("分配命名空间失败: %w", err)
+	}
+	return tenantNs, nil
+}
+
+// RemoveNamespace 移除租户命名空间
+func (s *TenantService) RemoveNamespace(tenantID, namespaceID uint) error {
+	result := s.db.Where("id = ? AND tenant_id = ?", namespaceID, tenantID).Delete(&models.TenantNamespace{})
+	if result.Error != nil {
+		return fmt.Errorf("移除命名空间失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errcode.New(errcode.TenantNamespaceNotFound)
+	}
+	return nil
+}
+
+// ========== 租户成员 ==========
+
+// ListMembers 获取租户成员
+func (s *TenantService) ListMembers(tenantID uint) ([]models.TenantMember, error) {
+	var members []models.TenantMember
+	if err := s.db.Preload("User").Where("tenant_id = ?", tenantID).Order("id ASC").Find(&members).Error; err != nil {
+		return nil, fmt.Errorf("获取租户成员失败: %w", err)
+	}
+	return members, nil
+}
+
+// AddMember 添加租户成员（已是本租户成员时更新角色）
+func (s *TenantService) AddMember(tenantID, userID uint, role string) (*models.TenantMember, error) {
+	if role == "" {
+		role = models.TenantRoleMember
+	}
+	if role != models.TenantRoleAdmin && role != models.TenantRoleMember {
+		return nil, errcode.New(errcode.InvalidTenantRole)
+	}
+	if err := s.db.First(&models.Tenant{}, tenantID).Error; err != nil {
+		return nil, errcode.New(errcode.TenantNotFound)
+	}
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, errcode.New(errcode.UserNotFound)
+	}
+	if user.Username == "admin" {
+		return nil, errcode.New(errcode.TenantBuiltinAdmin)
+	}
+
+	var member models.TenantMember
+	s.db.Where("user_id = ?", userID).Limit(1).Find(&member)
+	if member.ID != 0 {
+		if member.TenantID != tenantID {
+			return nil, errcode.New(errcode.TenantMemberTaken)
+		}
+		member.Role = role
+		if err := s.db.Save(&member).Error; err != nil {
+			return nil, fmt.Errorf("更新成员角色失败: %w", err)
+		}
+		return &member, nil
+	}
+
+	member = models.TenantMember{TenantID: tenantID, UserID: userID, Role: role}
+	if err := s.db.Create(&member).Error; err != nil {
+		return nil, fmt.Errorf("添加租户成员失败: %w", err)
+	}
+	return &member, nil
+}
+
+// RemoveMember 移除租户成员，并回收租户授予该成员的权限
+func (s *TenantService) RemoveMember(tenantID, userID uint) error {
+	var grants []models.ClusterPermission
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("tenant_id = ? AND user_id = ?", tenantID, userID).Delete(&models.TenantMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errcode.New(errcode.TenantMemberNotFound)
+		}
+		if err := tx.Where("tenant_id = ? AND user_id = ?", tenantID, userID).Find(&grants).Error; err != nil {
+			return err
+		}
+		return tx.Where("tenant_id = ? AND user_id = ?", tenantID, userID).Delete(&models.ClusterPermission{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	for i := range grants {
+		go s.cleanupGrantRBAC(&grants[i])
+	}
+	return nil
+}
+
+// IsTenantAdmin 用户是否为指定租户的管理员
+func (s *TenantService) IsTenantAdmin(userID, tenantID uint) bool {
+	var count int64
+	s.db.Model(&models.TenantMember{}).
+		Where("tenant_id = ? AND user_id = ? AND role = ?", tenantID, userID, models.TenantRoleAdmin).
+		Count(&count)
+	return count > 0
+}
+
+// ========== 租户授权 ==========
+
+// TenantGrantRequest 租户管理员授权请求
+type TenantGrantRequest struct {
+	UserID         uint     `json:"user_id" binding:"required"`
+	ClusterID      uint     `json:"cluster_id" binding:"required"`
+	PermissionType string   `json:"permission_type" binding:"required"`
+	Namespaces     []string `json:"namespaces" binding:"required"`
+}
+
+// ListGrants 获取租户授予的权限
+func (s *TenantService) ListGrants(tenantID uint) ([]models.ClusterPermission, error) {
+	var grants []models.ClusterPermission
+	if err := s.db.Preload("User").Preload("Cluster").Where("tenant_id = ?", tenantID).Find(&grants).Error; err != nil {
+		return nil, fmt.Errorf("获取租户授权失败: %w", err)
+	}
+	return grants, nil
+}
+
+// CreateGrant 租户管理员在本租户命名空间内为成员授权（仅支持 dev / readonly）
+func (s *TenantService) CreateGrant(tenantID uint, req *TenantGrantRequest) (*models.ClusterPermission, error) {
+	if req.PermissionType != models.PermissionTypeDev && req.PermissionType != models.PermissionTypeReadonly {
+		return nil, errcode.New(errcode.TenantGrantTypeDenied)
+	}
+
+	var member models.TenantMember
+	s.db.Where("tenant_id = ? AND user_id = ?", tenantID, req.UserID).Limit(1).Find(&member)
+	if member.ID == 0 {
+		return nil, errcode.New(errcode.TenantGrantNonMember)
+	}
+
+	var owned []models.TenantNamespace
+	s.db.Where("tenant_id = ? AND cluster_id = ?", tenantID, req.ClusterID).Find(&owned)
+	scope := NewTenantScope(&member, owned)
+	if len(req.Namespaces) == 0 {
+		return nil, errcode.New(errcode.TenantGrantNamespaceRequired)
+	}
+	for _, ns := range req.Namespaces {
+		if !scope.Allows(req.ClusterID, ns) {
+			return nil, errcode.New(errcode.TenantGrantNamespaceDenied, ns)
+		}
+	}
+
+	userID := req.UserID
+	permission, err := s.permissionSvc.CreateClusterPermission(&CreateClusterPermissionRequest{
+		ClusterID:      req.ClusterID,
+		UserID:         &userID,
+		PermissionType: req.PermissionType,
+		Namespaces:     req.Namespaces,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(permission).Update("tenant_id", tenantID).Error; err != nil {
+		return nil, fmt.Errorf("记录租户授权失败: %w", err)
+	}
+	permission.TenantID = &tenantID
+
+	go s.ensureGrantRBAC(permission)
+	return permission, nil
+}
+
+// DeleteGrant 删除租户授予的权限
+func (s *TenantService) DeleteGrant(tenantID, permissionID uint) error {
+	var grant models.ClusterPermission
+	if err := s.db.Where("id = ? AND tenant_id = ?", permissionID, tenantID).First(&grant).Error; err != nil {
+		return errcode.New(errcode.TenantGrantNotFound)
+	}
+	if err := s.db.Delete(&grant).Error; err != nil {
+		return fmt.Errorf("删除租户授权失败: %w", err)
+	}
+	go s.cleanupGrantRBAC(&grant)
+	return nil
+}
+
+// ensureGrantRBAC 为租户授权创建集群内 RBAC 资源
+func (s *TenantService) ensureGrantRBAC(permission *models.ClusterPermission) {
+	if s.rbacSvc == nil || permission.UserID == nil {
+		return
+	}
+	clientset, err := clusterClientsetByID(s.db, permission.ClusterID)
+	if err != nil {
+		logger.Error("创建 K8s 客户端失败，无法同步租户授权 RBAC: clusterID=%d, err=%v", permission.ClusterID, err)
+		return
+	}
+	config := &UserRBACConfig{
+		UserID:         *permission.UserID,
+		PermissionType: permission.PermissionType,
+		Namespaces:     permission.GetNamespaceList(),
+	}
+	if err := s.rbacSvc.EnsureUserRBAC(clientset, config); err != nil {
+		logger.Error("创建租户授权 RBAC 失败: permissionID=%d, err=%v", permission.ID, err)
+	}
+}
+
+// cleanupGrantRBAC 清理租户授权对应的集群内 RBAC 资源
+func (s *TenantService) cleanupGrantRBAC(permission *models.ClusterPermission) {
+	if s.rbacSvc == nil || permission.UserID == nil {
+		return
+	}
+	clientset, err := clusterClientsetByID(s.db, permission.ClusterID)
+	if err != nil {
+		logger.Error("创建 K8s 客户端失败，无法清理租户授权 RBAC: clusterID=%d, err=%v", permission.ClusterID, err)
+		return
+	}
+	if err := cleanupRevokedUserRBAC(s.db, s.rbacSvc, clientset, permission); err != nil {
+		logger.Error("清理租户授权 RBAC 失败: permissionID=%d, err=%v", permission.ID, err)
+	}
+}
+
+// ========== 租户配额 ==========
+
+// NamespaceQuota 命名空间配额汇总
+type NamespaceQuota struct {
+	ClusterID   uint              `json:"cluster_id"`
+	ClusterName string            `json:"cluster_name"`
+	Namespace   string            `json:"namespace"`
+	Hard        map[string]string `json:"hard"`
+	Used        map[string]string `json:"used"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// TenantQuotaSummary 租户配额汇总（由各命名空间 ResourceQuota 聚合）
+type TenantQuotaSummary struct {
+	TenantID   uint              `json:"tenant_id"`
+	Hard       map[string]string `json:"hard"`
+	Used       map[string]string `json:"used"`
+	Namespaces []NamespaceQuota  `json:"namespaces"`
+}
+
+// GetQuotas 汇总租户所有命名空间的 ResourceQuota
+func (s *TenantService) GetQuotas(ctx context.Context, tenantID uint) (*TenantQuotaSummary, error) {
+	tenant, err := s.GetTenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &TenantQuotaSummary{TenantID: tenantID, Namespaces: []NamespaceQuota{}}
+	totalHard := corev1.ResourceList{}
+	totalUsed := corev1.ResourceList{}
+
+	for _, ns := range tenant.Namespaces {
+		item := NamespaceQuota{ClusterID: ns.ClusterID, Namespace: ns.Namespace}
+		if ns.Cluster != nil {
+			item.ClusterName = ns.Cluster.Name
+		}
+
+		clientset, err := clusterClientsetByID(s.db, ns.ClusterID)
+		if err != nil {
+			item.Error = err.Error()
+			summary.Namespaces = append(summary.Namespaces, item)
+			continue
+		}
+		listCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		quotaNamespace := ns.Namespace
+		if quotaNamespace == models.TenantNamespaceAll {
+			quotaNamespace = metav1.NamespaceAll
+		}
+		quotas, err := clientset.CoreV1().ResourceQuotas(quotaNamespace).List(listCtx, metav1.ListOptions{})
+		cancel()
+		if err != nil {
+			item.Error = err.Error()
+			summary.Namespaces = append(summary.Namespaces, item)
+			continue
+		}
+
+		hard, used := AggregateResourceQuotas(quotas.Items)
+		item.Hard = formatResourceList(hard)
+		item.Used = formatResourceList(used)
+		summary.Namespaces = append(summary.Namespaces, item)
+		addResourceList(totalHard, hard)
+		addResourceList(totalUsed, used)
+	}
+
+	summary.Hard = formatResourceList(totalHard)
+	summary.Used = formatResourceList(totalUsed)
+	return summary, nil
+}
+
+// AggregateResourceQuotas 聚合多个 ResourceQuota 的限额与用量
+func AggregateResourceQuotas(quotas []corev1.ResourceQuota) (corev1.ResourceList, corev1.ResourceList) {
+	hard := corev1.ResourceList{}
+	used := corev1.ResourceList{}
+	for _, quota := range quotas {
+		addResourceList(hard, quota.Status.Hard)
+		addResourceList(used, quota.Status.Used)
+	}
+	return hard, used
+}
+
+// addResourceList 将 src 中的资源量累加到 dst
+func addResourceList(dst, src corev1.ResourceList) {
+	for name, quantity := range src {
+		if current, ok := dst[name]; ok {
+			current.Add(quantity)
+			dst[name] = current
+		} else {
+			dst[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// formatResourceList 将资源列表转换为字符串形式
+func formatResourceList(list corev1.ResourceList) map[string]string {
+	result := make(map[string]string, len(list))
+	for name, quantity := range list {
+		q := quantity
+		result[string(name)] = q.String()
+	}
+	return result
+}
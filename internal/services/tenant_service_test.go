@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+func newTestTenantScope() *TenantScope {
+	member := &models.TenantMember{TenantID: 7, Role: models.TenantRoleMember}
+	return NewTenantScope(member, []models.TenantNamespace{
+		{TenantID: 7, ClusterID: 1, Namespace: "team-a"},
+		{TenantID: 7, ClusterID: 1, Namespace: "team-a-dev"},
+		{TenantID: 7, ClusterID: 2, Namespace: "team-a"},
+	})
+}
+
+func TestScopePermissionToTenantDowngradesClusterWideGrant(t *testing.T) {
+	permission := &models.ClusterPermission{ClusterID: 1, PermissionType: models.PermissionTypeAdmin}
+	_ = permission.SetNamespaceList([]string{"*"})
+
+	scoped, ok := ScopePermissionToTenant(permission, newTestTenantScope(), 1)
+	if !ok {
+		t.Fatal("expected tenant namespaces to remain accessible")
+	}
+	if scoped.PermissionType != models.PermissionTypeDev {
+		t.Fatalf("admin should be downgraded to dev, got %q", scoped.PermissionType)
+	}
+	namespaces := scoped.GetNamespaceList()
+	if len(namespaces) != 2 || namespaces[0] != "team-a" || namespaces[1] != "team-a-dev" {
+		t.Fatalf("unexpected namespaces: %v", namespaces)
+	}
+	if permission.PermissionType != models.PermissionTypeAdmin {
+		t.Fatal("original permission must not be modified")
+	}
+}
+
+func TestScopePermissionToTenantIntersectsNamespaces(t *testing.T) {
+	permission := &models.ClusterPermission{ClusterID: 1, PermissionType: models.PermissionTypeReadonly}
+	_ = permission.SetNamespaceList([]string{"team-a-*", "other"})
+
+	scoped, ok := ScopePermissionToTenant(permission, newTestTenantScope(), 1)
+	if !ok {
+		t.Fatal("expected team-a-dev to remain accessible")
+	}
+	if namespaces := scoped.GetNamespaceList(); len(namespaces) != 1 || namespaces[0] != "team-a-dev" {
+		t.Fatalf("unexpected namespaces: %v", namespaces)
+	}
+	if scoped.PermissionType != models.PermissionTypeReadonly {
+		t.Fatalf("readonly should be kept, got %q", scoped.PermissionType)
+	}
+
+	if _, ok := ScopePermissionToTenant(permission, newTestTenantScope(), 3); ok {
+		t.Fatal("cluster without tenant namespaces should not be accessible")
+	}
+}
+
+func TestAggregateResourceQuotas(t *testing.T) {
+	quota := func(hardCPU, usedCPU string) corev1.ResourceQuota {
+		return corev1.ResourceQuota{Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{corev1.ResourceLimitsCPU: resource.MustParse(hardCPU)},
+			Used: corev1.ResourceList{corev1.ResourceLimitsCPU: resource.MustParse(usedCPU)},
+		}}
+	}
+
+	hard, used := AggregateResourceQuotas([]corev1.ResourceQuota{quota("2", "500m"), quota("1500m", "1")})
+	if got := hard[corev1.ResourceLimitsCPU]; got.MilliValue() != 3500 {
+		t.Fatalf("expected hard 3500m, got %s", got.String())
+	}
+	if got := used[corev1.ResourceLimitsCPU]; got.MilliValue() != 1500 {
+		t.Fatalf("expected used 1500m, got %s", got.String())
+	}
+}
+
+func TestScopePermissionToTenantKeepsOwnedCluster(t *testing.T) {
+	member := &models.TenantMember{TenantID: 7, Role: models.TenantRoleAdmin}
+	scope := NewTenantScope(member, []models.TenantNamespace{
+		{TenantID: 7, ClusterID: 4, Namespace: models.TenantNamespaceAll},
+	})
+	permission := &models.ClusterPermission{ClusterID: 4, PermissionType: models.PermissionTypeAdmin}
+	_ = permission.SetNamespaceList([]string{"*"})
+
+	scoped, ok := ScopePermissionToTenant(permission, scope, 4)
+	if !ok || scoped.PermissionType != models.PermissionTypeAdmin {
+		t.Fatalf("owned cluster should keep the original grant, got %+v", scoped)
+	}
+	if !scope.Allows(4, "anything") {
+		t.Fatal("owned cluster should allow every namespace")
+	}
+}
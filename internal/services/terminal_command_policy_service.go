@@ -0,0 +1,377 @@
+package services
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// CommandCheckRequest 终端命令检查请求
+type CommandCheckRequest struct {
+	TerminalType   TerminalType      `json:"terminal_type"`
+	ClusterLabels  map[string]string `json:"cluster_labels,omitempty"`
+	Namespace      string            `json:"namespace"`
+	PermissionType string            `json:"permission_type"`
+	Command        string            `json:"command"`
+}
+
+// CommandDecision 终端命令检查结果
+type CommandDecision struct {
+	// Effect 为 allow / confirm / deny，未命中任何规则时为空，表示允许执行
+	Effect   string `json:"effect"`
+	RuleID   uint   `json:"rule_id,omitempty"`
+	RuleName string `json:"rule_name,omitempty"`
+	Segment  string `json:"segment,omitempty"` // 命中规则的命令片段
+	Message  string `json:"message,omitempty"`
+}
+
+// Denied 是否被拒绝
+func (d *CommandDecision) Denied() bool {
+	return d != nil && d.Effect == models.CommandRuleEffectDeny
+}
+
+// NeedsConfirm 是否需要确认
+func (d *CommandDecision) NeedsConfirm() bool {
+	return d != nil && d.Effect == models.CommandRuleEffectConfirm
+}
+
+// commandEffectRank 效果的限制程度，数值越大越严格
+func commandEffectRank(effect string) int {
+	switch effect {
+	case models.CommandRuleEffectDeny:
+		return 3
+	case models.CommandRuleEffectConfirm:
+		return 2
+	case models.CommandRuleEffectAllow:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// NormalizeCommand 规整命令：去掉首尾空白并合并连续空白
+func NormalizeCommand(command string) string {
+	return strings.Join(strings.Fields(command), " ")
+}
+
+// SplitCommandSegments 按 ; && || | & 与换行拆分命令行，返回规整后的非空片段
+// 片段开头的 sudo 会被去掉，避免通过 sudo 绕过前缀规则。
+func SplitCommandSegments(command string) []string {
+	replacer := strings.NewReplacer("&&", "\n", "||", "\n", ";", "\n", "|", "\n", "&", "\n", "`", "\n", "$(", "\n", "\r", "\n")
+	var segments []string
+	for _, part := range strings.Split(replacer.Replace(command), "\n") {
+		part = NormalizeCommand(strings.TrimRight(strings.TrimSpace(part), ")"))
+		for strings.HasPrefix(part, "sudo ") {
+			part = strings.TrimSpace(strings.TrimPrefix(part, "sudo "))
+		}
+		if part != "" {
+			segments = append(segments, part)
+		}
+	}
+	return segments
+}
+
+// commandRegexCache 已编译的规则正则
+var commandRegexCache sync.Map
+
+// compileCommandRegex 编译并缓存规则正则
+func compileCommandRegex(pattern string) (*regexp.Regexp, error) {
+	if re, ok := commandRegexCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	commandRegexCache.Store(pattern, re)
+	return re, nil
+}
+
+// commandPatternMatches 判断命令片段是否匹配规则模式
+func commandPatternMatches(rule *models.TerminalCommandRule, segment string) bool {
+	pattern := rule.Pattern
+	switch rule.MatchType {
+	case models.CommandRuleMatchPrefix:
+		pattern = NormalizeCommand(pattern)
+		if pattern == "" {
+			return false
+		}
+		return segment == pattern || strings.HasPrefix(segment, pattern+" ")
+	case models.CommandRuleMatchContains:
+		pattern = NormalizeCommand(pattern)
+		return pattern != "" && strings.Contains(segment, pattern)
+	case models.CommandRuleMatchRegex:
+		re, err := compileCommandRegex(pattern)
+		if err != nil {
+			return false
+		}
+		return re.MatchString(segment)
+	default:
+		return false
+	}
+}
+
+// commandRuleApplies 判断规则是否适用于终端上下文（终端类型、集群、命名空间、权限类型）
+func commandRuleApplies(rule *models.TerminalCommandRule, req *CommandCheckRequest) bool {
+	if types := rule.GetTerminalTypeList(); len(types) > 0 && !containsString(types, string(req.TerminalType)) {
+		return false
+	}
+	if !models.MatchLabels(rule.GetClusterSelector(), req.ClusterLabels) {
+		return false
+	}
+	// 终端不涉及命名空间时（如 kubectl、节点终端），仅匹配未限定命名空间的规则
+	if namespaces := rule.GetNamespaceList(); len(namespaces) > 0 && !matchPolicyPattern(namespaces, req.Namespace) {
+		return false
+	}
+	if permissionTypes := rule.GetPermissionTypeList(); len(permissionTypes) > 0 && !containsString(permissionTypes, req.PermissionType) {
+		return false
+	}
+	return true
+}
+
+// EvaluateCommandRules 对命令行评估终端命令规则
+// 整行与拆分出的每个片段分别评估：单个片段取优先级最高的规则（同优先级取更严格的效果），
+// 各片段之间取最严格的结果，因此允许规则无法放行同一行中被拒绝的其他片段。
+func EvaluateCommandRules(rules []models.TerminalCommandRule, req *CommandCheckRequest) *CommandDecision {
+	decision := &CommandDecision{}
+
+	var applicable []*models.TerminalCommandRule
+	for i := range rules {
+		if rules[i].Enabled && commandRuleApplies(&rules[i], req) {
+			applicable = append(applicable, &rules[i])
+		}
+	}
+	if len(applicable) == 0 {
+		return decision
+	}
+
+	line := NormalizeCommand(req.Command)
+	if line == "" {
+		return decision
+	}
+	candidates := append([]string{line}, SplitCommandSegments(req.Command)...)
+
+	for _, segment := range candidates {
+		var deciding *models.TerminalCommandRule
+		for _, rule := range applicable {
+			if !commandPatternMatches(rule, segment) {
+				continue
+			}
+			if deciding == nil || rule.Priority > deciding.Priority ||
+				(rule.Priority == deciding.Priority && commandEffectRank(rule.Effect) > commandEffectRank(deciding.Effect)) {
+				deciding = rule
+			}
+		}
+		if deciding == nil || commandEffectRank(deciding.Effect) <= commandEffectRank(decision.Effect) {
+			continue
+		}
+		decision.Effect = deciding.Effect
+		decision.RuleID = deciding.ID
+		decision.RuleName = deciding.Name
+		decision.Segment = segment
+		decision.Message = deciding.Message
+	}
+	return decision
+}
+
+// ========== 规则管理 ==========
+
+// TerminalCommandPolicyService 终端命令策略服务
+type TerminalCommandPolicyService struct {
+	db *gorm.DB
+
+	mu       sync.RWMutex
+	cache    []models.TerminalCommandRule
+	loadedAt time.Time
+}
+
+// NewTerminalCommandPolicyService 创建终端命令策略服务
+func NewTerminalCommandPolicyService(db *gorm.DB) *TerminalCommandPolicyService {
+	return &TerminalCommandPolicyService{db: db}
+}
+
+// TerminalCommandRuleRequest 创建/更新终端命令规则请求
+type TerminalCommandRuleRequest struct {
+	Name            string            `json:"name" binding:"required"`
+	Description     string            `json:"description"`
+	Effect          string            `json:"effect" binding:"required"`
+	MatchType       string            `json:"match_type" binding:"required"`
+	Pattern         string            `json:"pattern" binding:"required"`
+	Priority        int               `json:"priority"`
+	TerminalTypes   []string          `json:"terminal_types"`
+	ClusterSelector map[string]string `json:"cluster_selector"`
+	Namespaces      []string          `json:"namespaces"`
+	PermissionTypes []string          `json:"permission_types"`
+	Message         string            `json:"message"`
+	Enabled         *bool             `json:"enabled"`
+}
+
+// applyTo 校验并写入规则模型
+func (b *TerminalCommandRuleRequest) applyTo(r *models.TerminalCommandRule) error {
+	switch b.Effect {
+	case models.CommandRuleEffectAllow, models.CommandRuleEffectConfirm, models.CommandRuleEffectDeny:
+	default:
+		return errcode.New(errcode.InvalidCommandRuleEffect)
+	}
+	switch b.MatchType {
+	case models.CommandRuleMatchPrefix, models.CommandRuleMatchContains:
+		if NormalizeCommand(b.Pattern) == "" {
+			return errcode.New(errcode.CommandPatternRequired)
+		}
+	case models.CommandRuleMatchRegex:
+		if _, err := regexp.Compile(b.Pattern); err != nil {
+			return errcode.New(errcode.InvalidCommandRegex, err)
+		}
+	default:
+		return errcode.New(errcode.InvalidCommandMatchType)
+	}
+	for _, t := range b.TerminalTypes {
+		switch TerminalType(t) {
+		case TerminalTypeKubectl, TerminalTypePod, TerminalTypeNode:
+		default:
+			return errcode.New(errcode.UnsupportedTerminalType, t)
+		}
+	}
+	for _, pattern := range b.Namespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errcode.New(errcode.InvalidNamespacePattern, pattern)
+		}
+	}
+
+	r.Name = b.Name
+	r.Description = b.Description
+	r.Effect = b.Effect
+	r.MatchType = b.MatchType
+	r.Pattern = b.Pattern
+	r.Priority = b.Priority
+	r.TerminalTypes = encodeJSONOrEmpty(b.TerminalTypes, len(b.TerminalTypes) == 0)
+	r.ClusterSelector = encodeJSONOrEmpty(b.ClusterSelector, len(b.ClusterSelector) == 0)
+	r.Namespaces = encodeJSONOrEmpty(b.Namespaces, len(b.Namespaces) == 0)
+	r.PermissionTypes = encodeJSONOrEmpty(b.PermissionTypes, len(b.PermissionTypes) == 0)
+	r.Message = b.Message
+	r.Enabled = b.Enabled == nil || *b.Enabled
+	return nil
+}
+
+// CreateRule 创建规则
+func (s *TerminalCommandPolicyService) CreateRule(body *TerminalCommandRuleRequest) (*models.TerminalCommandRule, error) {
+	rule := &models.TerminalCommandRule{}
+	if err := body.applyTo(rule); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(rule).Error; err != nil {
+		return nil, fmt.Errorf("创建终端命令规则失败: %w", err)
+	}
+	s.invalidate()
+	logger.Info("创建终端命令规则: id=%d, name=%s, effect=%s", rule.ID, rule.Name, rule.Effect)
+	return rule, nil
+}
+
+// UpdateRule 更新规则
+func (s *TerminalCommandPolicyService) UpdateRule(id uint, body *TerminalCommandRuleRequest) (*models.TerminalCommandRule, error) {
+	var rule models.TerminalCommandRule
+	if err := s.db.First(&rule, id).Error; err != nil {
+		return nil, errcode.New(errcode.CommandRuleNotFound)
+	}
+	if err := body.applyTo(&rule); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(&rule).Error; err != nil {
+		return nil, fmt.Errorf("更新终端命令规则失败: %w", err)
+	}
+	s.invalidate()
+	return &rule, nil
+}
+
+// DeleteRule 删除规则
+func (s *TerminalCommandPolicyService) DeleteRule(id uint) error {
+	result := s.db.Delete(&models.TerminalCommandRule{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除终端命令规则失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errcode.New(errcode.CommandRuleNotFound)
+	}
+	s.invalidate()
+	return nil
+}
+
+// GetRule 获取规则详情
+func (s *TerminalCommandPolicyService) GetRule(id uint) (*models.TerminalCommandRule, error) {
+	var rule models.TerminalCommandRule
+	if err := s.db.First(&rule, id).Error; err != nil {
+		return nil, errcode.New(errcode.CommandRuleNotFound)
+	}
+	return &rule, nil
+}
+
+// ListRules 获取规则列表
+func (s *TerminalCommandPolicyService) ListRules() ([]models.TerminalCommandRule, error) {
+	var rules []models.TerminalCommandRule
+	if err := s.db.Order("priority DESC, id ASC").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("获取终端命令规则失败: %w", err)
+	}
+	return rules, nil
+}
+
+// ========== 规则评估 ==========
+
+// enabledRules 获取已启用规则（带缓存）
+func (s *TerminalCommandPolicyService) enabledRules() ([]models.TerminalCommandRule, error) {
+	s.mu.RLock()
+	if s.cache != nil && time.Since(s.loadedAt) < policyCacheTTL {
+		rules := s.cache
+		s.mu.RUnlock()
+		return rules, nil
+	}
+	s.mu.RUnlock()
+
+	var rules []models.TerminalCommandRule
+	if err := s.db.Where("enabled = ?", true).Order("priority DESC, id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache = rules
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return rules, nil
+}
+
+// invalidate 使规则缓存失效
+func (s *TerminalCommandPolicyService) invalidate() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}
+
+// ClusterLabels 获取集群标签（终端建立时调用一次）
+func (s *TerminalCommandPolicyService) ClusterLabels(clusterID uint) map[string]string {
+	var cluster models.Cluster
+	if clusterID == 0 || s.db.Select("id", "labels").First(&cluster, clusterID).Error != nil {
+		return map[string]string{}
+	}
+	return cluster.GetLabels()
+}
+
+// Check 检查命令，加载规则失败时放行并记录日志（避免策略存储故障导致终端不可用）
+func (s *TerminalCommandPolicyService) Check(req *CommandCheckRequest) *CommandDecision {
+	rules, err := s.enabledRules()
+	if err != nil {
+		logger.Error("加载终端命令规则失败: %v", err)
+		return &CommandDecision{}
+	}
+	if len(rules) == 0 {
+		return &CommandDecision{}
+	}
+	return EvaluateCommandRules(rules, req)
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+func TestSplitCommandSegments(t *testing.T) {
+	got := SplitCommandSegments("ls  -la && sudo rm -rf / ; echo $(shutdown -h now) | tee x")
+	want := []string{"ls -la", "rm -rf /", "echo", "shutdown -h now", "tee x"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("segment %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestEvaluateCommandRules(t *testing.T) {
+	rules := []models.TerminalCommandRule{
+		{ID: 1, Name: "deny-rm-root", Effect: models.CommandRuleEffectDeny, MatchType: models.CommandRuleMatchPrefix, Pattern: "rm -rf /", Enabled: true},
+		{ID: 2, Name: "confirm-delete-ns", Effect: models.CommandRuleEffectConfirm, MatchType: models.CommandRuleMatchRegex, Pattern: `^kubectl\s+delete\s+(ns|namespace)\b`, Enabled: true},
+		{ID: 3, Name: "deny-shutdown-prod", Effect: models.CommandRuleEffectDeny, MatchType: models.CommandRuleMatchPrefix, Pattern: "shutdown", ClusterSelector: `{"env":"prod"}`, Enabled: true},
+		{ID: 4, Name: "allow-shutdown-admin", Effect: models.CommandRuleEffectAllow, MatchType: models.CommandRuleMatchPrefix, Pattern: "shutdown", PermissionTypes: `["admin"]`, Priority: 10, Enabled: true},
+		{ID: 5, Name: "disabled", Effect: models.CommandRuleEffectDeny, MatchType: models.CommandRuleMatchContains, Pattern: "ls", Enabled: false},
+		{ID: 6, Name: "deny-kube-system", Effect: models.CommandRuleEffectDeny, MatchType: models.CommandRuleMatchContains, Pattern: "cat", Namespaces: `["kube-*"]`, Enabled: true},
+	}
+	prod := map[string]string{"env": "prod"}
+
+	cases := []struct {
+		name    string
+		req     CommandCheckRequest
+		effect  string
+		ruleID  uint
+		segment string
+	}{
+		{"prefix on word boundary", CommandCheckRequest{Command: "rm   -rf /"}, models.CommandRuleEffectDeny, 1, "rm -rf /"},
+		{"prefix does not match longer word", CommandCheckRequest{Command: "rm -rf /tmp/x"}, "", 0, ""},
+		{"segment after operator", CommandCheckRequest{Command: "cd / && sudo rm -rf /"}, models.CommandRuleEffectDeny, 1, "rm -rf /"},
+		{"regex confirm", CommandCheckRequest{Command: "kubectl delete ns demo"}, models.CommandRuleEffectConfirm, 2, "kubectl delete ns demo"},
+		{"cluster selector mismatch", CommandCheckRequest{Command: "shutdown -h now", ClusterLabels: map[string]string{"env": "dev"}}, "", 0, ""},
+		{"cluster selector match", CommandCheckRequest{Command: "shutdown -h now", ClusterLabels: prod, PermissionType: models.PermissionTypeOps}, models.CommandRuleEffectDeny, 3, "shutdown -h now"},
+		{"higher priority allow", CommandCheckRequest{Command: "shutdown -h now", ClusterLabels: prod, PermissionType: models.PermissionTypeAdmin}, models.CommandRuleEffectAllow, 4, "shutdown -h now"},
+		{"allow cannot override other segment", CommandCheckRequest{Command: "shutdown -c; rm -rf /", ClusterLabels: prod, PermissionType: models.PermissionTypeAdmin}, models.CommandRuleEffectDeny, 1, "rm -rf /"},
+		{"disabled rule ignored", CommandCheckRequest{Command: "ls"}, "", 0, ""},
+		{"namespace pattern", CommandCheckRequest{Command: "cat /etc/hosts", Namespace: "kube-system"}, models.CommandRuleEffectDeny, 6, "cat /etc/hosts"},
+		{"namespace rule skipped without namespace", CommandCheckRequest{Command: "cat /etc/hosts"}, "", 0, ""},
+	}
+
+	for _, tc := range cases {
+		req := tc.req
+		d := EvaluateCommandRules(rules, &req)
+		if d.Effect != tc.effect || d.RuleID != tc.ruleID {
+			t.Fatalf("%s: expected effect=%q rule=%d, got %+v", tc.name, tc.effect, tc.ruleID, d)
+		}
+		if tc.segment != "" && d.Segment != tc.segment {
+			t.Fatalf("%s: expected segment %q, got %q", tc.name, tc.segment, d.Segment)
+		}
+	}
+}
+
+func TestTerminalCommandRuleRequestValidation(t *testing.T) {
+	bad := []TerminalCommandRuleRequest{
+		{Name: "x", Effect: "block", MatchType: models.CommandRuleMatchPrefix, Pattern: "rm"},
+		{Name: "x", Effect: models.CommandRuleEffectDeny, MatchType: "glob", Pattern: "rm"},
+		{Name: "x", Effect: models.CommandRuleEffectDeny, MatchType: models.CommandRuleMatchRegex, Pattern: "("},
+		{Name: "x", Effect: models.CommandRuleEffectDeny, MatchType: models.CommandRuleMatchPrefix, Pattern: "   "},
+		{Name: "x", Effect: models.CommandRuleEffectDeny, MatchType: models.CommandRuleMatchPrefix, Pattern: "rm", TerminalTypes: []string{"arthas"}},
+	}
+	for i, body := range bad {
+		if err := body.applyTo(&models.TerminalCommandRule{}); err == nil {
+			t.Fatalf("case %d: expected validation error", i)
+		}
+	}
+
+	var rule models.TerminalCommandRule
+	body := TerminalCommandRuleRequest{Name: "ok", Effect: models.CommandRuleEffectConfirm, MatchType: models.CommandRuleMatchContains, Pattern: "delete", TerminalTypes: []string{"pod"}}
+	if err := body.applyTo(&rule); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !rule.Enabled || rule.Namespaces != "" || rule.TerminalTypes != `["pod"]` {
+		t.Fatalf("unexpected rule: %+v", rule)
+	}
+}
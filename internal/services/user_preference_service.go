@@ -0,0 +1,63 @@
+package services
+
+import (
+	"sync"
+
+	"gorm.io/gorm"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+// UserPreferenceService 用户个人偏好服务，语言偏好在每个请求中读取，因此按用户缓存
+type UserPreferenceService struct {
+	db *gorm.DB
+
+	mu        sync.RWMutex
+	languages map[uint]string
+}
+
+// NewUserPreferenceService 创建用户偏好服务
+func NewUserPreferenceService(db *gorm.DB) *UserPreferenceService {
+	return &UserPreferenceService{db: db, languages: make(map[uint]string)}
+}
+
+// Language 用户的语言偏好，未设置或查询失败时返回空串（由 Accept-Language 决定）
+func (s *UserPreferenceService) Language(userID uint) string {
+	s.mu.RLock()
+	lang, ok := s.languages[userID]
+	s.mu.RUnlock()
+	if ok {
+		return lang
+	}
+
+	var user models.User
+	if err := s.db.Select("id", "language").First(&user, userID).Error; err != nil {
+		return ""
+	}
+	lang = errcode.NormalizeLanguage(user.Language)
+	s.mu.Lock()
+	s.languages[userID] = lang
+	s.mu.Unlock()
+	return lang
+}
+
+// UpdateLanguage 设置用户的语言偏好，空串表示清除偏好
+func (s *UserPreferenceService) UpdateLanguage(userID uint, language string) (string, error) {
+	lang := errcode.NormalizeLanguage(language)
+	if language != "" && lang == "" {
+		return "", errcode.New(errcode.UnsupportedLanguage, language)
+	}
+	result := s.db.Model(&models.User{}).Where("id = ?", userID).Update("language", lang)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return "", errcode.New(errcode.UserNotFound)
+	}
+
+	s.mu.Lock()
+	s.languages[userID] = lang
+	s.mu.Unlock()
+	return lang, nil
+}
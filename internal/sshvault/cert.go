@@ -0,0 +1,59 @@
+package sshvault
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// clockSkew 证书生效时间前移，容忍节点与平台之间的时钟偏差
+const clockSkew = time.Minute
+
+// IssueCertificate 为本次会话生成一次性 ed25519 密钥，并用 CA 签发短期用户证书。
+// 返回的 Signer 只存在于服务端内存中，会话结束即丢弃。
+func IssueCertificate(ca ssh.Signer, keyID string, principals []string, ttl time.Duration, now time.Time) (ssh.Signer, *ssh.Certificate, error) {
+	if len(principals) == 0 {
+		return nil, nil, errors.New("证书至少需要一个登录用户")
+	}
+	if ttl <= 0 {
+		return nil, nil, errors.New("证书有效期必须大于 0")
+	}
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var serial [8]byte
+	if _, err := rand.Read(serial[:]); err != nil {
+		return nil, nil, err
+	}
+	cert := &ssh.Certificate{
+		Key:             signer.PublicKey(),
+		Serial:          binary.BigEndian.Uint64(serial[:]),
+		CertType:        ssh.UserCert,
+		KeyId:           keyID,
+		ValidPrincipals: principals,
+		ValidAfter:      uint64(now.Add(-clockSkew).Unix()),
+		ValidBefore:     uint64(now.Add(ttl).Unix()),
+		Permissions: ssh.Permissions{
+			Extensions: map[string]string{"permit-pty": ""},
+		},
+	}
+	if err := cert.SignCert(rand.Reader, ca); err != nil {
+		return nil, nil, err
+	}
+	certSigner, err := ssh.NewCertSigner(cert, signer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return certSigner, cert, nil
+}
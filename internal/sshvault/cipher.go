@@ -0,0 +1,72 @@
+package sshvault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const cipherPrefix = "v1:"
+
+// Cipher 凭据加密（AES-256-GCM，密钥由配置的口令派生）
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher 由口令创建凭据加密器
+func NewCipher(secret string) (*Cipher, error) {
+	if secret == "" {
+		return nil, errors.New("凭据加密密钥不能为空")
+	}
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Encrypt 加密明文，空字符串原样返回
+func (c *Cipher) Encrypt(plain string) (string, error) {
+	if plain == "" {
+		return "", nil
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return cipherPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密 Encrypt 的输出，空字符串原样返回
+func (c *Cipher) Decrypt(enc string) (string, error) {
+	if enc == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(enc, cipherPrefix) {
+		return "", errors.New("不支持的密文格式")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(enc, cipherPrefix))
+	if err != nil {
+		return "", fmt.Errorf("密文解码失败: %w", err)
+	}
+	n := c.aead.NonceSize()
+	if len(raw) < n {
+		return "", errors.New("密文长度无效")
+	}
+	plain, err := c.aead.Open(nil, raw[:n], raw[n:], nil)
+	if err != nil {
+		return "", errors.New("凭据解密失败，加密密钥可能已变更")
+	}
+	return string(plain), nil
+}
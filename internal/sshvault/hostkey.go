@@ -0,0 +1,54 @@
+package sshvault
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// KnownHost 已信任的主机密钥
+type KnownHost struct {
+	KeyType     string
+	Fingerprint string // SHA256 指纹
+}
+
+// HostKeyStore 主机密钥存储（按集群 + 主机地址区分）
+type HostKeyStore interface {
+	// LookupHostKey 查询已信任的主机密钥，未记录时返回 nil
+	LookupHostKey(clusterID uint, host string) (*KnownHost, error)
+	// TrustHostKey 首次连接时记录主机密钥，已记录且一致时刷新最近使用时间
+	TrustHostKey(clusterID uint, host, node string, key ssh.PublicKey) error
+	// RecordHostKeyMismatch 记录与已信任密钥不一致的主机密钥并告警
+	RecordHostKeyMismatch(clusterID uint, host, node string, key ssh.PublicKey) error
+}
+
+// MismatchError 主机密钥与首次记录不一致
+type MismatchError struct {
+	Host      string
+	Expected  string
+	Presented string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("主机 %s 的密钥与首次记录不一致（记录 %s，当前 %s），可能存在中间人攻击，已拒绝连接",
+		e.Host, e.Expected, e.Presented)
+}
+
+// HostKeyCallback 首次使用即信任（TOFU）的主机密钥校验：首次连接记录密钥，之后密钥变化时拒绝连接并记录告警
+func HostKeyCallback(store HostKeyStore, clusterID uint, node string) ssh.HostKeyCallback {
+	return func(hostname string, _ net.Addr, key ssh.PublicKey) error {
+		known, err := store.LookupHostKey(clusterID, hostname)
+		if err != nil {
+			return fmt.Errorf("读取主机密钥记录失败: %w", err)
+		}
+		fingerprint := ssh.FingerprintSHA256(key)
+		if known != nil && (known.Fingerprint != fingerprint || known.KeyType != key.Type()) {
+			if err := store.RecordHostKeyMismatch(clusterID, hostname, node, key); err != nil {
+				return fmt.Errorf("记录主机密钥告警失败: %w", err)
+			}
+			return &MismatchError{Host: hostname, Expected: known.Fingerprint, Presented: fingerprint}
+		}
+		return store.TrustHostKey(clusterID, hostname, node, key)
+	}
+}
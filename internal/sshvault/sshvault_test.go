@@ -0,0 +1,126 @@
+package sshvault
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestCipherRoundTrip(t *testing.T) {
+	c, err := NewCipher("secret")
+	if err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+	enc, err := c.Encrypt("p@ssw0rd")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if enc == "p@ssw0rd" {
+		t.Fatalf("ciphertext equals plaintext")
+	}
+	plain, err := c.Decrypt(enc)
+	if err != nil || plain != "p@ssw0rd" {
+		t.Fatalf("Decrypt = %q, %v", plain, err)
+	}
+
+	other, _ := NewCipher("other")
+	if _, err := other.Decrypt(enc); err == nil {
+		t.Fatalf("decrypt with another key should fail")
+	}
+	if enc, _ := c.Encrypt(""); enc != "" {
+		t.Fatalf("empty plaintext should stay empty, got %q", enc)
+	}
+}
+
+func newTestSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("NewSignerFromKey: %v", err)
+	}
+	return signer
+}
+
+func TestIssueCertificate(t *testing.T) {
+	ca := newTestSigner(t)
+	now := time.Now()
+	signer, cert, err := IssueCertificate(ca, "kubepolaris:user-1:10.0.0.1", []string{"root"}, 5*time.Minute, now)
+	if err != nil {
+		t.Fatalf("IssueCertificate: %v", err)
+	}
+	if cert.CertType != ssh.UserCert || cert.KeyId != "kubepolaris:user-1:10.0.0.1" {
+		t.Fatalf("unexpected cert: type=%d keyID=%s", cert.CertType, cert.KeyId)
+	}
+	if _, ok := signer.PublicKey().(*ssh.Certificate); !ok {
+		t.Fatalf("signer should present the certificate")
+	}
+
+	checker := &ssh.CertChecker{
+		IsUserAuthority: func(auth ssh.PublicKey) bool {
+			return string(auth.Marshal()) == string(ca.PublicKey().Marshal())
+		},
+		Clock: func() time.Time { return now },
+	}
+	if err := checker.CheckCert("root", cert); err != nil {
+		t.Fatalf("CheckCert: %v", err)
+	}
+	if err := checker.CheckCert("admin", cert); err == nil {
+		t.Fatalf("certificate should not be valid for other principals")
+	}
+	checker.Clock = func() time.Time { return now.Add(6 * time.Minute) }
+	if err := checker.CheckCert("root", cert); err == nil {
+		t.Fatalf("certificate should expire after ttl")
+	}
+}
+
+type memoryStore struct {
+	keys       map[string]KnownHost
+	mismatches int
+}
+
+func (m *memoryStore) LookupHostKey(_ uint, host string) (*KnownHost, error) {
+	if k, ok := m.keys[host]; ok {
+		return &k, nil
+	}
+	return nil, nil
+}
+
+func (m *memoryStore) TrustHostKey(_ uint, host, _ string, key ssh.PublicKey) error {
+	m.keys[host] = KnownHost{KeyType: key.Type(), Fingerprint: ssh.FingerprintSHA256(key)}
+	return nil
+}
+
+func (m *memoryStore) RecordHostKeyMismatch(uint, string, string, ssh.PublicKey) error {
+	m.mismatches++
+	return nil
+}
+
+func TestHostKeyCallbackTrustOnFirstUse(t *testing.T) {
+	store := &memoryStore{keys: map[string]KnownHost{}}
+	cb := HostKeyCallback(store, 1, "node-1")
+	first := newTestSigner(t).PublicKey()
+
+	if err := cb("10.0.0.1:22", nil, first); err != nil {
+		t.Fatalf("first connection should be trusted: %v", err)
+	}
+	if err := cb("10.0.0.1:22", nil, first); err != nil {
+		t.Fatalf("same key should be accepted: %v", err)
+	}
+
+	err := cb("10.0.0.1:22", nil, newTestSigner(t).PublicKey())
+	var mismatch *MismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("changed key should be rejected, got %v", err)
+	}
+	if mismatch.Expected != ssh.FingerprintSHA256(first) || store.mismatches != 1 {
+		t.Fatalf("unexpected mismatch record: %+v, count=%d", mismatch, store.mismatches)
+	}
+}
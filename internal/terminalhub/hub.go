@@ -0,0 +1,478 @@
+package terminalhub
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/errcode"
+)
+
+// 会话事件类型
+const (
+	EventJoin         = "join"          // 旁观者加入
+	EventLeave        = "leave"         // 旁观者离开
+	EventDriveRequest = "drive_request" // 申请协同操作
+	EventDriveGranted = "drive_granted" // 所有者同意协同操作
+	EventDriveDenied  = "drive_denied"  // 所有者拒绝协同操作
+	EventDriveRevoked = "drive_revoked" // 所有者收回协同操作
+	EventKill         = "kill"          // 管理员强制终止
+)
+
+// 旁观模式
+const (
+	ModeWatch = "watch" // 只读旁观
+	ModeDrive = "drive" // 申请协同操作（需所有者同意）
+)
+
+// 推送给旁观者的消息类型
+const (
+	MessageData   = "data"   // 终端输出
+	MessageResize = "resize" // 终端大小变化
+	MessageNotice = "notice" // 状态提示
+	MessageDrive  = "drive"  // 协同操作授权状态变化（data 为 granted/denied/revoked）
+	MessageClosed = "closed" // 会话已结束
+)
+
+const (
+	backlogSize     = 64 * 1024 // 旁观者加入时回放的最近输出字节数
+	watcherQueueLen = 256       // 旁观者消息队列长度，写满时丢弃（慢速旁观者不能阻塞终端）
+)
+
+// EventRecorder 会话事件记录回调，actorID 为空表示所有者或系统触发
+type EventRecorder func(sessionID uint, actorID *uint, event, detail string)
+
+// Owner 会话所有者一侧提供的回调
+type Owner struct {
+	// Input 写入终端输入，应与所有者输入走同一路径（含命令策略检查）
+	Input func(data string)
+	// Notify 向所有者终端发送提示消息
+	Notify func(msgType, data string)
+	// Kill 强制断开所有者连接
+	Kill func(reason string)
+}
+
+// SessionInfo 在线会话信息
+type SessionInfo struct {
+	SessionID  uint          `json:"session_id"`
+	UserID     uint          `json:"user_id"`
+	Username   string        `json:"username"`
+	ClusterID  uint          `json:"cluster_id"`
+	TargetType string        `json:"target_type"`
+	Target     string        `json:"target"`
+	StartAt    time.Time     `json:"start_at"`
+	Watchers   []WatcherInfo `json:"watchers"`
+}
+
+// WatcherInfo 旁观者信息
+type WatcherInfo struct {
+	ID       string    `json:"id"`
+	UserID   uint      `json:"user_id"`
+	Username string    `json:"username"`
+	Mode     string    `json:"mode"`
+	Driving  bool      `json:"driving"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// Message 推送给旁观者的消息
+type Message struct {
+	Type string `json:"type"`
+	Data string `json:"data,omitempty"`
+	Cols int    `json:"cols,omitempty"`
+	Rows int    `json:"rows,omitempty"`
+}
+
+// Hub 在线终端会话注册表
+type Hub struct {
+	mu       sync.RWMutex
+	sessions map[uint]*Session
+	record   EventRecorder
+	seq      uint64
+}
+
+// NewHub 创建在线会话注册表，record 可为空
+func NewHub(record EventRecorder) *Hub {
+	return &Hub{
+		sessions: make(map[uint]*Session),
+		record:   record,
+	}
+}
+
+// Register 注册在线会话，sessionID 为审计会话ID；hub 为空或 sessionID 为 0 时返回 nil
+func (h *Hub) Register(info SessionInfo, owner Owner) *Session {
+	if h == nil || info.SessionID == 0 {
+		return nil
+	}
+	if info.StartAt.IsZero() {
+		info.StartAt = time.Now()
+	}
+	s := &Session{
+		hub:      h,
+		info:     info,
+		owner:    owner,
+		watchers: make(map[string]*Watcher),
+	}
+	h.mu.Lock()
+	h.sessions[info.SessionID] = s
+	h.mu.Unlock()
+	return s
+}
+
+// Get 获取在线会话
+func (h *Hub) Get(sessionID uint) *Session {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.sessions[sessionID]
+}
+
+// List 列出在线会话（按开始时间倒序）
+func (h *Hub) List() []SessionInfo {
+	h.mu.RLock()
+	sessions := make([]*Session, 0, len(h.sessions))
+	for _, s := range h.sessions {
+		sessions = append(sessions, s)
+	}
+	h.mu.RUnlock()
+
+	list := make([]SessionInfo, 0, len(sessions))
+	for _, s := range sessions {
+		list = append(list, s.Info())
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].StartAt.After(list[j].StartAt) })
+	return list
+}
+
+// Kill 强制终止在线会话
+func (h *Hub) Kill(sessionID, actorID uint, actorName, reason string) error {
+	s := h.Get(sessionID)
+	if s == nil {
+		return errcode.New(errcode.SessionOffline)
+	}
+
+	s.mu.Lock()
+	if s.killed {
+		s.mu.Unlock()
+		return errcode.New(errcode.SessionKilling)
+	}
+	s.killed = true
+	s.mu.Unlock()
+
+	s.emit(&actorID, EventKill, fmt.Sprintf("%s 强制终止会话：%s", actorName, reason))
+	if s.owner.Kill != nil {
+		s.owner.Kill(reason)
+	}
+	return nil
+}
+
+func (h *Hub) nextWatcherID() string {
+	return strconv.FormatUint(atomic.AddUint64(&h.seq, 1), 10)
+}
+
+// Session 在线终端会话
+type Session struct {
+	hub   *Hub
+	info  SessionInfo
+	owner Owner
+
+	mu           sync.Mutex
+	watchers     map[string]*Watcher
+	backlog      []byte
+	cols, rows   int
+	pendingDrive *Watcher // 等待所有者确认的协同操作申请
+	killed       bool
+	closed       bool
+}
+
+// Info 获取会话信息
+func (s *Session) Info() SessionInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info := s.info
+	info.Watchers = make([]WatcherInfo, 0, len(s.watchers))
+	for _, w := range s.watchers {
+		info.Watchers = append(info.Watchers, w.info())
+	}
+	sort.Slice(info.Watchers, func(i, j int) bool { return info.Watchers[i].JoinedAt.Before(info.Watchers[j].JoinedAt) })
+	return info
+}
+
+// Broadcast 将终端输出分发给旁观者（与 Recorder.Record 同一位置调用）
+func (s *Session) Broadcast(p []byte) {
+	if s == nil || len(p) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.backlog = append(s.backlog, p...)
+	if len(s.backlog) > backlogSize {
+		s.backlog = append([]byte(nil), s.backlog[len(s.backlog)-backlogSize:]...)
+	}
+	msg := Message{Type: MessageData, Data: string(p)}
+	for _, w := range s.watchers {
+		w.push(msg)
+	}
+}
+
+// Resize 同步终端大小给旁观者
+func (s *Session) Resize(cols, rows int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cols, s.rows = cols, rows
+	for _, w := range s.watchers {
+		w.push(Message{Type: MessageResize, Cols: cols, Rows: rows})
+	}
+}
+
+// Close 注销会话并断开所有旁观者，返回会话是否被强制终止
+func (s *Session) Close() (killed bool) {
+	if s == nil {
+		return false
+	}
+	s.hub.mu.Lock()
+	if s.hub.sessions[s.info.SessionID] == s {
+		delete(s.hub.sessions, s.info.SessionID)
+	}
+	s.hub.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return s.killed
+	}
+	s.closed = true
+	for id, w := range s.watchers {
+		w.push(Message{Type: MessageClosed, Data: "会话已结束"})
+		w.close()
+		delete(s.watchers, id)
+	}
+	s.pendingDrive = nil
+	return s.killed
+}
+
+// Attach 旁观者加入会话，mode 为 drive 时向所有者发起协同操作申请
+func (s *Session) Attach(userID uint, username, mode string) (*Watcher, error) {
+	if mode != ModeWatch && mode != ModeDrive {
+		return nil, fmt.Errorf("不支持的旁观模式: %s", mode)
+	}
+	if userID == s.info.UserID {
+		return nil, errors.New("不能旁观自己的会话")
+	}
+
+	w := &Watcher{
+		ID:       s.hub.nextWatcherID(),
+		UserID:   userID,
+		Username: username,
+		Mode:     mode,
+		JoinedAt: time.Now(),
+		messages: make(chan Message, watcherQueueLen),
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, errors.New("会话已结束")
+	}
+	if s.cols > 0 && s.rows > 0 {
+		w.push(Message{Type: MessageResize, Cols: s.cols, Rows: s.rows})
+	}
+	if len(s.backlog) > 0 {
+		w.push(Message{Type: MessageData, Data: string(s.backlog)})
+	}
+	s.watchers[w.ID] = w
+	s.mu.Unlock()
+
+	s.emit(&userID, EventJoin, fmt.Sprintf("%s 以 %s 模式加入会话", username, mode))
+	s.notifyOwner("watch_joined", fmt.Sprintf("%s 正在旁观此终端", username))
+
+	if mode == ModeDrive {
+		if err := s.RequestDrive(w); err != nil {
+			s.send(w, Message{Type: MessageNotice, Data: err.Error()})
+		}
+	}
+	return w, nil
+}
+
+// Detach 旁观者离开会话
+func (s *Session) Detach(w *Watcher) {
+	s.mu.Lock()
+	if _, ok := s.watchers[w.ID]; !ok {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.watchers, w.ID)
+	if s.pendingDrive == w {
+		s.pendingDrive = nil
+	}
+	w.close()
+	s.mu.Unlock()
+
+	s.emit(&w.UserID, EventLeave, fmt.Sprintf("%s 离开会话", w.Username))
+	s.notifyOwner("watch_left", fmt.Sprintf("%s 已停止旁观此终端", w.Username))
+}
+
+// RequestDrive 旁观者申请协同操作，同一时间只允许一个待确认申请
+func (s *Session) RequestDrive(w *Watcher) error {
+	s.mu.Lock()
+	if w.driving {
+		s.mu.Unlock()
+		return errors.New("已获得协同操作权限")
+	}
+	if s.pendingDrive != nil && s.pendingDrive != w {
+		s.mu.Unlock()
+		return errors.New("已有待所有者确认的协同操作申请")
+	}
+	s.pendingDrive = w
+	w.push(Message{Type: MessageNotice, Data: "已向会话所有者发送协同操作申请，等待确认"})
+	s.mu.Unlock()
+
+	s.emit(&w.UserID, EventDriveRequest, fmt.Sprintf("%s 申请协同操作", w.Username))
+	s.notifyOwner("share_request", fmt.Sprintf("%s 申请协同操作此终端，回复 share_response（approve/deny）确认", w.Username))
+	return nil
+}
+
+// RespondDrive 所有者答复待确认的协同操作申请
+func (s *Session) RespondDrive(approve bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	w := s.pendingDrive
+	s.pendingDrive = nil
+	if w == nil {
+		s.mu.Unlock()
+		return
+	}
+	w.driving = approve
+	if approve {
+		w.push(Message{Type: MessageDrive, Data: "granted"})
+	} else {
+		w.push(Message{Type: MessageDrive, Data: "denied"})
+	}
+	s.mu.Unlock()
+
+	if approve {
+		s.emit(nil, EventDriveGranted, fmt.Sprintf("所有者同意 %s 协同操作", w.Username))
+	} else {
+		s.emit(nil, EventDriveDenied, fmt.Sprintf("所有者拒绝 %s 协同操作", w.Username))
+	}
+}
+
+// RevokeDrive 所有者收回全部协同操作权限
+func (s *Session) RevokeDrive() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	var revoked []*Watcher
+	for _, w := range s.watchers {
+		if w.driving {
+			w.driving = false
+			w.push(Message{Type: MessageDrive, Data: "revoked"})
+			revoked = append(revoked, w)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, w := range revoked {
+		s.emit(nil, EventDriveRevoked, fmt.Sprintf("所有者收回 %s 的协同操作权限", w.Username))
+	}
+}
+
+// Input 协同操作者写入终端输入
+func (s *Session) Input(w *Watcher, data string) error {
+	s.mu.Lock()
+	allowed := w.driving && !s.closed
+	s.mu.Unlock()
+	if !allowed {
+		return errors.New("未获得协同操作权限")
+	}
+	if s.owner.Input != nil {
+		s.owner.Input(data)
+	}
+	return nil
+}
+
+// NotifyWatcher 向旁观者发送状态提示
+func (s *Session) NotifyWatcher(w *Watcher, text string) {
+	s.send(w, Message{Type: MessageNotice, Data: text})
+}
+
+// send 向单个旁观者投递消息
+func (s *Session) send(w *Watcher, msg Message) {
+	s.mu.Lock()
+	w.push(msg)
+	s.mu.Unlock()
+}
+
+// notifyOwner 向所有者发送提示
+func (s *Session) notifyOwner(msgType, data string) {
+	if s.owner.Notify != nil {
+		s.owner.Notify(msgType, data)
+	}
+}
+
+// emit 记录会话事件
+func (s *Session) emit(actorID *uint, event, detail string) {
+	if s.hub.record != nil {
+		s.hub.record(s.info.SessionID, actorID, event, detail)
+	}
+}
+
+// Watcher 会话旁观者
+type Watcher struct {
+	ID       string
+	UserID   uint
+	Username string
+	Mode     string
+	JoinedAt time.Time
+
+	driving  bool // 是否已获得协同操作权限（受 Session.mu 保护）
+	messages chan Message
+	closed   bool
+}
+
+// Messages 旁观者消息队列，会话结束或离开后关闭
+func (w *Watcher) Messages() <-chan Message {
+	return w.messages
+}
+
+// push 投递消息，队列已满时丢弃（调用方持有 Session.mu）
+func (w *Watcher) push(msg Message) {
+	if w.closed {
+		return
+	}
+	select {
+	case w.messages <- msg:
+	default:
+	}
+}
+
+// close 关闭消息队列（调用方持有 Session.mu）
+func (w *Watcher) close() {
+	if !w.closed {
+		w.closed = true
+		close(w.messages)
+	}
+}
+
+// info 获取旁观者信息（调用方持有 Session.mu）
+func (w *Watcher) info() WatcherInfo {
+	return WatcherInfo{
+		ID:       w.ID,
+		UserID:   w.UserID,
+		Username: w.Username,
+		Mode:     w.Mode,
+		Driving:  w.driving,
+		JoinedAt: w.JoinedAt,
+	}
+}
@@ -0,0 +1,161 @@
+package terminalhub
+
+import (
+	"sync"
+	"testing"
+)
+
+type recordedEvent struct {
+	actorID *uint
+	event   string
+}
+
+func newTestHub() (*Hub, *[]recordedEvent) {
+	var mu sync.Mutex
+	events := &[]recordedEvent{}
+	hub := NewHub(func(sessionID uint, actorID *uint, event, detail string) {
+		mu.Lock()
+		defer mu.Unlock()
+		*events = append(*events, recordedEvent{actorID: actorID, event: event})
+	})
+	return hub, events
+}
+
+func drain(w *Watcher) []Message {
+	var msgs []Message
+	for {
+		select {
+		case msg, ok := <-w.Messages():
+			if !ok {
+				return msgs
+			}
+			msgs = append(msgs, msg)
+		default:
+			return msgs
+		}
+	}
+}
+
+func TestWatcherReceivesBacklogAndOutput(t *testing.T) {
+	hub, events := newTestHub()
+	s := hub.Register(SessionInfo{SessionID: 1, UserID: 10}, Owner{})
+	s.Resize(100, 40)
+	s.Broadcast([]byte("$ ls\r\n"))
+
+	if _, err := s.Attach(10, "owner", ModeWatch); err == nil {
+		t.Fatal("owner should not be able to watch own session")
+	}
+	w, err := s.Attach(20, "admin", ModeWatch)
+	if err != nil {
+		t.Fatalf("attach failed: %v", err)
+	}
+	s.Broadcast([]byte("file\r\n"))
+
+	msgs := drain(w)
+	if len(msgs) != 3 || msgs[0].Type != MessageResize || msgs[1].Data != "$ ls\r\n" || msgs[2].Data != "file\r\n" {
+		t.Fatalf("unexpected messages: %+v", msgs)
+	}
+	if err := s.Input(w, "whoami\r"); err == nil {
+		t.Fatal("watch-only watcher should not be able to send input")
+	}
+
+	if infos := hub.List(); len(infos) != 1 || len(infos[0].Watchers) != 1 {
+		t.Fatalf("unexpected live sessions: %+v", infos)
+	}
+
+	s.Detach(w)
+	if _, ok := <-w.Messages(); ok {
+		t.Fatal("watcher queue should be closed after detach")
+	}
+	if len(*events) != 2 || (*events)[0].event != EventJoin || (*events)[1].event != EventLeave {
+		t.Fatalf("unexpected events: %+v", *events)
+	}
+}
+
+func TestDriveRequiresOwnerConsent(t *testing.T) {
+	hub, events := newTestHub()
+	var inputs, notices []string
+	s := hub.Register(SessionInfo{SessionID: 2, UserID: 10}, Owner{
+		Input:  func(data string) { inputs = append(inputs, data) },
+		Notify: func(msgType, data string) { notices = append(notices, msgType) },
+	})
+
+	w, err := s.Attach(20, "admin", ModeDrive)
+	if err != nil {
+		t.Fatalf("attach failed: %v", err)
+	}
+	if err := s.Input(w, "ls\r"); err == nil {
+		t.Fatal("input should be rejected before owner consent")
+	}
+	other, _ := s.Attach(30, "auditor", ModeWatch)
+	if err := s.RequestDrive(other); err == nil {
+		t.Fatal("second pending drive request should be rejected")
+	}
+
+	s.RespondDrive(true)
+	if err := s.Input(w, "ls\r"); err != nil {
+		t.Fatalf("input should be accepted after consent: %v", err)
+	}
+	if len(inputs) != 1 || inputs[0] != "ls\r" {
+		t.Fatalf("owner input callback not called: %v", inputs)
+	}
+
+	s.RevokeDrive()
+	if err := s.Input(w, "ls\r"); err == nil {
+		t.Fatal("input should be rejected after revoke")
+	}
+	if notices[1] != "share_request" {
+		t.Fatalf("owner should be asked for consent, got %v", notices)
+	}
+
+	var got []string
+	for _, e := range *events {
+		got = append(got, e.event)
+	}
+	want := []string{EventJoin, EventDriveRequest, EventJoin, EventDriveGranted, EventDriveRevoked}
+	if len(got) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected events %v, got %v", want, got)
+		}
+	}
+}
+
+func TestKillSession(t *testing.T) {
+	hub, events := newTestHub()
+	var killedReason string
+	s := hub.Register(SessionInfo{SessionID: 3, UserID: 10}, Owner{
+		Kill: func(reason string) { killedReason = reason },
+	})
+	w, _ := s.Attach(20, "admin", ModeWatch)
+
+	if err := hub.Kill(99, 1, "root", "x"); err == nil {
+		t.Fatal("killing an offline session should fail")
+	}
+	if err := hub.Kill(3, 1, "root", "policy violation"); err != nil {
+		t.Fatalf("kill failed: %v", err)
+	}
+	if killedReason != "policy violation" {
+		t.Fatalf("owner kill callback not called, got %q", killedReason)
+	}
+	if err := hub.Kill(3, 1, "root", "again"); err == nil {
+		t.Fatal("second kill should be rejected")
+	}
+
+	if !s.Close() {
+		t.Fatal("close should report the session was killed")
+	}
+	if hub.Get(3) != nil {
+		t.Fatal("closed session should be unregistered")
+	}
+	msgs := drain(w)
+	if len(msgs) == 0 || msgs[len(msgs)-1].Type != MessageClosed {
+		t.Fatalf("watcher should be told the session closed, got %+v", msgs)
+	}
+	last := (*events)[len(*events)-1]
+	if last.event != EventKill || last.actorID == nil || *last.actorID != 1 {
+		t.Fatalf("kill should be recorded with actor, got %+v", last)
+	}
+}
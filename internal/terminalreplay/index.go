@@ -0,0 +1,153 @@
+package terminalreplay
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/asciinema"
+)
+
+// maxIndexedOutput caps the output stored per command.
+const maxIndexedOutput = 64 * 1024
+
+// IndexFile parses a finished .cast.gz replay into command index rows.
+func IndexFile(path string) ([]models.TerminalReplayIndex, error) {
+	f, err := os.Open(path) // #nosec G304 -- path is built from the configured replay dir
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return IndexGzip(f)
+}
+
+// IndexGzip parses a gzipped asciicast stream into command index rows.
+func IndexGzip(r io.Reader) ([]models.TerminalReplayIndex, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return BuildIndex(zr)
+}
+
+// BuildIndex parses an asciicast v2 stream into command index rows.
+// Commands are rebuilt from "i" events (backspace, Ctrl+U, Ctrl+C and escape
+// sequences are honoured); output until the next command is attached to the
+// preceding command with control sequences stripped. Output before the first
+// command is kept as Seq 0 with an empty command.
+func BuildIndex(r io.Reader) ([]models.TerminalReplayIndex, error) {
+	reader, err := asciinema.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		entries []models.TerminalReplayIndex
+		line    []rune
+		output  strings.Builder
+		current = models.TerminalReplayIndex{Seq: 0}
+	)
+	flush := func() {
+		current.Output = truncateUTF8(CleanOutput(output.String()), maxIndexedOutput)
+		if current.Command != "" || strings.TrimSpace(current.Output) != "" {
+			entries = append(entries, current)
+		}
+		output.Reset()
+	}
+
+	for {
+		ev, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch ev.Type {
+		case asciinema.EventOutput:
+			if output.Len() < maxIndexedOutput*2 {
+				output.WriteString(ev.Data)
+			}
+		case asciinema.EventInput:
+			data := ev.Data
+			for i := 0; i < len(data); i++ {
+				c := data[i]
+				switch {
+				case c == '\r' || c == '\n':
+					command := strings.TrimSpace(string(line))
+					line = line[:0]
+					if command == "" || strings.Trim(command, "*") == "" {
+						continue // 空行或被掩码的密码
+					}
+					flush()
+					current = models.TerminalReplayIndex{
+						Seq:     current.Seq + 1,
+						Offset:  ev.Time,
+						Command: truncateUTF8(command, 1024),
+					}
+				case c == '\x7f' || c == '\b':
+					if len(line) > 0 {
+						line = line[:len(line)-1]
+					}
+				case c == '\x15' || c == '\x03':
+					line = line[:0]
+				case c == '\x1b':
+					i = skipEscape(data, i)
+				case c >= utf8.RuneSelf:
+					r, size := utf8.DecodeRuneInString(data[i:])
+					line = append(line, r)
+					i += size - 1
+				case c >= 32:
+					line = append(line, rune(c))
+				}
+			}
+		}
+	}
+	flush()
+	return entries, nil
+}
+
+// CleanOutput strips escape sequences and carriage returns from terminal output.
+func CleanOutput(s string) string {
+	s = ansiPattern.ReplaceAllString(s, "")
+	var b strings.Builder
+	for _, r := range s {
+		if r == '\n' || r == '\t' || r >= 32 && r != 0x7f {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// skipEscape returns the index of the last byte of the escape sequence starting at i.
+func skipEscape(s string, i int) int {
+	j := i + 1
+	if j < len(s) && (s[j] == '[' || s[j] == 'O') {
+		j++
+	}
+	for j < len(s) {
+		c := s[j]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '~' {
+			return j
+		}
+		j++
+	}
+	return len(s) - 1
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a rune.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
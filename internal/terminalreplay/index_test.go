@@ -0,0 +1,58 @@
+package terminalreplay
+
+import (
+	"strings"
+	"testing"
+)
+
+const testCast = `{"version":2,"width":80,"height":24}
+[0.1,"o","Last login\r\n$ "]
+[1.0,"i","lx\u007fs -l"]
+[1.5,"i","\r"]
+[1.6,"o","\u001b[1mtotal 0\u001b[0m\r\n$ "]
+[2.0,"i","sudo su\r"]
+[2.1,"o","[sudo] password for dev: "]
+[2.5,"i","****\r"]
+[3.0,"i","\u001b[Aabc\u0015whoami\r"]
+[3.1,"o","root\r\n"]
+`
+
+func TestBuildIndex(t *testing.T) {
+	entries, err := BuildIndex(strings.NewReader(testCast))
+	if err != nil {
+		t.Fatalf("build index failed: %v", err)
+	}
+	want := []string{"", "ls -l", "sudo su", "whoami"}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), entries)
+	}
+	for i, cmd := range want {
+		if entries[i].Command != cmd || entries[i].Seq != i {
+			t.Fatalf("entry %d: expected command %q, got %+v", i, cmd, entries[i])
+		}
+	}
+	if entries[1].Offset != 1.5 || entries[1].Output != "total 0\n$ " {
+		t.Fatalf("unexpected entry: %+v", entries[1])
+	}
+	if !strings.Contains(entries[2].Output, "password for dev") || entries[3].Output != "root\n" {
+		t.Fatalf("unexpected output: %+v", entries)
+	}
+}
+
+func TestSecretInputMasking(t *testing.T) {
+	if !IsSecretPrompt([]byte("\x1b[0m[sudo] password for dev: ")) {
+		t.Fatal("sudo prompt should be detected")
+	}
+	if IsSecretPrompt([]byte("password changed\r\n$ ")) {
+		t.Fatal("shell prompt should not be detected as secret prompt")
+	}
+
+	masked, more := MaskSecretInput([]byte("s3cr"))
+	if string(masked) != "****" || !more {
+		t.Fatalf("unexpected mask result: %q, %v", masked, more)
+	}
+	masked, more = MaskSecretInput([]byte("et\rls\r"))
+	if string(masked) != "**\rls\r" || more {
+		t.Fatalf("masking should stop at Enter, got %q, %v", masked, more)
+	}
+}
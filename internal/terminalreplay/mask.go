@@ -0,0 +1,52 @@
+package terminalreplay
+
+import (
+	"regexp"
+	"unicode/utf8"
+)
+
+// outputTailSize is how much trailing output is kept to detect secret prompts.
+const outputTailSize = 256
+
+// secretPromptPattern matches common no-echo prompts at the end of the output,
+// e.g. "Password:", "[sudo] password for root:", "Enter passphrase for key '...':".
+var secretPromptPattern = regexp.MustCompile(`(?i)(password|passphrase|passcode|pin|token|secret)[^\r\n:]{0,80}:\s*$`)
+
+// ansiPattern matches CSI / OSC escape sequences.
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07]*\x07|\x1b[()][A-Za-z0-9]`)
+
+// appendTail keeps the last outputTailSize bytes of output.
+func appendTail(tail, p []byte) []byte {
+	tail = append(tail, p...)
+	if len(tail) > outputTailSize {
+		tail = append(tail[:0], tail[len(tail)-outputTailSize:]...)
+	}
+	return tail
+}
+
+// IsSecretPrompt reports whether output ends with a prompt whose answer is not echoed.
+func IsSecretPrompt(output []byte) bool {
+	return secretPromptPattern.Match(ansiPattern.ReplaceAll(output, nil))
+}
+
+// MaskSecretInput replaces printable input with '*'. Control characters are kept
+// so the replay still shows Enter / Ctrl+C. The returned flag reports whether
+// masking should continue (false once Enter or Ctrl+C is seen).
+func MaskSecretInput(p []byte) ([]byte, bool) {
+	masked := make([]byte, 0, len(p))
+	for len(p) > 0 {
+		r, size := utf8.DecodeRune(p)
+		p = p[size:]
+		switch {
+		case r == '\r' || r == '\n' || r == '\x03':
+			masked = append(masked, byte(r))
+			// everything after the end of the secret is recorded as typed
+			return append(masked, p...), false
+		case r < 32 || r == 0x7f:
+			masked = append(masked, byte(r))
+		default:
+			masked = append(masked, '*')
+		}
+	}
+	return masked, true
+}
@@ -2,8 +2,12 @@ package terminalreplay
 
 import (
 	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
 	"sync"
@@ -14,34 +18,38 @@ import (
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
 )
 
-// Recorder writes asciicast v2 to disk, gzips on close, and updates audit DB.
+// Recorder spools asciicast v2 to local disk, then gzips it, uploads it to the
+// replay storage and records its key, size and SHA-256 in the audit DB on End.
 type Recorder struct {
 	mu         sync.Mutex
 	sessionID  uint
 	audit      *services.AuditService
-	root       string
+	storage    *Storage
 	absCast    string
-	absGz      string
-	relGz      string
+	key        string
 	writer     *asciinema.Writer
 	file       *os.File
 	started    bool
 	headerOnce sync.Once
 	startTime  time.Time
 	closed     bool
+
+	// secret masking: set when the latest output looks like a no-echo prompt
+	// (password, passphrase, ...), cleared on Enter.
+	outputTail []byte
+	masking    bool
 }
 
-// NewRecorder creates a recorder for a DB terminal session row. replayRoot empty disables recording (returns nil).
-func NewRecorder(replayRoot string, audit *services.AuditService, sessionID uint, cols, rows int) (*Recorder, error) {
-	if replayRoot == "" || audit == nil || sessionID == 0 {
+// NewRecorder creates a recorder for a DB terminal session row. A nil storage disables recording (returns nil).
+func NewRecorder(storage *Storage, audit *services.AuditService, sessionID uint, cols, rows int) (*Recorder, error) {
+	if storage == nil || audit == nil || sessionID == 0 {
 		return nil, nil
 	}
-	day := time.Now().UTC().Format("2006-01-02")
-	dir := filepath.Join(replayRoot, day)
-	if err := os.MkdirAll(dir, 0750); err != nil {
+	if err := os.MkdirAll(storage.spoolDir, 0750); err != nil {
 		return nil, err
 	}
-	name := filepath.Join(dir, strconv.FormatUint(uint64(sessionID), 10)+".cast")
+	id := strconv.FormatUint(uint64(sessionID), 10)
+	name := filepath.Join(storage.spoolDir, id+".cast")
 	f, err := os.Create(name)
 	if err != nil {
 		return nil, err
@@ -52,14 +60,12 @@ func NewRecorder(replayRoot string, audit *services.AuditService, sessionID uint
 		asciinema.WithHeight(rows),
 		asciinema.WithTimestamp(start),
 	)
-	rel := filepath.Join(day, strconv.FormatUint(uint64(sessionID), 10)+".cast.gz")
 	return &Recorder{
 		sessionID: sessionID,
 		audit:     audit,
-		root:      replayRoot,
+		storage:   storage,
 		absCast:   name,
-		absGz:     name + ".gz",
-		relGz:     rel,
+		key:       path.Join(start.UTC().Format("2006-01-02"), id+".cast.gz"),
 		writer:    w,
 		file:      f,
 		startTime: start,
@@ -85,6 +91,37 @@ func (r *Recorder) Record(p []byte) {
 	if err := r.writer.WriteRow(p); err != nil {
 		logger.Error("replay: write row failed", "error", err)
 	}
+	r.outputTail = appendTail(r.outputTail, p)
+	if IsSecretPrompt(r.outputTail) {
+		r.masking = true
+	}
+}
+
+// RecordInput appends client input (keystrokes sent to the PTY) to the cast as "i" events.
+// Input typed at a no-echo prompt is masked with '*' up to and including Enter.
+func (r *Recorder) RecordInput(p []byte) {
+	if r == nil || len(p) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.headerOnce.Do(func() {
+		if err := r.writer.WriteHeader(); err != nil {
+			logger.Error("replay: write header failed", "error", err)
+		}
+		r.started = true
+	})
+	data := p
+	if r.masking {
+		data, r.masking = MaskSecretInput(p)
+		r.outputTail = r.outputTail[:0]
+	}
+	if err := r.writer.WriteInputRow(data); err != nil {
+		logger.Error("replay: write input row failed", "error", err)
+	}
 }
 
 // Resize records a terminal resize event.
@@ -110,7 +147,7 @@ func (r *Recorder) Resize(cols, rows int) {
 	}
 }
 
-// End closes the cast file, gzips, updates DB, and removes temp files.
+// End closes the cast file, gzips and uploads it, updates DB, and removes spool files.
 func (r *Recorder) End() {
 	if r == nil {
 		return
@@ -125,41 +162,86 @@ func (r *Recorder) End() {
 		_ = r.file.Close()
 		r.file = nil
 	}
+	defer os.Remove(r.absCast)
 	if !r.started {
-		_ = os.Remove(r.absCast)
 		return
 	}
-	if err := gzipFile(r.absCast, r.absGz); err != nil {
-		logger.Error("replay: gzip failed", "error", err)
-		_ = os.Remove(r.absCast)
+	gzPath := r.absCast + ".gz"
+	size, digest, err := gzipFile(r.absCast, gzPath)
+	if err != nil {
+		logger.Error("replay: gzip failed: session=%d, err=%v", r.sessionID, err)
+		_ = os.Remove(gzPath)
+		return
+	}
+	if err := r.upload(gzPath, size); err != nil {
+		logger.Error("replay: upload failed: session=%d, key=%s, err=%v", r.sessionID, r.key, err)
+		_ = os.Remove(gzPath)
 		return
 	}
-	_ = os.Remove(r.absCast)
-	info, err := os.Stat(r.absGz)
+	if err := r.audit.SetSessionReplayReady(r.sessionID, r.key, size, digest); err != nil {
+		logger.Error("replay: update DB failed: session=%d, err=%v", r.sessionID, err)
+	}
+	go func() {
+		defer os.Remove(gzPath)
+		IndexSession(r.audit, r.sessionID, gzPath)
+	}()
+}
+
+func (r *Recorder) upload(gzPath string, size int64) error {
+	f, err := os.Open(gzPath)
 	if err != nil {
-		logger.Error("replay: stat gz failed", "error", err)
+		return err
+	}
+	defer f.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	return r.storage.store.Put(ctx, r.key, f, size)
+}
+
+// IndexSession parses a finished replay and replaces the session's command index.
+func IndexSession(audit *services.AuditService, sessionID uint, gzPath string) {
+	entries, err := IndexFile(gzPath)
+	if err != nil {
+		logger.Error("replay: build index failed: session=%d, err=%v", sessionID, err)
 		return
 	}
-	if err := r.audit.SetSessionReplayReady(r.sessionID, r.relGz, info.Size()); err != nil {
-		logger.Error("replay: update DB failed", "error", err)
+	if err := audit.SaveReplayIndex(sessionID, entries); err != nil {
+		logger.Error("replay: save index failed: session=%d, err=%v", sessionID, err)
 	}
 }
 
-func gzipFile(src, dst string) error {
+// gzipFile compresses src into dst and returns the size and SHA-256 of dst.
+func gzipFile(src, dst string) (int64, string, error) {
 	in, err := os.Open(src)
 	if err != nil {
-		return err
+		return 0, "", err
 	}
 	defer in.Close()
 	out, err := os.Create(dst)
 	if err != nil {
-		return err
+		return 0, "", err
 	}
 	defer out.Close()
-	zw := gzip.NewWriter(out)
+	h := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(out, h)}
+	zw := gzip.NewWriter(counter)
 	if _, err := io.Copy(zw, in); err != nil {
 		_ = zw.Close()
-		return err
+		return 0, "", err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, "", err
 	}
-	return zw.Close()
+	return counter.n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
 }
@@ -0,0 +1,180 @@
+package terminalreplay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+	"github.com/clay-wangzhi/KubePolaris/pkg/objectstore"
+)
+
+// ErrIntegrity is returned when a stored replay does not match its recorded size or SHA-256.
+var ErrIntegrity = errors.New("replay integrity check failed")
+
+// Storage keeps finished replays in an object store. Casts being recorded are
+// spooled to a local directory and uploaded (gzipped) when the session ends.
+type Storage struct {
+	spoolDir string
+	store    objectstore.Store
+}
+
+// NewStorage returns replay storage using store for finished replays and
+// spoolDir for in-progress casts.
+func NewStorage(spoolDir string, store objectstore.Store) *Storage {
+	return &Storage{spoolDir: spoolDir, store: store}
+}
+
+// NewStorageFromConfig builds replay storage from terminal config.
+// It returns nil (recording disabled) when ReplayDir is empty.
+func NewStorageFromConfig(cfg config.TerminalConfig) (*Storage, error) {
+	if cfg.ReplayDir == "" {
+		return nil, nil
+	}
+	switch cfg.ReplayStorage {
+	case "", "local":
+		return NewStorage(filepath.Join(cfg.ReplayDir, ".spool"), objectstore.NewLocal(cfg.ReplayDir)), nil
+	case "s3":
+		store, err := objectstore.NewS3(objectstore.S3Config{
+			Endpoint:  cfg.ReplayS3.Endpoint,
+			Region:    cfg.ReplayS3.Region,
+			Bucket:    cfg.ReplayS3.Bucket,
+			AccessKey: cfg.ReplayS3.AccessKey,
+			SecretKey: cfg.ReplayS3.SecretKey,
+			Prefix:    cfg.ReplayS3.Prefix,
+			PathStyle: cfg.ReplayS3.PathStyle,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return NewStorage(filepath.Join(cfg.ReplayDir, ".spool"), store), nil
+	default:
+		return nil, fmt.Errorf("unknown replay storage %q", cfg.ReplayStorage)
+	}
+}
+
+// Open downloads the replay stored under key into a spool file, verifies its
+// size and SHA-256 (when digest is set) and returns it positioned at the start.
+// The spool file is removed when the returned reader is closed.
+func (s *Storage) Open(ctx context.Context, key string, size int64, digest string) (io.ReadCloser, error) {
+	rc, err := s.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	if err := os.MkdirAll(s.spoolDir, 0750); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(s.spoolDir, "download-*.cast.gz")
+	if err != nil {
+		return nil, err
+	}
+	f := &tempFile{File: tmp}
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), rc)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if digest != "" && (n != size || hex.EncodeToString(h.Sum(nil)) != digest) {
+		f.Close()
+		return nil, ErrIntegrity
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// Delete removes a stored replay.
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	return s.store.Delete(ctx, key)
+}
+
+// tempFile is a file that is removed on Close.
+type tempFile struct {
+	*os.File
+}
+
+func (f *tempFile) Close() error {
+	err := f.File.Close()
+	_ = os.Remove(f.Name())
+	return err
+}
+
+// RetentionPolicy limits how long and how much replay data is kept. Zero disables a limit.
+type RetentionPolicy struct {
+	MaxAge        time.Duration
+	MaxTotalBytes int64
+}
+
+// SelectExpired returns the sessions whose replays fall outside the policy.
+// sessions must be ordered newest first; the newest replays are kept within MaxTotalBytes.
+func SelectExpired(sessions []models.TerminalSession, policy RetentionPolicy, now time.Time) []models.TerminalSession {
+	var expired []models.TerminalSession
+	var total int64
+	for _, s := range sessions {
+		if policy.MaxAge > 0 && s.StartAt.Before(now.Add(-policy.MaxAge)) {
+			expired = append(expired, s)
+			continue
+		}
+		total += s.ReplaySize
+		if policy.MaxTotalBytes > 0 && total > policy.MaxTotalBytes {
+			expired = append(expired, s)
+		}
+	}
+	return expired
+}
+
+// ApplyRetention deletes replays outside the policy and clears them from the audit DB.
+func (s *Storage) ApplyRetention(ctx context.Context, audit *services.AuditService, policy RetentionPolicy) (int, error) {
+	if policy.MaxAge <= 0 && policy.MaxTotalBytes <= 0 {
+		return 0, nil
+	}
+	sessions, err := audit.ListStoredReplays()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, sess := range SelectExpired(sessions, policy, time.Now()) {
+		if err := s.store.Delete(ctx, sess.ReplayPath); err != nil {
+			logger.Error("replay: retention delete failed: session=%d, key=%s, err=%v", sess.ID, sess.ReplayPath, err)
+			continue
+		}
+		if err := audit.ClearSessionReplay(sess.ID); err != nil {
+			logger.Error("replay: retention update DB failed: session=%d, err=%v", sess.ID, err)
+			continue
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// StartRetentionWorker periodically applies the retention policy.
+func (s *Storage) StartRetentionWorker(audit *services.AuditService, policy RetentionPolicy, interval time.Duration) {
+	if policy.MaxAge <= 0 && policy.MaxTotalBytes <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for ; ; <-ticker.C {
+		n, err := s.ApplyRetention(context.Background(), audit, policy)
+		if err != nil {
+			logger.Error("replay: retention failed: %v", err)
+		} else if n > 0 {
+			logger.Info("replay: retention removed %d replays", n)
+		}
+	}
+}
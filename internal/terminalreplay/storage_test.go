@@ -0,0 +1,97 @@
+package terminalreplay
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/objectstore"
+)
+
+func TestStorageOpenVerifiesDigest(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewStorage(filepath.Join(dir, ".spool"), objectstore.NewLocal(dir))
+	ctx := context.Background()
+
+	data := []byte("gzipped cast")
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	if err := storage.store.Put(ctx, "2026-04-17/1.cast.gz", bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	rc, err := storage.Open(ctx, "2026-04-17/1.cast.gz", int64(len(data)), digest)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	got, _ := io.ReadAll(rc)
+	rc.Close()
+	if !bytes.Equal(got, data) {
+		t.Fatalf("expected %q, got %q", data, got)
+	}
+
+	// tamper with the stored file
+	if err := os.WriteFile(filepath.Join(dir, "2026-04-17", "1.cast.gz"), []byte("gzipped cas7"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storage.Open(ctx, "2026-04-17/1.cast.gz", int64(len(data)), digest); !errors.Is(err, ErrIntegrity) {
+		t.Fatalf("expected ErrIntegrity, got %v", err)
+	}
+	// legacy replays without digest are served unverified
+	if rc, err := storage.Open(ctx, "2026-04-17/1.cast.gz", 0, ""); err != nil {
+		t.Fatalf("open legacy replay: %v", err)
+	} else {
+		rc.Close()
+	}
+
+	spool, _ := os.ReadDir(filepath.Join(dir, ".spool"))
+	if len(spool) != 0 {
+		t.Fatalf("spool files should be removed, found %d", len(spool))
+	}
+}
+
+func TestSelectExpired(t *testing.T) {
+	now := time.Now()
+	sessions := []models.TerminalSession{
+		{ID: 4, StartAt: now.Add(-time.Hour), ReplaySize: 40},
+		{ID: 3, StartAt: now.Add(-2 * time.Hour), ReplaySize: 40},
+		{ID: 2, StartAt: now.Add(-3 * time.Hour), ReplaySize: 40},
+		{ID: 1, StartAt: now.Add(-48 * time.Hour), ReplaySize: 10},
+	}
+
+	ids := func(list []models.TerminalSession) []uint {
+		var out []uint
+		for _, s := range list {
+			out = append(out, s.ID)
+		}
+		return out
+	}
+	cases := []struct {
+		policy RetentionPolicy
+		want   []uint
+	}{
+		{RetentionPolicy{}, nil},
+		{RetentionPolicy{MaxAge: 24 * time.Hour}, []uint{1}},
+		{RetentionPolicy{MaxTotalBytes: 100}, []uint{2, 1}},
+		{RetentionPolicy{MaxAge: 24 * time.Hour, MaxTotalBytes: 80}, []uint{2, 1}},
+	}
+	for i, tc := range cases {
+		got := ids(SelectExpired(sessions, tc.policy, now))
+		if len(got) != len(tc.want) {
+			t.Fatalf("case %d: expected %v, got %v", i, tc.want, got)
+		}
+		for j := range got {
+			if got[j] != tc.want[j] {
+				t.Fatalf("case %d: expected %v, got %v", i, tc.want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package asciinema
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Event types defined by asciicast v2.
+const (
+	EventOutput = "o"
+	EventInput  = "i"
+	EventResize = "r"
+)
+
+// Header is the decoded asciicast v2 header line.
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Event is one decoded event line [time, type, data].
+type Event struct {
+	Time float64
+	Type string
+	Data string
+}
+
+// Reader decodes asciicast v2 (newline-delimited JSON).
+type Reader struct {
+	scanner *bufio.Scanner
+	Header  Header
+}
+
+// NewReader reads the header line and returns a reader positioned at the first event.
+func NewReader(r io.Reader) (*Reader, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("asciicast: missing header")
+	}
+	var header Header
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return nil, fmt.Errorf("asciicast: invalid header: %w", err)
+	}
+	if header.Version != version {
+		return nil, fmt.Errorf("asciicast: unsupported version %d", header.Version)
+	}
+	return &Reader{scanner: scanner, Header: header}, nil
+}
+
+// Next returns the next event, or io.EOF when the cast is exhausted.
+func (r *Reader) Next() (Event, error) {
+	for r.scanner.Scan() {
+		line := r.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var row []interface{}
+		if err := json.Unmarshal(line, &row); err != nil {
+			return Event{}, fmt.Errorf("asciicast: invalid event: %w", err)
+		}
+		if len(row) != 3 {
+			return Event{}, fmt.Errorf("asciicast: invalid event length %d", len(row))
+		}
+		ts, ok1 := row[0].(float64)
+		typ, ok2 := row[1].(string)
+		data, ok3 := row[2].(string)
+		if !ok1 || !ok2 || !ok3 {
+			return Event{}, errors.New("asciicast: invalid event fields")
+		}
+		return Event{Time: ts, Type: typ, Data: data}, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Event{}, err
+	}
+	return Event{}, io.EOF
+}
@@ -123,6 +123,27 @@ func (w *Writer) WriteRow(p []byte) error {
 	return w.WriteStdout(ts, p)
 }
 
+// WriteStdin appends one input event [t, "i", data] with relative time in seconds.
+func (w *Writer) WriteStdin(ts float64, data []byte) error {
+	row := []interface{}{ts, "i", string(data)}
+	raw, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	if _, err := w.writer.Write(raw); err != nil {
+		return err
+	}
+	_, err = w.writer.Write(newline)
+	return err
+}
+
+// WriteInputRow writes input using elapsed time since TimestampNS.
+func (w *Writer) WriteInputRow(p []byte) error {
+	now := time.Now().UnixNano()
+	ts := float64(now-w.TimestampNS) / 1e9
+	return w.WriteStdin(ts, p)
+}
+
 // WriteResize emits a resize event [t, "r", "COLSxROWS"] per asciicast v2.
 func (w *Writer) WriteResize(ts float64, cols, rows int) error {
 	row := []interface{}{ts, "r", fmtDim(cols, rows)}
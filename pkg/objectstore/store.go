@@ -0,0 +1,120 @@
+// Package objectstore provides a minimal blob store abstraction with a local
+// filesystem and an S3-compatible implementation.
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned by Get when the object does not exist.
+var ErrNotFound = errors.New("objectstore: object not found")
+
+// Store is a flat key/value blob store. Keys are slash-separated relative paths.
+type Store interface {
+	// Put stores size bytes read from r under key, replacing any existing object.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Get opens the object stored under key. It returns ErrNotFound if it does not exist.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// CleanKey validates a key and returns its canonical form.
+// Absolute keys and keys escaping the store root ("..") are rejected.
+func CleanKey(key string) (string, error) {
+	key = strings.ReplaceAll(key, "\\", "/")
+	if key == "" || strings.HasPrefix(key, "/") {
+		return "", fmt.Errorf("objectstore: invalid key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("objectstore: invalid key %q", key)
+		}
+	}
+	cleaned := path.Clean(key)
+	if cleaned == "." {
+		return "", fmt.Errorf("objectstore: invalid key %q", key)
+	}
+	return cleaned, nil
+}
+
+// Local stores objects as files under a root directory.
+type Local struct {
+	root string
+}
+
+// NewLocal returns a store rooted at dir.
+func NewLocal(dir string) *Local {
+	return &Local{root: filepath.Clean(dir)}
+}
+
+func (l *Local) path(key string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(l.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes the object to a temporary file and renames it into place.
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	full, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(full), 0750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(full), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if size >= 0 && n != size {
+		return fmt.Errorf("objectstore: short write for %q: %d of %d bytes", key, n, size)
+	}
+	return os.Rename(tmp.Name(), full)
+}
+
+// Get opens the file for key.
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	full, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(full) // #nosec G304 -- key is validated by CleanKey
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Delete removes the file for key.
+func (l *Local) Delete(ctx context.Context, key string) error {
+	full, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(full); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
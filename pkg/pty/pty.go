@@ -0,0 +1,7 @@
+// Package pty starts processes attached to a pseudo-terminal.
+package pty
+
+import "errors"
+
+// ErrUnsupported is returned on platforms without pseudo-terminal support.
+var ErrUnsupported = errors.New("pty: unsupported platform")
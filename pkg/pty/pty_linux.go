@@ -0,0 +1,113 @@
+//go:build linux
+
+package pty
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Start starts cmd in a new session with stdin, stdout and stderr attached to a
+// new pseudo-terminal of the given size, and returns the master side.
+// The caller must close the returned file and wait for cmd.
+func Start(cmd *exec.Cmd, cols, rows uint16) (*os.File, error) {
+	return start(cmd, cols, rows, nil)
+}
+
+// StartAs is like Start but runs cmd as the given user and group, with no
+// supplementary groups. The terminal is handed over to that user so the
+// process can reopen it. Switching users requires root or CAP_SETUID and
+// CAP_SETGID.
+func StartAs(cmd *exec.Cmd, cols, rows uint16, uid, gid uint32) (*os.File, error) {
+	return start(cmd, cols, rows, &syscall.Credential{Uid: uid, Gid: gid, Groups: []uint32{}})
+}
+
+func start(cmd *exec.Cmd, cols, rows uint16, cred *syscall.Credential) (*os.File, error) {
+	ptmx, tty, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer tty.Close()
+
+	if err := Setsize(ptmx, cols, rows); err != nil {
+		ptmx.Close()
+		return nil, err
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = tty, tty, tty
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+	cmd.SysProcAttr.Ctty = 0 // child's stdin
+	if cred != nil {
+		if err := tty.Chown(int(cred.Uid), int(cred.Gid)); err != nil {
+			ptmx.Close()
+			return nil, err
+		}
+		cmd.SysProcAttr.Credential = cred
+	}
+	if err := cmd.Start(); err != nil {
+		ptmx.Close()
+		return nil, err
+	}
+	return ptmx, nil
+}
+
+// Setsize sets the window size of the terminal.
+func Setsize(f *os.File, cols, rows uint16) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var ioctlErr error
+	if err := conn.Control(func(fd uintptr) {
+		ioctlErr = unix.IoctlSetWinsize(int(fd), unix.TIOCSWINSZ, &unix.Winsize{Col: cols, Row: rows})
+	}); err != nil {
+		return err
+	}
+	return ioctlErr
+}
+
+// open allocates a pseudo-terminal pair.
+func open() (ptmx, tty *os.File, err error) {
+	fd, err := unix.Open("/dev/ptmx", unix.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		unix.Close(fd)
+		return nil, nil, err
+	}
+	n, err := unix.IoctlGetUint32(fd, unix.TIOCGPTN)
+	if err != nil {
+		unix.Close(fd)
+		return nil, nil, err
+	}
+	if err := unix.SetNonblock(fd, true); err != nil {
+		unix.Close(fd)
+		return nil, nil, err
+	}
+	ptmx = os.NewFile(uintptr(fd), "/dev/ptmx")
+
+	name := "/dev/pts/" + strconv.FormatUint(uint64(n), 10)
+	tty, err = os.OpenFile(name, os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		ptmx.Close()
+		return nil, nil, err
+	}
+	return ptmx, tty, nil
+}
+
+// Kill kills the process group led by cmd (the new session created by Start),
+// so children such as a running kubectl are terminated with the shell.
+func Kill(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return unix.Kill(-cmd.Process.Pid, unix.SIGKILL)
+}
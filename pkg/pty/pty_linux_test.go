@@ -0,0 +1,66 @@
+//go:build linux
+
+package pty
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStart(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "stty size; tty -s && echo interactive")
+	ptmx, err := Start(cmd, 100, 40)
+	if err != nil {
+		t.Skipf("pseudo-terminals unavailable: %v", err)
+	}
+	defer ptmx.Close()
+
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(&out, ptmx) // returns EIO once the child exits
+		close(done)
+	}()
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out reading pty output")
+	}
+	if !bytes.Contains(out.Bytes(), []byte("40 100")) || !bytes.Contains(out.Bytes(), []byte("interactive")) {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestStartAs(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("switching users requires root")
+	}
+	cmd := exec.Command("sh", "-c", "id -u; id -G; test -w \"$(tty)\" && echo owns-tty")
+	ptmx, err := StartAs(cmd, 80, 24, 65534, 65534)
+	if err != nil {
+		t.Skipf("pseudo-terminals unavailable: %v", err)
+	}
+	defer ptmx.Close()
+
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(&out, ptmx)
+		close(done)
+	}()
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("command failed: %v, output %q", err, out.String())
+	}
+	<-done
+	if got := strings.Fields(out.String()); len(got) != 3 || got[0] != "65534" || got[1] != "65534" || got[2] != "owns-tty" {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
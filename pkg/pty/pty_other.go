@@ -0,0 +1,31 @@
+//go:build !linux
+
+package pty
+
+import (
+	"os"
+	"os/exec"
+)
+
+// Start is not supported on this platform.
+func Start(cmd *exec.Cmd, cols, rows uint16) (*os.File, error) {
+	return nil, ErrUnsupported
+}
+
+// StartAs is not supported on this platform.
+func StartAs(cmd *exec.Cmd, cols, rows uint16, uid, gid uint32) (*os.File, error) {
+	return nil, ErrUnsupported
+}
+
+// Setsize is not supported on this platform.
+func Setsize(f *os.File, cols, rows uint16) error {
+	return ErrUnsupported
+}
+
+// Kill kills the process started by Start.
+func Kill(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
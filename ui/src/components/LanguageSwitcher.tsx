@@ -4,12 +4,17 @@ import { GlobalOutlined } from '@ant-design/icons';
 import { useTranslation } from 'react-i18next';
 import type { MenuProps } from 'antd';
 import { supportedLanguages } from '../i18n';
+import { authService } from '../services/authService';
 
 const LanguageSwitcher: React.FC = () => {
   const { i18n } = useTranslation();
 
   const handleLanguageChange: MenuProps['onClick'] = ({ key }) => {
     i18n.changeLanguage(key);
+    // 已登录时同步保存为个人偏好，后端据此返回对应语言的错误信息
+    if (localStorage.getItem('token')) {
+      authService.updatePreferences({ language: key }).catch(() => undefined);
+    }
   };
 
   const currentLanguage = supportedLanguages.find(lang => lang.code === i18n.language) 
@@ -82,6 +82,11 @@ export const authService = {
   changePassword: (data: ChangePasswordRequest): Promise<ApiResponse<null>> => {
     return request.post<null>('/auth/change-password', data);
   },
+
+  // 更新个人偏好（错误提示语言）
+  updatePreferences: (data: { language: string }): Promise<ApiResponse<{ language: string }>> => {
+    return request.put<{ language: string }>('/auth/preferences', data);
+  },
 };
 
 // 系统设置服务
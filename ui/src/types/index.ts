@@ -388,6 +388,7 @@ export interface User {
   status: 'active' | 'inactive' | 'locked';
   last_login_at?: string;
   last_login_ip?: string;
+  language?: string;
   created_at: string;
   updated_at: string;
 }
@@ -15,6 +15,11 @@ api.interceptors.request.use(
     if (token) {
       config.headers.Authorization = `Bearer ${token}`;
     }
+    // 后端按 Accept-Language 返回对应语言的错误信息
+    const language = localStorage.getItem('kubepolaris-language');
+    if (language) {
+      config.headers['Accept-Language'] = language;
+    }
     return config;
   },
   (error) => {